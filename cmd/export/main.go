@@ -0,0 +1,240 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The export command renders the pages of modules in the discovery database
+// into a directory tree of static HTML and JSON files, suitable for serving
+// from object storage, for example in air-gapped environments or as an
+// archival snapshot.
+//
+// Usage:
+//
+//	export -out DIR [-all] [MODULE[@VERSION] ...]
+//
+// For each module version, the module page and, for each of its packages, the
+// documentation page and its JSON form (see /api/v1/docjson) are written. A
+// page with URL path P is written to DIR/P/index.html, and a JSON response to
+// DIR/P/index.json. Modules given without a version, and all modules when
+// -all is set, are exported at their latest version, under unversioned URLs.
+// Only the default tab of each page is exported.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/frontend"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/middleware"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+var (
+	outDir         = flag.String("out", "", "directory to write the exported files to")
+	all            = flag.Bool("all", false, "export the latest version of every module in the database")
+	staticPath     = flag.String("static", "content/static", "path to folder containing static files served")
+	thirdPartyPath = flag.String("third_party", "third_party", "path to folder containing third-party libraries")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s -out DIR [-all] [MODULE[@VERSION] ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	ctx := context.Background()
+	if *outDir == "" || (!*all && flag.NArg() == 0) {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cfg, err := config.Init(ctx)
+	if err != nil {
+		log.Fatal(ctx, err)
+	}
+	ddb, err := database.Open("postgres", cfg.DBConnInfo())
+	if err != nil {
+		log.Fatalf(ctx, "database.Open: %v", err)
+	}
+	db := postgres.New(ddb)
+	defer db.Close()
+
+	server, err := frontend.NewServer(frontend.ServerConfig{
+		DataSource:     db,
+		StaticPath:     *staticPath,
+		ThirdPartyPath: *thirdPartyPath,
+	})
+	if err != nil {
+		log.Fatalf(ctx, "frontend.NewServer: %v", err)
+	}
+	mux := http.NewServeMux()
+	server.Install(mux.Handle, nil)
+	e := &exporter{
+		db:      db,
+		handler: middleware.LatestVersion(server.LatestVersion)(mux),
+		dir:     *outDir,
+	}
+
+	var modules []*internal.ModuleInfo
+	if *all {
+		modules, err = db.GetLatestModuleVersions(ctx)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+	}
+	for _, arg := range flag.Args() {
+		mi := &internal.ModuleInfo{ModulePath: arg, Version: internal.LatestVersion}
+		if i := strings.Index(arg, "@"); i >= 0 {
+			mi.ModulePath, mi.Version = arg[:i], arg[i+1:]
+		}
+		modules = append(modules, mi)
+	}
+	for _, mi := range modules {
+		if err := e.exportModule(ctx, mi.ModulePath, mi.Version, *all); err != nil {
+			log.Errorf(ctx, "%s@%s: %v", mi.ModulePath, mi.Version, err)
+			e.failures++
+		}
+	}
+	for _, dir := range []struct{ src, dst string }{
+		{*staticPath, "static"},
+		{*thirdPartyPath, "third_party"},
+	} {
+		if err := copyDir(dir.src, filepath.Join(*outDir, dir.dst)); err != nil {
+			log.Fatal(ctx, err)
+		}
+	}
+	log.Infof(ctx, "exported %d files to %s; %d failures", e.files, *outDir, e.failures)
+	if e.failures > 0 {
+		os.Exit(1)
+	}
+}
+
+// An exporter writes the responses of the frontend to files.
+type exporter struct {
+	db       *postgres.DB
+	handler  http.Handler
+	dir      string
+	files    int
+	failures int
+}
+
+// exportModule exports the module page and package pages of modulePath at
+// version. If isLatest is true or version is internal.LatestVersion, version
+// must be the latest version of the module, and the pages are exported under
+// unversioned URLs.
+func (e *exporter) exportModule(ctx context.Context, modulePath, version string, isLatest bool) error {
+	if version == internal.LatestVersion {
+		mi, err := e.db.GetModuleInfo(ctx, modulePath, version)
+		if err != nil {
+			return err
+		}
+		version = mi.Version
+		isLatest = true
+	}
+	pkgs, err := e.db.GetPackagesInModule(ctx, modulePath, version)
+	if err != nil {
+		return err
+	}
+	urlVersion := version
+	if modulePath == stdlib.ModulePath {
+		urlVersion, err = stdlib.TagForVersion(version)
+		if err != nil {
+			return err
+		}
+	}
+
+	modURL := "/mod/" + modulePath
+	if modulePath == stdlib.ModulePath {
+		modURL = "/std"
+	}
+	if !isLatest {
+		modURL += "@" + urlVersion
+	}
+	e.export(ctx, modURL, "index.html")
+	for _, pkg := range pkgs {
+		pkgURL := "/" + pkg.Path
+		switch {
+		case isLatest:
+		case pkg.Path == modulePath || modulePath == stdlib.ModulePath:
+			pkgURL += "@" + urlVersion
+		default:
+			pkgURL = fmt.Sprintf("/%s@%s/%s", modulePath, urlVersion, strings.TrimPrefix(pkg.Path, modulePath+"/"))
+		}
+		e.export(ctx, pkgURL, "index.html")
+		e.export(ctx, "/api/v1/docjson"+pkgURL, "index.json")
+	}
+	return nil
+}
+
+// export writes the response to a request for urlPath to the file name in
+// the corresponding directory. Failures are logged and counted.
+func (e *exporter) export(ctx context.Context, urlPath, name string) {
+	req := httptest.NewRequest(http.MethodGet, urlPath, nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	e.handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		log.Errorf(ctx, "%s: got status %d", urlPath, w.Code)
+		e.failures++
+		return
+	}
+	filename := filepath.Join(e.dir, filepath.FromSlash(urlPath), name)
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		log.Errorf(ctx, "%s: %v", urlPath, err)
+		e.failures++
+		return
+	}
+	if err := ioutil.WriteFile(filename, w.Body.Bytes(), 0644); err != nil {
+		log.Errorf(ctx, "%s: %v", urlPath, err)
+		e.failures++
+		return
+	}
+	e.files++
+}
+
+// copyDir copies the files in the directory tree rooted at src to dst.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	_, err = io.Copy(out, in)
+	return err
+}
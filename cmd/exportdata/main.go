@@ -0,0 +1,168 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The exportdata command exports tables of the discovery database as CSV
+// files, for analysis by researchers or with tools like BigQuery.
+//
+// Usage:
+//
+//	exportdata -dest DEST [-partitions N] [TABLE ...]
+//
+// The tables are modules, packages, licenses, imports and imported_by; by
+// default all of them are exported. See postgres.ExportTables for their
+// columns, which hold only public information about modules. Modules hidden
+// from signed-out users by GO_DISCOVERY_ACL_FILE are not exported.
+//
+// DEST is a local directory or a Cloud Storage location like
+// gs://bucket/prefix. Each table is split into N partitions by module path,
+// and partition P of TABLE is written to
+//
+//	DEST/TABLE/dt=DATE/part-PPPPP.csv
+//
+// where DATE is the day of the export. The first line of each file names the
+// columns. This layout can be loaded into BigQuery as a table partitioned by
+// dt, using hive partitioning.
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+var (
+	dest       = flag.String("dest", "", "directory or gs://bucket/prefix to write the files to")
+	partitions = flag.Int("partitions", 16, "number of files to split each table into")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s -dest DEST [-partitions N] [TABLE ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	ctx := context.Background()
+	if *dest == "" || *partitions < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	tables := postgres.ExportTables
+	if flag.NArg() > 0 {
+		tables = nil
+		for _, name := range flag.Args() {
+			t := postgres.LookupExportTable(name)
+			if t == nil {
+				log.Fatalf(ctx, "unknown table %q", name)
+			}
+			tables = append(tables, t)
+		}
+	}
+
+	cfg, err := config.Init(ctx)
+	if err != nil {
+		log.Fatal(ctx, err)
+	}
+	ddb, err := database.Open("postgres", cfg.DBConnInfo())
+	if err != nil {
+		log.Fatalf(ctx, "database.Open: %v", err)
+	}
+	db := postgres.New(ddb)
+	defer db.Close()
+	if cfg.Auth.ACLFile != "" {
+		acl, err := auth.ReadACL(cfg.Auth.ACLFile)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+		db.SetACL(acl)
+	}
+
+	create, err := destination(ctx, *dest)
+	if err != nil {
+		log.Fatal(ctx, err)
+	}
+	date := time.Now().UTC().Format("2006-01-02")
+	for _, t := range tables {
+		for p := 0; p < *partitions; p++ {
+			name := path.Join(t.Name, "dt="+date, fmt.Sprintf("part-%05d.csv", p))
+			n, err := exportPartition(ctx, db, t, p, *partitions, create, name)
+			if err != nil {
+				log.Fatalf(ctx, "%s: %v", name, err)
+			}
+			log.Infof(ctx, "wrote %d rows to %s", n, name)
+		}
+	}
+}
+
+// A createFunc creates the file with the given slash-separated name, relative
+// to the destination.
+type createFunc func(name string) (io.WriteCloser, error)
+
+// destination returns a createFunc for the directory or Cloud Storage
+// location dest.
+func destination(ctx context.Context, dest string) (createFunc, error) {
+	if !strings.HasPrefix(dest, "gs://") {
+		return func(name string) (io.WriteCloser, error) {
+			filename := filepath.Join(dest, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+				return nil, err
+			}
+			return os.Create(filename)
+		}, nil
+	}
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bucket := strings.TrimPrefix(dest, "gs://")
+	var prefix string
+	if i := strings.Index(bucket, "/"); i >= 0 {
+		bucket, prefix = bucket[:i], bucket[i+1:]
+	}
+	return func(name string) (io.WriteCloser, error) {
+		w := client.Bucket(bucket).Object(path.Join(prefix, name)).NewWriter(ctx)
+		w.ContentType = "text/csv"
+		return w, nil
+	}, nil
+}
+
+// exportPartition writes partition p of table t to the file name, and
+// returns the number of rows written.
+func exportPartition(ctx context.Context, db *postgres.DB, t *postgres.ExportTable, p, numPartitions int, create createFunc, name string) (n int, err error) {
+	f, err := create(name)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	w := csv.NewWriter(f)
+	if err := w.Write(t.Columns); err != nil {
+		return 0, err
+	}
+	err = db.ExportRows(ctx, t, p, numPartitions, func(row []string) error {
+		n++
+		return w.Write(row)
+	})
+	if err != nil {
+		return 0, err
+	}
+	w.Flush()
+	return n, w.Error()
+}
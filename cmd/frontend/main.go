@@ -6,22 +6,32 @@ package main
 import (
 	"bufio"
 	"context"
+	"database/sql"
+	"encoding/json"
 	"flag"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	cloudtasks "cloud.google.com/go/cloudtasks/apiv2"
+	"cloud.google.com/go/errorreporting"
 	"cloud.google.com/go/profiler"
 	"contrib.go.opencensus.io/integrations/ocsql"
 	"github.com/go-redis/redis/v7"
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/assets"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/cdn"
 	"golang.org/x/pkgsite/internal/config"
 	"golang.org/x/pkgsite/internal/database"
 	"golang.org/x/pkgsite/internal/dcensus"
 	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/elasticsearch"
 	"golang.org/x/pkgsite/internal/experiment"
 	"golang.org/x/pkgsite/internal/frontend"
 	"golang.org/x/pkgsite/internal/log"
@@ -31,6 +41,7 @@ import (
 	"golang.org/x/pkgsite/internal/proxydatasource"
 	"golang.org/x/pkgsite/internal/queue"
 	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/verify"
 )
 
 var (
@@ -62,10 +73,44 @@ func main() {
 		exp        internal.ExperimentSource
 		fetchQueue queue.Queue
 	)
-	proxyClient, err := proxy.New(*proxyURL)
+	proxyClient, err := proxy.NewWithConfig(proxy.Config{
+		URL:        *proxyURL,
+		Routes:     cfg.ProxyRoutes,
+		Netrc:      cfg.ProxyNetrc,
+		Header:     cfg.ProxyHeader,
+		SumDB:      cfg.SumDB,
+		NoSumCheck: cfg.NoSumCheck,
+	})
 	if err != nil {
 		log.Fatal(ctx, err)
 	}
+	var acl *auth.ACL
+	if cfg.Auth.ACLFile != "" {
+		if *directProxy {
+			log.Fatal(ctx, "ACLs are not supported with -direct_proxy")
+		}
+		acl, err = auth.ReadACL(cfg.Auth.ACLFile)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+	}
+	var branding *frontend.Branding
+	if cfg.BrandingFile != "" {
+		branding, err = frontend.ReadBranding(cfg.BrandingFile)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+	}
+	// dbStats reports the DB connection pool statistics, for load shedding.
+	var dbStats func() sql.DBStats
+	// poolHandler serves the state of the DB connection pool on the debug
+	// server, and lets it be tuned.
+	var poolHandler http.Handler
+	// searchBackend is nil if the data source does not support search.
+	var searchBackend internal.SearchBackend
+	// apiKeys rate limits requests made with API keys, which are stored in
+	// the database.
+	apiKeys := middleware.Identity()
 	if *directProxy {
 		ds = proxydatasource.New(proxyClient)
 		exp = internal.NewLocalExperimentSource(readLocalExperiments(ctx))
@@ -79,11 +124,32 @@ func main() {
 		if err != nil {
 			log.Fatal(ctx, err)
 		}
+		database.SlowQueryThreshold = cfg.SlowQueryThreshold
+		if ci := cfg.DBReplicaConnInfo(); ci != "" {
+			if err := ddb.SetReplica(ctx, ocDriver, ci, cfg.DBReplicaMaxLag); err != nil {
+				log.Fatal(ctx, err)
+			}
+		}
 		db := postgres.New(ddb)
 		defer db.Close()
+		db.SetACL(acl)
+		go ddb.RecordPoolStats(ctx, poolStatsInterval)
+		poolHandler = database.PoolHandler(ddb)
+		dbStats = ddb.Stats
 		ds = db
 		exp = db
-		sourceClient := source.NewClient(config.SourceTimeout)
+		searchBackend = db
+		apiKeys = middleware.APIKeys(middleware.NewAPIKeyLimiter(ctx, time.Minute, db, cfg.Quota))
+		if cfg.ElasticsearchURL != "" {
+			searchBackend, err = elasticsearch.New(cfg.ElasticsearchURL, cfg.ElasticsearchIndex, db)
+			if err != nil {
+				log.Fatal(ctx, err)
+			}
+		}
+		var sourceClient *source.Client
+		if !cfg.DisableSourceLookups {
+			sourceClient = source.NewClient(config.SourceTimeout)
+		}
 		fetchQueue = newQueue(ctx, cfg, proxyClient, sourceClient, db)
 	}
 	var haClient *redis.Client
@@ -92,6 +158,21 @@ func main() {
 			Addr: cfg.RedisHAHost + ":" + cfg.RedisHAPort,
 		})
 	}
+	// Use the files bundled into the binary, if any, unless in developer
+	// mode, where templates are reloaded from disk.
+	var staticFS, thirdPartyFS http.FileSystem
+	if !*devMode {
+		staticFS, thirdPartyFS = assets.Bundled()
+	}
+	var verifier *verify.Checker
+	if cfg.Auth.OIDCIssuer != "" {
+		// Only signed-in users can verify that they own modules.
+		verifier = verify.NewChecker(&http.Client{Timeout: 10 * time.Second}, net.DefaultResolver)
+	}
+	var cdnPurger *cdn.Purger
+	if cfg.CDNServiceURL != "" {
+		cdnPurger = cdn.NewPurger(cfg.CDNServiceURL, cfg.CDNAPIKey)
+	}
 	server, err := frontend.NewServer(frontend.ServerConfig{
 		DataSource:           ds,
 		Queue:                fetchQueue,
@@ -99,7 +180,22 @@ func main() {
 		TaskIDChangeInterval: config.TaskIDChangeIntervalFrontend,
 		StaticPath:           *staticPath,
 		ThirdPartyPath:       *thirdPartyPath,
+		StaticFS:             staticFS,
+		ThirdPartyFS:         thirdPartyFS,
 		DevMode:              *devMode,
+		ACL:                  acl,
+		Branding:             branding,
+		SearchBackend:        searchBackend,
+		// Watchlists belong to signed-in users.
+		Watchlists:     cfg.Auth.OIDCIssuer != "",
+		SignIn:         cfg.Auth.OIDCIssuer != "",
+		RobotsDisallow: cfg.RobotsDisallow,
+		CachePolicies:  cfg.CachePolicies,
+		PrefsKey:       []byte(cfg.Auth.SessionKey),
+		// The worker refreshes the signals of the same providers.
+		ExternalSignals: cfg.ExternalSignals,
+		Verifier:        verifier,
+		CDNPurger:       cdnPurger,
 	})
 	if err != nil {
 		log.Fatalf(ctx, "frontend.NewServer: %v", err)
@@ -115,12 +211,17 @@ func main() {
 	views := append(dcensus.ServerViews,
 		postgres.SearchLatencyDistribution,
 		postgres.SearchResponseCount,
+		postgres.SearchResponseCountByExperiment,
 		frontend.FrontendFetchLatencyDistribution,
 		frontend.FrontendFetchResponseCount,
 		middleware.CacheResultCount,
 		middleware.CacheErrorCount,
 		middleware.QuotaResultCount,
+		middleware.LoadShedCount,
+		database.SlowQueryCount,
 	)
+	views = append(views, database.PoolViews...)
+	views = append(views, dcensus.ExperimentViews...)
 	if err := dcensus.Init(cfg, views...); err != nil {
 		log.Fatal(ctx, err)
 	}
@@ -131,7 +232,13 @@ func main() {
 		if err != nil {
 			log.Fatal(ctx, err)
 		}
-		go http.ListenAndServe(cfg.DebugAddr("localhost:8081"), dcensusServer)
+		debugMux := http.NewServeMux()
+		debugMux.Handle("/", dcensusServer)
+		debugMux.HandleFunc("/slowqueryz", serveSlowQueries)
+		if poolHandler != nil {
+			debugMux.Handle("/dbpoolz", poolHandler)
+		}
+		go http.ListenAndServe(cfg.DebugAddr("localhost:8081"), debugMux)
 	}
 	panicHandler, err := server.PanicHandler()
 	if err != nil {
@@ -142,22 +249,88 @@ func main() {
 	if err != nil {
 		log.Fatal(ctx, err)
 	}
+	authenticate := middleware.Identity()
+	if cfg.Auth.OIDCIssuer != "" {
+		oidc, err := auth.NewOIDC(ctx, auth.OIDCConfig{
+			Issuer:       cfg.Auth.OIDCIssuer,
+			ClientID:     cfg.Auth.OIDCClientID,
+			ClientSecret: cfg.Auth.OIDCClientSecret,
+			RedirectURL:  cfg.Auth.OIDCRedirectURL,
+			GroupsClaim:  cfg.Auth.OIDCGroupsClaim,
+			SessionKey:   []byte(cfg.Auth.SessionKey),
+		})
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+		authenticate = middleware.Authenticate(oidc, cfg.Auth.AllowedGroups)
+	}
 	mw := middleware.Chain(
 		middleware.RequestLog(requestLogger),
-		middleware.AcceptMethods(http.MethodGet), // accept only GETs
+		middleware.CORS(cfg.CORS, "/api/"), // answers preflight OPTIONS requests, so must come first
+		// Accept only GETs, and POSTs of the abuse report, watchlist and verification forms.
+		middleware.AcceptMethodsOrPostsTo([]string{"/report", "/watchlist", "/verify", "/api/v1/deps"}, http.MethodGet),
+		authenticate, // must come before caching, so pages are only served to signed-in users
+		apiKeys,      // must come before Quota, which does not limit requests with API keys
 		middleware.Quota(cfg.Quota),
+		middleware.LoadShedding(cfg.LoadShedMaxInFlight, cfg.LoadShedMaxDBWait, dbStats, cfg.Quota.ExpensiveRoutes),
 		middleware.GodocURL(),                          // potentially redirects so should be early in chain
 		middleware.SecureHeaders(),                     // must come before any caching for nonces to work
 		middleware.LatestVersion(server.LatestVersion), // must come before caching for version badge to work
-		middleware.Panic(panicHandler),
+		middleware.Panic(panicHandler, errorReporter(ctx, cfg)),
 		middleware.Timeout(54*time.Second),
 		middleware.Experiment(experimenter),
 	)
+	if cfg.GRPCPort != "" {
+		if acl != nil || cfg.Auth.OIDCIssuer != "" {
+			// gRPC calls bypass the middleware above, so they could read
+			// what only signed-in users may see.
+			log.Fatal(ctx, "gRPC is not supported with sign-in or an ACL")
+		}
+		lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+		log.Infof(ctx, "Serving gRPC on port %s", cfg.GRPCPort)
+		go func() {
+			log.Fatal(ctx, server.NewGRPCServer(middleware.GRPCQuota(cfg.Quota)).Serve(lis))
+		}()
+	}
 	addr := cfg.HostAddr("localhost:8080")
 	log.Infof(ctx, "Listening on addr %s", addr)
-	log.Fatal(ctx, http.ListenAndServe(addr, mw(router)))
+	serve(ctx, &http.Server{Addr: addr, Handler: mw(router)})
 }
 
+// shutdownTimeout is how long in-flight requests, and the last traces, are
+// given to finish when the frontend is stopped.
+const shutdownTimeout = 10 * time.Second
+
+// serve serves requests with srv until the process is asked to stop with
+// SIGTERM or an interrupt, then shuts srv down and sends the traces that are
+// still buffered.
+func serve(ctx context.Context, srv *http.Server) {
+	go func() {
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(ctx, err)
+		}
+	}()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, os.Interrupt)
+	<-sig
+	log.Infof(ctx, "Shutting down")
+	ctx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Errorf(ctx, "srv.Shutdown: %v", err)
+	}
+	if err := dcensus.Shutdown(ctx); err != nil {
+		log.Errorf(ctx, "dcensus.Shutdown: %v", err)
+	}
+}
+
+// poolStatsInterval is how often statistics about the database connection
+// pool are recorded.
+const poolStatsInterval = 10 * time.Second
+
 func newQueue(ctx context.Context, cfg *config.Config, proxyClient *proxy.Client, sourceClient *source.Client, db *postgres.DB) queue.Queue {
 	if !cfg.OnAppEngine() {
 		experiments, err := db.GetExperiments(ctx)
@@ -203,6 +376,32 @@ func openDB(ctx context.Context, cfg *config.Config, driver string) (_ *database
 		cfg.DBHost, err, cfg.DBSecondaryHost)
 	return database.Open(driver, ci)
 }
+
+// errorReporter returns the reporter for panics: Stackdriver Error Reporting
+// on AppEngine, Sentry if a DSN is configured, and the log otherwise.
+func errorReporter(ctx context.Context, cfg *config.Config) middleware.ErrorReporter {
+	if cfg.OnAppEngine() {
+		reporter, err := errorreporting.NewClient(ctx, cfg.ProjectID, errorreporting.Config{
+			ServiceName: cfg.ServiceID,
+			OnError: func(err error) {
+				log.Errorf(ctx, "Error reporting failed: %v", err)
+			},
+		})
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+		return reporter
+	}
+	if cfg.SentryDSN != "" {
+		reporter, err := middleware.NewSentryReporter(cfg.SentryDSN)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+		return reporter
+	}
+	return middleware.LogReporter{}
+}
+
 func getLogger(ctx context.Context, cfg *config.Config) middleware.Logger {
 	if cfg.OnAppEngine() {
 		logger, err := log.UseStackdriver(ctx, cfg, "frontend-log")
@@ -261,3 +460,14 @@ func readLocalExperiments(ctx context.Context) []*internal.Experiment {
 	log.Infof(ctx, "found %d experiment(s)", len(experiments))
 	return experiments
 }
+
+// serveSlowQueries writes the most recent slow database queries, with their
+// plans, as JSON.
+func serveSlowQueries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(database.RecentSlowQueries()); err != nil {
+		log.Error(r.Context(), err)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The migratedb command applies the pending migrations to the discovery
+// database, without needing the migrate CLI.
+//
+// Usage:
+//
+//	migratedb [-dry_run] [-migrations DIR]
+//
+// The migrations are read from DIR, unless the binary was built with the
+// embedassets tag, in which case the migrations bundled into it are used:
+//
+//	go generate ./internal/assets
+//	go build -tags embedassets ./cmd/migratedb
+//
+// With -dry_run, the pending migrations are listed but not applied.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"golang.org/x/pkgsite/internal/assets"
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+var (
+	dryRun         = flag.Bool("dry_run", false, "list the pending migrations without applying them")
+	migrationsPath = flag.String("migrations", "migrations", "path to folder containing migrations, if not bundled")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-dry_run] [-migrations DIR]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	ctx := context.Background()
+	if flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cfg, err := config.Init(ctx)
+	if err != nil {
+		log.Fatal(ctx, err)
+	}
+	versions, err := database.Migrate(cfg.DBConnInfo(), assets.Migrations(*migrationsPath), *dryRun)
+	if err != nil {
+		log.Fatal(ctx, err)
+	}
+	verb := "applied"
+	if *dryRun {
+		verb = "would apply"
+	}
+	if len(versions) == 0 {
+		log.Info(ctx, "database is up to date")
+	}
+	for _, v := range versions {
+		log.Infof(ctx, "%s migration %d", verb, v)
+	}
+}
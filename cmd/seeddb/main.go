@@ -0,0 +1,238 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The seeddb command fetches a list of module versions and writes them to the
+// discovery database, to populate a new deployment or build a corpus for load
+// testing.
+//
+// Usage:
+//
+//	seeddb [-f FILE] [-workers N] [-worker URL]
+//
+// Each line of FILE, or of standard input if FILE is omitted or "-", is a
+// module path, optionally followed by @VERSION. Modules without a version are
+// fetched at their latest version. Blank lines and lines beginning with '#'
+// are ignored.
+//
+// By default the modules are fetched from the proxy and processed in this
+// process, using the same configuration as the worker. With -worker, each
+// module version is instead sent to the /fetch endpoint of the running worker
+// at URL.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/proxy"
+	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/worker"
+)
+
+var (
+	file          = flag.String("f", "-", "file of module versions to fetch, or - for standard input")
+	workers       = flag.Int("workers", 10, "number of module versions to fetch concurrently")
+	workerURL     = flag.String("worker", "", "URL of a running worker to send the module versions to, instead of fetching them here")
+	progressEvery = flag.Duration("progress", 10*time.Second, "how often to report progress")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-f FILE] [-workers N] [-worker URL]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	ctx := context.Background()
+	if flag.NArg() > 0 || *workers < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	in := os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+		defer f.Close()
+		in = f
+	}
+	modules, err := readModules(in)
+	if err != nil {
+		log.Fatal(ctx, err)
+	}
+
+	var fetch fetchFunc
+	if *workerURL != "" {
+		fetch = remoteFetcher(strings.TrimSuffix(*workerURL, "/"))
+	} else {
+		fetch = localFetcher(ctx)
+	}
+
+	s := &seeder{fetch: fetch, total: len(modules)}
+	done := make(chan struct{})
+	go s.reportProgress(ctx, *progressEvery, done)
+	s.run(ctx, modules, *workers)
+	close(done)
+	log.Infof(ctx, "fetched %d module versions; %d failures", s.total, s.failures)
+	if s.failures > 0 {
+		os.Exit(1)
+	}
+}
+
+// readModules reads module versions from r, one per line.
+func readModules(r io.Reader) ([]*internal.ModuleInfo, error) {
+	var modules []*internal.ModuleInfo
+	scan := bufio.NewScanner(r)
+	for scan.Scan() {
+		line := strings.TrimSpace(scan.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		mi := &internal.ModuleInfo{ModulePath: line, Version: internal.LatestVersion}
+		if i := strings.Index(line, "@"); i >= 0 {
+			mi.ModulePath, mi.Version = line[:i], line[i+1:]
+		}
+		if mi.ModulePath == "" || mi.Version == "" {
+			return nil, fmt.Errorf("invalid module version %q", line)
+		}
+		modules = append(modules, mi)
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	return modules, nil
+}
+
+// A fetchFunc fetches and processes a module version.
+type fetchFunc func(ctx context.Context, modulePath, version string) error
+
+// localFetcher returns a fetchFunc that processes module versions in this
+// process.
+func localFetcher(ctx context.Context) fetchFunc {
+	cfg, err := config.Init(ctx)
+	if err != nil {
+		log.Fatal(ctx, err)
+	}
+	ddb, err := database.Open("postgres", cfg.DBConnInfo())
+	if err != nil {
+		log.Fatalf(ctx, "database.Open: %v", err)
+	}
+	db := postgres.New(ddb)
+	proxyClient, err := proxy.NewWithConfig(proxy.Config{
+		URL:        cfg.ProxyURL,
+		Routes:     cfg.ProxyRoutes,
+		Netrc:      cfg.ProxyNetrc,
+		Header:     cfg.ProxyHeader,
+		SumDB:      cfg.SumDB,
+		NoSumCheck: cfg.NoSumCheck,
+	})
+	if err != nil {
+		log.Fatal(ctx, err)
+	}
+	var sourceClient *source.Client
+	if !cfg.DisableSourceLookups {
+		sourceClient = source.NewClient(config.SourceTimeout)
+	}
+	return func(ctx context.Context, modulePath, version string) error {
+		code, err := worker.FetchAndUpdateState(ctx, modulePath, version, proxyClient, sourceClient, db)
+		if err != nil {
+			return err
+		}
+		if code/100 != 2 {
+			return fmt.Errorf("status %d", code)
+		}
+		return nil
+	}
+}
+
+// remoteFetcher returns a fetchFunc that asks the worker at baseURL to
+// process module versions. The worker reports only failures that it would
+// retry, so other failures are visible only in its logs.
+func remoteFetcher(baseURL string) fetchFunc {
+	return func(ctx context.Context, modulePath, version string) error {
+		u := fmt.Sprintf("%s/fetch/%s/@v/%s", baseURL, modulePath, version)
+		if version == internal.LatestVersion {
+			u = fmt.Sprintf("%s/fetch/%s/@latest", baseURL, modulePath)
+		}
+		req, err := http.NewRequest(http.MethodPost, u, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			body, _ := ioutil.ReadAll(resp.Body)
+			return fmt.Errorf("%s: %s: %s", u, resp.Status, strings.TrimSpace(string(body)))
+		}
+		return nil
+	}
+}
+
+// A seeder fetches module versions concurrently and counts the results.
+type seeder struct {
+	fetch    fetchFunc
+	total    int
+	done     int64
+	failures int64
+}
+
+// run fetches modules using n concurrent goroutines.
+func (s *seeder) run(ctx context.Context, modules []*internal.ModuleInfo, n int) {
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for _, mi := range modules {
+		mi := mi
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := s.fetch(ctx, mi.ModulePath, mi.Version); err != nil {
+				log.Errorf(ctx, "%s@%s: %v", mi.ModulePath, mi.Version, err)
+				atomic.AddInt64(&s.failures, 1)
+			}
+			atomic.AddInt64(&s.done, 1)
+		}()
+	}
+	wg.Wait()
+}
+
+// reportProgress logs the number of module versions fetched every interval,
+// until done is closed.
+func (s *seeder) reportProgress(ctx context.Context, interval time.Duration, done <-chan struct{}) {
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			n := atomic.LoadInt64(&s.done)
+			log.Infof(ctx, "fetched %d/%d module versions (%d failures) in %s",
+				n, s.total, atomic.LoadInt64(&s.failures), time.Since(start).Round(time.Second))
+		}
+	}
+}
@@ -2,20 +2,30 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// The teeproxy hits the frontend with a URL from godoc.org.
+// The teeproxy hits the frontend with a URL from godoc.org, and records
+// differences in status and latency between the two sites.
 package main
 
 import (
 	"context"
+	"flag"
 	"net/http"
 	"os"
 
 	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/database"
 	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
 	"golang.org/x/pkgsite/internal/teeproxy"
 )
 
+var (
+	staticPath = flag.String("static", "content/static", "path to folder containing static files served")
+	record     = flag.Bool("record", true, "record request events in the database")
+)
+
 func main() {
+	flag.Parse()
 	ctx := context.Background()
 
 	cfg, err := config.Init(ctx)
@@ -30,10 +40,25 @@ func main() {
 		}
 	}
 
+	var db *postgres.DB
+	if *record {
+		ddb, err := database.Open("postgres", cfg.DBConnInfo())
+		if err != nil {
+			log.Fatalf(ctx, "database.Open: %v", err)
+		}
+		db = postgres.New(ddb)
+		defer db.Close()
+	}
+	server, err := teeproxy.NewServer(db, *staticPath)
+	if err != nil {
+		log.Fatal(ctx, err)
+	}
+
 	http.HandleFunc("/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "content/static/img/favicon.ico")
+		http.ServeFile(w, r, *staticPath+"/img/favicon.ico")
 	})
-	http.HandleFunc("/", teeproxy.HandleGddoEvent)
+	http.HandleFunc("/report", server.HandleReport)
+	http.HandleFunc("/", server.HandleGddoEvent)
 
 	addr := cfg.HostAddr("localhost:8020")
 	log.Infof(ctx, "Listening on addr %s", addr)
@@ -12,21 +12,32 @@ import (
 	"flag"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	cloudtasks "cloud.google.com/go/cloudtasks/apiv2"
 	"cloud.google.com/go/errorreporting"
 	"cloud.google.com/go/profiler"
 	"github.com/go-redis/redis/v7"
+	"golang.org/x/pkgsite/internal/assets"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/cdn"
 	"golang.org/x/pkgsite/internal/config"
 	"golang.org/x/pkgsite/internal/database"
 	"golang.org/x/pkgsite/internal/dcensus"
+	"golang.org/x/pkgsite/internal/elasticsearch"
 	"golang.org/x/pkgsite/internal/experiment"
+	"golang.org/x/pkgsite/internal/fetch"
 	"golang.org/x/pkgsite/internal/index"
 	"golang.org/x/pkgsite/internal/queue"
+	"golang.org/x/pkgsite/internal/repometa"
+	"golang.org/x/pkgsite/internal/signals"
 	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/watchlist"
+	"golang.org/x/pkgsite/internal/webhook"
 	"golang.org/x/pkgsite/internal/worker"
 
 	"golang.org/x/pkgsite/internal/log"
@@ -42,6 +53,8 @@ var (
 	queueName  = config.GetEnv("GO_DISCOVERY_WORKER_TASK_QUEUE", "")
 	workers    = flag.Int("workers", 10, "number of concurrent requests to the fetch service, when running locally")
 	staticPath = flag.String("static", "content/static", "path to folder containing static files served")
+	migrateDB  = flag.Bool("migrate", false, "apply pending database migrations before starting")
+	migrations = flag.String("migrations", "migrations", "path to folder containing migrations, if not bundled")
 )
 
 func main() {
@@ -62,6 +75,16 @@ func main() {
 	}
 
 	readProxyRemoved(ctx)
+	fetch.SkipPatterns = append(fetch.SkipPatterns, cfg.FetchSkipPatterns...)
+	fetch.LargeFileSize = cfg.LargeFileSize
+
+	if *migrateDB {
+		versions, err := database.Migrate(cfg.DBConnInfo(), assets.Migrations(*migrations), false)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+		log.Infof(ctx, "applied migrations %v", versions)
+	}
 
 	// Wrap the postgres driver with OpenCensus instrumentation.
 	driverName, err := ocsql.Register("postgres", ocsql.WithAllTraceOptions())
@@ -74,22 +97,91 @@ func main() {
 	}
 	db := postgres.New(ddb)
 	defer db.Close()
+	go ddb.RecordPoolStats(ctx, 10*time.Second)
 
 	populateExcluded(ctx, db)
 
-	indexClient, err := index.New(cfg.IndexURL)
-	if err != nil {
-		log.Fatal(ctx, err)
+	var indexClient *index.Client
+	if cfg.IndexURL != config.IndexOff {
+		indexClient, err = index.New(cfg.IndexURL)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
 	}
-	proxyClient, err := proxy.New(cfg.ProxyURL)
+	proxyClient, err := proxy.NewWithConfig(proxy.Config{
+		URL:        cfg.ProxyURL,
+		Routes:     cfg.ProxyRoutes,
+		Netrc:      cfg.ProxyNetrc,
+		Header:     cfg.ProxyHeader,
+		SumDB:      cfg.SumDB,
+		NoSumCheck: cfg.NoSumCheck,
+	})
 	if err != nil {
 		log.Fatal(ctx, err)
 	}
-	sourceClient := source.NewClient(config.SourceTimeout)
+	var sourceClient *source.Client
+	if !cfg.DisableSourceLookups {
+		sourceClient = source.NewClient(config.SourceTimeout)
+	}
 	fetchQueue := newQueue(ctx, cfg, proxyClient, sourceClient, db)
 	reportingClient := reportingClient(ctx, cfg)
 	redisHAClient := getHARedis(ctx, cfg)
 	redisCacheClient := getCacheRedis(ctx, cfg)
+	var cdnPurger *cdn.Purger
+	if cfg.CDNServiceURL != "" {
+		cdnPurger = cdn.NewPurger(cfg.CDNServiceURL, cfg.CDNAPIKey)
+	}
+	var searchBackend *elasticsearch.Backend
+	if cfg.ElasticsearchURL != "" {
+		searchBackend, err = elasticsearch.New(cfg.ElasticsearchURL, cfg.ElasticsearchIndex, db)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+	}
+	var webhooks []*webhook.Hook
+	if cfg.WebhooksFile != "" {
+		webhooks, err = webhook.ReadHooks(cfg.WebhooksFile)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+	}
+	signalProviders, err := signals.Providers(cfg.ExternalSignals, cfg.GitHubToken)
+	if err != nil {
+		log.Fatal(ctx, err)
+	}
+	var repoClient *repometa.Client
+	if cfg.RepositoryMetadata {
+		repoClient = repometa.NewClient(&http.Client{Timeout: config.SourceTimeout}, cfg.GitHubToken, cfg.GitLabToken)
+	}
+	var mailer watchlist.Mailer
+	if cfg.Mail.SMTPAddr != "" {
+		mailer, err = watchlist.NewSMTPMailer(cfg.Mail.SMTPAddr, cfg.Mail.SMTPUser, cfg.Mail.SMTPPassword, cfg.Mail.From)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+	}
+	var acl *auth.ACL
+	if cfg.Auth.ACLFile != "" {
+		acl, err = auth.ReadACL(cfg.Auth.ACLFile)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+	}
+	var adminAuth middleware.Authenticator
+	if cfg.Auth.OIDCIssuer != "" && cfg.Auth.AdminRedirectURL != "" {
+		oidc, err := auth.NewOIDC(ctx, auth.OIDCConfig{
+			Issuer:       cfg.Auth.OIDCIssuer,
+			ClientID:     cfg.Auth.OIDCClientID,
+			ClientSecret: cfg.Auth.OIDCClientSecret,
+			RedirectURL:  cfg.Auth.AdminRedirectURL,
+			GroupsClaim:  cfg.Auth.OIDCGroupsClaim,
+			SessionKey:   []byte(cfg.Auth.SessionKey),
+		})
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+		adminAuth = oidc
+	}
 	server, err := worker.NewServer(cfg, worker.ServerConfig{
 		DB:                   db,
 		IndexClient:          indexClient,
@@ -99,8 +191,18 @@ func main() {
 		RedisCacheClient:     redisCacheClient,
 		Queue:                fetchQueue,
 		ReportingClient:      reportingClient,
+		CDNPurger:            cdnPurger,
 		TaskIDChangeInterval: config.TaskIDChangeIntervalWorker,
 		StaticPath:           *staticPath,
+		SearchBackend:        searchBackend,
+		AdminAuthenticator:   adminAuth,
+		AdminGroups:          cfg.Auth.AdminGroups,
+		Webhooks:             webhooks,
+		Mailer:               mailer,
+		SiteURL:              cfg.Mail.SiteURL,
+		ACL:                  acl,
+		SignalProviders:      signalProviders,
+		RepoClient:           repoClient,
 	})
 	if err != nil {
 		log.Fatal(ctx, err)
@@ -109,6 +211,7 @@ func main() {
 	server.Install(router.Handle)
 
 	views := append(dcensus.ClientViews, dcensus.ServerViews...)
+	views = append(views, database.PoolViews...)
 	if err := dcensus.Init(cfg, views...); err != nil {
 		log.Fatal(ctx, err)
 	}
@@ -132,8 +235,22 @@ func main() {
 	if err != nil {
 		log.Fatal(ctx, err)
 	}
+	var reporter middleware.ErrorReporter = middleware.LogReporter{}
+	if reportingClient != nil {
+		reporter = reportingClient
+	} else if cfg.SentryDSN != "" {
+		reporter, err = middleware.NewSentryReporter(cfg.SentryDSN)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+	}
+	panicHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	})
 	mw := middleware.Chain(
 		middleware.RequestLog(requestLogger),
+		middleware.SecureHeaders(), // the index page's script needs the nonce
+		middleware.Panic(panicHandler, reporter),
 		middleware.Timeout(time.Duration(handlerTimeout)*time.Minute),
 		middleware.Experiment(experimenter),
 	)
@@ -141,7 +258,34 @@ func main() {
 
 	addr := cfg.HostAddr("localhost:8000")
 	log.Infof(ctx, "Listening on addr %s", addr)
-	log.Fatal(ctx, http.ListenAndServe(addr, nil))
+	serve(ctx, &http.Server{Addr: addr})
+}
+
+// shutdownTimeout is how long in-flight requests, and the last traces, are
+// given to finish when the worker is stopped.
+const shutdownTimeout = 10 * time.Second
+
+// serve serves requests with srv until the process is asked to stop with
+// SIGTERM or an interrupt, then shuts srv down and sends the traces that are
+// still buffered.
+func serve(ctx context.Context, srv *http.Server) {
+	go func() {
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(ctx, err)
+		}
+	}()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, os.Interrupt)
+	<-sig
+	log.Infof(ctx, "Shutting down")
+	ctx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Errorf(ctx, "srv.Shutdown: %v", err)
+	}
+	if err := dcensus.Shutdown(ctx); err != nil {
+		log.Errorf(ctx, "dcensus.Shutdown: %v", err)
+	}
 }
 
 func newQueue(ctx context.Context, cfg *config.Config, proxyClient *proxy.Client, sourceClient *source.Client, db *postgres.DB) queue.Queue {
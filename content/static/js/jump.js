@@ -14,6 +14,9 @@
 
 // The dialog is activated by pressing the 'f' key. It presents a list
 // (#JumpDialog-list) of all Go identifiers displayed in the documentation.
+// The identifiers are loaded from the URL in the dialog's data-symbols-url
+// attribute the first time the dialog is opened; if that fails, they are
+// collected from the DOM.
 // Entering text in the dialog's text box (#JumpDialog-filter) restricts the
 // list to identifiers containing the text. Clicking on an identifier jumps to
 // its documentation.
@@ -33,6 +36,36 @@ if (!jumpDialog.showModal) {
 
 let jumpListItems; // All the identifiers in the doc; computed only once.
 
+// loadJumpListItems fetches the identifiers in the documentation from the
+// symbols endpoint and sets jumpListItems. It returns a promise that is
+// resolved when the identifiers are loaded, or when loading them fails; in
+// that case jumpListItems is left unset, and updateJumpList collects the
+// identifiers from the DOM.
+function loadJumpListItems() {
+  const url = jumpDialog.getAttribute('data-symbols-url');
+  if (jumpListItems || !url || !window.fetch) {
+    return Promise.resolve();
+  }
+  return fetch(url)
+    .then(function (response) {
+      if (!response.ok) {
+        throw new Error(response.statusText);
+      }
+      return response.json();
+    })
+    .then(function (symbols) {
+      if (symbols.length == 0) {
+        return;
+      }
+      const items = [];
+      for (const s of symbols) {
+        items.push(newJumpListItemForSymbol(s));
+      }
+      jumpListItems = finishJumpListItems(items);
+    })
+    .catch(function () {});
+}
+
 // collectJumpListItems returns a list of items, one for each identifier in the
 // documentation on the current page.
 //
@@ -50,6 +83,12 @@ function collectJumpListItems() {
   if (items.length == 0) {
     items = collectJumpListItemsFallback(doc);
   }
+  return finishJumpListItems(items);
+}
+
+// finishJumpListItems makes the links of items close the dialog, and returns
+// items sorted by name.
+function finishJumpListItems(items) {
   // Clicking on any of the links closes the dialog.
   for (const item of items) {
     item.link.addEventListener('click', function () {
@@ -105,6 +144,20 @@ function newJumpListItem(el) {
   };
 }
 
+// newJumpListItemForSymbol creates a new item for s, a symbol returned by the
+// symbols endpoint.
+function newJumpListItemForSymbol(s) {
+  const a = document.createElement('a');
+  a.setAttribute('href', '#' + s.anchor);
+  a.setAttribute('tabindex', '-1');
+  return {
+    link: a,
+    name: s.name,
+    kind: s.kind,
+    lower: s.name.toLowerCase(), // for sorting
+  };
+}
+
 // guessKind tries to guess the kind of el by looking around the DOM.
 // Fixing b/143456714 would make this unnecessary.
 function guessKind(el) {
@@ -131,7 +184,7 @@ function guessKind(el) {
   }
 }
 
-let lastFilterValue; // The last contents of the filter text box.
+let lastFilterValue = ''; // The last contents of the filter text box.
 let activeJumpItem = -1; // The index of the currently active item in the list.
 
 // updateJumpList sets the elements of the dialog list to
@@ -269,7 +322,9 @@ document.addEventListener('keypress', function (e) {
       e.preventDefault();
       jumpFilter.value = '';
       jumpDialog.showModal();
-      updateJumpList('');
+      loadJumpListItems().then(function () {
+        updateJumpList(jumpFilter.value);
+      });
       break;
     case '?':
       shortcutsDialog.showModal();
@@ -0,0 +1,119 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package abuse defines reports that users make about packages and modules
+// that contain malware, violate a license or are spam, and the actions that
+// administrators take to moderate them.
+package abuse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kinds of report.
+const (
+	KindMalware          = "malware"
+	KindLicenseViolation = "license-violation"
+	KindSpam             = "spam"
+)
+
+// Kinds are the kinds of report, in the order they are offered to users.
+var Kinds = []string{KindMalware, KindLicenseViolation, KindSpam}
+
+// Review statuses of a Report.
+const (
+	StatusPending   = "pending"
+	StatusResolved  = "resolved"
+	StatusDismissed = "dismissed"
+)
+
+// MaxDetailsLen is the maximum length of the details of a report, in bytes.
+const MaxDetailsLen = 4000
+
+// A Report is a user's report that a package or module is abusive.
+type Report struct {
+	ID int64
+	// Path is the path of the reported package or module.
+	Path       string
+	Version    string // empty if the report is not about a specific version
+	Kind       string
+	Details    string
+	Contact    string // how to reach the reporter; may be empty
+	Status     string
+	CreatedAt  time.Time
+	ReviewedAt time.Time // zero if not reviewed
+}
+
+// Validate checks that r has a path and a known kind, and that its details
+// are not too long.
+func (r *Report) Validate() error {
+	if r.Path == "" {
+		return fmt.Errorf("missing path")
+	}
+	if !validKind(r.Kind) {
+		return fmt.Errorf("kind must be one of %s; got %q", strings.Join(Kinds, ", "), r.Kind)
+	}
+	if len(r.Details) > MaxDetailsLen {
+		return fmt.Errorf("details must be at most %d bytes; got %d", MaxDetailsLen, len(r.Details))
+	}
+	return nil
+}
+
+func validKind(kind string) bool {
+	for _, k := range Kinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Kinds of Action.
+const (
+	ActionResolveReport    = "resolve-report"
+	ActionDismissReport    = "dismiss-report"
+	ActionConfirmLookalike = "confirm-lookalike"
+	ActionDismissLookalike = "dismiss-lookalike"
+	ActionExclude          = "exclude"
+	ActionUnexclude        = "unexclude"
+	ActionSupersede        = "supersede"
+	ActionUnsupersede      = "unsupersede"
+)
+
+// An Action is a change made by an administrator while moderating the site.
+// Actions are recorded in the audit log; see package audit.
+type Action struct {
+	Actor string // the email address of the administrator
+	Kind  string
+	// Target is what the action applies to: the ID of an abuse report, a
+	// module path for lookalike flags, a path prefix for exclusions, or a
+	// package path for supersessions.
+	Target string
+	Reason string
+	// SupersededBy is the path of the package that supersedes Target, for
+	// ActionSupersede.
+	SupersededBy string
+}
+
+// An Exclusion is a path prefix that is excluded from processing and
+// serving.
+type Exclusion struct {
+	Prefix    string
+	CreatedBy string
+	Reason    string
+	CreatedAt time.Time
+}
+
+// A Supersession records that a package is superseded by another one, which
+// its users should move to, like github.com/pkg/errors by errors.
+type Supersession struct {
+	PackagePath  string
+	SupersededBy string
+	// CreatedBy is the email address of the verified owner of the module of
+	// the package, or of the administrator, who recorded the supersession.
+	CreatedBy string
+	CreatedAt time.Time
+}
@@ -0,0 +1,31 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package abuse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		report  Report
+		wantErr bool
+	}{
+		{"valid", Report{Path: "github.com/a/b", Kind: KindMalware}, false},
+		{"valid with details", Report{Path: "github.com/a/b", Kind: KindSpam, Details: "ads"}, false},
+		{"missing path", Report{Kind: KindSpam}, true},
+		{"unknown kind", Report{Path: "github.com/a/b", Kind: "rude"}, true},
+		{"long details", Report{Path: "github.com/a/b", Kind: KindSpam, Details: strings.Repeat("x", MaxDetailsLen+1)}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.report.Validate()
+			if (err != nil) != test.wantErr {
+				t.Errorf("Validate() = %v, want error: %t", err, test.wantErr)
+			}
+		})
+	}
+}
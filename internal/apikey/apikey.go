@@ -0,0 +1,69 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package apikey defines the keys that give programmatic users of the API
+// higher rate limits than anonymous traffic.
+//
+// Clients send their key in the Header of their requests. Only the hash of
+// a key is stored: the key itself is shown once, when it is issued, and
+// cannot be recovered afterwards.
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// Header is the request header holding the API key.
+const Header = "X-API-Key"
+
+// A Key is an issued API key, without its secret.
+type Key struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"` // who or what the key was issued to
+	// Tier is the name of the quota tier of the key, which determines its
+	// rate limit; see config.QuotaSettings.APIKeyTiers.
+	Tier      string     `json:"tier"`
+	CreatedBy string     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	// Requests is the number of requests made with the key in the last
+	// UsageDays days. It is only set when listing keys.
+	Requests int64 `json:"requests"`
+}
+
+// UsageDays is the number of days of usage reported for each key.
+const UsageDays = 30
+
+// Generate returns a new random key.
+func Generate() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Hash returns the hash of key that is stored instead of the key.
+func Hash(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+type keyKey struct{}
+
+// NewContextWithKey returns a context derived from ctx that holds k.
+func NewContextWithKey(ctx context.Context, k *Key) context.Context {
+	return context.WithValue(ctx, keyKey{}, k)
+}
+
+// KeyFromContext returns the Key stored in ctx, or nil if there is none.
+func KeyFromContext(ctx context.Context) *Key {
+	k, _ := ctx.Value(keyKey{}).(*Key)
+	return k
+}
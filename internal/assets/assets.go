@@ -0,0 +1,198 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package assets provides the templates and static files of the frontend,
+// and the database migrations, either from directories on disk or bundled
+// into the binary.
+//
+// To bundle the files, run
+//
+//	go generate ./internal/assets
+//
+// and build with the embedassets tag:
+//
+//	go build -tags embedassets ./cmd/frontend
+package assets
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:generate go run gen_assets.go
+
+// bundle maps the slash-separated paths of bundled files, like
+// "static/html/base.tmpl", to their contents. It is set by assets.gen.go when
+// building with the embedassets tag.
+var bundle map[string]string
+
+// bundleTime is the modification time reported for bundled files.
+var bundleTime time.Time
+
+// Bundled returns the file systems holding the content/static and
+// third_party directories bundled into the binary. It returns nils if the
+// binary was built without them.
+func Bundled() (static, thirdParty http.FileSystem) {
+	if bundle == nil {
+		return nil, nil
+	}
+	return newMapFS(bundle, "static"), newMapFS(bundle, "third_party")
+}
+
+// Migrations returns the file system holding the database migrations bundled
+// into the binary, or the directory dir if the binary was built without them.
+func Migrations(dir string) http.FileSystem {
+	if bundle == nil {
+		return http.Dir(dir)
+	}
+	return newMapFS(bundle, "migrations")
+}
+
+// ReadFile returns the contents of the named file of fsys.
+func ReadFile(fsys http.FileSystem, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// Glob returns the names of the files of fsys in dir whose base names match
+// pattern, as with path.Match, in sorted order.
+func Glob(fsys http.FileSystem, dir, pattern string) ([]string, error) {
+	f, err := fsys.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	infos, err := f.Readdir(-1)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, fi := range infos {
+		if fi.IsDir() {
+			continue
+		}
+		ok, err := path.Match(pattern, fi.Name())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			names = append(names, path.Join(dir, fi.Name()))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// mapFS is an http.FileSystem holding the files of a map whose paths start
+// with prefix. Paths in the map do not have a leading slash.
+type mapFS struct {
+	files  map[string]string
+	prefix string
+}
+
+func newMapFS(files map[string]string, prefix string) *mapFS {
+	return &mapFS{files: files, prefix: prefix}
+}
+
+// Open implements http.FileSystem.
+func (m *mapFS) Open(name string) (http.File, error) {
+	name = strings.TrimPrefix(path.Join(m.prefix, path.Clean("/"+name)), "/")
+	if contents, ok := m.files[name]; ok {
+		return &mapFile{
+			Reader: strings.NewReader(contents),
+			info:   fileInfo{name: path.Base(name), size: int64(len(contents))},
+		}, nil
+	}
+	// A directory exists if some file is in it.
+	var children []os.FileInfo
+	seen := map[string]bool{}
+	dirPrefix := name + "/"
+	if name == "" {
+		dirPrefix = ""
+	}
+	for p, contents := range m.files {
+		if !strings.HasPrefix(p, dirPrefix) {
+			continue
+		}
+		rest := p[len(dirPrefix):]
+		child := fileInfo{name: rest, size: int64(len(contents))}
+		if i := strings.Index(rest, "/"); i >= 0 {
+			child = fileInfo{name: rest[:i], dir: true}
+		}
+		if !seen[child.name] {
+			seen[child.name] = true
+			children = append(children, child)
+		}
+	}
+	if len(children) == 0 {
+		return nil, os.ErrNotExist
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].Name() < children[j].Name() })
+	return &mapFile{
+		Reader:   strings.NewReader(""),
+		info:     fileInfo{name: path.Base(name), dir: true},
+		children: children,
+	}, nil
+}
+
+// mapFile is a file or directory of a mapFS.
+type mapFile struct {
+	*strings.Reader
+	info     fileInfo
+	children []os.FileInfo
+}
+
+func (f *mapFile) Close() error { return nil }
+
+func (f *mapFile) Stat() (os.FileInfo, error) { return f.info, nil }
+
+func (f *mapFile) Readdir(count int) ([]os.FileInfo, error) {
+	if !f.info.dir {
+		return nil, &os.PathError{Op: "readdir", Path: f.info.name, Err: os.ErrInvalid}
+	}
+	if count <= 0 {
+		children := f.children
+		f.children = nil
+		return children, nil
+	}
+	if len(f.children) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(f.children) {
+		count = len(f.children)
+	}
+	children := f.children[:count]
+	f.children = f.children[count:]
+	return children, nil
+}
+
+// fileInfo implements os.FileInfo for the files of a mapFS.
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.size }
+func (fi fileInfo) ModTime() time.Time { return bundleTime }
+func (fi fileInfo) IsDir() bool        { return fi.dir }
+func (fi fileInfo) Sys() interface{}   { return nil }
+
+func (fi fileInfo) Mode() os.FileMode {
+	if fi.dir {
+		return os.ModeDir | 0555
+	}
+	return 0444
+}
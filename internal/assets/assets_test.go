@@ -0,0 +1,88 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package assets
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testFiles = map[string]string{
+	"static/html/base.tmpl":          "base",
+	"static/html/helpers/a.tmpl":     "a",
+	"static/html/helpers/b.tmpl":     "b",
+	"static/html/helpers/README":     "readme",
+	"static/html/helpers/sub/c.tmpl": "c",
+	"third_party/lib.js":             "lib",
+}
+
+func TestMapFS(t *testing.T) {
+	fsys := newMapFS(testFiles, "static")
+
+	got, err := ReadFile(fsys, "/html/base.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "base" {
+		t.Errorf("ReadFile(base.tmpl) = %q, want %q", got, "base")
+	}
+	for _, name := range []string{"/lib.js", "/html/missing.tmpl", "/missing"} {
+		if _, err := fsys.Open(name); !os.IsNotExist(err) {
+			t.Errorf("Open(%q): got %v, want not-exist error", name, err)
+		}
+	}
+
+	names, err := Glob(fsys, "/html/helpers", "*.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/html/helpers/a.tmpl", "/html/helpers/b.tmpl"}
+	if diff := cmp.Diff(want, names); diff != "" {
+		t.Errorf("Glob mismatch (-want +got):\n%s", diff)
+	}
+
+	f, err := fsys.Open("/html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos, err := f.Readdir(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []string
+	for _, fi := range infos {
+		e := fi.Name()
+		if fi.IsDir() {
+			e += "/"
+		}
+		entries = append(entries, e)
+	}
+	if diff := cmp.Diff([]string{"base.tmpl", "helpers/"}, entries); diff != "" {
+		t.Errorf("Readdir mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMapFSFileServer(t *testing.T) {
+	ts := httptest.NewServer(http.FileServer(newMapFS(testFiles, "third_party")))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/lib.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "lib" {
+		t.Errorf("got %d %q, want 200 %q", resp.StatusCode, body, "lib")
+	}
+}
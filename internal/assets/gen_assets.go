@@ -0,0 +1,104 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build ignore
+
+// This file generates assets.gen.go.
+// It bundles the files in content/static, third_party and migrations into a
+// map.
+// Run by a "go:generate" comment in assets.go.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const outfile = "assets.gen.go"
+
+var dirs = []struct{ src, dst string }{
+	{"../../content/static", "static"},
+	{"../../third_party", "third_party"},
+	{"../../migrations", "migrations"},
+}
+
+func main() {
+	files := map[string][]byte{}
+	for _, dir := range dirs {
+		err := filepath.Walk(dir.src, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			base := info.Name()
+			if info.IsDir() {
+				// Ignore hidden directories, like .git.
+				if strings.HasPrefix(base, ".") && p != dir.src {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			// Ignore hidden files and common editor backup files.
+			if strings.HasPrefix(base, ".") || strings.HasSuffix(base, "~") || strings.HasSuffix(base, ".swp") {
+				return nil
+			}
+			rel, err := filepath.Rel(dir.src, p)
+			if err != nil {
+				return err
+			}
+			data, err := ioutil.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			files[dir.dst+"/"+filepath.ToSlash(rel)] = data
+			return nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if len(files) == 0 {
+		log.Fatal("no files")
+	}
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := new(bytes.Buffer)
+	fmt.Fprintf(out, `// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by gen_assets.go; DO NOT EDIT.
+
+// +build embedassets
+
+package assets
+
+import "time"
+
+func init() {
+	bundleTime = time.Unix(%d, 0)
+	bundle = map[string]string{
+`, time.Now().Unix())
+	for _, name := range names {
+		fmt.Fprintf(out, "\t\t%q: %q,\n", name, files[name])
+	}
+	fmt.Fprint(out, "\t}\n}\n")
+	// The output is not passed through go/format: it is large, and
+	// formatting would not change it.
+	if err := ioutil.WriteFile(outfile, out.Bytes(), 0640); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("wrote %s (%d files)\n", outfile, len(names))
+}
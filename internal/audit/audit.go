@@ -0,0 +1,61 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package audit defines the entries of the audit log, which records every
+// change that administrators make to the site.
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actions recorded in the audit log, other than moderation actions, whose
+// names are the kinds of abuse.Action.
+const (
+	ActionReprocess  = "reprocess"
+	ActionClearCache = "clear-cache"
+	// The target of the webhook actions is the ID of the webhook.
+	ActionAddWebhook    = "add-webhook"
+	ActionDeleteWebhook = "delete-webhook"
+	// The target of the API key actions is the ID of the key.
+	ActionAddAPIKey    = "add-api-key"
+	ActionRevokeAPIKey = "revoke-api-key"
+	// The target of the collection actions is the name of the collection.
+	ActionSaveCollection   = "save-collection"
+	ActionDeleteCollection = "delete-collection"
+)
+
+// An Entry records one change made by an administrator. Entries are never
+// changed or deleted.
+type Entry struct {
+	ID     int64
+	Actor  string // the email address of the administrator, or another name
+	Action string
+	// Target is what the action applies to, like the ID of an abuse report,
+	// a module path or a path prefix.
+	Target string
+	Reason string
+	// Before and After are JSON descriptions of the target before and after
+	// the change, or nil if it did not exist or the action does not record
+	// them.
+	Before    json.RawMessage
+	After     json.RawMessage
+	CreatedAt time.Time
+}
+
+// A Query selects entries of the audit log. Empty fields match any entry.
+type Query struct {
+	Actor  string
+	Action string
+	Target string
+	Since  time.Time
+	// Limit is the maximum number of entries to return. If it is zero,
+	// DefaultLimit is used.
+	Limit int
+}
+
+// DefaultLimit is the number of entries returned for a Query without a
+// limit.
+const DefaultLimit = 100
@@ -0,0 +1,75 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// An ACL restricts which users may see modules, by module path prefix.
+type ACL struct {
+	rules []aclRule // sorted by decreasing prefix length
+}
+
+type aclRule struct {
+	prefix string
+	groups []string
+}
+
+// NewACL returns an ACL from a map from module path prefixes to the groups
+// whose members may see the modules with that prefix, and the packages in
+// them. A prefix matches whole path components, so "example.com/a" matches
+// "example.com/a/b" but not "example.com/ab". If several prefixes match a
+// path, the longest one applies. Paths that match no prefix are visible to
+// everyone.
+func NewACL(rules map[string][]string) *ACL {
+	a := &ACL{}
+	for prefix, groups := range rules {
+		a.rules = append(a.rules, aclRule{strings.TrimSuffix(prefix, "/"), groups})
+	}
+	sort.Slice(a.rules, func(i, j int) bool {
+		return len(a.rules[i].prefix) > len(a.rules[j].prefix)
+	})
+	return a
+}
+
+// ReadACL reads an ACL from a JSON file holding an object like the argument
+// to NewACL, for example
+//
+//	{"corp.example.com/secret": ["secret-team", "admins"]}
+func ReadACL(filename string) (_ *ACL, err error) {
+	defer derrors.Wrap(&err, "ReadACL(%q)", filename)
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var rules map[string][]string
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	return NewACL(rules), nil
+}
+
+// Allowed reports whether the user stored in ctx may see path, a module or
+// package path. A nil ACL allows everything.
+func (a *ACL) Allowed(ctx context.Context, path string) bool {
+	if a == nil {
+		return true
+	}
+	for _, r := range a.rules {
+		if path == r.prefix || strings.HasPrefix(path, r.prefix+"/") {
+			u := UserFromContext(ctx)
+			return u != nil && u.InAnyGroup(r.groups)
+		}
+	}
+	return true
+}
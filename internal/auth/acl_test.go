@@ -0,0 +1,44 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"testing"
+)
+
+func TestACLAllowed(t *testing.T) {
+	acl := NewACL(map[string][]string{
+		"corp.com":            {"eng"},
+		"corp.com/secret/":    {"secret"},
+		"corp.com/open/inner": {"eng", "docs"},
+	})
+	eng := NewContextWithUser(context.Background(), &User{Email: "e@corp.com", Groups: []string{"eng"}})
+	secret := NewContextWithUser(context.Background(), &User{Email: "s@corp.com", Groups: []string{"secret"}})
+	anon := context.Background()
+	for _, test := range []struct {
+		ctx  context.Context
+		path string
+		want bool
+	}{
+		{anon, "github.com/a/b", true},
+		{anon, "corp.com/a", false},
+		{eng, "corp.com/a", true},
+		{eng, "corp.community/a", true},
+		{eng, "corp.com/secret", false},
+		{eng, "corp.com/secret/pkg", false},
+		{secret, "corp.com/secret/pkg", true},
+		{secret, "corp.com/a", false},
+		{eng, "corp.com/open/inner/x", true},
+	} {
+		if got := acl.Allowed(test.ctx, test.path); got != test.want {
+			t.Errorf("%v: Allowed(%q) = %t, want %t", UserFromContext(test.ctx), test.path, got, test.want)
+		}
+	}
+	var nilACL *ACL
+	if !nilACL.Allowed(anon, "corp.com/a") {
+		t.Error("nil ACL: got false, want true")
+	}
+}
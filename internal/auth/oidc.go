@@ -0,0 +1,324 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/oauth2"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+const (
+	sessionCookie = "pkgsite-session"
+	stateCookie   = "pkgsite-oidc-state"
+
+	// sessionDuration is how long a user stays signed in.
+	sessionDuration = 12 * time.Hour
+
+	// loginTimeout is how long a user has to complete sign-in at the
+	// identity provider.
+	loginTimeout = 10 * time.Minute
+)
+
+// OIDCConfig configures an OIDC authenticator.
+type OIDCConfig struct {
+	// Issuer is the URL of the OpenID Connect provider, like
+	// https://accounts.google.com.
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the URL the provider redirects to after sign-in. Its
+	// path is handled by the authenticator.
+	RedirectURL string
+	// GroupsClaim is the ID token claim that lists the groups of the user.
+	// If empty, "groups" is used.
+	GroupsClaim string
+	// SessionKey is the key used to sign session cookies.
+	SessionKey []byte
+	// HTTPClient is used to talk to the provider. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// OIDC authenticates users with the OpenID Connect authorization code flow,
+// and keeps them signed in with a signed session cookie.
+type OIDC struct {
+	cfg          OIDCConfig
+	oauth        *oauth2.Config
+	callbackPath string
+	now          func() time.Time
+}
+
+// providerMetadata is the part of the OpenID provider metadata that is used.
+// See https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata.
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+}
+
+// NewOIDC returns an OIDC authenticator, after fetching the metadata of the
+// provider.
+func NewOIDC(ctx context.Context, cfg OIDCConfig) (_ *OIDC, err error) {
+	defer derrors.Wrap(&err, "NewOIDC(ctx, %q)", cfg.Issuer)
+
+	if len(cfg.SessionKey) == 0 {
+		return nil, errors.New("missing session key")
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	redirect, err := url.Parse(cfg.RedirectURL)
+	if err != nil {
+		return nil, err
+	}
+	issuer := strings.TrimSuffix(cfg.Issuer, "/")
+	resp, err := ctxhttp.Get(ctx, cfg.HTTPClient, issuer+"/.well-known/openid-configuration")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching provider metadata: %s", resp.Status)
+	}
+	var md providerMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&md); err != nil {
+		return nil, err
+	}
+	if md.Issuer != issuer {
+		return nil, fmt.Errorf("provider metadata has issuer %q, want %q", md.Issuer, issuer)
+	}
+	cfg.Issuer = issuer
+	return &OIDC{
+		cfg: cfg,
+		oauth: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  md.AuthorizationEndpoint,
+				TokenURL: md.TokenEndpoint,
+			},
+			Scopes: []string{"openid", "email", "profile"},
+		},
+		callbackPath: redirect.Path,
+		now:          time.Now,
+	}, nil
+}
+
+// Authenticate implements middleware.Authenticator. It returns the user
+// signed in with the session cookie of r. Otherwise, it handles the redirect
+// from the provider, or starts sign-in by redirecting to the provider.
+func (o *OIDC) Authenticate(w http.ResponseWriter, r *http.Request) *User {
+	if r.URL.Path == o.callbackPath {
+		o.handleCallback(w, r)
+		return nil
+	}
+	if c, err := r.Cookie(sessionCookie); err == nil {
+		if u := o.sessionUser(c.Value); u != nil {
+			return u
+		}
+	}
+	o.startLogin(w, r)
+	return nil
+}
+
+// session is the contents of a session cookie.
+type session struct {
+	Email   string
+	Groups  []string
+	Expires int64 // Unix time
+}
+
+func (o *OIDC) sessionUser(cookie string) *User {
+	var s session
+	if !o.verify(cookie, &s) || o.now().Unix() >= s.Expires {
+		return nil
+	}
+	return &User{Email: s.Email, Groups: s.Groups}
+}
+
+// startLogin redirects to the provider, remembering the requested URL.
+func (o *OIDC) startLogin(w http.ResponseWriter, r *http.Request) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Errorf(r.Context(), "OIDC: rand.Read: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	state := hex.EncodeToString(b)
+	o.setCookie(w, stateCookie, o.sign([]string{state, r.URL.RequestURI()}), loginTimeout)
+	http.Redirect(w, r, o.oauth.AuthCodeURL(state), http.StatusFound)
+}
+
+// handleCallback completes sign-in: it exchanges the authorization code for
+// an ID token, sets the session cookie and redirects to the URL originally
+// requested.
+func (o *OIDC) handleCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	fail := func(status int, format string, args ...interface{}) {
+		log.Infof(ctx, "OIDC: "+format, args...)
+		http.Error(w, http.StatusText(status), status)
+	}
+	c, err := r.Cookie(stateCookie)
+	var state []string
+	if err != nil || !o.verify(c.Value, &state) || len(state) != 2 {
+		fail(http.StatusBadRequest, "missing or invalid state cookie")
+		return
+	}
+	if r.FormValue("state") != state[0] {
+		fail(http.StatusBadRequest, "state mismatch")
+		return
+	}
+	if e := r.FormValue("error"); e != "" {
+		fail(http.StatusForbidden, "provider returned error %q", e)
+		return
+	}
+	tok, err := o.oauth.Exchange(context.WithValue(ctx, oauth2.HTTPClient, o.cfg.HTTPClient), r.FormValue("code"))
+	if err != nil {
+		fail(http.StatusBadGateway, "exchanging code: %v", err)
+		return
+	}
+	rawIDToken, _ := tok.Extra("id_token").(string)
+	u, err := o.parseIDToken(rawIDToken)
+	if err != nil {
+		fail(http.StatusForbidden, "%v", err)
+		return
+	}
+	o.setCookie(w, sessionCookie, o.sign(&session{
+		Email:   u.Email,
+		Groups:  u.Groups,
+		Expires: o.now().Add(sessionDuration).Unix(),
+	}), sessionDuration)
+	o.setCookie(w, stateCookie, "", -1)
+	returnTo := state[1]
+	if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") {
+		returnTo = "/"
+	}
+	http.Redirect(w, r, returnTo, http.StatusFound)
+}
+
+// parseIDToken returns the user identified by the ID token. The token was
+// received directly from the token endpoint over TLS, so its signature need
+// not be checked; see
+// https://openid.net/specs/openid-connect-core-1_0.html#IDTokenValidation.
+func (o *OIDC) parseIDToken(raw string) (_ *User, err error) {
+	defer derrors.Wrap(&err, "parseIDToken")
+
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed ID token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, err
+	}
+	if iss, _ := claims["iss"].(string); iss != o.cfg.Issuer {
+		return nil, fmt.Errorf("issuer %q, want %q", iss, o.cfg.Issuer)
+	}
+	if !hasAudience(claims["aud"], o.cfg.ClientID) {
+		return nil, fmt.Errorf("audience %v does not include %q", claims["aud"], o.cfg.ClientID)
+	}
+	if exp, _ := claims["exp"].(float64); o.now().Unix() >= int64(exp) {
+		return nil, errors.New("expired ID token")
+	}
+	email, _ := claims["email"].(string)
+	if email == "" {
+		return nil, errors.New("no email in ID token")
+	}
+	u := &User{Email: email}
+	groups, _ := claims[o.cfg.GroupsClaim].([]interface{})
+	for _, g := range groups {
+		if s, ok := g.(string); ok {
+			u.Groups = append(u.Groups, s)
+		}
+	}
+	return u, nil
+}
+
+// hasAudience reports whether the aud claim, a string or a list of strings,
+// includes clientID.
+func hasAudience(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, a := range aud {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (o *OIDC) setCookie(w http.ResponseWriter, name, value string, maxAge time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// sign returns v encoded as JSON, with an HMAC of the encoding.
+func (o *OIDC) sign(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		// v is always one of our own types.
+		panic(err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(o.mac(payload))
+}
+
+// verify checks the HMAC of s, a value returned by sign, and decodes it into v.
+func (o *OIDC) verify(s string, v interface{}) bool {
+	i := strings.LastIndex(s, ".")
+	if i < 0 {
+		return false
+	}
+	payload := s[:i]
+	mac, err := base64.RawURLEncoding.DecodeString(s[i+1:])
+	if err != nil || !hmac.Equal(mac, o.mac(payload)) {
+		return false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+func (o *OIDC) mac(payload string) []byte {
+	h := hmac.New(sha256.New, o.cfg.SessionKey)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOIDC(t *testing.T) {
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&providerMetadata{
+			Issuer:                issuer,
+			AuthorizationEndpoint: issuer + "/authorize",
+			TokenEndpoint:         issuer + "/token",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "good-code" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		claims, _ := json.Marshal(map[string]interface{}{
+			"iss":    issuer,
+			"aud":    []string{"client"},
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"email":  "gopher@example.com",
+			"groups": []string{"eng"},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "token",
+			"token_type":   "Bearer",
+			"id_token":     "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".sig",
+		})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	issuer = ts.URL
+
+	o, err := NewOIDC(context.Background(), OIDCConfig{
+		Issuer:       issuer,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "https://pkg.example.com/auth/callback",
+		SessionKey:   []byte("key"),
+		HTTPClient:   ts.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An unauthenticated request is redirected to the provider.
+	w := httptest.NewRecorder()
+	if u := o.Authenticate(w, httptest.NewRequest("GET", "/example.com/p?tab=doc", nil)); u != nil {
+		t.Fatalf("got user %v before sign-in", u)
+	}
+	if w.Code != http.StatusFound {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusFound)
+	}
+	loc, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := loc.Path, "/authorize"; got != want {
+		t.Fatalf("redirected to %q, want %q", got, want)
+	}
+	state := loc.Query().Get("state")
+	cookies := w.Result().Cookies()
+
+	callback := func(query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/auth/callback?"+query, nil)
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		if u := o.Authenticate(w, r); u != nil {
+			t.Fatalf("got user %v from callback", u)
+		}
+		return w
+	}
+	if w := callback("state=wrong&code=good-code"); w.Code != http.StatusBadRequest {
+		t.Errorf("wrong state: got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := callback("state=" + state + "&code=bad-code"); w.Code != http.StatusBadGateway {
+		t.Errorf("bad code: got status %d, want %d", w.Code, http.StatusBadGateway)
+	}
+	w = callback("state=" + state + "&code=good-code")
+	if w.Code != http.StatusFound {
+		t.Fatalf("callback: got status %d, want %d", w.Code, http.StatusFound)
+	}
+	if got, want := w.Header().Get("Location"), "/example.com/p?tab=doc"; got != want {
+		t.Errorf("callback redirected to %q, want %q", got, want)
+	}
+
+	// The session cookie identifies the user.
+	r := httptest.NewRequest("GET", "/example.com/p", nil)
+	for _, c := range w.Result().Cookies() {
+		if c.Name == sessionCookie {
+			r.AddCookie(c)
+		}
+	}
+	got := o.Authenticate(httptest.NewRecorder(), r)
+	want := &User{Email: "gopher@example.com", Groups: []string{"eng"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	// The session expires.
+	o.now = func() time.Time { return time.Now().Add(sessionDuration) }
+	if u := o.Authenticate(httptest.NewRecorder(), r); u != nil {
+		t.Errorf("got user %v after session expired", u)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import "context"
+
+// A User is a person signed in to a private deployment of the site.
+type User struct {
+	Email  string
+	Groups []string
+}
+
+// InAnyGroup reports whether u is a member of one of groups.
+func (u *User) InAnyGroup(groups []string) bool {
+	for _, g := range groups {
+		for _, ug := range u.Groups {
+			if g == ug {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type userKey struct{}
+
+// NewContextWithUser returns a context derived from ctx that holds u.
+func NewContextWithUser(ctx context.Context, u *User) context.Context {
+	return context.WithValue(ctx, userKey{}, u)
+}
+
+// UserFromContext returns the User stored in ctx, or nil if there is none.
+func UserFromContext(ctx context.Context) *User {
+	u, _ := ctx.Value(userKey{}).(*User)
+	return u
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cdn supports caching pages in a CDN in front of the frontend.
+//
+// Responses are tagged with surrogate keys naming the module and module
+// version they were rendered from. When the worker reprocesses a module, it
+// purges the keys for that module, so the CDN can cache pages for a long time
+// without serving stale documentation.
+package cdn
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// SurrogateKeyHeader is the response header holding the space-separated
+// surrogate keys of a page.
+const SurrogateKeyHeader = "Surrogate-Key"
+
+// ModuleKey returns the surrogate key for all pages of modulePath.
+func ModuleKey(modulePath string) string {
+	return "mod/" + modulePath
+}
+
+// ModuleVersionKey returns the surrogate key for the pages of
+// modulePath@version.
+func ModuleVersionKey(modulePath, version string) string {
+	return "modver/" + modulePath + "@" + version
+}
+
+// TagModuleVersion adds the surrogate keys for modulePath@version to the
+// response headers of w. It must be called before the response is written.
+func TagModuleVersion(w http.ResponseWriter, modulePath, version string) {
+	keys := []string{ModuleKey(modulePath), ModuleVersionKey(modulePath, version)}
+	if old := w.Header().Get(SurrogateKeyHeader); old != "" {
+		keys = append([]string{old}, keys...)
+	}
+	w.Header().Set(SurrogateKeyHeader, strings.Join(keys, " "))
+}
+
+// A Purger purges pages tagged with a surrogate key from the CDN.
+type Purger struct {
+	serviceURL string
+	apiKey     string
+	client     *http.Client
+}
+
+// NewPurger returns a Purger that uses the Fastly purge API of the CDN service
+// at serviceURL, such as https://api.fastly.com/service/<id>, authenticating
+// with apiKey. A key is purged by a POST to serviceURL/purge/<key>.
+func NewPurger(serviceURL, apiKey string) *Purger {
+	return &Purger{
+		serviceURL: strings.TrimSuffix(serviceURL, "/"),
+		apiKey:     apiKey,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Purge purges the pages tagged with any of the given keys.
+func (p *Purger) Purge(ctx context.Context, keys ...string) (err error) {
+	defer derrors.Wrap(&err, "Purge(ctx, %q)", keys)
+	for _, key := range keys {
+		req, err := http.NewRequest("POST", p.serviceURL+"/purge/"+url.PathEscape(key), nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Fastly-Key", p.apiKey)
+		resp, err := ctxhttp.Do(ctx, p.client, req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("purging %q: %s", key, resp.Status)
+		}
+	}
+	return nil
+}
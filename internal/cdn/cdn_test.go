@@ -0,0 +1,50 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cdn
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTagModuleVersion(t *testing.T) {
+	w := httptest.NewRecorder()
+	TagModuleVersion(w, "example.com/mod", "v1.0.0")
+	TagModuleVersion(w, "example.com/other", "v2.0.0")
+	want := "mod/example.com/mod modver/example.com/mod@v1.0.0 mod/example.com/other modver/example.com/other@v2.0.0"
+	if got := w.Header().Get(SurrogateKeyHeader); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	var got []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("got method %s, want POST", r.Method)
+		}
+		if k := r.Header.Get("Fastly-Key"); k != "secret" {
+			t.Errorf("got API key %q, want %q", k, "secret")
+		}
+		got = append(got, r.URL.EscapedPath())
+	}))
+	defer ts.Close()
+
+	p := NewPurger(ts.URL+"/service/1/", "secret")
+	if err := p.Purge(context.Background(), ModuleKey("example.com/mod"), ModuleVersionKey("example.com/mod", "v1.0.0")); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"/service/1/purge/mod%2Fexample.com%2Fmod",
+		"/service/1/purge/modver%2Fexample.com%2Fmod@v1.0.0",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package collection defines the collections of packages that administrators
+// curate, like "HTTP routers", and that the frontend shows at
+// /collections/<name>.
+package collection
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A Collection is a list of hand-picked packages.
+type Collection struct {
+	// Name is the last element of the URL of the collection, like
+	// "http-routers".
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	// Packages are the packages of the collection, in the order they are
+	// shown.
+	Packages  []*Package `json:"packages,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at,omitempty"`
+}
+
+// A Package is a package of a collection.
+type Package struct {
+	Path string `json:"path"`
+	// Blurb says why the package is in the collection.
+	Blurb string `json:"blurb,omitempty"`
+	// Synopsis is the synopsis of the package, if it is in search. It is not
+	// stored with the collection.
+	Synopsis string `json:"synopsis,omitempty"`
+}
+
+// nameRegexp matches the names of collections: lower case words separated by
+// hyphens.
+var nameRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Validate reports whether c has a valid name, a title, and packages with
+// distinct paths.
+func (c *Collection) Validate() error {
+	if !nameRegexp.MatchString(c.Name) {
+		return fmt.Errorf("bad name %q: must be lower case words separated by hyphens", c.Name)
+	}
+	if c.Title == "" {
+		return errors.New("missing title")
+	}
+	seen := map[string]bool{}
+	for _, p := range c.Packages {
+		if p.Path == "" {
+			return errors.New("missing package path")
+		}
+		if seen[p.Path] {
+			return fmt.Errorf("package %q is listed twice", p.Path)
+		}
+		seen[p.Path] = true
+	}
+	return nil
+}
+
+// ParsePackages parses the packages of a collection from s, which holds one
+// package per line: its path, optionally followed by spaces and its blurb.
+// Blank lines are ignored.
+func ParsePackages(s string) []*Package {
+	var pkgs []*Package
+	for _, line := range strings.Split(s, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		pkgs = append(pkgs, &Package{
+			Path:  fields[0],
+			Blurb: strings.Join(fields[1:], " "),
+		})
+	}
+	return pkgs
+}
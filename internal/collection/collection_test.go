@@ -0,0 +1,41 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collection
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		c    Collection
+		want bool
+	}{
+		{Collection{Name: "http-routers", Title: "HTTP routers"}, true},
+		{Collection{Name: "x", Title: "X", Packages: []*Package{{Path: "a.com/a"}, {Path: "b.com/b"}}}, true},
+		{Collection{Name: "HTTP routers", Title: "HTTP routers"}, false},
+		{Collection{Name: "-routers", Title: "HTTP routers"}, false},
+		{Collection{Name: "http-routers"}, false},
+		{Collection{Name: "x", Title: "X", Packages: []*Package{{Path: ""}}}, false},
+		{Collection{Name: "x", Title: "X", Packages: []*Package{{Path: "a.com/a"}, {Path: "a.com/a"}}}, false},
+	} {
+		if got := test.c.Validate() == nil; got != test.want {
+			t.Errorf("%+v: got valid %t, want %t", test.c, got, test.want)
+		}
+	}
+}
+
+func TestParsePackages(t *testing.T) {
+	got := ParsePackages("github.com/go-chi/chi  Lightweight,  idiomatic router.\n\n  github.com/gorilla/mux\r\n")
+	want := []*Package{
+		{Path: "github.com/go-chi/chi", Blurb: "Lightweight, idiomatic router."},
+		{Path: "github.com/gorilla/mux"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"math/rand"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -46,6 +47,14 @@ func AppVersionLabel() string {
 	return cfg.AppVersionLabel()
 }
 
+const megabyte = 1000 * 1000
+
+// CheckpointZipSize returns the size, in bytes, of the zips of modules whose
+// packages are processed with checkpoints. Zero means no module is.
+func CheckpointZipSize() int64 {
+	return cfg.CheckpointZipSize
+}
+
 // AppVersionFormat is the expected format of the app version timestamp.
 const AppVersionFormat = "20060102t150405"
 
@@ -72,8 +81,25 @@ type Config struct {
 	// Discovery environment variables
 	ProxyURL, IndexURL string
 
+	// ProxyRoutes, ProxyNetrc and ProxyHeader configure the module proxy
+	// client for private modules; see proxy.Config.
+	ProxyRoutes string
+	ProxyNetrc  string `json:"-"`
+	ProxyHeader string `json:"-"`
+
+	// SumDB is the checksum database that fetched modules are verified
+	// against, and NoSumCheck lists the modules that are not verified; see
+	// proxy.Config.
+	SumDB, NoSumCheck string
+
+	// DisableSourceLookups prevents fetching from third-party URLs to find
+	// the source repository of a module, for running without internet access.
+	// Repositories on well-known hosts are still linked.
+	DisableSourceLookups bool
+
 	// Ports used for hosting. 'DebugPort' is used for serving HTTP debug pages.
-	Port, DebugPort string
+	// 'GRPCPort', if set, is used for serving the frontend's gRPC API.
+	Port, DebugPort, GRPCPort string
 
 	// AppEngine identifiers
 	ProjectID, ServiceID, VersionID, ZoneID, InstanceID, LocationID string
@@ -96,6 +122,16 @@ type Config struct {
 	DBSecondaryHost                          string // DB host to use if first one is down
 	DBPassword                               string `json:"-"`
 
+	// DBReplicaHost is the host of a read-only replica of the database. If it
+	// is set, the frontend sends its reads there, unless the replica is more
+	// than DBReplicaMaxLag behind the primary.
+	DBReplicaHost   string
+	DBReplicaMaxLag time.Duration
+
+	// SlowQueryThreshold is the duration above which a database query is
+	// logged as slow, with its plan. Zero disables slow query logging.
+	SlowQueryThreshold time.Duration
+
 	// Configuration for redis page cache.
 	RedisCacheHost, RedisCachePort string
 
@@ -106,7 +142,102 @@ type Config struct {
 	// UseProfiler specifies whether to enable Stackdriver Profiler.
 	UseProfiler bool
 
+	// OTLPEndpoint is the URL of the OTLP/HTTP traces endpoint of an
+	// OpenTelemetry collector, such as http://localhost:4318/v1/traces.
+	// If it is set, trace spans are exported there, in addition to
+	// Stackdriver.
+	OTLPEndpoint string
+
+	// TraceSampleRate is the probability that a request is traced.
+	TraceSampleRate float64
+
+	// SentryDSN is the Sentry project DSN to report errors to when not
+	// running on AppEngine. If it is empty, errors are only logged.
+	SentryDSN string `json:"-"`
+
+	// CDNServiceURL is the URL of the purge API of the CDN in front of the
+	// frontend. If it is set, the worker purges the pages of a module from
+	// the CDN after processing it or after an administrator changes its
+	// state, and the frontend after a user verifies their ownership of it.
+	CDNServiceURL string
+	CDNAPIKey     string `json:"-"`
+
 	Quota QuotaSettings
+
+	// LoadShedMaxInFlight and LoadShedMaxDBWait are the thresholds above
+	// which the frontend rejects requests for the expensive routes of
+	// Quota: the number of such requests being served, and the average
+	// time spent waiting for a database connection. See
+	// middleware.LoadShedding.
+	LoadShedMaxInFlight int
+	LoadShedMaxDBWait   time.Duration
+
+	// CachePolicies are the caching headers of frontend responses, by route
+	// class: "static", "latest", "pinned" and "search". See
+	// middleware.CacheControl.
+	CachePolicies map[string]CachePolicy
+
+	// CORS configures which other sites may call the frontend's JSON API
+	// from browsers.
+	CORS CORSSettings
+
+	// Auth configures sign-in for private deployments of the frontend. If
+	// Auth.OIDCIssuer is empty, the frontend is public.
+	Auth AuthSettings
+
+	// Mail configures the email sent by the worker, like watchlist
+	// summaries. If Mail.SMTPAddr is empty, no email is sent.
+	Mail MailSettings
+
+	// BrandingFile is the path of a JSON file with the site name, logos,
+	// links and colors of the frontend; see frontend.ReadBranding.
+	BrandingFile string
+
+	// WebhooksFile is the path of a JSON file with webhooks that the worker
+	// notifies of new module versions, in addition to those registered in
+	// the database; see webhook.ReadHooks.
+	WebhooksFile string
+
+	// ElasticsearchURL, if set, is the URL of an Elasticsearch cluster used
+	// for search instead of the database. ElasticsearchIndex is the name of
+	// the index holding the search documents.
+	ElasticsearchURL, ElasticsearchIndex string
+
+	// PseudoVersionRetention is how long the documentation of a
+	// pseudo-version is kept after its commit, once its module has a tagged
+	// version. Zero means it is kept forever.
+	PseudoVersionRetention time.Duration
+
+	// CheckpointZipSize is the size, in bytes, above which the worker
+	// saves the packages of a module version as it processes them, so that
+	// a fetch that is interrupted can resume. Zero disables checkpoints.
+	CheckpointZipSize int64
+
+	// FetchSkipPatterns are added to fetch.SkipPatterns, to skip more of
+	// the files of modules when processing them.
+	FetchSkipPatterns []string
+
+	// LargeFileSize is the size, in bytes, above which a file in a module
+	// zip is flagged as large. It sets fetch.LargeFileSize.
+	LargeFileSize int64
+
+	// RobotsDisallow, if non-nil, are the paths that the frontend's
+	// robots.txt asks crawlers not to visit, instead of the defaults.
+	RobotsDisallow []string
+
+	// ExternalSignals are the names of the external signal providers that
+	// the worker refreshes and the frontend shows, like "github-stars"; see
+	// package signals. GitHubToken, if set, authenticates the requests of
+	// the providers to the GitHub API.
+	ExternalSignals []string
+	GitHubToken     string
+
+	// RepositoryMetadata is whether the worker gets the metadata of the
+	// repositories of modules from GitHub and GitLab, like whether they are
+	// archived. GitLabToken, if set, authenticates the requests to the
+	// GitLab API, as GitHubToken does for GitHub.
+	RepositoryMetadata bool
+	GitLabToken        string
 }
 
 // AppVersionLabel returns the version label for the current instance.  This is
@@ -126,6 +257,9 @@ func (c *Config) OnAppEngine() bool {
 	return c.GaeEnv == "standard"
 }
 
+// IndexOff is the value of IndexURL that disables polling the module index.
+const IndexOff = "off"
+
 // StatementTimeout is the value of the Postgres statement_timeout parameter.
 // Statements that run longer than this are terminated.
 // 10 minutes is the App Engine standard request timeout.
@@ -159,6 +293,16 @@ func (c *Config) DBSecondaryConnInfo() string {
 	return c.dbConnInfo(c.DBSecondaryHost)
 }
 
+// DBReplicaConnInfo returns a PostgreSQL connection string constructed from
+// environment variables, using the read-only replica host. It returns the
+// empty string if no replica is configured.
+func (c *Config) DBReplicaConnInfo() string {
+	if c.DBReplicaHost == "" {
+		return ""
+	}
+	return c.dbConnInfo(c.DBReplicaHost)
+}
+
 // dbConnInfo returns a PostgresSQL connection string for the given host.
 func (c *Config) dbConnInfo(host string) string {
 	// For the connection string syntax, see
@@ -193,6 +337,15 @@ type configOverride struct {
 	DBSecondaryHost string
 	DBName          string
 	Quota           QuotaSettings
+	CachePolicies   map[string]CachePolicy
+}
+
+// CachePolicy is config for internal/middleware/cachecontrol.go: the values
+// of the Cache-Control and Surrogate-Control headers of responses for a class
+// of routes. Empty values leave the header unset.
+type CachePolicy struct {
+	CacheControl     string
+	SurrogateControl string
 }
 
 // QuotaSettings is config for internal/middleware/quota.go
@@ -206,6 +359,92 @@ type QuotaSettings struct {
 	// AcceptedURLs is the list of URLs that will be ignored by the quota
 	// middleware.
 	AcceptedURLs []string
+	// ExpensiveRoutes are the rate limits of the routes that are expensive
+	// to serve or to abuse, by route: a path, like "/search", or a tab, like
+	// "tab=importedby". Each route is tracked separately from other requests
+	// from the same IP block. Zero fields of a RouteQuota default to
+	// ExpensiveQPS and ExpensiveBurst.
+	ExpensiveRoutes map[string]RouteQuota
+	// ExpensiveQPS and ExpensiveBurst are the default rate and burst of
+	// ExpensiveRoutes. If they are zero, QPS and Burst are used.
+	ExpensiveQPS   int
+	ExpensiveBurst int
+	// AllowedUserAgents is the list of substrings of User-Agent headers
+	// belonging to known good crawlers. Requests from those user agents are
+	// not rate limited, except on ExpensiveRoutes: the header is easily
+	// spoofed.
+	AllowedUserAgents []string
+	// APIKeyTiers are the rate limits of requests made with API keys, by the
+	// name of the tier of the key. Such requests are limited per key instead
+	// of per IP block; see middleware.APIKeys.
+	APIKeyTiers map[string]APIKeyTier
+}
+
+// RouteQuota is the rate limit of an expensive route, per IP block.
+type RouteQuota struct {
+	QPS   int // allowed queries per second
+	Burst int // the size of the token bucket
+}
+
+// APIKeyTier is the rate limit of the API keys in a quota tier.
+type APIKeyTier struct {
+	QPS   int // allowed queries per second, per key
+	Burst int // the size of the token bucket
+}
+
+// MailSettings is config for sending email through an SMTP server.
+type MailSettings struct {
+	// SMTPAddr is the host:port of the SMTP server.
+	SMTPAddr     string
+	SMTPUser     string
+	SMTPPassword string `json:"-"`
+	// From is the address that email is sent from.
+	From string
+	// SiteURL is the URL of the frontend, for links in email.
+	SiteURL string
+}
+
+// CORSSettings is config for internal/middleware/cors.go.
+type CORSSettings struct {
+	// AllowedOrigins are the origins, like https://example.com, of the pages
+	// that may call the API. "*" allows all origins. If it is empty, CORS
+	// requests are not allowed.
+	AllowedOrigins []string
+	// AllowedMethods are the methods that CORS requests may use.
+	AllowedMethods []string
+	// MaxAge is how long, in seconds, browsers may cache the response to a
+	// preflight request.
+	MaxAge int
+}
+
+// AuthSettings is config for internal/middleware/authenticate.go.
+type AuthSettings struct {
+	// OIDCIssuer is the URL of the OpenID Connect provider that users sign
+	// in with.
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCClientSecret string `json:"-"`
+	// OIDCRedirectURL is the URL of the frontend that the provider
+	// redirects to after sign-in.
+	OIDCRedirectURL string
+	// OIDCGroupsClaim is the ID token claim that lists the groups of a user.
+	OIDCGroupsClaim string
+	// SessionKey is the key used to sign session cookies, and the cookies
+	// holding the preferences of users. Users can only set preferences if it
+	// is set.
+	SessionKey string `json:"-"`
+	// AllowedGroups is the list of groups whose members may use the site. If
+	// it is empty, any signed-in user may.
+	AllowedGroups []string
+	// ACLFile is the path of a JSON file restricting which groups may see
+	// which modules; see auth.ReadACL.
+	ACLFile string
+	// AdminGroups is the list of groups whose members may use the moderation
+	// dashboard of the worker. AdminRedirectURL is the URL of the worker
+	// that the provider redirects to after an administrator signs in; its
+	// path must be under /admin/.
+	AdminGroups      []string
+	AdminRedirectURL string
 }
 
 var cfg Config
@@ -236,10 +475,25 @@ func load(ctx context.Context) (_ *Config, err error) {
 	cfg := &Config{}
 
 	// Resolve client/server configuration from the environment.
+	// IndexURL may be IndexOff, and ProxyURL a file:// URL, so that the
+	// worker can run without network access.
 	cfg.IndexURL = GetEnv("GO_MODULE_INDEX_URL", "https://index.golang.org/index")
 	cfg.ProxyURL = GetEnv("GO_MODULE_PROXY_URL", "https://proxy.golang.org")
+	cfg.ProxyRoutes = os.Getenv("GO_MODULE_PROXY_ROUTES")
+	cfg.ProxyHeader = os.Getenv("GO_MODULE_PROXY_HEADER")
+	cfg.SumDB = GetEnv("GO_MODULE_SUMDB", "sum.golang.org")
+	cfg.NoSumCheck = os.Getenv("GO_MODULE_NOSUMCHECK")
+	if f := os.Getenv("GO_MODULE_PROXY_NETRC"); f != "" {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("reading GO_MODULE_PROXY_NETRC: %v", err)
+		}
+		cfg.ProxyNetrc = string(data)
+	}
+	cfg.DisableSourceLookups = os.Getenv("GO_DISCOVERY_DISABLE_SOURCE_LOOKUPS") == "TRUE"
 	cfg.Port = os.Getenv("PORT")
 	cfg.DebugPort = os.Getenv("DEBUG_PORT")
+	cfg.GRPCPort = os.Getenv("GO_DISCOVERY_GRPC_PORT")
 
 	// Resolve AppEngine identifiers
 	cfg.ProjectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
@@ -282,6 +536,23 @@ func load(ctx context.Context) (_ *Config, err error) {
 		panic("DBHost is empty; impossible")
 	}
 	cfg.DBSecondaryHost = chooseOne(os.Getenv("GO_DISCOVERY_DATABASE_SECONDARY_HOST"))
+	cfg.DBReplicaHost = os.Getenv("GO_DISCOVERY_DATABASE_REPLICA_HOST")
+	cfg.DBReplicaMaxLag = 5 * time.Second
+	if s := os.Getenv("GO_DISCOVERY_DATABASE_REPLICA_MAX_LAG_SECONDS"); s != "" {
+		secs, err := strconv.Atoi(s)
+		if err != nil || secs <= 0 {
+			return nil, fmt.Errorf("GO_DISCOVERY_DATABASE_REPLICA_MAX_LAG_SECONDS must be a positive integer; got %q", s)
+		}
+		cfg.DBReplicaMaxLag = time.Duration(secs) * time.Second
+	}
+	cfg.SlowQueryThreshold = time.Second
+	if s := os.Getenv("GO_DISCOVERY_SLOW_QUERY_THRESHOLD_MS"); s != "" {
+		ms, err := strconv.Atoi(s)
+		if err != nil || ms < 0 {
+			return nil, fmt.Errorf("GO_DISCOVERY_SLOW_QUERY_THRESHOLD_MS must be a non-negative integer; got %q", s)
+		}
+		cfg.SlowQueryThreshold = time.Duration(ms) * time.Millisecond
+	}
 	cfg.DBPort = GetEnv("GO_DISCOVERY_DATABASE_PORT", "5432")
 	cfg.DBName = GetEnv("GO_DISCOVERY_DATABASE_NAME", "discovery-db")
 	cfg.DBSecret = os.Getenv("GO_DISCOVERY_DATABASE_SECRET")
@@ -299,13 +570,125 @@ func load(ctx context.Context) (_ *Config, err error) {
 	cfg.RedisHAHost = os.Getenv("GO_DISCOVERY_REDIS_HA_HOST")
 	cfg.RedisHAPort = GetEnv("GO_DISCOVERY_REDIS_HA_PORT", "6379")
 	cfg.Quota = QuotaSettings{
-		QPS:          10,
-		Burst:        20,
-		MaxEntries:   1000,
-		RecordOnly:   func() *bool { t := true; return &t }(),
-		AcceptedURLs: parseCommaList(GetEnv("GO_DISCOVERY_ACCEPTED_LIST", "")),
+		QPS:            10,
+		Burst:          20,
+		MaxEntries:     1000,
+		RecordOnly:     func() *bool { t := true; return &t }(),
+		AcceptedURLs:   parseCommaList(GetEnv("GO_DISCOVERY_ACCEPTED_LIST", "")),
+		ExpensiveQPS:   2,
+		ExpensiveBurst: 5,
+		ExpensiveRoutes: map[string]RouteQuota{
+			"/search":        {},
+			"/report":        {QPS: 1, Burst: 2},
+			"/api/v1/deps":   {QPS: 1, Burst: 2},
+			"tab=importedby": {},
+		},
+		AllowedUserAgents: parseCommaList(GetEnv("GO_DISCOVERY_QUOTA_ALLOWED_USER_AGENTS",
+			"Googlebot,bingbot,DuckDuckBot")),
+		APIKeyTiers: map[string]APIKeyTier{
+			"standard": {QPS: 20, Burst: 40},
+			"high":     {QPS: 100, Burst: 200},
+		},
+	}
+	cfg.CachePolicies = map[string]CachePolicy{
+		"static": {CacheControl: "public, max-age=3600"},
+		"latest": {CacheControl: "public, max-age=600", SurrogateControl: "max-age=600"},
+		"pinned": {CacheControl: "public, max-age=86400", SurrogateControl: "max-age=86400"},
+		"search": {CacheControl: "public, max-age=600", SurrogateControl: "max-age=600"},
+	}
+	cfg.CORS = CORSSettings{
+		AllowedOrigins: parseCommaList(os.Getenv("GO_DISCOVERY_CORS_ALLOWED_ORIGINS")),
+		AllowedMethods: parseCommaList(GetEnv("GO_DISCOVERY_CORS_ALLOWED_METHODS", "GET,POST")),
+		MaxAge:         3600,
+	}
+	if s := os.Getenv("GO_DISCOVERY_CORS_MAX_AGE"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("GO_DISCOVERY_CORS_MAX_AGE must be a non-negative integer; got %q", s)
+		}
+		cfg.CORS.MaxAge = n
+	}
+	cfg.Auth = AuthSettings{
+		OIDCIssuer:       os.Getenv("GO_DISCOVERY_OIDC_ISSUER"),
+		OIDCClientID:     os.Getenv("GO_DISCOVERY_OIDC_CLIENT_ID"),
+		OIDCClientSecret: os.Getenv("GO_DISCOVERY_OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:  os.Getenv("GO_DISCOVERY_OIDC_REDIRECT_URL"),
+		OIDCGroupsClaim:  GetEnv("GO_DISCOVERY_OIDC_GROUPS_CLAIM", "groups"),
+		SessionKey:       os.Getenv("GO_DISCOVERY_SESSION_KEY"),
+		AllowedGroups:    parseCommaList(os.Getenv("GO_DISCOVERY_AUTH_ALLOWED_GROUPS")),
+		ACLFile:          os.Getenv("GO_DISCOVERY_ACL_FILE"),
+		AdminGroups:      parseCommaList(os.Getenv("GO_DISCOVERY_ADMIN_GROUPS")),
+		AdminRedirectURL: os.Getenv("GO_DISCOVERY_ADMIN_OIDC_REDIRECT_URL"),
+	}
+	cfg.Mail = MailSettings{
+		SMTPAddr:     os.Getenv("GO_DISCOVERY_SMTP_ADDR"),
+		SMTPUser:     os.Getenv("GO_DISCOVERY_SMTP_USER"),
+		SMTPPassword: os.Getenv("GO_DISCOVERY_SMTP_PASSWORD"),
+		From:         os.Getenv("GO_DISCOVERY_MAIL_FROM"),
+		SiteURL:      GetEnv("GO_DISCOVERY_SITE_URL", "https://pkg.go.dev"),
+	}
+	cfg.BrandingFile = os.Getenv("GO_DISCOVERY_BRANDING_FILE")
+	cfg.WebhooksFile = os.Getenv("GO_DISCOVERY_WEBHOOKS_FILE")
+	cfg.ElasticsearchURL = os.Getenv("GO_DISCOVERY_ELASTICSEARCH_URL")
+	cfg.ElasticsearchIndex = GetEnv("GO_DISCOVERY_ELASTICSEARCH_INDEX", "pkgsite")
+	if d := os.Getenv("GO_DISCOVERY_PSEUDO_VERSION_RETENTION_DAYS"); d != "" {
+		days, err := strconv.Atoi(d)
+		if err != nil || days < 0 {
+			return nil, fmt.Errorf("GO_DISCOVERY_PSEUDO_VERSION_RETENTION_DAYS must be a non-negative integer; got %q", d)
+		}
+		cfg.PseudoVersionRetention = time.Duration(days) * 24 * time.Hour
+	}
+	cfg.CheckpointZipSize = 100 * megabyte
+	if s := os.Getenv("GO_DISCOVERY_CHECKPOINT_ZIP_SIZE_MB"); s != "" {
+		mb, err := strconv.Atoi(s)
+		if err != nil || mb < 0 {
+			return nil, fmt.Errorf("GO_DISCOVERY_CHECKPOINT_ZIP_SIZE_MB must be a non-negative integer; got %q", s)
+		}
+		cfg.CheckpointZipSize = int64(mb) * megabyte
+	}
+	cfg.FetchSkipPatterns = parseCommaList(os.Getenv("GO_DISCOVERY_FETCH_SKIP_PATTERNS"))
+	cfg.RobotsDisallow = parseCommaList(os.Getenv("GO_DISCOVERY_ROBOTS_DISALLOW"))
+	cfg.ExternalSignals = parseCommaList(os.Getenv("GO_DISCOVERY_EXTERNAL_SIGNALS"))
+	cfg.GitHubToken = os.Getenv("GO_DISCOVERY_GITHUB_TOKEN")
+	cfg.RepositoryMetadata = os.Getenv("GO_DISCOVERY_REPOSITORY_METADATA") == "TRUE"
+	cfg.GitLabToken = os.Getenv("GO_DISCOVERY_GITLAB_TOKEN")
+	cfg.LargeFileSize = 10 * megabyte
+	if s := os.Getenv("GO_DISCOVERY_LARGE_FILE_SIZE_MB"); s != "" {
+		mb, err := strconv.Atoi(s)
+		if err != nil || mb <= 0 {
+			return nil, fmt.Errorf("GO_DISCOVERY_LARGE_FILE_SIZE_MB must be a positive integer; got %q", s)
+		}
+		cfg.LargeFileSize = int64(mb) * megabyte
 	}
 	cfg.UseProfiler = os.Getenv("GO_DISCOVERY_USE_PROFILER") == "TRUE"
+	cfg.OTLPEndpoint = os.Getenv("GO_DISCOVERY_OTLP_ENDPOINT")
+	cfg.TraceSampleRate = 0.01
+	if r := os.Getenv("GO_DISCOVERY_TRACE_SAMPLE_RATE"); r != "" {
+		rate, err := strconv.ParseFloat(r, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("GO_DISCOVERY_TRACE_SAMPLE_RATE must be a number between 0 and 1; got %q", r)
+		}
+		cfg.TraceSampleRate = rate
+	}
+	cfg.LoadShedMaxInFlight = 50
+	if s := os.Getenv("GO_DISCOVERY_LOAD_SHED_MAX_IN_FLIGHT"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("GO_DISCOVERY_LOAD_SHED_MAX_IN_FLIGHT must be a positive integer; got %q", s)
+		}
+		cfg.LoadShedMaxInFlight = n
+	}
+	cfg.LoadShedMaxDBWait = 500 * time.Millisecond
+	if s := os.Getenv("GO_DISCOVERY_LOAD_SHED_MAX_DB_WAIT_MS"); s != "" {
+		ms, err := strconv.Atoi(s)
+		if err != nil || ms <= 0 {
+			return nil, fmt.Errorf("GO_DISCOVERY_LOAD_SHED_MAX_DB_WAIT_MS must be a positive integer; got %q", s)
+		}
+		cfg.LoadShedMaxDBWait = time.Duration(ms) * time.Millisecond
+	}
+	cfg.SentryDSN = os.Getenv("GO_DISCOVERY_SENTRY_DSN")
+	cfg.CDNServiceURL = os.Getenv("GO_DISCOVERY_CDN_SERVICE_URL")
+	cfg.CDNAPIKey = os.Getenv("GO_DISCOVERY_CDN_API_KEY")
 
 	// If GO_DISCOVERY_CONFIG_OVERRIDE is set, it should point to a file
 	// in overrideBucket which provides overrides for selected configuration.
@@ -353,6 +736,29 @@ func processOverrides(cfg *Config, bytes []byte) {
 	overrideInt("Quota.Burst", &cfg.Quota.Burst, ov.Quota.Burst)
 	overrideInt("Quota.MaxEntries", &cfg.Quota.MaxEntries, ov.Quota.MaxEntries)
 	overrideBool("Quota.RecordOnly", &cfg.Quota.RecordOnly, ov.Quota.RecordOnly)
+	overrideInt("Quota.ExpensiveQPS", &cfg.Quota.ExpensiveQPS, ov.Quota.ExpensiveQPS)
+	overrideInt("Quota.ExpensiveBurst", &cfg.Quota.ExpensiveBurst, ov.Quota.ExpensiveBurst)
+	if len(ov.Quota.APIKeyTiers) > 0 && cfg.Quota.APIKeyTiers == nil {
+		cfg.Quota.APIKeyTiers = map[string]APIKeyTier{}
+	}
+	for tier, t := range ov.Quota.APIKeyTiers {
+		cfg.Quota.APIKeyTiers[tier] = t
+		log.Printf("overriding Quota.APIKeyTiers[%q] with %+v", tier, t)
+	}
+	if len(ov.Quota.ExpensiveRoutes) > 0 && cfg.Quota.ExpensiveRoutes == nil {
+		cfg.Quota.ExpensiveRoutes = map[string]RouteQuota{}
+	}
+	for route, q := range ov.Quota.ExpensiveRoutes {
+		cfg.Quota.ExpensiveRoutes[route] = q
+		log.Printf("overriding Quota.ExpensiveRoutes[%q] with %+v", route, q)
+	}
+	if len(ov.CachePolicies) > 0 && cfg.CachePolicies == nil {
+		cfg.CachePolicies = map[string]CachePolicy{}
+	}
+	for class, p := range ov.CachePolicies {
+		cfg.CachePolicies[class] = p
+		log.Printf("overriding CachePolicies[%q] with %+v", class, p)
+	}
 }
 
 func overrideString(name string, field *string, val string) {
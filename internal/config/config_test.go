@@ -59,19 +59,36 @@ func TestProcessOverrides(t *testing.T) {
 		DBHost: "origHost",
 		DBName: "origName",
 		Quota:  QuotaSettings{QPS: 1, Burst: 2, MaxEntries: 3, RecordOnly: &tr},
+		CachePolicies: map[string]CachePolicy{
+			"static": {CacheControl: "public, max-age=3600"},
+			"search": {CacheControl: "public, max-age=600"},
+		},
 	}
 	ov := `
         DBHost: newHost
         Quota:
            MaxEntries: 17
            RecordOnly: false
+           APIKeyTiers:
+              high:
+                 QPS: 50
+                 Burst: 100
+        CachePolicies:
+           search:
+              CacheControl: no-store
     `
 	processOverrides(&cfg, []byte(ov))
 	got := cfg
 	want := Config{
 		DBHost: "newHost",
 		DBName: "origName",
-		Quota:  QuotaSettings{QPS: 1, Burst: 2, MaxEntries: 17, RecordOnly: &f},
+		Quota: QuotaSettings{QPS: 1, Burst: 2, MaxEntries: 17, RecordOnly: &f,
+			APIKeyTiers: map[string]APIKeyTier{"high": {QPS: 50, Burst: 100}},
+		},
+		CachePolicies: map[string]CachePolicy{
+			"static": {CacheControl: "public, max-age=3600"},
+			"search": {CacheControl: "no-store"},
+		},
 	}
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Errorf("mismatch (-want, +got):\n%s", diff)
@@ -0,0 +1,95 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+const (
+	// breakerThreshold is the number of consecutive connection failures
+	// after which the breaker opens.
+	breakerThreshold = 5
+
+	// breakerCooldown is how long the breaker stays open before requests are
+	// allowed through again to probe the database.
+	breakerCooldown = 10 * time.Second
+)
+
+// A breaker is a circuit breaker for the database connection. After
+// breakerThreshold consecutive connection failures, it opens: for
+// breakerCooldown, queries fail immediately with derrors.DBUnavailable instead
+// of each waiting on a dead connection. After that, queries are let through
+// again; the first connection failure reopens the breaker, and the first
+// success closes it.
+type breaker struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func newBreaker() *breaker {
+	return &breaker{now: time.Now}
+}
+
+// allow reports whether a query may be sent to the database.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.now().Before(b.openUntil)
+}
+
+// record updates the state of the breaker with the result of a query.
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !isConnectionError(err) {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= breakerThreshold {
+		b.openUntil = b.now().Add(breakerCooldown)
+	}
+}
+
+// isConnectionError reports whether err indicates that the database could not
+// be reached, as opposed to a problem with a particular query.
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var nerr *net.OpError
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.As(err, &nerr)
+}
+
+// unavailableContext is a context that is already done, with
+// derrors.DBUnavailable as its error. It is used to make methods that do not
+// return an error, like QueryRow, fail without contacting the database; the
+// error is reported when the result is used.
+type unavailableContext struct {
+	context.Context
+}
+
+var closedChan = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+func (unavailableContext) Done() <-chan struct{} { return closedChan }
+func (unavailableContext) Err() error            { return derrors.DBUnavailable }
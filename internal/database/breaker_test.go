@@ -0,0 +1,99 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := &breaker{now: func() time.Time { return now }}
+	connErr := fmt.Errorf("query: %w", driver.ErrBadConn)
+
+	// Query errors don't count.
+	for i := 0; i < 2*breakerThreshold; i++ {
+		b.record(errors.New("syntax error"))
+	}
+	if !b.allow() {
+		t.Fatal("breaker opened after query errors")
+	}
+
+	for i := 0; i < breakerThreshold-1; i++ {
+		b.record(connErr)
+	}
+	if !b.allow() {
+		t.Fatalf("breaker opened after %d connection errors", breakerThreshold-1)
+	}
+	b.record(connErr)
+	if b.allow() {
+		t.Fatalf("breaker not open after %d connection errors", breakerThreshold)
+	}
+
+	// After the cooldown, queries are let through; one more failure reopens
+	// the breaker.
+	now = now.Add(breakerCooldown)
+	if !b.allow() {
+		t.Fatal("breaker still open after cooldown")
+	}
+	b.record(connErr)
+	if b.allow() {
+		t.Fatal("breaker not reopened after failed probe")
+	}
+
+	// A success closes it.
+	now = now.Add(breakerCooldown)
+	b.record(nil)
+	b.record(connErr)
+	if !b.allow() {
+		t.Fatal("breaker open after success")
+	}
+}
+
+func TestQueryRowBreakerOpen(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	db := New(testDB.db)
+	db.breaker.openUntil = time.Now().Add(time.Hour)
+	var n int
+	err := db.QueryRow(ctx, "SELECT 1").Scan(&n)
+	if !errors.Is(err, derrors.DBUnavailable) {
+		t.Errorf("got %v, want %v", err, derrors.DBUnavailable)
+	}
+	if _, err := db.Exec(ctx, "SELECT 1"); !errors.Is(err, derrors.DBUnavailable) {
+		t.Errorf("Exec: got %v, want %v", err, derrors.DBUnavailable)
+	}
+}
+
+func TestQueryRowBreakerRecords(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	// Nothing listens on port 1, so connections are refused.
+	sdb, err := sql.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sdb.Close()
+	db := New(sdb)
+	for i := 0; i < breakerThreshold; i++ {
+		var n int
+		if err := db.QueryRow(ctx, "SELECT 1").Scan(&n); err == nil {
+			t.Fatal("QueryRow succeeded without a database")
+		}
+	}
+	if db.breaker.allow() {
+		t.Error("breaker closed after failures of QueryRow")
+	}
+}
@@ -35,7 +35,10 @@ type DB struct {
 	db         *sql.DB
 	tx         *sql.Tx
 	mu         sync.Mutex
-	maxRetries int // max times a single transaction was retried
+	maxRetries int         // max times a single transaction was retried
+	breaker    *breaker    // shared by a DB and its transactions
+	replica    *replica    // see SetReplica
+	limits     *PoolLimits // set by SetPoolLimits; protected by mu
 }
 
 // Open creates a new DB  for the given connection string.
@@ -55,7 +58,7 @@ func Open(driverName, dbinfo string) (_ *DB, err error) {
 
 // New creates a new DB from a sql.DB.
 func New(db *sql.DB) *DB {
-	return &DB{db: db}
+	return &DB{db: db, breaker: newBreaker()}
 }
 
 func (db *DB) InTransaction() bool {
@@ -68,41 +71,77 @@ func redactPassword(dbinfo string) string {
 	return passwordRegexp.ReplaceAllLiteralString(dbinfo, "password=REDACTED")
 }
 
+// Stats returns statistics about the database connection pool.
+func (db *DB) Stats() sql.DBStats {
+	return db.db.Stats()
+}
+
 // Close closes the database connection.
 func (db *DB) Close() error {
+	if db.replica != nil {
+		if err := db.replica.close(); err != nil {
+			db.db.Close()
+			return err
+		}
+	}
 	return db.db.Close()
 }
 
 // Exec executes a SQL statement.
 func (db *DB) Exec(ctx context.Context, query string, args ...interface{}) (res sql.Result, err error) {
-	defer logQuery(ctx, query, args)(&err)
+	defer db.logQuery(ctx, query, args)(&err)
+	ctx = withQueryDeadline(ctx)
 
+	if !db.breaker.allow() {
+		return nil, derrors.DBUnavailable
+	}
+	defer func() { db.breaker.record(err) }()
 	if db.tx != nil {
 		return db.tx.ExecContext(ctx, query, args...)
 	}
+	db.NoteWrite()
 	return db.db.ExecContext(ctx, query, args...)
 }
 
 // Query runs the DB query.
 func (db *DB) Query(ctx context.Context, query string, args ...interface{}) (_ *sql.Rows, err error) {
-	defer logQuery(ctx, query, args)(&err)
+	defer db.logQuery(ctx, query, args)(&err)
+	ctx = withQueryDeadline(ctx)
+	if !db.breaker.allow() {
+		return nil, derrors.DBUnavailable
+	}
+	defer func() { db.breaker.record(err) }()
 	if db.tx != nil {
 		return db.tx.QueryContext(ctx, query, args...)
 	}
-	return db.db.QueryContext(ctx, query, args...)
+	return db.reader(ctx).QueryContext(ctx, query, args...)
 }
 
 // QueryRow runs the query and returns a single row.
 func (db *DB) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	defer logQuery(ctx, query, args)(nil)
+	defer db.logQuery(ctx, query, args)(nil)
+	ctx = withQueryDeadline(ctx)
+	allowed := db.breaker.allow()
+	if !allowed {
+		// The error is reported by the Scan method of the returned row.
+		ctx = unavailableContext{ctx}
+	}
+	var row *sql.Row
 	if db.tx != nil {
-		return db.tx.QueryRowContext(ctx, query, args...)
+		row = db.tx.QueryRowContext(ctx, query, args...)
+	} else {
+		row = db.reader(ctx).QueryRowContext(ctx, query, args...)
 	}
-	return db.db.QueryRowContext(ctx, query, args...)
+	if allowed {
+		// Errors running the query, like failures to connect, are known
+		// now; only errors reading the row wait for Scan.
+		db.breaker.record(row.Err())
+	}
+	return row
 }
 
 func (db *DB) Prepare(ctx context.Context, query string) (*sql.Stmt, error) {
-	defer logQuery(ctx, "preparing "+query, nil)
+	defer db.logQuery(ctx, "preparing "+query, nil)
 	if db.tx != nil {
 		return db.tx.PrepareContext(ctx, query)
 	}
@@ -183,7 +222,11 @@ func (db *DB) transact(ctx context.Context, opts *sql.TxOptions, txFunc func(*DB
 	if db.InTransaction() {
 		return errors.New("a DB Transact function was called on a DB already in a transaction")
 	}
+	if !db.breaker.allow() {
+		return derrors.DBUnavailable
+	}
 	tx, err := db.db.BeginTx(ctx, opts)
+	db.breaker.record(err)
 	if err != nil {
 		return fmt.Errorf("db.BeginTx(): %w", err)
 	}
@@ -197,11 +240,13 @@ func (db *DB) transact(ctx context.Context, opts *sql.TxOptions, txFunc func(*DB
 			if err = tx.Commit(); err != nil {
 				err = fmt.Errorf("tx.Commit(): %w", err)
 			}
+			db.NoteWrite()
 		}
 	}()
 
 	dbtx := New(db.db)
 	dbtx.tx = tx
+	dbtx.breaker = db.breaker
 	defer logTransaction(ctx, opts)(&err)
 	if err := txFunc(dbtx); err != nil {
 		return fmt.Errorf("txFunc(tx): %w", err)
@@ -260,6 +305,54 @@ func (db *DB) BulkUpsertReturning(ctx context.Context, table string, columns []s
 	return db.BulkInsertReturning(ctx, table, columns, values, conflictAction, returningColumns, scanFunc)
 }
 
+// CopyUpsert is like BulkUpsert, but sends the values with the COPY
+// protocol, which is much faster for many rows. The values are copied into a
+// temporary table, and then upserted from there into table. CopyUpsert must
+// be called inside a transaction.
+func (db *DB) CopyUpsert(ctx context.Context, table string, columns []string, values []interface{}, conflictColumns []string) (err error) {
+	defer derrors.Wrap(&err, "DB.CopyUpsert(ctx, %q, %v, [%d values], %v)",
+		table, columns, len(values), conflictColumns)
+
+	if db.tx == nil {
+		return errors.New("not in a transaction")
+	}
+	if remainder := len(values) % len(columns); remainder != 0 {
+		return fmt.Errorf("modulus of len(values) and len(columns) must be 0: got %d", remainder)
+	}
+	// The temporary table has only the given columns, without their
+	// constraints or defaults. It is dropped at the end of the transaction,
+	// or before it is reused within it.
+	tempTable := "__copy_" + table
+	cols := strings.Join(columns, ", ")
+	if _, err := db.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", tempTable)); err != nil {
+		return err
+	}
+	if _, err := db.Exec(ctx, fmt.Sprintf("CREATE TEMPORARY TABLE %s ON COMMIT DROP AS SELECT %s FROM %s WITH NO DATA",
+		tempTable, cols, table)); err != nil {
+		return err
+	}
+	stmt, err := db.Prepare(ctx, pq.CopyIn(tempTable, columns...))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for i := 0; i < len(values); i += len(columns) {
+		if _, err := stmt.ExecContext(ctx, values[i:i+len(columns)]...); err != nil {
+			return fmt.Errorf("copying values[%d:%d]: %w", i, i+len(columns), err)
+		}
+	}
+	// A final Exec with no arguments flushes the copied rows.
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return err
+	}
+	// Rows are inserted in a consistent order, so that concurrent upserts
+	// lock them in the same order and cannot deadlock.
+	_, err = db.Exec(ctx, fmt.Sprintf("INSERT INTO %s (%s) SELECT %[2]s FROM %s ORDER BY %s %s",
+		table, cols, tempTable, strings.Join(conflictColumns, ", "),
+		buildUpsertConflictAction(columns, conflictColumns)))
+	return err
+}
+
 func (db *DB) bulkInsert(ctx context.Context, table string, columns, returningColumns []string, values []interface{}, conflictAction string, scanFunc func(*sql.Rows) error) (err error) {
 	if remainder := len(values) % len(columns); remainder != 0 {
 		return fmt.Errorf("modulus of len(values) and len(columns) must be 0: got %d", remainder)
@@ -451,7 +544,7 @@ type queryEndLogEntry struct {
 	Error           string `json:",omitempty"`
 }
 
-func logQuery(ctx context.Context, query string, args []interface{}) func(*error) {
+func (db *DB) logQuery(ctx context.Context, query string, args []interface{}) func(*error) {
 	if QueryLoggingDisabled {
 		return func(*error) {}
 	}
@@ -459,6 +552,7 @@ func logQuery(ctx context.Context, query string, args []interface{}) func(*error
 
 	// To make the query more compact and readable, replace newlines with spaces
 	// and collapse adjacent whitespace.
+	fullQuery := query
 	var r []rune
 	for _, c := range query {
 		if c == '\n' {
@@ -482,7 +576,14 @@ func logQuery(ctx context.Context, query string, args []interface{}) func(*error
 	)
 	var argStrings []string
 	for i := 0; i < len(args) && i < maxArgs; i++ {
-		s := fmt.Sprint(args[i])
+		var s string
+		if b, ok := args[i].([]byte); ok {
+			// Byte slices hold compressed or binary data that would be
+			// unreadable, and may be large.
+			s = fmt.Sprintf("[%d bytes]", len(b))
+		} else {
+			s = fmt.Sprint(args[i])
+		}
 		if len(s) > maxArgLen {
 			s = s[:maxArgLen] + "..."
 		}
@@ -497,6 +598,9 @@ func logQuery(ctx context.Context, query string, args []interface{}) func(*error
 	start := time.Now()
 	return func(errp *error) {
 		dur := time.Since(start)
+		if SlowQueryThreshold > 0 && dur > SlowQueryThreshold {
+			db.noteSlowQuery(ctx, fullQuery, query, args, argString, dur)
+		}
 		if errp == nil { // happens with queryRow
 			log.Debugf(ctx, "%s done", uid)
 		} else {
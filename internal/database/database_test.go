@@ -253,6 +253,58 @@ func TestBulkUpsert(t *testing.T) {
 	}
 }
 
+func TestCopyUpsert(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout*3)
+	defer cancel()
+	if _, err := testDB.Exec(ctx, `CREATE TABLE test_copy_upsert (C1 int PRIMARY KEY, C2 int, C3 text[]);`); err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Exec(ctx, `DROP TABLE test_copy_upsert`)
+
+	for _, values := range [][]interface{}{
+		{2, 4, "{a}", 4, 8, nil},                                // First, insert some rows.
+		{1, -1, "{}", 2, -2, "{b,c}", 3, -3, nil, 4, -4, "{d}"}, // Then replace those rows while inserting others.
+	} {
+		err := testDB.Transact(ctx, sql.LevelDefault, func(tx *DB) error {
+			// Upsert twice in the transaction, to check that the temporary
+			// table can be reused.
+			for i := 0; i < 2; i++ {
+				if err := tx.CopyUpsert(ctx, "test_copy_upsert", []string{"C1", "C2", "C3"}, values, []string{"C1"}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []interface{}
+		err = testDB.RunQuery(ctx, `SELECT C1, C2, C3 FROM test_copy_upsert ORDER BY C1`, func(rows *sql.Rows) error {
+			var a, b int
+			var c sql.NullString
+			if err := rows.Scan(&a, &b, &c); err != nil {
+				return err
+			}
+			if c.Valid {
+				got = append(got, a, b, c.String)
+			} else {
+				got = append(got, a, b, nil)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.Equal(got, values) {
+			t.Errorf("%v: got %v, want %v", values, got, values)
+		}
+	}
+
+	if err := testDB.CopyUpsert(ctx, "test_copy_upsert", []string{"C1"}, []interface{}{5}, []string{"C1"}); err == nil {
+		t.Error("CopyUpsert outside a transaction: got nil error, want error")
+	}
+}
+
 func TestBuildUpsertConflictAction(t *testing.T) {
 	got := buildUpsertConflictAction([]string{"a", "b"}, []string{"c", "d"})
 	want := "ON CONFLICT (c, d) DO UPDATE SET a=excluded.a, b=excluded.b"
@@ -433,3 +485,29 @@ func TestTransactSerializable(t *testing.T) {
 	}
 
 }
+
+func TestReader(t *testing.T) {
+	ctx := context.Background()
+	primary, rdb := &sql.DB{}, &sql.DB{}
+	db := &DB{db: primary}
+	if got := db.reader(ctx); got != primary {
+		t.Error("without a replica: reader did not return the primary")
+	}
+
+	db.replica = &replica{db: rdb, maxLag: time.Minute}
+	if got := db.reader(ctx); got != rdb {
+		t.Error("with a replica: reader did not return the replica")
+	}
+	if got := db.reader(WithPrimary(ctx)); got != primary {
+		t.Error("WithPrimary: reader did not return the primary")
+	}
+	db.replica.lagging = 1
+	if got := db.reader(ctx); got != primary {
+		t.Error("lagging replica: reader did not return the primary")
+	}
+	db.replica.lagging = 0
+	db.NoteWrite()
+	if got := db.reader(ctx); got != primary {
+		t.Error("after NoteWrite: reader did not return the primary")
+	}
+}
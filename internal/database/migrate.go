@@ -0,0 +1,209 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	mpostgres "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// Migrate brings the schema of the postgres database described by dbinfo up
+// to date with the migrations in fsys, which are files in the root
+// directory named like those in the migrations directory of this repo. It
+// returns the versions of the migrations that it applied, in order.
+//
+// Migrations are applied while holding a postgres advisory lock, so if
+// several processes call Migrate at once, one applies the migrations and the
+// others wait for it and then find nothing to do.
+//
+// If dryRun is true, Migrate returns the versions of the migrations that it
+// would apply, without applying them.
+func Migrate(dbinfo string, fsys http.FileSystem, dryRun bool) (_ []uint, err error) {
+	defer derrors.Wrap(&err, "Migrate(%q, fsys, %t)", redactPassword(dbinfo), dryRun)
+
+	src, err := newMigrationSource(fsys)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("postgres", dbinfo)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := mpostgres.WithInstance(db, &mpostgres.Config{})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	m, err := migrate.NewWithInstance("fs", src, "postgres", driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	defer func() {
+		if srcErr, dbErr := m.Close(); err == nil {
+			if srcErr != nil {
+				err = srcErr
+			} else {
+				err = dbErr
+			}
+		}
+	}()
+
+	before, err := schemaVersion(m)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return src.versionsAfter(before, ^uint(0)), nil
+	}
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return nil, err
+	}
+	after, err := schemaVersion(m)
+	if err != nil {
+		return nil, err
+	}
+	return src.versionsAfter(before, after), nil
+}
+
+// schemaVersion returns the version of the last migration applied by m, or
+// 0 if none has been. It fails if a migration was left half-applied.
+func schemaVersion(m *migrate.Migrate) (uint, error) {
+	version, dirty, err := m.Version()
+	if err == migrate.ErrNilVersion {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("migration %d failed and must be fixed by hand", version)
+	}
+	return version, nil
+}
+
+// migrationSource is a source.Driver for the migrations in an
+// http.FileSystem. The files are read when it is created.
+type migrationSource struct {
+	migrations *source.Migrations
+	contents   map[string]string // from file name to contents
+}
+
+func newMigrationSource(fsys http.FileSystem) (*migrationSource, error) {
+	dir, err := fsys.Open("/")
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	infos, err := dir.Readdir(-1)
+	if err != nil {
+		return nil, err
+	}
+	s := &migrationSource{
+		migrations: source.NewMigrations(),
+		contents:   map[string]string{},
+	}
+	for _, fi := range infos {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), ".sql") {
+			continue
+		}
+		m, err := source.Parse(fi.Name())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fi.Name(), err)
+		}
+		f, err := fsys.Open(path.Join("/", fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		s.contents[m.Raw] = string(data)
+		if !s.migrations.Append(m) {
+			return nil, fmt.Errorf("%s: duplicate migration", fi.Name())
+		}
+	}
+	if _, ok := s.migrations.First(); !ok {
+		return nil, errors.New("no migrations")
+	}
+	return s, nil
+}
+
+// versionsAfter returns the versions of the up migrations greater than from
+// and at most to.
+func (s *migrationSource) versionsAfter(from, to uint) []uint {
+	var versions []uint
+	v, ok := s.migrations.First()
+	for ok && v <= to {
+		if _, up := s.migrations.Up(v); up && v > from {
+			versions = append(versions, v)
+		}
+		v, ok = s.migrations.Next(v)
+	}
+	return versions
+}
+
+// Open implements source.Driver. It is not used, because a migrationSource
+// is passed to migrate.NewWithInstance.
+func (s *migrationSource) Open(url string) (source.Driver, error) {
+	return nil, errors.New("migrationSource: Open is not supported")
+}
+
+// Close implements source.Driver.
+func (s *migrationSource) Close() error { return nil }
+
+// First implements source.Driver.
+func (s *migrationSource) First() (uint, error) {
+	if v, ok := s.migrations.First(); ok {
+		return v, nil
+	}
+	return 0, &os.PathError{Op: "first", Path: "migrations", Err: os.ErrNotExist}
+}
+
+// Prev implements source.Driver.
+func (s *migrationSource) Prev(version uint) (uint, error) {
+	if v, ok := s.migrations.Prev(version); ok {
+		return v, nil
+	}
+	return 0, &os.PathError{Op: fmt.Sprintf("prev for version %d", version), Path: "migrations", Err: os.ErrNotExist}
+}
+
+// Next implements source.Driver.
+func (s *migrationSource) Next(version uint) (uint, error) {
+	if v, ok := s.migrations.Next(version); ok {
+		return v, nil
+	}
+	return 0, &os.PathError{Op: fmt.Sprintf("next for version %d", version), Path: "migrations", Err: os.ErrNotExist}
+}
+
+// ReadUp implements source.Driver.
+func (s *migrationSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	if m, ok := s.migrations.Up(version); ok {
+		return ioutil.NopCloser(strings.NewReader(s.contents[m.Raw])), m.Identifier, nil
+	}
+	return nil, "", &os.PathError{Op: fmt.Sprintf("read up version %d", version), Path: "migrations", Err: os.ErrNotExist}
+}
+
+// ReadDown implements source.Driver.
+func (s *migrationSource) ReadDown(version uint) (io.ReadCloser, string, error) {
+	if m, ok := s.migrations.Down(version); ok {
+		return ioutil.NopCloser(strings.NewReader(s.contents[m.Raw])), m.Identifier, nil
+	}
+	return nil, "", &os.PathError{Op: fmt.Sprintf("read down version %d", version), Path: "migrations", Err: os.ErrNotExist}
+}
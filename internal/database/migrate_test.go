@@ -0,0 +1,67 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMigrationSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, contents := range map[string]string{
+		"000001_a.up.sql":   "up 1",
+		"000001_a.down.sql": "down 1",
+		"000002_b.up.sql":   "up 2",
+		"000002_b.down.sql": "down 2",
+		"000004_c.up.sql":   "up 4",
+		"README":            "ignored",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, err := newMigrationSource(http.Dir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := s.Next(2); err != nil || got != 4 {
+		t.Errorf("Next(2) = %d, %v; want 4, nil", got, err)
+	}
+	if _, err := s.Next(4); !os.IsNotExist(err) {
+		t.Errorf("Next(4): got %v, want not-exist error", err)
+	}
+	r, id, err := s.ReadUp(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "up 2" || id != "b" {
+		t.Errorf("ReadUp(2) = %q, %q; want %q, %q", got, id, "up 2", "b")
+	}
+	if _, _, err := s.ReadDown(4); !os.IsNotExist(err) {
+		t.Errorf("ReadDown(4): got %v, want not-exist error", err)
+	}
+
+	if diff := cmp.Diff([]uint{2, 4}, s.versionsAfter(1, 10)); diff != "" {
+		t.Errorf("versionsAfter(1, 10) mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]uint{1, 2}, s.versionsAfter(0, 3)); diff != "" {
+		t.Errorf("versionsAfter(0, 3) mismatch (-want +got):\n%s", diff)
+	}
+}
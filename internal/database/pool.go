@@ -0,0 +1,223 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// PoolLimits are the limits on the connection pool of a DB. They have the
+// meanings of the arguments to the corresponding methods of sql.DB, like
+// SetMaxOpenConns.
+type PoolLimits struct {
+	MaxOpen     int           // zero means no limit
+	MaxIdle     int           // zero or less means no idle connections are kept
+	MaxLifetime time.Duration // zero means connections are reused forever
+}
+
+// defaultMaxIdle is the sql.DB default for the number of idle connections.
+const defaultMaxIdle = 2
+
+// PoolLimits returns the limits on the connection pool of db.
+func (db *DB) PoolLimits() PoolLimits {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.limits == nil {
+		return PoolLimits{MaxIdle: defaultMaxIdle}
+	}
+	return *db.limits
+}
+
+// SetPoolLimits sets the limits on the connection pool of db, and of its
+// replica if it has one. It may be called while db is in use.
+func (db *DB) SetPoolLimits(l PoolLimits) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.limits = &l
+	for _, sdb := range []*sql.DB{db.db, db.replicaDB()} {
+		if sdb == nil {
+			continue
+		}
+		sdb.SetMaxOpenConns(l.MaxOpen)
+		sdb.SetMaxIdleConns(l.MaxIdle)
+		sdb.SetConnMaxLifetime(l.MaxLifetime)
+	}
+}
+
+// replicaDB returns the sql.DB of the replica of db, or nil.
+func (db *DB) replicaDB() *sql.DB {
+	if db.replica == nil {
+		return nil
+	}
+	return db.replica.db
+}
+
+var (
+	keyPool          = tag.MustNewKey("db.pool")
+	keyConnState     = tag.MustNewKey("db.conn_state")
+	poolConnections  = stats.Int64("go-discovery/database/pool_connections", "Connections in the pool.", stats.UnitDimensionless)
+	poolWaitCount    = stats.Int64("go-discovery/database/pool_wait_count", "Total number of waits for a connection.", stats.UnitDimensionless)
+	poolWaitDuration = stats.Float64("go-discovery/database/pool_wait_duration", "Total time spent waiting for a connection.", stats.UnitMilliseconds)
+	poolClosed       = stats.Int64("go-discovery/database/pool_closed", "Total number of connections closed because of a pool limit.", stats.UnitDimensionless)
+
+	// PoolConnections is the number of connections in a pool, by pool
+	// (primary or replica) and state (in_use or idle).
+	PoolConnections = &view.View{
+		Name:        "go-discovery/database/pool_connections",
+		Measure:     poolConnections,
+		Aggregation: view.LastValue(),
+		Description: "connections in the pool, by pool and state",
+		TagKeys:     []tag.Key{keyPool, keyConnState},
+	}
+	// PoolWaitCount is the total number of times a query waited for a
+	// connection, by pool.
+	PoolWaitCount = &view.View{
+		Name:        "go-discovery/database/pool_wait_count",
+		Measure:     poolWaitCount,
+		Aggregation: view.LastValue(),
+		Description: "total waits for a connection, by pool",
+		TagKeys:     []tag.Key{keyPool},
+	}
+	// PoolWaitDuration is the total time spent waiting for a connection, by
+	// pool.
+	PoolWaitDuration = &view.View{
+		Name:        "go-discovery/database/pool_wait_duration",
+		Measure:     poolWaitDuration,
+		Aggregation: view.LastValue(),
+		Description: "total time waiting for a connection, by pool",
+		TagKeys:     []tag.Key{keyPool},
+	}
+	// PoolClosed is the total number of connections closed because of the
+	// idle or lifetime limits, by pool and limit.
+	PoolClosed = &view.View{
+		Name:        "go-discovery/database/pool_closed",
+		Measure:     poolClosed,
+		Aggregation: view.LastValue(),
+		Description: "total connections closed by a pool limit, by pool and limit",
+		TagKeys:     []tag.Key{keyPool, keyConnState},
+	}
+
+	// PoolViews are the views of connection pool statistics recorded by
+	// RecordPoolStats.
+	PoolViews = []*view.View{PoolConnections, PoolWaitCount, PoolWaitDuration, PoolClosed}
+)
+
+// RecordPoolStats records statistics about the connection pools of db every
+// interval, until ctx is done.
+func (db *DB) RecordPoolStats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		recordPoolStats(ctx, "primary", db.db.Stats())
+		if rdb := db.replicaDB(); rdb != nil {
+			recordPoolStats(ctx, "replica", rdb.Stats())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func recordPoolStats(ctx context.Context, pool string, s sql.DBStats) {
+	record := func(state string, m stats.Measurement) {
+		mutators := []tag.Mutator{tag.Upsert(keyPool, pool)}
+		if state != "" {
+			mutators = append(mutators, tag.Upsert(keyConnState, state))
+		}
+		if err := stats.RecordWithTags(ctx, mutators, m); err != nil {
+			log.Errorf(ctx, "recording pool stats: %v", err)
+		}
+	}
+	record("in_use", poolConnections.M(int64(s.InUse)))
+	record("idle", poolConnections.M(int64(s.Idle)))
+	record("", poolWaitCount.M(s.WaitCount))
+	record("", poolWaitDuration.M(float64(s.WaitDuration)/float64(time.Millisecond)))
+	record("max_idle", poolClosed.M(s.MaxIdleClosed))
+	record("max_lifetime", poolClosed.M(s.MaxLifetimeClosed))
+}
+
+// PoolStatus describes the connection pools of a DB.
+type PoolStatus struct {
+	Limits  PoolLimits
+	Primary sql.DBStats
+	Replica *sql.DBStats `json:",omitempty"`
+}
+
+// PoolStatus returns the limits and statistics of the connection pools of db.
+func (db *DB) PoolStatus() PoolStatus {
+	ps := PoolStatus{Limits: db.PoolLimits(), Primary: db.db.Stats()}
+	if rdb := db.replicaDB(); rdb != nil {
+		s := rdb.Stats()
+		ps.Replica = &s
+	}
+	return ps
+}
+
+// PoolHandler returns a handler that writes the PoolStatus of db as JSON.
+// For a POST request, it first changes the pool limits given by the form
+// values max_open, max_idle and max_lifetime (a duration like "30m"), so that
+// the pool can be tuned without restarting the server. It should only be
+// served to operators.
+func PoolHandler(db *DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			l, err := parsePoolLimits(r, db.PoolLimits())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			db.SetPoolLimits(l)
+			log.Infof(r.Context(), "set database pool limits to %+v", l)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(db.PoolStatus()); err != nil {
+			log.Error(r.Context(), err)
+		}
+	})
+}
+
+// parsePoolLimits returns l, changed by the limits in the form values of r.
+func parsePoolLimits(r *http.Request, l PoolLimits) (_ PoolLimits, err error) {
+	defer derrors.Wrap(&err, "parsePoolLimits")
+	for _, name := range []string{"max_open", "max_idle"} {
+		v := r.FormValue(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return l, fmt.Errorf("%s must be a non-negative integer; got %q", name, v)
+		}
+		if name == "max_open" {
+			l.MaxOpen = n
+		} else {
+			l.MaxIdle = n
+		}
+	}
+	if v := r.FormValue("max_lifetime"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return l, fmt.Errorf("max_lifetime must be a non-negative duration; got %q", v)
+		}
+		l.MaxLifetime = d
+	}
+	return l, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePoolLimits(t *testing.T) {
+	start := PoolLimits{MaxOpen: 10, MaxIdle: 2}
+	for _, test := range []struct {
+		form    string
+		want    PoolLimits
+		wantErr bool
+	}{
+		{"", start, false},
+		{"max_open=20", PoolLimits{MaxOpen: 20, MaxIdle: 2}, false},
+		{"max_idle=0&max_lifetime=30m", PoolLimits{MaxOpen: 10, MaxIdle: 0, MaxLifetime: 30 * time.Minute}, false},
+		{"max_open=-1", start, true},
+		{"max_idle=many", start, true},
+		{"max_lifetime=30", start, true},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/?"+test.form, nil)
+		got, err := parsePoolLimits(r, start)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parsePoolLimits(%q): got error %v, want error: %t", test.form, err, test.wantErr)
+			continue
+		}
+		if err == nil && got != test.want {
+			t.Errorf("parsePoolLimits(%q) = %+v, want %+v", test.form, got, test.want)
+		}
+	}
+}
+
+func TestPoolHandler(t *testing.T) {
+	defer testDB.SetPoolLimits(testDB.PoolLimits())
+
+	form := url.Values{"max_open": {"7"}, "max_idle": {"3"}}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	PoolHandler(testDB).ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	var got PoolStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := PoolLimits{MaxOpen: 7, MaxIdle: 3}
+	if got.Limits != want {
+		t.Errorf("got limits %+v, want %+v", got.Limits, want)
+	}
+	if got.Primary.MaxOpenConnections != 7 {
+		t.Errorf("got MaxOpenConnections = %d, want 7", got.Primary.MaxOpenConnections)
+	}
+}
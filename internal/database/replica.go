@@ -0,0 +1,138 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// A replica is a read-only copy of the database, kept up to date by
+// streaming replication.
+type replica struct {
+	db     *sql.DB
+	maxLag time.Duration
+	done   chan struct{}
+
+	// lagging is 1 if the replica was more than maxLag behind the primary,
+	// or could not be reached, at the last check.
+	lagging int32
+	// primaryUntil is the time, in Unix nanoseconds, until which reads go to
+	// the primary because of a recent write.
+	primaryUntil int64
+}
+
+// SetReplica makes db send queries made outside a transaction to the
+// read-only replica described by dbinfo, while the replica is at most maxLag
+// behind the primary. Statements that write, and everything in a
+// transaction, still go to the primary.
+//
+// So that recent writes are visible, queries also go to the primary for
+// maxLag after a write through db, or a call to NoteWrite, and always for
+// contexts returned by WithPrimary.
+//
+// SetReplica must be called before db is used.
+func (db *DB) SetReplica(ctx context.Context, driverName, dbinfo string, maxLag time.Duration) (err error) {
+	defer derrors.Wrap(&err, "SetReplica(ctx, %q, %q, %s)", driverName, redactPassword(dbinfo), maxLag)
+
+	rdb, err := sql.Open(driverName, dbinfo)
+	if err != nil {
+		return err
+	}
+	if err := rdb.PingContext(ctx); err != nil {
+		rdb.Close()
+		return err
+	}
+	db.replica = &replica{db: rdb, maxLag: maxLag, done: make(chan struct{})}
+	db.replica.checkLag(ctx)
+	go db.replica.monitor(ctx)
+	return nil
+}
+
+// replicaLagQuery returns how far the replica is behind the primary, in
+// seconds. The time of the last replayed transaction only measures the lag
+// while there is WAL left to replay; otherwise the replica is up to date,
+// however long ago that transaction was.
+const replicaLagQuery = `
+	SELECT CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END`
+
+// monitor checks the lag of the replica regularly, until r is closed.
+func (r *replica) monitor(ctx context.Context) {
+	interval := r.maxLag / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.checkLag(ctx)
+		}
+	}
+}
+
+func (r *replica) checkLag(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, r.maxLag)
+	defer cancel()
+	var lag float64
+	lagging := int32(0)
+	if err := r.db.QueryRowContext(ctx, replicaLagQuery).Scan(&lag); err != nil {
+		log.Errorf(ctx, "checking replica lag: %v", err)
+		lagging = 1
+	} else if time.Duration(lag*float64(time.Second)) > r.maxLag {
+		lagging = 1
+	}
+	if old := atomic.SwapInt32(&r.lagging, lagging); old != lagging {
+		if lagging == 1 {
+			log.Errorf(ctx, "replica is more than %s behind; reading from the primary", r.maxLag)
+		} else {
+			log.Info(ctx, "replica has caught up; reading from it")
+		}
+	}
+}
+
+func (r *replica) close() error {
+	close(r.done)
+	return r.db.Close()
+}
+
+// NoteWrite makes db read from the primary for the maximum replica lag, so
+// that a write made through another connection to the primary, like one by
+// the worker, is visible. It does nothing if db has no replica.
+func (db *DB) NoteWrite() {
+	if db.replica == nil {
+		return
+	}
+	atomic.StoreInt64(&db.replica.primaryUntil, time.Now().Add(db.replica.maxLag).UnixNano())
+}
+
+type primaryKey struct{}
+
+// WithPrimary returns a context that makes queries read from the primary, even
+// if there is a replica.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// reader returns the database to send a query outside a transaction to.
+func (db *DB) reader(ctx context.Context) *sql.DB {
+	r := db.replica
+	if r == nil || ctx.Value(primaryKey{}) != nil || atomic.LoadInt32(&r.lagging) == 1 ||
+		time.Now().UnixNano() < atomic.LoadInt64(&r.primaryUntil) {
+		return db.db
+	}
+	return r.db
+}
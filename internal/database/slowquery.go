@@ -0,0 +1,173 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+type queryTimeoutKey struct{}
+
+// WithQueryTimeout returns a context in which each query made through a DB
+// is canceled if it takes longer than d. Unlike a deadline on ctx itself,
+// the timeout applies to every query separately, so that a single slow query
+// fails quickly while a request that makes many fast ones succeeds. A d of
+// zero or less means no timeout.
+func WithQueryTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, d)
+}
+
+// withQueryDeadline returns ctx with the deadline set by WithQueryTimeout, if
+// any, for a query starting now.
+func withQueryDeadline(ctx context.Context) context.Context {
+	d, ok := ctx.Value(queryTimeoutKey{}).(time.Duration)
+	if !ok || d <= 0 {
+		return ctx
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	// The rows of a query are read after Query returns, so the context can't
+	// be canceled then. Release it when it times out instead.
+	time.AfterFunc(d, cancel)
+	return ctx
+}
+
+// SlowQueryThreshold is the duration above which a query is considered slow.
+// Slow queries are logged with their arguments and plans, counted in
+// SlowQueryCount, and listed by RecentSlowQueries. Zero disables this.
+// It should be set only at startup.
+var SlowQueryThreshold time.Duration
+
+// A SlowQuery describes a query that took longer than SlowQueryThreshold.
+type SlowQuery struct {
+	Time     time.Time
+	Route    string `json:",omitempty"`
+	Query    string
+	Args     string
+	Duration time.Duration
+	// Plan is the plan of the query, as shown by EXPLAIN, or the error from
+	// getting it. Only queries that read are explained.
+	Plan string `json:",omitempty"`
+}
+
+var (
+	slowQueryLatency = stats.Float64(
+		"go-discovery/database/slow_query_latency",
+		"Latency of a slow database query.",
+		stats.UnitMilliseconds,
+	)
+	// SlowQueryCount counts slow queries, by the route they were made for.
+	SlowQueryCount = &view.View{
+		Name:        "go-discovery/database/slow_query_count",
+		Measure:     slowQueryLatency,
+		Aggregation: view.Count(),
+		Description: "slow database queries, by route",
+		TagKeys:     []tag.Key{ochttp.KeyServerRoute},
+	}
+)
+
+// maxSlowQueries is the number of slow queries kept for RecentSlowQueries.
+const maxSlowQueries = 100
+
+var slowQueries struct {
+	mu      sync.Mutex
+	queries []*SlowQuery // ring buffer
+	next    int          // index of the next query to replace
+}
+
+// RecentSlowQueries returns the most recent slow queries made by this
+// process, newest first.
+func RecentSlowQueries() []SlowQuery {
+	slowQueries.mu.Lock()
+	defer slowQueries.mu.Unlock()
+	var sqs []SlowQuery
+	n := len(slowQueries.queries)
+	for i := 1; i <= n; i++ {
+		sqs = append(sqs, *slowQueries.queries[(slowQueries.next-i+n)%n])
+	}
+	return sqs
+}
+
+func addSlowQuery(sq *SlowQuery) {
+	slowQueries.mu.Lock()
+	defer slowQueries.mu.Unlock()
+	if len(slowQueries.queries) < maxSlowQueries {
+		slowQueries.queries = append(slowQueries.queries, sq)
+	} else {
+		slowQueries.queries[slowQueries.next] = sq
+	}
+	slowQueries.next = (slowQueries.next + 1) % maxSlowQueries
+}
+
+// explainTimeout bounds the time spent getting the plan of a slow query.
+const explainTimeout = 10 * time.Second
+
+// noteSlowQuery records a query that took dur, which is more than
+// SlowQueryThreshold. The query is explained in the background, so that the
+// caller is not slowed down further.
+func (db *DB) noteSlowQuery(ctx context.Context, query, shortQuery string, args []interface{}, argString string, dur time.Duration) {
+	route, _ := tag.FromContext(ctx).Value(ochttp.KeyServerRoute)
+	sq := &SlowQuery{
+		Time:     time.Now(),
+		Route:    route,
+		Query:    shortQuery,
+		Args:     argString,
+		Duration: dur,
+	}
+	stats.Record(ctx, slowQueryLatency.M(float64(dur)/float64(time.Millisecond)))
+	// Queries in a transaction may depend on its earlier statements, like
+	// creating a temporary table, so they can't be explained separately.
+	if db.tx != nil || !isRead(query) {
+		log.Infof(ctx, "slow query (%s, route %q): %s args=%s", dur, route, shortQuery, argString)
+		addSlowQuery(sq)
+		return
+	}
+	go func() {
+		// The query's context may be done by now.
+		ectx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+		defer cancel()
+		sq.Plan = db.explain(ectx, query, args)
+		log.Infof(ctx, "slow query (%s, route %q): %s args=%s\n%s", dur, route, shortQuery, argString, sq.Plan)
+		addSlowQuery(sq)
+	}()
+}
+
+// isRead reports whether query only reads, so that it can be explained
+// without side effects.
+func isRead(query string) bool {
+	q := strings.ToUpper(strings.TrimSpace(query))
+	return strings.HasPrefix(q, "SELECT") || strings.HasPrefix(q, "WITH")
+}
+
+// explain returns the plan of query, or the error from getting it. It does
+// not run the query.
+func (db *DB) explain(ctx context.Context, query string, args []interface{}) string {
+	rows, err := db.db.QueryContext(ctx, "EXPLAIN "+query, args...)
+	if err != nil {
+		return fmt.Sprintf("EXPLAIN failed: %v", err)
+	}
+	defer rows.Close()
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return fmt.Sprintf("EXPLAIN failed: %v", err)
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Sprintf("EXPLAIN failed: %v", err)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithQueryDeadline(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := withQueryDeadline(ctx).Deadline(); ok {
+		t.Error("without a query timeout: got a deadline, want none")
+	}
+	if _, ok := withQueryDeadline(WithQueryTimeout(ctx, 0)).Deadline(); ok {
+		t.Error("with a zero query timeout: got a deadline, want none")
+	}
+	start := time.Now()
+	deadline, ok := withQueryDeadline(WithQueryTimeout(ctx, time.Minute)).Deadline()
+	if !ok {
+		t.Fatal("with a query timeout: got no deadline")
+	}
+	if d := deadline.Sub(start); d <= 0 || d > time.Minute {
+		t.Errorf("with a query timeout of 1m: got deadline %s from now", d)
+	}
+}
+
+func TestRecentSlowQueries(t *testing.T) {
+	for i := 0; i < maxSlowQueries+5; i++ {
+		addSlowQuery(&SlowQuery{Duration: time.Duration(i)})
+	}
+	got := RecentSlowQueries()
+	if len(got) != maxSlowQueries {
+		t.Fatalf("got %d slow queries, want %d", len(got), maxSlowQueries)
+	}
+	for i, sq := range got {
+		if want := time.Duration(maxSlowQueries + 4 - i); sq.Duration != want {
+			t.Fatalf("slow query %d has duration %d, want %d", i, sq.Duration, want)
+		}
+	}
+}
+
+func TestIsRead(t *testing.T) {
+	for _, test := range []struct {
+		query string
+		want  bool
+	}{
+		{"SELECT 1", true},
+		{"\n\t\tselect path FROM packages", true},
+		{"WITH x AS (SELECT 1) SELECT * FROM x", true},
+		{"INSERT INTO t VALUES (1)", false},
+		{"DELETE FROM t", false},
+	} {
+		if got := isRead(test.query); got != test.want {
+			t.Errorf("isRead(%q) = %t, want %t", test.query, got, test.want)
+		}
+	}
+}
@@ -6,7 +6,9 @@ package internal
 
 import (
 	"context"
+	"fmt"
 
+	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/licenses"
 )
 
@@ -52,8 +54,9 @@ type DataSource interface {
 	GetModuleLicenses(ctx context.Context, modulePath, version string) ([]*licenses.License, error)
 	// GetPackage returns the LegacyVersionedPackage corresponding to the given package
 	// pkgPath, modulePath, and version. When multiple package paths satisfy this query, it
-	// should prefer the module with the longest path.
-	GetPackage(ctx context.Context, pkgPath, modulePath, version string) (*LegacyVersionedPackage, error)
+	// should prefer the module with the longest path. Only the large fields
+	// in fields are read.
+	GetPackage(ctx context.Context, pkgPath, modulePath, version string, fields FieldSet) (*LegacyVersionedPackage, error)
 	// GetPackageLicenses returns all Licenses that apply to pkgPath, within the
 	// module version specified by modulePath and version.
 	GetPackageLicenses(ctx context.Context, pkgPath, modulePath, version string) ([]*licenses.License, error)
@@ -61,3 +64,33 @@ type DataSource interface {
 	// specified by modulePath and version.
 	GetPackagesInModule(ctx context.Context, modulePath, version string) ([]*LegacyPackage, error)
 }
+
+// SearchBackend is the interface used by the frontend to search for packages.
+// The default implementation is the postgres database; large deployments can
+// use a separate search engine instead.
+type SearchBackend interface {
+	// Search returns the page of results for the query q with the given limit
+	// and offset, in decreasing order of score. Results past
+	// MaxSearchResults are not returned: an offset at or beyond it is an
+	// error, and a limit that reaches beyond it is reduced.
+	Search(ctx context.Context, q string, limit, offset int) ([]*SearchResult, error)
+}
+
+// MaxSearchResults is the number of search results that can be paged
+// through. Deeper pages are expensive to compute and rarely useful to anyone
+// but crawlers.
+const MaxSearchResults = 100
+
+// CheckSearchPage returns the limit to use for a search with the given limit
+// and offset, reduced so that no result past MaxSearchResults is returned. It
+// returns an error wrapping derrors.InvalidArgument if there is no such
+// limit.
+func CheckSearchPage(limit, offset int) (int, error) {
+	if limit < 1 || offset < 0 || offset >= MaxSearchResults {
+		return 0, fmt.Errorf("limit %d, offset %d: %w", limit, offset, derrors.InvalidArgument)
+	}
+	if offset+limit > MaxSearchResults {
+		limit = MaxSearchResults - offset
+	}
+	return limit, nil
+}
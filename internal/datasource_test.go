@@ -0,0 +1,38 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+func TestCheckSearchPage(t *testing.T) {
+	for _, test := range []struct {
+		limit, offset, want int
+		wantErr             bool
+	}{
+		{10, 0, 10, false},
+		{10, 90, 10, false},
+		{20, 90, 10, false},
+		{10, MaxSearchResults, 0, true},
+		{10, 100000, 0, true},
+		{0, 0, 0, true},
+		{10, -1, 0, true},
+	} {
+		got, err := CheckSearchPage(test.limit, test.offset)
+		if (err != nil) != test.wantErr {
+			t.Fatalf("CheckSearchPage(%d, %d): got error %v, want error: %t", test.limit, test.offset, err, test.wantErr)
+		}
+		if err != nil && !errors.Is(err, derrors.InvalidArgument) {
+			t.Errorf("CheckSearchPage(%d, %d): got error %v, want InvalidArgument", test.limit, test.offset, err)
+		}
+		if got != test.want {
+			t.Errorf("CheckSearchPage(%d, %d) = %d, want %d", test.limit, test.offset, got, test.want)
+		}
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"contrib.go.opencensus.io/exporter/prometheus"
 	"contrib.go.opencensus.io/exporter/stackdriver"
 	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/plugin/ochttp/propagation/tracecontext"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 	"go.opencensus.io/trace"
@@ -47,8 +48,10 @@ func NewRouter(tagger RouteTagger) *Router {
 	}
 	mux := http.NewServeMux()
 	return &Router{
-		mux:     mux,
-		Handler: &ochttp.Handler{Handler: mux},
+		mux: mux,
+		// Accept W3C Trace Context headers, so that traces started by
+		// OpenTelemetry-instrumented callers continue here.
+		Handler: &ochttp.Handler{Handler: mux, Propagation: &tracecontext.HTTPFormat{}},
 		tagger:  tagger,
 	}
 }
@@ -74,19 +77,50 @@ const debugPage = `
 `
 
 // Init configures tracing and aggregation according to the given Views. If
-// running on GCP, Init also configures exporting to StackDriver.
+// running on GCP, Init also configures exporting to StackDriver. If an OTLP
+// endpoint is configured, Init also configures exporting traces there.
 func Init(cfg *config.Config, views ...*view.View) error {
 	// The default trace sampler samples with probability 1e-4. That's too
-	// infrequent for our traffic levels. In the future we may want to decrease
-	// this sampling rate.
-	trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(0.01)})
+	// infrequent for our traffic levels, so we use cfg.TraceSampleRate
+	// instead.
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(cfg.TraceSampleRate)})
 	if err := view.Register(views...); err != nil {
 		return fmt.Errorf("dcensus.Init(views): view.Register: %v", err)
 	}
 	exportToStackdriver(context.Background(), cfg)
+	exportToOTLP(context.Background(), cfg)
 	return nil
 }
 
+// exportToOTLP configures exporting traces to an OpenTelemetry collector, if
+// cfg.OTLPEndpoint is set.
+func exportToOTLP(ctx context.Context, cfg *config.Config) {
+	if cfg.OTLPEndpoint == "" {
+		return
+	}
+	serviceName := cfg.ServiceID
+	if serviceName == "" {
+		serviceName = "pkgsite"
+	}
+	log.Infof(ctx, "Exporting traces to OTLP endpoint %s", cfg.OTLPEndpoint)
+	otlpExporter = NewOTLPExporter(ctx, cfg.OTLPEndpoint, serviceName)
+	trace.RegisterExporter(otlpExporter)
+}
+
+// otlpExporter is the exporter registered by Init, if any.
+var otlpExporter *OTLPExporter
+
+// Shutdown stops exporting traces to the OTLP endpoint configured by Init,
+// after sending the spans that are still buffered. It does nothing if no
+// endpoint is configured.
+func Shutdown(ctx context.Context) error {
+	if otlpExporter == nil {
+		return nil
+	}
+	trace.UnregisterExporter(otlpExporter)
+	return otlpExporter.Stop(ctx)
+}
+
 // NewServer creates a new http.Handler for serving debug information.
 func NewServer() (http.Handler, error) {
 	pe, err := prometheus.NewExporter(prometheus.Options{})
@@ -228,6 +262,23 @@ var (
 		Measure:     ochttp.ServerResponseBytes,
 		Aggregation: ochttp.DefaultSizeDistribution,
 	}
+	// ServerLatencyByExperiment and ServerResponseCountByExperiment are like
+	// ServerLatency and ServerResponseCount, but also broken down by the
+	// experiments active for the request, to compare experiment cohorts.
+	ServerLatencyByExperiment = &view.View{
+		Name:        "go-discovery/http/server/response_latency_by_experiment",
+		Description: "Server response distribution by route and active experiments",
+		TagKeys:     []tag.Key{ochttp.KeyServerRoute, KeyExperiments},
+		Measure:     ochttp.ServerLatency,
+		Aggregation: ochttp.DefaultLatencyDistribution,
+	}
+	ServerResponseCountByExperiment = &view.View{
+		Name:        "go-discovery/http/server/response_count_by_experiment",
+		Description: "Server response count by status code, route and active experiments",
+		TagKeys:     []tag.Key{ochttp.StatusCode, ochttp.KeyServerRoute, KeyExperiments},
+		Measure:     ochttp.ServerLatency,
+		Aggregation: view.Count(),
+	}
 	ClientViews = []*view.View{
 		ClientCompletedCount,
 		ClientRoundtripLatencyDistribution,
@@ -239,4 +290,13 @@ var (
 		ServerLatency,
 		ServerResponseBytes,
 	}
+	ExperimentViews = []*view.View{
+		ServerLatencyByExperiment,
+		ServerResponseCountByExperiment,
+	}
 )
+
+// KeyExperiments is a census tag for the experiments active for a request,
+// set by the experiment middleware. Its value is the sorted, comma-separated
+// list of experiment names, or "none".
+var KeyExperiments = tag.MustNewKey("go-discovery.experiments")
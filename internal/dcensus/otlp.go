@@ -0,0 +1,279 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dcensus
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opencensus.io/trace"
+	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+const (
+	// otlpBatchSize is the maximum number of spans sent in a single request
+	// to the OTLP endpoint.
+	otlpBatchSize = 512
+	// otlpFlushInterval is the maximum time a span is buffered before it is
+	// sent to the OTLP endpoint.
+	otlpFlushInterval = 5 * time.Second
+	// otlpMaxBuffered is the maximum number of spans that are buffered.
+	// Spans exported when the buffer is full are dropped.
+	otlpMaxBuffered = 8 * otlpBatchSize
+)
+
+// OTLPExporter is a trace.Exporter that sends spans to an OpenTelemetry
+// collector, using the JSON encoding of the OTLP/HTTP protocol.
+type OTLPExporter struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+
+	mu    sync.Mutex
+	spans []*trace.SpanData
+
+	stopOnce sync.Once
+	stop     chan struct{} // closed by Stop
+	done     chan struct{} // closed when periodic flushing has stopped
+}
+
+// NewOTLPExporter returns an OTLPExporter that sends spans to endpoint,
+// for example http://localhost:4318/v1/traces. Spans are attributed to a
+// service with the given name. Buffered spans are sent periodically until ctx
+// is done or Stop is called.
+func NewOTLPExporter(ctx context.Context, endpoint, serviceName string) *OTLPExporter {
+	e := &OTLPExporter{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		// Use a plain client, so that requests to the collector are not
+		// themselves traced.
+		client: &http.Client{Timeout: 10 * time.Second},
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(e.done)
+		ticker := time.NewTicker(otlpFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				if err := e.Flush(ctx); err != nil {
+					log.Errorf(ctx, "OTLPExporter: %v", err)
+				}
+			}
+		}
+	}()
+	return e
+}
+
+// ExportSpan implements trace.Exporter. It buffers sd, to be sent on the next
+// flush.
+func (e *OTLPExporter) ExportSpan(sd *trace.SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.spans) >= otlpMaxBuffered {
+		return
+	}
+	e.spans = append(e.spans, sd)
+}
+
+// Flush sends all buffered spans to the OTLP endpoint.
+func (e *OTLPExporter) Flush(ctx context.Context) (err error) {
+	defer derrors.Wrap(&err, "OTLPExporter.Flush(ctx)")
+
+	e.mu.Lock()
+	spans := e.spans
+	e.spans = nil
+	e.mu.Unlock()
+
+	for len(spans) > 0 {
+		n := len(spans)
+		if n > otlpBatchSize {
+			n = otlpBatchSize
+		}
+		if err := e.send(ctx, spans[:n]); err != nil {
+			return err
+		}
+		spans = spans[n:]
+	}
+	return nil
+}
+
+// Stop stops sending spans periodically, and sends the spans that are still
+// buffered. Spans exported after Stop are never sent. Call it before the
+// program exits, so that the last spans are not lost.
+func (e *OTLPExporter) Stop(ctx context.Context) error {
+	e.stopOnce.Do(func() { close(e.stop) })
+	<-e.done
+	return e.Flush(ctx)
+}
+
+func (e *OTLPExporter) send(ctx context.Context, spans []*trace.SpanData) error {
+	body, err := json.Marshal(e.newRequest(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := ctxhttp.Do(ctx, e.client, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("exporting %d spans to %s: %s", len(spans), e.endpoint, resp.Status)
+	}
+	return nil
+}
+
+// The following types are the subset of the OTLP JSON encoding used by the
+// exporter. See
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/trace/v1/trace.proto.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+// OTLP span kinds and status codes.
+const (
+	otlpSpanKindInternal = 1
+	otlpSpanKindServer   = 2
+	otlpSpanKindClient   = 3
+
+	otlpStatusOK    = 1
+	otlpStatusError = 2
+)
+
+func (e *OTLPExporter) newRequest(spans []*trace.SpanData) *otlpRequest {
+	var ospans []otlpSpan
+	for _, sd := range spans {
+		ospans = append(ospans, newOTLPSpan(sd))
+	}
+	return &otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{newOTLPKeyValue("service.name", e.serviceName)},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "golang.org/x/pkgsite"},
+				Spans: ospans,
+			}},
+		}},
+	}
+}
+
+func newOTLPSpan(sd *trace.SpanData) otlpSpan {
+	s := otlpSpan{
+		TraceID:           hex.EncodeToString(sd.TraceID[:]),
+		SpanID:            hex.EncodeToString(sd.SpanID[:]),
+		Name:              sd.Name,
+		StartTimeUnixNano: strconv.FormatInt(sd.StartTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(sd.EndTime.UnixNano(), 10),
+	}
+	if sd.ParentSpanID != (trace.SpanID{}) {
+		s.ParentSpanID = hex.EncodeToString(sd.ParentSpanID[:])
+	}
+	switch sd.SpanKind {
+	case trace.SpanKindServer:
+		s.Kind = otlpSpanKindServer
+	case trace.SpanKindClient:
+		s.Kind = otlpSpanKindClient
+	default:
+		s.Kind = otlpSpanKindInternal
+	}
+	for k, v := range sd.Attributes {
+		s.Attributes = append(s.Attributes, newOTLPKeyValue(k, v))
+	}
+	if sd.Code == trace.StatusCodeOK {
+		s.Status.Code = otlpStatusOK
+	} else {
+		s.Status = otlpStatus{Code: otlpStatusError, Message: sd.Message}
+	}
+	return s
+}
+
+func newOTLPKeyValue(key string, value interface{}) otlpKeyValue {
+	kv := otlpKeyValue{Key: key}
+	switch v := value.(type) {
+	case bool:
+		kv.Value.BoolValue = &v
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		kv.Value.IntValue = &s
+	case float64:
+		kv.Value.DoubleValue = &v
+	case string:
+		kv.Value.StringValue = &v
+	default:
+		s := fmt.Sprint(v)
+		kv.Value.StringValue = &s
+	}
+	return kv
+}
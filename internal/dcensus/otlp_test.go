@@ -0,0 +1,105 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dcensus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/trace"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	var got otlpRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want %q", ct, "application/json")
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	e := NewOTLPExporter(ctx, ts.URL, "frontend")
+
+	start := time.Unix(0, 1000)
+	e.ExportSpan(&trace.SpanData{
+		SpanContext: trace.SpanContext{
+			TraceID: trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			SpanID:  trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		SpanKind:   trace.SpanKindServer,
+		Name:       "frontend.serveDetails",
+		StartTime:  start,
+		EndTime:    start.Add(time.Microsecond),
+		Attributes: map[string]interface{}{"fullPath": "net/http"},
+		Status:     trace.Status{Code: trace.StatusCodeNotFound, Message: "not found"},
+	})
+	if err := e.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	str := func(s string) *string { return &s }
+	want := otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{{Key: "service.name", Value: otlpValue{StringValue: str("frontend")}}},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "golang.org/x/pkgsite"},
+				Spans: []otlpSpan{{
+					TraceID:           "0102030405060708090a0b0c0d0e0f10",
+					SpanID:            "0102030405060708",
+					Name:              "frontend.serveDetails",
+					Kind:              otlpSpanKindServer,
+					StartTimeUnixNano: "1000",
+					EndTimeUnixNano:   "2000",
+					Attributes:        []otlpKeyValue{{Key: "fullPath", Value: otlpValue{StringValue: str("net/http")}}},
+					Status:            otlpStatus{Code: otlpStatusError, Message: "not found"},
+				}},
+			}},
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOTLPExporterStop(t *testing.T) {
+	var n int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		n += len(req.ResourceSpans[0].ScopeSpans[0].Spans)
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	e := NewOTLPExporter(ctx, ts.URL, "worker")
+	e.ExportSpan(&trace.SpanData{Name: "worker.fetch"})
+	if err := e.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("got %d spans sent on Stop, want 1", n)
+	}
+	// Stopping again sends nothing.
+	if err := e.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("got %d spans sent after a second Stop, want 1", n)
+	}
+}
@@ -33,6 +33,14 @@ var (
 	// from the path specified in the go.mod file.
 	AlternativeModule = errors.New("alternative module")
 
+	// ChecksumMismatch indicates that a downloaded module zip or go.mod file
+	// does not match the checksum database.
+	ChecksumMismatch = errors.New("checksum mismatch")
+
+	// DBUnavailable indicates that the database could not be reached
+	// (HTTP 503).
+	DBUnavailable = errors.New("database unavailable")
+
 	// Unknown indicates that the error has unknown semantics.
 	Unknown = errors.New("unknown")
 
@@ -86,12 +94,14 @@ var httpCodes = []struct {
 	{NotFound, http.StatusNotFound},
 	{InvalidArgument, http.StatusBadRequest},
 	{Excluded, http.StatusForbidden},
+	{DBUnavailable, http.StatusServiceUnavailable},
 
 	// Since the following aren't HTTP statuses, pick unused codes.
 	{HasIncompletePackages, 290},
 	{DBModuleInsertInvalid, 480},
 	{BadModule, 490},
 	{AlternativeModule, 491},
+	{ChecksumMismatch, 492},
 
 	// 52x errors represents modules that need to be reprocessed, and the
 	// previous status code the module had. Note that the status code
@@ -52,6 +52,7 @@ func TestToHTTPStatus(t *testing.T) {
 		{NotFound, http.StatusNotFound},
 		{BadModule, 490},
 		{AlternativeModule, 491},
+		{ChecksumMismatch, 492},
 		{Unknown, http.StatusInternalServerError},
 		{fmt.Errorf("wrapping: %w", NotFound), http.StatusNotFound},
 		{io.ErrUnexpectedEOF, http.StatusInternalServerError},
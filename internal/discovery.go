@@ -21,7 +21,7 @@ const (
 	LatestVersion = "latest"
 
 	// MasterVersion signifies the version at master.
-	MasterVersion = "master"
+	MasterVersion = version.Master
 
 	// UnknownModulePath signifies that the module path for a given package
 	// path is ambiguous or not known. This is because requests to the
@@ -38,7 +38,24 @@ type ModuleInfo struct {
 	VersionType       version.Type
 	IsRedistributable bool
 	HasGoMod          bool // whether the module zip has a go.mod file
+	HasExecutables    bool // whether the module zip has executable files
+	HasLargeFiles     bool // whether the module zip has files larger than fetch.LargeFileSize
 	SourceInfo        *source.Info
+	// Origin describes where the module proxy got the module version from.
+	// It is nil if the proxy did not say.
+	Origin *Origin
+}
+
+// Origin describes the version control origin of a module version, as
+// reported in the Origin field of the proxy's .info response. It lets users
+// check exactly which commit a module version, and its documentation, was
+// built from.
+type Origin struct {
+	VCS    string `json:",omitempty"` // version control system, like "git"
+	URL    string `json:",omitempty"` // repository URL
+	Subdir string `json:",omitempty"` // directory of the module in the repository
+	Hash   string `json:",omitempty"` // commit hash
+	Ref    string `json:",omitempty"` // ref the version was resolved from, like "refs/tags/v1.2.3"
 }
 
 // LegacyModuleInfo holds metadata associated with a module.
@@ -99,10 +116,32 @@ type Module struct {
 	// that may be contained in nested subdirectories.
 	Licenses    []*licenses.License
 	Directories []*DirectoryNew
+	// Files lists the files in the module zip, sorted by path.
+	Files []*ModuleFile
+	// Requires lists the requirements in the go.mod file, sorted by path.
+	Requires []*ModuleRequire
+	// ReleaseNotes is the section about this version of a changelog at the
+	// root of the module, if one was found. Its Filepath is the path of the
+	// changelog.
+	ReleaseNotes *Readme
 
 	LegacyPackages []*LegacyPackage
 }
 
+// A ModuleFile is a file in a module zip.
+type ModuleFile struct {
+	Path       string // relative to the module root
+	Size       int64  // uncompressed size in bytes
+	Executable bool   // whether the file is a compiled executable or object file
+}
+
+// A ModuleRequire is a requirement in the go.mod file of a module.
+type ModuleRequire struct {
+	Path     string
+	Version  string
+	Indirect bool // whether the requirement is marked "// indirect"
+}
+
 // VersionedDirectory is a DirectoryNew along with its corresponding module
 // information.
 type VersionedDirectory struct {
@@ -233,6 +272,16 @@ type SearchResult struct {
 	// can be approximate if search scanned only a subset of documents, and
 	// result count is estimated using the hyperloglog algorithm.
 	Approximate bool
+
+	// SynopsisHighlight and ReadmeHighlight are the fragments of the synopsis
+	// and README that match the search query, as HTML in which the matching
+	// words are in <b> elements. They are empty if there is no match.
+	SynopsisHighlight string
+	ReadmeHighlight   string
+
+	// Symbols are the most prominent exported identifiers of the package,
+	// most prominent first, to tell apart packages with similar names.
+	Symbols []string
 }
 
 // A FieldSet is a bit set of struct fields. It is used to avoid reading large
@@ -293,6 +342,44 @@ type LegacyPackage struct {
 	// V1Path is the package path of a package with major version 1 in a given
 	// series.
 	V1Path string
+
+	// Quality holds the signals about the quality of the package found when
+	// it was fetched. It is nil if they are not known.
+	Quality *PackageQuality
+
+	// Usages are excerpts of the source of the package that call the
+	// packages of other modules it imports, at most one per imported package.
+	Usages []*UsageSnippet
+}
+
+// PackageQuality holds facts about a package version that hint at its
+// quality, found by analyzing its source when it is fetched.
+type PackageQuality struct {
+	// HasTests reports whether the package has _test.go files.
+	HasTests bool
+	// HasExamples reports whether the documentation of the package has
+	// examples.
+	HasExamples bool
+	// NumExported is the number of exported constants, variables, functions,
+	// types and methods of the package, and NumDocumented is the number of
+	// them that have a doc comment.
+	NumExported   int
+	NumDocumented int
+}
+
+// A UsageSnippet is a short excerpt of the source of a package that calls a
+// package it imports, found when the package is fetched. The snippets of the
+// importers of a package show how it is used.
+type UsageSnippet struct {
+	// PackagePath is the path of the package the snippet is from. It is not
+	// set in LegacyPackage.Usages.
+	PackagePath string
+	// ImportPath is the path of the package called by the snippet.
+	ImportPath string
+	Code       string
+	// URL is the URL of the first line of the snippet at the repository of
+	// the package, or empty if it is not known.
+	URL string
 }
 
 // LegacyVersionedPackage is a LegacyPackage along with its corresponding module
@@ -0,0 +1,12 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package discoverypb holds the gRPC API of the frontend, defined in
+// discovery.proto, for services that prefer it to the JSON API.
+//
+// After changing discovery.proto, regenerate discovery.pb.go with
+// protoc-gen-go v1.3.5, the version of github.com/golang/protobuf in go.mod.
+package discoverypb
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. discovery.proto
@@ -0,0 +1,412 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package elasticsearch provides a search backend that uses Elasticsearch,
+// so that large deployments can scale search independently of the database.
+//
+// The Elasticsearch index is a copy of the search_documents table, kept up to
+// date by the worker (see Backend.Sync). Search results are completed from the
+// database, which also removes packages that were deleted since they were
+// copied.
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opencensus.io/plugin/ochttp"
+	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// A Backend searches for packages in an Elasticsearch index.
+type Backend struct {
+	// indexURL is the URL of the index, like "http://localhost:9200/pkgsite".
+	indexURL string
+
+	// httpClient is used for HTTP requests. It is mutable for testing purposes.
+	httpClient *http.Client
+
+	db *postgres.DB
+	// complete is db.CompleteSearchResults. It is mutable for testing
+	// purposes.
+	complete func(context.Context, []*internal.SearchResult) ([]*internal.SearchResult, error)
+	// pin is db.PinExactPathMatch. It is mutable for testing purposes.
+	pin func(context.Context, string, []*internal.SearchResult) ([]*internal.SearchResult, error)
+	// highlight is db.HighlightSearchResults. It is mutable for testing
+	// purposes.
+	highlight func(context.Context, string, []*internal.SearchResult) error
+}
+
+// New returns a Backend for the index with the given name in the
+// Elasticsearch cluster at rawurl. The database db is the source of the
+// documents in the index.
+func New(rawurl, index string, db *postgres.DB) (_ *Backend, err error) {
+	defer derrors.Wrap(&err, "elasticsearch.New(%q, %q)", rawurl, index)
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("scheme must be http or https (got %q)", u.Scheme)
+	}
+	if index == "" || strings.ContainsAny(index, "/?#") {
+		return nil, fmt.Errorf("invalid index name %q", index)
+	}
+	return &Backend{
+		indexURL:   strings.TrimRight(rawurl, "/") + "/" + index,
+		httpClient: &http.Client{Transport: &ochttp.Transport{}},
+		db:         db,
+		complete:   db.CompleteSearchResults,
+		pin:        db.PinExactPathMatch,
+		highlight:  db.HighlightSearchResults,
+	}, nil
+}
+
+// document is the form of a postgres.SearchDocument in the index.
+type document struct {
+	PackagePath     string    `json:"package_path"`
+	ModulePath      string    `json:"module_path"`
+	Version         string    `json:"version"`
+	Name            string    `json:"name"`
+	CommitTime      time.Time `json:"commit_time"`
+	ImportedByCount int       `json:"imported_by_count"`
+	Rank            float64   `json:"rank"`
+	PathTokens      string    `json:"path_tokens"`
+	SectionB        string    `json:"section_b"`
+	SectionC        string    `json:"section_c"`
+	SectionD        string    `json:"section_d"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// indexMapping is the definition of the index. The text sections are analyzed
+// like the corresponding sections of the tsvector in search_documents.
+const indexMapping = `{
+	"mappings": {
+		"properties": {
+			"package_path":      {"type": "keyword"},
+			"module_path":       {"type": "keyword"},
+			"version":           {"type": "keyword"},
+			"name":              {"type": "keyword"},
+			"commit_time":       {"type": "date"},
+			"imported_by_count": {"type": "integer"},
+			"rank":              {"type": "float"},
+			"path_tokens":       {"type": "text", "analyzer": "simple"},
+			"section_b":         {"type": "text", "analyzer": "english"},
+			"section_c":         {"type": "text", "analyzer": "english"},
+			"section_d":         {"type": "text", "analyzer": "english"},
+			"updated_at":        {"type": "date_nanos"}
+		}
+	}
+}`
+
+// sectionFields are the text fields that are searched, with the same
+// weights as the ts_rank call in postgres.
+var sectionFields = []string{"path_tokens^1.0", "section_b^1.0", "section_c^0.2", "section_d^0.1"}
+
+// Search implements internal.SearchBackend.
+func (b *Backend) Search(ctx context.Context, q string, limit, offset int) (_ []*internal.SearchResult, err error) {
+	defer derrors.Wrap(&err, "elasticsearch.Search(ctx, %q, %d, %d)", q, limit, offset)
+
+	limit, err = internal.CheckSearchPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	// The index does not hold symbols, so identifier searches use the
+	// database.
+	if _, ok := postgres.IdentifierQuery(q); ok {
+		return b.db.Search(ctx, q, limit, offset)
+	}
+
+	// Like websearch_to_tsquery in postgres, every term must match, and the
+	// score is multiplied by the precomputed rank of the document.
+	text, kind := postgres.ParseSearchFilters(q)
+	match := map[string]interface{}{
+		"simple_query_string": map[string]interface{}{
+			"query":            text,
+			"fields":           sectionFields,
+			"default_operator": "and",
+		},
+	}
+	isCommand := map[string]interface{}{"term": map[string]string{"name": "main"}}
+	switch kind {
+	case postgres.CommandKind:
+		match = map[string]interface{}{"bool": map[string]interface{}{"must": match, "filter": isCommand}}
+	case postgres.PackageKind:
+		match = map[string]interface{}{"bool": map[string]interface{}{"must": match, "must_not": isCommand}}
+	}
+	query := map[string]interface{}{
+		"from":             offset,
+		"size":             limit,
+		"track_total_hits": true,
+		"query": map[string]interface{}{
+			"function_score": map[string]interface{}{
+				"query": match,
+				"field_value_factor": map[string]interface{}{
+					"field":   "rank",
+					"missing": 1,
+				},
+				"boost_mode": "multiply",
+			},
+		},
+		"sort": []interface{}{
+			"_score",
+			map[string]string{"commit_time": "desc"},
+			map[string]string{"package_path": "asc"},
+		},
+		"_source": []string{"package_path", "module_path", "version", "commit_time", "imported_by_count"},
+	}
+	var resp searchResponse
+	if err := b.do(ctx, http.MethodPost, "/_search", query, &resp); err != nil {
+		return nil, err
+	}
+	var results []*internal.SearchResult
+	for _, h := range resp.Hits.Hits {
+		results = append(results, &internal.SearchResult{
+			PackagePath:   h.Source.PackagePath,
+			ModulePath:    h.Source.ModulePath,
+			Version:       h.Source.Version,
+			CommitTime:    h.Source.CommitTime,
+			NumImportedBy: uint64(h.Source.ImportedByCount),
+			Score:         h.Score,
+		})
+	}
+	results, err = b.complete(ctx, results)
+	if err != nil {
+		return nil, err
+	}
+	if offset == 0 && kind == "" {
+		results, err = b.pin(ctx, text, results)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// The index holds the processed words of the synopsis and README rather
+	// than their text, so the fragments to highlight come from the database.
+	if err := b.highlight(ctx, text, results); err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		r.NumResults = resp.Hits.Total.Value
+		r.Approximate = resp.Hits.Total.Relation != "eq"
+	}
+	return results, nil
+}
+
+// searchResponse is the part of the response to a search request that
+// Backend uses.
+type searchResponse struct {
+	Hits struct {
+		Total struct {
+			Value    uint64 `json:"value"`
+			Relation string `json:"relation"`
+		} `json:"total"`
+		Hits []struct {
+			Score  float64  `json:"_score"`
+			Source document `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// CreateIndex creates the index, if it does not exist.
+func (b *Backend) CreateIndex(ctx context.Context) (err error) {
+	defer derrors.Wrap(&err, "elasticsearch.CreateIndex(ctx)")
+
+	err = b.do(ctx, http.MethodPut, "", json.RawMessage(indexMapping), nil)
+	var rerr *responseError
+	if errors.As(err, &rerr) && rerr.Type == "resource_already_exists_exception" {
+		return nil
+	}
+	return err
+}
+
+// Index adds the documents to the index, replacing existing documents with
+// the same package paths.
+func (b *Backend) Index(ctx context.Context, docs []*postgres.SearchDocument) (err error) {
+	defer derrors.Wrap(&err, "elasticsearch.Index(ctx, %d documents)", len(docs))
+
+	if len(docs) == 0 {
+		return nil
+	}
+	// The bulk API takes newline-delimited pairs of actions and documents.
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, d := range docs {
+		action := map[string]interface{}{"index": map[string]string{"_id": d.PackagePath}}
+		if err := enc.Encode(action); err != nil {
+			return err
+		}
+		if err := enc.Encode(document{
+			PackagePath:     d.PackagePath,
+			ModulePath:      d.ModulePath,
+			Version:         d.Version,
+			Name:            d.Name,
+			CommitTime:      d.CommitTime,
+			ImportedByCount: d.ImportedByCount,
+			Rank:            d.Rank,
+			PathTokens:      d.PathTokens,
+			SectionB:        d.SectionB,
+			SectionC:        d.SectionC,
+			SectionD:        d.SectionD,
+			UpdatedAt:       d.UpdatedAt,
+		}); err != nil {
+			return err
+		}
+	}
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string          `json:"_id"`
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := b.do(ctx, http.MethodPost, "/_bulk", &body, &resp); err != nil {
+		return err
+	}
+	if !resp.Errors {
+		return nil
+	}
+	for _, item := range resp.Items {
+		for _, r := range item {
+			if len(r.Error) > 0 {
+				return fmt.Errorf("indexing %q: %s", r.ID, r.Error)
+			}
+		}
+	}
+	return errors.New("bulk request reported errors")
+}
+
+// lastUpdated returns the update time and package path of the most recently
+// updated document in the index, or the zero time if the index is empty.
+func (b *Backend) lastUpdated(ctx context.Context) (time.Time, string, error) {
+	query := map[string]interface{}{
+		"size": 1,
+		"sort": []interface{}{
+			map[string]string{"updated_at": "desc"},
+			map[string]string{"package_path": "desc"},
+		},
+		"_source": []string{"package_path", "updated_at"},
+	}
+	var resp searchResponse
+	if err := b.do(ctx, http.MethodPost, "/_search", query, &resp); err != nil {
+		return time.Time{}, "", err
+	}
+	if len(resp.Hits.Hits) == 0 {
+		return time.Time{}, "", nil
+	}
+	d := resp.Hits.Hits[0].Source
+	return d.UpdatedAt, d.PackagePath, nil
+}
+
+// Sync copies the search documents that were updated since the last call to
+// Sync from the database to the index, in batches of batchSize. It stops
+// after copying at least maxDocs documents, and returns the number of
+// documents copied.
+//
+// Documents deleted from the database are not deleted from the index, but
+// they are removed from search results by postgres.DB.CompleteSearchResults.
+func (b *Backend) Sync(ctx context.Context, batchSize, maxDocs int) (n int, err error) {
+	defer derrors.Wrap(&err, "elasticsearch.Sync(ctx, %d, %d)", batchSize, maxDocs)
+
+	if err := b.CreateIndex(ctx); err != nil {
+		return 0, err
+	}
+	since, afterPath, err := b.lastUpdated(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for n < maxDocs {
+		docs, err := b.db.SearchDocumentsUpdatedSince(ctx, since, afterPath, batchSize)
+		if err != nil {
+			return n, err
+		}
+		if err := b.Index(ctx, docs); err != nil {
+			return n, err
+		}
+		n += len(docs)
+		if len(docs) < batchSize {
+			break
+		}
+		last := docs[len(docs)-1]
+		since, afterPath = last.UpdatedAt, last.PackagePath
+	}
+	log.Infof(ctx, "copied %d search documents to %s", n, b.indexURL)
+	return n, nil
+}
+
+// responseError is an error returned by Elasticsearch.
+type responseError struct {
+	Status int
+	Type   string
+	Reason string
+}
+
+func (e *responseError) Error() string {
+	return fmt.Sprintf("elasticsearch: %d %s: %s", e.Status, e.Type, e.Reason)
+}
+
+// do sends a request to the path under the index URL, with body encoded as
+// JSON unless it is an io.Reader or a json.RawMessage, and decodes the JSON
+// response into result, if it is not nil.
+func (b *Backend) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var r io.Reader
+	contentType := "application/json"
+	switch body := body.(type) {
+	case nil:
+	case io.Reader:
+		r = body
+		contentType = "application/x-ndjson"
+	case json.RawMessage:
+		r = bytes.NewReader(body)
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, b.indexURL+path, r)
+	if err != nil {
+		return err
+	}
+	if r != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := ctxhttp.Do(ctx, b.httpClient, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(data, &e)
+		return &responseError{Status: resp.StatusCode, Type: e.Error.Type, Reason: e.Error.Reason}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
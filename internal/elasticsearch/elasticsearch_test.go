@@ -0,0 +1,181 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elasticsearch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// newTestBackend returns a Backend that sends requests to handler, and
+// completes search results by setting their names and highlights. The
+// returned function shuts down the server.
+func newTestBackend(t *testing.T, handler http.HandlerFunc) (*Backend, func()) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	b, err := New(server.URL, "pkgsite", nil)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	b.httpClient = server.Client()
+	b.complete = func(_ context.Context, rs []*internal.SearchResult) ([]*internal.SearchResult, error) {
+		for _, r := range rs {
+			r.Name = "name"
+		}
+		return rs, nil
+	}
+	b.pin = func(_ context.Context, _ string, rs []*internal.SearchResult) ([]*internal.SearchResult, error) {
+		return rs, nil
+	}
+	b.highlight = func(_ context.Context, q string, rs []*internal.SearchResult) error {
+		for _, r := range rs {
+			r.SynopsisHighlight = "<b>" + q + "</b>"
+		}
+		return nil
+	}
+	return b, server.Close
+}
+
+func TestSearch(t *testing.T) {
+	commitTime := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	b, cleanup := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/pkgsite/_search" {
+			t.Errorf("got %s %s, want POST /pkgsite/_search", r.Method, r.URL.Path)
+		}
+		var query struct {
+			From  int `json:"from"`
+			Size  int `json:"size"`
+			Query struct {
+				FunctionScore struct {
+					Query struct {
+						SimpleQueryString struct {
+							Query string `json:"query"`
+						} `json:"simple_query_string"`
+					} `json:"query"`
+				} `json:"function_score"`
+			} `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+			t.Error(err)
+			return
+		}
+		if got := query.Query.FunctionScore.Query.SimpleQueryString.Query; got != "foo bar" {
+			t.Errorf("got query %q, want %q", got, "foo bar")
+		}
+		if query.From != 20 || query.Size != 10 {
+			t.Errorf("got from %d, size %d; want 20, 10", query.From, query.Size)
+		}
+		fmt.Fprint(w, `{"hits": {
+			"total": {"value": 42, "relation": "eq"},
+			"hits": [{
+				"_score": 1.5,
+				"_source": {
+					"package_path": "example.com/foo",
+					"module_path": "example.com/foo",
+					"version": "v1.0.0",
+					"commit_time": "2020-06-01T00:00:00Z",
+					"imported_by_count": 7
+				}
+			}]
+		}}`)
+	})
+	defer cleanup()
+	got, err := b.Search(context.Background(), "foo bar", 10, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*internal.SearchResult{{
+		Name:              "name",
+		PackagePath:       "example.com/foo",
+		ModulePath:        "example.com/foo",
+		Version:           "v1.0.0",
+		CommitTime:        commitTime,
+		Score:             1.5,
+		NumImportedBy:     7,
+		NumResults:        42,
+		SynopsisHighlight: "<b>foo bar</b>",
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	var got []map[string]interface{}
+	b, cleanup := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/pkgsite/_bulk" {
+			t.Errorf("got %s %s, want POST /pkgsite/_bulk", r.Method, r.URL.Path)
+		}
+		sc := bufio.NewScanner(r.Body)
+		for sc.Scan() {
+			var m map[string]interface{}
+			if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+				t.Error(err)
+				return
+			}
+			got = append(got, m)
+		}
+		fmt.Fprint(w, `{"errors": false, "items": []}`)
+	})
+	defer cleanup()
+	docs := []*postgres.SearchDocument{
+		{PackagePath: "example.com/a", Rank: 2},
+		{PackagePath: "example.com/b", Rank: 1},
+	}
+	if err := b.Index(context.Background(), docs); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("got %d lines, want 4", len(got))
+	}
+	for i, d := range docs {
+		action, doc := got[2*i], got[2*i+1]
+		if id := action["index"].(map[string]interface{})["_id"]; id != d.PackagePath {
+			t.Errorf("action %d: got _id %v, want %q", i, id, d.PackagePath)
+		}
+		if path := doc["package_path"]; path != d.PackagePath {
+			t.Errorf("document %d: got package_path %v, want %q", i, path, d.PackagePath)
+		}
+		if rank := doc["rank"]; rank != d.Rank {
+			t.Errorf("document %d: got rank %v, want %v", i, rank, d.Rank)
+		}
+	}
+}
+
+func TestCreateIndex(t *testing.T) {
+	for _, test := range []struct {
+		status  int
+		body    string
+		wantErr bool
+	}{
+		{http.StatusOK, `{"acknowledged": true}`, false},
+		{http.StatusBadRequest, `{"error": {"type": "resource_already_exists_exception", "reason": "exists"}}`, false},
+		{http.StatusBadRequest, `{"error": {"type": "mapper_parsing_exception", "reason": "bad"}}`, true},
+	} {
+		b, cleanup := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPut || r.URL.Path != "/pkgsite" {
+				t.Errorf("got %s %s, want PUT /pkgsite", r.Method, r.URL.Path)
+			}
+			w.WriteHeader(test.status)
+			fmt.Fprint(w, test.body)
+		})
+		err := b.CreateIndex(context.Background())
+		cleanup()
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: got error %v, want error: %t", test.body, err, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// LargeFileSize is the size above which a file in a module zip is considered
+// large. Module versions with large files, or with executables, are flagged
+// on their pages, since users reviewing dependencies often want to know about
+// them. It should be set only at startup.
+var LargeFileSize int64 = 10 * megabyte
+
+// executableMagic are the prefixes of executable and object file formats.
+var executableMagic = [][]byte{
+	[]byte("\x7fELF"),          // ELF
+	[]byte("MZ"),               // Windows PE
+	[]byte("\xfe\xed\xfa\xce"), // Mach-O 32-bit, big-endian
+	[]byte("\xce\xfa\xed\xfe"), // Mach-O 32-bit, little-endian
+	[]byte("\xfe\xed\xfa\xcf"), // Mach-O 64-bit, big-endian
+	[]byte("\xcf\xfa\xed\xfe"), // Mach-O 64-bit, little-endian
+	[]byte("\xca\xfe\xba\xbe"), // Mach-O universal binary, or Java class file
+	[]byte("\x00asm"),          // WebAssembly
+}
+
+// isExecutable reports whether f is an executable or object file, judging by
+// its first bytes. Go source files are never considered executable.
+func isExecutable(f *zip.File) bool {
+	if strings.HasSuffix(f.Name, ".go") || f.UncompressedSize64 < 4 {
+		return false
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return false
+	}
+	defer rc.Close()
+	var buf [4]byte
+	if _, err := io.ReadFull(rc, buf[:]); err != nil {
+		return false
+	}
+	for _, m := range executableMagic {
+		if bytes.HasPrefix(buf[:], m) {
+			return true
+		}
+	}
+	return false
+}
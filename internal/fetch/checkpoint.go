@@ -0,0 +1,45 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"archive/zip"
+	"context"
+
+	"golang.org/x/pkgsite/internal"
+)
+
+// A Checkpoint holds packages of a module version that have been processed.
+// States has the state of every package in Packages, and of the directories
+// that could not be processed into a package.
+type Checkpoint struct {
+	Packages []*internal.LegacyPackage
+	States   []*internal.PackageVersionState
+}
+
+// A CheckpointStore saves the progress of processing the packages of a module
+// version, so that a fetch that times out or crashes can resume where it
+// left off instead of starting over.
+type CheckpointStore interface {
+	// LoadCheckpoint returns all the packages saved for the module version.
+	LoadCheckpoint(ctx context.Context, modulePath, version string) (*Checkpoint, error)
+	// SaveCheckpoint adds the packages in cp to those saved for the module
+	// version.
+	SaveCheckpoint(ctx context.Context, modulePath, version string, cp *Checkpoint) error
+}
+
+// CheckpointBatchSize is the number of packages processed between
+// checkpoints. It is a variable for testing.
+var CheckpointBatchSize = 100
+
+// zipSize returns the compressed size of the files in r, which is close to
+// the size of the zip.
+func zipSize(r *zip.Reader) int64 {
+	var n int64
+	for _, f := range r.File {
+		n += int64(f.CompressedSize64)
+	}
+	return n
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/proxy"
+	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+// memCheckpointStore is a CheckpointStore that keeps checkpoints in memory.
+type memCheckpointStore struct {
+	cp    Checkpoint
+	saves int
+}
+
+func (s *memCheckpointStore) LoadCheckpoint(ctx context.Context, modulePath, version string) (*Checkpoint, error) {
+	cp := s.cp
+	return &cp, nil
+}
+
+func (s *memCheckpointStore) SaveCheckpoint(ctx context.Context, modulePath, version string, cp *Checkpoint) error {
+	s.cp.Packages = append(s.cp.Packages, cp.Packages...)
+	s.cp.States = append(s.cp.States, cp.States...)
+	s.saves++
+	return nil
+}
+
+func TestFetchModuleWithCheckpoints(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	defer func(n int) { CheckpointBatchSize = n }(CheckpointBatchSize)
+	CheckpointBatchSize = 1
+
+	mod := moduleMultiPackage.mod
+	proxyClient, teardownProxy := proxy.SetupTestProxy(t, []*proxy.TestModule{{
+		ModulePath: mod.ModulePath,
+		Version:    "v1.0.0",
+		Files:      mod.Files,
+	}})
+	defer teardownProxy()
+	sourceClient := source.NewClient(sourceTimeout)
+
+	// A zip smaller than the minimum size is not checkpointed.
+	cs := &memCheckpointStore{}
+	fr := FetchModuleWithCheckpoints(ctx, mod.ModulePath, "v1.0.0", proxyClient, sourceClient, cs, 1<<40)
+	if fr.Error != nil {
+		t.Fatal(fr.Error)
+	}
+	if cs.saves != 0 {
+		t.Errorf("small zip: got %d checkpoints, want 0", cs.saves)
+	}
+
+	want := FetchModuleWithCheckpoints(ctx, mod.ModulePath, "v1.0.0", proxyClient, sourceClient, cs, 0)
+	if want.Error != nil {
+		t.Fatal(want.Error)
+	}
+	n := len(want.PackageVersionStates)
+	if cs.saves != n || len(cs.cp.States) != n {
+		t.Fatalf("got %d checkpoints of %d packages, want %d of %d", cs.saves, len(cs.cp.States), n, n)
+	}
+
+	// Fetching again resumes from the checkpoint, so nothing is processed or
+	// saved.
+	cs.saves = 0
+	got := FetchModuleWithCheckpoints(ctx, mod.ModulePath, "v1.0.0", proxyClient, sourceClient, cs, 0)
+	if got.Error != nil {
+		t.Fatal(got.Error)
+	}
+	if cs.saves != 0 {
+		t.Errorf("resumed fetch: got %d checkpoints, want 0", cs.saves)
+	}
+	sortFetchResult(want)
+	sortFetchResult(got)
+	if diff := cmp.Diff(want.Module.LegacyPackages, got.Module.LegacyPackages, sample.LicenseCmpOpts...); diff != "" {
+		t.Errorf("packages mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want.PackageVersionStates, got.PackageVersionStates); diff != "" {
+		t.Errorf("package states mismatch (-want +got):\n%s", diff)
+	}
+}
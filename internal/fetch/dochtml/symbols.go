@@ -0,0 +1,142 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import (
+	"math"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// A Symbol is an identifier documented in the HTML produced by Render.
+type Symbol struct {
+	// Name is the name of the identifier, qualified by its type for fields and
+	// methods, like "Client.Do". It is also the id of the element that
+	// documents the identifier.
+	Name string
+	// Kind is one of "constant", "variable", "function", "type", "method" and
+	// "field".
+	Kind string
+}
+
+// Symbols returns the symbols in docHTML, which must have been produced by
+// Render, in the order in which they are documented. Render marks each
+// symbol with an id and a data-kind attribute.
+func Symbols(docHTML string) ([]*Symbol, error) {
+	root, err := html.Parse(strings.NewReader(docHTML))
+	if err != nil {
+		return nil, err
+	}
+	var symbols []*Symbol
+	seen := map[string]bool{}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			var id, kind string
+			for _, a := range n.Attr {
+				switch a.Key {
+				case "id":
+					id = a.Val
+				case "data-kind":
+					kind = a.Val
+				}
+			}
+			if id != "" && kind != "" && !seen[id] {
+				seen[id] = true
+				symbols = append(symbols, &Symbol{Name: id, Kind: kind})
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return symbols, nil
+}
+
+// SymbolImportance returns how prominent each symbol in docHTML, which must
+// have been produced by Render, is, by name. The score grows with the number
+// of links to the symbol, like those in the declarations and documentation of
+// other symbols, and with the length of the symbol's own documentation.
+// Methods and fields score half as much as other symbols, since they are
+// reached through their type.
+func SymbolImportance(docHTML string) (map[string]float64, error) {
+	root, err := html.Parse(strings.NewReader(docHTML))
+	if err != nil {
+		return nil, err
+	}
+	var (
+		kinds   = map[string]string{}
+		refs    = map[string]int{}
+		docLens = map[string]int{}
+		// current is the symbol documented by the paragraphs that follow.
+		current string
+	)
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			var id, kind, href string
+			for _, a := range n.Attr {
+				switch a.Key {
+				case "id":
+					id = a.Val
+				case "data-kind":
+					kind = a.Val
+				case "href":
+					href = a.Val
+				}
+			}
+			if id != "" && kind != "" {
+				if _, ok := kinds[id]; !ok {
+					kinds[id] = kind
+				}
+				current = id
+			}
+			switch n.DataAtom {
+			case atom.A:
+				// The "¶" link of a symbol's header links to itself.
+				if strings.HasPrefix(href, "#") && nodeText(n) != "¶" {
+					refs[href[1:]]++
+				}
+			case atom.P:
+				if current != "" {
+					docLens[current] += len(nodeText(n))
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	scores := map[string]float64{}
+	for name, kind := range kinds {
+		score := float64(refs[name]) + math.Log1p(float64(docLens[name]))
+		if kind == "method" || kind == "field" {
+			score /= 2
+		}
+		scores[name] = score
+	}
+	return scores, nil
+}
+
+// nodeText returns the text in n and its descendants.
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.TrimSpace(b.String())
+}
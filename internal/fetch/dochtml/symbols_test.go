@@ -0,0 +1,93 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import (
+	"go/ast"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestSymbols(t *testing.T) {
+	fset, d := mustLoadPackage("everydecl")
+	rawDoc, err := Render(fset, d, RenderOptions{
+		SourceLinkFunc: func(ast.Node) string { return "src" },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Symbols(rawDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Symbol{
+		{"C", "constant"},
+		{"CT", "constant"},
+		{"F", "function"},
+		{"TF", "function"},
+		{"T.M", "method"},
+		{"V", "variable"},
+		{"VT", "variable"},
+		{"T", "type"},
+		{"S1", "type"},
+		{"S1.F", "field"},
+		{"S2", "type"},
+		{"S2.S1", "field"},
+		{"S2.G", "field"},
+		{"I1", "type"},
+		{"I1.M1", "method"},
+		{"I2", "type"},
+		{"I2.M2", "method"},
+	}
+	diff := cmp.Diff(want, got, cmpopts.SortSlices(func(s1, s2 *Symbol) bool {
+		return s1.Name < s2.Name
+	}))
+	if diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestSymbolImportance(t *testing.T) {
+	docHTML := `<section class="Documentation-overview"><p>Package p uses <a href="#Client">Client</a>.</p></section>
+<section class="Documentation-functions">
+	<div class="Documentation-function">
+		<h3 id="New" data-kind="function">func New <a href="#New">¶</a></h3>
+		<pre>func New() *<a href="#Client">Client</a></pre>
+		<p>New returns a new Client.</p>
+	</div>
+	<div class="Documentation-function">
+		<h3 id="Old" data-kind="function">func Old <a href="#Old">¶</a></h3>
+		<pre>func Old()</pre>
+	</div>
+</section>
+<section class="Documentation-types">
+	<div class="Documentation-type">
+		<h3 id="Client" data-kind="type">type Client <a href="#Client">¶</a></h3>
+		<pre>type Client struct{}</pre>
+		<p>A Client is a client.</p>
+		<div class="Documentation-typeMethod">
+			<h3 id="Client.Do" data-kind="method">func (c *Client) Do <a href="#Client.Do">¶</a></h3>
+			<pre>func (c *<a href="#Client">Client</a>) Do()</pre>
+			<p>Do does it.</p>
+		</div>
+	</div>
+</section>`
+	got, err := SymbolImportance(docHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{
+		"New":       math.Log1p(float64(len("New returns a new Client."))),
+		"Old":       0,
+		"Client":    3 + math.Log1p(float64(len("A Client is a client."))),
+		"Client.Do": math.Log1p(float64(len("Do does it."))) / 2,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
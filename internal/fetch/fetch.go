@@ -65,6 +65,14 @@ type FetchResult struct {
 //
 // Even if err is non-nil, the result may contain useful information, like the go.mod path.
 func FetchModule(ctx context.Context, modulePath, requestedVersion string, proxyClient *proxy.Client, sourceClient *source.Client) (fr *FetchResult) {
+	return FetchModuleWithCheckpoints(ctx, modulePath, requestedVersion, proxyClient, sourceClient, nil, 0)
+}
+
+// FetchModuleWithCheckpoints is like FetchModule, but if the module zip is
+// larger than minZipSize, its packages are saved to cs as they are processed,
+// and packages saved by an earlier, interrupted fetch are not processed
+// again. If cs is nil, nothing is checkpointed.
+func FetchModuleWithCheckpoints(ctx context.Context, modulePath, requestedVersion string, proxyClient *proxy.Client, sourceClient *source.Client, cs CheckpointStore, minZipSize int64) (fr *FetchResult) {
 	fr = &FetchResult{
 		ModulePath:       modulePath,
 		RequestedVersion: requestedVersion,
@@ -82,16 +90,17 @@ func FetchModule(ctx context.Context, modulePath, requestedVersion string, proxy
 
 	var (
 		commitTime time.Time
+		origin     *internal.Origin
 		zipReader  *zip.Reader
+		goModBytes []byte
 		err        error
 	)
 	if modulePath == stdlib.ModulePath {
-		zipReader, commitTime, err = stdlib.Zip(requestedVersion)
+		zipReader, fr.ResolvedVersion, commitTime, err = stdlib.Zip(requestedVersion)
 		if err != nil {
 			fr.Error = err
 			return fr
 		}
-		fr.ResolvedVersion = requestedVersion
 	} else {
 		info, err := proxyClient.GetInfo(ctx, modulePath, requestedVersion)
 		if err != nil {
@@ -100,8 +109,9 @@ func FetchModule(ctx context.Context, modulePath, requestedVersion string, proxy
 		}
 		fr.ResolvedVersion = info.Version
 		commitTime = info.Time
+		origin = info.Origin
 
-		goModBytes, err := proxyClient.GetMod(ctx, modulePath, fr.ResolvedVersion)
+		goModBytes, err = proxyClient.GetMod(ctx, modulePath, fr.ResolvedVersion)
 		if err != nil {
 			fr.Error = err
 			return fr
@@ -119,23 +129,32 @@ func FetchModule(ctx context.Context, modulePath, requestedVersion string, proxy
 			fr.Error = fmt.Errorf("module path=%s, go.mod path=%s: %w", modulePath, goModPath, derrors.AlternativeModule)
 			return fr
 		}
-		zipReader, err = proxyClient.GetZip(ctx, modulePath, fr.ResolvedVersion)
+		// Keep the zip on disk rather than in memory: large modules would
+		// otherwise use more memory than the worker has.
+		zipFile, err := proxyClient.GetZipFile(ctx, modulePath, fr.ResolvedVersion)
 		if err != nil {
 			fr.Error = err
 			return fr
 		}
+		defer zipFile.Close()
+		zipReader = zipFile.Reader
 	}
 	versionType, err := version.ParseType(fr.ResolvedVersion)
 	if err != nil {
 		fr.Error = fmt.Errorf("%v: %w", err, derrors.BadModule)
 		return fr
 	}
-	mod, pvs, err := processZipFile(ctx, modulePath, versionType, fr.ResolvedVersion, commitTime, zipReader, sourceClient)
+	if cs != nil && zipSize(zipReader) <= minZipSize {
+		cs = nil
+	}
+	mod, pvs, err := processZipFile(ctx, modulePath, versionType, fr.ResolvedVersion, commitTime, zipReader, sourceClient, cs)
 	if err != nil {
 		fr.Error = err
 		return fr
 	}
 	fr.Module = mod
+	fr.Module.Origin = origin
+	fr.Module.Requires = moduleRequires(ctx, modulePath, fr.ResolvedVersion, goModBytes)
 	fr.PackageVersionStates = pvs
 	if modulePath == stdlib.ModulePath {
 		fr.Module.HasGoMod = true
@@ -149,7 +168,7 @@ func FetchModule(ctx context.Context, modulePath, requestedVersion string, proxy
 }
 
 // processZipFile extracts information from the module version zip.
-func processZipFile(ctx context.Context, modulePath string, versionType version.Type, resolvedVersion string, commitTime time.Time, zipReader *zip.Reader, sourceClient *source.Client) (_ *internal.Module, _ []*internal.PackageVersionState, err error) {
+func processZipFile(ctx context.Context, modulePath string, versionType version.Type, resolvedVersion string, commitTime time.Time, zipReader *zip.Reader, sourceClient *source.Client, cs CheckpointStore) (_ *internal.Module, _ []*internal.PackageVersionState, err error) {
 	defer derrors.Wrap(&err, "processZipFile(%q, %q)", modulePath, resolvedVersion)
 
 	ctx, span := trace.StartSpan(ctx, "fetch.processZipFile")
@@ -163,12 +182,16 @@ func processZipFile(ctx context.Context, modulePath string, versionType version.
 	if err != nil {
 		return nil, nil, fmt.Errorf("extractReadmesFromZip(%q, %q, zipReader): %v", modulePath, resolvedVersion, err)
 	}
+	releaseNotes, err := extractReleaseNotes(modulePath, resolvedVersion, zipReader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("extractReleaseNotes(%q, %q, zipReader): %v", modulePath, resolvedVersion, err)
+	}
 	logf := func(format string, args ...interface{}) {
 		log.Infof(ctx, format, args...)
 	}
 	d := licenses.NewDetector(modulePath, resolvedVersion, zipReader, logf)
 	allLicenses := d.AllLicenses()
-	packages, packageVersionStates, err := extractPackagesFromZip(ctx, modulePath, resolvedVersion, zipReader, d, sourceInfo)
+	packages, packageVersionStates, err := extractPackagesFromZip(ctx, modulePath, resolvedVersion, zipReader, d, sourceInfo, cs)
 	if errors.Is(err, errModuleContainsNoPackages) || errors.Is(err, errMalformedZip) {
 		return nil, nil, fmt.Errorf("%v: %w", err.Error(), derrors.BadModule)
 	}
@@ -177,6 +200,13 @@ func processZipFile(ctx context.Context, modulePath string, versionType version.
 	}
 	hasGoMod := zipContainsFilename(zipReader, path.Join(moduleVersionDir(modulePath, resolvedVersion), "go.mod"))
 
+	files := moduleFiles(zipReader, moduleVersionDir(modulePath, resolvedVersion)+"/")
+	var hasExecutables, hasLargeFiles bool
+	for _, f := range files {
+		hasExecutables = hasExecutables || f.Executable
+		hasLargeFiles = hasLargeFiles || f.Size > LargeFileSize
+	}
+
 	var readmeFilePath, readmeContents string
 	for _, r := range readmes {
 		if path.Dir(r.Filepath) != "." {
@@ -195,6 +225,8 @@ func processZipFile(ctx context.Context, modulePath string, versionType version.
 				VersionType:       versionType,
 				IsRedistributable: d.ModuleIsRedistributable(),
 				HasGoMod:          hasGoMod,
+				HasExecutables:    hasExecutables,
+				HasLargeFiles:     hasLargeFiles,
 				SourceInfo:        sourceInfo,
 			},
 			LegacyReadmeFilePath: readmeFilePath,
@@ -203,6 +235,8 @@ func processZipFile(ctx context.Context, modulePath string, versionType version.
 		LegacyPackages: packages,
 		Licenses:       allLicenses,
 		Directories:    moduleDirectories(modulePath, packages, readmes, d),
+		Files:          files,
+		ReleaseNotes:   releaseNotes,
 	}, packageVersionStates, nil
 }
 
@@ -213,24 +247,27 @@ func moduleVersionDir(modulePath, version string) string {
 }
 
 // extractReadmesFromZip returns the file path and contents of all files from r
-// that are README files.
+// that are README files. READMEs excluded by SkipPatterns, or larger than
+// MaxAssetSize, are ignored.
 func extractReadmesFromZip(modulePath, resolvedVersion string, r *zip.Reader) ([]*internal.Readme, error) {
 	var readmes []*internal.Readme
+	prefix := moduleVersionDir(modulePath, resolvedVersion) + "/"
 	for _, zipFile := range r.File {
-		if isReadme(zipFile.Name) {
-			if zipFile.UncompressedSize64 > MaxFileSize {
-				return nil, fmt.Errorf("file size %d exceeds max limit %d", zipFile.UncompressedSize64, MaxFileSize)
-			}
-			c, err := readZipFile(zipFile)
-			if err != nil {
-				return nil, err
-			}
-			readmes = append(readmes, &internal.Readme{
-				Filepath: strings.TrimPrefix(zipFile.Name, moduleVersionDir(modulePath, resolvedVersion)+"/"),
-				Contents: string(c),
-			})
-
+		if !isReadme(zipFile.Name) {
+			continue
 		}
+		relPath := strings.TrimPrefix(zipFile.Name, prefix)
+		if skipPath(relPath) || zipFile.UncompressedSize64 > MaxAssetSize {
+			continue
+		}
+		c, err := readZipFile(zipFile)
+		if err != nil {
+			return nil, err
+		}
+		readmes = append(readmes, &internal.Readme{
+			Filepath: relPath,
+			Contents: string(c),
+		})
 	}
 	return readmes, nil
 }
@@ -258,7 +295,10 @@ func isReadme(file string) bool {
 // * a maximum file size (MaxFileSize)
 // * the particular set of build contexts we consider (goEnvs)
 // * whether the import path is valid.
-func extractPackagesFromZip(ctx context.Context, modulePath, resolvedVersion string, r *zip.Reader, d *licenses.Detector, sourceInfo *source.Info) (_ []*internal.LegacyPackage, _ []*internal.PackageVersionState, err error) {
+//
+// If cs is non-nil, the packages processed are saved to it in batches, and the
+// packages it already has are not processed again.
+func extractPackagesFromZip(ctx context.Context, modulePath, resolvedVersion string, r *zip.Reader, d *licenses.Detector, sourceInfo *source.Info, cs CheckpointStore) (_ []*internal.LegacyPackage, _ []*internal.PackageVersionState, err error) {
 	ctx, span := trace.StartSpan(ctx, "fetch.extractPackagesFromZip")
 	defer span.End()
 	defer func() {
@@ -323,7 +363,7 @@ func extractPackagesFromZip(ctx context.Context, modulePath, resolvedVersion str
 			continue
 		}
 		importPath := path.Join(modulePath, innerPath)
-		if ignoredByGoTool(importPath) || isVendored(importPath) {
+		if ignoredByGoTool(importPath) || isVendored(importPath) || skipPath(f.Name[len(modulePrefix):]) {
 			// File is in a directory we're not looking to process at this time, so skip it.
 			continue
 		}
@@ -371,20 +411,49 @@ func extractPackagesFromZip(ctx context.Context, modulePath, resolvedVersion str
 	// If we got this far, the file metadata was okay.
 	// Start reading the file contents now to extract information
 	// about Go packages.
-	var pkgs []*internal.LegacyPackage
+	var (
+		pkgs []*internal.LegacyPackage
+		// done is the set of package paths processed by an earlier fetch,
+		// from the checkpoint.
+		done  map[string]bool
+		batch Checkpoint
+	)
+	if cs != nil {
+		cp, err := cs.LoadCheckpoint(ctx, modulePath, resolvedVersion)
+		if err != nil {
+			return nil, nil, err
+		}
+		done = map[string]bool{}
+		for _, s := range cp.States {
+			done[s.PackagePath] = true
+		}
+		pkgs = append(pkgs, cp.Packages...)
+		packageVersionStates = append(packageVersionStates, cp.States...)
+		if len(cp.States) > 0 {
+			log.Infof(ctx, "resuming from a checkpoint of %d packages", len(cp.States))
+		}
+	}
 	for innerPath, goFiles := range dirs {
 		if incompleteDirs[innerPath] {
 			// Something went wrong when processing this directory, so we skip.
 			log.Infof(ctx, "Skipping %q because it is incomplete", innerPath)
 			continue
 		}
+		if done[path.Join(modulePath, innerPath)] {
+			continue
+		}
 
 		var (
 			status error
 			errMsg string
 		)
 		pkg, err := loadPackage(ctx, goFiles, innerPath, modulePath, sourceInfo)
-		if bpe := (*BadPackageError)(nil); errors.As(err, &bpe) {
+		if errors.Is(err, derrors.PackageMaxFileSizeLimitExceeded) {
+			// The zip header understated the size of a file.
+			incompleteDirs[innerPath] = true
+			status = derrors.PackageMaxFileSizeLimitExceeded
+			errMsg = err.Error()
+		} else if bpe := (*BadPackageError)(nil); errors.As(err, &bpe) {
 			incompleteDirs[innerPath] = true
 			status = derrors.PackageInvalidContents
 			errMsg = err.Error()
@@ -419,14 +488,29 @@ func extractPackagesFromZip(ctx context.Context, modulePath, resolvedVersion str
 		if status != nil {
 			code = derrors.ToHTTPStatus(status)
 		}
-		packageVersionStates = append(packageVersionStates, &internal.PackageVersionState{
+		state := &internal.PackageVersionState{
 			ModulePath:  modulePath,
 			PackagePath: pkgPath,
 			Version:     resolvedVersion,
 			Status:      code,
 			Error:       errMsg,
-		})
+		}
+		packageVersionStates = append(packageVersionStates, state)
+		if cs != nil {
+			if pkg != nil {
+				batch.Packages = append(batch.Packages, pkg)
+			}
+			batch.States = append(batch.States, state)
+			if len(batch.States) >= CheckpointBatchSize {
+				if err := cs.SaveCheckpoint(ctx, modulePath, resolvedVersion, &batch); err != nil {
+					return nil, nil, err
+				}
+				batch = Checkpoint{}
+			}
+		}
 	}
+	// The last, partial batch is not saved, since the module is about to be
+	// inserted.
 	if len(pkgs) == 0 {
 		return nil, packageVersionStates, errModuleContainsNoPackages
 	}
@@ -471,6 +555,51 @@ func zipContainsFilename(r *zip.Reader, name string) bool {
 	return false
 }
 
+// moduleFiles returns the files in r whose names start with prefix, with
+// the prefix removed, sorted by path. Files excluded by SkipPatterns are
+// included, so that users can see everything a module ships, and executables
+// are marked.
+func moduleFiles(r *zip.Reader, prefix string) []*internal.ModuleFile {
+	var files []*internal.ModuleFile
+	for _, f := range r.File {
+		if !strings.HasPrefix(f.Name, prefix) || strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		files = append(files, &internal.ModuleFile{
+			Path:       strings.TrimPrefix(f.Name, prefix),
+			Size:       int64(f.UncompressedSize64),
+			Executable: isExecutable(f),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+// moduleRequires returns the requirements in the contents of a go.mod file,
+// sorted by path. A go.mod file that does not parse is logged and treated as
+// having no requirements, since the module was already accepted on the
+// strength of its module path alone.
+func moduleRequires(ctx context.Context, modulePath, version string, goModBytes []byte) []*internal.ModuleRequire {
+	if len(goModBytes) == 0 {
+		return nil
+	}
+	mf, err := modfile.ParseLax("go.mod", goModBytes, nil)
+	if err != nil {
+		log.Infof(ctx, "%s@%s: parsing go.mod: %v", modulePath, version, err)
+		return nil
+	}
+	var reqs []*internal.ModuleRequire
+	for _, r := range mf.Require {
+		reqs = append(reqs, &internal.ModuleRequire{
+			Path:     r.Mod.Path,
+			Version:  r.Mod.Version,
+			Indirect: r.Indirect,
+		})
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].Path < reqs[j].Path })
+	return reqs
+}
+
 // BadPackageError represents an error loading a package
 // because its contents do not make up a valid package.
 //
@@ -549,6 +678,7 @@ func loadPackageWithBuildContext(ctx context.Context, goos, goarch string, zipGo
 		allGoFiles      []*ast.File
 		packageName     string
 		packageNameFile string // Name of file where packageName came from.
+		hasTests        bool
 	)
 	for name, b := range files {
 		pf, err := parser.ParseFile(fset, name, b, parser.ParseComments)
@@ -560,6 +690,7 @@ func loadPackageWithBuildContext(ctx context.Context, goos, goarch string, zipGo
 		}
 		allGoFiles = append(allGoFiles, pf)
 		if strings.HasSuffix(name, "_test.go") {
+			hasTests = true
 			continue
 		}
 		goFiles[name] = pf
@@ -679,6 +810,8 @@ func loadPackageWithBuildContext(ctx context.Context, goos, goarch string, zipGo
 		DocumentationHTML: docHTML,
 		GOOS:              goos,
 		GOARCH:            goarch,
+		Quality:           packageQuality(d, hasTests),
+		Usages:            usageSnippets(fset, goFiles, files, modulePath, innerPath, sourceInfo),
 	}, err
 }
 
@@ -686,15 +819,10 @@ func loadPackageWithBuildContext(ctx context.Context, goos, goarch string, zipGo
 // It includes only those files that match the build context determined by goos and goarch.
 func matchingFiles(goos, goarch string, zipGoFiles []*zip.File) (files map[string][]byte, err error) {
 	defer derrors.Wrap(&err, "matchingFiles(%q, %q, zipGoFiles)", goos, goarch)
-	// Populate the map with all the zip files.
-	files = make(map[string][]byte)
+	zipFiles := make(map[string]*zip.File)
 	for _, f := range zipGoFiles {
 		_, name := path.Split(f.Name)
-		b, err := readZipFile(f)
-		if err != nil {
-			return nil, err
-		}
-		files[name] = b
+		zipFiles[name] = f
 	}
 
 	// bctx is used to make decisions about which of the .go files are included
@@ -707,8 +835,11 @@ func matchingFiles(goos, goarch string, zipGoFiles []*zip.File) (files map[strin
 		ReleaseTags: build.Default.ReleaseTags,
 
 		JoinPath: path.Join,
+		// MatchFile reads only the start of each file, so the files are
+		// read from the zip as needed, and only those that match are kept in
+		// memory.
 		OpenFile: func(name string) (io.ReadCloser, error) {
-			return ioutil.NopCloser(bytes.NewReader(files[name])), nil
+			return zipFiles[name].Open()
 		},
 
 		// If left nil, the default implementations of these read from disk,
@@ -722,34 +853,47 @@ func matchingFiles(goos, goarch string, zipGoFiles []*zip.File) (files map[strin
 		ReadDir:       func(string) ([]os.FileInfo, error) { panic("internal error: unexpected call to ReadDir") },
 	}
 
-	for name := range files {
-		match, err := bctx.MatchFile(".", name) // This will access the file we just added to files map above.
+	files = make(map[string][]byte)
+	for name, f := range zipFiles {
+		match, err := bctx.MatchFile(".", name)
 		if err != nil {
 			return nil, &BadPackageError{Err: fmt.Errorf(`bctx.MatchFile(".", %q): %w`, name, err)}
 		}
 		if !match {
 			// Excluded by build context.
-			delete(files, name)
+			continue
+		}
+		b, err := readZipFile(f)
+		if err != nil {
+			return nil, err
 		}
+		files[name] = b
 	}
 	return files, nil
 }
 
 // readZipFile decompresses zip file f and returns its uncompressed contents.
 // The caller can check f.UncompressedSize64 before calling readZipFile to
-// get the expected uncompressed size of f.
+// get the expected uncompressed size of f. If f is larger than MaxFileSize,
+// the error wraps derrors.PackageMaxFileSizeLimitExceeded.
 func readZipFile(f *zip.File) (_ []byte, err error) {
-	defer derrors.Add(&err, "readZipFile(%q)", f.Name)
+	defer derrors.Wrap(&err, "readZipFile(%q)", f.Name)
 
 	r, err := f.Open()
 	if err != nil {
 		return nil, fmt.Errorf("f.Open(): %v", err)
 	}
-	b, err := ioutil.ReadAll(r)
+	// Don't trust the size in the zip header: read at most MaxFileSize bytes,
+	// so that a malicious zip can't exhaust memory.
+	b, err := ioutil.ReadAll(io.LimitReader(r, MaxFileSize+1))
 	if err != nil {
 		r.Close()
 		return nil, fmt.Errorf("ioutil.ReadAll(r): %v", err)
 	}
+	if len(b) > MaxFileSize {
+		r.Close()
+		return nil, fmt.Errorf("file is larger than %d bytes: %w", MaxFileSize, derrors.PackageMaxFileSizeLimitExceeded)
+	}
 	if err := r.Close(); err != nil {
 		return nil, fmt.Errorf("closing: %v", err)
 	}
@@ -102,6 +102,12 @@ func TestFetchModule(t *testing.T) {
 				cmpopts.IgnoreFields(internal.LegacyPackage{}, "DocumentationHTML"),
 				cmpopts.IgnoreFields(internal.Documentation{}, "HTML"),
 				cmpopts.IgnoreFields(internal.PackageVersionState{}, "Error"),
+				// Files is checked by TestModuleFiles.
+				cmpopts.IgnoreFields(internal.Module{}, "Files"),
+				// Quality is checked by TestPackageQuality.
+				cmpopts.IgnoreFields(internal.LegacyPackage{}, "Quality"),
+				// Usages is checked by TestUsageSnippets.
+				cmpopts.IgnoreFields(internal.LegacyPackage{}, "Usages"),
 				cmp.AllowUnexported(source.Info{}),
 				cmpopts.EquateEmpty(),
 			}
@@ -212,7 +218,7 @@ func TestExtractReadmesFromZip(t *testing.T) {
 				err    error
 			)
 			if test.modulePath == stdlib.ModulePath {
-				reader, _, err = stdlib.Zip(test.version)
+				reader, _, _, err = stdlib.Zip(test.version)
 				if err != nil {
 					t.Fatal(err)
 				}
@@ -379,6 +385,94 @@ func TestMatchingFiles(t *testing.T) {
 	}
 }
 
+func TestLoadPackageFileTooLarge(t *testing.T) {
+	// loadPackage is only called on files whose header size was checked, but
+	// it must not trust the header.
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("bar/bar.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, "package bar\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(make([]byte, MaxFileSize)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = loadPackage(context.Background(), zr.File, "bar", "github.com/my/module", nil)
+	if !errors.Is(err, derrors.PackageMaxFileSizeLimitExceeded) {
+		t.Errorf("got %v, want PackageMaxFileSizeLimitExceeded", err)
+	}
+}
+
+func TestModuleFiles(t *testing.T) {
+	data, err := testhelper.ZipContents(map[string]string{
+		"m.com@v1.0.0/go.mod":         "module m.com",
+		"m.com@v1.0.0/README.md":      "README",
+		"m.com@v1.0.0/bin/tool.exe":   "MZ\x90\x00",
+		"m.com@v1.0.0/bin/tool":       "\x7fELF\x02\x01",
+		"m.com@v1.0.0/notes.txt":      "Make zero",
+		"m.com@v1.0.0/vendor/a/a.go":  "package a",
+		"other.com@v1.0.0/other.go":   "package other",
+		"m.com@v1.0.0/a/testdata/x.y": "",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*internal.ModuleFile{
+		{Path: "README.md", Size: 6},
+		{Path: "a/testdata/x.y", Size: 0},
+		{Path: "bin/tool", Size: 6, Executable: true},
+		{Path: "bin/tool.exe", Size: 4, Executable: true},
+		{Path: "go.mod", Size: 12},
+		{Path: "notes.txt", Size: 9},
+		{Path: "vendor/a/a.go", Size: 9},
+	}
+	got := moduleFiles(r, "m.com@v1.0.0/")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("moduleFiles mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestModuleRequires(t *testing.T) {
+	ctx := context.Background()
+	goMod := `module m.com
+
+go 1.14
+
+require (
+	golang.org/x/text v0.3.2
+	example.com/b v1.0.0 // indirect
+)
+
+require example.com/a v0.1.0
+`
+	want := []*internal.ModuleRequire{
+		{Path: "example.com/a", Version: "v0.1.0"},
+		{Path: "example.com/b", Version: "v1.0.0", Indirect: true},
+		{Path: "golang.org/x/text", Version: "v0.3.2"},
+	}
+	got := moduleRequires(ctx, "m.com", "v1.0.0", []byte(goMod))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("moduleRequires mismatch (-want +got):\n%s", diff)
+	}
+	if got := moduleRequires(ctx, "m.com", "v1.0.0", []byte("module m.com\nrequire (\n")); got != nil {
+		t.Errorf("moduleRequires of a bad go.mod = %v, want nil", got)
+	}
+}
+
 func mustParse(fset *token.FileSet, filename, src string) *ast.File {
 	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
 	if err != nil {
@@ -0,0 +1,50 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"path"
+	"strings"
+)
+
+// SkipPatterns are patterns for the files and directories of a module that
+// are not processed for documentation: no packages or READMEs are extracted
+// from them. They are still part of the module zip, and so of its file
+// listing.
+//
+// A pattern without a slash, like "vendor" or "*.pb", matches any element of
+// a file's path relative to the module root, as with path.Match. A pattern
+// with a slash, like "docs/assets", matches a prefix of the relative path.
+//
+// Vendored packages and directories ignored by the go tool, like testdata,
+// are never processed, whatever the patterns. SkipPatterns should be set only
+// at startup.
+var SkipPatterns = []string{"vendor", "testdata"}
+
+// MaxAssetSize is the size above which a file that isn't Go source, like a
+// README, is not read. Modules sometimes ship large generated or binary files,
+// and reading them would only slow processing down.
+const MaxAssetSize = 1 * megabyte
+
+// skipPath reports whether the file at the given path, relative to the module
+// root, is excluded from processing by SkipPatterns.
+func skipPath(relPath string) bool {
+	elems := strings.Split(relPath, "/")
+	for _, p := range SkipPatterns {
+		if strings.Contains(p, "/") {
+			p = strings.Trim(p, "/")
+			if relPath == p || strings.HasPrefix(relPath, p+"/") {
+				return true
+			}
+			continue
+		}
+		for _, e := range elems {
+			if ok, _ := path.Match(p, e); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
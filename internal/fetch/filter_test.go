@@ -0,0 +1,30 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import "testing"
+
+func TestSkipPath(t *testing.T) {
+	defer func(ps []string) { SkipPatterns = ps }(SkipPatterns)
+	SkipPatterns = []string{"vendor", "testdata", "*.pb", "docs/assets"}
+
+	for _, test := range []struct {
+		path string
+		want bool
+	}{
+		{"foo/foo.go", false},
+		{"vendor/example.com/x/x.go", true},
+		{"foo/testdata/README.md", true},
+		{"foo/model.pb", true},
+		{"docs/assets/logo.png", true},
+		{"docs/assets", true},
+		{"docs/README.md", false},
+		{"mydocs/assets/README.md", false},
+	} {
+		if got := skipPath(test.path); got != test.want {
+			t.Errorf("skipPath(%q) = %t, want %t", test.path, got, test.want)
+		}
+	}
+}
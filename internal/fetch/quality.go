@@ -0,0 +1,71 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"go/ast"
+	"go/doc"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/fetch/dochtml"
+)
+
+// packageQuality returns the quality signals of the package documented by d.
+// hasTests reports whether the package has _test.go files.
+func packageQuality(d *doc.Package, hasTests bool) *internal.PackageQuality {
+	q := &internal.PackageQuality{HasTests: hasTests}
+	dochtml.WalkExamples(d, func(string, *doc.Example) {
+		q.HasExamples = true
+	})
+	// count adds n exported identifiers to q, all of which are documented
+	// if doc is not empty.
+	count := func(n int, doc string) {
+		q.NumExported += n
+		if doc != "" {
+			q.NumDocumented += n
+		}
+	}
+	values := func(vs []*doc.Value) {
+		for _, v := range vs {
+			// A value declaration is documented by the comment of its group,
+			// or by the comments of its specs.
+			for _, spec := range v.Decl.Specs {
+				vspec, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				specDoc := v.Doc
+				if vspec.Doc != nil {
+					specDoc += vspec.Doc.Text()
+				}
+				for _, name := range vspec.Names {
+					if name.IsExported() {
+						count(1, specDoc)
+					}
+				}
+			}
+		}
+	}
+	funcs := func(fs []*doc.Func) {
+		for _, f := range fs {
+			if ast.IsExported(f.Name) {
+				count(1, f.Doc)
+			}
+		}
+	}
+	values(d.Consts)
+	values(d.Vars)
+	funcs(d.Funcs)
+	for _, t := range d.Types {
+		if ast.IsExported(t.Name) {
+			count(1, t.Doc)
+		}
+		values(t.Consts)
+		values(t.Vars)
+		funcs(t.Funcs)
+		funcs(t.Methods)
+	}
+	return q
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+)
+
+func TestPackageQuality(t *testing.T) {
+	const src = `
+// Package p is a package.
+package p
+
+// Documented constants.
+const (
+	A = 1
+	B = 2
+)
+
+var (
+	// C is documented.
+	C = 3
+	D = 4
+	e = 5
+)
+
+// T is a type.
+type T struct{}
+
+// NewT returns a T.
+func NewT() *T { return nil }
+
+func (T) M() {}
+
+// F is a function.
+func F() {}
+
+func g() {}
+`
+	const testSrc = `
+package p
+
+func ExampleF() {}
+`
+	for _, test := range []struct {
+		name  string
+		files map[string]string
+		want  *internal.PackageQuality
+	}{
+		{
+			name:  "no tests",
+			files: map[string]string{"p.go": src},
+			want:  &internal.PackageQuality{NumExported: 8, NumDocumented: 6},
+		},
+		{
+			name:  "examples",
+			files: map[string]string{"p.go": src, "p_test.go": testSrc},
+			want:  &internal.PackageQuality{HasTests: true, HasExamples: true, NumExported: 8, NumDocumented: 6},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			var files []*ast.File
+			hasTests := false
+			for name, contents := range test.files {
+				f, err := parser.ParseFile(fset, name, contents, parser.ParseComments)
+				if err != nil {
+					t.Fatal(err)
+				}
+				files = append(files, f)
+				if name == "p_test.go" {
+					hasTests = true
+				}
+			}
+			d, err := doc.NewFromFiles(fset, files, "example.com/p")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, packageQuality(d, hasTests)); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
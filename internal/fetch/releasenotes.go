@@ -0,0 +1,180 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"archive/zip"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal"
+)
+
+// releaseNotesNames are the base names, without extension, of the files at
+// the root of a module that are searched for release notes.
+var releaseNotesNames = []string{"CHANGELOG", "CHANGES", "HISTORY", "NEWS", "RELEASES", "RELEASE_NOTES", "RELEASE-NOTES"}
+
+// maxReleaseNotesSize is the maximum size of the release notes of a version.
+// Longer sections are truncated at a line boundary.
+const maxReleaseNotesSize = 16 * 1024
+
+// isReleaseNotes reports whether the file at the given path relative to the
+// module root is a changelog. It is case insensitive.
+func isReleaseNotes(relPath string) bool {
+	if strings.Contains(relPath, "/") {
+		return false
+	}
+	name := strings.TrimSuffix(relPath, path.Ext(relPath))
+	for _, n := range releaseNotesNames {
+		if strings.EqualFold(name, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractReleaseNotes returns the section about resolvedVersion of the first
+// changelog at the root of the module zip r, in order of file name, that has
+// one. It returns nil if there is no such section.
+func extractReleaseNotes(modulePath, resolvedVersion string, r *zip.Reader) (*internal.Readme, error) {
+	prefix := moduleVersionDir(modulePath, resolvedVersion) + "/"
+	var files []*zip.File
+	for _, f := range r.File {
+		relPath := strings.TrimPrefix(f.Name, prefix)
+		if strings.HasPrefix(f.Name, prefix) && isReleaseNotes(relPath) && f.UncompressedSize64 <= MaxAssetSize {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	for _, f := range files {
+		c, err := readZipFile(f)
+		if err != nil {
+			return nil, err
+		}
+		if section := releaseNotesSection(string(c), resolvedVersion); section != "" {
+			return &internal.Readme{
+				Filepath: strings.TrimPrefix(f.Name, prefix),
+				Contents: section,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+var (
+	atxHeading      = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	setextUnderline = regexp.MustCompile(`^(=+|-+|~+|\^+)\s*$`)
+	// setextLevels maps the characters of underlines to heading levels, as
+	// in Markdown and the usual reStructuredText style.
+	setextLevels = map[byte]int{'=': 1, '-': 2, '~': 3, '^': 4}
+	// plainHeading matches a line of a plain-text changelog that starts with
+	// a version, like "1.2.0 (2020-06-01)" or "Version v1.2.0".
+	plainHeading = regexp.MustCompile(`^(?i:version\s+|release\s+)?\[?v?\d+\.\d+`)
+)
+
+// A heading is a heading of a changelog.
+type heading struct {
+	line  int // index of the first line after the heading
+	start int // index of the first line of the heading
+	level int
+	text  string
+}
+
+// releaseNotesSection returns the section of the changelog contents that is
+// about version, without its heading, or the empty string if there is none.
+//
+// Changelogs have no standard format, so the section is found heuristically:
+// it starts at the first heading that mentions the version, and ends at the
+// next heading of the same or a higher level. Markdown headings, underlined
+// headings as in Markdown and reStructuredText, and, in files without those,
+// unindented lines that start with a version are recognized.
+func releaseNotesSection(contents, version string) string {
+	if !semver.IsValid(version) {
+		return ""
+	}
+	// Build metadata, like "+incompatible", is not part of release names.
+	v := strings.TrimPrefix(version, "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	mentions := regexp.MustCompile(`(?:^|[^0-9A-Za-z.])[vV]?` + regexp.QuoteMeta(v) + `(?:$|[^0-9A-Za-z.+-])`)
+
+	lines := strings.Split(strings.ReplaceAll(contents, "\r\n", "\n"), "\n")
+	headings := changelogHeadings(lines)
+	for i, h := range headings {
+		if !mentions.MatchString(h.text) {
+			continue
+		}
+		end := len(lines)
+		for _, next := range headings[i+1:] {
+			if next.level <= h.level {
+				end = next.start
+				break
+			}
+		}
+		return trimSection(lines[h.line:end])
+	}
+	return ""
+}
+
+// changelogHeadings returns the headings in lines, in order.
+func changelogHeadings(lines []string) []heading {
+	var (
+		hs    []heading
+		fence bool
+	)
+	for i, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			fence = !fence
+		}
+		if fence {
+			continue
+		}
+		if m := atxHeading.FindStringSubmatch(line); m != nil {
+			hs = append(hs, heading{line: i + 1, start: i, level: len(m[1]), text: m[2]})
+			continue
+		}
+		if i > 0 && setextUnderline.MatchString(line) && len(line) >= 3 {
+			prev := lines[i-1]
+			if strings.TrimSpace(prev) == "" || strings.IndexAny(prev[:1], " \t-*+") >= 0 {
+				// Not a title, or a list item followed by a rule.
+				continue
+			}
+			hs = append(hs, heading{line: i + 1, start: i - 1, level: setextLevels[line[0]], text: strings.TrimSpace(prev)})
+		}
+	}
+	if len(hs) > 0 {
+		return hs
+	}
+	for i, line := range lines {
+		if plainHeading.MatchString(line) {
+			hs = append(hs, heading{line: i + 1, start: i, level: 1, text: line})
+		}
+	}
+	return hs
+}
+
+// trimSection joins lines, without leading and trailing blank lines, up to
+// maxReleaseNotesSize bytes.
+func trimSection(lines []string) string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	var b strings.Builder
+	for _, l := range lines {
+		if b.Len()+len(l)+1 > maxReleaseNotesSize {
+			break
+		}
+		b.WriteString(l)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
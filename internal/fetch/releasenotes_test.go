@@ -0,0 +1,106 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/testhelper"
+)
+
+func TestReleaseNotesSection(t *testing.T) {
+	const keepAChangelog = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+
+- Nothing yet.
+
+## [1.2.0] - 2020-06-01
+
+### Added
+
+- The Frob function.
+
+## [1.2.0-rc.1] - 2020-05-01
+
+- Release candidate.
+
+## [1.1.0] - 2020-01-01
+
+- Initial release.
+`
+	const underlined = `Changes
+=======
+
+v1.2.0
+------
+
+* Frob frobs faster.
+
+v1.1.0
+------
+
+* Initial release.
+`
+	const plain = `1.2.0 (2020-06-01)
+  - Frob frobs faster.
+  - 1.1.0 was slow.
+
+1.1.0 (2020-01-01)
+  - Initial release.
+`
+	for _, test := range []struct {
+		name, contents, version, want string
+	}{
+		{"keep a changelog", keepAChangelog, "v1.2.0", "### Added\n\n- The Frob function.\n"},
+		{"prerelease", keepAChangelog, "v1.2.0-rc.1", "- Release candidate.\n"},
+		{"last section", keepAChangelog, "v1.1.0", "- Initial release.\n"},
+		{"missing", keepAChangelog, "v1.0.0", ""},
+		{"not a prefix", keepAChangelog, "v1.2.0-rc", ""},
+		{"underlined", underlined, "v1.2.0", "* Frob frobs faster.\n"},
+		{"plain", plain, "v1.2.0", "  - Frob frobs faster.\n  - 1.1.0 was slow.\n"},
+		{"incompatible", plain, "v1.1.0+incompatible", "  - Initial release.\n"},
+		{"pseudo-version", plain, "v1.2.1-0.20200601000000-abcdefabcdef", ""},
+		{"crlf", "## v1.2.0\r\n\r\nFixed.\r\n", "v1.2.0", "Fixed.\n"},
+		{"fenced", "## v1.1.0\n\n```\n# v1.2.0\n```\n", "v1.2.0", ""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := releaseNotesSection(test.contents, test.version)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("releaseNotesSection mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExtractReleaseNotes(t *testing.T) {
+	data, err := testhelper.ZipContents(map[string]string{
+		"m.com@v1.1.0/go.mod":           "module m.com",
+		"m.com@v1.1.0/CHANGELOG.md":     "## v1.0.0\n\nFirst.\n",
+		"m.com@v1.1.0/NEWS":             "v1.1.0\n  Second.\n",
+		"m.com@v1.1.0/sub/CHANGELOG.md": "## v1.1.0\n\nSub.\n",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := extractReleaseNotes("m.com", "v1.1.0", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &internal.Readme{Filepath: "NEWS", Contents: "  Second.\n"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("extractReleaseNotes mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+const (
+	// maxUsageSnippetLines is the number of lines of the longest usage
+	// snippet. Longer calls are not shown as snippets.
+	maxUsageSnippetLines = 5
+
+	// maxUsageSnippetLen is the length in bytes of the longest usage
+	// snippet.
+	maxUsageSnippetLen = 400
+)
+
+// usageSnippets returns, for each package of another module than modulePath
+// that files call, the first statement of files that calls it, in the order
+// of the file names. contents holds the contents of the files, by name.
+// Calls to the standard library are too common to be worth showing, so they
+// are left out.
+func usageSnippets(fset *token.FileSet, files map[string]*ast.File, contents map[string][]byte, modulePath, innerPath string, sourceInfo *source.Info) []*internal.UsageSnippet {
+	if modulePath == stdlib.ModulePath {
+		return nil
+	}
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var snippets []*internal.UsageSnippet
+	seen := map[string]bool{}
+	for _, name := range names {
+		f := files[name]
+		imports := map[string]string{} // from package name to import path
+		for _, spec := range f.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil || stdlib.Contains(importPath) || importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/") {
+				continue
+			}
+			pkgName := guessPackageName(importPath)
+			if spec.Name != nil {
+				pkgName = spec.Name.Name
+			}
+			if pkgName != "_" && pkgName != "." {
+				imports[pkgName] = importPath
+			}
+		}
+		if len(imports) == 0 {
+			continue
+		}
+		var stack []ast.Node
+		ast.Inspect(f, func(n ast.Node) bool {
+			if n == nil {
+				stack = stack[:len(stack)-1]
+				return true
+			}
+			stack = append(stack, n)
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			id, ok := sel.X.(*ast.Ident)
+			// Identifiers that refer to packages are not resolved by the
+			// parser.
+			if !ok || id.Obj != nil || imports[id.Name] == "" || seen[imports[id.Name]] {
+				return true
+			}
+			// Show the statement of the call if it is short enough, and the
+			// call alone otherwise.
+			code, line := snippet(fset, simpleStmt(stack), contents[name])
+			if code == "" {
+				code, line = snippet(fset, call, contents[name])
+			}
+			if code == "" {
+				return true
+			}
+			importPath := imports[id.Name]
+			seen[importPath] = true
+			s := &internal.UsageSnippet{ImportPath: importPath, Code: code}
+			if sourceInfo != nil {
+				s.URL = sourceInfo.LineURL(path.Join(innerPath, name), line)
+			}
+			snippets = append(snippets, s)
+			return true
+		})
+	}
+	return snippets
+}
+
+// simpleStmt returns the innermost statement in stack, a path from the root
+// of a syntax tree, if it is a statement without a block, or nil.
+func simpleStmt(stack []ast.Node) ast.Node {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.AssignStmt, *ast.DeclStmt, *ast.DeferStmt, *ast.ExprStmt,
+			*ast.GoStmt, *ast.IncDecStmt, *ast.ReturnStmt, *ast.SendStmt:
+			return n
+		case ast.Stmt:
+			return nil
+		}
+	}
+	return nil
+}
+
+// snippet returns the source of n in src, with the indentation of the line
+// it starts on removed from all its lines, and the number of that line. It
+// returns "", 0 if n is too long for a snippet.
+func snippet(fset *token.FileSet, n ast.Node, src []byte) (string, int) {
+	if n == nil {
+		return "", 0
+	}
+	start, end := fset.Position(n.Pos()), fset.Position(n.End())
+	if end.Line-start.Line >= maxUsageSnippetLines || end.Offset-start.Offset > maxUsageSnippetLen || end.Offset > len(src) {
+		return "", 0
+	}
+	line := string(src[start.Offset-(start.Column-1) : start.Offset])
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	lines := strings.Split(string(src[start.Offset:end.Offset]), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimPrefix(l, indent)
+	}
+	return strings.Join(lines, "\n"), start.Line
+}
+
+// majorVersionSuffix matches the major version suffix of the last element of
+// an import path, as in "gopkg.in/yaml.v2", and majorVersionElem a last
+// element that is a major version, as in "github.com/a/b/v2".
+var (
+	majorVersionSuffix = regexp.MustCompile(`\.v[0-9]+$`)
+	majorVersionElem   = regexp.MustCompile(`^v[0-9]+$`)
+)
+
+// guessPackageName returns the likely name of the package at importPath,
+// from the last element of the path that is not a major version.
+func guessPackageName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && majorVersionElem.MatchString(name) {
+		name = elems[len(elems)-2]
+	}
+	name = majorVersionSuffix.ReplaceAllString(name, "")
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+	return strings.Replace(name, "-", "_", -1)
+}
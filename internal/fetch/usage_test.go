@@ -0,0 +1,98 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/source"
+)
+
+func TestUsageSnippets(t *testing.T) {
+	const a = `package p
+
+import (
+	"fmt"
+
+	"example.com/m/internal/util"
+	yaml "gopkg.in/yaml.v2"
+	"github.com/pkg/errors"
+	"github.com/a/b/v2"
+	_ "github.com/lib/pq"
+)
+
+func f(b []byte) error {
+	var v map[string]string
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return errors.Wrap(err,
+			"unmarshaling")
+	}
+	fmt.Println(util.X(v))
+	for range v {
+		// b is the parameter, not the package github.com/a/b/v2.
+		b.Do()
+	}
+	return nil
+}
+`
+	const z = `package p
+
+import "gopkg.in/yaml.v2"
+
+func g() {
+	yaml.Marshal(1)
+}
+`
+	fset := token.NewFileSet()
+	files := map[string]*ast.File{}
+	contents := map[string][]byte{}
+	for name, src := range map[string]string{"a.go": a, "z.go": z} {
+		f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name] = f
+		contents[name] = []byte(src)
+	}
+	info := source.NewGitHubInfo("https://github.com/m/m", "", "v1.0.0")
+	got := usageSnippets(fset, files, contents, "example.com/m", "p", info)
+	want := []*internal.UsageSnippet{
+		{
+			ImportPath: "gopkg.in/yaml.v2",
+			Code:       "err := yaml.Unmarshal(b, &v)",
+			URL:        "https://github.com/m/m/blob/v1.0.0/p/a.go#L15",
+		},
+		{
+			ImportPath: "github.com/pkg/errors",
+			Code:       "return errors.Wrap(err,\n\t\"unmarshaling\")",
+			URL:        "https://github.com/m/m/blob/v1.0.0/p/a.go#L16",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGuessPackageName(t *testing.T) {
+	for _, test := range []struct {
+		importPath, want string
+	}{
+		{"github.com/pkg/errors", "errors"},
+		{"gopkg.in/yaml.v2", "yaml"},
+		{"github.com/a/b/v2", "b"},
+		{"github.com/mattn/go-sqlite3", "sqlite3"},
+		{"github.com/a/b-go", "b"},
+		{"example.com/x-y", "x_y"},
+	} {
+		if got := guessPackageName(test.importPath); got != test.want {
+			t.Errorf("guessPackageName(%q) = %q, want %q", test.importPath, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/middleware"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// apiPrefix is the prefix of the URL paths of the JSON API.
+const apiPrefix = "/api/v1/"
+
+// apiError is the body of an error response from the JSON API.
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// apiHandler returns a handler that serves the value returned by f as JSON.
+// f may set response headers on w, but must not write the body. If f returns
+// an error, the response has the corresponding HTTP status and an apiError as
+// body.
+func apiHandler(f func(w http.ResponseWriter, r *http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		v, err := f(w, r)
+		status := http.StatusOK
+		if err != nil {
+			status = derrors.ToHTTPStatus(err)
+			var serr *serverError
+			if errors.As(err, &serr) {
+				status = serr.status
+			}
+			if status == http.StatusInternalServerError {
+				middleware.RecordRequestError(ctx, err)
+			}
+			w.Header().Del("Cache-Control")
+			v = &apiError{Code: status, Message: http.StatusText(status)}
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			middleware.RecordRequestError(ctx, fmt.Errorf("apiHandler: json.Marshal: %v", err))
+			status = http.StatusInternalServerError
+			data, _ = json.Marshal(&apiError{Code: status, Message: http.StatusText(status)})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if _, err := w.Write(data); err != nil {
+			middleware.RecordRequestError(ctx, fmt.Errorf("apiHandler: error writing response: %v", err))
+		}
+	}
+}
+
+// parseAPIPath parses the part of the URL path of an API request that follows
+// the name of the endpoint, in one of the forms accepted for details pages.
+// The standard library is handled as in serveDetails.
+func parseAPIPath(urlPath string) (fullPath, modulePath, version string, err error) {
+	if parts := strings.SplitN(strings.TrimPrefix(urlPath, "/"), "@", 2); stdlib.Contains(parts[0]) {
+		fullPath, version, err = parseStdLibURLPath(urlPath)
+		modulePath = stdlib.ModulePath
+	} else {
+		fullPath, modulePath, version, err = parseDetailsURLPath(urlPath)
+	}
+	if err != nil {
+		return "", "", "", &serverError{status: http.StatusBadRequest, err: err}
+	}
+	return fullPath, modulePath, version, nil
+}
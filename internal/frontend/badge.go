@@ -0,0 +1,197 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+const (
+	// versionBadgeMaxAge is the max-age of version badges. A new version
+	// may be processed at any time.
+	versionBadgeMaxAge = 60 * 60
+
+	// licenseBadgeMaxAge is the max-age of license badges. Licenses rarely
+	// change between versions.
+	licenseBadgeMaxAge = 24 * 60 * 60
+
+	// unknownBadgeMaxAge is the max-age of badges for paths that are not
+	// known, so that badges appear soon after a path is first fetched.
+	unknownBadgeMaxAge = 5 * 60
+)
+
+const (
+	badgeColor        = "#007d9c"
+	badgeUnknownColor = "#9f9f9f"
+)
+
+// badge is the text of a badge, in two parts: a label and a value.
+type badge struct {
+	Label, Value string
+	Color        string
+}
+
+// handleBadge serves SVG badges for a module or package path, at
+// /badge/<path>/version with the latest tagged version of its module, and at
+// /badge/<path>/license with its license types at the latest version.
+func (s *Server) handleBadge(w http.ResponseWriter, r *http.Request) {
+	if err := s.serveBadge(w, r); err != nil {
+		s.serveError(w, r, err)
+	}
+}
+
+func (s *Server) serveBadge(w http.ResponseWriter, r *http.Request) (err error) {
+	defer derrors.Wrap(&err, "serveBadge(%q)", r.URL.Path)
+
+	fullPath, kind, err := parseBadgePath(r.URL.Path)
+	if err != nil {
+		return &serverError{status: http.StatusNotFound, err: err}
+	}
+	ctx := r.Context()
+	var (
+		b      *badge
+		maxAge int
+	)
+	switch kind {
+	case "version":
+		b, err = s.versionBadge(ctx, fullPath)
+		maxAge = versionBadgeMaxAge
+	case "license":
+		b, err = s.licenseBadge(ctx, fullPath)
+		maxAge = licenseBadgeMaxAge
+	}
+	status := http.StatusOK
+	if errors.Is(err, derrors.NotFound) {
+		// Still serve an image, so that pages embedding the badge show it.
+		b = &badge{Label: kind, Value: "not found", Color: badgeUnknownColor}
+		maxAge = unknownBadgeMaxAge
+		status = http.StatusNotFound
+	} else if err != nil {
+		return err
+	}
+	svg, err := renderBadge(b)
+	if err != nil {
+		return err
+	}
+	scope := "public"
+	if s.private() {
+		scope = "private"
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, maxAge))
+	w.WriteHeader(status)
+	if _, err := w.Write(svg); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseBadgePath returns the module or package path and the kind of badge
+// requested by urlPath, which has the form /badge/<path>/<kind>.
+func parseBadgePath(urlPath string) (fullPath, kind string, err error) {
+	p := strings.TrimPrefix(urlPath, "/badge/")
+	i := strings.LastIndex(p, "/")
+	if i <= 0 {
+		return "", "", fmt.Errorf("no path or kind in %q", urlPath)
+	}
+	fullPath, kind = p[:i], p[i+1:]
+	if kind != "version" && kind != "license" {
+		return "", "", fmt.Errorf("unknown badge kind %q", kind)
+	}
+	return fullPath, kind, nil
+}
+
+// versionBadge returns the badge of the latest tagged version of the module
+// of fullPath. Modules without tagged versions are shown as "untagged".
+func (s *Server) versionBadge(ctx context.Context, fullPath string) (*badge, error) {
+	modulePath, latest, _, err := s.ds.GetPathInfo(ctx, fullPath, internal.UnknownModulePath, internal.LatestVersion)
+	if err != nil {
+		return nil, err
+	}
+	if version.IsPseudo(latest) {
+		// The latest version is a pseudo-version only if there are no
+		// releases, but there may still be prereleases.
+		tagged, err := s.ds.GetTaggedVersionsForModule(ctx, modulePath)
+		if err != nil {
+			return nil, err
+		}
+		if len(tagged) == 0 {
+			return &badge{Label: "version", Value: "untagged", Color: badgeUnknownColor}, nil
+		}
+		latest = tagged[0].Version
+	}
+	return &badge{Label: "version", Value: displayVersion(latest, modulePath), Color: badgeColor}, nil
+}
+
+// licenseBadge returns the badge of the license types that apply to fullPath
+// at its latest version.
+func (s *Server) licenseBadge(ctx context.Context, fullPath string) (*badge, error) {
+	modulePath, latest, isPackage, err := s.ds.GetPathInfo(ctx, fullPath, internal.UnknownModulePath, internal.LatestVersion)
+	if err != nil {
+		return nil, err
+	}
+	var lics []*licenses.License
+	if isPackage {
+		lics, err = s.ds.GetPackageLicenses(ctx, fullPath, modulePath, latest)
+	} else {
+		lics, err = s.ds.GetModuleLicenses(ctx, modulePath, latest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	types := licenseTypes(lics)
+	if len(types) == 0 {
+		return &badge{Label: "license", Value: "none detected", Color: badgeUnknownColor}, nil
+	}
+	return &badge{Label: "license", Value: strings.Join(types, ", "), Color: badgeColor}, nil
+}
+
+// badgeTemplate is a flat badge in the style of shields.io. The widths of
+// the two parts are estimated from the lengths of their text.
+var badgeTemplate = template.Must(template.New("badge").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{html .Label}}: {{html .Value}}">
+  <title>{{html .Label}}: {{html .Value}}</title>
+  <rect width="{{.LabelWidth}}" height="20" fill="#555"/>
+  <rect x="{{.LabelWidth}}" width="{{.ValueWidth}}" height="20" fill="{{.Color}}"/>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="{{.LabelX}}" y="14">{{html .Label}}</text>
+    <text x="{{.ValueX}}" y="14">{{html .Value}}</text>
+  </g>
+</svg>
+`))
+
+// renderBadge returns b as an SVG image.
+func renderBadge(b *badge) ([]byte, error) {
+	textWidth := func(s string) int { return 7*utf8.RuneCountInString(s) + 10 }
+	lw, vw := textWidth(b.Label), textWidth(b.Value)
+	var buf bytes.Buffer
+	err := badgeTemplate.Execute(&buf, struct {
+		*badge
+		Width, LabelWidth, ValueWidth int
+		LabelX, ValueX                float64
+	}{
+		badge:      b,
+		Width:      lw + vw,
+		LabelWidth: lw,
+		ValueWidth: vw,
+		LabelX:     float64(lw) / 2,
+		ValueX:     float64(lw) + float64(vw)/2,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
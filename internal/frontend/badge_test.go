@@ -0,0 +1,89 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestParseBadgePath(t *testing.T) {
+	for _, test := range []struct {
+		urlPath, wantPath, wantKind string
+	}{
+		{"/badge/example.com/m/version", "example.com/m", "version"},
+		{"/badge/example.com/m/p/license", "example.com/m/p", "license"},
+		{"/badge/net/http/version", "net/http", "version"},
+	} {
+		gotPath, gotKind, err := parseBadgePath(test.urlPath)
+		if err != nil {
+			t.Fatalf("parseBadgePath(%q): %v", test.urlPath, err)
+		}
+		if gotPath != test.wantPath || gotKind != test.wantKind {
+			t.Errorf("parseBadgePath(%q) = %q, %q, want %q, %q", test.urlPath, gotPath, gotKind, test.wantPath, test.wantKind)
+		}
+	}
+	for _, bad := range []string{"/badge/", "/badge/version", "/badge/example.com/m/stars"} {
+		if _, _, err := parseBadgePath(bad); err == nil {
+			t.Errorf("parseBadgePath(%q): got no error", bad)
+		}
+	}
+}
+
+func TestRenderBadgeEscapes(t *testing.T) {
+	got, err := renderBadge(&badge{Label: "license", Value: "<A&B>", Color: badgeColor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(got), "<A&B>") || !strings.Contains(string(got), "&lt;A&amp;B&gt;") {
+		t.Errorf("renderBadge did not escape the value:\n%s", got)
+	}
+}
+
+func TestServeBadge(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	for _, v := range []string{"v1.0.0", "v1.1.0-pre"} {
+		if err := testDB.InsertModule(ctx, sample.Module("example.com/m", v, "p")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, test := range []struct {
+		urlPath    string
+		wantStatus int
+		wantText   string
+		wantMaxAge string
+	}{
+		{"/badge/example.com/m/version", http.StatusOK, ">v1.0.0<", "max-age=3600"},
+		{"/badge/example.com/m/p/license", http.StatusOK, ">MIT<", "max-age=86400"},
+		{"/badge/example.com/unknown/version", http.StatusNotFound, ">not found<", "max-age=300"},
+	} {
+		t.Run(test.urlPath, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", test.urlPath, nil))
+			if w.Code != test.wantStatus {
+				t.Fatalf("got status %d, want %d", w.Code, test.wantStatus)
+			}
+			if got := w.Header().Get("Content-Type"); got != "image/svg+xml" {
+				t.Errorf("Content-Type = %q, want image/svg+xml", got)
+			}
+			if got := w.Header().Get("Cache-Control"); got != "public, "+test.wantMaxAge {
+				t.Errorf("Cache-Control = %q, want %q", got, "public, "+test.wantMaxAge)
+			}
+			if !strings.Contains(w.Body.String(), test.wantText) {
+				t.Errorf("body does not contain %q:\n%s", test.wantText, w.Body.String())
+			}
+		})
+	}
+}
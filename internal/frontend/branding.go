@@ -0,0 +1,196 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// Branding holds the parts of the site layout that identify a deployment:
+// its name, logos, navigation and footer links, and colors. It lets private
+// instances rebrand the site without changing templates.
+type Branding struct {
+	// SiteName is appended to the title of every page.
+	SiteName string
+	// Description is the content of the Description meta tag.
+	Description string
+
+	// LogoPath is the URL of the logo shown in the header, on a dark
+	// background. DrawerLogoPath is the URL of the logo shown in the
+	// navigation drawer on small screens, on a light background, and on the
+	// home page.
+	LogoPath       string
+	DrawerLogoPath string
+	LogoAlt        string
+	// LogoLink is where the logos link to.
+	LogoLink string
+
+	// Banner, if non-nil, is shown above the header.
+	Banner *BannerLink
+
+	// NavLinks are shown in the header and in the navigation drawer. A link
+	// whose URL is "/" is highlighted as the current site.
+	NavLinks []Link
+	// FooterColumns are the columns of links at the top of the footer. The
+	// first link of each column is its heading.
+	FooterColumns [][]Link
+	// FooterLinks are shown in a row at the bottom of the footer.
+	FooterLinks []Link
+	// FooterImagePath, if set, is the URL of an image shown at the bottom
+	// left of the footer.
+	FooterImagePath string
+	// ShowGoogleLogo reports whether the Google logo is shown at the bottom
+	// right of the footer.
+	ShowGoogleLogo bool
+
+	// Colors overrides the CSS custom properties defined by the stylesheet,
+	// like "turq-med" or "slate". Keys do not include the leading "--".
+	Colors map[string]string
+}
+
+// Link is a link in the header or footer of the site.
+type Link struct {
+	Title string
+	URL   string
+	// External reports whether the link opens in a new tab.
+	External bool
+}
+
+// BannerLink is a message shown above the header, followed by a link.
+type BannerLink struct {
+	Message string
+	Link
+}
+
+// DefaultBranding returns the branding of pkg.go.dev.
+func DefaultBranding() *Branding {
+	return &Branding{
+		SiteName:       "pkg.go.dev",
+		Description:    "Go is an open source programming language that makes it easy to build simple, reliable, and efficient software.",
+		LogoPath:       "/static/img/go-logo-white.svg",
+		DrawerLogoPath: "/static/img/go-logo-blue.svg",
+		LogoAlt:        "Go",
+		LogoLink:       "https://go.dev/",
+		Banner: &BannerLink{
+			Message: "Black Lives Matter",
+			Link: Link{
+				Title:    "Support the Equal Justice Initiative",
+				URL:      "https://support.eji.org/give/153413/#!/donation/checkout",
+				External: true,
+			},
+		},
+		NavLinks: []Link{
+			{Title: "Why Go", URL: "https://go.dev/solutions"},
+			{Title: "Getting Started", URL: "https://learn.go.dev"},
+			{Title: "Discover Packages", URL: "/"},
+			{Title: "About", URL: "https://go.dev/about"},
+		},
+		FooterColumns: [][]Link{
+			{
+				{Title: "Why Go", URL: "https://go.dev/solutions"},
+				{Title: "Use Cases", URL: "https://go.dev/solutions#use-cases"},
+				{Title: "Case Studies", URL: "https://go.dev/solutions#case-studies"},
+			},
+			{
+				{Title: "Getting Started", URL: "https://learn.go.dev/"},
+				{Title: "Playground", URL: "https://play.golang.org"},
+				{Title: "Tour", URL: "https://tour.golang.org"},
+				{Title: "Stack Overflow", URL: "https://stackoverflow.com/questions/tagged/go?tab=Newest"},
+			},
+			{
+				{Title: "Discover Packages", URL: "https://pkg.go.dev"},
+			},
+			{
+				{Title: "About", URL: "https://go.dev/about"},
+				{Title: "Download", URL: "https://golang.org/dl/"},
+				{Title: "Blog", URL: "https://blog.golang.org"},
+				{Title: "Release Notes", URL: "https://golang.org/doc/devel/release.html"},
+				{Title: "Brand Guidelines", URL: "https://blog.golang.org/go-brand"},
+				{Title: "Code of Conduct", URL: "https://golang.org/conduct"},
+			},
+			{
+				{Title: "Connect", URL: "https://www.twitter.com/golang"},
+				{Title: "Twitter", URL: "https://www.twitter.com/golang"},
+				{Title: "GitHub", URL: "https://github.com/golang"},
+				{Title: "Slack", URL: "https://invite.slack.golangbridge.org/"},
+				{Title: "Meetup", URL: "https://www.meetup.com/pro/go"},
+			},
+		},
+		FooterLinks: []Link{
+			{Title: "Copyright", URL: "https://go.dev/copyright"},
+			{Title: "Terms of Service", URL: "https://go.dev/tos"},
+			{Title: "Privacy Policy", URL: "http://www.google.com/intl/en/policies/privacy/", External: true},
+			{Title: "Report an Issue", URL: "https://golang.org/s/discovery-feedback", External: true},
+			{Title: "golang.org", URL: "https://golang.org", External: true},
+		},
+		FooterImagePath: "/static/img/pilot-bust.svg",
+		ShowGoogleLogo:  true,
+	}
+}
+
+// ReadBranding reads a branding from a JSON file. Fields missing from the
+// file keep their values from DefaultBranding.
+func ReadBranding(filename string) (_ *Branding, err error) {
+	defer derrors.Wrap(&err, "ReadBranding(%q)", filename)
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	b := DefaultBranding()
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, err
+	}
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+var (
+	colorNameRegexp  = regexp.MustCompile(`^[a-zA-Z][-a-zA-Z0-9]*$`)
+	colorValueRegexp = regexp.MustCompile(`^[-#%.,()a-zA-Z0-9 ]+$`)
+)
+
+// validate checks that b can be safely rendered.
+func (b *Branding) validate() error {
+	for name, value := range b.Colors {
+		if !colorNameRegexp.MatchString(name) {
+			return fmt.Errorf("invalid color name %q", name)
+		}
+		if !colorValueRegexp.MatchString(value) {
+			return fmt.Errorf("invalid value %q for color %q", value, name)
+		}
+	}
+	return nil
+}
+
+// ColorStyles returns a CSS rule that sets the custom properties in
+// b.Colors, or the empty string if there are none.
+func (b *Branding) ColorStyles() template.CSS {
+	if len(b.Colors) == 0 {
+		return ""
+	}
+	var names []string
+	for name := range b.Colors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	sb.WriteString(":root {")
+	for _, name := range names {
+		fmt.Fprintf(&sb, " --%s: %s;", name, b.Colors[name])
+	}
+	sb.WriteString(" }")
+	return template.CSS(sb.String())
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadBranding(t *testing.T) {
+	dir, err := ioutil.TempDir("", "branding")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(contents string) string {
+		t.Helper()
+		filename := filepath.Join(dir, "branding.json")
+		if err := ioutil.WriteFile(filename, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+
+	got, err := ReadBranding(write(`{
+		"SiteName": "Example Packages",
+		"Banner": null,
+		"NavLinks": [{"Title": "Packages", "URL": "/"}],
+		"Colors": {"turq-med": "#d35400", "slate": "rgb(27, 42, 73)"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultBranding()
+	want.SiteName = "Example Packages"
+	want.Banner = nil
+	want.NavLinks = []Link{{Title: "Packages", URL: "/"}}
+	want.Colors = map[string]string{"turq-med": "#d35400", "slate": "rgb(27, 42, 73)"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	wantCSS := template.CSS(":root { --slate: rgb(27, 42, 73); --turq-med: #d35400; }")
+	if got := got.ColorStyles(); got != wantCSS {
+		t.Errorf("ColorStyles() = %q, want %q", got, wantCSS)
+	}
+	if got := DefaultBranding().ColorStyles(); got != "" {
+		t.Errorf("DefaultBranding().ColorStyles() = %q, want empty", got)
+	}
+
+	for _, bad := range []string{
+		`{"Colors": {"slate": "red; } body { display: none"}}`,
+		`{"Colors": {"--slate": "red"}}`,
+		`{"Colors": {"slate": "</style>"}}`,
+		`{"SiteName": 1}`,
+	} {
+		if _, err := ReadBranding(write(bad)); err == nil {
+			t.Errorf("ReadBranding(%s): got nil error, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+const (
+	// defaultBrowseLimit is the number of prefixes on a page of the browse
+	// index.
+	defaultBrowseLimit = 100
+
+	// maxBrowsePrefixes is the number of prefixes under a prefix that can be
+	// paged through.
+	maxBrowsePrefixes = 10000
+)
+
+// browsePage is a page of the index of module path prefixes, like
+// github.com, that lets users browse the modules in search by where they are
+// hosted.
+type browsePage struct {
+	basePage
+	// Prefix is the prefix whose prefixes are listed, or empty for the hosts
+	// of all modules.
+	Prefix string
+	// Breadcrumbs link to the prefixes above Prefix, and to the index itself.
+	Breadcrumbs []*BrowseLink
+	Prefixes    []*BrowsePrefix
+	Pagination  pagination
+}
+
+// BrowseLink is a link to a page.
+type BrowseLink struct {
+	Name string
+	URL  string
+}
+
+// BrowsePrefix is a prefix of module paths on a page of the browse index.
+type BrowsePrefix struct {
+	Prefix     string
+	NumModules int
+	// URL is the page listing the modules under Prefix, or empty if the only
+	// module under it is at Prefix.
+	URL string
+	// ModuleURL is the page of the module at Prefix, or empty if there is
+	// none.
+	ModuleURL string
+}
+
+// serveBrowse serves the index of the hosts of modules at /browse/, and the
+// prefixes one path element longer than <prefix> at /browse/<prefix>.
+func (s *Server) serveBrowse(w http.ResponseWriter, r *http.Request) (err error) {
+	defer derrors.Wrap(&err, "serveBrowse(%q)", r.URL.Path)
+
+	db, ok := s.ds.(*postgres.DB)
+	if !ok {
+		// The index is built from aggregate queries.
+		return &serverError{status: http.StatusNotFound}
+	}
+	ctx := r.Context()
+	prefix := strings.Trim(strings.TrimPrefix(r.URL.Path, "/browse/"), "/")
+	params, err := newPaginationParams(r, defaultBrowseLimit, maxBrowsePrefixes)
+	if err != nil {
+		return &serverError{status: http.StatusBadRequest, err: err}
+	}
+	pps, total, err := db.GetPathPrefixes(ctx, prefix, params.limit, params.offset())
+	if err != nil {
+		return err
+	}
+	if total == 0 && prefix != "" {
+		return &serverError{
+			status: http.StatusNotFound,
+			epage: &errorPage{
+				Message:          fmt.Sprintf("There are no known modules under %s.", prefix),
+				SecondaryMessage: suggestedSearch(prefix),
+			},
+			err: fmt.Errorf("no modules under %q: %w", prefix, derrors.NotFound),
+		}
+	}
+	title := "Browse modules"
+	if prefix != "" {
+		title = prefix
+	}
+	page := &browsePage{
+		basePage:    s.newBasePage(r, title),
+		Prefix:      prefix,
+		Breadcrumbs: browseBreadcrumbs(prefix),
+		Pagination:  newPagination(params, len(pps), total),
+	}
+	for _, p := range pps {
+		bp := &BrowsePrefix{Prefix: p.Prefix, NumModules: p.NumModules}
+		if p.IsModule {
+			bp.ModuleURL = "/mod/" + p.Prefix
+		}
+		switch {
+		case isOrgPath(p.Prefix):
+			// Organizations have their own pages.
+			bp.URL = "/" + p.Prefix
+		case !p.IsModule || p.NumModules > 1:
+			bp.URL = "/browse/" + p.Prefix
+		}
+		page.Prefixes = append(page.Prefixes, bp)
+	}
+	s.servePage(ctx, w, "browse.tmpl", page)
+	return nil
+}
+
+// browseBreadcrumbs returns the links to the index and to each prefix above
+// prefix, like the index, github.com and github.com/golang for
+// github.com/golang/go.
+func browseBreadcrumbs(prefix string) []*BrowseLink {
+	if prefix == "" {
+		return nil
+	}
+	links := []*BrowseLink{{Name: "All", URL: "/browse/"}}
+	elems := strings.Split(prefix, "/")
+	for i := range elems[:len(elems)-1] {
+		links = append(links, &BrowseLink{
+			Name: elems[i],
+			URL:  "/browse/" + strings.Join(elems[:i+1], "/"),
+		})
+	}
+	return links
+}
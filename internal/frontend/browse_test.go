@@ -0,0 +1,73 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestServeBrowse(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	for _, m := range []string{"github.com/a/x", "github.com/a/y", "golang.org/x/tools", "golang.org/x/tools/gopls", "golang.org/x/net"} {
+		if err := testDB.InsertModule(ctx, sample.Module(m, sample.VersionString, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, test := range []struct {
+		name, path string
+		wantCode   int
+		wantBody   []string
+	}{
+		{"index", "/browse/", http.StatusOK, []string{`href="/browse/github.com"`, `href="/browse/golang.org"`}},
+		{"org", "/browse/github.com", http.StatusOK, []string{`href="/github.com/a"`}},
+		{
+			"modules",
+			"/browse/golang.org/x",
+			http.StatusOK,
+			[]string{`href="/browse/golang.org/x/tools"`, `href="/mod/golang.org/x/tools"`, `href="/mod/golang.org/x/net"`},
+		},
+		{"unknown prefix", "/browse/example.com", http.StatusNotFound, []string{"example.com"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+			if w.Code != test.wantCode {
+				t.Fatalf("got code %d, want %d", w.Code, test.wantCode)
+			}
+			for _, want := range test.wantBody {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("body does not contain %q:\n%s", want, w.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestBrowseBreadcrumbs(t *testing.T) {
+	got := browseBreadcrumbs("github.com/golang/go")
+	want := []*BrowseLink{
+		{Name: "All", URL: "/browse/"},
+		{Name: "github.com", URL: "/browse/github.com"},
+		{Name: "golang", URL: "/browse/github.com/golang"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if got := browseBreadcrumbs(""); got != nil {
+		t.Errorf("browseBreadcrumbs(%q) = %v, want nil", "", got)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/collection"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// collectionsPage is the page that lists the curated collections of
+// packages, or the packages of one of them.
+type collectionsPage struct {
+	basePage
+	// Collections are all the collections, without their packages. It is
+	// empty if Collection is set.
+	Collections []*collection.Collection
+	// Collection is the collection shown, or nil if the page lists all of
+	// them.
+	Collection *collection.Collection
+}
+
+// serveCollections serves the list of collections at /collections/, and the
+// collection with the given name at /collections/<name>. Collections are
+// curated by administrators, so they are only served from the database.
+func (s *Server) serveCollections(w http.ResponseWriter, r *http.Request) (err error) {
+	defer derrors.Wrap(&err, "serveCollections(%q)", r.URL.Path)
+
+	db, ok := s.ds.(*postgres.DB)
+	if !ok {
+		return &serverError{status: http.StatusNotFound}
+	}
+	ctx := r.Context()
+	page := &collectionsPage{}
+	title := "Collections"
+	name := strings.TrimPrefix(r.URL.Path, "/collections/")
+	if name == "" {
+		page.Collections, err = db.GetCollections(ctx)
+		if err != nil {
+			return err
+		}
+	} else {
+		page.Collection, err = db.GetCollection(ctx, name)
+		if errors.Is(err, derrors.NotFound) {
+			return &serverError{
+				status: http.StatusNotFound,
+				epage:  &errorPage{Message: fmt.Sprintf("There is no collection %q.", name)},
+				err:    err,
+			}
+		}
+		if err != nil {
+			return err
+		}
+		title = page.Collection.Title
+	}
+	page.basePage = s.newBasePage(r, title)
+	s.servePage(ctx, w, "collections.tmpl", page)
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/collection"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestServeCollections(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	if err := testDB.InsertModule(ctx, sample.Module("github.com/gorilla/mux", sample.VersionString, "")); err != nil {
+		t.Fatal(err)
+	}
+	c := &collection.Collection{
+		Name:        "http-routers",
+		Title:       "HTTP routers",
+		Description: "Routers for net/http.",
+		Packages: []*collection.Package{
+			{Path: "github.com/gorilla/mux", Blurb: "A powerful router."},
+			{Path: "github.com/go-chi/chi"},
+		},
+	}
+	if err := testDB.SaveCollection(ctx, c, "a@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name, path string
+		wantCode   int
+		wantBody   []string
+	}{
+		{"index", "/collections/", http.StatusOK, []string{`href="/collections/http-routers"`, "HTTP routers", "Routers for net/http."}},
+		{
+			"collection",
+			"/collections/http-routers",
+			http.StatusOK,
+			[]string{`href="/github.com/gorilla/mux"`, "A powerful router.", sample.Synopsis, `href="/github.com/go-chi/chi"`},
+		},
+		{"unknown collection", "/collections/loggers", http.StatusNotFound, []string{"loggers"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+			if w.Code != test.wantCode {
+				t.Fatalf("got code %d, want %d", w.Code, test.wantCode)
+			}
+			for _, want := range test.wantBody {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("body does not contain %q:\n%s", want, w.Body)
+				}
+			}
+		})
+	}
+}
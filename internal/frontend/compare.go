@@ -0,0 +1,150 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// comparePage is the page that compares two packages side by side, to help
+// users choose between them.
+type comparePage struct {
+	basePage
+	// PathA and PathB are the paths that were asked for.
+	PathA, PathB string
+	// Packages are the compared packages, in the order of their paths. It
+	// is empty if no paths were asked for.
+	Packages []*PackageComparison
+	// Stats is whether the fields of the packages read from the database are
+	// set.
+	Stats bool
+}
+
+// PackageComparison is what the compare page shows about the latest version
+// of a package.
+type PackageComparison struct {
+	Path     string
+	URL      string
+	Synopsis string
+	// Module is the path of the module of the package, shown only if it is
+	// not the path of the package.
+	Module          string
+	Version         string
+	Licenses        []LicenseMetadata
+	CommitTime      string
+	CommitTimestamp string // CommitTime in RFC 3339
+	// The fields below are only set when the frontend reads from the
+	// database.
+	ImportedByCount int
+	// NumFiles is the number of files in the directory of the package, and
+	// Size their total size.
+	NumFiles int
+	Size     string
+	// Quality is nil if the quality signals of the package are not known.
+	Quality *QualitySignals
+}
+
+// serveCompare serves the comparison of the packages with the paths in the
+// "a" and "b" query parameters, at their latest versions. Without them, it
+// serves a form to choose the packages.
+func (s *Server) serveCompare(w http.ResponseWriter, r *http.Request) (err error) {
+	defer derrors.Wrap(&err, "serveCompare(%q)", r.URL.RawQuery)
+
+	ctx := r.Context()
+	page := &comparePage{
+		PathA: strings.TrimSpace(r.FormValue("a")),
+		PathB: strings.TrimSpace(r.FormValue("b")),
+	}
+	title := "Compare packages"
+	if page.PathA != "" || page.PathB != "" {
+		if page.PathA == "" || page.PathB == "" {
+			return &serverError{
+				status: http.StatusBadRequest,
+				epage:  &errorPage{Message: "Enter the paths of two packages to compare."},
+			}
+		}
+		for _, p := range []string{page.PathA, page.PathB} {
+			c, err := s.comparePackage(ctx, p)
+			if err != nil {
+				return err
+			}
+			page.Packages = append(page.Packages, c)
+		}
+		title = fmt.Sprintf("%s vs %s", page.Packages[0].Path, page.Packages[1].Path)
+	}
+	page.basePage = s.newBasePage(r, title)
+	_, page.Stats = s.ds.(*postgres.DB)
+	// Comparisons of any two packages are too many pages to be crawled.
+	page.NoIndex = true
+	s.servePage(ctx, w, "compare.tmpl", page)
+	return nil
+}
+
+// comparePackage returns the comparison of the latest version of the
+// package at pkgPath.
+func (s *Server) comparePackage(ctx context.Context, pkgPath string) (_ *PackageComparison, err error) {
+	defer derrors.Wrap(&err, "comparePackage(%q)", pkgPath)
+
+	pkg, err := s.ds.GetPackage(ctx, pkgPath, internal.UnknownModulePath, internal.LatestVersion, internal.MinimalFields)
+	if errors.Is(err, derrors.NotFound) {
+		return nil, &serverError{
+			status: http.StatusNotFound,
+			epage:  &errorPage{Message: fmt.Sprintf("There is no package %q.", pkgPath)},
+			err:    err,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &PackageComparison{
+		Path:            pkg.Path,
+		URL:             constructPackageURL(pkg.Path, pkg.ModulePath, internal.LatestVersion),
+		Synopsis:        pkg.Synopsis,
+		Version:         displayVersion(pkg.Version, pkg.ModulePath),
+		Licenses:        transformLicenseMetadata(pkg.Licenses),
+		CommitTime:      elapsedTime(ctx, pkg.CommitTime),
+		CommitTimestamp: timestamp(pkg.CommitTime),
+	}
+	if pkg.ModulePath != pkg.Path {
+		c.Module = pkg.ModulePath
+	}
+	db, ok := s.ds.(*postgres.DB)
+	if !ok {
+		return c, nil
+	}
+	c.ImportedByCount, err = db.GetImportedByCount(ctx, pkg.Path, pkg.ModulePath)
+	if err != nil {
+		return nil, err
+	}
+	files, err := db.GetModuleFiles(ctx, pkg.ModulePath, pkg.Version)
+	if err != nil {
+		return nil, err
+	}
+	subdir := packageSubdir(pkg.Path, pkg.ModulePath)
+	var size int64
+	for _, f := range files {
+		if dir := path.Dir(f.Path); dir == subdir || (dir == "." && subdir == "") {
+			c.NumFiles++
+			size += f.Size
+		}
+	}
+	c.Size = formatSize(size)
+	c.Quality, err = fetchQualitySignals(ctx, s.ds, pkg.Path, pkg.ModulePath, pkg.Version)
+	if err != nil && !errors.Is(err, derrors.NotFound) {
+		// A comparison without quality signals is better than none.
+		log.Error(ctx, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestServeCompare(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	for _, m := range []string{"a.com/zap", "b.com/logrus"} {
+		if err := testDB.InsertModule(ctx, sample.Module(m, sample.VersionString, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, test := range []struct {
+		name, query string
+		wantCode    int
+		wantBody    []string
+	}{
+		{"form", "", http.StatusOK, []string{`name="a"`, `name="b"`}},
+		{
+			"compare",
+			"?a=a.com/zap&b=b.com/logrus",
+			http.StatusOK,
+			[]string{"Compare-table", `href="/a.com/zap"`, `href="/b.com/logrus"`, "MIT", "v1.0.0"},
+		},
+		{"one path", "?a=a.com/zap", http.StatusBadRequest, nil},
+		{"unknown package", "?a=a.com/zap&b=c.com/unknown", http.StatusNotFound, []string{"c.com/unknown"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/compare"+test.query, nil))
+			if w.Code != test.wantCode {
+				t.Fatalf("got code %d, want %d", w.Code, test.wantCode)
+			}
+			for _, want := range test.wantBody {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("body does not contain %q:\n%s", want, w.Body)
+				}
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"sort"
@@ -16,23 +17,20 @@ import (
 	"github.com/go-redis/redis/v7"
 	"golang.org/x/pkgsite/internal/complete"
 	"golang.org/x/pkgsite/internal/derrors"
-	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/middleware"
 )
 
 // handleAutoCompletion handles requests for /autocomplete?q=<input prefix>, by
-// querying redis sorted sets indexing package paths.
+// querying redis sorted sets indexing package paths. With format=opensearch,
+// the response is in the format of OpenSearch suggestions, for browsers.
 func (s *Server) handleAutoCompletion(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	var completions []*complete.Completion
-	if s.cmplClient != nil {
-		var err error
-		q := r.FormValue("q")
-		completions, err = doCompletion(r.Context(), s.cmplClient, strings.ToLower(q), 5)
-		if err != nil {
-			code := http.StatusInternalServerError
-			http.Error(w, http.StatusText(code), code)
-			return
-		}
+	q := r.FormValue("q")
+	completions, err := s.completePackages(ctx, q, 5)
+	if err != nil {
+		code := http.StatusInternalServerError
+		http.Error(w, http.StatusText(code), code)
+		return
 	}
 	if completions == nil {
 		// autocomplete.js complains if the JSON returned by this endpoint is null,
@@ -40,13 +38,19 @@ func (s *Server) handleAutoCompletion(w http.ResponseWriter, r *http.Request) {
 		// array.
 		completions = []*complete.Completion{}
 	}
-	response, err := json.Marshal(completions)
+	var body interface{} = completions
+	contentType := "application/json"
+	if r.FormValue("format") == "opensearch" {
+		body = openSearchSuggestions(q, completions)
+		contentType = "application/x-suggestions+json"
+	}
+	response, err := json.Marshal(body)
 	if err != nil {
-		log.Errorf(ctx, "error marshalling completion: json.Marshal: %v", err)
+		middleware.RecordRequestError(ctx, fmt.Errorf("error marshalling completion: json.Marshal: %v", err))
 	}
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", contentType)
 	if _, err := io.Copy(w, bytes.NewReader(response)); err != nil {
-		log.Errorf(ctx, "Error copying json buffer to ResponseWriter: %v", err)
+		middleware.RecordRequestError(ctx, fmt.Errorf("error copying json buffer to ResponseWriter: %v", err))
 	}
 }
 
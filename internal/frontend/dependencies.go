@@ -0,0 +1,149 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"sort"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// DependenciesDetails contains the data needed to render the dependencies
+// tab of a module page, which lists the requirements in the go.mod file and
+// how they changed since an earlier version.
+type DependenciesDetails struct {
+	Requires []*internal.ModuleRequire
+
+	// From is the earlier version that the requirements are compared
+	// with. It is empty if there is no earlier version.
+	From string
+	// Changes are the differences in requirements between From and this
+	// version, sorted by path.
+	Changes []*RequireChange
+	// Versions are the earlier versions that can be compared with.
+	Versions []*DependencyVersion
+}
+
+// DependencyVersion is an earlier version of a module that the
+// dependencies tab can compare with.
+type DependencyVersion struct {
+	Version        string // as in the URL
+	DisplayVersion string
+	Selected       bool
+}
+
+// Kinds of RequireChange.
+const (
+	requireAdded      = "added"
+	requireRemoved    = "removed"
+	requireUpgraded   = "upgraded"
+	requireDowngraded = "downgraded"
+	requireChanged    = "changed"
+)
+
+// RequireChange is a difference in the requirement of a module between two
+// versions of a go.mod file.
+type RequireChange struct {
+	Path string
+	Kind string // one of added, removed, upgraded, downgraded or changed
+	// From and To are the required versions before and after the change.
+	// From is empty for an added requirement, and To for a removed one.
+	From, To string
+	Indirect bool // whether the requirement is indirect, before the change if it was removed
+}
+
+// fetchDependenciesDetails returns the requirements of the given module
+// version, compared with those of the version from, or if from is empty or
+// unknown, of the closest earlier tagged version. Data sources other than
+// the database do not record requirements, and for them the tab is empty.
+func fetchDependenciesDetails(ctx context.Context, ds internal.DataSource, mi *internal.LegacyModuleInfo, from string) (*DependenciesDetails, error) {
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		return &DependenciesDetails{}, nil
+	}
+	reqs, err := db.GetModuleRequires(ctx, mi.ModulePath, mi.Version)
+	if err != nil {
+		return nil, err
+	}
+	dd := &DependenciesDetails{Requires: reqs}
+
+	versions, err := db.GetTaggedVersionsForModule(ctx, mi.ModulePath)
+	if err != nil {
+		return nil, err
+	}
+	var earlier []string
+	for _, v := range versions {
+		if semver.Compare(v.Version, mi.Version) < 0 {
+			earlier = append(earlier, v.Version)
+		}
+	}
+	if len(earlier) == 0 {
+		return dd, nil
+	}
+	// Versions are sorted in descending order, so the first earlier one
+	// is the closest.
+	dd.From = earlier[0]
+	for _, v := range earlier {
+		if v == from || linkVersion(v, mi.ModulePath) == from {
+			dd.From = v
+		}
+	}
+	for _, v := range earlier {
+		dd.Versions = append(dd.Versions, &DependencyVersion{
+			Version:        linkVersion(v, mi.ModulePath),
+			DisplayVersion: displayVersion(v, mi.ModulePath),
+			Selected:       v == dd.From,
+		})
+	}
+	fromReqs, err := db.GetModuleRequires(ctx, mi.ModulePath, dd.From)
+	if err != nil {
+		return nil, err
+	}
+	dd.Changes = diffRequires(fromReqs, reqs)
+	dd.From = displayVersion(dd.From, mi.ModulePath)
+	return dd, nil
+}
+
+// diffRequires returns the differences between the requirements from and
+// to, sorted by path. Requirements whose version did not change are
+// omitted.
+func diffRequires(from, to []*internal.ModuleRequire) []*RequireChange {
+	old := map[string]*internal.ModuleRequire{}
+	for _, r := range from {
+		old[r.Path] = r
+	}
+	var changes []*RequireChange
+	for _, r := range to {
+		o, ok := old[r.Path]
+		delete(old, r.Path)
+		if !ok {
+			changes = append(changes, &RequireChange{Path: r.Path, Kind: requireAdded, To: r.Version, Indirect: r.Indirect})
+			continue
+		}
+		if o.Version == r.Version {
+			continue
+		}
+		c := &RequireChange{Path: r.Path, From: o.Version, To: r.Version, Indirect: r.Indirect}
+		switch n := semver.Compare(r.Version, o.Version); {
+		case n > 0:
+			c.Kind = requireUpgraded
+		case n < 0:
+			c.Kind = requireDowngraded
+		default:
+			// The versions differ only in build metadata, like
+			// "+incompatible".
+			c.Kind = requireChanged
+		}
+		changes = append(changes, c)
+	}
+	for _, o := range old {
+		changes = append(changes, &RequireChange{Path: o.Path, Kind: requireRemoved, From: o.Version, Indirect: o.Indirect})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestDiffRequires(t *testing.T) {
+	from := []*internal.ModuleRequire{
+		{Path: "example.com/down", Version: "v1.2.0"},
+		{Path: "example.com/gone", Version: "v0.1.0", Indirect: true},
+		{Path: "example.com/same", Version: "v1.0.0"},
+		{Path: "example.com/up", Version: "v1.0.0"},
+		{Path: "example.com/x", Version: "v2.0.0"},
+	}
+	to := []*internal.ModuleRequire{
+		{Path: "example.com/down", Version: "v1.1.9"},
+		{Path: "example.com/new", Version: "v0.0.0-20200101000000-abcdefabcdef", Indirect: true},
+		{Path: "example.com/same", Version: "v1.0.0"},
+		{Path: "example.com/up", Version: "v1.0.1"},
+		{Path: "example.com/x", Version: "v2.0.0+incompatible"},
+	}
+	want := []*RequireChange{
+		{Path: "example.com/down", Kind: "downgraded", From: "v1.2.0", To: "v1.1.9"},
+		{Path: "example.com/gone", Kind: "removed", From: "v0.1.0", Indirect: true},
+		{Path: "example.com/new", Kind: "added", To: "v0.0.0-20200101000000-abcdefabcdef", Indirect: true},
+		{Path: "example.com/up", Kind: "upgraded", From: "v1.0.0", To: "v1.0.1"},
+		{Path: "example.com/x", Kind: "changed", From: "v2.0.0", To: "v2.0.0+incompatible"},
+	}
+	if diff := cmp.Diff(want, diffRequires(from, to)); diff != "" {
+		t.Errorf("diffRequires mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFetchDependenciesDetails(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer postgres.ResetTestDB(testDB, t)
+
+	const modulePath = "example.com/m"
+	for _, m := range []struct {
+		version  string
+		requires []*internal.ModuleRequire
+	}{
+		{"v1.0.0", []*internal.ModuleRequire{{Path: "example.com/a", Version: "v1.0.0"}}},
+		{"v1.1.0", []*internal.ModuleRequire{{Path: "example.com/a", Version: "v1.1.0"}}},
+		{"v1.2.0", []*internal.ModuleRequire{{Path: "example.com/a", Version: "v1.1.0"}, {Path: "example.com/b", Version: "v0.1.0"}}},
+	} {
+		mod := sample.Module(modulePath, m.version, "p")
+		mod.Requires = m.requires
+		if err := testDB.InsertModule(ctx, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mi := sample.LegacyModuleInfo(modulePath, "v1.2.0")
+
+	for _, test := range []struct {
+		from string
+		want []*RequireChange
+	}{
+		{"", []*RequireChange{{Path: "example.com/b", Kind: "added", To: "v0.1.0"}}},
+		{"v1.0.0", []*RequireChange{
+			{Path: "example.com/a", Kind: "upgraded", From: "v1.0.0", To: "v1.1.0"},
+			{Path: "example.com/b", Kind: "added", To: "v0.1.0"},
+		}},
+		// A version that is not earlier is ignored.
+		{"v1.3.0", []*RequireChange{{Path: "example.com/b", Kind: "added", To: "v0.1.0"}}},
+	} {
+		got, err := fetchDependenciesDetails(ctx, testDB, mi, test.from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Requires) != 2 || len(got.Versions) != 2 {
+			t.Errorf("from=%q: got %d requirements and %d versions, want 2 and 2", test.from, len(got.Requires), len(got.Versions))
+		}
+		if diff := cmp.Diff(test.want, got.Changes); diff != "" {
+			t.Errorf("from=%q: Changes mismatch (-want +got):\n%s", test.from, diff)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+const (
+	// maxDepsBodySize is the maximum size of a go.mod or go.sum file posted
+	// to the deps endpoint.
+	maxDepsBodySize = 1 << 20
+	// maxDeps is the maximum number of module versions checked by one
+	// request to the deps endpoint.
+	maxDeps = 1000
+)
+
+// DepsJSON is the response of the deps API endpoint.
+type DepsJSON struct {
+	Modules []*DepJSON `json:"modules"`
+}
+
+// DepJSON is the status of a dependency, as served by the deps API endpoint.
+type DepJSON struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	// Known reports whether the module has been processed by the site.
+	Known bool `json:"known"`
+	// Latest is the latest version of the module known to the site.
+	Latest string `json:"latest,omitempty"`
+	// UpdateAvailable reports whether Latest is newer than Version.
+	UpdateAvailable bool `json:"updateAvailable"`
+}
+
+// serveDeps handles POSTs of a go.mod or go.sum file to /api/v1/deps,
+// returning for each module version it requires, or has a checksum for, the
+// latest known version of the module.
+func (s *Server) serveDeps(w http.ResponseWriter, r *http.Request) (_ interface{}, err error) {
+	defer derrors.Wrap(&err, "serveDeps")
+
+	if r.Method != http.MethodPost {
+		return nil, &serverError{status: http.StatusMethodNotAllowed}
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxDepsBodySize))
+	if err != nil {
+		return nil, &serverError{status: http.StatusRequestEntityTooLarge, err: err}
+	}
+	deps, err := parseDeps(body)
+	if err != nil {
+		return nil, &serverError{status: http.StatusBadRequest, err: err}
+	}
+	if len(deps) > maxDeps {
+		return nil, &serverError{status: http.StatusBadRequest, err: fmt.Errorf("%d modules, more than %d", len(deps), maxDeps)}
+	}
+	var paths []string
+	for i, d := range deps {
+		// deps is sorted by path.
+		if i == 0 || d.Path != deps[i-1].Path {
+			paths = append(paths, d.Path)
+		}
+	}
+	latest, err := latestVersions(r.Context(), s.ds, paths)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deps {
+		v, ok := latest[d.Path]
+		if !ok {
+			continue
+		}
+		d.Known = true
+		d.Latest = v
+		d.UpdateAvailable = semver.Compare(v, d.Version) > 0
+	}
+	return &DepsJSON{Modules: deps}, nil
+}
+
+// latestVersions returns the latest versions of the modules with
+// modulePaths that are known to ds, keyed by module path. The database is
+// queried for all of them at once.
+func latestVersions(ctx context.Context, ds internal.DataSource, modulePaths []string) (map[string]string, error) {
+	if db, ok := ds.(*postgres.DB); ok {
+		return db.GetLatestVersions(ctx, modulePaths)
+	}
+	latest := map[string]string{}
+	for _, p := range modulePaths {
+		mi, err := ds.GetModuleInfo(ctx, p, internal.LatestVersion)
+		if errors.Is(err, derrors.NotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		latest[p] = mi.Version
+	}
+	return latest, nil
+}
+
+// parseDeps returns the module versions in the contents of a go.mod or
+// go.sum file, sorted by path and version. The format is detected from the
+// contents: go.sum files only have lines of three fields, the last of which
+// is a hash.
+func parseDeps(contents []byte) ([]*DepJSON, error) {
+	var mvs []module.Version
+	if isGoSum(contents) {
+		seen := map[module.Version]bool{}
+		for _, line := range strings.Split(string(contents), "\n") {
+			f := strings.Fields(line)
+			if len(f) == 0 {
+				continue
+			}
+			mv := module.Version{Path: f[0], Version: strings.TrimSuffix(f[1], "/go.mod")}
+			if !seen[mv] {
+				seen[mv] = true
+				mvs = append(mvs, mv)
+			}
+		}
+	} else {
+		mf, err := modfile.ParseLax("go.mod", contents, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range mf.Require {
+			mvs = append(mvs, r.Mod)
+		}
+	}
+	deps := []*DepJSON{}
+	for _, mv := range mvs {
+		if err := module.Check(mv.Path, mv.Version); err != nil {
+			return nil, err
+		}
+		deps = append(deps, &DepJSON{Path: mv.Path, Version: mv.Version})
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Path != deps[j].Path {
+			return deps[i].Path < deps[j].Path
+		}
+		return semver.Compare(deps[i].Version, deps[j].Version) < 0
+	})
+	return deps, nil
+}
+
+// isGoSum reports whether contents look like a go.sum file.
+func isGoSum(contents []byte) bool {
+	lines := 0
+	for _, line := range strings.Split(string(contents), "\n") {
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			continue
+		}
+		if len(f) != 3 || !strings.HasPrefix(f[2], "h1:") {
+			return false
+		}
+		lines++
+	}
+	return lines > 0
+}
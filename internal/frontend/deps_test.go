@@ -0,0 +1,111 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestParseDeps(t *testing.T) {
+	for _, test := range []struct {
+		name, contents string
+		want           []*DepJSON
+	}{
+		{
+			name: "go.mod",
+			contents: `module example.com/m
+
+require (
+	example.com/b v1.0.0 // indirect
+	example.com/a v0.2.0
+)
+`,
+			want: []*DepJSON{
+				{Path: "example.com/a", Version: "v0.2.0"},
+				{Path: "example.com/b", Version: "v1.0.0"},
+			},
+		},
+		{
+			name: "go.sum",
+			contents: `example.com/a v0.10.0 h1:AAAA=
+example.com/a v0.10.0/go.mod h1:BBBB=
+example.com/a v0.9.0/go.mod h1:CCCC=
+`,
+			want: []*DepJSON{
+				{Path: "example.com/a", Version: "v0.9.0"},
+				{Path: "example.com/a", Version: "v0.10.0"},
+			},
+		},
+		{
+			name:     "empty",
+			contents: "",
+			want:     []*DepJSON{},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseDeps([]byte(test.contents))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("parseDeps mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, bad := range []string{
+		"module m\nrequire (\n",
+		"example.com/a not-a-version h1:AAAA=\n",
+	} {
+		if _, err := parseDeps([]byte(bad)); err == nil {
+			t.Errorf("parseDeps(%q): got no error", bad)
+		}
+	}
+}
+
+func TestServeDeps(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	for _, v := range []string{"v1.0.0", "v1.1.0"} {
+		if err := testDB.InsertModule(ctx, sample.Module("example.com/a", v, "p")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	goMod := "module example.com/m\n\nrequire (\n\texample.com/a v1.0.0\n\texample.com/unknown v0.1.0\n)\n"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/deps", strings.NewReader(goMod)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	var got DepsJSON
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := DepsJSON{Modules: []*DepJSON{
+		{Path: "example.com/a", Version: "v1.0.0", Known: true, Latest: "v1.1.0", UpdateAvailable: true},
+		{Path: "example.com/unknown", Version: "v0.1.0"},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/deps", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: got status %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -6,24 +6,30 @@ package frontend
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
 	"strings"
 
+	"go.opencensus.io/trace"
 	"golang.org/x/mod/module"
 	"golang.org/x/mod/semver"
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/experiment"
 	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/lookalike"
 	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/signals"
 	"golang.org/x/pkgsite/internal/stdlib"
 )
 
-// DetailsPage contains data for a package of module details template.
+// DetailsPage contains data for a package of module details template. With
+// ?format=json, details pages serve it as JSON, without the basePage, so its
+// exported fields are part of a machine interface.
 type DetailsPage struct {
-	basePage
+	basePage       `json:"-"`
 	Title          string
 	CanShowDetails bool
 	Settings       TabSettings
@@ -35,6 +41,69 @@ type DetailsPage struct {
 	// PageType is either "mod", "dir", or "pkg" depending on the details
 	// handler.
 	PageType string
+
+	// LicenseChange is set if the licenses of the module version differ
+	// from those of the version before.
+	LicenseChange *LicenseChange
+
+	// Quality is set on package pages if the quality signals of the package
+	// are known.
+	Quality *QualitySignals
+
+	ModuleState
+}
+
+// ModuleState is the state of a module, set by administrators and background
+// jobs rather than by fetching the module, that details pages show in banners
+// and chips. It is part of the ETag of the page.
+type ModuleState struct {
+	// Lookalike is set if the module path looks confusingly like the path
+	// of a popular module, and the flag has not been dismissed.
+	Lookalike *lookalike.Flag
+
+	// Signals are facts about the module from services outside the site,
+	// shown as chips in the header.
+	Signals []*signals.Signal
+
+	// Archived is set if the repository of the module is archived.
+	Archived *ArchivedRepository
+
+	// Verified is whether a user was verified as an owner of the module.
+	Verified bool
+
+	// SupersededBy is set on package pages to the path of the package that
+	// supersedes the package, if any.
+	SupersededBy string
+}
+
+// moduleState returns the ModuleState of the module at modulePath, whose
+// repository is at repoURL. pkgPath is the path of the package of a package
+// page, or "" for other pages.
+func (s *Server) moduleState(ctx context.Context, modulePath, repoURL, pkgPath string) ModuleState {
+	ms := ModuleState{
+		Lookalike: s.lookalikeFlag(ctx, modulePath),
+		Signals:   s.externalSignals(ctx, modulePath),
+		Archived:  s.archivedRepository(ctx, repoURL),
+		Verified:  s.moduleVerified(ctx, modulePath),
+	}
+	if pkgPath != "" {
+		ms.SupersededBy = s.supersededBy(ctx, pkgPath)
+	}
+	return ms
+}
+
+// serveDetailsPage serves page with the template of its tab or, if the
+// request has the query parameter format=json, serves the page as JSON. The
+// JSON is the same data that the template renders, so it cannot fall out of
+// sync with the HTML.
+func (s *Server) serveDetailsPage(w http.ResponseWriter, r *http.Request, page *DetailsPage) {
+	if r.FormValue("format") != "json" {
+		s.servePage(r.Context(), w, page.Settings.TemplateName, page)
+		return
+	}
+	apiHandler(func(http.ResponseWriter, *http.Request) (interface{}, error) {
+		return page, nil
+	})(w, r)
 }
 
 // serveDetails handles requests for package/directory/module details pages. It
@@ -43,7 +112,7 @@ type DetailsPage struct {
 // be redirected to that path.
 func (s *Server) serveDetails(w http.ResponseWriter, r *http.Request) (err error) {
 	if r.URL.Path == "/" {
-		s.staticPageHandler("index.tmpl", "")(w, r)
+		s.servePage(r.Context(), w, "index.tmpl", s.newBasePage(r, ""))
 		return nil
 	}
 	if r.URL.Path == "/C" {
@@ -84,7 +153,28 @@ func (s *Server) serveDetails(w http.ResponseWriter, r *http.Request) (err error
 		}
 	}
 
-	ctx := r.Context()
+	if !isModule && fullPath != stdlib.ModulePath {
+		// The documentation of packages may be served as text, negotiated
+		// by the Accept header (see docTextFormat), so all the responses for
+		// the path vary with it.
+		w.Header().Add("Vary", "Accept")
+	}
+
+	ctx, span := trace.StartSpan(r.Context(), "frontend.serveDetails")
+	span.AddAttributes(
+		trace.StringAttribute("fullPath", fullPath),
+		trace.StringAttribute("modulePath", modulePath),
+		trace.StringAttribute("version", requestedVersion))
+	defer span.End()
+	r = r.WithContext(ctx)
+	if modulePath == stdlib.ModulePath && requestedVersion == internal.MasterVersion {
+		// The standard library at master is refreshed periodically by the
+		// worker, so serve the most recently processed commit.
+		requestedVersion, err = s.stdlibMasterVersion(ctx, fullPath)
+		if err != nil {
+			return err
+		}
+	}
 	// Validate the fullPath and requestedVersion that were parsed.
 	if err := checkPathAndVersion(ctx, s.ds, fullPath, requestedVersion); err != nil {
 		return err
@@ -104,6 +194,11 @@ func (s *Server) serveDetails(w http.ResponseWriter, r *http.Request) (err error
 				modulePath, fullPath, requestedVersion, r.URL.Path, status, responseText)
 		}()
 	}
+	// Organizations on code hosts, like github.com/golang, are neither
+	// modules nor packages, so list their modules instead.
+	if db, ok := s.ds.(*postgres.DB); ok && !isModule && requestedVersion == internal.LatestVersion && isOrgPath(fullPath) {
+		return s.serveOrgPage(w, r, db, fullPath)
+	}
 	// Depending on what the request was for, return the module or package page.
 	if isModule || fullPath == stdlib.ModulePath {
 		return s.serveModulePage(w, r, fullPath, requestedVersion)
@@ -309,9 +404,35 @@ func parseStdLibURLPath(urlPath string) (path, version string, err error) {
 	if len(parts) == 1 {
 		return path, internal.LatestVersion, nil
 	}
+	if parts[1] == internal.MasterVersion {
+		return path, internal.MasterVersion, nil
+	}
 	version = stdlib.VersionForTag(parts[1])
 	if version == "" {
 		return "", "", fmt.Errorf("invalid Go tag for url: %q", urlPath)
 	}
 	return path, version, nil
 }
+
+// stdlibMasterVersion returns the pseudo-version that the standard library at
+// master was most recently resolved to by the worker.
+func (s *Server) stdlibMasterVersion(ctx context.Context, fullPath string) (_ string, err error) {
+	defer derrors.Wrap(&err, "stdlibMasterVersion(ctx, %q)", fullPath)
+
+	db, ok := s.ds.(*postgres.DB)
+	if !ok {
+		// The proxydatasource cannot resolve master for the standard library.
+		return "", pathNotFoundError(ctx, "package", fullPath, internal.MasterVersion)
+	}
+	vm, err := db.GetVersionMap(ctx, stdlib.ModulePath, internal.MasterVersion)
+	if err != nil {
+		if errors.Is(err, derrors.NotFound) {
+			return "", pathNotFoundError(ctx, "package", fullPath, internal.MasterVersion)
+		}
+		return "", err
+	}
+	if vm.Status >= 400 || !semver.IsValid(vm.ResolvedVersion) {
+		return "", pathNotFoundError(ctx, "package", fullPath, internal.MasterVersion)
+	}
+	return vm.ResolvedVersion, nil
+}
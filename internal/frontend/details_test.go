@@ -6,12 +6,16 @@ package frontend
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/testing/sample"
 )
 
 func TestParseDetailsURLPath(t *testing.T) {
@@ -129,3 +133,67 @@ func TestCheckPathAndVersion(t *testing.T) {
 type fakeDataSource struct {
 	internal.DataSource
 }
+
+func TestServeDetailsPageJSON(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	s, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	if err := testDB.InsertModule(ctx, sample.Module("github.com/a/m", sample.VersionString, "p")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		path, wantPageType, wantTab, wantHeaderPath string
+	}{
+		{"/github.com/a/m/p?tab=doc&format=json", "pkg", "doc", "github.com/a/m/p"},
+		{"/github.com/a/m/p?tab=licenses&format=json", "pkg", "licenses", "github.com/a/m/p"},
+		{"/mod/github.com/a/m?format=json", "mod", "overview", "github.com/a/m"},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", test.path, nil)
+			handler.ServeHTTP(w, r)
+			if w.Code != http.StatusOK {
+				t.Fatalf("got code %d, want 200", w.Code)
+			}
+			// The stale cache does not keep the Content-Type.
+			if _, ok := s.staleCache.get(staleKey(r)); ok {
+				t.Error("the JSON was saved in the stale cache")
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("got Content-Type %q, want application/json", ct)
+			}
+			var got struct {
+				PageType string
+				Settings struct{ Name string }
+				Header   struct {
+					Path       string
+					ModulePath string
+				}
+				Details json.RawMessage
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.PageType != test.wantPageType || got.Settings.Name != test.wantTab {
+				t.Errorf("got page type %q, tab %q; want %q, %q", got.PageType, got.Settings.Name, test.wantPageType, test.wantTab)
+			}
+			headerPath := got.Header.Path
+			if test.wantPageType == "mod" {
+				headerPath = got.Header.ModulePath
+			}
+			if headerPath != test.wantHeaderPath {
+				t.Errorf("got header path %q, want %q", headerPath, test.wantHeaderPath)
+			}
+			if len(got.Details) == 0 || string(got.Details) == "null" {
+				t.Error("got no details")
+			}
+			// The basePage is not part of the JSON.
+			if strings.Contains(w.Body.String(), "HTMLTitle") {
+				t.Error("the JSON has the fields of the basePage")
+			}
+		})
+	}
+}
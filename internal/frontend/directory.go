@@ -31,6 +31,10 @@ type Directory struct {
 	Module
 	Path     string
 	Packages []*Package
+	// Commands holds the commands of the Go distribution, such as cmd/go and
+	// cmd/gofmt. It is only populated for the standard library module
+	// directory; in that case, those commands are not also in Packages.
+	Commands []*Package
 	URL      string
 }
 
@@ -42,11 +46,15 @@ func (s *Server) serveDirectoryPage(ctx context.Context, w http.ResponseWriter,
 		tab = "subdirectories"
 		settings = directoryTabLookup[tab]
 	}
+	state := s.moduleState(ctx, dbDir.ModulePath, dbDir.SourceInfo.RepoURL(), "")
+	if s.serveNotModified(ctx, w, r, dbDir.Path, dbDir.ModulePath, dbDir.Version, "dir", state) {
+		return nil
+	}
 	licenses, err := s.ds.GetModuleLicenses(ctx, dbDir.ModulePath, dbDir.Version)
 	if err != nil {
 		return err
 	}
-	header, err := createDirectory(dbDir, licensesToMetadatas(licenses), false)
+	header, err := createDirectory(ctx, dbDir, licensesToMetadatas(licenses), false)
 	if err != nil {
 		return err
 	}
@@ -68,8 +76,12 @@ func (s *Server) serveDirectoryPage(ctx context.Context, w http.ResponseWriter,
 		CanShowDetails: true,
 		Tabs:           directoryTabSettings,
 		PageType:       "dir",
+		LicenseChange:  s.licenseChange(ctx, dbDir.ModulePath, dbDir.Version),
+		ModuleState:    state,
 	}
-	s.servePage(ctx, w, settings.TemplateName, page)
+	page.NoIndex = noIndex(r, requestedVersion, dbDir.Version)
+	tagModuleVersion(ctx, w, dbDir.ModulePath, dbDir.Version)
+	s.serveDetailsPage(w, r, page)
 	return nil
 }
 
@@ -96,7 +108,7 @@ func fetchDirectoryDetails(ctx context.Context, ds internal.DataSource, dirPath
 		if err != nil {
 			return nil, err
 		}
-		return createDirectory(&internal.LegacyDirectory{
+		return createDirectory(ctx, &internal.LegacyDirectory{
 			LegacyModuleInfo: internal.LegacyModuleInfo{ModuleInfo: *mi},
 			Path:             dirPath,
 			Packages:         pkgs,
@@ -105,7 +117,7 @@ func fetchDirectoryDetails(ctx context.Context, ds internal.DataSource, dirPath
 
 	dbDir, err := ds.GetDirectory(ctx, dirPath, mi.ModulePath, mi.Version, internal.AllFields)
 	if errors.Is(err, derrors.NotFound) {
-		return createDirectory(&internal.LegacyDirectory{
+		return createDirectory(ctx, &internal.LegacyDirectory{
 			LegacyModuleInfo: internal.LegacyModuleInfo{ModuleInfo: *mi},
 			Path:             dirPath,
 			Packages:         nil,
@@ -114,7 +126,7 @@ func fetchDirectoryDetails(ctx context.Context, ds internal.DataSource, dirPath
 	if err != nil {
 		return nil, err
 	}
-	return createDirectory(dbDir, licmetas, includeDirPath)
+	return createDirectory(ctx, dbDir, licmetas, includeDirPath)
 }
 
 // createDirectory constructs a *LegacyDirectory from the provided dbDir and licmetas.
@@ -126,15 +138,15 @@ func fetchDirectoryDetails(ctx context.Context, ds internal.DataSource, dirPath
 // the module path. However, on the package and directory view's
 // "Subdirectories" tab, we do not want to include packages whose import paths
 // are the same as the dirPath.
-func createDirectory(dbDir *internal.LegacyDirectory, licmetas []*licenses.Metadata, includeDirPath bool) (_ *Directory, err error) {
+func createDirectory(ctx context.Context, dbDir *internal.LegacyDirectory, licmetas []*licenses.Metadata, includeDirPath bool) (_ *Directory, err error) {
 	defer derrors.Wrap(&err, "createDirectory(%q, %q, %t)", dbDir.Path, dbDir.Version, includeDirPath)
 
-	var packages []*Package
+	var packages, commands []*Package
 	for _, pkg := range dbDir.Packages {
 		if !includeDirPath && pkg.Path == dbDir.Path {
 			continue
 		}
-		newPkg, err := createPackage(pkg, &dbDir.ModuleInfo, false)
+		newPkg, err := createPackage(ctx, pkg, &dbDir.ModuleInfo, false)
 		if err != nil {
 			return nil, err
 		}
@@ -145,19 +157,31 @@ func createDirectory(dbDir *internal.LegacyDirectory, licmetas []*licenses.Metad
 		if newPkg.PathAfterDirectory == "" {
 			newPkg.PathAfterDirectory = effectiveName(pkg) + " (root)"
 		}
+		if dbDir.Path == stdlib.ModulePath && isGoCommand(pkg) {
+			commands = append(commands, newPkg)
+			continue
+		}
 		packages = append(packages, newPkg)
 	}
-	mod := createModule(&dbDir.ModuleInfo, licmetas, false)
+	mod := createModule(ctx, &dbDir.ModuleInfo, licmetas, false)
 	sort.Slice(packages, func(i, j int) bool { return packages[i].Path < packages[j].Path })
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Path < commands[j].Path })
 
 	return &Directory{
 		Module:   *mod,
 		Path:     dbDir.Path,
 		Packages: packages,
+		Commands: commands,
 		URL:      constructDirectoryURL(dbDir.Path, dbDir.ModulePath, linkVersion(dbDir.Version, dbDir.ModulePath)),
 	}, nil
 }
 
+// isGoCommand reports whether pkg is a command in the Go distribution, such as
+// cmd/go.
+func isGoCommand(pkg *internal.LegacyPackage) bool {
+	return pkg.Name == "main" && strings.HasPrefix(pkg.Path, "cmd/")
+}
+
 func constructDirectoryURL(dirPath, modulePath, linkVersion string) string {
 	if linkVersion == internal.LatestVersion {
 		return fmt.Sprintf("/%s", dirPath)
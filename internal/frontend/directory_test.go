@@ -33,7 +33,7 @@ func TestFetchDirectoryDetails(t *testing.T) {
 		var wantPkgs []*Package
 		for _, suffix := range suffixes {
 			sp := sample.LegacyPackage(modulePath, suffix)
-			pkg, err := createPackage(sp, mi, false)
+			pkg, err := createPackage(ctx, sp, mi, false)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -44,7 +44,7 @@ func TestFetchDirectoryDetails(t *testing.T) {
 			wantPkgs = append(wantPkgs, pkg)
 		}
 
-		mod := createModule(mi, sample.LicenseMetadata, false)
+		mod := createModule(ctx, mi, sample.LicenseMetadata, false)
 		want := &Directory{
 			Module:   *mod,
 			Path:     dirPath,
@@ -201,3 +201,21 @@ func TestFetchDirectoryDetailsInvalidArguments(t *testing.T) {
 		})
 	}
 }
+
+func TestIsGoCommand(t *testing.T) {
+	for _, test := range []struct {
+		path, name string
+		want       bool
+	}{
+		{"cmd/go", "main", true},
+		{"cmd/gofmt", "main", true},
+		{"cmd/internal/obj", "obj", false},
+		{"net/http", "http", false},
+		{"github.com/foo/cmd/bar", "main", false},
+	} {
+		pkg := &internal.LegacyPackage{Path: test.path, Name: test.name}
+		if got := isGoCommand(pkg); got != test.want {
+			t.Errorf("isGoCommand(%q, %q) = %t, want %t", test.path, test.name, got, test.want)
+		}
+	}
+}
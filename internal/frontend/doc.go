@@ -13,6 +13,7 @@ import (
 
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/prefs"
 	"golang.org/x/pkgsite/internal/stdlib"
 )
 
@@ -21,6 +22,15 @@ type DocumentationDetails struct {
 	GOOS          string
 	GOARCH        string
 	Documentation template.HTML
+
+	// PreferredGOOS is the platform that the user prefers documentation for,
+	// if it is not GOOS. The worker renders the documentation of a package
+	// for a single platform.
+	PreferredGOOS string
+
+	// SymbolsURL is the URL from which the jump-to-identifier dialog loads
+	// the symbols in the documentation.
+	SymbolsURL string
 }
 
 // addDocQueryParam controls whether to use a regexp replacement to append
@@ -28,7 +38,7 @@ type DocumentationDetails struct {
 var addDocQueryParam = true
 
 // fetchDocumentationDetails returns a DocumentationDetails constructed from pkg.
-func fetchDocumentationDetails(pkg *internal.LegacyVersionedPackage) *DocumentationDetails {
+func fetchDocumentationDetails(ctx context.Context, pkg *internal.LegacyVersionedPackage) *DocumentationDetails {
 	docHTML := pkg.DocumentationHTML
 	if addDocQueryParam {
 		docHTML = hackUpDocumentation(docHTML)
@@ -37,11 +47,15 @@ func fetchDocumentationDetails(pkg *internal.LegacyVersionedPackage) *Documentat
 		GOOS:          pkg.GOOS,
 		GOARCH:        pkg.GOARCH,
 		Documentation: template.HTML(docHTML),
+		SymbolsURL:    symbolsURL(pkg.Path, pkg.ModulePath, pkg.Version),
+		PreferredGOOS: preferredGOOS(ctx, pkg.GOOS),
 	}
 }
 
-// fetchDocumentationDetails returnsNew a DocumentationDetails constructed from doc.
-func fetchDocumentationDetailsNew(doc *internal.Documentation) *DocumentationDetails {
+// fetchDocumentationDetailsNew returns a DocumentationDetails constructed from
+// the documentation of vdir.
+func fetchDocumentationDetailsNew(ctx context.Context, vdir *internal.VersionedDirectory) *DocumentationDetails {
+	doc := vdir.Package.Documentation
 	docHTML := doc.HTML
 	if addDocQueryParam {
 		docHTML = hackUpDocumentation(docHTML)
@@ -50,9 +64,20 @@ func fetchDocumentationDetailsNew(doc *internal.Documentation) *DocumentationDet
 		GOOS:          doc.GOOS,
 		GOARCH:        doc.GOARCH,
 		Documentation: template.HTML(docHTML),
+		SymbolsURL:    symbolsURL(vdir.Path, vdir.ModulePath, vdir.Version),
+		PreferredGOOS: preferredGOOS(ctx, doc.GOOS),
 	}
 }
 
+// preferredGOOS returns the platform the user of ctx prefers documentation
+// for, if it is not goos, the platform of the documentation.
+func preferredGOOS(ctx context.Context, goos string) string {
+	if p := prefs.FromContext(ctx).GOOS; goos != "" && p != goos {
+		return p
+	}
+	return ""
+}
+
 // packageLinkRegexp matches cross-package identifier links that have been
 // generated by the dochtml package. At the time this hack was added, these
 // links are all constructed to have either the form
@@ -86,5 +111,8 @@ func fileSource(modulePath, version, filePath string) string {
 		log.Errorf(context.TODO(), "fileSource: %v", err)
 		return fmt.Sprintf("%s/+/refs/heads/master/%s", root, filePath)
 	}
+	if tag == internal.MasterVersion {
+		return fmt.Sprintf("%s/+/refs/heads/master/%s", root, filePath)
+	}
 	return fmt.Sprintf("%s/+/refs/tags/%s/%s", root, tag, filePath)
 }
@@ -0,0 +1,414 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// DocJSON is the documentation of a package, as served by the docjson API
+// endpoint.
+type DocJSON struct {
+	Path       string `json:"path"`
+	ModulePath string `json:"modulePath"`
+	Version    string `json:"version"`
+	Name       string `json:"name"`
+	Synopsis   string `json:"synopsis"`
+
+	// Doc is the package comment, as text.
+	Doc      string         `json:"doc,omitempty"`
+	Examples []*ExampleJSON `json:"examples,omitempty"`
+	Consts   []*DeclJSON    `json:"consts,omitempty"`
+	Vars     []*DeclJSON    `json:"vars,omitempty"`
+	Funcs    []*DeclJSON    `json:"funcs,omitempty"`
+	Types    []*TypeJSON    `json:"types,omitempty"`
+}
+
+// DeclJSON is a declaration of one or more constants, variables, or a function
+// or method.
+type DeclJSON struct {
+	// Name is the name of a function or method. It is empty for constants and
+	// variables.
+	Name string `json:"name,omitempty"`
+	// Names are the names of the constants or variables declared.
+	Names []string `json:"names,omitempty"`
+	// Recv is the receiver of a method, like "*T".
+	Recv     string         `json:"recv,omitempty"`
+	Decl     string         `json:"decl"`
+	Doc      string         `json:"doc,omitempty"`
+	Examples []*ExampleJSON `json:"examples,omitempty"`
+}
+
+// TypeJSON is the declaration of a type, along with the constants, variables,
+// functions and methods associated with it.
+type TypeJSON struct {
+	Name     string         `json:"name"`
+	Decl     string         `json:"decl"`
+	Doc      string         `json:"doc,omitempty"`
+	Examples []*ExampleJSON `json:"examples,omitempty"`
+	Consts   []*DeclJSON    `json:"consts,omitempty"`
+	Vars     []*DeclJSON    `json:"vars,omitempty"`
+	Funcs    []*DeclJSON    `json:"funcs,omitempty"`
+	Methods  []*DeclJSON    `json:"methods,omitempty"`
+}
+
+// ExampleJSON is an example.
+type ExampleJSON struct {
+	// ID is the anchor of the example on the package page, like "example-Foo-Bar".
+	ID     string `json:"id"`
+	Suffix string `json:"suffix,omitempty"`
+	Doc    string `json:"doc,omitempty"`
+	Code   string `json:"code"`
+	Output string `json:"output,omitempty"`
+}
+
+// serveDocJSON handles requests for /api/v1/docjson/<path>[@<version>],
+// returning the documentation of the package as a DocJSON.
+func (s *Server) serveDocJSON(w http.ResponseWriter, r *http.Request) (_ interface{}, err error) {
+	defer derrors.Wrap(&err, "serveDocJSON(%q)", r.URL.Path)
+
+	ctx := r.Context()
+	fullPath, modulePath, version, err := parseAPIPath(strings.TrimPrefix(r.URL.Path, apiPrefix+"docjson"))
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := s.ds.GetPackage(ctx, fullPath, modulePath, version, internal.WithDocumentationHTML)
+	if err != nil {
+		return nil, err
+	}
+	doc := &DocJSON{}
+	// As on the package page, documentation is not displayed for packages
+	// that are not redistributable.
+	if pkg.LegacyPackage.IsRedistributable {
+		doc, err = docHTMLToJSON(pkg.DocumentationHTML)
+		if err != nil {
+			return nil, err
+		}
+	}
+	doc.Path = pkg.Path
+	doc.ModulePath = pkg.ModulePath
+	doc.Version = pkg.Version
+	doc.Name = pkg.Name
+	doc.Synopsis = pkg.Synopsis
+	return doc, nil
+}
+
+// docHTMLToJSON recovers the structure of the documentation of a package from
+// the HTML rendered by the dochtml package. The HTML is the only form in which
+// documentation is stored, and its elements are marked with classes for each
+// kind of declaration.
+func docHTMLToJSON(docHTML string) (_ *DocJSON, err error) {
+	defer derrors.Wrap(&err, "docHTMLToJSON")
+
+	root, err := html.Parse(strings.NewReader(docHTML))
+	if err != nil {
+		return nil, err
+	}
+	doc := &DocJSON{}
+	walkElements(root, func(n *html.Node) bool {
+		switch {
+		case hasClass(n, "Documentation-overview"):
+			doc.Doc, doc.Examples = parseDocBody(n)
+		case hasClass(n, "Documentation-constants"):
+			doc.Consts = parseValueDecls(n)
+		case hasClass(n, "Documentation-variables"):
+			doc.Vars = parseValueDecls(n)
+		case hasClass(n, "Documentation-function"):
+			doc.Funcs = append(doc.Funcs, parseFuncDecl(n))
+		case hasClass(n, "Documentation-type"):
+			doc.Types = append(doc.Types, parseTypeDecl(n))
+		default:
+			return true
+		}
+		return false
+	})
+	return doc, nil
+}
+
+// parseTypeDecl parses a div of class Documentation-type.
+func parseTypeDecl(n *html.Node) *TypeJSON {
+	t := &TypeJSON{Name: headingID(n)}
+	t.Decl, t.Doc, t.Examples = parseDecl(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case hasClass(c, "Documentation-typeConstant"):
+			t.Consts = append(t.Consts, parseValueDecls(c)...)
+		case hasClass(c, "Documentation-typeVariable"):
+			t.Vars = append(t.Vars, parseValueDecls(c)...)
+		case hasClass(c, "Documentation-typeFunc"):
+			t.Funcs = append(t.Funcs, parseFuncDecl(c))
+		case hasClass(c, "Documentation-typeMethod"):
+			m := parseFuncDecl(c)
+			m.Name = strings.TrimPrefix(m.Name, t.Name+".")
+			t.Methods = append(t.Methods, m)
+		}
+	}
+	return t
+}
+
+// parseFuncDecl parses a div holding a function or method.
+func parseFuncDecl(n *html.Node) *DeclJSON {
+	d := &DeclJSON{Name: headingID(n)}
+	d.Decl, d.Doc, d.Examples = parseDecl(n)
+	if hasClass(n, "Documentation-typeMethod") {
+		// The declaration starts with "func (recv) Name(".
+		if i := strings.Index(d.Decl, "("); i >= 0 {
+			if j := strings.Index(d.Decl[i:], ")"); j >= 0 {
+				fields := strings.Fields(d.Decl[i+1 : i+j])
+				if len(fields) > 0 {
+					d.Recv = fields[len(fields)-1]
+				}
+			}
+		}
+	}
+	return d
+}
+
+// parseDecl returns the declaration, documentation and examples of the
+// element n, which holds a heading followed by the declaration in a pre
+// element, its documentation and its examples.
+func parseDecl(n *html.Node) (decl, doc string, examples []*ExampleJSON) {
+	var body []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch {
+		case c.DataAtom == atom.H3 && decl == "" && body == nil:
+		case c.DataAtom == atom.Pre && decl == "":
+			decl = preText(c)
+		case c.DataAtom == atom.Details:
+			examples = append(examples, parseExample(c))
+		case c.DataAtom == atom.Div:
+			// Declarations associated with a type.
+		default:
+			body = append(body, c)
+		}
+	}
+	return decl, blocksText(body), examples
+}
+
+// parseValueDecls parses the constant or variable declarations in n. Each
+// declaration is a pre element starting with "const" or "var", followed by its
+// documentation.
+func parseValueDecls(n *html.Node) []*DeclJSON {
+	var (
+		decls []*DeclJSON
+		body  []*html.Node
+	)
+	flush := func() {
+		if len(decls) > 0 {
+			decls[len(decls)-1].Doc = blocksText(body)
+		}
+		body = nil
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || (c.DataAtom == atom.H3 && decls == nil) {
+			// Skip the section heading.
+			continue
+		}
+		if c.DataAtom == atom.Pre {
+			if text := preText(c); strings.HasPrefix(text, "const") || strings.HasPrefix(text, "var") {
+				flush()
+				decls = append(decls, &DeclJSON{Decl: text, Names: declaredNames(c)})
+				continue
+			}
+		}
+		body = append(body, c)
+	}
+	flush()
+	return decls
+}
+
+// declaredNames returns the names of the constants or variables declared in
+// the pre element n, which the dochtml package marks with spans having a
+// data-kind attribute.
+func declaredNames(n *html.Node) []string {
+	var names []string
+	walkElements(n, func(c *html.Node) bool {
+		if k := getAttr(c, "data-kind"); k == "constant" || k == "variable" {
+			names = append(names, getAttr(c, "id"))
+		}
+		return true
+	})
+	return names
+}
+
+// parseDocBody returns the documentation and examples in n, which has a
+// heading followed by documentation and examples.
+func parseDocBody(n *html.Node) (doc string, examples []*ExampleJSON) {
+	var body []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type != html.ElementNode:
+		case c.DataAtom == atom.H2:
+		case c.DataAtom == atom.Details:
+			examples = append(examples, parseExample(c))
+		default:
+			body = append(body, c)
+		}
+	}
+	return blocksText(body), examples
+}
+
+// parseExample parses a details element of class
+// Documentation-exampleDetails.
+func parseExample(n *html.Node) *ExampleJSON {
+	ex := &ExampleJSON{ID: getAttr(n, "id")}
+	walkElements(n, func(c *html.Node) bool {
+		switch {
+		case c.DataAtom == atom.Summary:
+			s := strings.TrimSpace(strings.ReplaceAll(nodeText(c), "¶", ""))
+			s = strings.TrimSpace(strings.TrimPrefix(s, "Example"))
+			ex.Suffix = strings.TrimSuffix(strings.TrimPrefix(s, "("), ")")
+			return false
+		case hasClass(c, "Documentation-exampleDetailsBody"):
+			ex.Doc, ex.Code, ex.Output = parseExampleBody(c)
+			return false
+		}
+		return true
+	})
+	return ex
+}
+
+// parseExampleBody parses the body of an example: its documentation, a "Code:"
+// paragraph followed by the code, and an optional "Output:" paragraph
+// followed by the output.
+func parseExampleBody(n *html.Node) (doc, code, output string) {
+	var (
+		body []*html.Node
+		next *string
+	)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if c.DataAtom == atom.P {
+			switch text := strings.TrimSpace(nodeText(c)); {
+			case text == "Code:":
+				next = &code
+				continue
+			case text == "Output:" || text == "Unordered output:":
+				next = &output
+				continue
+			case hasClassDescendant(c, "Documentation-examplesPlay"):
+				continue
+			}
+		}
+		if next != nil && c.DataAtom == atom.Pre {
+			*next = preText(c)
+			next = nil
+			continue
+		}
+		body = append(body, c)
+	}
+	return blocksText(body), code, output
+}
+
+// blocksText returns the text of the documentation blocks in nodes, in the
+// form of a Go doc comment: paragraphs separated by blank lines and
+// preformatted text indented by a tab.
+func blocksText(nodes []*html.Node) string {
+	var blocks []string
+	for _, n := range nodes {
+		var s string
+		if n.DataAtom == atom.Pre {
+			lines := strings.Split(preText(n), "\n")
+			for i, l := range lines {
+				if l != "" {
+					lines[i] = "\t" + l
+				}
+			}
+			s = strings.Join(lines, "\n")
+		} else {
+			s = strings.Join(strings.Fields(strings.ReplaceAll(nodeText(n), "¶", "")), " ")
+		}
+		if s != "" {
+			blocks = append(blocks, s)
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// preText returns the text of a pre element, without its leading and trailing
+// newlines.
+func preText(n *html.Node) string {
+	return strings.Trim(nodeText(n), "\n")
+}
+
+// nodeText returns the concatenated text of n and its descendants.
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// headingID returns the id of the first h3 child of n.
+func headingID(n *html.Node) string {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.DataAtom == atom.H3 {
+			return getAttr(c, "id")
+		}
+	}
+	return ""
+}
+
+// walkElements calls f on each element in the tree rooted at n, in depth-first
+// order. The children of an element are visited only if f returns true.
+func walkElements(n *html.Node, f func(*html.Node) bool) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && !f(c) {
+			continue
+		}
+		walkElements(c, f)
+	}
+}
+
+func hasClassDescendant(n *html.Node, class string) bool {
+	found := false
+	walkElements(n, func(c *html.Node) bool {
+		if hasClass(c, class) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func hasClass(n *html.Node, class string) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	for _, c := range strings.Fields(getAttr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func getAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
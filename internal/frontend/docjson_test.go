@@ -0,0 +1,143 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDocHTMLToJSON(t *testing.T) {
+	docHTML := `<nav class="Documentation-nav"><ul class="Documentation-toc"><li>Overview</li></ul></nav>
+<div>
+<section class="Documentation-overview">
+	<h2 id="pkg-overview" class="Documentation-overviewHeader">Overview <a href="#pkg-overview">¶</a></h2>
+<p>Package p does things.
+</p>
+<pre>
+p.Do()
+</pre>
+</section>
+<section class="Documentation-constants">
+	<h3 id="pkg-constants" class="Documentation-constantsHeader">Constants <a href="#pkg-constants">¶</a></h3>
+<pre>
+const <span id="Max" data-kind="constant"></span>Max = 10
+</pre>
+<p>Max is the maximum.
+</p>
+<pre>
+const (
+	<span id="A" data-kind="constant"></span>A = 1
+	<span id="B" data-kind="constant"></span>B = 2
+)
+</pre>
+</section>
+<section class="Documentation-functions">
+	<div class="Documentation-function">
+		<h3 id="Do" data-kind="function" class="Documentation-functionHeader">func <a class="Documentation-source" href="/src">Do</a> <a href="#Do">¶</a></h3>
+<pre>
+func Do() error
+</pre>
+<p>Do does it.
+</p>
+<details id="example-Do" class="Documentation-exampleDetails">
+	<summary class="Documentation-exampleDetailsHeader">Example <a href="#example-Do">¶</a></summary>
+	<div class="Documentation-exampleDetailsBody">
+<p>Code:</p>
+<pre>
+p.Do()
+</pre>
+<p>Output:</p>
+<pre>
+done
+</pre>
+	</div>
+</details>
+	</div>
+</section>
+<section class="Documentation-types">
+	<div class="Documentation-type">
+		<h3 id="T" data-kind="type" class="Documentation-typeHeader">type <a class="Documentation-source" href="/src">T</a> <a href="#T">¶</a></h3>
+<pre>
+type T struct{}
+</pre>
+<p>T is a type.
+</p>
+		<div class="Documentation-typeFunc">
+			<h3 id="New" data-kind="function" class="Documentation-typeFuncHeader">func <a href="/src">New</a> <a href="#New">¶</a></h3>
+<pre>
+func New() *T
+</pre>
+		</div>
+		<div class="Documentation-typeMethod">
+			<h3 id="T.Run" data-kind="method" class="Documentation-typeMethodHeader">func (t *T) <a href="/src">Run</a> <a href="#T.Run">¶</a></h3>
+<pre>
+func (t *<a href="#T">T</a>) Run(n int)
+</pre>
+<p>Run runs n times.
+</p>
+<details id="example-T.Run-fast" class="Documentation-exampleDetails">
+	<summary class="Documentation-exampleDetailsHeader">Example (Fast) <a href="#example-T.Run-fast">¶</a></summary>
+	<div class="Documentation-exampleDetailsBody">
+<p>Run it fast.
+</p>
+<p><a class="Documentation-examplesPlay" href="/play">Open in Go playground »</a></p>
+<p>Code:</p>
+<pre>
+t.Run(1)
+</pre>
+	</div>
+</details>
+		</div>
+	</div>
+</section>
+</div>`
+	want := &DocJSON{
+		Doc: "Package p does things.\n\n\tp.Do()",
+		Consts: []*DeclJSON{
+			{Names: []string{"Max"}, Decl: "const Max = 10", Doc: "Max is the maximum."},
+			{Names: []string{"A", "B"}, Decl: "const (\n\tA = 1\n\tB = 2\n)"},
+		},
+		Funcs: []*DeclJSON{
+			{
+				Name: "Do",
+				Decl: "func Do() error",
+				Doc:  "Do does it.",
+				Examples: []*ExampleJSON{
+					{ID: "example-Do", Code: "p.Do()", Output: "done"},
+				},
+			},
+		},
+		Types: []*TypeJSON{
+			{
+				Name: "T",
+				Decl: "type T struct{}",
+				Doc:  "T is a type.",
+				Funcs: []*DeclJSON{
+					{Name: "New", Decl: "func New() *T"},
+				},
+				Methods: []*DeclJSON{
+					{
+						Name: "Run",
+						Recv: "*T",
+						Decl: "func (t *T) Run(n int)",
+						Doc:  "Run runs n times.",
+						Examples: []*ExampleJSON{
+							{ID: "example-T.Run-fast", Suffix: "Fast", Doc: "Run it fast.", Code: "t.Run(1)"},
+						},
+					},
+				},
+			},
+		},
+	}
+	got, err := docHTMLToJSON(docHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"net/http"
+	"time"
+)
+
+// docPrintPage is the documentation of a package laid out for printing, or
+// saving as PDF from the print dialog of a browser. It records the exact
+// version of the documentation, so that it can be archived.
+type docPrintPage struct {
+	basePage
+	Title  string
+	Header *Package
+	// Doc is the documentation, or nil if it cannot be shown because of
+	// its license.
+	Doc *DocumentationDetails
+	// Permalink is the URL of the documentation at its exact version.
+	Permalink string
+	// PrintedAt is the time the page was rendered, in RFC 3339.
+	PrintedAt string
+}
+
+// wantsDocPrint reports whether r asks for the documentation laid out for
+// printing, with ?format=print. ?format=pdf is the same: the PDF is saved
+// from the print dialog of the browser.
+func wantsDocPrint(r *http.Request) bool {
+	switch r.FormValue("format") {
+	case "print", "pdf":
+		return true
+	}
+	return false
+}
+
+// serveDocPrint serves the documentation doc of the package with the given
+// header and title laid out for printing. doc is nil if the package is not
+// redistributable.
+func (s *Server) serveDocPrint(w http.ResponseWriter, r *http.Request, title string, header *Package, doc *DocumentationDetails) {
+	page := &docPrintPage{
+		basePage:  s.newBasePage(r, title+" - "+header.Module.LinkVersion),
+		Title:     title,
+		Header:    header,
+		Doc:       doc,
+		Permalink: siteURL(r) + constructPackageURL(header.Path, header.ModulePath, header.LinkVersion) + "?tab=doc",
+		PrintedAt: timestamp(time.Now()),
+	}
+	page.NoIndex = true
+	s.servePage(r.Context(), w, "doc_print.tmpl", page)
+}
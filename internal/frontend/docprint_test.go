@@ -0,0 +1,51 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestDocPrint(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	if err := testDB.InsertModule(ctx, sample.DefaultModule()); err != nil {
+		t.Fatal(err)
+	}
+
+	permalink := "https://example.com/" + sample.ModulePath + "@" + sample.VersionString + "/" + sample.Suffix + "?tab=doc"
+	for _, format := range []string{"print", "pdf"} {
+		t.Run(format, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/"+sample.PackagePath+"?format="+format, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			body := w.Body.String()
+			for _, want := range []string{
+				`class="DocPrint-info"`,
+				permalink,
+				sample.DocumentationHTML,
+				"GOOS=" + sample.GOOS,
+			} {
+				if !strings.Contains(body, want) {
+					t.Errorf("page does not contain %q", want)
+				}
+			}
+			if strings.Contains(body, `class="DetailsNav`) {
+				t.Error("page contains the tabs of the details page")
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// plainTextWidth is the column at which paragraphs of plain-text documentation
+// are wrapped.
+const plainTextWidth = 80
+
+// wantsPlainText reports whether the client asked for a package page as plain
+// text, either with the format=txt query parameter or with an Accept header
+// that prefers text/plain to text/html.
+//
+// Command-line clients like curl send "Accept: */*", so they are also served
+// plain text when their User-Agent identifies them.
+func wantsPlainText(r *http.Request) bool {
+	if f := r.FormValue("format"); f != "" {
+		return f == "txt"
+	}
+	accept := r.Header.Get("Accept")
+	if strings.Contains(accept, "text/html") {
+		return false
+	}
+	if strings.Contains(accept, "text/plain") {
+		return true
+	}
+	if accept == "" || accept == "*/*" {
+		ua := r.UserAgent()
+		return strings.HasPrefix(ua, "curl/") || strings.HasPrefix(ua, "Wget/") || strings.HasPrefix(ua, "HTTPie/")
+	}
+	return false
+}
+
+// docTextFormat returns the text format requested for the documentation of a
+// package: "txt" for plain text, "md" for Markdown, or the empty string for
+// the HTML page.
+func docTextFormat(r *http.Request) string {
+	switch r.FormValue("format") {
+	case "md", "markdown":
+		return "md"
+	}
+	if wantsPlainText(r) {
+		return "txt"
+	}
+	return ""
+}
+
+// serveDocText writes the documentation of the package with the given name and
+// path in format, as returned by docTextFormat.
+func serveDocText(w http.ResponseWriter, format, name, path, docHTML string, isRedistributable bool) {
+	const licenseMessage = "Documentation not displayed due to license restrictions."
+	if format == "md" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		fmt.Fprintf(w, "# package %s\n\n`import %q`\n\n", name, path)
+		if !isRedistributable {
+			fmt.Fprintln(w, licenseMessage)
+			return
+		}
+		io.WriteString(w, docHTMLToMarkdown(docHTML))
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "package %s // import %q\n\n", name, path)
+	if !isRedistributable {
+		fmt.Fprintln(w, licenseMessage)
+		return
+	}
+	io.WriteString(w, docHTMLToText(docHTML))
+}
+
+// docHTMLToText converts documentation HTML rendered by the dochtml package
+// to text formatted like the output of go doc: headings on their own lines,
+// paragraphs wrapped and code blocks indented.
+func docHTMLToText(docHTML string) string {
+	return renderDocHTML(docHTML, &textRenderer{})
+}
+
+// docHTMLToMarkdown converts documentation HTML rendered by the dochtml
+// package to Markdown: sections become headings, code blocks are fenced,
+// inline code becomes code spans and the index becomes a list. Other text is
+// escaped, so that it is not read as Markdown.
+func docHTMLToMarkdown(docHTML string) string {
+	return renderDocHTML(docHTML, &textRenderer{markdown: true})
+}
+
+// renderDocHTML renders docHTML with t. The HTML documentation is the form in
+// which it is stored, so both text formats are derived from the same
+// structure as the HTML page.
+func renderDocHTML(docHTML string, t *textRenderer) string {
+	z := html.NewTokenizer(strings.NewReader(docHTML))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			t.flush()
+			return t.out.String()
+		case html.TextToken:
+			if t.skip == 0 {
+				t.writeText(string(z.Text()))
+			}
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			t.start(atom.Lookup(name))
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			t.end(atom.Lookup(name))
+		}
+	}
+}
+
+// textRenderer accumulates the text of HTML elements and writes it out, block
+// by block, as plain text or Markdown.
+type textRenderer struct {
+	markdown bool
+
+	out  strings.Builder
+	text strings.Builder // text of the current block
+
+	skip      int  // depth of elements whose text is dropped
+	inPre     bool // whether the current block is preformatted
+	inCode    bool // whether the text is in a Markdown code span
+	listDepth int  // nesting depth of lists
+	lastItem  bool // whether the last block written was a list item
+}
+
+func (t *textRenderer) start(a atom.Atom) {
+	switch a {
+	case atom.Nav, atom.Script, atom.Style, atom.Button, atom.Textarea, atom.Input:
+		t.skip++
+	case atom.Pre:
+		t.flush()
+		t.inPre = true
+	case atom.Ul, atom.Ol:
+		t.flush()
+		t.listDepth++
+	case atom.P, atom.H1, atom.H2, atom.H3, atom.H4, atom.Li, atom.Div,
+		atom.Section, atom.Details, atom.Summary, atom.Dl, atom.Dt, atom.Dd:
+		t.flush()
+	case atom.Br:
+		t.text.WriteString("\n")
+	case atom.Code:
+		if t.markdown && !t.inPre && !t.inCode {
+			t.text.WriteString("`")
+			t.inCode = true
+		}
+	}
+}
+
+func (t *textRenderer) end(a atom.Atom) {
+	switch a {
+	case atom.Nav, atom.Script, atom.Style, atom.Button, atom.Textarea, atom.Input:
+		if t.skip > 0 {
+			t.skip--
+		}
+	case atom.Pre:
+		t.flushPre()
+		t.inPre = false
+	case atom.Ul, atom.Ol:
+		t.flush()
+		t.listDepth--
+	case atom.H1, atom.H2:
+		t.flushHeading(true)
+	case atom.H3, atom.H4:
+		t.flushHeading(false)
+	case atom.Li:
+		t.flushItem()
+	case atom.P, atom.Div, atom.Section, atom.Details, atom.Summary, atom.Dl, atom.Dt, atom.Dd:
+		t.flush()
+	case atom.Code:
+		if t.inCode {
+			t.text.WriteString("`")
+			t.inCode = false
+		}
+	}
+}
+
+// markdownEscaper escapes the characters that have a meaning in Markdown
+// text.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"#", `\#`,
+)
+
+// writeText adds s to the text of the current block. In Markdown, text
+// outside code blocks and code spans is escaped.
+func (t *textRenderer) writeText(s string) {
+	if t.markdown && !t.inPre && !t.inCode {
+		s = markdownEscaper.Replace(s)
+	}
+	t.text.WriteString(s)
+}
+
+// blockText returns the collected text of the current block with whitespace
+// collapsed and anchor markers removed, and resets it.
+func (t *textRenderer) blockText() string {
+	s := strings.ReplaceAll(t.text.String(), "¶", "")
+	t.text.Reset()
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// writeBlock writes s, separated from the previous block by a blank line
+// unless both are list items.
+func (t *textRenderer) writeBlock(s string, item bool) {
+	if t.out.Len() > 0 && !(item && t.lastItem) {
+		t.out.WriteString("\n")
+	}
+	t.out.WriteString(s)
+	t.lastItem = item
+}
+
+func (t *textRenderer) flush() {
+	if t.inPre {
+		return
+	}
+	s := t.blockText()
+	switch {
+	case s == "":
+	case t.markdown:
+		if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+			// Not a list item.
+			s = `\` + s
+		}
+		t.writeBlock(s+"\n", false)
+	default:
+		t.writeBlock(wrapText(s, "    ", plainTextWidth), false)
+	}
+}
+
+func (t *textRenderer) flushHeading(top bool) {
+	s := t.blockText()
+	if s == "" {
+		return
+	}
+	switch {
+	case t.markdown && top:
+		s = "## " + s
+	case t.markdown:
+		s = "### " + s
+	case top:
+		s = strings.ToUpper(s)
+	}
+	t.writeBlock(s+"\n", false)
+}
+
+func (t *textRenderer) flushItem() {
+	s := t.blockText()
+	if s == "" {
+		return
+	}
+	if t.markdown {
+		var indent string
+		if t.listDepth > 1 {
+			indent = strings.Repeat("  ", t.listDepth-1)
+		}
+		t.writeBlock(indent+"- "+s+"\n", true)
+		return
+	}
+	indent := strings.Repeat("  ", t.listDepth)
+	t.writeBlock(indent+s+"\n", true)
+}
+
+func (t *textRenderer) flushPre() {
+	s := strings.Trim(t.text.String(), "\n")
+	t.text.Reset()
+	if strings.TrimSpace(s) == "" {
+		return
+	}
+	if t.markdown {
+		t.writeBlock("```go\n"+s+"\n```\n", false)
+		return
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString("    " + line + "\n")
+	}
+	t.writeBlock(b.String(), false)
+}
+
+// wrapText wraps the words of s into lines no longer than width, where
+// possible, each starting with indent.
+func wrapText(s, indent string, width int) string {
+	var b strings.Builder
+	line := indent
+	for _, word := range strings.Fields(s) {
+		if line != indent && len(line)+1+len(word) > width {
+			b.WriteString(line + "\n")
+			line = indent
+		}
+		if line != indent {
+			line += " "
+		}
+		line += word
+	}
+	if line != indent {
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,190 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestWantsPlainText(t *testing.T) {
+	for _, test := range []struct {
+		url, accept, userAgent string
+		want                   bool
+	}{
+		{"/net/http", "", "", false},
+		{"/net/http?format=txt", "", "", true},
+		{"/net/http?format=html", "text/plain", "", false},
+		{"/net/http", "text/plain", "", true},
+		{"/net/http", "text/html,application/xhtml+xml,*/*;q=0.8", "Mozilla/5.0", false},
+		{"/net/http", "text/html, text/plain;q=0.5", "", false},
+		{"/net/http", "*/*", "curl/7.64.1", true},
+		{"/net/http", "*/*", "Mozilla/5.0", false},
+		{"/net/http", "application/json", "curl/7.64.1", false},
+	} {
+		r := httptest.NewRequest("GET", test.url, nil)
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+		r.Header.Set("User-Agent", test.userAgent)
+		if got := wantsPlainText(r); got != test.want {
+			t.Errorf("%s, Accept: %q, User-Agent: %q: got %t, want %t",
+				test.url, test.accept, test.userAgent, got, test.want)
+		}
+	}
+}
+
+func TestDocTextFormat(t *testing.T) {
+	for _, test := range []struct {
+		url, accept, want string
+	}{
+		{"/net/http", "", ""},
+		{"/net/http?format=md", "", "md"},
+		{"/net/http?format=markdown", "text/plain", "md"},
+		{"/net/http?format=txt", "", "txt"},
+		{"/net/http", "text/plain", "txt"},
+	} {
+		r := httptest.NewRequest("GET", test.url, nil)
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+		if got := docTextFormat(r); got != test.want {
+			t.Errorf("%s, Accept: %q: got %q, want %q", test.url, test.accept, got, test.want)
+		}
+	}
+}
+
+func TestDocTextVary(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	if err := testDB.InsertModule(ctx, sample.DefaultModule()); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		accept, wantType string
+	}{
+		{"text/html", "text/html"},
+		{"text/plain", "text/plain"},
+	} {
+		r := httptest.NewRequest("GET", "/"+sample.PackagePath, nil)
+		r.Header.Set("Accept", test.accept)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Accept %q: got code %d, want 200", test.accept, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, test.wantType) {
+			t.Errorf("Accept %q: got Content-Type %q, want %s", test.accept, ct, test.wantType)
+		}
+		// Both responses are negotiated, so caches must keep them apart.
+		if !contains(w.Header()["Vary"], "Accept") {
+			t.Errorf("Accept %q: got Vary %q, want it to contain Accept", test.accept, w.Header()["Vary"])
+		}
+	}
+}
+
+func TestDocHTMLToText(t *testing.T) {
+	want := `OVERVIEW
+
+    Package http provides HTTP client and server implementations. Get, Head,
+    Post, and PostForm make HTTP (or HTTPS) requests:
+
+    resp, err := http.Get("http://example.com/")
+
+    resp, err := http.Head("http://example.com/")
+
+INDEX
+
+  func Get(url string) (resp *Response, err error)
+  func Head(url string) (resp *Response, err error)
+
+func Get
+
+    func Get(url string) (resp *Response, err error)
+
+    Get issues a GET to the specified URL.
+`
+	got := docHTMLToText(testDocHTML)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDocHTMLToMarkdown(t *testing.T) {
+	want := `## Overview
+
+Package http provides HTTP client and server implementations. Get, Head, Post, and PostForm make HTTP (or HTTPS) requests:
+
+` + "```go" + `
+resp, err := http.Get("http://example.com/")
+
+resp, err := http.Head("http://example.com/")
+` + "```" + `
+
+## Index
+
+- func Get(url string) (resp \*Response, err error)
+- func Head(url string) (resp \*Response, err error)
+
+### func Get
+
+` + "```go" + `
+func Get(url string) (resp *Response, err error)
+` + "```" + `
+
+Get issues a GET to the specified URL.
+`
+	got := docHTMLToMarkdown(testDocHTML)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDocHTMLToMarkdownEscaping(t *testing.T) {
+	docHTML := `<p>Call <code>Do_It(*p)</code> with *care*, [not] a_link, #1 &lt;T&gt;.</p>
+<p>- is not a list item.</p>
+<pre>x := *p_q</pre>`
+	want := "Call `Do_It(*p)` with \\*care\\*, \\[not\\] a\\_link, \\#1 \\<T\\>.\n\n" +
+		"\\- is not a list item.\n\n" +
+		"```go\nx := *p_q\n```\n"
+	got := docHTMLToMarkdown(docHTML)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+const testDocHTML = `<nav class="Documentation-nav"><ul><li>Overview</li></ul></nav>
+<section class="Documentation-overview">
+	<h2 id="pkg-overview">Overview <a href="#pkg-overview">¶</a></h2>
+	<p>Package http provides HTTP client and server implementations.
+	Get, Head, Post, and PostForm make HTTP (or HTTPS) requests:</p>
+<pre>resp, err := http.Get("http://example.com/")
+
+resp, err := http.Head("http://example.com/")
+</pre>
+</section>
+<section class="Documentation-index">
+	<h2 id="pkg-index">Index <a href="#pkg-index">¶</a></h2>
+	<ul>
+		<li><a href="#Get">func Get(url string) (resp *Response, err error)</a></li>
+		<li><a href="#Head">func Head(url string) (resp *Response, err error)</a></li>
+	</ul>
+</section>
+<section class="Documentation-functions">
+	<div class="Documentation-function">
+		<h3 id="Get">func <a href="/src">Get</a> <a href="#Get">¶</a></h3>
+		<pre>func Get(url string) (resp *<a href="#Response">Response</a>, err error)</pre>
+		<p>Get issues a GET to the specified URL.</p>
+	</div>
+</section>`
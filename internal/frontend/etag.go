@@ -0,0 +1,79 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/experiment"
+	"golang.org/x/pkgsite/internal/prefs"
+)
+
+// serveNotModified sets the ETag header for the details page of the given
+// path, at modulePath@version, showing the module state ms. If the request has a matching If-None-Match
+// header, it writes a 304 (Not Modified) response and returns true; the caller
+// need not render the page.
+//
+// Tabs whose contents depend on other module versions, like importedby and
+// versions, don't get an ETag.
+func (s *Server) serveNotModified(ctx context.Context, w http.ResponseWriter, r *http.Request, path, modulePath, version, pageType string, ms ModuleState) bool {
+	if tab := r.FormValue("tab"); tab == "importedby" || tab == "versions" {
+		return false
+	}
+	latest := s.LatestVersion(ctx, path, modulePath, pageType)
+	etag := detailsETag(ctx, r, s.language(r).String(), modulePath, version, latest, ms)
+	w.Header().Set("ETag", etag)
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	// The Content-Security-Policy header holds a nonce that is different for
+	// each response. Omit it, so that the client keeps the one that matches
+	// the nonce in its cached page.
+	w.Header().Del("Content-Security-Policy")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// detailsETag returns a strong ETag for the details page requested by r, for
+// modulePath@version, in the language lang. Besides the request URL and the
+// module version, it depends on the deployed app version, which determines
+// the templates, the latest version of the module, which is shown on the
+// page, the active experiments, the preferences of the user, the color
+// scheme hint of r, which chooses the theme of the page, and the module
+// state ms, which administrators and background jobs change without a new
+// version.
+func detailsETag(ctx context.Context, r *http.Request, lang, modulePath, version, latestVersion string, ms ModuleState) string {
+	// The state is hashed as JSON, which follows its pointers. It always
+	// marshals, since details pages are also served as JSON.
+	state, _ := json.Marshal(ms)
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%s@%s\n%s\n%s\n%+v\n%s\n%s",
+		config.AppVersionLabel(), r.URL.RequestURI(), lang, modulePath, version, latestVersion,
+		strings.Join(experiment.FromContext(ctx).Active(), ","), prefs.FromContext(ctx),
+		colorSchemeHint(r), state)
+	return `"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`
+}
+
+// etagMatches reports whether the value of an If-None-Match header matches
+// etag, using the weak comparison of RFC 7232, section 3.2.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, t := range strings.Split(ifNoneMatch, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
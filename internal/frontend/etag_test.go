@@ -0,0 +1,57 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestETagMatches(t *testing.T) {
+	const etag = `"abc"`
+	for _, test := range []struct {
+		ifNoneMatch string
+		want        bool
+	}{
+		{"", false},
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz"`, false},
+		{`"xyz", "abc"`, true},
+		{"*", true},
+	} {
+		if got := etagMatches(test.ifNoneMatch, etag); got != test.want {
+			t.Errorf("etagMatches(%q, %q) = %t, want %t", test.ifNoneMatch, etag, got, test.want)
+		}
+	}
+}
+
+func TestDetailsETag(t *testing.T) {
+	ctx := context.Background()
+	etag := func(url, version, latest string) string {
+		return detailsETag(ctx, httptest.NewRequest("GET", url, nil), "en", "example.com/mod", version, latest, ModuleState{})
+	}
+	dark := httptest.NewRequest("GET", "/example.com/mod?tab=doc", nil)
+	dark.Header.Set(colorSchemeHeader, `"dark"`)
+	base := etag("/example.com/mod?tab=doc", "v1.0.0", "v1.1.0")
+	if base != etag("/example.com/mod?tab=doc", "v1.0.0", "v1.1.0") {
+		t.Fatal("ETag is not deterministic")
+	}
+	for _, other := range []string{
+		etag("/example.com/mod?tab=overview", "v1.0.0", "v1.1.0", ModuleState{}),
+		etag("/example.com/mod?tab=doc", "v1.0.1", "v1.1.0"),
+		etag("/example.com/mod?tab=doc", "v1.0.0", "v1.2.0"),
+		detailsETag(ctx, dark, "en", "example.com/mod", "v1.0.0", "v1.1.0", ModuleState{}),
+		detailsETag(ctx, httptest.NewRequest("GET", "/example.com/mod?tab=doc", nil), "fr", "example.com/mod", "v1.0.0", "v1.1.0", ModuleState{}),
+		detailsETag(ctx, httptest.NewRequest("GET", "/example.com/mod?tab=doc", nil), "en", "example.com/mod", "v1.0.0", "v1.1.0", ModuleState{Verified: true}),
+		detailsETag(ctx, httptest.NewRequest("GET", "/example.com/mod?tab=doc", nil), "en", "example.com/mod", "v1.0.0", "v1.1.0", ModuleState{SupersededBy: "example.com/new"}),
+		detailsETag(ctx, httptest.NewRequest("GET", "/example.com/mod?tab=doc", nil), "en", "example.com/mod", "v1.0.0", "v1.1.0", ModuleState{Archived: &ArchivedRepository{}}),
+	} {
+		if other == base {
+			t.Errorf("got same ETag %s for different pages", base)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/signals"
+)
+
+// externalSignals returns the signals of the enabled providers for the
+// module at modulePath, to be shown as chips in the header of details pages.
+// Chips are optional, so errors are only logged.
+func (s *Server) externalSignals(ctx context.Context, modulePath string) []*signals.Signal {
+	if len(s.signalProviders) == 0 {
+		return nil
+	}
+	db, ok := s.ds.(*postgres.DB)
+	if !ok {
+		// Only the database stores signals.
+		return nil
+	}
+	sigs, err := db.GetExternalSignals(ctx, modulePath, s.signalProviders)
+	if err != nil {
+		log.Error(ctx, err)
+		return nil
+	}
+	return sigs
+}
@@ -0,0 +1,198 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/middleware"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+const (
+	// defaultFeedEntries is the number of entries in the Atom feed, and the
+	// default number of entries in a response of the JSON feed.
+	defaultFeedEntries = 100
+	// maxFeedEntries is the maximum number of entries in a response of the
+	// JSON feed.
+	maxFeedEntries = 1000
+)
+
+// atomFeed is an Atom feed, as described in RFC 4287.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Link    []atomLink  `xml:"link"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Updated    string         `xml:"updated"`
+	Summary    string         `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+// atomCategory is a license type of an entry.
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// feedJSONEntry is a line of the JSON feed.
+type feedJSONEntry struct {
+	*postgres.FeedEntry
+	URL string `json:"url"`
+}
+
+// handleFeed serves the feed of every processed module version, at
+// /feed/all.atom as an Atom feed of the latest versions, and at
+// /feed/all.json as a stream of JSON objects, one per line.
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	if err := s.serveFeed(w, r); err != nil {
+		s.serveError(w, r, err)
+	}
+}
+
+func (s *Server) serveFeed(w http.ResponseWriter, r *http.Request) (err error) {
+	defer derrors.Wrap(&err, "serveFeed(%q)", r.URL.Path)
+
+	db, ok := s.ds.(*postgres.DB)
+	if !ok {
+		// The feed needs the times that versions were processed.
+		return &serverError{status: http.StatusNotImplemented}
+	}
+	switch r.URL.Path {
+	case "/feed/all.atom":
+		entries, err := db.GetFeedEntries(r.Context(), postgres.FeedCursor{}, defaultFeedEntries)
+		if err != nil {
+			return err
+		}
+		return s.serveAtomFeed(w, r, entries)
+	case "/feed/all.json":
+		// The entry after which to continue is given by its fields.
+		after := postgres.FeedCursor{
+			ModulePath: r.FormValue("module_path"),
+			Version:    r.FormValue("version"),
+		}
+		if v := r.FormValue("since"); v != "" {
+			after.ProcessedAt, err = time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return &serverError{status: http.StatusBadRequest, err: err}
+			}
+		}
+		limit := defaultFeedEntries
+		if v := r.FormValue("limit"); v != "" {
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit <= 0 {
+				return &serverError{status: http.StatusBadRequest, err: fmt.Errorf("bad limit %q", v)}
+			}
+			if limit > maxFeedEntries {
+				limit = maxFeedEntries
+			}
+		}
+		entries, err := db.GetFeedEntries(r.Context(), after, limit)
+		if err != nil {
+			return err
+		}
+		return serveJSONFeed(w, r, entries)
+	default:
+		return &serverError{status: http.StatusNotFound}
+	}
+}
+
+func (s *Server) serveAtomFeed(w http.ResponseWriter, r *http.Request, entries []*postgres.FeedEntry) error {
+	base := siteURL(r)
+	feed := &atomFeed{
+		Title: s.branding.SiteName + ": new module versions",
+		ID:    base + "/feed/all.atom",
+		Link: []atomLink{
+			{Rel: "self", Href: base + "/feed/all.atom"},
+			{Href: base + "/"},
+		},
+		Author: atomAuthor{Name: s.branding.SiteName},
+	}
+	updated := time.Now()
+	if len(entries) > 0 {
+		updated = entries[0].ProcessedAt
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+	for _, e := range entries {
+		u := base + constructModuleURL(e.ModulePath, linkVersion(e.Version, e.ModulePath))
+		ae := atomEntry{
+			Title:   e.ModulePath + " " + displayVersion(e.Version, e.ModulePath),
+			ID:      u,
+			Link:    atomLink{Href: u},
+			Updated: e.ProcessedAt.UTC().Format(time.RFC3339),
+			Summary: e.Synopsis,
+		}
+		for _, l := range e.Licenses {
+			ae.Categories = append(ae.Categories, atomCategory{Term: l})
+		}
+		feed.Entries = append(feed.Entries, ae)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		middleware.RecordRequestError(r.Context(), fmt.Errorf("serveAtomFeed: error writing response: %v", err))
+	}
+	return nil
+}
+
+func serveJSONFeed(w http.ResponseWriter, r *http.Request, entries []*postgres.FeedEntry) error {
+	base := siteURL(r)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		u := base + constructModuleURL(e.ModulePath, linkVersion(e.Version, e.ModulePath))
+		if err := enc.Encode(feedJSONEntry{FeedEntry: e, URL: u}); err != nil {
+			return err
+		}
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		middleware.RecordRequestError(r.Context(), fmt.Errorf("serveJSONFeed: error writing response: %v", err))
+	}
+	return nil
+}
+
+// siteURL returns the scheme and host that r was sent to, like
+// https://pkg.go.dev. The site is served over HTTPS, except locally.
+func siteURL(r *http.Request) string {
+	scheme := "https"
+	if p := r.Header.Get("X-Forwarded-Proto"); p != "" {
+		scheme = p
+	} else if r.TLS == nil && (strings.HasPrefix(r.Host, "localhost") || strings.HasPrefix(r.Host, "127.0.0.1")) {
+		scheme = "http"
+	}
+	return scheme + "://" + r.Host
+}
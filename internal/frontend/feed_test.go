@@ -0,0 +1,94 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestFeed(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	for _, v := range []string{"v1.0.0", "v1.1.0"} {
+		if err := testDB.InsertModule(ctx, sample.Module("example.com/a", v, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		return w
+	}
+
+	w := get("/feed/all.atom")
+	if w.Code != http.StatusOK {
+		t.Fatalf("atom: got code %d, want 200", w.Code)
+	}
+	var feed atomFeed
+	if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, e := range feed.Entries {
+		titles = append(titles, e.Title)
+	}
+	if got, want := strings.Join(titles, ", "), "example.com/a v1.1.0, example.com/a v1.0.0"; got != want {
+		t.Errorf("atom titles = %q, want %q", got, want)
+	}
+	if got, want := feed.Entries[0].ID, "https://example.com/mod/example.com/a@v1.1.0"; got != want {
+		t.Errorf("atom entry ID = %q, want %q", got, want)
+	}
+
+	w = get("/feed/all.json?limit=1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("json: got code %d, want 200", w.Code)
+	}
+	var first feedJSONEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Version != "v1.1.0" || first.Synopsis != sample.Synopsis {
+		t.Errorf("json: got %+v, want the latest version with its synopsis", first)
+	}
+	// Following from before the newest version returns it again, and
+	// nothing after it.
+	since := first.ProcessedAt.Add(-time.Microsecond).UTC().Format(time.RFC3339Nano)
+	w = get("/feed/all.json?since=" + url.QueryEscape(since))
+	if lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n"); len(lines) != 1 {
+		t.Errorf("json since: got %d lines, want 1:\n%s", len(lines), w.Body)
+	}
+
+	// Following from the newest version returns nothing.
+	w = get("/feed/all.json?" + url.Values{
+		"since":       {first.ProcessedAt.UTC().Format(time.RFC3339Nano)},
+		"module_path": {first.ModulePath},
+		"version":     {first.Version},
+	}.Encode())
+	if body := strings.TrimSpace(w.Body.String()); body != "" {
+		t.Errorf("json after newest: got %q, want no entries", body)
+	}
+
+	for _, target := range []string{"/feed/all.json?limit=x", "/feed/all.json?since=yesterday"} {
+		if w := get(target); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got code %d, want 400", target, w.Code)
+		}
+	}
+	if w := get("/feed/other.atom"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown feed: got code %d, want 404", w.Code)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"go.opencensus.io/tag"
 	"golang.org/x/mod/semver"
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/experiment"
 	"golang.org/x/pkgsite/internal/fetch"
@@ -229,8 +230,14 @@ func (s *Server) fetchModule(ctx context.Context, fullPath, modulePath, requeste
 		return fr
 	}
 	// After the fetch request is enqueued, poll the database until it has been
-	// inserted or the request times out.
-	return pollForPath(ctx, db, pollEvery, fullPath, modulePath, requestedVersion)
+	// inserted or the request times out. Poll the primary, since a read replica
+	// may not have the worker's writes yet.
+	fr = pollForPath(database.WithPrimary(ctx), db, pollEvery, fullPath, modulePath, requestedVersion)
+	if fr.status != http.StatusRequestTimeout {
+		// The page that the user is sent to next must also see the new rows.
+		db.Underlying().NoteWrite()
+	}
+	return fr
 }
 
 // pollForPath polls the database until a row for fullPath is found.
@@ -0,0 +1,65 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// FilesDetails contains the data needed to render the files tab of a module
+// page, which lists the files in the module zip.
+type FilesDetails struct {
+	Files     []*FileListing
+	TotalSize string
+}
+
+// FileListing is a file in the module zip, as shown on the files tab.
+type FileListing struct {
+	Path       string
+	Size       string
+	Executable bool
+}
+
+// fetchFilesDetails returns the files of the given module version. Only names
+// and sizes are shown, so the tab is available even for modules that are not
+// redistributable. Data sources other than the database do not record files,
+// and for them the listing is empty.
+func fetchFilesDetails(ctx context.Context, ds internal.DataSource, modulePath, version string) (*FilesDetails, error) {
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		return &FilesDetails{}, nil
+	}
+	files, err := db.GetModuleFiles(ctx, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+	fd := &FilesDetails{}
+	var total int64
+	for _, f := range files {
+		fd.Files = append(fd.Files, &FileListing{Path: f.Path, Size: formatSize(f.Size), Executable: f.Executable})
+		total += f.Size
+	}
+	fd.TotalSize = formatSize(total)
+	return fd, nil
+}
+
+// formatSize formats a number of bytes for display, like "512 B" or
+// "1.5 MB".
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 3; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
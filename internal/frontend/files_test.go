@@ -0,0 +1,27 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import "testing"
+
+func TestFormatSize(t *testing.T) {
+	for _, test := range []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{5 << 20, "5.0 MB"},
+		{3 << 30, "3.0 GB"},
+		{2 << 40, "2.0 TB"},
+		{4 << 50, "4096.0 TB"},
+	} {
+		if got := formatSize(test.n); got != test.want {
+			t.Errorf("formatSize(%d) = %q, want %q", test.n, got, test.want)
+		}
+	}
+}
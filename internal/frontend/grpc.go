@@ -0,0 +1,242 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/discoverypb"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewGRPCServer returns a gRPC server for the Discovery service of
+// discoverypb, which serves the same data as the JSON API. interceptors, like
+// middleware.GRPCQuota, run before each call. Calls are anonymous, since the
+// server does not go through the HTTP middleware that signs users in, so it
+// must not be served on deployments with sign-in or an ACL.
+func (s *Server) NewGRPCServer(interceptors ...grpc.UnaryServerInterceptor) *grpc.Server {
+	gs := grpc.NewServer(grpc.ChainUnaryInterceptor(append(interceptors, grpcErrors)...))
+	discoverypb.RegisterDiscoveryServer(gs, &discoveryServer{s: s})
+	return gs
+}
+
+// grpcErrors converts the errors of gRPC methods to statuses, as apiHandler
+// does for the JSON API.
+func grpcErrors(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	switch {
+	case errors.Is(err, derrors.NotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, derrors.InvalidArgument):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, derrors.DBUnavailable):
+		return nil, status.Error(codes.Unavailable, codes.Unavailable.String())
+	default:
+		log.Errorf(ctx, "%s: %v", info.FullMethod, err)
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+}
+
+// discoveryServer implements discoverypb.DiscoveryServer.
+type discoveryServer struct {
+	s *Server
+}
+
+// GetPackage implements discoverypb.DiscoveryServer.
+func (d *discoveryServer) GetPackage(ctx context.Context, req *discoverypb.GetPackageRequest) (_ *discoverypb.Package, err error) {
+	defer derrors.Wrap(&err, "GetPackage(%q, %q, %q)", req.Path, req.ModulePath, req.Version)
+
+	modulePath, version, isPackage, err := d.s.ds.GetPathInfo(ctx, req.Path, grpcModulePath(req.ModulePath), grpcVersion(req.Version))
+	if err != nil {
+		return nil, err
+	}
+	if !isPackage {
+		return nil, fmt.Errorf("%q is not a package: %w", req.Path, derrors.NotFound)
+	}
+	pkg, err := d.s.ds.GetPackage(ctx, req.Path, modulePath, version, internal.MinimalFields)
+	if err != nil {
+		return nil, err
+	}
+	imports, err := d.s.ds.GetImports(ctx, req.Path, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+	var types []string
+	for _, m := range pkg.Licenses {
+		types = append(types, m.Types...)
+	}
+	return &discoverypb.Package{
+		Path:            pkg.Path,
+		Name:            pkg.Name,
+		Synopsis:        pkg.Synopsis,
+		ModulePath:      pkg.ModulePath,
+		Version:         pkg.Version,
+		CommitTime:      timestampProto(pkg.CommitTime),
+		Licenses:        uniqueStrings(types),
+		Redistributable: pkg.LegacyPackage.IsRedistributable,
+		Imports:         imports,
+	}, nil
+}
+
+// GetModule implements discoverypb.DiscoveryServer.
+func (d *discoveryServer) GetModule(ctx context.Context, req *discoverypb.GetModuleRequest) (_ *discoverypb.Module, err error) {
+	defer derrors.Wrap(&err, "GetModule(%q, %q)", req.Path, req.Version)
+
+	if req.Path == "" {
+		return nil, fmt.Errorf("missing path: %w", derrors.InvalidArgument)
+	}
+	mi, err := d.s.ds.GetModuleInfo(ctx, req.Path, grpcVersion(req.Version))
+	if err != nil {
+		return nil, err
+	}
+	lics, err := d.s.ds.GetModuleLicenses(ctx, mi.ModulePath, mi.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &discoverypb.Module{
+		Path:            mi.ModulePath,
+		Version:         mi.Version,
+		CommitTime:      timestampProto(mi.CommitTime),
+		VersionType:     string(mi.VersionType),
+		Redistributable: mi.IsRedistributable,
+		HasGoMod:        mi.HasGoMod,
+		RepositoryUrl:   mi.SourceInfo.RepoURL(),
+		Licenses:        licenseTypes(lics),
+	}, nil
+}
+
+// Search implements discoverypb.DiscoveryServer.
+func (d *discoveryServer) Search(ctx context.Context, req *discoverypb.SearchRequest) (_ *discoverypb.SearchResponse, err error) {
+	defer derrors.Wrap(&err, "Search(%q, %d, %d)", req.Query, req.Limit, req.Offset)
+
+	if d.s.search == nil {
+		return nil, fmt.Errorf("search is not supported: %w", derrors.NotFound)
+	}
+	if req.Query == "" {
+		return nil, fmt.Errorf("missing query: %w", derrors.InvalidArgument)
+	}
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	limit, err = internal.CheckSearchPage(limit, int(req.Offset))
+	if err != nil {
+		return nil, err
+	}
+	results, err := d.s.search.Search(ctx, req.Query, limit, int(req.Offset))
+	if err != nil {
+		return nil, err
+	}
+	resp := &discoverypb.SearchResponse{}
+	for _, r := range results {
+		resp.Results = append(resp.Results, &discoverypb.SearchResult{
+			PackagePath:   r.PackagePath,
+			ModulePath:    r.ModulePath,
+			Version:       r.Version,
+			Name:          r.Name,
+			Synopsis:      r.Synopsis,
+			Licenses:      r.Licenses,
+			NumImportedBy: r.NumImportedBy,
+			Score:         r.Score,
+		})
+	}
+	if len(results) > 0 {
+		resp.Total = results[0].NumResults
+	}
+	return resp, nil
+}
+
+// ListVersions implements discoverypb.DiscoveryServer.
+func (d *discoveryServer) ListVersions(ctx context.Context, req *discoverypb.ListVersionsRequest) (_ *discoverypb.ListVersionsResponse, err error) {
+	defer derrors.Wrap(&err, "ListVersions(%q)", req.ModulePath)
+
+	if req.ModulePath == "" {
+		return nil, fmt.Errorf("missing module path: %w", derrors.InvalidArgument)
+	}
+	tagged, err := d.s.ds.GetTaggedVersionsForModule(ctx, req.ModulePath)
+	if err != nil {
+		return nil, err
+	}
+	pseudo, err := d.s.ds.GetPseudoVersionsForModule(ctx, req.ModulePath)
+	if err != nil {
+		return nil, err
+	}
+	resp := &discoverypb.ListVersionsResponse{}
+	for _, mis := range [][]*internal.LegacyModuleInfo{tagged, pseudo} {
+		for _, mi := range mis {
+			// The versions of other major versions in the series are
+			// returned too.
+			if mi.ModulePath != req.ModulePath {
+				continue
+			}
+			resp.Versions = append(resp.Versions, &discoverypb.Version{
+				Version:     mi.Version,
+				CommitTime:  timestampProto(mi.CommitTime),
+				VersionType: string(mi.VersionType),
+			})
+		}
+	}
+	if len(resp.Versions) == 0 {
+		return nil, fmt.Errorf("module %q: %w", req.ModulePath, derrors.NotFound)
+	}
+	return resp, nil
+}
+
+// grpcModulePath returns the module path to look up for the module path of a
+// request, which may be empty.
+func grpcModulePath(modulePath string) string {
+	if modulePath == "" {
+		return internal.UnknownModulePath
+	}
+	return modulePath
+}
+
+// grpcVersion returns the version to look up for the version of a request,
+// which may be empty.
+func grpcVersion(version string) string {
+	if version == "" {
+		return internal.LatestVersion
+	}
+	return version
+}
+
+func timestampProto(t time.Time) *timestamp.Timestamp {
+	return &timestamp.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
+
+// licenseTypes returns the types of lics, without duplicates.
+func licenseTypes(lics []*licenses.License) []string {
+	var types []string
+	for _, l := range lics {
+		types = append(types, l.Types...)
+	}
+	return uniqueStrings(types)
+}
+
+// uniqueStrings returns the strings of a in order, without duplicates.
+func uniqueStrings(a []string) []string {
+	var u []string
+	seen := map[string]bool{}
+	for _, s := range a {
+		if !seen[s] {
+			seen[s] = true
+			u = append(u, s)
+		}
+	}
+	return u
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/discoverypb"
+	"golang.org/x/pkgsite/internal/testing/sample"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPC(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	s, _, teardown := newTestServer(t, nil)
+	defer teardown()
+	for _, v := range []string{"v1.0.0", "v1.1.0"} {
+		if err := testDB.InsertModule(ctx, sample.Module("example.com/m", v, "p")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := s.NewGRPCServer()
+	go gs.Serve(lis)
+	defer gs.Stop()
+	conn, err := grpc.DialContext(ctx, lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := discoverypb.NewDiscoveryClient(conn)
+
+	pkg, err := client.GetPackage(ctx, &discoverypb.GetPackageRequest{Path: "example.com/m/p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Name != sample.PackageName || pkg.ModulePath != "example.com/m" || pkg.Version != "v1.1.0" {
+		t.Errorf("GetPackage = %s %s@%s, want %s example.com/m@v1.1.0", pkg.Name, pkg.ModulePath, pkg.Version, sample.PackageName)
+	}
+	if diff := cmp.Diff([]string{"MIT"}, pkg.Licenses); diff != "" {
+		t.Errorf("GetPackage licenses mismatch (-want +got):\n%s", diff)
+	}
+	if len(pkg.Imports) != len(sample.Imports) {
+		t.Errorf("GetPackage imports = %v, want %d imports", pkg.Imports, len(sample.Imports))
+	}
+
+	mod, err := client.GetModule(ctx, &discoverypb.GetModuleRequest{Path: "example.com/m", Version: "v1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mod.Version != "v1.0.0" || mod.VersionType != "release" {
+		t.Errorf("GetModule = %s (%s), want v1.0.0 (release)", mod.Version, mod.VersionType)
+	}
+
+	vs, err := client.ListVersions(ctx, &discoverypb.ListVersionsRequest{ModulePath: "example.com/m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range vs.Versions {
+		got = append(got, v.Version)
+	}
+	if diff := cmp.Diff([]string{"v1.1.0", "v1.0.0"}, got); diff != "" {
+		t.Errorf("ListVersions mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, test := range []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"unknown package", func() error {
+			_, err := client.GetPackage(ctx, &discoverypb.GetPackageRequest{Path: "example.com/unknown"})
+			return err
+		}, codes.NotFound},
+		{"module is not a package", func() error {
+			_, err := client.GetPackage(ctx, &discoverypb.GetPackageRequest{Path: "example.com/m"})
+			return err
+		}, codes.NotFound},
+		{"no module path", func() error {
+			_, err := client.ListVersions(ctx, &discoverypb.ListVersionsRequest{})
+			return err
+		}, codes.InvalidArgument},
+		{"search too deep", func() error {
+			_, err := client.Search(ctx, &discoverypb.SearchRequest{Query: "foo", Offset: 1000})
+			return err
+		}, codes.InvalidArgument},
+	} {
+		if got := status.Code(test.call()); got != test.want {
+			t.Errorf("%s: got code %s, want %s", test.name, got, test.want)
+		}
+	}
+}
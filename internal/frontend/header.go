@@ -5,6 +5,7 @@
 package frontend
 
 import (
+	"context"
 	"fmt"
 	"html/template"
 	"path"
@@ -14,6 +15,7 @@ import (
 	"golang.org/x/mod/module"
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/i18n"
 	"golang.org/x/pkgsite/internal/licenses"
 	"golang.org/x/pkgsite/internal/middleware"
 	"golang.org/x/pkgsite/internal/stdlib"
@@ -36,8 +38,11 @@ type Module struct {
 	DisplayVersion    string
 	LinkVersion       string
 	ModulePath        string
-	CommitTime        string
+	CommitTime        string // relative to now, like "2 days ago"
+	CommitTimestamp   string // CommitTime in RFC 3339
 	IsRedistributable bool
+	HasExecutables    bool   // whether the module zip has executable files
+	HasLargeFiles     bool   // whether the module zip has unusually large files
 	URL               string // relative to this site
 	LatestURL         string // link with latest-version placeholder, relative to this site
 	Licenses          []LicenseMetadata
@@ -49,7 +54,7 @@ type Module struct {
 // latestRequested indicates whether the user requested the latest
 // version of the package. If so, the returned Package.URL will have the
 // structure /<path> instead of /<path>@<version>.
-func createPackage(pkg *internal.LegacyPackage, mi *internal.ModuleInfo, latestRequested bool) (_ *Package, err error) {
+func createPackage(ctx context.Context, pkg *internal.LegacyPackage, mi *internal.ModuleInfo, latestRequested bool) (_ *Package, err error) {
 	defer derrors.Wrap(&err, "createPackage(%v, %v)", pkg, mi)
 
 	if pkg == nil || mi == nil {
@@ -63,7 +68,7 @@ func createPackage(pkg *internal.LegacyPackage, mi *internal.ModuleInfo, latestR
 		}
 	}
 
-	m := createModule(mi, modLicenses, latestRequested)
+	m := createModule(ctx, mi, modLicenses, latestRequested)
 	urlVersion := m.LinkVersion
 	if latestRequested {
 		urlVersion = internal.LatestVersion
@@ -85,7 +90,7 @@ func createPackage(pkg *internal.LegacyPackage, mi *internal.ModuleInfo, latestR
 // latestRequested indicates whether the user requested the latest
 // version of the package. If so, the returned Package.URL will have the
 // structure /<path> instead of /<path>@<version>.
-func createPackageNew(vdir *internal.VersionedDirectory, latestRequested bool) (_ *Package, err error) {
+func createPackageNew(ctx context.Context, vdir *internal.VersionedDirectory, latestRequested bool) (_ *Package, err error) {
 	defer derrors.Wrap(&err, "createPackageNew(%v, %t)", vdir, latestRequested)
 
 	if vdir == nil || vdir.Package == nil {
@@ -99,7 +104,7 @@ func createPackageNew(vdir *internal.VersionedDirectory, latestRequested bool) (
 		}
 	}
 
-	m := createModule(&vdir.ModuleInfo, modLicenses, latestRequested)
+	m := createModule(ctx, &vdir.ModuleInfo, modLicenses, latestRequested)
 	urlVersion := m.LinkVersion
 	if latestRequested {
 		urlVersion = internal.LatestVersion
@@ -121,7 +126,7 @@ func createPackageNew(vdir *internal.VersionedDirectory, latestRequested bool) (
 // latestRequested indicates whether the user requested the latest
 // version of the package. If so, the returned Module.URL will have the
 // structure /<path> instead of /<path>@<version>.
-func createModule(mi *internal.ModuleInfo, licmetas []*licenses.Metadata, latestRequested bool) *Module {
+func createModule(ctx context.Context, mi *internal.ModuleInfo, licmetas []*licenses.Metadata, latestRequested bool) *Module {
 	urlVersion := linkVersion(mi.Version, mi.ModulePath)
 	if latestRequested {
 		urlVersion = internal.LatestVersion
@@ -130,8 +135,11 @@ func createModule(mi *internal.ModuleInfo, licmetas []*licenses.Metadata, latest
 		DisplayVersion:    displayVersion(mi.Version, mi.ModulePath),
 		LinkVersion:       linkVersion(mi.Version, mi.ModulePath),
 		ModulePath:        mi.ModulePath,
-		CommitTime:        elapsedTime(mi.CommitTime),
+		CommitTime:        elapsedTime(ctx, mi.CommitTime),
+		CommitTimestamp:   timestamp(mi.CommitTime),
 		IsRedistributable: mi.IsRedistributable,
+		HasExecutables:    mi.HasExecutables,
+		HasLargeFiles:     mi.HasLargeFiles,
 		Licenses:          transformLicenseMetadata(licmetas),
 		URL:               constructModuleURL(mi.ModulePath, urlVersion),
 		LatestURL:         constructModuleURL(mi.ModulePath, middleware.LatestVersionPlaceholder),
@@ -324,27 +332,38 @@ func moduleTitle(modulePath string) string {
 }
 
 // elapsedTime takes a date and returns returns human-readable,
-// relative timestamps based on the following rules:
+// relative timestamps in the language of ctx (see i18n.FromContext),
+// based on the following rules:
 // (1) 'X hours ago' when X < 6
 // (2) 'today' between 6 hours and 1 day ago
 // (3) 'Y days ago' when Y < 6
 // (4) A date formatted like "Jan 2, 2006" for anything further back
-func elapsedTime(date time.Time) string {
+func elapsedTime(ctx context.Context, date time.Time) string {
+	p := i18n.FromContext(ctx)
 	elapsedHours := int(time.Since(date).Hours())
 	if elapsedHours == 1 {
-		return "1 hour ago"
+		return p.Sprintf("1 hour ago")
 	} else if elapsedHours < 6 {
-		return fmt.Sprintf("%d hours ago", elapsedHours)
+		return p.Sprintf("%d hours ago", elapsedHours)
 	}
 
 	elapsedDays := elapsedHours / 24
 	if elapsedDays < 1 {
-		return "today"
+		return p.Sprintf("today")
 	} else if elapsedDays == 1 {
-		return "1 day ago"
+		return p.Sprintf("1 day ago")
 	} else if elapsedDays < 6 {
-		return fmt.Sprintf("%d days ago", elapsedDays)
+		return p.Sprintf("%d days ago", elapsedDays)
 	}
 
-	return date.Format("Jan _2, 2006")
+	// Translations can reorder the month, day and year with the indexes of
+	// the arguments, like "%[2]s %[1]s %[3]s". The day and year are strings,
+	// so that they are not formatted as numbers, like "2,020".
+	return p.Sprintf("%[1]s %[2]s, %[3]s", p.Sprintf(date.Format("Jan")), date.Format("_2"), date.Format("2006"))
+}
+
+// timestamp returns t in RFC 3339, for clients to show the exact time in the
+// time zone of the user.
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
 }
@@ -5,6 +5,7 @@
 package frontend
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -13,6 +14,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/net/html"
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/i18n"
 	"golang.org/x/pkgsite/internal/middleware"
 	"golang.org/x/pkgsite/internal/testing/htmlcheck"
 	"golang.org/x/pkgsite/internal/testing/sample"
@@ -28,6 +30,7 @@ func samplePackage(mutators ...func(*Package)) *Package {
 			DisplayVersion:    sample.VersionString,
 			LinkVersion:       sample.VersionString,
 			CommitTime:        "0 hours ago",
+			CommitTimestamp:   timestamp(sample.CommitTime),
 			ModulePath:        sample.ModulePath,
 			IsRedistributable: true,
 			Licenses:          transformLicenseMetadata(sample.LicenseMetadata),
@@ -84,7 +87,7 @@ func TestElapsedTime(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			elapsedTime := elapsedTime(tc.date)
+			elapsedTime := elapsedTime(context.Background(), tc.date)
 
 			if elapsedTime != tc.elapsedTime {
 				t.Errorf("elapsedTime(%q) = %s, want %s", tc.date, elapsedTime, tc.elapsedTime)
@@ -93,6 +96,28 @@ func TestElapsedTime(t *testing.T) {
 	}
 }
 
+func TestElapsedTimeTranslated(t *testing.T) {
+	catalog, err := i18n.NewCatalog(map[string]map[string]string{
+		"fr": {
+			"%d days ago":        "il y a %d jours",
+			"%[1]s %[2]s, %[3]s": "%[2]s %[1]s %[3]s",
+			"Jan":                "janv.",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := i18n.NewContext(context.Background(), catalog.Printer(catalog.Negotiate("fr")))
+	now := sample.NowTruncated()
+	if got, want := elapsedTime(ctx, now.Add(-3*24*time.Hour)), "il y a 3 jours"; got != want {
+		t.Errorf("elapsedTime(3 days ago) = %q, want %q", got, want)
+	}
+	date := time.Date(2019, 1, 15, 0, 0, 0, 0, time.UTC)
+	if got, want := elapsedTime(ctx, date), "15 janv. 2019"; got != want {
+		t.Errorf("elapsedTime(%s) = %q, want %q", date, got, want)
+	}
+}
+
 func TestCreatePackageHeader(t *testing.T) {
 	vpkg := func(modulePath, suffix, name string) *internal.LegacyVersionedPackage {
 		vp := &internal.LegacyVersionedPackage{
@@ -138,7 +163,7 @@ func TestCreatePackageHeader(t *testing.T) {
 		},
 	} {
 		t.Run(tc.label, func(t *testing.T) {
-			got, err := createPackage(&tc.pkg.LegacyPackage, &tc.pkg.ModuleInfo, false)
+			got, err := createPackage(context.Background(), &tc.pkg.LegacyPackage, &tc.pkg.ModuleInfo, false)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -0,0 +1,199 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// Hover is the documentation of a single symbol, as served by the hover API
+// endpoint for editor integrations.
+type Hover struct {
+	Path       string `json:"path"`
+	ModulePath string `json:"modulePath"`
+	Version    string `json:"version"`
+	Symbol     string `json:"symbol"`
+	Kind       string `json:"kind"`
+	Signature  string `json:"signature"`
+	Doc        string `json:"doc,omitempty"`
+	// Since is the earliest tagged version of the module in which the symbol
+	// is documented, or empty if it is not known.
+	Since string `json:"since,omitempty"`
+	// URL is the path of the symbol's documentation on the site.
+	URL string `json:"url"`
+}
+
+const (
+	// hoverVersionedMaxAge is the max-age of hover responses for a specific
+	// version. The documentation of a module version does not change, but
+	// the since version may, if older versions are fetched later.
+	hoverVersionedMaxAge = 24 * 60 * 60
+
+	// hoverLatestMaxAge is the max-age of hover responses for the latest
+	// version.
+	hoverLatestMaxAge = 60 * 60
+)
+
+// serveHover handles requests for /api/v1/hover/<path>[@<version>]?symbol=<symbol>,
+// returning the signature and documentation of the symbol as a Hover. Methods
+// are named by their receiver type, like "Client.Do".
+func (s *Server) serveHover(w http.ResponseWriter, r *http.Request) (_ interface{}, err error) {
+	defer derrors.Wrap(&err, "serveHover(%q)", r.URL.Path)
+
+	ctx := r.Context()
+	symbol := r.FormValue("symbol")
+	if symbol == "" {
+		return nil, &serverError{status: http.StatusBadRequest, err: errors.New("missing symbol")}
+	}
+	fullPath, modulePath, version, err := parseAPIPath(strings.TrimPrefix(r.URL.Path, apiPrefix+"hover"))
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := s.ds.GetPackage(ctx, fullPath, modulePath, version, internal.WithDocumentationHTML)
+	if err != nil {
+		return nil, err
+	}
+	if !pkg.LegacyPackage.IsRedistributable {
+		return nil, derrors.NotFound
+	}
+	doc, err := docHTMLToJSON(pkg.DocumentationHTML)
+	if err != nil {
+		return nil, err
+	}
+	h := findSymbol(doc, symbol)
+	if h == nil {
+		return nil, fmt.Errorf("symbol %q: %w", symbol, derrors.NotFound)
+	}
+	h.Path = pkg.Path
+	h.ModulePath = pkg.ModulePath
+	h.Version = pkg.Version
+	h.URL = constructPackageURL(pkg.Path, pkg.ModulePath, linkVersion(pkg.Version, pkg.ModulePath)) + "#" + symbol
+	h.Since, err = s.symbolSince(ctx, pkg, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	maxAge := hoverLatestMaxAge
+	if version != internal.LatestVersion {
+		maxAge = hoverVersionedMaxAge
+	}
+	scope := "public"
+	if s.private() {
+		scope = "private"
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, maxAge))
+	return h, nil
+}
+
+// findSymbol returns the Hover for symbol in doc, with only the symbol's
+// fields set, or nil if doc has no such symbol.
+func findSymbol(doc *DocJSON, symbol string) *Hover {
+	newHover := func(kind string, d *DeclJSON) *Hover {
+		return &Hover{Symbol: symbol, Kind: kind, Signature: d.Decl, Doc: d.Doc}
+	}
+	findValue := func(kind string, decls []*DeclJSON) *Hover {
+		for _, d := range decls {
+			for _, n := range d.Names {
+				if n == symbol {
+					return newHover(kind, d)
+				}
+			}
+		}
+		return nil
+	}
+	if h := findValue("constant", doc.Consts); h != nil {
+		return h
+	}
+	if h := findValue("variable", doc.Vars); h != nil {
+		return h
+	}
+	for _, f := range doc.Funcs {
+		if f.Name == symbol {
+			return newHover("function", f)
+		}
+	}
+	for _, t := range doc.Types {
+		if t.Name == symbol {
+			return &Hover{Symbol: symbol, Kind: "type", Signature: t.Decl, Doc: t.Doc}
+		}
+		if h := findValue("constant", t.Consts); h != nil {
+			return h
+		}
+		if h := findValue("variable", t.Vars); h != nil {
+			return h
+		}
+		for _, f := range t.Funcs {
+			if f.Name == symbol {
+				return newHover("function", f)
+			}
+		}
+		for _, m := range t.Methods {
+			if t.Name+"."+m.Name == symbol {
+				return newHover("method", m)
+			}
+		}
+	}
+	return nil
+}
+
+// symbolSince returns the earliest tagged version of pkg's module, up to
+// pkg's version, in which the package documents symbol. It assumes that
+// symbols are not removed once added, and so needs to look at the
+// documentation of only a few versions.
+func (s *Server) symbolSince(ctx context.Context, pkg *internal.LegacyVersionedPackage, symbol string) (_ string, err error) {
+	defer derrors.Wrap(&err, "symbolSince(%q, %q)", pkg.Path, symbol)
+
+	infos, err := s.ds.GetTaggedVersionsForPackageSeries(ctx, pkg.Path)
+	if err != nil {
+		return "", err
+	}
+	var versions []string
+	for _, mi := range infos {
+		if mi.ModulePath == pkg.ModulePath && semver.Compare(mi.Version, pkg.Version) <= 0 {
+			versions = append(versions, mi.Version)
+		}
+	}
+	if len(versions) == 0 {
+		return "", nil
+	}
+	sort.Slice(versions, func(i, j int) bool { return semver.Compare(versions[i], versions[j]) < 0 })
+
+	var searchErr error
+	i := sort.Search(len(versions), func(i int) bool {
+		if searchErr != nil {
+			return true
+		}
+		p, err := s.ds.GetPackage(ctx, pkg.Path, pkg.ModulePath, versions[i], internal.WithDocumentationHTML)
+		if err != nil {
+			if !errors.Is(err, derrors.NotFound) {
+				searchErr = err
+			}
+			return false
+		}
+		doc, err := docHTMLToJSON(p.DocumentationHTML)
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return findSymbol(doc, symbol) != nil
+	})
+	if searchErr != nil {
+		return "", searchErr
+	}
+	if i == len(versions) {
+		// The symbol is only in a pseudo-version.
+		return "", nil
+	}
+	return versions[i], nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindSymbol(t *testing.T) {
+	doc := &DocJSON{
+		Consts: []*DeclJSON{{Names: []string{"A", "B"}, Decl: "const (\n\tA = 1\n\tB = 2\n)"}},
+		Funcs:  []*DeclJSON{{Name: "Do", Decl: "func Do() error", Doc: "Do does it."}},
+		Types: []*TypeJSON{{
+			Name:    "T",
+			Decl:    "type T struct{}",
+			Doc:     "T is a type.",
+			Vars:    []*DeclJSON{{Names: []string{"DefaultT"}, Decl: "var DefaultT T"}},
+			Funcs:   []*DeclJSON{{Name: "New", Decl: "func New() *T"}},
+			Methods: []*DeclJSON{{Name: "Run", Recv: "*T", Decl: "func (t *T) Run()", Doc: "Run runs."}},
+		}},
+	}
+	for _, test := range []struct {
+		symbol string
+		want   *Hover
+	}{
+		{"B", &Hover{Symbol: "B", Kind: "constant", Signature: "const (\n\tA = 1\n\tB = 2\n)"}},
+		{"Do", &Hover{Symbol: "Do", Kind: "function", Signature: "func Do() error", Doc: "Do does it."}},
+		{"T", &Hover{Symbol: "T", Kind: "type", Signature: "type T struct{}", Doc: "T is a type."}},
+		{"DefaultT", &Hover{Symbol: "DefaultT", Kind: "variable", Signature: "var DefaultT T"}},
+		{"New", &Hover{Symbol: "New", Kind: "function", Signature: "func New() *T"}},
+		{"T.Run", &Hover{Symbol: "T.Run", Kind: "method", Signature: "func (t *T) Run()", Doc: "Run runs."}},
+		{"Run", nil},
+		{"C", nil},
+	} {
+		got := findSymbol(doc, test.symbol)
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("findSymbol(%q) mismatch (-want +got):\n%s", test.symbol, diff)
+		}
+	}
+}
@@ -6,9 +6,11 @@ package frontend
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/postgres"
 	"golang.org/x/pkgsite/internal/stdlib"
 )
@@ -28,6 +30,13 @@ type ImportsDetails struct {
 	// StdLib is an array of packages representing the package's imports
 	// that are in the Go standard library.
 	StdLib []string
+
+	// PreviousVersion is the display version of the closest earlier version
+	// of the module that contains the package, if any.
+	PreviousVersion string
+	// Added and Removed are the imports that the package gained and lost
+	// since PreviousVersion.
+	Added, Removed []string
 }
 
 // fetchImportsDetails fetches imports for the package version specified by
@@ -49,12 +58,62 @@ func fetchImportsDetails(ctx context.Context, ds internal.DataSource, pkgPath, m
 		}
 	}
 
-	return &ImportsDetails{
+	details := &ImportsDetails{
 		ModulePath:      modulePath,
 		ExternalImports: externalImports,
 		InternalImports: moduleImports,
 		StdLib:          std,
-	}, nil
+	}
+	if err := addImportsDiff(ctx, ds, details, dsImports, pkgPath, modulePath, version); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+// addImportsDiff sets the imports that the package gained and lost since the
+// previous version of its module that contains it. Only the database knows
+// the previous version.
+func addImportsDiff(ctx context.Context, ds internal.DataSource, details *ImportsDetails, imports []string, pkgPath, modulePath, version string) error {
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		return nil
+	}
+	prev, err := db.GetPreviousPackageVersion(ctx, pkgPath, modulePath, version)
+	if errors.Is(err, derrors.NotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	prevImports, err := db.GetImports(ctx, pkgPath, modulePath, prev)
+	if err != nil {
+		return err
+	}
+	details.PreviousVersion = displayVersion(prev, modulePath)
+	details.Added, details.Removed = diffImports(prevImports, imports)
+	return nil
+}
+
+// diffImports returns the paths in to but not in from, and those in from but
+// not in to, in the order of from and to.
+func diffImports(from, to []string) (added, removed []string) {
+	inFrom := map[string]bool{}
+	for _, p := range from {
+		inFrom[p] = true
+	}
+	inTo := map[string]bool{}
+	for _, p := range to {
+		inTo[p] = true
+		if !inFrom[p] {
+			added = append(added, p)
+		}
+	}
+	for _, p := range from {
+		if !inTo[p] {
+			removed = append(removed, p)
+		}
+	}
+	return added, removed
 }
 
 // ImportedByDetails contains information for the collection of packages that
@@ -67,7 +126,7 @@ type ImportedByDetails struct {
 	// They are organized into a tree of sections by prefix.
 	ImportedBy []*Section
 
-	Total        int  // number of packages in ImportedBy
+	Total        int  // number of importers, at least the number in ImportedBy
 	TotalIsExact bool // if false, then there may be more than Total
 }
 
@@ -84,16 +143,26 @@ func fetchImportedByDetails(ctx context.Context, db *postgres.DB, pkgPath, modul
 	// Say so, and show one less than the limit.
 	// For example, if the limit is 101 and we get 101 results, then we'll
 	// say there are more than 100, and show the first 100.
+	total := len(importedBy)
 	totalIsExact := true
 	if len(importedBy) == importedByLimit {
 		importedBy = importedBy[:len(importedBy)-1]
+		total = len(importedBy)
 		totalIsExact = false
+		// The precomputed count is a better estimate of the total.
+		n, err := db.GetImportedByCount(ctx, pkgPath, modulePath)
+		if err != nil {
+			return nil, err
+		}
+		if n > total {
+			total = n
+		}
 	}
 	sections := Sections(importedBy, nextPrefixAccount)
 	return &ImportedByDetails{
 		ModulePath:   modulePath,
 		ImportedBy:   sections,
-		Total:        len(importedBy),
+		Total:        total,
 		TotalIsExact: totalIsExact,
 	}, nil
 }
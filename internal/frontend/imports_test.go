@@ -71,6 +71,59 @@ func TestFetchImportsDetails(t *testing.T) {
 	}
 }
 
+func TestFetchImportsDetailsDiff(t *testing.T) {
+	defer postgres.ResetTestDB(testDB, t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	for _, m := range []struct {
+		version string
+		imports []string
+	}{
+		{"v1.0.0", []string{"context", "pa.th/import/1", "pa.th/import/2"}},
+		{"v1.1.0", []string{"context", "fmt", "pa.th/import/2"}},
+	} {
+		module := sample.Module(sample.ModulePath, m.version, sample.Suffix)
+		module.LegacyPackages[0].Imports = m.imports
+		if err := testDB.InsertModule(ctx, module); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := fetchImportsDetails(ctx, testDB, sample.PackagePath, sample.ModulePath, "v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.PreviousVersion != "v1.0.0" {
+		t.Errorf("PreviousVersion = %q, want %q", got.PreviousVersion, "v1.0.0")
+	}
+	if diff := cmp.Diff([]string{"fmt"}, got.Added); diff != "" {
+		t.Errorf("Added mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"pa.th/import/1"}, got.Removed); diff != "" {
+		t.Errorf("Removed mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = fetchImportsDetails(ctx, testDB, sample.PackagePath, sample.ModulePath, "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.PreviousVersion != "" || got.Added != nil || got.Removed != nil {
+		t.Errorf("first version: got PreviousVersion %q, Added %v, Removed %v; want none", got.PreviousVersion, got.Added, got.Removed)
+	}
+}
+
+func TestDiffImports(t *testing.T) {
+	added, removed := diffImports([]string{"a", "b", "c"}, []string{"b", "c", "d", "e"})
+	if diff := cmp.Diff([]string{"d", "e"}, added); diff != "" {
+		t.Errorf("added mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"a"}, removed); diff != "" {
+		t.Errorf("removed mismatch (-want +got):\n%s", diff)
+	}
+}
+
 // firstVersionedPackage is a helper function that returns an
 // *internal.LegacyVersionedPackage corresponding to the first package in the
 // version.
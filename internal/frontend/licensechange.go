@@ -0,0 +1,109 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// LicenseChange describes a change of the license types at the root of a
+// module between a version and the version before it.
+type LicenseChange struct {
+	// PreviousVersion is the display version of the version before.
+	PreviousVersion string
+	// From and To are the license types before and after the change, like
+	// "MIT" or "Apache-2.0, MIT", or "none".
+	From, To string
+}
+
+// licenseChanges returns the license changes of the versions in history,
+// which must be sorted in descending order, by version. The first version
+// has no change.
+func licenseChanges(modulePath string, history []*postgres.VersionLicenses) map[string]*LicenseChange {
+	changes := map[string]*LicenseChange{}
+	for i := 0; i+1 < len(history); i++ {
+		cur, prev := history[i], history[i+1]
+		if sameTypes(cur.Types, prev.Types) {
+			continue
+		}
+		changes[cur.Version] = &LicenseChange{
+			PreviousVersion: displayVersion(prev.Version, modulePath),
+			From:            formatLicenseTypes(prev.Types),
+			To:              formatLicenseTypes(cur.Types),
+		}
+	}
+	return changes
+}
+
+func sameTypes(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatLicenseTypes(types []string) string {
+	if len(types) == 0 {
+		return "none"
+	}
+	return strings.Join(types, ", ")
+}
+
+// licenseChange returns the license change of the given module version, or
+// nil if its licenses are the same as those of the version before. A missing
+// banner is better than a failed page, so errors are only logged.
+func (s *Server) licenseChange(ctx context.Context, modulePath, version string) *LicenseChange {
+	db, ok := s.ds.(*postgres.DB)
+	if !ok || modulePath == stdlib.ModulePath {
+		// Only the database has the licenses of every version.
+		return nil
+	}
+	history, err := db.GetLicenseHistory(ctx, modulePath)
+	if err != nil {
+		log.Error(ctx, err)
+		return nil
+	}
+	return licenseChanges(modulePath, history)[version]
+}
+
+// addVersionsLicenseChanges marks the versions in vd whose licenses differ
+// from those of the version before.
+func addVersionsLicenseChanges(ctx context.Context, ds internal.DataSource, vd *VersionsDetails) error {
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		return nil
+	}
+	byModule := map[string]map[string]*LicenseChange{}
+	for _, vl := range append(append([]*VersionList(nil), vd.ThisModule...), vd.OtherModules...) {
+		if vl.ModulePath == stdlib.ModulePath {
+			continue
+		}
+		changes, ok := byModule[vl.ModulePath]
+		if !ok {
+			history, err := db.GetLicenseHistory(ctx, vl.ModulePath)
+			if err != nil {
+				return err
+			}
+			changes = licenseChanges(vl.ModulePath, history)
+			byModule[vl.ModulePath] = changes
+		}
+		for _, vs := range vl.Versions {
+			// Outside the standard library, the tooltip is the full version.
+			vs.LicenseChange = changes[vs.TooltipVersion]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestLicenseChanges(t *testing.T) {
+	history := []*postgres.VersionLicenses{
+		{Version: "v1.3.0"},
+		{Version: "v1.2.0", Types: []string{"BUSL-1.1"}},
+		{Version: "v1.1.0", Types: []string{"MIT"}},
+		{Version: "v1.0.0", Types: []string{"MIT"}},
+	}
+	want := map[string]*LicenseChange{
+		"v1.3.0": {PreviousVersion: "v1.2.0", From: "BUSL-1.1", To: "none"},
+		"v1.2.0": {PreviousVersion: "v1.1.0", From: "MIT", To: "BUSL-1.1"},
+	}
+	if diff := cmp.Diff(want, licenseChanges("example.com/m", history)); diff != "" {
+		t.Errorf("licenseChanges mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLicenseChangeBanner(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+
+	const modulePath = "example.com/m"
+	for _, m := range []struct {
+		version, licenseType string
+	}{
+		{"v1.0.0", "MIT"},
+		{"v1.1.0", "BUSL-1.1"},
+	} {
+		mod := sample.Module(modulePath, m.version, "p")
+		mod.Licenses = []*licenses.License{{
+			Metadata: &licenses.Metadata{Types: []string{m.licenseType}, FilePath: "LICENSE"},
+			Contents: []byte("Lorem Ipsum"),
+		}}
+		if err := testDB.InsertModule(ctx, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, test := range []struct {
+		url  string
+		want bool
+	}{
+		{"/mod/example.com/m@v1.0.0", false},
+		{"/mod/example.com/m@v1.1.0", true},
+		{"/example.com/m/p@v1.1.0", true},
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", test.url, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d, want %d", test.url, w.Code, http.StatusOK)
+		}
+		if got := strings.Contains(w.Body.String(), `data-test-id="DetailsLicenseChange"`); got != test.want {
+			t.Errorf("%s: license change banner shown = %t, want %t", test.url, got, test.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/mod/example.com/m?tab=versions", nil))
+	if got, want := strings.Count(w.Body.String(), `class="Versions-licenseChange"`), 1; got != want {
+		t.Errorf("versions tab: got %d license changes, want %d", got, want)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/lookalike"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// lookalikeFlag returns the flag for modulePath if its path looks like the
+// path of a popular module and the flag has not been dismissed, or nil. A
+// missing warning is better than a failed page, so errors are only logged.
+func (s *Server) lookalikeFlag(ctx context.Context, modulePath string) *lookalike.Flag {
+	db, ok := s.ds.(*postgres.DB)
+	if !ok {
+		// Only the database records flags.
+		return nil
+	}
+	f, err := db.GetLookalike(ctx, modulePath)
+	if err != nil {
+		if !errors.Is(err, derrors.NotFound) {
+			log.Error(ctx, err)
+		}
+		return nil
+	}
+	if f.Status == lookalike.StatusDismissed {
+		return nil
+	}
+	return f
+}
@@ -49,12 +49,16 @@ func (s *Server) serveModulePage(w http.ResponseWriter, r *http.Request, moduleP
 }
 
 func (s *Server) serveModulePageWithModule(ctx context.Context, w http.ResponseWriter, r *http.Request, mi *internal.LegacyModuleInfo, requestedVersion string) error {
+	state := s.moduleState(ctx, mi.ModulePath, mi.SourceInfo.RepoURL(), "")
+	if s.serveNotModified(ctx, w, r, mi.ModulePath, mi.ModulePath, mi.Version, "mod", state) {
+		return nil
+	}
 	licenses, err := s.ds.GetModuleLicenses(ctx, mi.ModulePath, mi.Version)
 	if err != nil {
 		return err
 	}
 
-	modHeader := createModule(&mi.ModuleInfo, licensesToMetadatas(licenses), requestedVersion == internal.LatestVersion)
+	modHeader := createModule(ctx, &mi.ModuleInfo, licensesToMetadatas(licenses), requestedVersion == internal.LatestVersion)
 	tab := r.FormValue("tab")
 	settings, ok := moduleTabLookup[tab]
 	if !ok {
@@ -80,7 +84,11 @@ func (s *Server) serveModulePageWithModule(ctx context.Context, w http.ResponseW
 		CanShowDetails: canShowDetails,
 		Tabs:           moduleTabSettings,
 		PageType:       "mod",
+		LicenseChange:  s.licenseChange(ctx, mi.ModulePath, mi.Version),
+		ModuleState:    state,
 	}
-	s.servePage(ctx, w, settings.TemplateName, page)
+	page.NoIndex = noIndex(r, requestedVersion, mi.Version)
+	tagModuleVersion(ctx, w, mi.ModulePath, mi.Version)
+	s.serveDetailsPage(w, r, page)
 	return nil
 }
@@ -0,0 +1,90 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// ModuleJSON describes a module version, as served by the module API
+// endpoint.
+type ModuleJSON struct {
+	Path            string    `json:"path"`
+	Version         string    `json:"version"`
+	CommitTime      time.Time `json:"commitTime"`
+	VersionType     string    `json:"versionType"`
+	Redistributable bool      `json:"redistributable"`
+	HasGoMod        bool      `json:"hasGoMod"`
+	RepositoryURL   string    `json:"repositoryURL,omitempty"`
+	// Origin is where the module proxy got the module version from, so that
+	// users can check which commit the documentation was built from. It is
+	// omitted if the proxy did not report it.
+	Origin *OriginJSON `json:"origin,omitempty"`
+}
+
+// OriginJSON is the version control origin of a module version.
+type OriginJSON struct {
+	VCS    string `json:"vcs,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Subdir string `json:"subdir,omitempty"`
+	Hash   string `json:"hash,omitempty"`
+	Ref    string `json:"ref,omitempty"`
+}
+
+// serveModuleJSON handles requests for /api/v1/module/<module-path>[@<version>],
+// returning information about the module version as a ModuleJSON.
+func (s *Server) serveModuleJSON(w http.ResponseWriter, r *http.Request) (_ interface{}, err error) {
+	defer derrors.Wrap(&err, "serveModuleJSON(%q)", r.URL.Path)
+
+	ctx := r.Context()
+	fullPath, modulePath, version, err := parseAPIPath(strings.TrimPrefix(r.URL.Path, apiPrefix+"module"))
+	if err != nil {
+		return nil, err
+	}
+	if modulePath == internal.UnknownModulePath {
+		modulePath = fullPath
+	}
+	if fullPath != modulePath {
+		return nil, &serverError{
+			status: http.StatusBadRequest,
+			err:    fmt.Errorf("%q is not a module path", fullPath),
+		}
+	}
+	if modulePath == stdlib.ModulePath && version == internal.MasterVersion {
+		version, err = s.stdlibMasterVersion(ctx, fullPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	mi, err := s.ds.GetModuleInfo(ctx, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+	return moduleJSON(&mi.ModuleInfo), nil
+}
+
+// moduleJSON returns the ModuleJSON for mi.
+func moduleJSON(mi *internal.ModuleInfo) *ModuleJSON {
+	m := &ModuleJSON{
+		Path:            mi.ModulePath,
+		Version:         mi.Version,
+		CommitTime:      mi.CommitTime,
+		VersionType:     string(mi.VersionType),
+		Redistributable: mi.IsRedistributable,
+		HasGoMod:        mi.HasGoMod,
+		RepositoryURL:   mi.SourceInfo.RepoURL(),
+	}
+	if o := mi.Origin; o != nil {
+		m.Origin = &OriginJSON{VCS: o.VCS, URL: o.URL, Subdir: o.Subdir, Hash: o.Hash, Ref: o.Ref}
+	}
+	return m
+}
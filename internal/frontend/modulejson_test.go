@@ -0,0 +1,43 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+func TestModuleJSON(t *testing.T) {
+	commitTime := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	mi := &internal.ModuleInfo{
+		ModulePath:        "github.com/a/b",
+		Version:           "v1.2.3",
+		CommitTime:        commitTime,
+		VersionType:       version.TypeRelease,
+		IsRedistributable: true,
+		HasGoMod:          true,
+	}
+	want := &ModuleJSON{
+		Path:            "github.com/a/b",
+		Version:         "v1.2.3",
+		CommitTime:      commitTime,
+		VersionType:     "release",
+		Redistributable: true,
+		HasGoMod:        true,
+	}
+	if diff := cmp.Diff(want, moduleJSON(mi)); diff != "" {
+		t.Errorf("moduleJSON without origin mismatch (-want +got):\n%s", diff)
+	}
+
+	mi.Origin = &internal.Origin{VCS: "git", URL: "https://github.com/a/b", Hash: "abc123", Ref: "refs/tags/v1.2.3"}
+	want.Origin = &OriginJSON{VCS: "git", URL: "https://github.com/a/b", Hash: "abc123", Ref: "refs/tags/v1.2.3"}
+	if diff := cmp.Diff(want, moduleJSON(mi)); diff != "" {
+		t.Errorf("moduleJSON with origin mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"sort"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+const (
+	// maxSummaryPackages is the number of packages in a ModuleSummary.
+	maxSummaryPackages = 5
+
+	// maxSummaryVersions is the number of versions in a ModuleSummary.
+	maxSummaryVersions = 3
+)
+
+// ModuleSummary is the essentials of a module version, shown on the overview
+// tab of the module so that users need not click through the other tabs.
+type ModuleSummary struct {
+	// Packages are the packages of the module that are imported the most,
+	// at most maxSummaryPackages of them.
+	Packages []*SummaryPackage
+	// NumPackages is the number of packages in the module.
+	NumPackages int
+	// Licenses are the licenses at the root of the module.
+	Licenses []LicenseMetadata
+	// Versions are the latest versions of the module, newest first.
+	Versions []*VersionSummary
+	// URL is the URL of the module version, to which the names of tabs are
+	// added for links to them.
+	URL string
+}
+
+// SummaryPackage is a package in a ModuleSummary.
+type SummaryPackage struct {
+	Path     string
+	Synopsis string
+	URL      string
+	// NumImportedBy is the number of packages in other modules that import
+	// the package, if known.
+	NumImportedBy int
+}
+
+// fetchModuleSummary returns the ModuleSummary of the module version mi,
+// whose licenses are lics.
+func fetchModuleSummary(ctx context.Context, ds internal.DataSource, mi *internal.LegacyModuleInfo, lics []*licenses.License, versionedLinks bool) (_ *ModuleSummary, err error) {
+	defer derrors.Wrap(&err, "fetchModuleSummary(%q, %q)", mi.ModulePath, mi.Version)
+
+	lv := internal.LatestVersion
+	if versionedLinks {
+		lv = linkVersion(mi.Version, mi.ModulePath)
+	}
+	summary := &ModuleSummary{
+		Licenses: transformLicenseMetadata(licensesToMetadatas(lics)),
+		URL:      constructModuleURL(mi.ModulePath, lv),
+	}
+	pkgs, err := ds.GetPackagesInModule(ctx, mi.ModulePath, mi.Version)
+	if err != nil {
+		return nil, err
+	}
+	summary.NumPackages = len(pkgs)
+	var counts map[string]int
+	// Data sources other than the database do not count importers.
+	if db, ok := ds.(*postgres.DB); ok && len(pkgs) > 0 {
+		var paths []string
+		for _, p := range pkgs {
+			paths = append(paths, p.Path)
+		}
+		counts, err = db.GetImportedByCounts(ctx, mi.ModulePath, paths)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range pkgs {
+		summary.Packages = append(summary.Packages, &SummaryPackage{
+			Path:          p.Path,
+			Synopsis:      p.Synopsis,
+			URL:           constructPackageURL(p.Path, mi.ModulePath, lv),
+			NumImportedBy: counts[p.Path],
+		})
+	}
+	sort.SliceStable(summary.Packages, func(i, j int) bool {
+		pi, pj := summary.Packages[i], summary.Packages[j]
+		if pi.NumImportedBy != pj.NumImportedBy {
+			return pi.NumImportedBy > pj.NumImportedBy
+		}
+		return pi.Path < pj.Path
+	})
+	if len(summary.Packages) > maxSummaryPackages {
+		summary.Packages = summary.Packages[:maxSummaryPackages]
+	}
+
+	versions, err := ds.GetTaggedVersionsForModule(ctx, mi.ModulePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		versions, err = ds.GetPseudoVersionsForModule(ctx, mi.ModulePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range versions {
+		// The versions of other major versions in the series are returned
+		// too.
+		if v.ModulePath != mi.ModulePath {
+			continue
+		}
+		summary.Versions = append(summary.Versions, &VersionSummary{
+			TooltipVersion:  v.Version,
+			DisplayVersion:  displayVersion(v.Version, v.ModulePath),
+			CommitTime:      elapsedTime(ctx, v.CommitTime),
+			CommitTimestamp: timestamp(v.CommitTime),
+			Link:            constructModuleURL(v.ModulePath, linkVersion(v.Version, v.ModulePath)),
+		})
+		if len(summary.Versions) == maxSummaryVersions {
+			break
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestFetchModuleSummary(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	defer postgres.ResetTestDB(testDB, t)
+	for _, v := range []string{"v1.0.0", "v1.1.0"} {
+		if err := testDB.InsertModule(ctx, sample.Module("example.com/m", v, "b", "a")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mi, err := testDB.GetModuleInfo(ctx, "example.com/m", "v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lics, err := testDB.GetModuleLicenses(ctx, mi.ModulePath, mi.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := fetchModuleSummary(ctx, testDB, mi, lics, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ModuleSummary{
+		Packages: []*SummaryPackage{
+			{Path: "example.com/m/a", Synopsis: sample.Synopsis, URL: "/example.com/m@v1.1.0/a"},
+			{Path: "example.com/m/b", Synopsis: sample.Synopsis, URL: "/example.com/m@v1.1.0/b"},
+		},
+		NumPackages: 2,
+		Licenses:    transformLicenseMetadata(sample.LicenseMetadata),
+		Versions: []*VersionSummary{
+			{TooltipVersion: "v1.1.0", DisplayVersion: "v1.1.0", Link: "/mod/example.com/m@v1.1.0"},
+			{TooltipVersion: "v1.0.0", DisplayVersion: "v1.0.0", Link: "/mod/example.com/m@v1.0.0"},
+		},
+		URL: "/mod/example.com/m@v1.1.0",
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(VersionSummary{}, "CommitTime", "CommitTimestamp")); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/pkgsite/internal/complete"
+	"golang.org/x/pkgsite/internal/middleware"
+)
+
+// maxOpenSearchShortName is the maximum length of the ShortName of an
+// OpenSearch description.
+const maxOpenSearchShortName = 16
+
+// openSearchDescription is an OpenSearch description document, as described
+// in https://github.com/dewitt/opensearch. Browsers use it to add the site as
+// a search engine.
+type openSearchDescription struct {
+	XMLName       xml.Name        `xml:"http://a9.com/-/spec/opensearch/1.1/ OpenSearchDescription"`
+	ShortName     string          `xml:"ShortName"`
+	Description   string          `xml:"Description"`
+	InputEncoding string          `xml:"InputEncoding"`
+	Image         openSearchImage `xml:"Image"`
+	URLs          []openSearchURL `xml:"Url"`
+}
+
+type openSearchImage struct {
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+	Type   string `xml:"type,attr"`
+	URL    string `xml:",chardata"`
+}
+
+type openSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Rel      string `xml:"rel,attr,omitempty"`
+	Template string `xml:"template,attr"`
+}
+
+// handleOpenSearch serves the OpenSearch description of the site at
+// /opensearch.xml. Searches take the standard searchTerms, count and
+// startIndex parameters as q, limit and start, and suggestions come from the
+// autocomplete endpoint.
+func (s *Server) handleOpenSearch(w http.ResponseWriter, r *http.Request) {
+	base := siteURL(r)
+	shortName := s.branding.SiteName
+	if len(shortName) > maxOpenSearchShortName {
+		shortName = shortName[:maxOpenSearchShortName]
+	}
+	d := &openSearchDescription{
+		ShortName:     shortName,
+		Description:   "Search for Go packages on " + s.branding.SiteName,
+		InputEncoding: "UTF-8",
+		Image:         openSearchImage{Width: 16, Height: 16, Type: "image/x-icon", URL: base + "/favicon.ico"},
+		URLs: []openSearchURL{
+			{Type: "text/html", Template: base + "/search?q={searchTerms}&limit={count?}&start={startIndex?}"},
+			{Type: "application/x-suggestions+json", Template: base + "/autocomplete?format=opensearch&q={searchTerms}"},
+			{Type: "application/opensearchdescription+xml", Rel: "self", Template: base + "/opensearch.xml"},
+		},
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(d); err != nil {
+		s.serveError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/opensearchdescription+xml")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		middleware.RecordRequestError(r.Context(), fmt.Errorf("handleOpenSearch: error writing response: %v", err))
+	}
+}
+
+// openSearchSuggestions returns completions for the query q in the format of
+// OpenSearch suggestions: the query followed by the list of completed package
+// paths.
+func openSearchSuggestions(q string, completions []*complete.Completion) []interface{} {
+	paths := []string{}
+	for _, c := range completions {
+		paths = append(paths, c.PackagePath)
+	}
+	return []interface{}{q, paths}
+}
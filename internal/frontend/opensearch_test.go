@@ -0,0 +1,71 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/complete"
+)
+
+func TestOpenSearch(t *testing.T) {
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/opensearch.xml", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	if got, want := w.Header().Get("Content-Type"), "application/opensearchdescription+xml"; got != want {
+		t.Errorf("Content-Type = %q, want %q", got, want)
+	}
+	var got openSearchDescription
+	if err := xml.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ShortName != "pkg.go.dev" {
+		t.Errorf("ShortName = %q, want %q", got.ShortName, "pkg.go.dev")
+	}
+	templates := map[string]string{}
+	for _, u := range got.URLs {
+		templates[u.Type] = u.Template
+	}
+	want := map[string]string{
+		"text/html":                             "https://example.com/search?q={searchTerms}&limit={count?}&start={startIndex?}",
+		"application/x-suggestions+json":        "https://example.com/autocomplete?format=opensearch&q={searchTerms}",
+		"application/opensearchdescription+xml": "https://example.com/opensearch.xml",
+	}
+	if diff := cmp.Diff(want, templates); diff != "" {
+		t.Errorf("templates mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOpenSearchSuggestions(t *testing.T) {
+	completions := []*complete.Completion{
+		{PackagePath: "foo.com/bar/baz"},
+		{PackagePath: "foo.com/quux/bark"},
+	}
+	for _, test := range []struct {
+		completions []*complete.Completion
+		want        string
+	}{
+		{completions, `["ba",["foo.com/bar/baz","foo.com/quux/bark"]]`},
+		{nil, `["ba",[]]`},
+	} {
+		b, err := json.Marshal(openSearchSuggestions("ba", test.completions))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(b); got != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+const (
+	// defaultOrgLimit is the number of modules on a page of an organization.
+	defaultOrgLimit = 100
+
+	// maxOrgModules is the number of modules of an organization that can be
+	// paged through.
+	maxOrgModules = 10000
+)
+
+// orgPathRegexp matches the paths of organizations or users on code hosts
+// whose repositories are at <host>/<org>/<repo>. No module can be at such a
+// path, since it is not a repository.
+var orgPathRegexp = regexp.MustCompile(`^(github\.com|gitlab\.com|bitbucket\.org|gitee\.com)/[a-zA-Z0-9_.\-]+$`)
+
+// isOrgPath reports whether fullPath is the path of an organization on a
+// code host, like github.com/golang.
+func isOrgPath(fullPath string) bool {
+	return orgPathRegexp.MatchString(fullPath)
+}
+
+// orgPage lists the modules of an organization.
+type orgPage struct {
+	basePage
+	// Org is the path of the organization, like github.com/golang.
+	Org        string
+	Modules    []*OrgModule
+	Pagination pagination
+}
+
+// OrgModule is what an organization page shows about the latest version of a
+// module.
+type OrgModule struct {
+	ModulePath      string
+	Synopsis        string
+	Version         string
+	CommitTime      string
+	CommitTimestamp string // CommitTime in RFC 3339
+}
+
+// serveOrgPage serves the page listing the modules in search under the
+// organization at orgPath, like github.com/golang.
+func (s *Server) serveOrgPage(w http.ResponseWriter, r *http.Request, db *postgres.DB, orgPath string) (err error) {
+	defer derrors.Wrap(&err, "serveOrgPage(%q)", orgPath)
+
+	ctx := r.Context()
+	params, err := newPaginationParams(r, defaultOrgLimit, maxOrgModules)
+	if err != nil {
+		return &serverError{status: http.StatusBadRequest, err: err}
+	}
+	mods, total, err := db.GetModulesByPrefix(ctx, orgPath+"/", params.limit, params.offset())
+	if err != nil {
+		return err
+	}
+	if total == 0 {
+		return &serverError{
+			status: http.StatusNotFound,
+			epage: &errorPage{
+				Message:          fmt.Sprintf("There are no known modules under %s.", orgPath),
+				SecondaryMessage: suggestedSearch(orgPath),
+			},
+			err: fmt.Errorf("no modules under %q: %w", orgPath, derrors.NotFound),
+		}
+	}
+	page := &orgPage{
+		basePage:   s.newBasePage(r, orgPath),
+		Org:        orgPath,
+		Pagination: newPagination(params, len(mods), total),
+	}
+	for _, m := range mods {
+		page.Modules = append(page.Modules, &OrgModule{
+			ModulePath:      m.ModulePath,
+			Synopsis:        m.Synopsis,
+			Version:         displayVersion(m.Version, m.ModulePath),
+			CommitTime:      elapsedTime(ctx, m.CommitTime),
+			CommitTimestamp: timestamp(m.CommitTime),
+		})
+	}
+	s.servePage(ctx, w, "org.tmpl", page)
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestIsOrgPath(t *testing.T) {
+	for _, test := range []struct {
+		path string
+		want bool
+	}{
+		{"github.com/golang", true},
+		{"gitlab.com/gitlab-org", true},
+		{"github.com", false},
+		{"github.com/golang/go", false},
+		{"golang.org/x", false},
+		{"example.com/org", false},
+	} {
+		if got := isOrgPath(test.path); got != test.want {
+			t.Errorf("isOrgPath(%q) = %t, want %t", test.path, got, test.want)
+		}
+	}
+}
+
+func TestServeOrgPage(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	for _, m := range []string{"github.com/org/a", "github.com/org/b", "github.com/organization/c"} {
+		if err := testDB.InsertModule(ctx, sample.Module(m, sample.VersionString, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, test := range []struct {
+		name, path  string
+		wantCode    int
+		wantBody    []string
+		notWantBody []string
+	}{
+		{
+			"org",
+			"/github.com/org",
+			http.StatusOK,
+			[]string{`href="/mod/github.com/org/a"`, `href="/mod/github.com/org/b"`, sample.Synopsis, "v1.0.0"},
+			[]string{"github.com/organization/c"},
+		},
+		{
+			"second page",
+			"/github.com/org?limit=1&cursor=" + encodeCursor(1),
+			http.StatusOK,
+			[]string{`href="/mod/github.com/org/b"`, "2 – 2 of 2"},
+			[]string{`href="/mod/github.com/org/a"`},
+		},
+		{"unknown org", "/github.com/nobody", http.StatusNotFound, []string{"github.com/nobody"}, nil},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+			if w.Code != test.wantCode {
+				t.Fatalf("got code %d, want %d", w.Code, test.wantCode)
+			}
+			for _, want := range test.wantBody {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("body does not contain %q:\n%s", want, w.Body)
+				}
+			}
+			for _, notWant := range test.notWantBody {
+				if strings.Contains(w.Body.String(), notWant) {
+					t.Errorf("body contains %q", notWant)
+				}
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/russross/blackfriday/v2"
@@ -21,6 +22,7 @@ import (
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/experiment"
 	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/similar"
 	"golang.org/x/pkgsite/internal/stdlib"
 )
 
@@ -32,10 +34,57 @@ type OverviewDetails struct {
 	PackageSourceURL string
 	ReadMe           template.HTML
 	ReadMeSource     string
-	Redistributable  bool
-	RepositoryURL    string
+	// ReadMeFilePath is the path of the README file in the module, like
+	// "cmd/tool/README.md".
+	ReadMeFilePath string
+	// ReadMeFormat is the format the README is rendered from:
+	// readmeFormatMarkdown, or readmeFormatText for any other file, which
+	// is shown as is.
+	ReadMeFormat string
+	// ReadMeRawURL is the URL of the unrendered README at its repository,
+	// or empty if the repository host does not serve raw files.
+	ReadMeRawURL    string
+	Redistributable bool
+	RepositoryURL   string
+	// ReadMeOutline is the headings of the README, in order, for its table
+	// of contents.
+	ReadMeOutline []*ReadmeHeading
+	// Origin is where the module proxy got the module version from, if
+	// known. It is only set on module pages.
+	Origin *internal.Origin
+	// Summary is the essentials of the module version. It is only set on
+	// module pages.
+	Summary *ModuleSummary
+	// ReleaseNotes is the section about this version of the module's
+	// changelog, if any. It is only set on module pages.
+	ReleaseNotes       template.HTML
+	ReleaseNotesSource string
+	// SimilarPackages are the packages of other modules most similar to the
+	// package. It is only set on package pages.
+	SimilarPackages []*similar.Package
+	// UsedBy are snippets of the source of the most imported packages of
+	// other modules that call the package. It is only set on package pages.
+	UsedBy []*internal.UsageSnippet
 }
 
+// A ReadmeHeading is a heading of a README.
+type ReadmeHeading struct {
+	// Level is 1 for <h1>, 2 for <h2>, and so on.
+	Level int
+	Text  string
+	// ID is the id of the heading element, for links to it. It depends only
+	// on the text of the heading and of the ones before it, so links stay
+	// valid across versions of the README.
+	ID string
+}
+
+// Prefixes of the ids of headings in rendered markdown, so that they do not
+// clash with the ids of the page, like "pkg-overview".
+const (
+	readmeIDPrefix       = "readme-"
+	releaseNotesIDPrefix = "release-notes-"
+)
+
 // versionedLinks says whether the constructed URLs should have versions.
 // constructOverviewDetails uses the given version to construct an OverviewDetails.
 func constructOverviewDetails(ctx context.Context, mi *internal.ModuleInfo, readme *internal.Readme, isRedistributable bool, versionedLinks bool) *OverviewDetails {
@@ -50,10 +99,10 @@ func constructOverviewDetails(ctx context.Context, mi *internal.ModuleInfo, read
 		ModuleURL:       constructModuleURL(mi.ModulePath, lv),
 		RepositoryURL:   mi.SourceInfo.RepoURL(),
 		Redistributable: isRedistributable,
+		Origin:          mi.Origin,
 	}
 	if overview.Redistributable && readme != nil {
-		overview.ReadMeSource = fileSource(mi.ModulePath, mi.Version, readme.Filepath)
-		overview.ReadMe = readmeHTML(ctx, mi, readme)
+		addReadme(ctx, overview, mi, readme)
 	}
 	return overview
 }
@@ -85,12 +134,21 @@ func fetchPackageOverviewDetailsNew(ctx context.Context, vdir *internal.Versione
 		PackageSourceURL: vdir.SourceInfo.DirectoryURL(packageSubdir(vdir.Path, vdir.ModulePath)),
 	}
 	if overview.Redistributable && vdir.Readme != nil {
-		overview.ReadMeSource = fileSource(vdir.ModulePath, vdir.Version, vdir.Readme.Filepath)
-		overview.ReadMe = readmeHTML(ctx, &vdir.ModuleInfo, vdir.Readme)
+		addReadme(ctx, overview, &vdir.ModuleInfo, vdir.Readme)
 	}
 	return overview
 }
 
+// addReadme sets the README fields of overview from readme, which is in the
+// module mi.
+func addReadme(ctx context.Context, overview *OverviewDetails, mi *internal.ModuleInfo, readme *internal.Readme) {
+	overview.ReadMeSource = fileSource(mi.ModulePath, mi.Version, readme.Filepath)
+	overview.ReadMeFilePath = readme.Filepath
+	overview.ReadMeFormat = readmeFormat(readme.Filepath)
+	overview.ReadMeRawURL = mi.SourceInfo.RawURL(readme.Filepath)
+	overview.ReadMe, overview.ReadMeOutline = renderMarkdown(ctx, mi, readme, readmeIDPrefix)
+}
+
 // packageSubdir returns the subdirectory of the package relative to its module.
 func packageSubdir(pkgPath, modulePath string) string {
 	switch {
@@ -107,11 +165,18 @@ func packageSubdir(pkgPath, modulePath string) string {
 // a template.HTML. If readmeFilePath indicates that this is a markdown file,
 // it will also render the markdown contents using blackfriday.
 func readmeHTML(ctx context.Context, mi *internal.ModuleInfo, readme *internal.Readme) template.HTML {
+	h, _ := renderMarkdown(ctx, mi, readme, readmeIDPrefix)
+	return h
+}
+
+// renderMarkdown is like readmeHTML, but also returns the headings of the
+// README. Their ids start with idPrefix.
+func renderMarkdown(ctx context.Context, mi *internal.ModuleInfo, readme *internal.Readme, idPrefix string) (template.HTML, []*ReadmeHeading) {
 	if readme == nil {
-		return ""
+		return "", nil
 	}
-	if !isMarkdown(readme.Filepath) {
-		return template.HTML(fmt.Sprintf(`<pre class="readme">%s</pre>`, html.EscapeString(string(readme.Contents))))
+	if readmeFormat(readme.Filepath) != readmeFormatMarkdown {
+		return template.HTML(fmt.Sprintf(`<pre class="readme">%s</pre>`, html.EscapeString(string(readme.Contents)))), nil
 	}
 
 	// bluemonday.UGCPolicy allows a broad selection of HTML elements and
@@ -134,9 +199,21 @@ func readmeHTML(ctx context.Context, mi *internal.ModuleInfo, readme *internal.R
 	// Render HTML similar to blackfriday.Run(), but here we implement a custom
 	// Walk function in order to modify image paths in the rendered HTML.
 	b := &bytes.Buffer{}
+	var headings []*ReadmeHeading
+	ids := map[string]bool{}
 	rootNode := parser.Parse([]byte(readme.Contents))
 	rootNode.Walk(func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
 		switch node.Type {
+		case blackfriday.Heading:
+			if entering {
+				text := headingText(node)
+				node.HeadingID = uniqueHeadingID(ids, idPrefix+headingSlug(text))
+				headings = append(headings, &ReadmeHeading{
+					Level: node.HeadingData.Level,
+					Text:  text,
+					ID:    node.HeadingID,
+				})
+			}
 		case blackfriday.Image, blackfriday.Link:
 			useRaw := node.Type == blackfriday.Image
 			if d := translateRelativeLink(string(node.LinkData.Destination), mi, useRaw, readme); d != "" {
@@ -154,7 +231,65 @@ func readmeHTML(ctx context.Context, mi *internal.ModuleInfo, readme *internal.R
 		}
 		return renderer.RenderNode(b, node, entering)
 	})
-	return template.HTML(p.SanitizeReader(b).String())
+	return template.HTML(p.SanitizeReader(b).String()), headings
+}
+
+// headingText returns the text of the markdown heading node, without its
+// formatting.
+func headingText(heading *blackfriday.Node) string {
+	var b strings.Builder
+	heading.Walk(func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		if entering && (node.Type == blackfriday.Text || node.Type == blackfriday.Code) {
+			b.Write(node.Literal)
+		}
+		return blackfriday.GoToNext
+	})
+	return strings.TrimSpace(b.String())
+}
+
+// headingSlug returns the id of a heading with the given text, as on GitHub:
+// the text in lower case, with spaces replaced by hyphens, and punctuation
+// removed.
+func headingSlug(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	if b.Len() == 0 {
+		return "section"
+	}
+	return b.String()
+}
+
+// uniqueHeadingID returns id, or if it is in ids, id with the first suffix
+// "-1", "-2" and so on that is not, and adds it to ids.
+func uniqueHeadingID(ids map[string]bool, id string) string {
+	u := id
+	for i := 1; ids[u]; i++ {
+		u = fmt.Sprintf("%s-%d", id, i)
+	}
+	ids[u] = true
+	return u
+}
+
+// Formats of READMEs.
+const (
+	readmeFormatMarkdown = "markdown"
+	readmeFormatText     = "text"
+)
+
+// readmeFormat returns the format of the README file filename. READMEs that
+// are not markdown, like README.rst, are shown as plain text.
+func readmeFormat(filename string) string {
+	if isMarkdown(filename) {
+		return readmeFormatMarkdown
+	}
+	return readmeFormatText
 }
 
 // isMarkdown reports whether filename says that the file contains markdown.
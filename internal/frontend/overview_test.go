@@ -7,6 +7,7 @@ package frontend
 import (
 	"context"
 	"html/template"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -34,6 +35,9 @@ func TestFetchOverviewDetails(t *testing.T) {
 			RepositoryURL:   sample.RepositoryURL,
 			ReadMe:          template.HTML("<p>readme</p>\n"),
 			ReadMeSource:    "github.com/valid_module_name@v1.0.0/README.md",
+			ReadMeFilePath:  "README.md",
+			ReadMeFormat:    readmeFormatMarkdown,
+			ReadMeRawURL:    "https://raw.githubusercontent.com/valid_module_name/v1.0.0/README.md",
 			ModuleURL:       "/mod/github.com/valid_module_name@v1.0.0",
 			Redistributable: true,
 		},
@@ -80,6 +84,9 @@ func TestConstructPackageOverviewDetailsNew(t *testing.T) {
 				PackageSourceURL: "https://github.com/u/m/tree/v1.2.3/p",
 				ReadMe:           template.HTML("<p>readme</p>\n"),
 				ReadMeSource:     "github.com/u/m@v1.2.3/README.md",
+				ReadMeFilePath:   "README.md",
+				ReadMeFormat:     readmeFormatMarkdown,
+				ReadMeRawURL:     "https://raw.githubusercontent.com/u/m/v1.2.3/README.md",
 				Redistributable:  true,
 			},
 		},
@@ -104,6 +111,36 @@ func TestConstructPackageOverviewDetailsNew(t *testing.T) {
 				PackageSourceURL: "https://github.com/u/m/tree/v1.2.3/p",
 				ReadMe:           template.HTML("<p>readme</p>\n"),
 				ReadMeSource:     "github.com/u/m@v1.2.3/README.md",
+				ReadMeFilePath:   "README.md",
+				ReadMeFormat:     readmeFormatMarkdown,
+				ReadMeRawURL:     "https://raw.githubusercontent.com/u/m/v1.2.3/README.md",
+				Redistributable:  true,
+			},
+		},
+		{
+			name: "plain text",
+			vdir: &internal.VersionedDirectory{
+				DirectoryNew: internal.DirectoryNew{
+					Path:              "github.com/u/m/p",
+					IsRedistributable: true,
+					Readme: &internal.Readme{
+						Filepath: "p/README.rst",
+						Contents: "Use <p>\n==========",
+					},
+				},
+				ModuleInfo: *sample.ModuleInfo("github.com/u/m", "v1.2.3"),
+			},
+			versionedLinks: true,
+			want: &OverviewDetails{
+				ModulePath:       "github.com/u/m",
+				ModuleURL:        "/mod/github.com/u/m@v1.2.3",
+				RepositoryURL:    "https://github.com/u/m",
+				PackageSourceURL: "https://github.com/u/m/tree/v1.2.3/p",
+				ReadMe:           template.HTML("<pre class=\"readme\">Use &lt;p&gt;\n==========</pre>"),
+				ReadMeSource:     "github.com/u/m@v1.2.3/p/README.rst",
+				ReadMeFilePath:   "p/README.rst",
+				ReadMeFormat:     readmeFormatText,
+				ReadMeRawURL:     "https://raw.githubusercontent.com/u/m/v1.2.3/p/README.rst",
 				Redistributable:  true,
 			},
 		},
@@ -273,7 +310,7 @@ func TestReadmeHTML(t *testing.T) {
 				Filepath: "README.md",
 				Contents: "<img src=\"resources/logoSmall.png\" />\n\n# Heading\n",
 			},
-			want: template.HTML("<p><img src=\"https://raw.githubusercontent.com/pdfcpu/pdfcpu/v0.3.3/resources/logoSmall.png\"/></p>\n\n<h1 id=\"heading\">Heading</h1>\n"),
+			want: template.HTML("<p><img src=\"https://raw.githubusercontent.com/pdfcpu/pdfcpu/v0.3.3/resources/logoSmall.png\"/></p>\n\n<h1 id=\"readme-heading\">Heading</h1>\n"),
 		},
 		{
 			name: "image link in embedded HTML with surrounding p tag",
@@ -286,7 +323,7 @@ func TestReadmeHTML(t *testing.T) {
 				Filepath: "README.md",
 				Contents: "<p align=\"center\"><img src=\"foo.png\" /></p>\n\n# Heading",
 			},
-			want: template.HTML("<p align=\"center\"><img src=\"https://raw.githubusercontent.com/some/repo/v1.2.3/foo.png\"/></p>\n\n<h1 id=\"heading\">Heading</h1>\n"),
+			want: template.HTML("<p align=\"center\"><img src=\"https://raw.githubusercontent.com/some/repo/v1.2.3/foo.png\"/></p>\n\n<h1 id=\"readme-heading\">Heading</h1>\n"),
 		},
 		{
 			name: "image link in embedded HTML with surrounding div",
@@ -299,7 +336,7 @@ func TestReadmeHTML(t *testing.T) {
 				Filepath: "README.md",
 				Contents: "<div align=\"center\"><img src=\"foo.png\" /></div>\n\n# Heading",
 			},
-			want: template.HTML("<div align=\"center\"><img src=\"https://raw.githubusercontent.com/some/repo/v1.2.3/foo.png\"/></div>\n\n<h1 id=\"heading\">Heading</h1>\n"),
+			want: template.HTML("<div align=\"center\"><img src=\"https://raw.githubusercontent.com/some/repo/v1.2.3/foo.png\"/></div>\n\n<h1 id=\"readme-heading\">Heading</h1>\n"),
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -311,6 +348,47 @@ func TestReadmeHTML(t *testing.T) {
 	}
 }
 
+func TestReadmeOutline(t *testing.T) {
+	ctx := context.Background()
+	mi := &internal.ModuleInfo{
+		Version:     "v1.2.3",
+		VersionType: version.TypeRelease,
+		SourceInfo:  source.NewGitHubInfo("https://github.com/some/repo", "", "v1.2.3"),
+	}
+	readme := &internal.Readme{
+		Filepath: "README.md",
+		Contents: "# Project\n\n## Getting started\n\n### Using `go get`\n\n## Getting started\n\nSetext heading\n---\n\n## ¿Qué?\n",
+	}
+	wantHeadings := []*ReadmeHeading{
+		{Level: 1, Text: "Project", ID: "readme-project"},
+		{Level: 2, Text: "Getting started", ID: "readme-getting-started"},
+		{Level: 3, Text: "Using go get", ID: "readme-using-go-get"},
+		{Level: 2, Text: "Getting started", ID: "readme-getting-started-1"},
+		{Level: 2, Text: "Setext heading", ID: "readme-setext-heading"},
+		{Level: 2, Text: "¿Qué?", ID: "readme-qué"},
+	}
+	got, headings := renderMarkdown(ctx, mi, readme, readmeIDPrefix)
+	if diff := cmp.Diff(wantHeadings, headings); diff != "" {
+		t.Errorf("headings mismatch (-want +got):\n%s", diff)
+	}
+	for _, h := range wantHeadings {
+		if want := `id="` + h.ID + `"`; !strings.Contains(string(got), want) {
+			t.Errorf("rendered README does not contain %s", want)
+		}
+	}
+
+	// Release notes have their own ids, so that they do not clash with the
+	// README on module pages.
+	_, headings = renderMarkdown(ctx, mi, readme, releaseNotesIDPrefix)
+	if got, want := headings[0].ID, "release-notes-project"; got != want {
+		t.Errorf("release notes heading id = %q, want %q", got, want)
+	}
+
+	if _, headings := renderMarkdown(ctx, mi, &internal.Readme{Filepath: "README", Contents: "# Not markdown"}, readmeIDPrefix); headings != nil {
+		t.Errorf("plain text README: got headings %v, want none", headings)
+	}
+}
+
 func TestPackageSubdir(t *testing.T) {
 	for _, test := range []struct {
 		pkgPath, modulePath string
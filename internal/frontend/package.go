@@ -15,6 +15,7 @@ import (
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/prefs"
 	"golang.org/x/pkgsite/internal/stdlib"
 )
 
@@ -24,6 +25,17 @@ func (s *Server) handlePackageDetailsRedirect(w http.ResponseWriter, r *http.Req
 	http.Redirect(w, r, urlPath, http.StatusMovedPermanently)
 }
 
+// handleXRedirect redirects short forms of golang.org/x paths, such as
+// "/x/net/context", to the corresponding "/golang.org/x/net/context" page.
+// Any version and query are preserved.
+func (s *Server) handleXRedirect(w http.ResponseWriter, r *http.Request) {
+	urlPath := "/golang.org" + r.URL.Path
+	if r.URL.RawQuery != "" {
+		urlPath += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, urlPath, http.StatusMovedPermanently)
+}
+
 // servePackagePage serves details pages for the package with import path
 // pkgPath, in the module specified by modulePath and version.
 func (s *Server) servePackagePage(w http.ResponseWriter, r *http.Request, pkgPath, modulePath, version string) (err error) {
@@ -36,7 +48,11 @@ func (s *Server) servePackagePage(w http.ResponseWriter, r *http.Request, pkgPat
 	//   3. If there is another version that contains this package path: serve a
 	//      404 and suggest these versions.
 	//   4. Just serve a 404
-	pkg, err := s.ds.GetPackage(ctx, pkgPath, modulePath, version)
+	fields := packageTabFields(r.FormValue("tab"))
+	if docTextFormat(r) != "" || wantsDocPrint(r) {
+		fields = internal.WithDocumentationHTML
+	}
+	pkg, err := s.ds.GetPackage(ctx, pkgPath, modulePath, version, fields)
 	if err == nil {
 		return s.servePackagePageWithPackage(ctx, w, r, pkg, version)
 	}
@@ -65,7 +81,7 @@ func (s *Server) servePackagePage(w http.ResponseWriter, r *http.Request, pkgPat
 		// whatever response we resolve below might be inconsistent or misleading.
 		return fmt.Errorf("checking for directory: %v", err)
 	}
-	_, err = s.ds.GetPackage(ctx, pkgPath, modulePath, internal.LatestVersion)
+	_, err = s.ds.GetPackage(ctx, pkgPath, modulePath, internal.LatestVersion, internal.MinimalFields)
 	if err == nil {
 		return pathFoundAtLatestError(ctx, "package", pkgPath, version)
 	}
@@ -88,23 +104,35 @@ func (s *Server) servePackagePageWithPackage(ctx context.Context, w http.Respons
 			derrors.Wrap(&err, "servePackagePageWithPackage(w, r, %q, %q, %q)", pkg.Path, pkg.ModulePath, requestedVersion)
 		}
 	}()
-	pkgHeader, err := createPackage(&pkg.LegacyPackage, &pkg.ModuleInfo, requestedVersion == internal.LatestVersion)
+	if format := docTextFormat(r); format != "" {
+		serveDocText(w, format, pkg.Name, pkg.Path, pkg.DocumentationHTML, pkg.LegacyPackage.IsRedistributable)
+		return nil
+	}
+	pkgHeader, err := createPackage(ctx, &pkg.LegacyPackage, &pkg.ModuleInfo, requestedVersion == internal.LatestVersion)
 	if err != nil {
 		return fmt.Errorf("creating package header for %s@%s: %v", pkg.Path, pkg.Version, err)
 	}
+	if wantsDocPrint(r) {
+		var doc *DocumentationDetails
+		if pkg.LegacyPackage.IsRedistributable {
+			doc = fetchDocumentationDetails(ctx, pkg)
+		}
+		tagModuleVersion(ctx, w, pkg.ModulePath, pkg.Version)
+		s.serveDocPrint(w, r, packageTitle(&pkg.LegacyPackage), pkgHeader, doc)
+		return nil
+	}
 
 	tab := r.FormValue("tab")
 	settings, ok := packageTabLookup[tab]
 	if !ok {
-		var tab string
-		if pkg.LegacyPackage.IsRedistributable {
-			tab = "doc"
-		} else {
-			tab = "overview"
-		}
+		tab := defaultPackageTab(ctx, pkg.LegacyPackage.IsRedistributable)
 		http.Redirect(w, r, fmt.Sprintf(r.URL.Path+"?tab=%s", tab), http.StatusFound)
 		return nil
 	}
+	state := s.moduleState(ctx, pkg.ModulePath, pkg.SourceInfo.RepoURL(), pkg.Path)
+	if s.serveNotModified(ctx, w, r, pkg.Path, pkg.ModulePath, pkg.Version, "pkg", state) {
+		return nil
+	}
 	canShowDetails := pkg.LegacyPackage.IsRedistributable || settings.AlwaysShowDetails
 
 	var details interface{}
@@ -126,8 +154,13 @@ func (s *Server) servePackagePageWithPackage(ctx context.Context, w http.Respons
 		CanShowDetails: canShowDetails,
 		Tabs:           packageTabSettings,
 		PageType:       "pkg",
+		LicenseChange:  s.licenseChange(ctx, pkg.ModulePath, pkg.Version),
+		Quality:        s.qualitySignals(ctx, pkg.Path, pkg.ModulePath, pkg.Version),
+		ModuleState:    state,
 	}
-	s.servePage(ctx, w, settings.TemplateName, page)
+	page.NoIndex = noIndex(r, requestedVersion, pkg.Version)
+	tagModuleVersion(ctx, w, pkg.ModulePath, pkg.Version)
+	s.serveDetailsPage(w, r, page)
 	return nil
 }
 
@@ -208,23 +241,39 @@ func (s *Server) stdlibPathForShortcut(ctx context.Context, shortcut string) (pa
 
 func (s *Server) servePackagePageWithVersionedDirectory(ctx context.Context,
 	w http.ResponseWriter, r *http.Request, vdir *internal.VersionedDirectory, requestedVersion string) error {
-	pkgHeader, err := createPackageNew(vdir, requestedVersion == internal.LatestVersion)
+	if format := docTextFormat(r); format != "" {
+		var docHTML string
+		if doc := vdir.Package.Documentation; doc != nil {
+			docHTML = doc.HTML
+		}
+		serveDocText(w, format, vdir.Package.Name, vdir.Path, docHTML, vdir.DirectoryNew.IsRedistributable)
+		return nil
+	}
+	pkgHeader, err := createPackageNew(ctx, vdir, requestedVersion == internal.LatestVersion)
 	if err != nil {
 		return fmt.Errorf("creating package header for %s@%s: %v", vdir.Path, vdir.Version, err)
 	}
+	if wantsDocPrint(r) {
+		var doc *DocumentationDetails
+		if vdir.DirectoryNew.IsRedistributable && vdir.Package.Documentation != nil {
+			doc = fetchDocumentationDetailsNew(ctx, vdir)
+		}
+		tagModuleVersion(ctx, w, vdir.ModulePath, vdir.Version)
+		s.serveDocPrint(w, r, packageTitleNew(vdir.Package), pkgHeader, doc)
+		return nil
+	}
 
 	tab := r.FormValue("tab")
 	settings, ok := packageTabLookup[tab]
 	if !ok {
-		var tab string
-		if vdir.DirectoryNew.IsRedistributable {
-			tab = "doc"
-		} else {
-			tab = "overview"
-		}
+		tab := defaultPackageTab(ctx, vdir.DirectoryNew.IsRedistributable)
 		http.Redirect(w, r, fmt.Sprintf(r.URL.Path+"?tab=%s", tab), http.StatusFound)
 		return nil
 	}
+	state := s.moduleState(ctx, vdir.ModulePath, vdir.SourceInfo.RepoURL(), vdir.Path)
+	if s.serveNotModified(ctx, w, r, vdir.Path, vdir.ModulePath, vdir.Version, "pkg", state) {
+		return nil
+	}
 	canShowDetails := vdir.DirectoryNew.IsRedistributable || settings.AlwaysShowDetails
 
 	var details interface{}
@@ -246,7 +295,27 @@ func (s *Server) servePackagePageWithVersionedDirectory(ctx context.Context,
 		CanShowDetails: canShowDetails,
 		Tabs:           packageTabSettings,
 		PageType:       "pkg",
+		LicenseChange:  s.licenseChange(ctx, vdir.ModulePath, vdir.Version),
+		Quality:        s.qualitySignals(ctx, vdir.Path, vdir.ModulePath, vdir.Version),
+		ModuleState:    state,
 	}
-	s.servePage(ctx, w, settings.TemplateName, page)
+	page.NoIndex = noIndex(r, requestedVersion, vdir.Version)
+	tagModuleVersion(ctx, w, vdir.ModulePath, vdir.Version)
+	s.serveDetailsPage(w, r, page)
 	return nil
 }
+
+// defaultPackageTab returns the tab that a package page opens on when its URL
+// has none: the tab the user prefers, if the page can show it, and otherwise
+// the documentation, or the overview if the package is not redistributable.
+func defaultPackageTab(ctx context.Context, isRedistributable bool) string {
+	if tab := prefs.FromContext(ctx).Tab; tab != "" {
+		if ts, ok := packageTabLookup[tab]; ok && (isRedistributable || ts.AlwaysShowDetails) {
+			return tab
+		}
+	}
+	if isRedistributable {
+		return "doc"
+	}
+	return "overview"
+}
@@ -6,6 +6,8 @@ package frontend
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"golang.org/x/pkgsite/internal"
@@ -44,3 +46,23 @@ func TestStdlibPathForShortcut(t *testing.T) {
 		}
 	}
 }
+
+func TestXRedirect(t *testing.T) {
+	var s Server
+	for _, test := range []struct {
+		url, want string
+	}{
+		{"/x/net/context", "/golang.org/x/net/context"},
+		{"/x/tools@v0.1.0/go/packages", "/golang.org/x/tools@v0.1.0/go/packages"},
+		{"/x/net/html?tab=doc", "/golang.org/x/net/html?tab=doc"},
+	} {
+		w := httptest.NewRecorder()
+		s.handleXRedirect(w, httptest.NewRequest("GET", test.url, nil))
+		if w.Code != http.StatusMovedPermanently {
+			t.Errorf("%s: got status %d, want %d", test.url, w.Code, http.StatusMovedPermanently)
+		}
+		if got := w.Header().Get("Location"); got != test.want {
+			t.Errorf("%s: got Location %q, want %q", test.url, got, test.want)
+		}
+	}
+}
@@ -5,10 +5,14 @@
 package frontend
 
 import (
+	"encoding/base64"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
+	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/log"
 )
 
@@ -18,6 +22,9 @@ import (
 // Given a sequence of results with offsets 0, 1, 2 ... (typically from a
 // database query), we paginate it by dividing it into numbered pages
 // 1, 2, 3, .... Each page except possibly the last has the same number of results.
+//
+// Pages are requested with an opaque cursor (see encodeCursor), and only the
+// pages holding the first maxResults results can be requested.
 type pagination struct {
 	baseURL     *url.URL // URL common to all pages
 	limit       int      // the maximum number of results on a page
@@ -32,10 +39,15 @@ type pagination struct {
 }
 
 // PageURL constructs a URL that displays the given page.
-// It adds a "page" query parameter to the base URL.
+// It adds a "cursor" query parameter to the base URL, except for the first
+// page.
 func (p pagination) PageURL(page int) string {
 	newQuery := p.baseURL.Query()
-	newQuery.Set("page", strconv.Itoa(page))
+	newQuery.Del("cursor")
+	newQuery.Del("start")
+	if o := offset(page, p.limit); o > 0 {
+		newQuery.Set("cursor", encodeCursor(o))
+	}
 	p.baseURL.RawQuery = newQuery.Encode()
 	return p.baseURL.String()
 }
@@ -43,8 +55,13 @@ func (p pagination) PageURL(page int) string {
 // newPagination constructs a pagination. Call it after some results have been
 // obtained.
 // resultCount is the number of results in the current page.
-// totalCount is the total number of results.
+// totalCount is the total number of results. Pages past params.maxResults are
+// not linked to.
 func newPagination(params paginationParams, resultCount, totalCount int) pagination {
+	pageableCount := totalCount
+	if params.maxResults > 0 && pageableCount > params.maxResults {
+		pageableCount = params.maxResults
+	}
 	return pagination{
 		baseURL:     params.baseURL,
 		TotalCount:  totalCount,
@@ -53,16 +70,17 @@ func newPagination(params paginationParams, resultCount, totalCount int) paginat
 		limit:       params.limit,
 		Page:        params.page,
 		PrevPage:    prev(params.page),
-		NextPage:    next(params.page, params.limit, totalCount),
-		Pages:       pagesToLink(params.page, numPages(params.limit, totalCount), defaultNumPagesToLink),
+		NextPage:    next(params.page, params.limit, pageableCount),
+		Pages:       pagesToLink(params.page, numPages(params.limit, pageableCount), defaultNumPagesToLink),
 	}
 }
 
 // paginationParams holds pagination parameters extracted from the request.
 type paginationParams struct {
-	baseURL *url.URL
-	page    int // the number of the page to display
-	limit   int // the maximum number of results to display on the page
+	baseURL    *url.URL
+	page       int // the number of the page to display
+	limit      int // the maximum number of results to display on the page
+	maxResults int // the number of results that can be paged through, or 0 for no limit
 }
 
 // offset returns the offset of the first result on the page.
@@ -70,8 +88,18 @@ func (p paginationParams) offset() int {
 	return offset(p.page, p.limit)
 }
 
-// newPaginationParams extracts pagination params from the request.
-func newPaginationParams(r *http.Request, defaultLimit int) paginationParams {
+// newPaginationParams extracts pagination params from the request. The limit
+// is at most maxResults, and the cursor must be for a page within the first
+// maxResults results. It returns an error wrapping derrors.InvalidArgument if
+// the cursor is malformed or past that page.
+//
+// Instead of a cursor, the request may have a "start" parameter, the 1-based
+// index of the first result, as sent by browsers following the OpenSearch
+// description (see opensearch.go). Pages always start at a multiple of the
+// limit, so start is rounded down to one.
+func newPaginationParams(r *http.Request, defaultLimit, maxResults int) (_ paginationParams, err error) {
+	defer derrors.Wrap(&err, "newPaginationParams(r, %d, %d)", defaultLimit, maxResults)
+
 	positiveParam := func(key string, dflt int) (val int) {
 		var err error
 		if a := r.FormValue(key); a != "" {
@@ -85,11 +113,56 @@ func newPaginationParams(r *http.Request, defaultLimit int) paginationParams {
 		}
 		return val
 	}
+	limit := positiveParam("limit", defaultLimit)
+	if limit > maxResults {
+		limit = maxResults
+	}
+	off := 0
+	if c := r.FormValue("cursor"); c != "" {
+		off, err = decodeCursor(c)
+		if err != nil {
+			return paginationParams{}, err
+		}
+	} else if a := r.FormValue("start"); a != "" {
+		start, err := strconv.Atoi(a)
+		if err != nil || start < 1 {
+			return paginationParams{}, fmt.Errorf("bad start %q: %w", a, derrors.InvalidArgument)
+		}
+		off = (start - 1) / limit * limit
+	}
+	if off >= maxResults {
+		return paginationParams{}, fmt.Errorf("cursor is past the first %d results: %w", maxResults, derrors.InvalidArgument)
+	}
 	return paginationParams{
-		baseURL: r.URL,
-		page:    positiveParam("page", 1),
-		limit:   positiveParam("limit", defaultLimit),
+		baseURL:    r.URL,
+		page:       off/limit + 1,
+		limit:      limit,
+		maxResults: maxResults,
+	}, nil
+}
+
+// cursorPrefix versions the cursor encoding.
+const cursorPrefix = "o1:"
+
+// encodeCursor returns the cursor for the page that starts at offset. Cursors
+// are opaque to users, so that the encoding can change, for example to
+// identify the last result of the previous page instead of an offset.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset of the cursor c. It returns an error
+// wrapping derrors.InvalidArgument if c was not returned by encodeCursor.
+func decodeCursor(c string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil || !strings.HasPrefix(string(b), cursorPrefix) {
+		return 0, fmt.Errorf("malformed cursor %q: %w", c, derrors.InvalidArgument)
+	}
+	off, err := strconv.Atoi(strings.TrimPrefix(string(b), cursorPrefix))
+	if err != nil || off < 0 {
+		return 0, fmt.Errorf("malformed cursor %q: %w", c, derrors.InvalidArgument)
 	}
+	return off, nil
 }
 
 const defaultNumPagesToLink = 5
@@ -5,9 +5,13 @@
 package frontend
 
 import (
+	"encoding/base64"
+	"errors"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/derrors"
 )
 
 func TestPagination(t *testing.T) {
@@ -197,3 +201,75 @@ func TestPagesToDisplay(t *testing.T) {
 		})
 	}
 }
+
+func TestNewPaginationParams(t *testing.T) {
+	for _, test := range []struct {
+		query             string
+		wantPage, wantLim int
+		wantErr           bool
+	}{
+		{"", 1, 10, false},
+		{"limit=20", 1, 20, false},
+		{"limit=500", 1, 100, false},
+		{"cursor=" + encodeCursor(20), 3, 10, false},
+		{"cursor=" + encodeCursor(90), 10, 10, false},
+		{"cursor=" + encodeCursor(100), 0, 0, true},
+		{"cursor=" + encodeCursor(100000), 0, 0, true},
+		{"cursor=20", 0, 0, true},
+		{"cursor=" + base64.RawURLEncoding.EncodeToString([]byte("o1:-10")), 0, 0, true},
+		{"start=&limit=", 1, 10, false},
+		{"start=1", 1, 10, false},
+		{"start=25", 3, 10, false},
+		{"start=21&limit=20", 2, 20, false},
+		{"start=101", 0, 0, true},
+		{"start=0", 0, 0, true},
+		{"start=x", 0, 0, true},
+	} {
+		r := httptest.NewRequest("GET", "/search?q=foo&"+test.query, nil)
+		got, err := newPaginationParams(r, 10, 100)
+		if (err != nil) != test.wantErr {
+			t.Fatalf("%q: got error %v, want error: %t", test.query, err, test.wantErr)
+		}
+		if err != nil {
+			if !errors.Is(err, derrors.InvalidArgument) {
+				t.Errorf("%q: got error %v, want InvalidArgument", test.query, err)
+			}
+			continue
+		}
+		if got.page != test.wantPage || got.limit != test.wantLim {
+			t.Errorf("%q: got page %d, limit %d; want %d, %d", test.query, got.page, got.limit, test.wantPage, test.wantLim)
+		}
+	}
+}
+
+func TestPageURL(t *testing.T) {
+	r := httptest.NewRequest("GET", "/search?q=foo&cursor="+encodeCursor(10), nil)
+	params, err := newPaginationParams(r, 10, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newPagination(params, 10, 5000)
+	if got, want := p.PageURL(1), "/search?q=foo"; got != want {
+		t.Errorf("PageURL(1) = %q, want %q", got, want)
+	}
+	if got, want := p.PageURL(3), "/search?cursor="+encodeCursor(20)+"&q=foo"; got != want {
+		t.Errorf("PageURL(3) = %q, want %q", got, want)
+	}
+	if p.TotalCount != 5000 {
+		t.Errorf("TotalCount = %d, want 5000", p.TotalCount)
+	}
+
+	// Only the first 100 results can be paged through.
+	r = httptest.NewRequest("GET", "/search?q=foo&cursor="+encodeCursor(90), nil)
+	params, err = newPaginationParams(r, 10, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = newPagination(params, 10, 5000)
+	if p.NextPage != 0 {
+		t.Errorf("NextPage = %d, want 0", p.NextPage)
+	}
+	if diff := cmp.Diff([]int{6, 7, 8, 9, 10}, p.Pages); diff != "" {
+		t.Errorf("Pages mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// QualitySignals are facts about a package version that hint at its
+// quality, shown in the header of its page and served by the quality API
+// endpoint.
+type QualitySignals struct {
+	HasTests    bool `json:"hasTests"`
+	HasExamples bool `json:"hasExamples"`
+	// StableRelease reports whether the module of the package has a release
+	// with major version 1 or higher that is not a prerelease.
+	StableRelease bool `json:"stableRelease"`
+	NumExported   int  `json:"numExported"`
+	// DocumentedPercent is the percentage of the exported identifiers that
+	// have a doc comment, rounded down. It is 100 if there are none.
+	DocumentedPercent int `json:"documentedPercent"`
+}
+
+// serveQuality handles requests for /api/v1/quality/<path>[@<version>],
+// returning the QualitySignals of the package.
+func (s *Server) serveQuality(w http.ResponseWriter, r *http.Request) (_ interface{}, err error) {
+	defer derrors.Wrap(&err, "serveQuality(%q)", r.URL.Path)
+
+	ctx := r.Context()
+	fullPath, modulePath, version, err := parseAPIPath(strings.TrimPrefix(r.URL.Path, apiPrefix+"quality"))
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := s.ds.GetPackage(ctx, fullPath, modulePath, version, internal.MinimalFields)
+	if err != nil {
+		return nil, err
+	}
+	return fetchQualitySignals(ctx, s.ds, pkg.Path, pkg.ModulePath, pkg.Version)
+}
+
+// qualitySignals returns the QualitySignals of the package at pkgPath in
+// modulePath@version, for the header of its page. A header without them is
+// better than a failed page, so it returns nil if they are not known, and
+// only logs errors.
+func (s *Server) qualitySignals(ctx context.Context, pkgPath, modulePath, version string) *QualitySignals {
+	qs, err := fetchQualitySignals(ctx, s.ds, pkgPath, modulePath, version)
+	if err != nil {
+		if !errors.Is(err, derrors.NotFound) {
+			log.Error(ctx, err)
+		}
+		return nil
+	}
+	return qs
+}
+
+// fetchQualitySignals returns the QualitySignals of the package at pkgPath
+// in modulePath@version. It returns an error that wraps derrors.NotFound if
+// they are not known.
+func fetchQualitySignals(ctx context.Context, ds internal.DataSource, pkgPath, modulePath, version string) (_ *QualitySignals, err error) {
+	defer derrors.Wrap(&err, "fetchQualitySignals(%q, %q, %q)", pkgPath, modulePath, version)
+
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		// Only the database stores the results of the analysis at fetch time.
+		return nil, fmt.Errorf("quality signals: %w", derrors.NotFound)
+	}
+	q, err := db.GetPackageQuality(ctx, pkgPath, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := ds.GetTaggedVersionsForModule(ctx, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	return qualitySignals(q, modulePath, versions), nil
+}
+
+// qualitySignals returns the QualitySignals of a package of modulePath with
+// quality q, given the tagged versions of the module.
+func qualitySignals(q *internal.PackageQuality, modulePath string, versions []*internal.LegacyModuleInfo) *QualitySignals {
+	qs := &QualitySignals{
+		HasTests:          q.HasTests,
+		HasExamples:       q.HasExamples,
+		NumExported:       q.NumExported,
+		DocumentedPercent: 100,
+	}
+	if q.NumExported > 0 {
+		qs.DocumentedPercent = 100 * q.NumDocumented / q.NumExported
+	}
+	for _, v := range versions {
+		// The versions of other major versions in the series are returned
+		// too.
+		if v.ModulePath == modulePath && semver.Major(v.Version) != "v0" && semver.Prerelease(v.Version) == "" {
+			qs.StableRelease = true
+			break
+		}
+	}
+	return qs
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+)
+
+func TestQualitySignals(t *testing.T) {
+	const modulePath = "example.com/m"
+	versions := func(vs ...string) []*internal.LegacyModuleInfo {
+		var mis []*internal.LegacyModuleInfo
+		for _, v := range vs {
+			mi := &internal.LegacyModuleInfo{}
+			mi.ModulePath = modulePath
+			mi.Version = v
+			mis = append(mis, mi)
+		}
+		return mis
+	}
+	v2 := versions("v2.0.0")
+	v2[0].ModulePath = modulePath + "/v2"
+
+	for _, test := range []struct {
+		name     string
+		q        *internal.PackageQuality
+		versions []*internal.LegacyModuleInfo
+		want     *QualitySignals
+	}{
+		{
+			name:     "stable",
+			q:        &internal.PackageQuality{HasTests: true, HasExamples: true, NumExported: 3, NumDocumented: 2},
+			versions: versions("v1.1.0", "v1.0.0", "v0.1.0"),
+			want:     &QualitySignals{HasTests: true, HasExamples: true, StableRelease: true, NumExported: 3, DocumentedPercent: 66},
+		},
+		{
+			name:     "prerelease",
+			q:        &internal.PackageQuality{NumExported: 4, NumDocumented: 4},
+			versions: versions("v1.0.0-rc.1", "v0.3.0"),
+			want:     &QualitySignals{NumExported: 4, DocumentedPercent: 100},
+		},
+		{
+			name:     "other major version",
+			q:        &internal.PackageQuality{},
+			versions: v2,
+			want:     &QualitySignals{DocumentedPercent: 100},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := qualitySignals(test.q, modulePath, test.versions)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/complete"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// A Quicklink is a result of the quicklinks API endpoint, for the command
+// palette of the site.
+type Quicklink struct {
+	// Kind is one of "symbol", "tab" and "package".
+	Kind string `json:"kind"`
+	// Title is the text shown for the result, like "Client.Do" or
+	// "Imported By".
+	Title string `json:"title"`
+	// Detail is the kind of a symbol, or the module path of a package.
+	Detail string `json:"detail,omitempty"`
+	// URL is the path of the result on the site.
+	URL string `json:"url"`
+
+	score int
+}
+
+const (
+	// maxQuicklinks is the number of results of the quicklinks endpoint.
+	maxQuicklinks = 10
+
+	// maxQuicklinkPackages is the number of packages among them.
+	maxQuicklinkPackages = 5
+)
+
+// Scores of quicklinks, by how well their title matches the query.
+const (
+	quicklinkContains = iota + 1
+	quicklinkPrefix
+	quicklinkExact
+)
+
+// serveQuicklinks handles requests for /api/v1/quicklinks?q=<input>[&pkg=<path>[@<version>]],
+// returning the symbols and tabs of the package pkg that the user is on, and
+// the packages completing q, best matches first. Within a score, symbols come
+// before tabs, and tabs before packages.
+func (s *Server) serveQuicklinks(w http.ResponseWriter, r *http.Request) (_ interface{}, err error) {
+	defer derrors.Wrap(&err, "serveQuicklinks(%q, %q)", r.FormValue("q"), r.FormValue("pkg"))
+
+	ctx := r.Context()
+	q := strings.TrimSpace(r.FormValue("q"))
+	links := []*Quicklink{}
+	if q == "" {
+		return links, nil
+	}
+	if p := r.FormValue("pkg"); p != "" {
+		pkgLinks, err := s.packageQuicklinks(ctx, p, q)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, pkgLinks...)
+	}
+	pkgs, err := s.completePackages(ctx, q, maxQuicklinkPackages)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range pkgs {
+		links = append(links, &Quicklink{
+			Kind:   "package",
+			Title:  c.PackagePath,
+			Detail: c.ModulePath,
+			URL:    "/" + c.PackagePath,
+			score:  quicklinkScore(q, c.PackagePath, path.Base(c.PackagePath)),
+		})
+	}
+	sort.SliceStable(links, func(i, j int) bool {
+		return links[i].score > links[j].score
+	})
+	if len(links) > maxQuicklinks {
+		links = links[:maxQuicklinks]
+	}
+	return links, nil
+}
+
+// packageQuicklinks returns the quicklinks to the symbols and tabs of the
+// package at pkgPath, in the form path[@version], that match q.
+func (s *Server) packageQuicklinks(ctx context.Context, pkgPath, q string) (_ []*Quicklink, err error) {
+	fullPath, modulePath, version, err := parseAPIPath("/" + pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := s.ds.GetPackage(ctx, fullPath, modulePath, version, internal.MinimalFields)
+	if err != nil {
+		return nil, err
+	}
+	pkgURL := constructPackageURL(pkg.Path, pkg.ModulePath, linkVersion(pkg.Version, pkg.ModulePath))
+
+	symbols, err := s.packageSymbols(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+	var links []*Quicklink
+	for _, sym := range symbols {
+		// Match methods and fields by their own name too, so that "do"
+		// finds Client.Do.
+		name := sym.Name
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		if score := quicklinkScore(q, sym.Name, name); score > 0 {
+			links = append(links, &Quicklink{
+				Kind:   "symbol",
+				Title:  sym.Name,
+				Detail: sym.Kind,
+				URL:    pkgURL + "?tab=doc#" + sym.Anchor,
+				score:  score,
+			})
+		}
+	}
+	for _, t := range packageTabSettings {
+		if score := quicklinkScore(q, t.DisplayName, t.Name); score > 0 {
+			links = append(links, &Quicklink{
+				Kind:  "tab",
+				Title: t.DisplayName,
+				URL:   pkgURL + "?tab=" + t.Name,
+				score: score,
+			})
+		}
+	}
+	return links, nil
+}
+
+// completePackages returns at most maxResults packages that complete q, and
+// that the user may see.
+func (s *Server) completePackages(ctx context.Context, q string, maxResults int) ([]*complete.Completion, error) {
+	if s.cmplClient == nil {
+		return nil, nil
+	}
+	completions, err := doCompletion(ctx, s.cmplClient, strings.ToLower(q), maxResults)
+	if err != nil {
+		return nil, err
+	}
+	if s.acl == nil {
+		return completions, nil
+	}
+	var allowed []*complete.Completion
+	for _, c := range completions {
+		if s.acl.Allowed(ctx, c.ModulePath) {
+			allowed = append(allowed, c)
+		}
+	}
+	return allowed, nil
+}
+
+// quicklinkScore returns how well q matches the best of names, ignoring case,
+// or 0 if it matches none of them.
+func quicklinkScore(q string, names ...string) int {
+	q = strings.ToLower(q)
+	best := 0
+	for _, n := range names {
+		n = strings.ToLower(n)
+		score := 0
+		switch {
+		case n == q:
+			score = quicklinkExact
+		case strings.HasPrefix(n, q):
+			score = quicklinkPrefix
+		case strings.Contains(n, q):
+			score = quicklinkContains
+		}
+		if score > best {
+			best = score
+		}
+	}
+	return best
+}
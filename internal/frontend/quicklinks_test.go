@@ -0,0 +1,78 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestQuicklinkScore(t *testing.T) {
+	for _, test := range []struct {
+		q     string
+		names []string
+		want  int
+	}{
+		{"do", []string{"Client.Do", "Do"}, quicklinkExact},
+		{"cli", []string{"Client.Do", "Do"}, quicklinkPrefix},
+		{"ent", []string{"Client", "Client"}, quicklinkContains},
+		{"IMP", []string{"Imported By", "importedby"}, quicklinkPrefix},
+		{"x", []string{"Client"}, 0},
+	} {
+		if got := quicklinkScore(test.q, test.names...); got != test.want {
+			t.Errorf("quicklinkScore(%q, %q) = %d, want %d", test.q, test.names, got, test.want)
+		}
+	}
+}
+
+func TestQuicklinks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	if err := testDB.InsertModule(ctx, sample.DefaultModule()); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(q, pkg string) (int, []*Quicklink) {
+		t.Helper()
+		v := url.Values{"q": {q}, "pkg": {pkg}}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/quicklinks?"+v.Encode(), nil))
+		if w.Code != http.StatusOK {
+			return w.Code, nil
+		}
+		var links []*Quicklink
+		if err := json.Unmarshal(w.Body.Bytes(), &links); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, links
+	}
+
+	pkgURL := "/" + sample.ModulePath + "@" + sample.VersionString + "/" + sample.Suffix
+	_, got := get("imp", sample.PackagePath)
+	want := []*Quicklink{
+		{Kind: "tab", Title: "Imports", URL: pkgURL + "?tab=imports"},
+		{Kind: "tab", Title: "Imported By", URL: pkgURL + "?tab=importedby"},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(Quicklink{})); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, got := get("", sample.PackagePath); len(got) != 0 {
+		t.Errorf("empty query: got %d results, want none", len(got))
+	}
+	if code, _ := get("imp", "example.com/unknown"); code != http.StatusNotFound {
+		t.Errorf("unknown package: got status %d, want %d", code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// maxReleaseNotesExcerpt is the maximum number of characters of the release
+// notes shown on the versions tab.
+const maxReleaseNotesExcerpt = 120
+
+// addReleaseNotes sets the release notes of the overview of the given module
+// version, if it is redistributable and has some. Data sources other than the
+// database do not record release notes.
+func addReleaseNotes(ctx context.Context, ds internal.DataSource, mi *internal.LegacyModuleInfo, overview *OverviewDetails) error {
+	db, ok := ds.(*postgres.DB)
+	if !ok || !mi.IsRedistributable {
+		return nil
+	}
+	notes, err := db.GetReleaseNotes(ctx, mi.ModulePath, mi.Version)
+	if errors.Is(err, derrors.NotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	overview.ReleaseNotes, _ = renderMarkdown(ctx, &mi.ModuleInfo, notes, releaseNotesIDPrefix)
+	overview.ReleaseNotesSource = fileSource(mi.ModulePath, mi.Version, notes.Filepath)
+	return nil
+}
+
+// addVersionsReleaseNotes sets an excerpt of the release notes of each
+// version in vd that has some.
+func addVersionsReleaseNotes(ctx context.Context, ds internal.DataSource, vd *VersionsDetails) error {
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		return nil
+	}
+	byModule := map[string]map[string]*internal.Readme{}
+	for _, vl := range append(append([]*VersionList(nil), vd.ThisModule...), vd.OtherModules...) {
+		if vl.ModulePath == stdlib.ModulePath {
+			continue
+		}
+		notes, ok := byModule[vl.ModulePath]
+		if !ok {
+			var err error
+			notes, err = db.GetReleaseNotesForModule(ctx, vl.ModulePath)
+			if err != nil {
+				return err
+			}
+			byModule[vl.ModulePath] = notes
+		}
+		for _, vs := range vl.Versions {
+			// Outside the standard library, the tooltip is the full version.
+			if rn := notes[vs.TooltipVersion]; rn != nil {
+				vs.ReleaseNotes = releaseNotesExcerpt(rn.Contents)
+			}
+		}
+	}
+	return nil
+}
+
+// releaseNotesExcerpt returns the first line of text of the release notes
+// contents, without Markdown list markers and headings, shortened to
+// maxReleaseNotesExcerpt characters.
+func releaseNotesExcerpt(contents string) string {
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimLeft(line, " \t#*+-")
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+		if utf8.RuneCountInString(line) <= maxReleaseNotesExcerpt {
+			return line
+		}
+		runes := []rune(line)
+		return strings.TrimSpace(string(runes[:maxReleaseNotesExcerpt-1])) + "…"
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestReleaseNotesExcerpt(t *testing.T) {
+	long := strings.Repeat("word ", 40)
+	for _, test := range []struct {
+		contents, want string
+	}{
+		{"", ""},
+		{"\n### Added\n\n- The   Frob function.\n- More.\n", "Added"},
+		{"  * Fixed a bug.\n", "Fixed a bug."},
+		{long, strings.TrimSpace(long[:maxReleaseNotesExcerpt-1]) + "…"},
+	} {
+		if got := releaseNotesExcerpt(test.contents); got != test.want {
+			t.Errorf("releaseNotesExcerpt(%q) = %q, want %q", test.contents, got, test.want)
+		}
+	}
+}
+
+func TestReleaseNotes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer postgres.ResetTestDB(testDB, t)
+
+	const modulePath = "example.com/m"
+	for _, v := range []string{"v1.0.0", "v1.1.0"} {
+		m := sample.Module(modulePath, v, "p")
+		if v == "v1.1.0" {
+			m.ReleaseNotes = &internal.Readme{Filepath: "CHANGELOG.md", Contents: "- Frob *faster*.\n"}
+		}
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mi := sample.LegacyModuleInfo(modulePath, "v1.1.0")
+	overview := &OverviewDetails{}
+	if err := addReleaseNotes(ctx, testDB, mi, overview); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(overview.ReleaseNotes), "<em>faster</em>") {
+		t.Errorf("ReleaseNotes = %q, want rendered Markdown", overview.ReleaseNotes)
+	}
+	if want := fileSource(modulePath, "v1.1.0", "CHANGELOG.md"); overview.ReleaseNotesSource != want {
+		t.Errorf("ReleaseNotesSource = %q, want %q", overview.ReleaseNotesSource, want)
+	}
+
+	vd, err := fetchModuleVersionsDetails(ctx, testDB, mi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, vl := range vd.ThisModule {
+		for _, vs := range vl.Versions {
+			got[vs.TooltipVersion] = vs.ReleaseNotes
+		}
+	}
+	if got["v1.1.0"] != "Frob *faster*." || got["v1.0.0"] != "" {
+		t.Errorf("release notes on the versions tab = %q, want only v1.1.0's", got)
+	}
+}
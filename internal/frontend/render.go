@@ -0,0 +1,68 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+const (
+	// streamChunkSize is the size of the chunks in which rendered pages are
+	// written. Pages smaller than that are written only after their template
+	// executes successfully, so that an error page can be served instead.
+	streamChunkSize = 256 * 1024
+
+	// maxPooledBufferSize is the capacity above which a buffer is not put
+	// back in bufferPool, so that a few huge pages do not pin their memory.
+	maxPooledBufferSize = 4 * streamChunkSize
+)
+
+// bufferPool holds the buffers that pages are rendered into, to reduce
+// garbage from large documentation pages.
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// chunkWriter buffers writes to w and writes them in chunks of at least
+// streamChunkSize bytes.
+type chunkWriter struct {
+	w   io.Writer
+	buf *bytes.Buffer
+	// streaming reports whether some of the output has been written to w.
+	streaming bool
+}
+
+func (c *chunkWriter) Write(p []byte) (int, error) {
+	c.buf.Write(p)
+	if c.buf.Len() >= streamChunkSize {
+		c.streaming = true
+		if _, err := c.w.Write(c.buf.Bytes()); err != nil {
+			return 0, err
+		}
+		c.buf.Reset()
+	}
+	return len(p), nil
+}
+
+// flush writes the rest of the buffered output to w.
+func (c *chunkWriter) flush() error {
+	_, err := c.w.Write(c.buf.Bytes())
+	c.buf.Reset()
+	return err
+}
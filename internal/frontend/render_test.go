@@ -0,0 +1,40 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestChunkWriter(t *testing.T) {
+	var out bytes.Buffer
+	cw := &chunkWriter{w: &out, buf: getBuffer()}
+	defer putBuffer(cw.buf)
+
+	small := strings.Repeat("a", streamChunkSize/2)
+	if _, err := cw.Write([]byte(small)); err != nil {
+		t.Fatal(err)
+	}
+	if cw.streaming || out.Len() != 0 {
+		t.Fatalf("after %d bytes: streaming=%t, wrote %d bytes; want nothing written", len(small), cw.streaming, out.Len())
+	}
+	if _, err := cw.Write([]byte(small + "b")); err != nil {
+		t.Fatal(err)
+	}
+	if !cw.streaming || out.Len() != streamChunkSize+1 {
+		t.Fatalf("after %d bytes: streaming=%t, wrote %d bytes; want all written", 2*len(small)+1, cw.streaming, out.Len())
+	}
+	if _, err := cw.Write([]byte("tail")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if want := small + small + "b" + "tail"; out.String() != want {
+		t.Errorf("got %d bytes, want %d", out.Len(), len(want))
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// ArchivedRepository is shown on details pages when the repository of the
+// module is archived, and so may no longer be maintained.
+type ArchivedRepository struct {
+	URL string
+	// LastActivity is when the repository was last pushed to, like
+	// "Jan 2, 2006", or empty if it is not known.
+	LastActivity string
+}
+
+// archivedRepository returns the ArchivedRepository of the repository at
+// repoURL, or nil if it is not known to be archived. A missing warning is
+// better than a failed page, so errors are only logged.
+func (s *Server) archivedRepository(ctx context.Context, repoURL string) *ArchivedRepository {
+	db, ok := s.ds.(*postgres.DB)
+	if !ok || repoURL == "" {
+		// Only the worker gets repository metadata, into the database.
+		return nil
+	}
+	md, err := db.GetRepositoryMetadata(ctx, repoURL)
+	if err != nil {
+		if !errors.Is(err, derrors.NotFound) {
+			log.Error(ctx, err)
+		}
+		return nil
+	}
+	if !md.Archived {
+		return nil
+	}
+	ar := &ArchivedRepository{URL: repoURL}
+	if !md.LastActivity.IsZero() {
+		ar.LastActivity = elapsedTime(ctx, md.LastActivity)
+	}
+	return ar
+}
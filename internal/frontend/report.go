@@ -0,0 +1,135 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/pkgsite/internal/abuse"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// maxReportsPerHour is the number of abuse reports accepted from one IP
+// address in an hour. Reports beyond that are rejected with a 429.
+const maxReportsPerHour = 5
+
+// A CaptchaVerifier checks that a form was submitted by a person rather than a
+// script. It is the hook for a captcha service.
+type CaptchaVerifier interface {
+	// FormHTML returns the HTML that presents the challenge in a form. It
+	// comes from the configuration of the server, and is not escaped.
+	FormHTML() template.HTML
+	// Verify reports whether the form submitted with r solves the challenge.
+	Verify(r *http.Request) (bool, error)
+}
+
+// reportPage is the page for reporting abuse of a package or module.
+type reportPage struct {
+	basePage
+	Path      string
+	Version   string
+	Kinds     []string
+	Captcha   template.HTML
+	Submitted bool
+}
+
+// handleReport serves the abuse report form, and records the reports
+// submitted with it for review by an administrator.
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	if err := s.serveReport(w, r); err != nil {
+		s.serveError(w, r, err)
+	}
+}
+
+func (s *Server) serveReport(w http.ResponseWriter, r *http.Request) (err error) {
+	defer derrors.Wrap(&err, "serveReport(%q)", r.URL.Path)
+
+	db, ok := s.ds.(*postgres.DB)
+	if !ok {
+		// Only the database can record reports.
+		return &serverError{status: http.StatusNotImplemented}
+	}
+	page := &reportPage{
+		basePage: s.newBasePage(r, "Report a problem"),
+		Path:     strings.TrimSpace(r.FormValue("path")),
+		Version:  strings.TrimSpace(r.FormValue("version")),
+		Kinds:    abuse.Kinds,
+	}
+	if s.captcha != nil {
+		page.Captcha = s.captcha.FormHTML()
+	}
+	if r.Method != http.MethodPost {
+		s.servePage(r.Context(), w, "report.tmpl", page)
+		return nil
+	}
+
+	ctx := r.Context()
+	report := &abuse.Report{
+		Path:    page.Path,
+		Version: page.Version,
+		Kind:    r.FormValue("kind"),
+		Details: strings.TrimSpace(r.FormValue("details")),
+		Contact: strings.TrimSpace(r.FormValue("contact")),
+	}
+	if err := report.Validate(); err != nil {
+		return reportError(http.StatusBadRequest, err, "The report is incomplete: "+err.Error()+".")
+	}
+	if s.captcha != nil {
+		ok, err := s.captcha.Verify(r)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return reportError(http.StatusBadRequest, errors.New("captcha failed"),
+				"The captcha was not solved. Go back and try again.")
+		}
+	}
+	reporter := reporterKey(r)
+	n, err := db.CountAbuseReports(ctx, reporter, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if n >= maxReportsPerHour {
+		return reportError(http.StatusTooManyRequests, fmt.Errorf("%d reports in the last hour", n),
+			"Too many reports were sent from your network. Try again later.")
+	}
+	id, err := db.InsertAbuseReport(ctx, report, reporter)
+	if err != nil {
+		return err
+	}
+	log.Infof(ctx, "abuse report %d: %s %s@%s", id, report.Kind, report.Path, report.Version)
+	page.Submitted = true
+	s.servePage(ctx, w, "report.tmpl", page)
+	return nil
+}
+
+// reportError returns a serverError with the given status for err, which shows
+// message to the user.
+func reportError(status int, err error, message string) error {
+	return &serverError{
+		status: status,
+		epage:  &errorPage{Message: message},
+		err:    err,
+	}
+}
+
+// reporterKey returns a key that identifies the origin of r, for limiting the
+// rate of reports, without revealing its IP address.
+func reporterKey(r *http.Request) string {
+	ip := strings.TrimSpace(strings.SplitN(r.Header.Get("X-Forwarded-For"), ",", 2)[0])
+	if ip == "" {
+		ip, _, _ = net.SplitHostPort(r.RemoteAddr)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(ip)))
+}
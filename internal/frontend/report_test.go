@@ -0,0 +1,34 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReporterKey(t *testing.T) {
+	key := func(forwardedFor, remoteAddr string) string {
+		r := httptest.NewRequest("POST", "/report", nil)
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		r.RemoteAddr = remoteAddr
+		return reporterKey(r)
+	}
+	k1 := key("1.2.3.4, 10.0.0.1", "10.0.0.2:1234")
+	if k2 := key("1.2.3.4", "10.0.0.3:80"); k2 != k1 {
+		t.Errorf("same forwarded address: got different keys %q and %q", k1, k2)
+	}
+	if k2 := key("1.2.3.5", "10.0.0.2:1234"); k2 == k1 {
+		t.Errorf("different forwarded addresses: got the same key %q", k1)
+	}
+	if k1, k2 := key("", "1.2.3.4:1"), key("", "1.2.3.4:2"); k1 != k2 {
+		t.Errorf("same remote address, different ports: got different keys %q and %q", k1, k2)
+	}
+	if k := key("1.2.3.4", ""); k == "1.2.3.4" || len(k) != 64 {
+		t.Errorf("got key %q, want a hash", k)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// defaultRobotsDisallow are the paths that crawlers are asked not to visit,
+// unless configured otherwise: search results, fetch requests, the API, and
+// imported-by tabs, which are expensive to serve and of little use in search
+// engines.
+var defaultRobotsDisallow = []string{
+	"/search?*",
+	"/fetch/",
+	"/api/",
+	"/*tab=importedby",
+}
+
+// robotsTxt returns the contents of robots.txt, asking all crawlers not to
+// visit the disallowed paths.
+func robotsTxt(disallow []string) string {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	for _, d := range disallow {
+		b.WriteString("Disallow: " + d + "\n")
+	}
+	return b.String()
+}
+
+// noIndex reports whether search engines should be asked not to index a
+// details page for the given requested and resolved versions. Only the pages
+// of the latest version, at their canonical unversioned URLs, are worth
+// indexing; pages of pseudo-versions and imported-by tabs are not, even then.
+func noIndex(r *http.Request, requestedVersion, resolvedVersion string) bool {
+	return requestedVersion != internal.LatestVersion ||
+		version.IsPseudo(resolvedVersion) ||
+		r.FormValue("tab") == "importedby"
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/pkgsite/internal"
+)
+
+func TestRobotsTxt(t *testing.T) {
+	got := robotsTxt([]string{"/search?*", "/api/"})
+	want := "User-agent: *\nDisallow: /search?*\nDisallow: /api/\n"
+	if got != want {
+		t.Errorf("robotsTxt = %q, want %q", got, want)
+	}
+}
+
+func TestNoIndex(t *testing.T) {
+	const pseudo = "v0.0.0-20200101000000-0123456789ab"
+	for _, test := range []struct {
+		target                            string
+		requestedVersion, resolvedVersion string
+		want                              bool
+	}{
+		{"/example.com/m", internal.LatestVersion, "v1.2.0", false},
+		{"/example.com/m?tab=versions", internal.LatestVersion, "v1.2.0", false},
+		{"/example.com/m@v1.2.0", "v1.2.0", "v1.2.0", true},
+		{"/example.com/m@master", internal.MasterVersion, pseudo, true},
+		{"/example.com/m", internal.LatestVersion, pseudo, true},
+		{"/example.com/m?tab=importedby", internal.LatestVersion, "v1.2.0", true},
+	} {
+		r := httptest.NewRequest("GET", test.target, nil)
+		if got := noIndex(r, test.requestedVersion, test.resolvedVersion); got != test.want {
+			t.Errorf("noIndex(%q, %q, %q) = %t, want %t", test.target, test.requestedVersion, test.resolvedVersion, got, test.want)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html/template"
 	"math"
 	"net/http"
 	"path"
@@ -16,7 +17,6 @@ import (
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/log"
-	"golang.org/x/pkgsite/internal/postgres"
 )
 
 const defaultSearchLimit = 10
@@ -31,21 +31,30 @@ type SearchPage struct {
 
 // SearchResult contains data needed to display a single search result.
 type SearchResult struct {
-	Name           string
-	PackagePath    string
-	ModulePath     string
-	Synopsis       string
-	DisplayVersion string
-	Licenses       []string
-	CommitTime     string
-	NumImportedBy  uint64
-	Approximate    bool
+	Name            string
+	PackagePath     string
+	ModulePath      string
+	Synopsis        string
+	DisplayVersion  string
+	Licenses        []string
+	CommitTime      string // relative to now, like "2 days ago"
+	CommitTimestamp string // CommitTime in RFC 3339
+	NumImportedBy   uint64
+	Approximate     bool
+
+	// SynopsisHighlight and ReadmeHighlight show the parts of the synopsis
+	// and README that match the query. They are empty if there is no match.
+	SynopsisHighlight template.HTML
+	ReadmeHighlight   template.HTML
+
+	// Symbols are the most prominent exported identifiers of the package.
+	Symbols []string
 }
 
-// fetchSearchPage fetches data matching the search query from the database and
-// returns a SearchPage.
-func fetchSearchPage(ctx context.Context, db *postgres.DB, query string, pageParams paginationParams) (*SearchPage, error) {
-	dbresults, err := db.Search(ctx, query, pageParams.limit, pageParams.offset())
+// fetchSearchPage fetches data matching the search query from the search
+// backend and returns a SearchPage.
+func fetchSearchPage(ctx context.Context, sb internal.SearchBackend, query string, pageParams paginationParams) (*SearchPage, error) {
+	dbresults, err := sb.Search(ctx, query, pageParams.limit, pageParams.offset())
 	if err != nil {
 		return nil, err
 	}
@@ -53,14 +62,19 @@ func fetchSearchPage(ctx context.Context, db *postgres.DB, query string, pagePar
 	var results []*SearchResult
 	for _, r := range dbresults {
 		results = append(results, &SearchResult{
-			Name:           r.Name,
-			PackagePath:    r.PackagePath,
-			ModulePath:     r.ModulePath,
-			Synopsis:       r.Synopsis,
-			DisplayVersion: displayVersion(r.Version, r.ModulePath),
-			Licenses:       r.Licenses,
-			CommitTime:     elapsedTime(r.CommitTime),
-			NumImportedBy:  r.NumImportedBy,
+			Name:            r.Name,
+			PackagePath:     r.PackagePath,
+			ModulePath:      r.ModulePath,
+			Synopsis:        r.Synopsis,
+			DisplayVersion:  displayVersion(r.Version, r.ModulePath),
+			Licenses:        r.Licenses,
+			CommitTime:      elapsedTime(ctx, r.CommitTime),
+			CommitTimestamp: timestamp(r.CommitTime),
+			NumImportedBy:   r.NumImportedBy,
+			// The search backend escapes the text of the highlights.
+			SynopsisHighlight: template.HTML(r.SynopsisHighlight),
+			ReadmeHighlight:   template.HTML(r.ReadmeHighlight),
+			Symbols:           r.Symbols,
 		})
 	}
 
@@ -103,9 +117,8 @@ func approximateNumber(estimate int, sigma float64) int {
 // /search?q=<query>. If <query> is an exact match for a package path, the user
 // will be redirected to the details page.
 func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request) error {
-	db, ok := s.ds.(*postgres.DB)
-	if !ok {
-		// The proxydatasource does not support the imported by page.
+	if s.search == nil {
+		// The proxydatasource does not support search.
 		return &serverError{status: http.StatusFailedDependency}
 	}
 
@@ -120,9 +133,13 @@ func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request) error {
 		http.Redirect(w, r, path, http.StatusFound)
 		return nil
 	}
-	page, err := fetchSearchPage(ctx, db, query, newPaginationParams(r, defaultSearchLimit))
+	pageParams, err := newPaginationParams(r, defaultSearchLimit, internal.MaxSearchResults)
 	if err != nil {
-		return fmt.Errorf("fetchSearchPage(ctx, db, %q): %v", query, err)
+		return &serverError{status: http.StatusBadRequest, err: err}
+	}
+	page, err := fetchSearchPage(ctx, s.search, query, pageParams)
+	if err != nil {
+		return fmt.Errorf("fetchSearchPage(ctx, s.search, %q): %v", query, err)
 	}
 	page.basePage = s.newBasePage(r, query)
 	s.servePage(ctx, w, "search.tmpl", page)
@@ -154,7 +171,7 @@ func searchRequestRedirectPath(ctx context.Context, ds internal.DataSource, quer
 		return fmt.Sprintf("/mod/%s", requestedPath)
 	}
 
-	pkg, err := ds.GetPackage(ctx, requestedPath, internal.UnknownModulePath, internal.LatestVersion)
+	pkg, err := ds.GetPackage(ctx, requestedPath, internal.UnknownModulePath, internal.LatestVersion, internal.MinimalFields)
 	if err == nil {
 		return fmt.Sprintf("/%s", pkg.Path)
 	} else if !errors.Is(err, derrors.NotFound) {
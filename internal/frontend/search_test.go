@@ -88,14 +88,16 @@ func TestFetchSearchPage(t *testing.T) {
 				},
 				Results: []*SearchResult{
 					{
-						Name:           moduleBar.LegacyPackages[0].Name,
-						PackagePath:    moduleBar.LegacyPackages[0].Path,
-						ModulePath:     moduleBar.ModulePath,
-						Synopsis:       moduleBar.LegacyPackages[0].Synopsis,
-						DisplayVersion: moduleBar.Version,
-						Licenses:       []string{"MIT"},
-						CommitTime:     elapsedTime(moduleBar.CommitTime),
-						NumImportedBy:  0,
+						Name:              moduleBar.LegacyPackages[0].Name,
+						PackagePath:       moduleBar.LegacyPackages[0].Path,
+						ModulePath:        moduleBar.ModulePath,
+						Synopsis:          moduleBar.LegacyPackages[0].Synopsis,
+						DisplayVersion:    moduleBar.Version,
+						Licenses:          []string{"MIT"},
+						CommitTime:        elapsedTime(ctx, moduleBar.CommitTime),
+						CommitTimestamp:   timestamp(moduleBar.CommitTime),
+						NumImportedBy:     0,
+						SynopsisHighlight: "<b>bar</b> is used by <b>foo</b>.",
 					},
 				},
 			},
@@ -116,14 +118,16 @@ func TestFetchSearchPage(t *testing.T) {
 				},
 				Results: []*SearchResult{
 					{
-						Name:           moduleFoo.LegacyPackages[0].Name,
-						PackagePath:    moduleFoo.LegacyPackages[0].Path,
-						ModulePath:     moduleFoo.ModulePath,
-						Synopsis:       moduleFoo.LegacyPackages[0].Synopsis,
-						DisplayVersion: moduleFoo.Version,
-						Licenses:       []string{"MIT"},
-						CommitTime:     elapsedTime(moduleFoo.CommitTime),
-						NumImportedBy:  0,
+						Name:              moduleFoo.LegacyPackages[0].Name,
+						PackagePath:       moduleFoo.LegacyPackages[0].Path,
+						ModulePath:        moduleFoo.ModulePath,
+						Synopsis:          moduleFoo.LegacyPackages[0].Synopsis,
+						DisplayVersion:    moduleFoo.Version,
+						Licenses:          []string{"MIT"},
+						CommitTime:        elapsedTime(ctx, moduleFoo.CommitTime),
+						CommitTimestamp:   timestamp(moduleFoo.CommitTime),
+						NumImportedBy:     0,
+						SynopsisHighlight: "foo is a <b>package</b>.",
 					},
 				},
 			},
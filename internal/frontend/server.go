@@ -12,20 +12,29 @@ import (
 	"html/template"
 	"io"
 	"net/http"
-	"path/filepath"
+	"path"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v7"
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/assets"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/cdn"
 	"golang.org/x/pkgsite/internal/config"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/experiment"
+	"golang.org/x/pkgsite/internal/i18n"
 	"golang.org/x/pkgsite/internal/licenses"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/middleware"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/prefs"
 	"golang.org/x/pkgsite/internal/queue"
+	"golang.org/x/pkgsite/internal/verify"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 )
 
 // Server can be installed to serve the go discovery frontend.
@@ -36,11 +45,46 @@ type Server struct {
 	// set.
 	cmplClient           *redis.Client
 	taskIDChangeInterval time.Duration
-	staticPath           string
-	thirdPartyPath       string
-	templateDir          string
+	staticFS             http.FileSystem
+	thirdPartyFS         http.FileSystem
 	devMode              bool
 	errorPage            []byte
+	// staleCache holds recently rendered pages, served when the database
+	// is unavailable.
+	staleCache *staleCache
+	// acl, if non-nil, is the ACL enforced by ds. Pages are then not shared
+	// between users through caches.
+	acl *auth.ACL
+	// signIn is whether users must sign in to see pages.
+	signIn bool
+	// branding is the name, logos, links and colors of the site.
+	branding *Branding
+	// search, if non-nil, is used to serve search results.
+	search internal.SearchBackend
+	// captcha, if non-nil, checks that abuse reports are sent by people.
+	captcha CaptchaVerifier
+	// watchlists is whether signed-in users can keep watchlists.
+	watchlists bool
+	// verifier, if non-nil, checks that signed-in users control the modules
+	// whose ownership they claim.
+	verifier *verify.Checker
+	// robotsDisallow are the paths disallowed by robots.txt.
+	robotsDisallow []string
+	// cachePolicies are the caching headers of responses, by route class.
+	cachePolicies map[string]config.CachePolicy
+	// catalog holds the translations of the strings of pages.
+	catalog *i18n.Catalog
+	// prefs keeps the preferences of users. It is nil if they cannot have
+	// any.
+	prefs *prefs.Store
+	// signalProviders are the names of the providers of the external
+	// signals shown on details pages.
+	signalProviders []string
+	// cacheClient, if non-nil, is the redis client of the page cache, set
+	// by Install.
+	cacheClient *redis.Client
+	// cdnPurger, if non-nil, purges pages from the CDN.
+	cdnPurger *cdn.Purger
 
 	mu        sync.Mutex // Protects all fields below
 	templates map[string]*template.Template
@@ -54,27 +98,104 @@ type ServerConfig struct {
 	TaskIDChangeInterval time.Duration
 	StaticPath           string
 	ThirdPartyPath       string
-	DevMode              bool
+	// StaticFS and ThirdPartyFS, if set, hold the static files and
+	// third-party libraries, instead of the StaticPath and ThirdPartyPath
+	// directories.
+	StaticFS     http.FileSystem
+	ThirdPartyFS http.FileSystem
+	DevMode      bool
+	// ACL is the ACL that DataSource enforces, if any.
+	ACL *auth.ACL
+	// Branding is the branding of the site. If nil, DefaultBranding is used.
+	Branding *Branding
+	// SearchBackend is used to serve search results. If nil and DataSource
+	// is a *postgres.DB, the database is used.
+	SearchBackend internal.SearchBackend
+	// CaptchaVerifier, if non-nil, is used to require a captcha on the abuse
+	// report form.
+	CaptchaVerifier CaptchaVerifier
+	// Watchlists is whether signed-in users can keep lists of modules to be
+	// emailed about. It requires sign-in and a *postgres.DB DataSource.
+	Watchlists bool
+	// Verifier, if non-nil, lets signed-in users verify that they own
+	// modules. It requires sign-in and a *postgres.DB DataSource.
+	Verifier *verify.Checker
+	// RobotsDisallow are the paths that robots.txt asks crawlers not to
+	// visit. If nil, defaultRobotsDisallow is used.
+	RobotsDisallow []string
+	// SignIn is whether users must sign in to see pages. Like with an ACL,
+	// pages are then not kept by shared caches.
+	SignIn bool
+	// CachePolicies are the caching headers of responses, by route class
+	// (see routeClass). They are not used when ACL or SignIn is set.
+	CachePolicies map[string]config.CachePolicy
+	// PrefsKey is the key that signs the cookie holding the preferences of
+	// users. If it is empty, users cannot set preferences.
+	PrefsKey []byte
+	// ExternalSignals are the names of the external signal providers whose
+	// signals are shown in the header of details pages, like
+	// "github-stars". The worker must refresh them; see package signals.
+	ExternalSignals []string
+	// CDNPurger, if non-nil, purges the pages of modules from the CDN when
+	// users change what they show, like by verifying their ownership.
+	CDNPurger *cdn.Purger
 }
 
 // NewServer creates a new Server for the given database and template directory.
 func NewServer(scfg ServerConfig) (_ *Server, err error) {
 	defer derrors.Wrap(&err, "NewServer(...)")
-	templateDir := filepath.Join(scfg.StaticPath, "html")
-	ts, err := parsePageTemplates(templateDir)
+	staticFS := scfg.StaticFS
+	if staticFS == nil {
+		staticFS = http.Dir(scfg.StaticPath)
+	}
+	thirdPartyFS := scfg.ThirdPartyFS
+	if thirdPartyFS == nil {
+		thirdPartyFS = http.Dir(scfg.ThirdPartyPath)
+	}
+	ts, err := parsePageTemplates(staticFS)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing templates: %v", err)
 	}
+	catalog, err := i18n.ReadCatalog(staticFS, "/i18n")
+	if err != nil {
+		return nil, err
+	}
+	branding := scfg.Branding
+	if branding == nil {
+		branding = DefaultBranding()
+	}
+	if err := branding.validate(); err != nil {
+		return nil, err
+	}
 	s := &Server{
 		ds:                   scfg.DataSource,
 		queue:                scfg.Queue,
 		cmplClient:           scfg.CompletionClient,
-		staticPath:           scfg.StaticPath,
-		thirdPartyPath:       scfg.ThirdPartyPath,
-		templateDir:          templateDir,
+		staticFS:             staticFS,
+		thirdPartyFS:         thirdPartyFS,
 		devMode:              scfg.DevMode,
 		templates:            ts,
 		taskIDChangeInterval: scfg.TaskIDChangeInterval,
+		staleCache:           newStaleCache(staleCacheSize),
+		acl:                  scfg.ACL,
+		signIn:               scfg.SignIn,
+		branding:             branding,
+		search:               scfg.SearchBackend,
+		captcha:              scfg.CaptchaVerifier,
+		watchlists:           scfg.Watchlists,
+		verifier:             scfg.Verifier,
+		robotsDisallow:       scfg.RobotsDisallow,
+		cachePolicies:        scfg.CachePolicies,
+		catalog:              catalog,
+		prefs:                prefs.NewStore(scfg.PrefsKey),
+		signalProviders:      scfg.ExternalSignals,
+		cdnPurger:            scfg.CDNPurger,
+	}
+	if s.robotsDisallow == nil {
+		s.robotsDisallow = defaultRobotsDisallow
+	}
+	if db, ok := scfg.DataSource.(*postgres.DB); ok && s.search == nil {
+		s.search = db
 	}
 	errorPageBytes, err := s.renderErrorPage(context.Background(), http.StatusInternalServerError, "error.tmpl", nil)
 	if err != nil {
@@ -84,37 +205,174 @@ func NewServer(scfg ServerConfig) (_ *Server, err error) {
 	return s, nil
 }
 
+// cache returns a handler that caches the responses of h in redis under name.
+// Pages are cached separately for each theme that the color scheme hint of
+// requests can ask for.
+//
+// Text documentation is not cached: plain text is negotiated by request
+// headers that are not part of the cache key, and the cache does not keep the
+// Content-Type of responses. Neither are JSON pages, nor other responses that
+// depend on request headers; see cacheable.
+func (s *Server) cache(name string, client *redis.Client, expirer middleware.Expirer, h http.Handler) http.Handler {
+	cached := map[string]http.Handler{}
+	for _, theme := range append([]string{""}, prefs.Themes...) {
+		n := name
+		if theme != "" {
+			n += "-" + theme
+		}
+		cached[theme] = middleware.Cache(n, client, expirer)(h)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.cacheable(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		cached[colorSchemeHint(r)].ServeHTTP(w, r)
+	})
+}
+
 // Install registers server routes using the given handler registration func.
 func (s *Server) Install(handle func(string, http.Handler), redisClient *redis.Client) {
+	handle = withQueryTimeouts(handle)
+	if s.private() {
+		// Pages of private deployments must not be kept by shared caches.
+		handle = withPrivateCaching(handle)
+	} else {
+		handle = withCachePolicies(handle, s.cachePolicies)
+	}
 	var (
 		detailHandler http.Handler = s.errorHandler(s.serveDetails)
 		searchHandler http.Handler = s.errorHandler(s.serveSearch)
 	)
-	if redisClient != nil {
-		detailHandler = middleware.Cache("details", redisClient, detailsTTL)(detailHandler)
-		searchHandler = middleware.Cache("search", redisClient, middleware.TTL(defaultTTL))(searchHandler)
-	}
-	handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir(s.staticPath))))
-	handle("/third_party/", http.StripPrefix("/third_party", http.FileServer(http.Dir(s.thirdPartyPath))))
-	handle("/favicon.ico", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, fmt.Sprintf("%s/img/favicon.ico", http.Dir(s.staticPath)))
-	}))
+	if redisClient != nil && s.acl == nil {
+		s.cacheClient = redisClient
+		detailHandler = s.cache("details", redisClient, detailsTTL, detailHandler)
+		searchHandler = s.cache("search", redisClient, middleware.TTL(defaultTTL), searchHandler)
+	}
+	handle("/static/", http.StripPrefix("/static/", http.FileServer(s.staticFS)))
+	handle("/third_party/", http.StripPrefix("/third_party", http.FileServer(s.thirdPartyFS)))
+	handle("/favicon.ico", http.HandlerFunc(s.serveFavicon))
 	handle("/fetch/", http.HandlerFunc(s.fetchHandler))
 	handle("/pkg/", http.HandlerFunc(s.handlePackageDetailsRedirect))
+	handle("/x/", http.HandlerFunc(s.handleXRedirect))
 	handle("/search", searchHandler)
 	handle("/search-help", s.staticPageHandler("search_help.tmpl", "Search Help - go.dev"))
 	handle("/license-policy", s.licensePolicyHandler())
+	handle("/report", http.HandlerFunc(s.handleReport))
+	handle("/watchlist", http.HandlerFunc(s.handleWatchlist))
+	handle("/verify", s.errorHandler(s.serveVerify))
+	handle("/settings", s.errorHandler(s.serveSettings))
+	handle("/compare", s.errorHandler(s.serveCompare))
+	handle("/collections/", s.errorHandler(s.serveCollections))
+	handle("/browse/", s.errorHandler(s.serveBrowse))
+	handle("/feed/", http.HandlerFunc(s.handleFeed))
+	handle("/badge/", http.HandlerFunc(s.handleBadge))
 	handle("/about", http.RedirectHandler("https://go.dev/about", http.StatusFound))
 	handle("/", detailHandler)
 	handle("/autocomplete", http.HandlerFunc(s.handleAutoCompletion))
+	handle("/opensearch.xml", http.HandlerFunc(s.handleOpenSearch))
+	handle(apiPrefix+"docjson/", apiHandler(s.serveDocJSON))
+	handle(apiPrefix+"symbols/", apiHandler(s.serveSymbols))
+	handle(apiPrefix+"hover/", apiHandler(s.serveHover))
+	handle(apiPrefix+"module/", apiHandler(s.serveModuleJSON))
+	handle(apiPrefix+"quality/", apiHandler(s.serveQuality))
+	handle(apiPrefix+"similar/", apiHandler(s.serveSimilar))
+	handle(apiPrefix+"deps", apiHandler(s.serveDeps))
+	handle(apiPrefix+"quicklinks", apiHandler(s.serveQuicklinks))
+	robots := robotsTxt(s.robotsDisallow)
 	handle("/robots.txt", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(`User-agent: *
-Disallow: /search?*
-`))
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(robots))
 	}))
 }
 
+// queryTimeout returns the longest that a single database query may take
+// while serving route, or zero if there is no limit. A query that runs longer
+// is canceled, and the request fails, instead of tying up a connection that
+// other requests are waiting for.
+func queryTimeout(route string) time.Duration {
+	switch route {
+	case "/static/", "/third_party/", "/favicon.ico", "/robots.txt", "/opensearch.xml":
+		// No queries.
+		return 0
+	case "/fetch/":
+		// Polling for the result of a fetch has its own timeouts.
+		return 0
+	case "/autocomplete", apiPrefix + "quicklinks":
+		return 2 * time.Second
+	case "/search":
+		return 10 * time.Second
+	default:
+		return 15 * time.Second
+	}
+}
+
+// withQueryTimeouts wraps handle so that the handler for each route runs with
+// the query timeout of the route.
+func withQueryTimeouts(handle func(string, http.Handler)) func(string, http.Handler) {
+	return func(route string, h http.Handler) {
+		if d := queryTimeout(route); d > 0 {
+			h = middleware.QueryTimeout(d)(h)
+		}
+		handle(route, h)
+	}
+}
+
+// routeClass returns the class of a request to route, which selects the
+// caching headers of its response: "static" for static files, "search" for
+// search results, "latest" for details pages of the latest version, or of
+// master, and "pinned" for details pages of a specific version. Other requests
+// have no class.
+func routeClass(route string, r *http.Request) string {
+	switch route {
+	case "/static/", "/third_party/", "/favicon.ico":
+		return "static"
+	case "/search":
+		return "search"
+	case "/":
+		if r.URL.Path == "/" {
+			return ""
+		}
+		_, _, version, err := parseDetailsURLPath(strings.TrimPrefix(r.URL.Path, "/mod"))
+		if err != nil {
+			return ""
+		}
+		if version == internal.LatestVersion || version == internal.MasterVersion {
+			return "latest"
+		}
+		return "pinned"
+	default:
+		return ""
+	}
+}
+
+// withPrivateCaching wraps handle so that responses are only kept by the
+// caches of browsers, unless the handler sets the Cache-Control header
+// itself.
+func withPrivateCaching(handle func(string, http.Handler)) func(string, http.Handler) {
+	return func(route string, h http.Handler) {
+		handle(route, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "private")
+			h.ServeHTTP(w, r)
+		}))
+	}
+}
+
+// private reports whether pages may differ between users, or may only be
+// seen by signed-in users, so that they must not be kept by shared caches.
+func (s *Server) private() bool {
+	return s.acl != nil || s.signIn
+}
+
+// withCachePolicies wraps handle so that responses for each route have the
+// caching headers of the route class in policies.
+func withCachePolicies(handle func(string, http.Handler), policies map[string]config.CachePolicy) func(string, http.Handler) {
+	return func(route string, h http.Handler) {
+		class := func(r *http.Request) string { return routeClass(route, r) }
+		handle(route, middleware.CacheControl(policies, class)(h))
+	}
+}
+
 const (
 	// defaultTTL is used when details tab contents are subject to change, or when
 	// there is a problem confirming that the details can be permanently cached.
@@ -179,9 +437,10 @@ func suggestedSearch(userInput string) template.HTML {
 // staticPageHandler handles requests to a template that contains no dynamic
 // content.
 func (s *Server) staticPageHandler(templateName, title string) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
+	return s.errorHandler(func(w http.ResponseWriter, r *http.Request) error {
 		s.servePage(r.Context(), w, templateName, s.newBasePage(r, title))
-	}
+		return nil
+	})
 }
 
 // basePage contains fields shared by all pages when rendering templates.
@@ -192,6 +451,23 @@ type basePage struct {
 	Experiments *experiment.Set
 	GodocURL    string
 	DevMode     bool
+	Branding    *Branding
+	// Watchlists is whether pages link to the watchlist.
+	Watchlists bool
+	// Ownership is whether pages link to the verification of the ownership
+	// of modules.
+	Ownership bool
+	// NoIndex is whether search engines are asked not to index the page.
+	NoIndex bool
+	// Lang is the BCP 47 tag of the language of the page.
+	Lang string
+	// Preferences is whether pages link to the settings page.
+	Preferences bool
+	// Theme is the theme chosen by the user, if any; see prefs.Themes.
+	Theme string
+
+	// printer translates the strings of the page; see T.
+	printer *message.Printer
 }
 
 // licensePolicyPage is used to generate the static license policy page.
@@ -203,26 +479,86 @@ type licensePolicyPage struct {
 
 func (s *Server) licensePolicyHandler() http.HandlerFunc {
 	lics := licenses.AcceptedLicenses()
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return s.errorHandler(func(w http.ResponseWriter, r *http.Request) error {
 		page := licensePolicyPage{
 			basePage:         s.newBasePage(r, "Licenses"),
 			LicenseFileNames: licenses.FileNames,
 			LicenseTypes:     lics,
 		}
 		s.servePage(r.Context(), w, "license_policy.tmpl", page)
+		return nil
 	})
 }
 
-// newBasePage returns a base page for the given request and title.
+// newBasePage returns a base page for the given request and title, in the
+// language negotiated from its Accept-Language header.
 func (s *Server) newBasePage(r *http.Request, title string) basePage {
+	lang := s.language(r)
 	return basePage{
+		Lang:        lang.String(),
+		printer:     s.catalog.Printer(lang),
 		HTMLTitle:   title,
 		Query:       searchQuery(r),
 		Nonce:       middleware.NoncePlaceholder,
 		Experiments: experiment.FromContext(r.Context()),
 		GodocURL:    middleware.GodocURLPlaceholder,
 		DevMode:     s.devMode,
+		Branding:    s.branding,
+		Watchlists:  s.watchlists,
+		Preferences: s.prefs != nil,
+		Theme:       theme(r),
+		Ownership:   s.verifier != nil,
+	}
+}
+
+// T returns the translation of the English string key into the language of
+// the page, formatted with args as by fmt.Sprintf. Templates call it on
+// every string shown to users, like {{.T "Imported By"}}.
+func (b basePage) T(key string, args ...interface{}) string {
+	if b.printer == nil {
+		return fmt.Sprintf(key, args...)
 	}
+	return b.printer.Sprintf(key, args...)
+}
+
+// language returns the language of the pages served to r.
+func (s *Server) language(r *http.Request) language.Tag {
+	return s.catalog.Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// colorSchemeHeader is the client hint header in which browsers send the
+// color scheme that users prefer.
+const colorSchemeHeader = "Sec-CH-Prefers-Color-Scheme"
+
+// theme returns the theme of the pages served to r, one of prefs.Themes: the
+// one the user chose in their preferences, or else the one their browser
+// prefers. It returns the empty string if neither is known, in which case
+// the style sheet follows the browser.
+func theme(r *http.Request) string {
+	if t := prefs.FromContext(r.Context()).Theme; t != "" {
+		return t
+	}
+	return colorSchemeHint(r)
+}
+
+// colorSchemeHint returns the theme of the color scheme hint of r, if any.
+func colorSchemeHint(r *http.Request) string {
+	// The value is a structured header string, like "dark" with the quotes.
+	t := strings.Trim(r.Header.Get(colorSchemeHeader), `"`)
+	if !contains(prefs.Themes, t) {
+		return ""
+	}
+	return t
+}
+
+// cacheable reports whether the response to r may be cached by URL. The
+// response depends on headers of r when it is text documentation, when it
+// is in another language than i18n.Default, and when the user has
+// preferences. JSON pages are not cached either, because the caches do not
+// keep the Content-Type of responses.
+func (s *Server) cacheable(r *http.Request) bool {
+	return docTextFormat(r) == "" && r.FormValue("format") != "json" &&
+		s.language(r) == i18n.Default && s.prefs.Read(r) == nil
 }
 
 // GoogleTagManagerContainerID returns the container ID from GoogleTagManager.
@@ -242,6 +578,9 @@ type errorPage struct {
 	template         string
 	Message          string
 	SecondaryMessage template.HTML
+	// RequestID identifies the request in the logs. It is shown on the page
+	// so that users can include it when reporting a problem.
+	RequestID string
 }
 
 // PanicHandler returns an http.HandlerFunc that can be used in HTTP
@@ -257,7 +596,7 @@ func (s *Server) PanicHandler() (_ http.HandlerFunc, err error) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(status)
 		if _, err := io.Copy(w, bytes.NewReader(buf)); err != nil {
-			log.Errorf(r.Context(), "Error copying panic template to ResponseWriter: %v", err)
+			middleware.RecordRequestError(r.Context(), fmt.Errorf("error copying panic template to ResponseWriter: %v", err))
 		}
 	}, nil
 }
@@ -278,9 +617,39 @@ func (s *serverError) Unwrap() error {
 
 func (s *Server) errorHandler(f func(w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if err := f(w, r); err != nil {
+		if len(s.catalog.Languages()) > 1 {
+			// Pages are in the language negotiated from the request.
+			w.Header().Add("Vary", "Accept-Language")
+		}
+		// Ask browsers for the color scheme they prefer, and to retry the
+		// first request with it, so that pages have the right theme
+		// before their style sheet loads; see theme.
+		w.Header().Set("Accept-CH", colorSchemeHeader)
+		w.Header().Set("Critical-CH", colorSchemeHeader)
+		w.Header().Add("Vary", colorSchemeHeader)
+		// Strings formatted while building the page, like elapsed times,
+		// are in its language.
+		ctx := i18n.NewContext(r.Context(), s.catalog.Printer(s.language(r)))
+		if p := s.prefs.Read(r); p != nil {
+			ctx = prefs.NewContext(ctx, p)
+			// The page depends on the preferences, which are not part of
+			// the URL: it must not be cached by shared caches.
+			w.Header().Set("Cache-Control", "private")
+			w.Header().Del("Surrogate-Control")
+		}
+		r = r.WithContext(ctx)
+		if s.acl != nil || !s.cacheable(r) {
+			if err := f(w, r); err != nil {
+				s.serveError(w, r, err)
+			}
+			return
+		}
+		rec := &staleRecorder{ResponseWriter: w}
+		if err := f(rec, r); err != nil {
 			s.serveError(w, r, err)
+			return
 		}
+		s.staleCache.put(staleKey(r), rec)
 	}
 }
 
@@ -290,11 +659,16 @@ func (s *Server) serveError(w http.ResponseWriter, r *http.Request, err error) {
 	if !errors.As(err, &serr) {
 		serr = &serverError{status: http.StatusInternalServerError, err: err}
 	}
-	if serr.status == http.StatusInternalServerError {
-		log.Error(ctx, err)
-	} else {
-		log.Infof(ctx, "returning %d (%s) for error %v", serr.status, http.StatusText(serr.status), err)
+	if errors.Is(err, derrors.DBUnavailable) {
+		if p, ok := s.staleCache.get(staleKey(r)); ok {
+			log.Infof(ctx, "serving stale page for error %v", err)
+			serveStalePage(w, p)
+			return
+		}
+		serr.status = http.StatusServiceUnavailable
 	}
+	// The error is logged with the status of the request.
+	middleware.RecordRequestError(ctx, err)
 	s.serveErrorPage(w, r, serr.status, serr.epage)
 }
 
@@ -307,34 +681,32 @@ func (s *Server) serveErrorPage(w http.ResponseWriter, r *http.Request, status i
 	} else if page.template != "" {
 		template = page.template
 	}
+	page.RequestID = log.RequestID(r.Context())
 	buf, err := s.renderErrorPage(r.Context(), status, template, page)
 	if err != nil {
-		log.Errorf(r.Context(), "s.renderErrorPage(w, %d, %v): %v", status, page, err)
+		middleware.RecordRequestError(r.Context(), fmt.Errorf("s.renderErrorPage(w, %d, %v): %v", status, page, err))
 		buf = s.errorPage
 		status = http.StatusInternalServerError
 	}
 
 	w.WriteHeader(status)
 	if _, err := io.Copy(w, bytes.NewReader(buf)); err != nil {
-		log.Errorf(r.Context(), "Error copying template %q buffer to ResponseWriter: %v", template, err)
+		middleware.RecordRequestError(r.Context(), fmt.Errorf("error copying template %q buffer to ResponseWriter: %v", template, err))
 	}
 }
 
 // renderErrorPage executes error.tmpl with the given errorPage
 func (s *Server) renderErrorPage(ctx context.Context, status int, template string, page *errorPage) ([]byte, error) {
-	statusInfo := fmt.Sprintf("%d %s", status, http.StatusText(status))
 	if page == nil {
-		page = &errorPage{
-			Message: statusInfo,
-			basePage: basePage{
-				HTMLTitle: statusInfo,
-				Nonce:     middleware.NoncePlaceholder,
-			},
-		}
+		page = &errorPage{}
 	}
 	if page.Nonce == "" {
 		page.Nonce = middleware.NoncePlaceholder
 	}
+	if page.Branding == nil {
+		page.Branding = s.branding
+	}
+	statusInfo := fmt.Sprintf("%d %s", status, page.T(http.StatusText(status)))
 	if page.Message == "" {
 		page.Message = statusInfo
 	}
@@ -347,51 +719,108 @@ func (s *Server) renderErrorPage(ctx context.Context, status int, template strin
 	return s.renderPage(ctx, template, page)
 }
 
+// tagModuleVersion tags the response for a page of modulePath@version, so that
+// it is invalidated in the page cache and the CDN when the module is
+// reprocessed.
+func tagModuleVersion(ctx context.Context, w http.ResponseWriter, modulePath, version string) {
+	middleware.TagCachedModule(ctx, modulePath)
+	cdn.TagModuleVersion(w, modulePath, version)
+}
+
+// invalidateModule removes the pages of modulePath from the page cache and
+// the CDN, after a change to what they show that does not come from
+// reprocessing the module. The pages expire anyway, so errors are only
+// logged.
+func (s *Server) invalidateModule(ctx context.Context, modulePath string) {
+	if s.cacheClient != nil {
+		if err := middleware.InvalidateModule(ctx, s.cacheClient, modulePath); err != nil {
+			log.Error(ctx, err)
+		}
+	}
+	if s.cdnPurger != nil {
+		if err := s.cdnPurger.Purge(ctx, cdn.ModuleKey(modulePath)); err != nil {
+			log.Error(ctx, err)
+		}
+	}
+}
+
 // servePage is used to execute all templates for a *Server.
+//
+// Large pages are written in chunks as the template executes. If the template
+// fails after the first chunk was written, the response is aborted, so that a
+// truncated page is neither shown as complete nor cached.
 func (s *Server) servePage(ctx context.Context, w http.ResponseWriter, templateName string, page interface{}) {
-	buf, err := s.renderPage(ctx, templateName, page)
-	if err != nil {
-		log.Errorf(ctx, "s.renderPage(%q, %+v): %v", templateName, page, err)
+	cw := &chunkWriter{w: w, buf: getBuffer()}
+	defer putBuffer(cw.buf)
+	if err := s.executePage(ctx, cw, templateName, page); err != nil {
+		middleware.RecordRequestError(ctx, fmt.Errorf("s.executePage(%q, %+v): %v", templateName, page, err))
+		if cw.streaming {
+			panic(http.ErrAbortHandler)
+		}
 		w.WriteHeader(http.StatusInternalServerError)
-		buf = s.errorPage
+		if _, err := w.Write(s.errorPage); err != nil {
+			middleware.RecordRequestError(ctx, fmt.Errorf("error writing error page to ResponseWriter: %v", err))
+		}
+		return
 	}
-	if _, err := io.Copy(w, bytes.NewReader(buf)); err != nil {
-		log.Errorf(ctx, "Error copying template %q buffer to ResponseWriter: %v", templateName, err)
-		w.WriteHeader(http.StatusInternalServerError)
+	if err := cw.flush(); err != nil {
+		middleware.RecordRequestError(ctx, fmt.Errorf("error copying template %q buffer to ResponseWriter: %v", templateName, err))
 	}
 }
 
-// renderPage executes the given templateName with page.
+// renderPage executes the given templateName with page, and returns the
+// result.
 func (s *Server) renderPage(ctx context.Context, templateName string, page interface{}) ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := s.executePage(ctx, buf, templateName, page); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), buf.Bytes()...), nil
+}
+
+// executePage executes the given templateName with page, writing to w.
+func (s *Server) executePage(ctx context.Context, w io.Writer, templateName string, page interface{}) error {
 	if s.devMode {
 		s.mu.Lock()
 		defer s.mu.Unlock()
 		var err error
-		s.templates, err = parsePageTemplates(s.templateDir)
+		s.templates, err = parsePageTemplates(s.staticFS)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing templates: %v", err)
+			return fmt.Errorf("error parsing templates: %v", err)
 		}
 	}
 
-	var buf bytes.Buffer
 	tmpl := s.templates[templateName]
 	if tmpl == nil {
-		return nil, fmt.Errorf("BUG: s.templates[%q] not found", templateName)
+		return fmt.Errorf("BUG: s.templates[%q] not found", templateName)
 	}
-	if err := tmpl.Execute(&buf, page); err != nil {
-		log.Errorf(ctx, "Error executing page template %q: %v", templateName, err)
-		return nil, err
+	return tmpl.Execute(w, page)
+}
 
+// serveFavicon serves the favicon from the static files.
+func (s *Server) serveFavicon(w http.ResponseWriter, r *http.Request) {
+	f, err := s.staticFS.Open("/img/favicon.ico")
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		http.NotFound(w, r)
+		return
 	}
-	return buf.Bytes(), nil
+	http.ServeContent(w, r, fi.Name(), fi.ModTime(), f)
 }
 
-// parsePageTemplates parses html templates contained in the given base
-// directory in order to generate a map of Name->*template.Template.
+// parsePageTemplates parses html templates contained in the html directory of
+// the static files in order to generate a map of Name->*template.Template.
 //
 // Separate templates are used so that certain contextual functions (e.g.
 // templateName) can be bound independently for each page.
-func parsePageTemplates(base string) (map[string]*template.Template, error) {
+func parsePageTemplates(fsys http.FileSystem) (map[string]*template.Template, error) {
+	const base = "/html"
 	htmlSets := [][]string{
 		{"index.tmpl"},
 		{"error.tmpl"},
@@ -399,6 +828,15 @@ func parsePageTemplates(base string) (map[string]*template.Template, error) {
 		{"search.tmpl"},
 		{"search_help.tmpl"},
 		{"license_policy.tmpl"},
+		{"report.tmpl"},
+		{"watchlist.tmpl"},
+		{"verify.tmpl"},
+		{"settings.tmpl"},
+		{"compare.tmpl"},
+		{"collections.tmpl"},
+		{"org.tmpl"},
+		{"browse.tmpl"},
+		{"doc_print.tmpl"},
 		{"overview.tmpl", "details.tmpl"},
 		{"subdirectories.tmpl", "details.tmpl"},
 		{"pkg_doc.tmpl", "details.tmpl"},
@@ -406,12 +844,14 @@ func parsePageTemplates(base string) (map[string]*template.Template, error) {
 		{"pkg_imports.tmpl", "details.tmpl"},
 		{"licenses.tmpl", "details.tmpl"},
 		{"versions.tmpl", "details.tmpl"},
+		{"files.tmpl", "details.tmpl"},
+		{"dependencies.tmpl", "details.tmpl"},
 		{"not_implemented.tmpl", "details.tmpl"},
 	}
 
 	templates := make(map[string]*template.Template)
 	for _, set := range htmlSets {
-		t, err := template.New("base.tmpl").Funcs(template.FuncMap{
+		t := template.New("base.tmpl").Funcs(template.FuncMap{
 			"add": func(i, j int) int { return i + j },
 			"pluralize": func(i int, s string) string {
 				if i == 1 {
@@ -422,23 +862,48 @@ func parsePageTemplates(base string) (map[string]*template.Template, error) {
 			"commaseparate": func(s []string) string {
 				return strings.Join(s, ", ")
 			},
-		}).ParseFiles(filepath.Join(base, "base.tmpl"))
-		if err != nil {
+		})
+		if err := parseFiles(t, fsys, path.Join(base, "base.tmpl")); err != nil {
 			return nil, fmt.Errorf("ParseFiles: %v", err)
 		}
-		helperGlob := filepath.Join(base, "helpers", "*.tmpl")
-		if _, err := t.ParseGlob(helperGlob); err != nil {
-			return nil, fmt.Errorf("ParseGlob(%q): %v", helperGlob, err)
+		helperDir := path.Join(base, "helpers")
+		helpers, err := assets.Glob(fsys, helperDir, "*.tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("Glob(%q): %v", helperDir, err)
+		}
+		if err := parseFiles(t, fsys, helpers...); err != nil {
+			return nil, fmt.Errorf("ParseFiles(%v): %v", helpers, err)
 		}
 
 		var files []string
 		for _, f := range set {
-			files = append(files, filepath.Join(base, "pages", f))
+			files = append(files, path.Join(base, "pages", f))
 		}
-		if _, err := t.ParseFiles(files...); err != nil {
+		if err := parseFiles(t, fsys, files...); err != nil {
 			return nil, fmt.Errorf("ParseFiles(%v): %v", files, err)
 		}
 		templates[set[0]] = t
 	}
 	return templates, nil
 }
+
+// parseFiles is like t.ParseFiles, but reads the files from fsys.
+func parseFiles(t *template.Template, fsys http.FileSystem, filenames ...string) error {
+	for _, filename := range filenames {
+		data, err := assets.ReadFile(fsys, filename)
+		if err != nil {
+			return err
+		}
+		// As with ParseFiles, templates are named by the base names of
+		// their files.
+		name := path.Base(filename)
+		tmpl := t
+		if name != t.Name() {
+			tmpl = t.New(name)
+		}
+		if _, err := tmpl.Parse(string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -17,10 +17,13 @@ import (
 
 	"golang.org/x/net/html"
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/config"
 	"golang.org/x/pkgsite/internal/experiment"
+	"golang.org/x/pkgsite/internal/i18n"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/middleware"
 	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/prefs"
 	"golang.org/x/pkgsite/internal/proxy"
 	"golang.org/x/pkgsite/internal/queue"
 	"golang.org/x/pkgsite/internal/source"
@@ -854,6 +857,98 @@ func TestServerErrors(t *testing.T) {
 	}
 }
 
+func TestTranslations(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	s, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	if err := testDB.InsertModule(ctx, sample.DefaultModule()); err != nil {
+		t.Fatal(err)
+	}
+	catalog, err := i18n.NewCatalog(map[string]map[string]string{
+		"fr": {"Imported By": "Importé par", "Not Found": "Introuvable"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.catalog = catalog
+
+	etags := map[string]string{}
+	for _, test := range []struct {
+		name, path, acceptLanguage string
+		want, notWant              string
+	}{
+		{"default", sample.PackagePath, "", `<html lang="en">`, "Importé par"},
+		{"no match", sample.PackagePath, "de", `<html lang="en">`, "Importé par"},
+		{"french", sample.PackagePath, "fr-CA, en;q=0.5", `<html lang="fr">`, "Imported By"},
+		{"error page", "/invalid-page", "fr", "404 Introuvable", "Not Found"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", test.path, nil)
+			if test.acceptLanguage != "" {
+				r.Header.Set("Accept-Language", test.acceptLanguage)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			body := w.Body.String()
+			if !strings.Contains(body, test.want) {
+				t.Errorf("page does not contain %q", test.want)
+			}
+			if strings.Contains(body, test.notWant) {
+				t.Errorf("page contains %q", test.notWant)
+			}
+			if got, want := w.Header().Get("Vary"), "Accept-Language"; got != want {
+				t.Errorf("Vary = %q, want %q", got, want)
+			}
+			etags[test.name] = w.Header().Get("ETag")
+		})
+	}
+	// Pages in different languages have different ETags.
+	if etags["default"] == "" || etags["default"] != etags["no match"] || etags["default"] == etags["french"] {
+		t.Errorf("got ETags %v, want the same for default and no match, and another for french", etags)
+	}
+}
+
+func TestTheme(t *testing.T) {
+	s, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	s.prefs = prefs.NewStore([]byte("key"))
+	w := httptest.NewRecorder()
+	s.prefs.Write(w, &prefs.Prefs{Theme: "light"})
+	lightCookies := w.Result().Cookies()
+
+	for _, test := range []struct {
+		name    string
+		hint    string
+		cookies []*http.Cookie
+		want    string
+	}{
+		{"none", "", nil, `<body class="Site">`},
+		{"hint", `"dark"`, nil, `<body class="Site Site--dark">`},
+		{"bad hint", `"purple"`, nil, `<body class="Site">`},
+		{"preference", `"dark"`, lightCookies, `<body class="Site Site--light">`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/search-help", nil)
+			if test.hint != "" {
+				r.Header.Set(colorSchemeHeader, test.hint)
+			}
+			for _, c := range test.cookies {
+				r.AddCookie(c)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if !strings.Contains(w.Body.String(), test.want) {
+				t.Errorf("page does not contain %q", test.want)
+			}
+			if !contains(w.Header()["Vary"], colorSchemeHeader) {
+				t.Errorf("Vary = %q, want it to contain %q", w.Header()["Vary"], colorSchemeHeader)
+			}
+		})
+	}
+}
+
 func mustRequest(urlPath string, t *testing.T) *http.Request {
 	t.Helper()
 	r, err := http.NewRequest(http.MethodGet, "http://localhost"+urlPath, nil)
@@ -886,6 +981,26 @@ func TestDetailsTTL(t *testing.T) {
 	}
 }
 
+func TestRouteClass(t *testing.T) {
+	for _, test := range []struct {
+		route, url, want string
+	}{
+		{"/static/", "/static/css/stylesheet.css", "static"},
+		{"/favicon.ico", "/favicon.ico", "static"},
+		{"/search", "/search?q=foo", "search"},
+		{"/", "/", ""},
+		{"/", "/host.com/module/suffix", "latest"},
+		{"/", "/host.com/module@master/suffix", "latest"},
+		{"/", "/host.com/module@v1.2.3/suffix?tab=versions", "pinned"},
+		{"/", "/mod/host.com/module@v1.2.3", "pinned"},
+		{"/fetch/", "/fetch/host.com/module", ""},
+	} {
+		if got := routeClass(test.route, mustRequest(test.url, t)); got != test.want {
+			t.Errorf("routeClass(%q, %q) = %q, want %q", test.route, test.url, got, test.want)
+		}
+	}
+}
+
 func TestTagRoute(t *testing.T) {
 	mustRequest := func(url string) *http.Request {
 		req, err := http.NewRequest("GET", url, nil)
@@ -958,3 +1073,38 @@ func newTestServer(t *testing.T, proxyModules []*proxy.TestModule, experimentNam
 		postgres.ResetTestDB(testDB, t)
 	}
 }
+
+func TestCachePoliciesWithSignIn(t *testing.T) {
+	policies := map[string]config.CachePolicy{
+		"static": {CacheControl: "public, max-age=3600", SurrogateControl: "max-age=86400"},
+	}
+	for _, test := range []struct {
+		signIn           bool
+		wantCacheControl string
+		wantSurrogate    string
+	}{
+		{false, "public, max-age=3600", "max-age=86400"},
+		{true, "private", ""},
+	} {
+		s, err := NewServer(ServerConfig{
+			DataSource:     testDB,
+			StaticPath:     "../../content/static",
+			ThirdPartyPath: "../../third_party",
+			CachePolicies:  policies,
+			SignIn:         test.signIn,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		mux := http.NewServeMux()
+		s.Install(mux.Handle, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/static/css/stylesheet.css", nil))
+		if got := w.Header().Get("Cache-Control"); got != test.wantCacheControl {
+			t.Errorf("signIn=%t: got Cache-Control %q, want %q", test.signIn, got, test.wantCacheControl)
+		}
+		if got := w.Header().Get("Surrogate-Control"); got != test.wantSurrogate {
+			t.Errorf("signIn=%t: got Surrogate-Control %q, want %q", test.signIn, got, test.wantSurrogate)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"fmt"
+	"net/http"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/prefs"
+)
+
+// docPlatforms are the GOOS values that documentation may be rendered for,
+// in the order the worker tries them (see goEnvs in internal/fetch).
+var docPlatforms = []string{"linux", "windows", "darwin", "js"}
+
+// settingsPage is the page where users set their preferences.
+type settingsPage struct {
+	basePage
+	Prefs     prefs.Prefs
+	Tabs      []TabSettings
+	Platforms []string
+	Themes    []string
+	// Saved is whether the preferences were just saved.
+	Saved bool
+}
+
+// serveSettings serves the preferences of the user, and saves the ones
+// posted with the form.
+func (s *Server) serveSettings(w http.ResponseWriter, r *http.Request) (err error) {
+	defer derrors.Wrap(&err, "serveSettings(%q)", r.URL.Path)
+
+	if s.prefs == nil {
+		return &serverError{status: http.StatusNotFound}
+	}
+	if r.Method == http.MethodPost {
+		p := &prefs.Prefs{
+			Tab:   r.FormValue("tab"),
+			GOOS:  r.FormValue("goos"),
+			Theme: r.FormValue("theme"),
+		}
+		if err := validatePrefs(p); err != nil {
+			return &serverError{status: http.StatusBadRequest, err: err}
+		}
+		s.prefs.Write(w, p)
+		http.Redirect(w, r, "/settings?saved=1", http.StatusSeeOther)
+		return nil
+	}
+
+	page := &settingsPage{
+		basePage:  s.newBasePage(r, "Settings"),
+		Prefs:     prefs.FromContext(r.Context()),
+		Tabs:      packageTabSettings,
+		Platforms: docPlatforms,
+		Themes:    prefs.Themes,
+		Saved:     r.FormValue("saved") != "",
+	}
+	page.NoIndex = true
+	s.servePage(r.Context(), w, "settings.tmpl", page)
+	return nil
+}
+
+// validatePrefs returns an error if a preference of p is not one of the
+// choices of the settings page. Empty preferences are valid.
+func validatePrefs(p *prefs.Prefs) error {
+	if _, ok := packageTabLookup[p.Tab]; p.Tab != "" && !ok {
+		return fmt.Errorf("unknown tab %q", p.Tab)
+	}
+	if p.GOOS != "" && !contains(docPlatforms, p.GOOS) {
+		return fmt.Errorf("unknown GOOS %q", p.GOOS)
+	}
+	if p.Theme != "" && !contains(prefs.Themes, p.Theme) {
+		return fmt.Errorf("unknown theme %q", p.Theme)
+	}
+	return nil
+}
+
+func contains(a []string, s string) bool {
+	for _, x := range a {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
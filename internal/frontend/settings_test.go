@@ -0,0 +1,89 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/prefs"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestSettings(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	s, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	if err := testDB.InsertModule(ctx, sample.DefaultModule()); err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/settings", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	get := func(path string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// Without a key, there are no settings.
+	if w := get("/settings", nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET /settings without key: got %d, want 404", w.Code)
+	}
+	s.prefs = prefs.NewStore([]byte("key"))
+
+	if w := post(url.Values{"tab": {"nope"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown tab: got %d, want 400", w.Code)
+	}
+	w := post(url.Values{"tab": {"imports"}, "theme": {"dark"}})
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("POST /settings: got %d, want 303", w.Code)
+	}
+	cookies := w.Result().Cookies()
+
+	w = get("/settings", cookies)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /settings: got %d, want 200", w.Code)
+	}
+	if want := `class="Site Site--dark"`; !strings.Contains(w.Body.String(), want) {
+		t.Errorf("settings page does not contain %q", want)
+	}
+	if got, want := w.Header().Get("Cache-Control"), "private"; got != want {
+		t.Errorf("Cache-Control = %q, want %q", got, want)
+	}
+
+	for _, test := range []struct {
+		name    string
+		cookies []*http.Cookie
+		want    string
+	}{
+		{"default", nil, "?tab=doc"},
+		{"preferred", cookies, "?tab=imports"},
+	} {
+		w := get("/"+sample.PackagePath, test.cookies)
+		if w.Code != http.StatusFound {
+			t.Errorf("%s: got %d, want 302", test.name, w.Code)
+			continue
+		}
+		if got := w.Header().Get("Location"); !strings.HasSuffix(got, test.want) {
+			t.Errorf("%s: redirected to %q, want ...%s", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/similar"
+)
+
+// serveSimilar handles requests for /api/v1/similar/<path>[@<version>],
+// returning the packages most similar to the package, most similar first.
+// The version only selects the package: similar packages are computed for
+// the latest version.
+func (s *Server) serveSimilar(w http.ResponseWriter, r *http.Request) (_ interface{}, err error) {
+	defer derrors.Wrap(&err, "serveSimilar(%q)", r.URL.Path)
+
+	ctx := r.Context()
+	fullPath, modulePath, version, err := parseAPIPath(strings.TrimPrefix(r.URL.Path, apiPrefix+"similar"))
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := s.ds.GetPackage(ctx, fullPath, modulePath, version, internal.MinimalFields)
+	if err != nil {
+		return nil, err
+	}
+	db, ok := s.ds.(*postgres.DB)
+	if !ok {
+		// Only the database stores similar packages.
+		return []*similar.Package{}, nil
+	}
+	pkgs, err := db.GetSimilarPackages(ctx, pkg.Path)
+	if err != nil {
+		return nil, err
+	}
+	if pkgs == nil {
+		// Serve an empty list rather than null.
+		pkgs = []*similar.Package{}
+	}
+	return pkgs, nil
+}
+
+// similarPackages returns the packages most similar to the package at
+// pkgPath, for its overview. Similar packages are not worth failing a page
+// for, so errors are only logged.
+func similarPackages(ctx context.Context, ds internal.DataSource, pkgPath string) []*similar.Package {
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		return nil
+	}
+	pkgs, err := db.GetSimilarPackages(ctx, pkgPath)
+	if err != nil {
+		log.Error(ctx, err)
+		return nil
+	}
+	return pkgs
+}
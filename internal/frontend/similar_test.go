@@ -0,0 +1,67 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/pkgsite/internal/similar"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestSimilarPackages(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	for _, path := range []string{"a.com/zap", "b.com/logrus", "c.com/app"} {
+		m := sample.Module(path, sample.VersionString, "")
+		m.LegacyPackages[0].Imports = nil
+		if path == "c.com/app" {
+			m.LegacyPackages[0].Imports = []string{"a.com/zap", "b.com/logrus"}
+		}
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := testDB.UpdateSearchDocumentsImportedByCount(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testDB.ComputeSimilarPackages(ctx, time.Now(), 10); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/similar/a.com/zap", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	var got []*similar.Package
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Path != "b.com/logrus" {
+		t.Errorf("got %s, want b.com/logrus only", w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/similar/a.com/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown package: got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/a.com/zap?tab=overview", nil))
+	if body := w.Body.String(); !strings.Contains(body, "Overview-similar") || !strings.Contains(body, `href="/b.com/logrus"`) {
+		t.Errorf("overview has no similar packages:\n%s", body)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/golang/groupcache/lru"
+	"golang.org/x/pkgsite/internal/middleware"
+)
+
+const (
+	// staleCacheSize is the maximum total size of the pages kept in the
+	// stale cache.
+	staleCacheSize = 64 << 20
+
+	// maxStalePageSize is the size of the largest page kept in the stale
+	// cache.
+	maxStalePageSize = 1 << 20
+)
+
+// staleMainTag is the opening tag of the main element in base.tmpl. The
+// staleness banner is inserted after it.
+var staleMainTag = []byte(`<main class="Site-content">`)
+
+// A staleCache holds copies of recently rendered pages, to be served when the
+// database is unavailable. The least recently used pages are evicted when
+// their total size is over the size of the cache.
+type staleCache struct {
+	maxSize int
+
+	mu    sync.Mutex
+	cache *lru.Cache
+	size  int // total size of the bodies in cache
+}
+
+type stalePage struct {
+	body      []byte
+	createdAt time.Time
+}
+
+// staleKey returns the key of the page served to r in a staleCache. Pages
+// are kept separately for each theme of the color scheme hint.
+func staleKey(r *http.Request) string {
+	return r.URL.String() + " " + colorSchemeHint(r)
+}
+
+// newStaleCache returns a staleCache that keeps at most maxSize bytes of
+// pages.
+func newStaleCache(maxSize int) *staleCache {
+	c := &staleCache{maxSize: maxSize, cache: lru.New(0)}
+	c.cache.OnEvicted = func(_ lru.Key, v interface{}) {
+		c.size -= len(v.(*stalePage).body)
+	}
+	return c
+}
+
+// put saves the page recorded by rec under key, if it was served
+// successfully, was not streamed and is not too large.
+//
+// Pages are rendered with middleware.NoncePlaceholder in place of the nonce
+// of their Content-Security-Policy, which SecureHeaders substitutes when the
+// page is written, so a replayed page gets the nonce of the response that
+// replays it. In case the nonce was written anyway, it is turned back into the
+// placeholder.
+func (c *staleCache) put(key string, rec *staleRecorder) {
+	if rec.tooLarge || rec.flushed || (rec.status != 0 && rec.status != http.StatusOK) {
+		return
+	}
+	body := rec.buf.Bytes()
+	if nonce := cspNonce(rec.Header().Get("Content-Security-Policy")); nonce != "" {
+		body = bytes.ReplaceAll(body, []byte(nonce), []byte(middleware.NoncePlaceholder))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.Remove(key)
+	c.cache.Add(key, &stalePage{body: body, createdAt: time.Now()})
+	c.size += len(body)
+	for c.size > c.maxSize {
+		c.cache.RemoveOldest()
+	}
+}
+
+var cspNonceRegexp = regexp.MustCompile(`'nonce-([^']+)'`)
+
+// cspNonce returns the nonce in the Content-Security-Policy header value csp,
+// or the empty string if there is none.
+func cspNonce(csp string) string {
+	m := cspNonceRegexp.FindStringSubmatch(csp)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// get returns the page saved under key, if any.
+func (c *staleCache) get(key string) (*stalePage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return v.(*stalePage), true
+}
+
+// serveStalePage writes p to w, with a banner explaining that it may be out of
+// date.
+func serveStalePage(w http.ResponseWriter, p *stalePage) {
+	banner := fmt.Sprintf(`<div class="StaleBanner">The database is temporarily unavailable. `+
+		`This page was saved at %s and may be out of date.</div>`,
+		p.createdAt.UTC().Format(time.RFC1123))
+	body := bytes.Replace(p.body, staleMainTag, append(append([]byte{}, staleMainTag...), banner...), 1)
+	w.Header().Set("Warning", `110 - "Response is Stale"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// staleRecorder is an http.ResponseWriter that keeps a copy of the response
+// for the stale cache. It stops keeping it once the response is flushed:
+// streamed responses are not worth replaying.
+type staleRecorder struct {
+	http.ResponseWriter
+	status   int
+	buf      bytes.Buffer
+	tooLarge bool
+	flushed  bool
+}
+
+func (r *staleRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *staleRecorder) Write(b []byte) (int, error) {
+	if !r.tooLarge && !r.flushed {
+		if r.buf.Len()+len(b) > maxStalePageSize {
+			r.tooLarge = true
+			r.buf = bytes.Buffer{}
+		} else {
+			r.buf.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher, so that handlers can stream responses.
+func (r *staleRecorder) Flush() {
+	r.flushed = true
+	r.buf = bytes.Buffer{}
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/middleware"
+)
+
+func TestStaleCache(t *testing.T) {
+	c := newStaleCache(10 * maxStalePageSize)
+	record := func(status int, body string) *staleRecorder {
+		rec := &staleRecorder{ResponseWriter: httptest.NewRecorder()}
+		if status != 0 {
+			rec.WriteHeader(status)
+		}
+		fmt.Fprint(rec, body)
+		return rec
+	}
+
+	c.put("/ok", record(0, `<main class="Site-content">net/http</main>`))
+	c.put("/notfound", record(http.StatusNotFound, "not found"))
+	c.put("/large", record(0, strings.Repeat("x", maxStalePageSize+1)))
+
+	for _, key := range []string{"/notfound", "/large", "/missing"} {
+		if _, ok := c.get(key); ok {
+			t.Errorf("get(%q): got page, want none", key)
+		}
+	}
+	p, ok := c.get("/ok")
+	if !ok {
+		t.Fatal(`get("/ok"): no page`)
+	}
+	w := httptest.NewRecorder()
+	serveStalePage(w, p)
+	if w.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	if w.Header().Get("Warning") == "" {
+		t.Error("missing Warning header")
+	}
+	body := w.Body.String()
+	if !strings.HasPrefix(body, `<main class="Site-content"><div class="StaleBanner">`) ||
+		!strings.HasSuffix(body, "</div>net/http</main>") {
+		t.Errorf("got body %q, want banner inserted into main", body)
+	}
+}
+
+func TestStaleCacheSize(t *testing.T) {
+	c := newStaleCache(10)
+	record := func(body string) *staleRecorder {
+		rec := &staleRecorder{ResponseWriter: httptest.NewRecorder()}
+		fmt.Fprint(rec, body)
+		return rec
+	}
+	c.put("/a", record("aaaa"))
+	c.put("/b", record("bbbb"))
+	c.put("/a", record("aaaa")) // replacing a page does not count it twice
+	c.put("/c", record("cccc")) // evicts /b, the least recently used
+	for key, want := range map[string]bool{"/a": true, "/b": false, "/c": true} {
+		if _, got := c.get(key); got != want {
+			t.Errorf("get(%q): got page %t, want %t", key, got, want)
+		}
+	}
+	if c.size != 8 {
+		t.Errorf("got size %d, want 8", c.size)
+	}
+}
+
+func TestStaleRecorderFlush(t *testing.T) {
+	c := newStaleCache(maxStalePageSize)
+	w := httptest.NewRecorder()
+	rec := &staleRecorder{ResponseWriter: w}
+	var _ http.Flusher = rec
+	fmt.Fprint(rec, "streamed")
+	rec.Flush()
+	if !w.Flushed {
+		t.Error("Flush was not passed on")
+	}
+	c.put("/stream", rec)
+	if _, ok := c.get("/stream"); ok {
+		t.Error("got page for a streamed response, want none")
+	}
+}
+
+func TestStaleCacheNonce(t *testing.T) {
+	c := newStaleCache(maxStalePageSize)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stale" {
+			p, _ := c.get("/")
+			serveStalePage(w, p)
+			return
+		}
+		// Write the nonce itself, rather than the placeholder, so that the
+		// cache has to remove it.
+		rec := &staleRecorder{ResponseWriter: w}
+		fmt.Fprintf(rec, `<script nonce="%s"></script>`, cspNonce(w.Header().Get("Content-Security-Policy")))
+		c.put("/", rec)
+	}
+	h := middleware.SecureHeaders()(http.HandlerFunc(handler))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/stale", nil))
+	nonce := cspNonce(w.Header().Get("Content-Security-Policy"))
+	if want := `<script nonce="` + nonce + `">`; nonce == "" || !strings.Contains(w.Body.String(), want) {
+		t.Errorf("got body %q, want it to contain %q", w.Body.String(), want)
+	}
+}
@@ -32,6 +32,12 @@ func TestParseStdLibURLPath(t *testing.T) {
 			wantPath:    "cmd/go",
 			wantVersion: "v1.13.0-beta.1",
 		},
+		{
+			name:        "package at master",
+			url:         "/cmd/go@master",
+			wantPath:    "cmd/go",
+			wantVersion: internal.MasterVersion,
+		},
 		{
 			name:        "std",
 			url:         "/std@go1.13",
@@ -0,0 +1,64 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// supersedeAction supersedes, or for the "unsupersede" action stops
+// superseding, the package posted with r, which must be in the module at
+// modulePath. The user with the given email address must be a verified
+// owner of the module.
+func supersedeAction(r *http.Request, db *postgres.DB, email, modulePath, action string) error {
+	ctx := r.Context()
+	pkgPath := strings.TrimSpace(r.FormValue("package_path"))
+	if pkgPath != modulePath && !strings.HasPrefix(pkgPath, modulePath+"/") {
+		return &serverError{
+			status: http.StatusBadRequest,
+			epage:  &errorPage{Message: fmt.Sprintf("%q is not in module %q.", pkgPath, modulePath)},
+		}
+	}
+	var err error
+	if action == "unsupersede" {
+		err = db.UnsupersedePackage(ctx, pkgPath, email)
+	} else {
+		err = db.SupersedePackage(ctx, pkgPath, strings.TrimSpace(r.FormValue("superseded_by")), email)
+	}
+	if errors.Is(err, derrors.InvalidArgument) || errors.Is(err, derrors.NotFound) {
+		return &serverError{status: derrors.ToHTTPStatus(err), err: err}
+	}
+	if err != nil {
+		return err
+	}
+	log.Infof(ctx, "%s: %s %s", email, action, pkgPath)
+	return nil
+}
+
+// supersededBy returns the path of the package that supersedes the package
+// at pkgPath, or "" if it is not superseded. The banner is not worth failing a
+// page for, so errors are only logged.
+func (s *Server) supersededBy(ctx context.Context, pkgPath string) string {
+	db, ok := s.ds.(*postgres.DB)
+	if !ok {
+		return ""
+	}
+	path, err := db.GetSupersededBy(ctx, pkgPath)
+	if err != nil {
+		if !errors.Is(err, derrors.NotFound) {
+			log.Error(ctx, err)
+		}
+		return ""
+	}
+	return path
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// A Symbol is an identifier in the documentation of a package, as served by
+// the symbols API endpoint.
+type Symbol struct {
+	// Name is the name of the identifier, qualified by its type for fields and
+	// methods, like "Client.Do".
+	Name string `json:"name"`
+	// Kind is one of "constant", "variable", "function", "type", "method" and
+	// "field".
+	Kind string `json:"kind"`
+	// Anchor is the id of the element that documents the identifier on the
+	// package page.
+	Anchor string `json:"anchor"`
+}
+
+// symbolsURL returns the URL of the symbols of the package with the given
+// path, at modulePath@version.
+func symbolsURL(pkgPath, modulePath, version string) string {
+	return apiPrefix + "symbols" + constructPackageURL(pkgPath, modulePath, linkVersion(version, modulePath))
+}
+
+// serveSymbols handles requests for /api/v1/symbols/<path>[@<version>],
+// returning the symbols of the package, sorted by name.
+func (s *Server) serveSymbols(w http.ResponseWriter, r *http.Request) (_ interface{}, err error) {
+	defer derrors.Wrap(&err, "serveSymbols(%q)", r.URL.Path)
+
+	ctx := r.Context()
+	fullPath, modulePath, version, err := parseAPIPath(strings.TrimPrefix(r.URL.Path, apiPrefix+"symbols"))
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := s.ds.GetPackage(ctx, fullPath, modulePath, version, internal.MinimalFields)
+	if err != nil {
+		return nil, err
+	}
+	return s.packageSymbols(ctx, pkg)
+}
+
+// packageSymbols returns the symbols of pkg, sorted by name regardless of
+// case, from the symbols table that the worker fills when it processes the
+// module. Packages that are not redistributable have no symbols.
+func (s *Server) packageSymbols(ctx context.Context, pkg *internal.LegacyVersionedPackage) ([]*Symbol, error) {
+	symbols := []*Symbol{}
+	db, ok := s.ds.(*postgres.DB)
+	if !ok {
+		// Only the database stores symbols.
+		return symbols, nil
+	}
+	syms, err := db.GetSymbols(ctx, pkg.Path, pkg.ModulePath, pkg.Version)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range syms {
+		symbols = append(symbols, &Symbol{Name: s.Name, Kind: s.Kind, Anchor: s.Name})
+	}
+	return symbols, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestServeSymbols(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	m := sample.Module("example.com/a", sample.VersionString, "pkg")
+	m.LegacyPackages[0].DocumentationHTML = `<section class="Documentation-constants">
+<pre>
+const (
+	<span id="MaxSize" data-kind="constant"></span>MaxSize = 10
+)
+</pre>
+</section>
+<h3 id="Client" data-kind="type">type Client</h3>
+<span id="Client.Timeout" data-kind="field"></span>
+<h3 id="Client.Do" data-kind="method">func (c *Client) Do</h3>`
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/symbols/example.com/a/pkg", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got code %d, want 200", w.Code)
+	}
+	var got []*Symbol
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []*Symbol{
+		{Name: "Client", Kind: "type", Anchor: "Client"},
+		{Name: "Client.Do", Kind: "method", Anchor: "Client.Do"},
+		{Name: "Client.Timeout", Kind: "field", Anchor: "Client.Timeout"},
+		{Name: "MaxSize", Kind: "constant", Anchor: "MaxSize"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSymbolsURL(t *testing.T) {
+	for _, test := range []struct {
+		pkgPath, modulePath, version, want string
+	}{
+		{"github.com/a/b", "github.com/a/b", "v1.2.3", "/api/v1/symbols/github.com/a/b@v1.2.3"},
+		{"github.com/a/b/c", "github.com/a/b", "v1.2.3", "/api/v1/symbols/github.com/a/b@v1.2.3/c"},
+		{"net/http", stdlib.ModulePath, "v1.14.0", "/api/v1/symbols/net/http@go1.14"},
+	} {
+		if got := symbolsURL(test.pkgPath, test.modulePath, test.version); got != test.want {
+			t.Errorf("symbolsURL(%q, %q, %q) = %q, want %q",
+				test.pkgPath, test.modulePath, test.version, got, test.want)
+		}
+	}
+}
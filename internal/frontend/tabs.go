@@ -108,6 +108,18 @@ var (
 			DisplayName:  "Licenses",
 			TemplateName: "licenses.tmpl",
 		},
+		{
+			Name:              "files",
+			AlwaysShowDetails: true,
+			DisplayName:       "Files",
+			TemplateName:      "files.tmpl",
+		},
+		{
+			Name:              "dependencies",
+			AlwaysShowDetails: true,
+			DisplayName:       "Dependencies",
+			TemplateName:      "dependencies.tmpl",
+		},
 	}
 	moduleTabLookup = make(map[string]TabSettings)
 )
@@ -140,12 +152,25 @@ func init() {
 	}
 }
 
+// packageTabFields returns the large fields of a package that
+// fetchDetailsForPackage needs for tab, so that other tabs do not read them
+// from the data store.
+func packageTabFields(tab string) internal.FieldSet {
+	switch tab {
+	case "doc":
+		return internal.WithDocumentationHTML
+	case "overview":
+		return internal.WithReadmeContents
+	}
+	return internal.MinimalFields
+}
+
 // fetchDetailsForPackage returns tab details by delegating to the correct detail
 // handler.
 func fetchDetailsForPackage(ctx context.Context, r *http.Request, tab string, ds internal.DataSource, pkg *internal.LegacyVersionedPackage) (interface{}, error) {
 	switch tab {
 	case "doc":
-		return fetchDocumentationDetails(pkg), nil
+		return fetchDocumentationDetails(ctx, pkg), nil
 	case "versions":
 		return fetchPackageVersionsDetails(ctx, ds, pkg.Path, pkg.V1Path, pkg.ModulePath)
 	case "subdirectories":
@@ -162,7 +187,10 @@ func fetchDetailsForPackage(ctx context.Context, r *http.Request, tab string, ds
 	case "licenses":
 		return fetchPackageLicensesDetails(ctx, ds, pkg.Path, pkg.ModulePath, pkg.Version)
 	case "overview":
-		return fetchPackageOverviewDetails(ctx, pkg, urlIsVersioned(r.URL)), nil
+		overview := fetchPackageOverviewDetails(ctx, pkg, urlIsVersioned(r.URL))
+		overview.SimilarPackages = similarPackages(ctx, ds, pkg.Path)
+		overview.UsedBy = usedBy(ctx, ds, pkg.Path, pkg.ModulePath)
+		return overview, nil
 	}
 	return nil, fmt.Errorf("BUG: unable to fetch details: unknown tab %q", tab)
 }
@@ -173,7 +201,7 @@ func fetchDetailsForVersionedDirectory(ctx context.Context, r *http.Request, tab
 	ds internal.DataSource, vdir *internal.VersionedDirectory) (interface{}, error) {
 	switch tab {
 	case "doc":
-		return fetchDocumentationDetailsNew(vdir.Package.Documentation), nil
+		return fetchDocumentationDetailsNew(ctx, vdir), nil
 	case "versions":
 		return fetchPackageVersionsDetails(ctx, ds, vdir.Path, vdir.V1Path, vdir.ModulePath)
 	case "subdirectories":
@@ -190,7 +218,10 @@ func fetchDetailsForVersionedDirectory(ctx context.Context, r *http.Request, tab
 	case "licenses":
 		return fetchPackageLicensesDetails(ctx, ds, vdir.Path, vdir.ModulePath, vdir.Version)
 	case "overview":
-		return fetchPackageOverviewDetailsNew(ctx, vdir, urlIsVersioned(r.URL)), nil
+		overview := fetchPackageOverviewDetailsNew(ctx, vdir, urlIsVersioned(r.URL))
+		overview.SimilarPackages = similarPackages(ctx, ds, vdir.Path)
+		overview.UsedBy = usedBy(ctx, ds, vdir.Path, vdir.ModulePath)
+		return overview, nil
 	}
 	return nil, fmt.Errorf("BUG: unable to fetch details: unknown tab %q", tab)
 }
@@ -209,10 +240,22 @@ func fetchDetailsForModule(ctx context.Context, r *http.Request, tab string, ds
 		return &LicensesDetails{Licenses: transformLicenses(mi.ModulePath, mi.Version, licenses)}, nil
 	case "versions":
 		return fetchModuleVersionsDetails(ctx, ds, mi)
+	case "files":
+		return fetchFilesDetails(ctx, ds, mi.ModulePath, mi.Version)
+	case "dependencies":
+		return fetchDependenciesDetails(ctx, ds, mi, r.FormValue("from"))
 	case "overview":
-		// TODO(b/138448402): implement remaining module views.
 		readme := &internal.Readme{Filepath: mi.LegacyReadmeFilePath, Contents: mi.LegacyReadmeContents}
-		return constructOverviewDetails(ctx, &mi.ModuleInfo, readme, mi.IsRedistributable, urlIsVersioned(r.URL)), nil
+		overview := constructOverviewDetails(ctx, &mi.ModuleInfo, readme, mi.IsRedistributable, urlIsVersioned(r.URL))
+		summary, err := fetchModuleSummary(ctx, ds, mi, licenses, urlIsVersioned(r.URL))
+		if err != nil {
+			return nil, err
+		}
+		overview.Summary = summary
+		if err := addReleaseNotes(ctx, ds, mi, overview); err != nil {
+			return nil, err
+		}
+		return overview, nil
 	}
 	return nil, fmt.Errorf("BUG: unable to fetch details: unknown tab %q", tab)
 }
@@ -230,7 +273,7 @@ func constructDetailsForDirectory(r *http.Request, tab string, dir *internal.Leg
 		// fetchDetailsForPackage. However, since we already have the directory
 		// and licenses info, it doesn't make sense to call
 		// postgres.GetDirectory again.
-		return createDirectory(dir, licensesToMetadatas(licenses), false)
+		return createDirectory(r.Context(), dir, licensesToMetadatas(licenses), false)
 	case "licenses":
 		return &LicensesDetails{Licenses: transformLicenses(dir.ModulePath, dir.Version, licenses)}, nil
 	}
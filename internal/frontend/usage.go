@@ -0,0 +1,35 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// maxUsedBy is the number of usage snippets shown on the overview of a
+// package.
+const maxUsedBy = 5
+
+// usedBy returns snippets of the source of the most imported packages of
+// other modules than modulePath that call the package at pkgPath, for its
+// overview. The snippets are not worth failing a page for, so errors are only
+// logged.
+func usedBy(ctx context.Context, ds internal.DataSource, pkgPath, modulePath string) []*internal.UsageSnippet {
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		// Only the database stores the snippets found at fetch time.
+		return nil
+	}
+	snippets, err := db.GetUsageSnippets(ctx, pkgPath, modulePath, maxUsedBy)
+	if err != nil {
+		log.Error(ctx, err)
+		return nil
+	}
+	return snippets
+}
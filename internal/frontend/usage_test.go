@@ -0,0 +1,45 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestUsedBy(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	if err := testDB.InsertModule(ctx, sample.Module("a.com/zap", sample.VersionString, "")); err != nil {
+		t.Fatal(err)
+	}
+	m := sample.Module("b.com/app", sample.VersionString, "")
+	m.LegacyPackages[0].Imports = []string{"a.com/zap"}
+	m.LegacyPackages[0].Usages = []*internal.UsageSnippet{{
+		ImportPath: "a.com/zap",
+		Code:       "logger, err := zap.NewProduction()",
+		URL:        "https://b.com/app/main.go#L10",
+	}}
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/a.com/zap?tab=overview", nil))
+	body := w.Body.String()
+	for _, want := range []string{"Overview-usedBy", `href="/b.com/app"`, "logger, err := zap.NewProduction()", `href="https://b.com/app/main.go#L10"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("overview does not contain %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/abuse"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/verify"
+)
+
+// reprocessInterval is how often owners may have the latest version of their
+// module processed again. Requests within the same interval are merged into
+// one fetch task.
+const reprocessInterval = time.Hour
+
+// verifyPage is the page where signed-in users verify that they own a module,
+// and where verified owners act on it.
+type verifyPage struct {
+	basePage
+	ModulePath string
+	Email      string
+	// Verification is the request of the user to be verified as an owner of
+	// the module, or nil if the user has not asked yet.
+	Verification *verify.Verification
+	// FilePath and FileURL are the path of the verification file in the
+	// repository, and the URL it is read from. FileURL is empty if the
+	// code host of the module does not serve files.
+	FilePath string
+	FileURL  string
+	// DNSName is the name of the TXT record for vanity import paths, and
+	// DNSRecord its content.
+	DNSName   string
+	DNSRecord string
+	// Failed is the method of the check that just failed, if any.
+	Failed string
+	// Reprocessed is whether the module was just scheduled to be processed
+	// again.
+	Reprocessed bool
+	// Supersessions are those of the packages of the module, shown to
+	// verified owners.
+	Supersessions []*abuse.Supersession
+}
+
+// serveVerify serves the verification page of a module for the signed-in
+// user, and performs the action posted with its forms: starting the
+// verification, checking it, or, for verified owners, reprocessing the
+// latest version of the module and superseding its packages.
+func (s *Server) serveVerify(w http.ResponseWriter, r *http.Request) (err error) {
+	defer derrors.Wrap(&err, "serveVerify(%q)", r.URL.Path)
+
+	ctx := r.Context()
+	db, ok := s.ds.(*postgres.DB)
+	u := auth.UserFromContext(ctx)
+	if s.verifier == nil || !ok || u == nil {
+		return &serverError{status: http.StatusNotFound}
+	}
+	modulePath := strings.TrimSpace(r.FormValue("module_path"))
+	if modulePath == "" {
+		return &serverError{
+			status: http.StatusBadRequest,
+			epage:  &errorPage{Message: "Missing module path."},
+		}
+	}
+	mi, err := db.GetModuleInfo(ctx, modulePath, internal.LatestVersion)
+	if errors.Is(err, derrors.NotFound) {
+		return &serverError{
+			status: http.StatusNotFound,
+			epage:  &errorPage{Message: fmt.Sprintf("There is no module %q.", modulePath)},
+			err:    err,
+		}
+	}
+	if err != nil {
+		return err
+	}
+	v, err := db.GetVerification(ctx, u.Email, modulePath)
+	if err != nil && !errors.Is(err, derrors.NotFound) {
+		return err
+	}
+	if r.Method == http.MethodPost {
+		query, err := s.verifyAction(r, db, u.Email, mi, v)
+		if err != nil {
+			return err
+		}
+		query.Set("module_path", modulePath)
+		http.Redirect(w, r, "/verify?"+query.Encode(), http.StatusSeeOther)
+		return nil
+	}
+
+	page := &verifyPage{
+		basePage:     s.newBasePage(r, "Verify "+modulePath),
+		ModulePath:   modulePath,
+		Email:        u.Email,
+		Verification: v,
+		FilePath:     verify.FilePath,
+		FileURL:      verify.FileURL(mi.SourceInfo),
+		DNSName:      verify.DNSName(modulePath),
+		Failed:       r.FormValue("failed"),
+		Reprocessed:  r.FormValue("reprocessed") != "",
+	}
+	if v != nil {
+		page.DNSRecord = verify.DNSRecord(v.Token)
+	}
+	if v != nil && v.Verified() {
+		page.Supersessions, err = db.GetSupersessions(ctx, modulePath)
+		if err != nil {
+			return err
+		}
+	}
+	page.NoIndex = true
+	s.servePage(ctx, w, "verify.tmpl", page)
+	return nil
+}
+
+// verifyAction performs the action posted with r by the user with the given
+// email address for the latest version mi of a module, and returns the query
+// of the verification page to show next. v is the verification request of the
+// user for the module, if any.
+func (s *Server) verifyAction(r *http.Request, db *postgres.DB, email string, mi *internal.LegacyModuleInfo, v *verify.Verification) (url.Values, error) {
+	ctx := r.Context()
+	query := url.Values{}
+	switch action := r.FormValue("action"); action {
+	case "start":
+		token, err := verify.NewToken()
+		if err != nil {
+			return nil, err
+		}
+		if err := db.StartVerification(ctx, email, mi.ModulePath, token); err != nil {
+			return nil, err
+		}
+	case "check":
+		if v == nil {
+			return nil, &serverError{status: http.StatusBadRequest, err: errors.New("verification not started")}
+		}
+		method := r.FormValue("method")
+		ok, err := s.verifier.Check(ctx, method, mi.ModulePath, mi.SourceInfo, v.Token)
+		if errors.Is(err, derrors.InvalidArgument) {
+			return nil, &serverError{status: http.StatusBadRequest, err: err}
+		}
+		if err != nil {
+			// The repository or DNS servers of the module failing is not a
+			// failure of the site, so the user is only told that the check
+			// failed.
+			log.Errorf(ctx, "verification of %s by %s: %v", mi.ModulePath, email, err)
+		}
+		if !ok {
+			query.Set("failed", method)
+			return query, nil
+		}
+		if err := db.MarkVerified(ctx, email, mi.ModulePath, method); err != nil {
+			return nil, err
+		}
+		s.invalidateModule(ctx, mi.ModulePath)
+		log.Infof(ctx, "%s verified as an owner of %s by %s", email, mi.ModulePath, method)
+	case "reprocess", "supersede", "unsupersede":
+		if v == nil || !v.Verified() {
+			return nil, &serverError{status: http.StatusForbidden, err: errors.New("not a verified owner")}
+		}
+		if action != "reprocess" {
+			if err := supersedeAction(r, db, email, mi.ModulePath, action); err != nil {
+				return nil, err
+			}
+			s.invalidateModule(ctx, mi.ModulePath)
+			return query, nil
+		}
+		if s.queue == nil {
+			return nil, &serverError{status: http.StatusNotImplemented, err: errors.New("no queue")}
+		}
+		suffix := "reprocess-" + strconv.FormatInt(time.Now().Truncate(reprocessInterval).Unix(), 10)
+		if err := s.queue.ScheduleFetch(ctx, mi.ModulePath, mi.Version, suffix, s.taskIDChangeInterval); err != nil {
+			return nil, err
+		}
+		log.Infof(ctx, "%s reprocesses %s@%s", email, mi.ModulePath, mi.Version)
+		query.Set("reprocessed", "1")
+	default:
+		return nil, &serverError{status: http.StatusBadRequest, err: fmt.Errorf("unknown action %q", action)}
+	}
+	return query, nil
+}
+
+// moduleVerified reports whether a user was verified as an owner of the
+// module at modulePath. Errors are only logged, since the badge is not worth
+// failing a page for.
+func (s *Server) moduleVerified(ctx context.Context, modulePath string) bool {
+	db, ok := s.ds.(*postgres.DB)
+	if s.verifier == nil || !ok {
+		return false
+	}
+	verified, err := db.IsVerifiedModule(ctx, modulePath)
+	if err != nil {
+		log.Error(ctx, err)
+		return false
+	}
+	return verified
+}
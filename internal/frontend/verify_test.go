@@ -0,0 +1,150 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/testing/sample"
+	"golang.org/x/pkgsite/internal/verify"
+)
+
+// txtRecords is a verify.Resolver with the TXT records of a few names.
+type txtRecords map[string][]string
+
+func (r txtRecords) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if records, ok := r[name]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+// recordingQueue is a queue.Queue that records the module versions it is
+// asked to fetch.
+type recordingQueue struct {
+	fetches []string
+}
+
+func (q *recordingQueue) ScheduleFetch(ctx context.Context, modulePath, version, suffix string, taskIDChangeInterval time.Duration) error {
+	q.fetches = append(q.fetches, modulePath+"@"+version)
+	return nil
+}
+
+func TestVerify(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	s, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	const modulePath = "example.com/a"
+	if err := testDB.InsertModule(ctx, sample.Module(modulePath, "v1.0.0", "p")); err != nil {
+		t.Fatal(err)
+	}
+	user := &auth.User{Email: "alice@example.com"}
+	serve := func(u *auth.User, method, target string, form url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		if form != nil {
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		if u != nil {
+			r = r.WithContext(auth.NewContextWithUser(r.Context(), u))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	post := func(action string) *httptest.ResponseRecorder {
+		return serve(user, "POST", "/verify", url.Values{"action": {action}, "method": {"dns"}, "module_path": {modulePath}})
+	}
+	verified := func() bool {
+		v, err := testDB.IsVerifiedModule(ctx, modulePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	// Without a verifier, or without a user, there is no page.
+	if w := serve(user, "GET", "/verify?module_path="+modulePath, nil); w.Code != http.StatusNotFound {
+		t.Errorf("verification disabled: got code %d, want 404", w.Code)
+	}
+	records := txtRecords{}
+	s.verifier = verify.NewChecker(http.DefaultClient, records)
+	q := &recordingQueue{}
+	s.queue = q
+	if w := serve(nil, "GET", "/verify?module_path="+modulePath, nil); w.Code != http.StatusNotFound {
+		t.Errorf("no user: got code %d, want 404", w.Code)
+	}
+	if w := serve(user, "GET", "/verify?module_path=example.com/unknown", nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown module: got code %d, want 404", w.Code)
+	}
+	if w := post("reprocess"); w.Code != http.StatusForbidden {
+		t.Errorf("reprocess before verification: got code %d, want 403", w.Code)
+	}
+
+	if w := post("start"); w.Code != http.StatusSeeOther {
+		t.Fatalf("start: got code %d, want 303", w.Code)
+	}
+	v, err := testDB.GetVerification(ctx, user.Email, modulePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := serve(user, "GET", "/verify?module_path="+modulePath, nil); !strings.Contains(w.Body.String(), v.Token) {
+		t.Errorf("page does not show the token %q:\n%s", v.Token, w.Body)
+	}
+
+	// The check fails until the token is published.
+	if w := post("check"); w.Code != http.StatusSeeOther || !strings.Contains(w.Header().Get("Location"), "failed=dns") {
+		t.Errorf("check without a record: got code %d, location %q; want 303 to a failure", w.Code, w.Header().Get("Location"))
+	}
+	if verified() {
+		t.Fatal("verified without a record")
+	}
+	records["_pkgsite-verification.example.com"] = []string{verify.DNSRecord(v.Token)}
+	if w := post("check"); w.Code != http.StatusSeeOther {
+		t.Errorf("check: got code %d, want 303", w.Code)
+	}
+	if !verified() {
+		t.Fatal("not verified after publishing the record")
+	}
+	if w := serve(nil, "GET", "/"+modulePath+"/p", nil); !strings.Contains(w.Body.String(), "Verified publisher") {
+		t.Errorf("package page has no badge:\n%s", w.Body)
+	}
+
+	if w := post("reprocess"); w.Code != http.StatusSeeOther {
+		t.Errorf("reprocess: got code %d, want 303", w.Code)
+	}
+	if want := modulePath + "@v1.0.0"; len(q.fetches) != 1 || q.fetches[0] != want {
+		t.Errorf("got fetches %v, want [%s]", q.fetches, want)
+	}
+
+	// Owners can supersede the packages of their module, and only those.
+	supersede := func(pkgPath string) *httptest.ResponseRecorder {
+		return serve(user, "POST", "/verify", url.Values{
+			"action":        {"supersede"},
+			"module_path":   {modulePath},
+			"package_path":  {pkgPath},
+			"superseded_by": {"errors"},
+		})
+	}
+	if w := supersede("example.com/other/p"); w.Code != http.StatusBadRequest {
+		t.Errorf("supersede a package of another module: got code %d, want 400", w.Code)
+	}
+	if w := supersede(modulePath + "/p"); w.Code != http.StatusSeeOther {
+		t.Errorf("supersede: got code %d, want 303", w.Code)
+	}
+	body := serve(nil, "GET", "/"+modulePath+"/p", nil).Body.String()
+	if !strings.Contains(body, "DetailsSuperseded") || !strings.Contains(body, `href="/errors"`) {
+		t.Errorf("package page has no supersession banner:\n%s", body)
+	}
+}
@@ -54,11 +54,21 @@ type VersionList struct {
 // VersionSummary holds data required to format the version link on the
 // versions tab.
 type VersionSummary struct {
-	TooltipVersion string
-	DisplayVersion string
-	CommitTime     string
+	TooltipVersion  string
+	DisplayVersion  string
+	CommitTime      string // relative to now, like "2 days ago"
+	CommitTimestamp string // CommitTime in RFC 3339
 	// Link to this version, for use in the anchor href.
 	Link string
+	// IsPrerelease reports whether this version is a pre-release, such as a
+	// beta or release candidate of Go.
+	IsPrerelease bool
+	// ReleaseNotes is an excerpt of the release notes of this version, if
+	// any.
+	ReleaseNotes string
+	// LicenseChange is set if the licenses of this version differ from
+	// those of the version before.
+	LicenseChange *LicenseChange
 }
 
 // fetchModuleVersionsDetails builds a version hierarchy for module versions
@@ -79,7 +89,14 @@ func fetchModuleVersionsDetails(ctx context.Context, ds internal.DataSource, mi
 	linkify := func(m *internal.LegacyModuleInfo) string {
 		return constructModuleURL(m.ModulePath, linkVersion(m.Version, m.ModulePath))
 	}
-	return buildVersionDetails(mi.ModulePath, versions, linkify), nil
+	vd := buildVersionDetails(ctx, mi.ModulePath, versions, linkify)
+	if err := addVersionsReleaseNotes(ctx, ds, vd); err != nil {
+		return nil, err
+	}
+	if err := addVersionsLicenseChanges(ctx, ds, vd); err != nil {
+		return nil, err
+	}
+	return vd, nil
 }
 
 // fetchPackageVersionsDetails builds a version hierarchy for all module
@@ -120,7 +137,14 @@ func fetchPackageVersionsDetails(ctx context.Context, ds internal.DataSource, pk
 		}
 		return constructPackageURL(versionPath, mi.ModulePath, linkVersion(mi.Version, mi.ModulePath))
 	}
-	return buildVersionDetails(modulePath, filteredVersions, linkify), nil
+	vd := buildVersionDetails(ctx, modulePath, filteredVersions, linkify)
+	if err := addVersionsReleaseNotes(ctx, ds, vd); err != nil {
+		return nil, err
+	}
+	if err := addVersionsLicenseChanges(ctx, ds, vd); err != nil {
+		return nil, err
+	}
+	return vd, nil
 }
 
 // pathInVersion constructs the full import path of the package corresponding
@@ -130,10 +154,11 @@ func fetchPackageVersionsDetails(ctx context.Context, ds internal.DataSource, pk
 //
 // For example: if we're considering package foo.com/v3/bar/baz, and encounter
 // module version foo.com/bar/v2, we do the following:
-//   1) Start with the v1Path foo.com/bar/baz.
-//   2) Trim off the version series path foo.com/bar to get 'baz'.
-//   3) Join with the versioned module path foo.com/bar/v2 to get
-//      foo.com/bar/v2/baz.
+//  1. Start with the v1Path foo.com/bar/baz.
+//  2. Trim off the version series path foo.com/bar to get 'baz'.
+//  3. Join with the versioned module path foo.com/bar/v2 to get
+//     foo.com/bar/v2/baz.
+//
 // ...being careful about slashes along the way.
 func pathInVersion(v1Path string, mi *internal.LegacyModuleInfo) string {
 	suffix := strings.TrimPrefix(strings.TrimPrefix(v1Path, mi.SeriesPath()), "/")
@@ -147,7 +172,7 @@ func pathInVersion(v1Path string, mi *internal.LegacyModuleInfo) string {
 // versions tab, organizing major versions into those that have the same module
 // path as the package version under consideration, and those that don't.  The
 // given versions MUST be sorted first by module path and then by semver.
-func buildVersionDetails(currentModulePath string, modInfos []*internal.LegacyModuleInfo, linkify func(v *internal.LegacyModuleInfo) string) *VersionsDetails {
+func buildVersionDetails(ctx context.Context, currentModulePath string, modInfos []*internal.LegacyModuleInfo, linkify func(v *internal.LegacyModuleInfo) string) *VersionsDetails {
 
 	// lists organizes versions by VersionListKey. Note that major version isn't
 	// sufficient as a key: there are packages contained in the same major
@@ -189,10 +214,12 @@ func buildVersionDetails(currentModulePath string, modInfos []*internal.LegacyMo
 			ttversion = fmtVersion // tooltips will show the Go tag
 		}
 		vs := &VersionSummary{
-			TooltipVersion: ttversion,
-			Link:           linkify(mi),
-			CommitTime:     elapsedTime(mi.CommitTime),
-			DisplayVersion: fmtVersion,
+			TooltipVersion:  ttversion,
+			Link:            linkify(mi),
+			CommitTime:      elapsedTime(ctx, mi.CommitTime),
+			CommitTimestamp: timestamp(mi.CommitTime),
+			DisplayVersion:  fmtVersion,
+			IsPrerelease:    isPrerelease(mi.Version),
 		}
 		if _, ok := lists[key]; !ok {
 			seenLists = append(seenLists, key)
@@ -220,11 +247,13 @@ func buildVersionDetails(currentModulePath string, modInfos []*internal.LegacyMo
 //
 // For prerelease versions, formatVersion separates the prerelease portion of
 // the version string into a parenthetical. i.e.
-//   formatVersion("v1.2.3-alpha") = "v1.2.3 (alpha)"
+//
+//	formatVersion("v1.2.3-alpha") = "v1.2.3 (alpha)"
 //
 // For pseudo versions, formatVersion uses a short commit hash to identify the
 // version. i.e.
-//   formatVersion("v1.2.3-20190311183353-d8887717615a") = "v.1.2.3 (d888771)"
+//
+//	formatVersion("v1.2.3-20190311183353-d8887717615a") = "v.1.2.3 (d888771)"
 func formatVersion(v string) string {
 	vType, err := version.ParseType(v)
 	if err != nil {
@@ -249,6 +278,13 @@ func formatVersion(v string) string {
 	}
 }
 
+// isPrerelease reports whether v is a pre-release version, such as
+// v1.13.0-beta.1. Pseudo-versions are not considered pre-releases.
+func isPrerelease(v string) bool {
+	vType, err := version.ParseType(v)
+	return err == nil && vType == version.TypePrerelease
+}
+
 // pseudoVersionRev extracts the commit identifier from a pseudo version
 // string. It assumes the pseudo version is correctly formatted.
 func pseudoVersionRev(v string) string {
@@ -37,18 +37,22 @@ func versionSummaries(path string, versions []string, linkify func(path, version
 	vs := make([]*VersionSummary, len(versions))
 	for i, version := range versions {
 		var semver, displayVersion string
+		prerelease := isPrerelease(version)
 		if stdlib.Contains(path) {
 			semver = version
 			displayVersion = version
+			prerelease = isPrerelease(stdlib.VersionForTag(version))
 		} else {
 			semver = version
 			displayVersion = formatVersion(semver)
 		}
 		vs[i] = &VersionSummary{
-			TooltipVersion: semver,
-			DisplayVersion: displayVersion,
-			Link:           linkify(path, version),
-			CommitTime:     commitTime,
+			TooltipVersion:  semver,
+			DisplayVersion:  displayVersion,
+			Link:            linkify(path, version),
+			CommitTime:      commitTime,
+			CommitTimestamp: timestamp(sample.CommitTime),
+			IsPrerelease:    prerelease,
 		}
 	}
 	return vs
@@ -201,6 +205,20 @@ func TestFetchPackageVersionsDetails(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "want stdlib pre-release versions",
+			pkg:  nethttpPkg,
+			modules: []*internal.Module{
+				sampleModule("std", "v1.12.5", version.TypeRelease, &nethttpPkg.LegacyPackage),
+				sampleModule("std", "v1.13.0-beta.1", version.TypePrerelease, &nethttpPkg.LegacyPackage),
+				sampleModule("std", "v1.13.0-rc.1", version.TypePrerelease, &nethttpPkg.LegacyPackage),
+			},
+			wantDetails: &VersionsDetails{
+				ThisModule: []*VersionList{
+					makeList("net/http", "std", "go1", []string{"go1.13rc1", "go1.13beta1", "go1.12.5"}),
+				},
+			},
+		},
 		{
 			name: "want v1 first",
 			pkg:  pkg1,
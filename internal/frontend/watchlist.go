@@ -0,0 +1,90 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/watchlist"
+)
+
+// watchlistPage is the page listing the modules watched by the signed-in
+// user.
+type watchlistPage struct {
+	basePage
+	Email   string
+	Watches []*watchlist.Watch
+	// Add is the path of a module to offer to watch, if it is not watched
+	// already.
+	Add string
+}
+
+// handleWatchlist serves the watchlist of the signed-in user, and adds or
+// removes the module posted with the form.
+func (s *Server) handleWatchlist(w http.ResponseWriter, r *http.Request) {
+	if err := s.serveWatchlist(w, r); err != nil {
+		s.serveError(w, r, err)
+	}
+}
+
+func (s *Server) serveWatchlist(w http.ResponseWriter, r *http.Request) (err error) {
+	defer derrors.Wrap(&err, "serveWatchlist(%q)", r.URL.Path)
+
+	ctx := r.Context()
+	db, ok := s.ds.(*postgres.DB)
+	u := auth.UserFromContext(ctx)
+	if !s.watchlists || !ok || u == nil {
+		return &serverError{status: http.StatusNotFound}
+	}
+	modulePath := strings.TrimSpace(r.FormValue("module_path"))
+	if r.Method == http.MethodPost {
+		switch action := r.FormValue("action"); action {
+		case "watch":
+			err = db.Watch(ctx, u.Email, modulePath)
+		case "unwatch":
+			err = db.Unwatch(ctx, u.Email, modulePath)
+		default:
+			return &serverError{status: http.StatusBadRequest, err: fmt.Errorf("unknown action %q", action)}
+		}
+		if errors.Is(err, derrors.NotFound) {
+			return &serverError{
+				status: http.StatusNotFound,
+				epage:  &errorPage{Message: fmt.Sprintf("There is no module %q.", modulePath)},
+				err:    err,
+			}
+		}
+		if err != nil {
+			return err
+		}
+		log.Infof(ctx, "watchlist of %s: %s %s", u.Email, r.FormValue("action"), modulePath)
+		http.Redirect(w, r, "/watchlist", http.StatusSeeOther)
+		return nil
+	}
+
+	watches, err := db.GetWatchlist(ctx, u.Email)
+	if err != nil {
+		return err
+	}
+	page := &watchlistPage{
+		basePage: s.newBasePage(r, "Watchlist"),
+		Email:    u.Email,
+		Watches:  watches,
+		Add:      modulePath,
+	}
+	for _, w := range watches {
+		if w.ModulePath == modulePath {
+			page.Add = ""
+		}
+	}
+	s.servePage(ctx, w, "watchlist.tmpl", page)
+	return nil
+}
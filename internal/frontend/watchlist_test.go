@@ -0,0 +1,78 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestWatchlist(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	s, handler, teardown := newTestServer(t, nil)
+	defer teardown()
+	if err := testDB.InsertModule(ctx, sample.Module("example.com/a", "v1.0.0", "p")); err != nil {
+		t.Fatal(err)
+	}
+	user := &auth.User{Email: "alice@example.com"}
+	serve := func(u *auth.User, method, target string, form url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		if form != nil {
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		if u != nil {
+			r = r.WithContext(auth.NewContextWithUser(r.Context(), u))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	watch := url.Values{"action": {"watch"}, "module_path": {"example.com/a"}}
+
+	// Without watchlists, or without a user, there is no page.
+	if w := serve(user, "GET", "/watchlist", nil); w.Code != http.StatusNotFound {
+		t.Errorf("watchlists disabled: got code %d, want 404", w.Code)
+	}
+	s.watchlists = true
+	if w := serve(nil, "POST", "/watchlist", watch); w.Code != http.StatusNotFound {
+		t.Errorf("no user: got code %d, want 404", w.Code)
+	}
+
+	if w := serve(user, "GET", "/watchlist?module_path=example.com/a", nil); !strings.Contains(w.Body.String(), "Watch example.com/a") {
+		t.Errorf("page does not offer to watch example.com/a:\n%s", w.Body)
+	}
+	if w := serve(user, "POST", "/watchlist", watch); w.Code != http.StatusSeeOther {
+		t.Fatalf("watch: got code %d, want 303", w.Code)
+	}
+	watches, err := testDB.GetWatchlist(ctx, user.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(watches) != 1 || watches[0].ModulePath != "example.com/a" {
+		t.Errorf("got watchlist %+v, want example.com/a", watches)
+	}
+	w := serve(user, "GET", "/watchlist?module_path=example.com/a", nil)
+	if body := w.Body.String(); strings.Contains(body, "Watch example.com/a") || !strings.Contains(body, "Stop watching") {
+		t.Errorf("page does not list example.com/a as watched:\n%s", body)
+	}
+
+	unknown := url.Values{"action": {"watch"}, "module_path": {"example.com/unknown"}}
+	if w := serve(user, "POST", "/watchlist", unknown); w.Code != http.StatusNotFound {
+		t.Errorf("watch unknown module: got code %d, want 404", w.Code)
+	}
+	unwatch := url.Values{"action": {"unwatch"}, "module_path": {"example.com/a"}}
+	if w := serve(user, "POST", "/watchlist", unwatch); w.Code != http.StatusSeeOther {
+		t.Errorf("unwatch: got code %d, want 303", w.Code)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package i18n translates the strings shown by the frontend into the
+// languages of its users.
+//
+// Strings are written in English in the code and templates, and serve as
+// their own keys in a Catalog. Translations are read from one JSON file per
+// language, which maps English strings to their translations; strings
+// without a translation are shown in English.
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/assets"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// Default is the language of the strings in the code and templates.
+var Default = language.English
+
+// A Catalog holds the translations of the strings of the frontend.
+type Catalog struct {
+	builder *catalog.Builder
+	tags    []language.Tag // the supported languages, Default first
+	matcher language.Matcher
+}
+
+// NewCatalog returns a Catalog with the given translations, by BCP 47
+// language tag and English string.
+func NewCatalog(translations map[string]map[string]string) (_ *Catalog, err error) {
+	defer derrors.Wrap(&err, "NewCatalog")
+
+	var langs []string
+	for lang := range translations {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	c := &Catalog{
+		builder: catalog.NewBuilder(catalog.Fallback(Default)),
+		tags:    []language.Tag{Default},
+	}
+	for _, lang := range langs {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return nil, err
+		}
+		if tag == Default {
+			return nil, fmt.Errorf("%s: the strings are already in %s", lang, Default)
+		}
+		for key, msg := range translations[lang] {
+			if err := c.builder.SetString(tag, key, msg); err != nil {
+				return nil, fmt.Errorf("%s: %q: %v", lang, key, err)
+			}
+		}
+		c.tags = append(c.tags, tag)
+	}
+	c.matcher = language.NewMatcher(c.tags)
+	return c, nil
+}
+
+// ReadCatalog returns a Catalog with the translations in the files named
+// <tag>.json in dir of fsys, like "fr.json" or "pt-BR.json". If dir does not
+// exist, the Catalog has no translations.
+func ReadCatalog(fsys http.FileSystem, dir string) (_ *Catalog, err error) {
+	defer derrors.Wrap(&err, "ReadCatalog(%q)", dir)
+
+	files, err := assets.Glob(fsys, dir, "*.json")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	translations := map[string]map[string]string{}
+	for _, f := range files {
+		data, err := assets.ReadFile(fsys, f)
+		if err != nil {
+			return nil, err
+		}
+		var msgs map[string]string
+		if err := json.Unmarshal(data, &msgs); err != nil {
+			return nil, fmt.Errorf("%s: %v", f, err)
+		}
+		translations[strings.TrimSuffix(path.Base(f), ".json")] = msgs
+	}
+	return NewCatalog(translations)
+}
+
+// Languages returns the languages of c, Default first.
+func (c *Catalog) Languages() []language.Tag {
+	return c.tags
+}
+
+// Negotiate returns the language of c that best matches the value of an
+// Accept-Language header, or Default if none does.
+func (c *Catalog) Negotiate(acceptLanguage string) language.Tag {
+	if len(c.tags) == 1 || acceptLanguage == "" {
+		return Default
+	}
+	prefs, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return Default
+	}
+	_, i, conf := c.matcher.Match(prefs...)
+	if conf == language.No {
+		return Default
+	}
+	return c.tags[i]
+}
+
+// Printer returns a printer that translates strings into the language tag,
+// which should be one of c.Languages().
+func (c *Catalog) Printer(tag language.Tag) *message.Printer {
+	return message.NewPrinter(tag, message.Catalog(c.builder))
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying p, the printer for the language of a
+// request.
+func NewContext(ctx context.Context, p *message.Printer) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the printer of ctx, or a printer for Default if ctx
+// has none.
+func FromContext(ctx context.Context) *message.Printer {
+	if p, ok := ctx.Value(contextKey{}).(*message.Printer); ok {
+		return p
+	}
+	return message.NewPrinter(Default)
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestCatalog(t *testing.T) {
+	c, err := NewCatalog(map[string]map[string]string{
+		"fr":    {"Imported By": "Importé par", "%d hours ago": "il y a %d heures"},
+		"pt-BR": {"Imported By": "Importado por"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		accept string
+		want   language.Tag
+	}{
+		{"", language.English},
+		{"fr-CA,fr;q=0.9,en;q=0.8", language.French},
+		{"de", language.English},
+		{"de, pt-BR;q=0.5", language.BrazilianPortuguese},
+		{"en-GB", language.English},
+		{"!!!", language.English},
+	} {
+		if got := c.Negotiate(test.accept); got != test.want {
+			t.Errorf("Negotiate(%q) = %s, want %s", test.accept, got, test.want)
+		}
+	}
+	for _, test := range []struct {
+		tag  language.Tag
+		key  string
+		args []interface{}
+		want string
+	}{
+		{language.French, "Imported By", nil, "Importé par"},
+		{language.French, "%d hours ago", []interface{}{3}, "il y a 3 heures"},
+		{language.French, "Licenses", nil, "Licenses"},
+		{language.BrazilianPortuguese, "Imported By", nil, "Importado por"},
+		{language.English, "%d hours ago", []interface{}{3}, "3 hours ago"},
+	} {
+		if got := c.Printer(test.tag).Sprintf(test.key, test.args...); got != test.want {
+			t.Errorf("%s: Sprintf(%q) = %q, want %q", test.tag, test.key, got, test.want)
+		}
+	}
+}
+
+func TestNewCatalogRejectsDefault(t *testing.T) {
+	if _, err := NewCatalog(map[string]map[string]string{"en": {"a": "b"}}); err == nil {
+		t.Error("got no error for English translations")
+	}
+}
+
+func TestReadCatalogMissingDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "i18n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c, err := ReadCatalog(http.Dir(dir), "/i18n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(c.Languages()); got != 1 {
+		t.Errorf("got %d languages, want 1", got)
+	}
+}
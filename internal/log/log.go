@@ -33,6 +33,9 @@ type (
 
 	// labelsKey is the type of the context key for labels.
 	labelsKey struct{}
+
+	// requestIDKey is the type of the context key for request IDs.
+	requestIDKey struct{}
 )
 
 // NewContextWithTraceID creates a new context from ctx that adds the trace ID.
@@ -40,6 +43,19 @@ func NewContextWithTraceID(ctx context.Context, traceID string) context.Context
 	return context.WithValue(ctx, traceIDKey{}, traceID)
 }
 
+// NewContextWithRequestID creates a new context from ctx that adds the request
+// ID. The request ID is added to every log entry written with the context.
+func NewContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID added to ctx by NewContextWithRequestID, or
+// the empty string if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
 // NewContextWithLabel creates anew context from ctx that adds a label that will
 // appear in the log entry.
 func NewContextWithLabel(ctx context.Context, key, value string) context.Context {
@@ -66,12 +82,18 @@ func (l *stackdriverLogger) log(ctx context.Context, s logging.Severity, payload
 	traceID, _ := ctx.Value(traceIDKey{}).(string) // if not present, traceID is "", which is fine
 	labels, _ := ctx.Value(labelsKey{}).(map[string]string)
 	es := experimentString(ctx)
-	if len(es) > 0 {
+	requestID := RequestID(ctx)
+	if len(es) > 0 || requestID != "" {
 		nl := map[string]string{}
 		for k, v := range labels {
 			nl[k] = v
 		}
-		nl["experiments"] = es
+		if len(es) > 0 {
+			nl["experiments"] = es
+		}
+		if requestID != "" {
+			nl["requestID"] = requestID
+		}
 		labels = nl
 	}
 	l.sdlogger.Log(logging.Entry{
@@ -91,6 +113,9 @@ func (stdlibLogger) log(ctx context.Context, s logging.Severity, payload interfa
 	if traceID != "" {
 		extras = append(extras, fmt.Sprintf("traceID %s", traceID))
 	}
+	if requestID := RequestID(ctx); requestID != "" {
+		extras = append(extras, fmt.Sprintf("requestID %s", requestID))
+	}
 	if labels, ok := ctx.Value(labelsKey{}).(map[string]string); ok {
 		extras = append(extras, fmt.Sprint(labels))
 	}
@@ -0,0 +1,136 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lookalike detects module paths that look confusingly like the paths
+// of popular modules, as a typosquatting attack would use.
+//
+// The checks are heuristics: a flagged module is only a candidate for review,
+// and many flagged modules are legitimate forks or unrelated projects with
+// similar names.
+package lookalike
+
+import (
+	"strings"
+	"time"
+)
+
+// Reasons a module path is flagged.
+const (
+	ReasonHomoglyph    = "differs only in confusable characters"
+	ReasonEditDistance = "differs by a single character"
+)
+
+// Review statuses of a Flag.
+const (
+	StatusPending   = "pending"
+	StatusConfirmed = "confirmed"
+	StatusDismissed = "dismissed"
+)
+
+// A Flag records that a module path looks like the path of a popular module.
+type Flag struct {
+	ModulePath string
+	SimilarTo  string // the path of the popular module
+	Reason     string
+	Status     string
+	CreatedAt  time.Time
+	ReviewedAt time.Time // zero if not reviewed
+}
+
+// minEditDistanceLen is the length below which paths are not compared by edit
+// distance, because short paths are too often one character apart.
+const minEditDistanceLen = 12
+
+// Find returns the first path in popular that modulePath looks confusingly
+// like, and the reason, or "", "" if there is none. Paths in popular with the
+// same owner as modulePath, like other repositories of the same GitHub user
+// or other paths under the same vanity domain, are not considered, since the
+// owner controls both. For the same reason, paths that differ only in case
+// are not reported.
+func Find(modulePath string, popular []string) (similarTo, reason string) {
+	lower := strings.ToLower(modulePath)
+	norm := normalize(lower)
+	for _, p := range popular {
+		if p == modulePath || related(p, modulePath) || owner(p) == owner(modulePath) {
+			continue
+		}
+		pl := strings.ToLower(p)
+		switch {
+		case normalize(pl) == norm:
+			return p, ReasonHomoglyph
+		case len(pl) >= minEditDistanceLen && editDistanceIsOne(pl, lower):
+			return p, ReasonEditDistance
+		}
+	}
+	return "", ""
+}
+
+// related reports whether one of a and b is a prefix of the other, as with a
+// module and its major versions or nested modules.
+func related(a, b string) bool {
+	return strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
+// codeHosts are hosts whose paths start with the name of a user or
+// organization.
+var codeHosts = map[string]bool{
+	"github.com":    true,
+	"gitlab.com":    true,
+	"bitbucket.org": true,
+}
+
+// owner returns the part of modulePath that identifies who controls it: the
+// host and user for a code host, and the host otherwise. The result is in
+// lower case, since hosts and the users of code hosts are case-insensitive.
+func owner(modulePath string) string {
+	parts := strings.SplitN(strings.ToLower(modulePath), "/", 3)
+	if codeHosts[parts[0]] && len(parts) > 1 {
+		return parts[0] + "/" + parts[1]
+	}
+	return parts[0]
+}
+
+// confusables maps sequences of characters to the characters they are easily
+// mistaken for, in lower case.
+var confusables = strings.NewReplacer(
+	"rn", "m",
+	"vv", "w",
+	"cl", "d",
+	"0", "o",
+	"1", "l",
+	"i", "l",
+	"-", "",
+	"_", "",
+)
+
+// normalize replaces the confusable characters in the lower-case path s by
+// the ones they look like.
+func normalize(s string) string {
+	return confusables.Replace(s)
+}
+
+// editDistanceIsOne reports whether a can be turned into b by inserting,
+// deleting or substituting one byte, or by swapping two adjacent bytes.
+func editDistanceIsOne(a, b string) bool {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	if len(a)-len(b) > 1 || a == b {
+		return false
+	}
+	// Skip the common prefix.
+	i := 0
+	for i < len(b) && a[i] == b[i] {
+		i++
+	}
+	if len(a) > len(b) {
+		// One deletion from a.
+		return a[i+1:] == b[i:]
+	}
+	// Same length: one substitution, or one transposition.
+	if a[i+1:] == b[i+1:] {
+		return true
+	}
+	return i+1 < len(a) && a[i] == b[i+1] && a[i+1] == b[i] && a[i+2:] == b[i+2:]
+}
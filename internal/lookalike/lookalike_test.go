@@ -0,0 +1,64 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lookalike
+
+import "testing"
+
+func TestFind(t *testing.T) {
+	popular := []string{
+		"github.com/sirupsen/logrus",
+		"github.com/stretchr/testify",
+		"golang.org/x/tools",
+		"gopkg.in/yaml.v2",
+		"github.com/go-redis/redis",
+	}
+	for _, test := range []struct {
+		modulePath, wantSimilar, wantReason string
+	}{
+		{"github.com/sirupsen/logrus", "", ""},
+		{"github.com/sirupsen/logrus/v2", "", ""},
+		{"github.com/sirupsen/logrvs", "", ""}, // same owner
+		{"github.com/Sirupsen/logrus", "", ""}, // same owner, in another case
+		{"golang.org/x/tooIs", "", ""},         // same vanity domain
+		{"github.com/slrupsen/logrus", "github.com/sirupsen/logrus", ReasonHomoglyph},
+		{"github.com/sirupsen1/logrus", "github.com/sirupsen/logrus", ReasonEditDistance},
+		{"github.com/stretchr/testlfy", "", ""}, // same owner
+		{"github.com/strechr/testify", "github.com/stretchr/testify", ReasonEditDistance},
+		{"github.com/stretcrh/testify", "github.com/stretchr/testify", ReasonEditDistance},
+		{"github.com/go_redis/redis", "github.com/go-redis/redis", ReasonHomoglyph},
+		{"github.com/goredis/redis", "github.com/go-redis/redis", ReasonHomoglyph},
+		{"gopkg.in/yaml.v3", "", ""}, // same host
+		{"github.com/golang/tools", "", ""},
+		{"example.com/a", "", ""},
+	} {
+		gotSimilar, gotReason := Find(test.modulePath, popular)
+		if gotSimilar != test.wantSimilar || gotReason != test.wantReason {
+			t.Errorf("Find(%q) = %q, %q; want %q, %q", test.modulePath, gotSimilar, gotReason, test.wantSimilar, test.wantReason)
+		}
+	}
+}
+
+func TestEditDistanceIsOne(t *testing.T) {
+	for _, test := range []struct {
+		a, b string
+		want bool
+	}{
+		{"abc", "abc", false},
+		{"abc", "abd", true},
+		{"abc", "ab", true},
+		{"abc", "bc", true},
+		{"abc", "axbc", true},
+		{"abc", "acb", true},
+		{"abc", "bac", true},
+		{"abc", "cba", false},
+		{"abc", "a", false},
+		{"abcd", "abdc", true},
+		{"abcd", "xbcy", false},
+	} {
+		if got := editDistanceIsOne(test.a, test.b); got != test.want {
+			t.Errorf("editDistanceIsOne(%q, %q) = %t, want %t", test.a, test.b, got, test.want)
+		}
+	}
+}
@@ -8,6 +8,12 @@ import "net/http"
 
 // AcceptMethods serves 405 (Method Not Allowed) for any method not on the given list.
 func AcceptMethods(methods ...string) Middleware {
+	return AcceptMethodsOrPostsTo(nil, methods...)
+}
+
+// AcceptMethodsOrPostsTo is like AcceptMethods, but also accepts POSTs to the
+// given paths, such as the paths of forms.
+func AcceptMethodsOrPostsTo(postPaths []string, methods ...string) Middleware {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			for _, m := range methods {
@@ -16,6 +22,14 @@ func AcceptMethods(methods ...string) Middleware {
 					return
 				}
 			}
+			if r.Method == http.MethodPost {
+				for _, p := range postPaths {
+					if r.URL.Path == p {
+						h.ServeHTTP(w, r)
+						return
+					}
+				}
+			}
 			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 		})
 	}
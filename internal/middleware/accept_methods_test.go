@@ -52,3 +52,25 @@ func TestAcceptMethods(t *testing.T) {
 		}
 	}
 }
+
+func TestAcceptMethodsOrPostsTo(t *testing.T) {
+	mw := AcceptMethodsOrPostsTo([]string{"/form"}, "GET")
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, test := range []struct {
+		method, path string
+		want         int
+	}{
+		{"GET", "/", http.StatusOK},
+		{"GET", "/form", http.StatusOK},
+		{"POST", "/form", http.StatusOK},
+		{"POST", "/form/x", http.StatusMethodNotAllowed},
+		{"POST", "/", http.StatusMethodNotAllowed},
+		{"PUT", "/form", http.StatusMethodNotAllowed},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(test.method, test.path, nil))
+		if got := w.Code; got != test.want {
+			t.Errorf("%s %s: got code %d, want %d", test.method, test.path, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/groupcache/lru"
+	"golang.org/x/pkgsite/internal/apikey"
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/time/rate"
+)
+
+// An APIKeyStore looks up API keys and records how much they are used.
+type APIKeyStore interface {
+	// LookupAPIKey returns the key, or an error wrapping derrors.NotFound if
+	// it does not exist or was revoked.
+	LookupAPIKey(ctx context.Context, key string) (*apikey.Key, error)
+	// RecordAPIKeyUsage adds requests, by key ID, to the usage of the keys on
+	// the day of t.
+	RecordAPIKeyUsage(ctx context.Context, t time.Time, requests map[int64]int64) error
+}
+
+const (
+	// apiKeyCacheTTL is how long the result of looking up a key is cached.
+	// It bounds how long a revoked key keeps working.
+	apiKeyCacheTTL = time.Minute
+
+	// apiKeyCacheSize is the maximum number of lookups cached.
+	apiKeyCacheSize = 1000
+)
+
+// An APIKeyLimiter enforces the rate limits of API keys, and counts the
+// requests made with them.
+type APIKeyLimiter struct {
+	store      APIKeyStore
+	tiers      map[string]config.APIKeyTier
+	flushEvery time.Duration
+
+	// lookupLimiters limit the lookups in the store of keys that are not
+	// cached, by IP address, so that random keys cannot flood the store.
+	lookupLimiters *ipLimiters
+
+	mu       sync.Mutex
+	lookups  *lru.Cache // from the hash of a known key to a *apiKeyLookup
+	unknown  *lru.Cache // from the hash of an unknown or revoked key to its expiry time
+	limiters map[int64]*rate.Limiter
+	usage    map[int64]int64 // requests not yet recorded in the store, by key ID
+}
+
+// apiKeyLookup is the cached result of looking up a known key.
+type apiKeyLookup struct {
+	key     *apikey.Key
+	expires time.Time
+}
+
+// NewAPIKeyLimiter returns an APIKeyLimiter for use in the APIKeys
+// middleware, which limits each key to the rate of its tier in
+// settings.APIKeyTiers. Keys that are not cached are looked up in store at
+// most at the rate that settings allow each IP block. The limiter records
+// the usage of keys in store in the background, every flushEvery.
+func NewAPIKeyLimiter(ctx context.Context, flushEvery time.Duration, store APIKeyStore, settings config.QuotaSettings) *APIKeyLimiter {
+	l := &APIKeyLimiter{
+		store:          store,
+		tiers:          settings.APIKeyTiers,
+		flushEvery:     flushEvery,
+		lookupLimiters: newIPLimiters(settings),
+		lookups:        lru.New(apiKeyCacheSize),
+		unknown:        lru.New(apiKeyCacheSize),
+		limiters:       map[int64]*rate.Limiter{},
+		usage:          map[int64]int64{},
+	}
+	go l.flushUsage(ctx)
+	return l
+}
+
+// APIKeys returns a middleware that rate limits requests carrying an API key
+// in the apikey.Header header by key, instead of by IP address as Quota
+// does. Requests over the limit of the key's tier are rejected with a 429
+// (TooManyRequests), even if Quota only records blocking. Allowed requests
+// carry the key in their context (see apikey.KeyFromContext), so that Quota
+// lets them through. It must come before Quota.
+//
+// Requests with an unknown or revoked key, or with a key that could not be
+// looked up because its IP block made too many lookups, are passed on
+// marked, so that Quota limits them by IP address and then rejects them with
+// a 401 (Unauthorized). Unknown keys thus cannot get around Quota.
+//
+// Requests without a key, or with a key whose tier is not configured, are
+// left to Quota. So are all requests if the key store fails: API keys should
+// never make the site less available.
+func APIKeys(l *APIKeyLimiter) Middleware {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			secret := r.Header.Get(apikey.Header)
+			if secret == "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+			ctx := r.Context()
+			key, err := l.lookup(ctx, secret, ipKey(r.Header.Get("X-Forwarded-For")))
+			if err != nil {
+				log.Errorf(ctx, "APIKeys: %v", err)
+				h.ServeHTTP(w, r)
+				return
+			}
+			if key == nil {
+				h.ServeHTTP(w, r.WithContext(context.WithValue(ctx, unknownAPIKeyKey{}, true)))
+				return
+			}
+			tier, ok := l.tiers[key.Tier]
+			if !ok {
+				log.Errorf(ctx, "APIKeys: key %d has unknown tier %q", key.ID, key.Tier)
+				h.ServeHTTP(w, r)
+				return
+			}
+			blocked := !l.allow(key.ID, tier)
+			recordQuotaMetric(strconv.FormatBool(blocked))
+			if blocked {
+				const tmr = http.StatusTooManyRequests
+				http.Error(w, http.StatusText(tmr), tmr)
+				return
+			}
+			h.ServeHTTP(w, r.WithContext(apikey.NewContextWithKey(ctx, key)))
+		})
+	}
+}
+
+// unknownAPIKeyKey is the context key that marks requests with an unknown
+// API key, for Quota.
+type unknownAPIKeyKey struct{}
+
+// hasUnknownAPIKey reports whether ctx was marked by APIKeys as carrying an
+// unknown or revoked API key.
+func hasUnknownAPIKey(ctx context.Context) bool {
+	v, _ := ctx.Value(unknownAPIKeyKey{}).(bool)
+	return v
+}
+
+// rejectUnknownAPIKey serves the response to a request with an unknown or
+// revoked API key.
+func rejectUnknownAPIKey(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "unknown or revoked API key", http.StatusUnauthorized)
+}
+
+// lookup returns the key whose secret is secret, or nil if there is no such
+// key, using the cached result of an earlier lookup if it has not expired.
+// Keys that are not cached are only looked up in the store if the IP
+// addresses of ip, as returned by ipKey, are within their quota of lookups;
+// otherwise lookup returns nil.
+func (l *APIKeyLimiter) lookup(ctx context.Context, secret, ip string) (*apikey.Key, error) {
+	hash := apikey.Hash(secret)
+	now := time.Now()
+	l.mu.Lock()
+	v, known := l.lookups.Get(hash)
+	u, unknown := l.unknown.Get(hash)
+	l.mu.Unlock()
+	if known {
+		if c := v.(*apiKeyLookup); now.Before(c.expires) {
+			return c.key, nil
+		}
+	}
+	if unknown && now.Before(u.(time.Time)) {
+		return nil, nil
+	}
+	if !l.lookupLimiters.allow(ip, "") {
+		return nil, nil
+	}
+	key, err := l.store.LookupAPIKey(ctx, secret)
+	if err != nil && !errors.Is(err, derrors.NotFound) {
+		return nil, err
+	}
+	// Unknown keys are cached apart from known ones, so that many unknown
+	// keys cannot evict the known ones.
+	l.mu.Lock()
+	if key == nil {
+		l.lookups.Remove(hash)
+		l.unknown.Add(hash, now.Add(apiKeyCacheTTL))
+	} else {
+		l.unknown.Remove(hash)
+		l.lookups.Add(hash, &apiKeyLookup{key: key, expires: now.Add(apiKeyCacheTTL)})
+	}
+	l.mu.Unlock()
+	return key, nil
+}
+
+// allow reports whether a request with the key with the given ID is within
+// the limits of tier, and if so counts it.
+func (l *APIKeyLimiter) allow(id int64, tier config.APIKeyTier) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lim, ok := l.limiters[id]
+	if !ok || lim.Limit() != rate.Limit(tier.QPS) || lim.Burst() != tier.Burst {
+		lim = rate.NewLimiter(rate.Limit(tier.QPS), tier.Burst)
+		l.limiters[id] = lim
+	}
+	if !lim.Allow() {
+		return false
+	}
+	l.usage[id]++
+	return true
+}
+
+// flushUsage records the usage of keys in the store every l.flushEvery,
+// until ctx is done.
+func (l *APIKeyLimiter) flushUsage(ctx context.Context) {
+	ticker := time.NewTicker(l.flushEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ctx2, cancel := context.WithTimeout(ctx, l.flushEvery)
+			if err := l.flush(ctx2); err != nil {
+				log.Error(ctx, err)
+			}
+			cancel()
+		}
+	}
+}
+
+// flush records the usage counted since the last flush in the store. If that
+// fails, the usage is kept for the next flush.
+func (l *APIKeyLimiter) flush(ctx context.Context) (err error) {
+	defer derrors.Wrap(&err, "flush")
+	l.mu.Lock()
+	usage := l.usage
+	l.usage = map[int64]int64{}
+	l.mu.Unlock()
+	if len(usage) == 0 {
+		return nil
+	}
+	if err := l.store.RecordAPIKeyUsage(ctx, time.Now(), usage); err != nil {
+		l.mu.Lock()
+		for id, n := range usage {
+			l.usage[id] += n
+		}
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
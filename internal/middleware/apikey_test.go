@@ -0,0 +1,168 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/apikey"
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+type fakeAPIKeyStore struct {
+	keys    map[string]*apikey.Key
+	lookups int
+	fail    bool
+	usage   map[int64]int64
+}
+
+func (s *fakeAPIKeyStore) LookupAPIKey(ctx context.Context, key string) (*apikey.Key, error) {
+	s.lookups++
+	if s.fail {
+		return nil, errors.New("store failure")
+	}
+	k, ok := s.keys[key]
+	if !ok {
+		return nil, derrors.NotFound
+	}
+	return k, nil
+}
+
+func (s *fakeAPIKeyStore) RecordAPIKeyUsage(ctx context.Context, t time.Time, requests map[int64]int64) error {
+	if s.fail {
+		return errors.New("store failure")
+	}
+	for id, n := range requests {
+		s.usage[id] += n
+	}
+	return nil
+}
+
+func TestAPIKeys(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := &fakeAPIKeyStore{
+		keys: map[string]*apikey.Key{
+			"good":  {ID: 1, Tier: "small"},
+			"other": {ID: 2, Tier: "missing"},
+		},
+		usage: map[int64]int64{},
+	}
+	settings := config.QuotaSettings{
+		QPS:        1,
+		Burst:      3,
+		MaxEntries: 10,
+		RecordOnly: boolptr(false),
+		APIKeyTiers: map[string]config.APIKeyTier{
+			"small": {QPS: 1, Burst: 2},
+		},
+	}
+	l := NewAPIKeyLimiter(ctx, time.Hour, store, settings)
+	var gotKey *apikey.Key
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = apikey.KeyFromContext(r.Context())
+	})
+	mw := APIKeys(l)(Quota(settings)(h))
+
+	serveFrom := func(ip, key string) int {
+		gotKey = nil
+		r := httptest.NewRequest("GET", "/api/v1/search?q=foo", nil)
+		if ip != "" {
+			r.Header.Set("X-Forwarded-For", ip)
+		}
+		if key != "" {
+			r.Header.Set(apikey.Header, key)
+		}
+		w := httptest.NewRecorder()
+		mw.ServeHTTP(w, r)
+		return w.Code
+	}
+	serve := func(key string) int { return serveFrom("", key) }
+
+	if got := serve(""); got != http.StatusOK || gotKey != nil {
+		t.Errorf("no key: got %d, key %v; want 200, no key", got, gotKey)
+	}
+	if got := serve("bad"); got != http.StatusUnauthorized {
+		t.Errorf("bad key: got %d, want 401", got)
+	}
+	if got := serve("other"); got != http.StatusOK || gotKey != nil {
+		t.Errorf("unknown tier: got %d, key %v; want 200, no key", got, gotKey)
+	}
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		if got := serve("good"); got != want {
+			t.Errorf("good key, #%d: got %d, want %d", i, got, want)
+		}
+	}
+	// Lookups are cached, including failed ones.
+	serve("bad")
+	if got, want := store.lookups, 3; got != want {
+		t.Errorf("got %d lookups, want %d", got, want)
+	}
+
+	// Unknown keys are limited by IP address, like requests without a key,
+	// and so are their lookups.
+	for i, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		if got := serveFrom("1.2.3.4", fmt.Sprintf("random%d", i)); got != want {
+			t.Errorf("random key, #%d: got %d, want %d", i, got, want)
+		}
+	}
+	if got, want := store.lookups, 6; got != want {
+		t.Errorf("got %d lookups, want %d", got, want)
+	}
+	// Unknown keys do not evict known ones.
+	for i := 0; i < apiKeyCacheSize; i++ {
+		l.lookup(ctx, fmt.Sprintf("unknown%d", i), "")
+	}
+	lookups := store.lookups
+	serve("good")
+	if store.lookups != lookups {
+		t.Error("the good key was looked up again after many unknown keys")
+	}
+
+	if err := l.flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[int64]int64{1: 2}, store.usage); diff != "" {
+		t.Errorf("usage mismatch (-want, +got):\n%s", diff)
+	}
+
+	// Usage that fails to be recorded is kept for the next flush.
+	time.Sleep(1100 * time.Millisecond)
+	serve("good")
+	store.fail = true
+	if err := l.flush(ctx); err == nil {
+		t.Fatal("flush succeeded with a failing store")
+	}
+	store.fail = false
+	if err := l.flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[int64]int64{1: 3}, store.usage); diff != "" {
+		t.Errorf("usage mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestQuotaSkipsAPIKeys(t *testing.T) {
+	mw := Quota(config.QuotaSettings{QPS: 1, Burst: 1, MaxEntries: 1, RecordOnly: boolptr(false)})
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 3; i++ {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("X-Forwarded-For", "1.2.3.4")
+		r = r.WithContext(apikey.NewContextWithKey(r.Context(), &apikey.Key{ID: 1}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("#%d: got %d, want 200", i, w.Code)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+
+	"golang.org/x/pkgsite/internal/auth"
+)
+
+// An Authenticator identifies the user making a request.
+type Authenticator interface {
+	// Authenticate returns the user making the request r. If it returns nil,
+	// it must have written a response to w, for example a redirect to a
+	// sign-in page.
+	Authenticate(w http.ResponseWriter, r *http.Request) *auth.User
+}
+
+// Authenticate requires every request to come from a user identified by a.
+// If allowedGroups is non-empty, the user must also be in one of those
+// groups. The user is stored in the request context; see
+// auth.UserFromContext.
+func Authenticate(a Authenticator, allowedGroups []string) Middleware {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u := a.Authenticate(w, r)
+			if u == nil {
+				return
+			}
+			if len(allowedGroups) > 0 && !u.InAnyGroup(allowedGroups) {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+			h.ServeHTTP(w, r.WithContext(auth.NewContextWithUser(r.Context(), u)))
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/auth"
+)
+
+type fakeAuthenticator struct{}
+
+// Authenticate returns a user whose email and group are the value of the
+// "user" query parameter, or writes a 401 if it is missing.
+func (fakeAuthenticator) Authenticate(w http.ResponseWriter, r *http.Request) *auth.User {
+	name := r.FormValue("user")
+	if name == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return nil
+	}
+	return &auth.User{Email: name + "@example.com", Groups: []string{name}}
+}
+
+func TestAuthenticate(t *testing.T) {
+	var gotEmail string
+	h := Authenticate(fakeAuthenticator{}, []string{"eng", "docs"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEmail = auth.UserFromContext(r.Context()).Email
+	}))
+	for _, test := range []struct {
+		user       string
+		wantStatus int
+		wantEmail  string
+	}{
+		{"", http.StatusUnauthorized, ""},
+		{"eng", http.StatusOK, "eng@example.com"},
+		{"sales", http.StatusForbidden, ""},
+	} {
+		gotEmail = ""
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/?user="+test.user, nil))
+		if w.Code != test.wantStatus {
+			t.Errorf("user %q: got status %d, want %d", test.user, w.Code, test.wantStatus)
+		}
+		if gotEmail != test.wantEmail {
+			t.Errorf("user %q: got email %q, want %q", test.user, gotEmail, test.wantEmail)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+
+	"golang.org/x/pkgsite/internal/config"
+)
+
+// CacheControl returns a middleware that sets the Cache-Control and
+// Surrogate-Control headers of responses from the policy of the class of the
+// request, as returned by class. Requests whose class has no policy are left
+// alone, and handlers may set the headers themselves to override the policy.
+// Only successful responses are cached: the headers are removed from other
+// responses, unless the handler set them.
+func CacheControl(policies map[string]config.CachePolicy, class func(*http.Request) string) Middleware {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := policies[class(r)]
+			if !ok {
+				h.ServeHTTP(w, r)
+				return
+			}
+			setHeader(w.Header(), "Cache-Control", p.CacheControl)
+			setHeader(w.Header(), "Surrogate-Control", p.SurrogateControl)
+			h.ServeHTTP(&cacheControlResponseWriter{ResponseWriter: w, policy: p}, r)
+		})
+	}
+}
+
+func setHeader(h http.Header, key, value string) {
+	if value != "" {
+		h.Set(key, value)
+	}
+}
+
+// cacheControlResponseWriter removes the headers set from policy from
+// unsuccessful responses.
+type cacheControlResponseWriter struct {
+	http.ResponseWriter
+	policy config.CachePolicy
+}
+
+func (w *cacheControlResponseWriter) WriteHeader(code int) {
+	if code != http.StatusOK && code != http.StatusNotModified {
+		h := w.Header()
+		if p := w.policy.CacheControl; p != "" && h.Get("Cache-Control") == p {
+			h.Del("Cache-Control")
+		}
+		if p := w.policy.SurrogateControl; p != "" && h.Get("Surrogate-Control") == p {
+			h.Del("Surrogate-Control")
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,53 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/config"
+)
+
+func TestCacheControl(t *testing.T) {
+	policies := map[string]config.CachePolicy{
+		"pinned": {CacheControl: "public, max-age=86400", SurrogateControl: "max-age=86400"},
+		"static": {CacheControl: "public, max-age=3600"},
+	}
+	class := func(r *http.Request) string { return r.FormValue("class") }
+	handler := CacheControl(policies, class)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cc := r.FormValue("set"); cc != "" {
+			w.Header().Set("Cache-Control", cc)
+		}
+		if r.FormValue("fail") != "" {
+			http.Error(w, "oops", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+
+	for _, test := range []struct {
+		target                     string
+		wantCacheControl, wantSurr string
+	}{
+		{"/?class=pinned", "public, max-age=86400", "max-age=86400"},
+		{"/?class=static", "public, max-age=3600", ""},
+		{"/?class=other", "", ""},
+		{"/", "", ""},
+		{"/?class=pinned&set=no-store", "no-store", "max-age=86400"},
+		{"/?class=pinned&fail=1", "", ""},
+		{"/?class=pinned&fail=1&set=no-store", "no-store", ""},
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", test.target, nil))
+		if got := w.Header().Get("Cache-Control"); got != test.wantCacheControl {
+			t.Errorf("%s: Cache-Control = %q, want %q", test.target, got, test.wantCacheControl)
+		}
+		if got := w.Header().Get("Surrogate-Control"); got != test.wantSurr {
+			t.Errorf("%s: Surrogate-Control = %q, want %q", test.target, got, test.wantSurr)
+		}
+	}
+}
@@ -12,12 +12,14 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v7"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
+	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/log"
 )
 
@@ -105,6 +107,60 @@ func Cache(name string, client *redis.Client, expirer Expirer) Middleware {
 
 const cacheBypassHeader = "x-go-discovery-bypass-cache"
 
+// moduleKeysTTL is the expiration of the sets of cache keys tagged with a
+// module. It must be longer than the TTL of any cached page.
+const moduleKeysTTL = 48 * time.Hour
+
+// cacheKey returns the key under which the response to r is cached: the cache
+// name, the URL path, and the query parameters in a canonical order.
+func cacheKey(name string, r *http.Request) string {
+	key := name + ":" + r.URL.Path
+	if q := r.URL.Query(); len(q) > 0 {
+		key += "?" + q.Encode()
+	}
+	return key
+}
+
+// moduleKeysKey returns the key of the redis set holding the cache keys of the
+// pages tagged with modulePath.
+func moduleKeysKey(modulePath string) string {
+	return "module-keys:" + modulePath
+}
+
+type cacheTagsKey struct{}
+
+// cacheTags holds the module paths that the page being cached depends on.
+type cacheTags struct {
+	mu      sync.Mutex
+	modules []string
+}
+
+// TagCachedModule records that the page being served for ctx depends on
+// modulePath, so that it is removed from the cache by InvalidateModule. It
+// does nothing if the page is not being cached.
+func TagCachedModule(ctx context.Context, modulePath string) {
+	tags, ok := ctx.Value(cacheTagsKey{}).(*cacheTags)
+	if !ok {
+		return
+	}
+	tags.mu.Lock()
+	defer tags.mu.Unlock()
+	tags.modules = append(tags.modules, modulePath)
+}
+
+// InvalidateModule removes all cached pages tagged with modulePath from the
+// cache.
+func InvalidateModule(ctx context.Context, client *redis.Client, modulePath string) (err error) {
+	defer derrors.Wrap(&err, "InvalidateModule(%q)", modulePath)
+
+	setKey := moduleKeysKey(modulePath)
+	keys, err := client.WithContext(ctx).SMembers(setKey).Result()
+	if err != nil {
+		return err
+	}
+	return client.WithContext(ctx).Del(append(keys, setKey)...).Err()
+}
+
 func (c *cache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// To facilitate load testing and debugging, we check for a magic header that
 	// bypasses the cache. This completely avoids the cached serving path, and
@@ -113,8 +169,14 @@ func (c *cache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		c.delegate.ServeHTTP(w, r)
 		return
 	}
+	// Conditional requests are passed to the handler, which can answer them
+	// with a 304 (Not Modified) without rendering the page.
+	if r.Header.Get("If-None-Match") != "" {
+		c.delegate.ServeHTTP(w, r)
+		return
+	}
 	ctx := r.Context()
-	key := r.URL.String()
+	key := cacheKey(c.name, r)
 	if reader, ok := c.get(ctx, key); ok {
 		recordCacheResult(ctx, c.name, true)
 		if _, err := io.Copy(w, reader); err != nil {
@@ -124,13 +186,14 @@ func (c *cache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	recordCacheResult(ctx, c.name, false)
 	rec := newRecorder(w)
-	c.delegate.ServeHTTP(rec, r)
+	tags := &cacheTags{}
+	c.delegate.ServeHTTP(rec, r.WithContext(context.WithValue(ctx, cacheTagsKey{}, tags)))
 	if rec.bufErr == nil && (rec.statusCode == 0 || rec.statusCode == http.StatusOK) {
 		ttl := c.expirer(r)
 		if testMode {
-			c.put(ctx, key, rec, ttl)
+			c.put(ctx, key, rec, ttl, tags.modules)
 		} else {
-			go c.put(ctx, key, rec, ttl)
+			go c.put(ctx, key, rec, ttl, tags.modules)
 		}
 	}
 }
@@ -163,7 +226,7 @@ func (c *cache) get(ctx context.Context, key string) (io.Reader, bool) {
 	return zr, true
 }
 
-func (c *cache) put(ctx context.Context, key string, rec *cacheRecorder, ttl time.Duration) {
+func (c *cache) put(ctx context.Context, key string, rec *cacheRecorder, ttl time.Duration, modulePaths []string) {
 	if err := rec.zipWriter.Close(); err != nil {
 		log.Errorf(ctx, "cache: error closing zip for %q: %v", key, err)
 		return
@@ -175,6 +238,16 @@ func (c *cache) put(ctx context.Context, key string, rec *cacheRecorder, ttl tim
 	if err != nil {
 		recordCacheError(ctx, c.name, "SET")
 		log.Errorf(ctx, "cache set %q: %v", key, err)
+		return
+	}
+	for _, mp := range modulePaths {
+		pipe := c.client.WithContext(setCtx).TxPipeline()
+		pipe.SAdd(moduleKeysKey(mp), key)
+		pipe.Expire(moduleKeysKey(mp), moduleKeysTTL)
+		if _, err := pipe.Exec(); err != nil {
+			recordCacheError(ctx, c.name, "TAG")
+			log.Errorf(ctx, "cache tag %q with %q: %v", key, mp, err)
+		}
 	}
 }
 
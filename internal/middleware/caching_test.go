@@ -5,6 +5,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -177,3 +178,63 @@ func TestCache(t *testing.T) {
 		}
 	}
 }
+
+func TestCacheInvalidateModule(t *testing.T) {
+	testMode = true
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	c := redis.NewClient(&redis.Options{Addr: s.Addr()})
+
+	var body string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		TagCachedModule(r.Context(), "example.com/mod")
+		fmt.Fprint(w, body)
+	})
+	ts := httptest.NewServer(Cache("details", c, TTL(time.Hour))(handler))
+	defer ts.Close()
+
+	get := func(path string) string {
+		resp, err := ts.Client().Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	body = "1"
+	get("/example.com/mod?tab=doc&x=1")
+	body = "2"
+	// The query parameters are canonicalized, so this is a cache hit.
+	if got := get("/example.com/mod?x=1&tab=doc"); got != "1" {
+		t.Errorf("before invalidation: got %q, want %q", got, "1")
+	}
+	if err := InvalidateModule(context.Background(), c, "example.com/mod"); err != nil {
+		t.Fatal(err)
+	}
+	if got := get("/example.com/mod?tab=doc&x=1"); got != "2" {
+		t.Errorf("after invalidation: got %q, want %q", got, "2")
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	for _, test := range []struct {
+		url, want string
+	}{
+		{"/net/http", "details:/net/http"},
+		{"/net/http?tab=doc", "details:/net/http?tab=doc"},
+		{"/net/http?tab=doc&a=b", "details:/net/http?a=b&tab=doc"},
+	} {
+		r := httptest.NewRequest("GET", test.url, nil)
+		if got := cacheKey("details", r); got != test.want {
+			t.Errorf("cacheKey(%q) = %q, want %q", test.url, got, test.want)
+		}
+	}
+}
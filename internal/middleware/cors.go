@@ -0,0 +1,81 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/apikey"
+	"golang.org/x/pkgsite/internal/config"
+)
+
+// CORS returns a middleware that lets pages on the origins allowed by
+// settings call the routes under pathPrefix from browsers, as described in
+// https://fetch.spec.whatwg.org/#http-cors-protocol. It answers preflight
+// requests itself, so it must come before any middleware that rejects OPTIONS
+// requests. Credentials are never allowed.
+func CORS(settings config.CORSSettings, pathPrefix string) Middleware {
+	methods := strings.Join(settings.AllowedMethods, ", ")
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" || !strings.HasPrefix(r.URL.Path, pathPrefix) {
+				h.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			allowOrigin := corsAllowedOrigin(origin, settings.AllowedOrigins)
+			if allowOrigin == "" {
+				if preflight {
+					http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+					return
+				}
+				// The browser will not let the page read the response.
+				h.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+			if !preflight {
+				h.ServeHTTP(w, r)
+				return
+			}
+			if !containsString(settings.AllowedMethods, r.Header.Get("Access-Control-Request-Method")) {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, "+apikey.Header)
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(settings.MaxAge))
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// corsAllowedOrigin returns the value of the Access-Control-Allow-Origin
+// header for a request from origin, or the empty string if origin is not
+// allowed.
+func corsAllowedOrigin(origin string, allowed []string) string {
+	for _, a := range allowed {
+		if a == "*" {
+			return "*"
+		}
+		if a == origin {
+			return origin
+		}
+	}
+	return ""
+}
+
+func containsString(a []string, s string) bool {
+	for _, x := range a {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/config"
+)
+
+func TestCORS(t *testing.T) {
+	settings := config.CORSSettings{
+		AllowedOrigins: []string{"https://editor.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		MaxAge:         600,
+	}
+	handler := CORS(settings, "/api/")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	for _, test := range []struct {
+		name, method, path, origin, requestMethod string
+		wantStatus                                int
+		wantAllowOrigin, wantMaxAge               string
+	}{
+		{"no origin", "GET", "/api/v1/module/a.com/m", "", "", 200, "", ""},
+		{"allowed", "GET", "/api/v1/module/a.com/m", "https://editor.example.com", "", 200, "https://editor.example.com", ""},
+		{"other origin", "GET", "/api/v1/module/a.com/m", "https://evil.example.com", "", 200, "", ""},
+		{"not api", "GET", "/a.com/m", "https://editor.example.com", "", 200, "", ""},
+		{"preflight", "OPTIONS", "/api/v1/deps", "https://editor.example.com", "POST", 204, "https://editor.example.com", "600"},
+		{"preflight other origin", "OPTIONS", "/api/v1/deps", "https://evil.example.com", "POST", 403, "", ""},
+		{"preflight bad method", "OPTIONS", "/api/v1/deps", "https://editor.example.com", "DELETE", 403, "https://editor.example.com", ""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, test.path, nil)
+			if test.origin != "" {
+				r.Header.Set("Origin", test.origin)
+			}
+			if test.requestMethod != "" {
+				r.Header.Set("Access-Control-Request-Method", test.requestMethod)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, test.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != test.wantAllowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, test.wantAllowOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Max-Age"); got != test.wantMaxAge {
+				t.Errorf("Access-Control-Max-Age = %q, want %q", got, test.wantMaxAge)
+			}
+		})
+	}
+
+	wildcard := CORS(config.CORSSettings{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}}, "/api/")(handler)
+	r := httptest.NewRequest("GET", "/api/v1/module/a.com/m", nil)
+	r.Header.Set("Origin", "https://any.example.com")
+	w := httptest.NewRecorder()
+	wildcard.ServeHTTP(w, r)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("wildcard: Access-Control-Allow-Origin = %q, want %q", got, "*")
+	}
+}
@@ -5,12 +5,39 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
 	"cloud.google.com/go/errorreporting"
+	"golang.org/x/pkgsite/internal/log"
 )
 
+// An ErrorReporter reports server errors to an error aggregation service.
+// *errorreporting.Client, which reports to Stackdriver, is an ErrorReporter.
+// Report is called while the request is being handled, so it should not
+// block on the service.
+type ErrorReporter interface {
+	Report(errorreporting.Entry)
+}
+
+// LogReporter is an ErrorReporter that only logs errors. It can be used when
+// running locally.
+type LogReporter struct{}
+
+// Report implements the ErrorReporter interface via our internal log package.
+func (LogReporter) Report(e errorreporting.Entry) {
+	ctx := context.Background()
+	if e.Req != nil {
+		ctx = e.Req.Context()
+	}
+	if len(e.Stack) > 0 {
+		log.Errorf(ctx, "%v\n%s", e.Error, e.Stack)
+		return
+	}
+	log.Error(ctx, e.Error)
+}
+
 // ErrorReporting returns a middleware that reports any server errors using the
 // report func.
 func ErrorReporting(report func(errorreporting.Entry)) Middleware {
@@ -6,19 +6,36 @@ package middleware
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"hash/fnv"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"go.opencensus.io/tag"
+
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/dcensus"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/experiment"
 	"golang.org/x/pkgsite/internal/log"
 )
 
-const experimentQueryParamKey = "experiment"
+const (
+	experimentQueryParamKey = "experiment"
+
+	// experimentCookie holds a random ID that assigns a browser to
+	// experiment cohorts, so that its experiments do not change with its IP
+	// address.
+	experimentCookie = "pkgsite-experiment-id"
+
+	// experimentCookieMaxAge is how long a browser keeps its cohorts.
+	experimentCookieMaxAge = 365 * 24 * time.Hour
+)
 
 // An Experimenter contains information about active experiments from the
 // experiment source.
@@ -51,20 +68,64 @@ func NewExperimenter(ctx context.Context, pollEvery time.Duration, es internal.E
 func Experiment(e *Experimenter) Middleware {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if e.hasPartialRollout() {
+				r = setExperimentCookie(w, r)
+			}
 			r2 := e.setExperimentsForRequest(r)
 			h.ServeHTTP(w, r2)
 		})
 	}
 }
 
+// hasPartialRollout reports whether some experiment is enabled for only some
+// requests.
+func (e *Experimenter) hasPartialRollout() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, exp := range e.snapshot {
+		if exp.Rollout > 0 && exp.Rollout < 100 {
+			return true
+		}
+	}
+	return false
+}
+
+// setExperimentCookie gives a new random experiment ID to a request without
+// one, and sets the cookie so that later requests from the same browser use
+// the same ID. It returns the request with the cookie added.
+func setExperimentCookie(w http.ResponseWriter, r *http.Request) *http.Request {
+	if _, err := r.Cookie(experimentCookie); err == nil {
+		return r
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Errorf(r.Context(), "setExperimentCookie: rand.Read: %v", err)
+		return r
+	}
+	c := &http.Cookie{
+		Name:     experimentCookie,
+		Value:    hex.EncodeToString(b),
+		Path:     "/",
+		MaxAge:   int(experimentCookieMaxAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	http.SetCookie(w, c)
+	r2 := r.Clone(r.Context())
+	r2.AddCookie(c)
+	return r2
+}
+
 // setExperimentsForRequest sets the experiments for a given request.
-// Experiments should be stable for a given IP address.
+// Experiments should be stable for a given experiment ID or IP address.
 func (e *Experimenter) setExperimentsForRequest(r *http.Request) *http.Request {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
 	set := map[string]bool{}
+	known := map[string]bool{}
 	for _, exp := range e.snapshot {
+		known[exp.Name] = true
 		if shouldSetExperiment(r, exp) {
 			set[exp.Name] = true
 		}
@@ -73,7 +134,39 @@ func (e *Experimenter) setExperimentsForRequest(r *http.Request) *http.Request {
 	for _, k := range keys {
 		set[k] = true
 	}
-	return r.WithContext(experiment.NewContext(r.Context(), experiment.NewSet(set)))
+	ctx := experiment.NewContext(r.Context(), experiment.NewSet(set))
+	// Tag metrics recorded for the request with its experiments, so that
+	// cohorts can be compared.
+	if tctx, err := tag.New(ctx, tag.Upsert(dcensus.KeyExperiments, experimentsTagValue(set, known))); err == nil {
+		ctx = tctx
+	} else {
+		log.Errorf(ctx, "setExperimentsForRequest: tag.New: %v", err)
+	}
+	return r.WithContext(ctx)
+}
+
+// maxTagValueLength is the maximum length of a census tag value.
+const maxTagValueLength = 255
+
+// experimentsTagValue returns the value of the dcensus.KeyExperiments tag for
+// a set of experiments. Only known experiments are included, so that the
+// experiment query parameter cannot create arbitrary tag values.
+func experimentsTagValue(set, known map[string]bool) string {
+	var names []string
+	for name := range set {
+		if known[name] {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	sort.Strings(names)
+	v := strings.Join(names, ",")
+	if len(v) > maxTagValueLength {
+		v = v[:maxTagValueLength]
+	}
+	return v
 }
 
 // pollUpdates polls the experiment source for updates to the snapshot, until
@@ -111,10 +204,10 @@ func (e *Experimenter) loadNextSnapshot(ctx context.Context) (err error) {
 }
 
 // shouldSetExperiment reports whether a given request should be enrolled in
-// the experiment, based on the ip. e.Name, and e.Rollout.
+// the experiment, based on its cohort key, e.Name, and e.Rollout.
 //
-// Requests from empty ip addresses are never enrolled.
-// All requests from the same IP will be enrolled in the same set of
+// Requests without a cohort key are never enrolled.
+// All requests with the same key will be enrolled in the same set of
 // experiments.
 func shouldSetExperiment(r *http.Request, e *internal.Experiment) bool {
 	if e.Rollout == 0 {
@@ -123,11 +216,20 @@ func shouldSetExperiment(r *http.Request, e *internal.Experiment) bool {
 	if e.Rollout == 100 {
 		return true
 	}
-	ip := ipKey(r.Header.Get("X-Forwarded-For"))
-	if ip == "" {
+	key := cohortKey(r)
+	if key == "" {
 		return false
 	}
 	h := fnv.New32a()
-	fmt.Fprintf(h, "%s %s", ip, e.Name)
+	fmt.Fprintf(h, "%s %s", key, e.Name)
 	return uint(h.Sum32())%(100/e.Rollout) == 0
 }
+
+// cohortKey returns the key that assigns r to experiment cohorts: the ID in
+// its experiment cookie if it has one, and otherwise its IP address.
+func cohortKey(r *http.Request) string {
+	if c, err := r.Cookie(experimentCookie); err == nil && c.Value != "" {
+		return "id:" + c.Value
+	}
+	return ipKey(r.Header.Get("X-Forwarded-For"))
+}
@@ -16,7 +16,9 @@ import (
 	"testing"
 	"time"
 
+	"go.opencensus.io/tag"
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/dcensus"
 	"golang.org/x/pkgsite/internal/experiment"
 )
 
@@ -138,3 +140,93 @@ func TestShouldSetExperiment(t *testing.T) {
 		})
 	}
 }
+
+func TestExperimentCookie(t *testing.T) {
+	ctx := context.Background()
+	const testFeature = "test-feature"
+	source := &testExperimentSource{
+		experiments: []*internal.Experiment{
+			{Name: testFeature, Rollout: 50},
+		},
+	}
+	experimenter, err := NewExperimenter(ctx, time.Hour, source, LocalLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var featureIsOn bool
+	handler := Experiment(experimenter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		featureIsOn = experiment.IsActive(r.Context(), testFeature)
+	}))
+
+	// A request without an ID is given one.
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != experimentCookie || cookies[0].Value == "" {
+		t.Fatalf("got cookies %v, want one %q cookie", cookies, experimentCookie)
+	}
+	want := featureIsOn
+
+	// Later requests with that ID are in the same cohort, whatever their IP.
+	for i := 0; i < 20; i++ {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("1.2.%d.4", i))
+		req.AddCookie(cookies[0])
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if featureIsOn != want {
+			t.Fatalf("request from 1.2.%d.4: active = %t, want %t", i, featureIsOn, want)
+		}
+		if got := w.Result().Cookies(); len(got) != 0 {
+			t.Fatalf("got cookies %v, want none", got)
+		}
+	}
+
+	// No cookie is set when there are no partial rollouts.
+	source.updatedExperiments([]*internal.Experiment{{Name: testFeature, Rollout: 100}})
+	if err := experimenter.loadNextSnapshot(ctx); err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if got := w.Result().Cookies(); len(got) != 0 {
+		t.Errorf("got cookies %v, want none", got)
+	}
+}
+
+func TestExperimentsTag(t *testing.T) {
+	ctx := context.Background()
+	source := &testExperimentSource{
+		experiments: []*internal.Experiment{
+			{Name: "b-feature", Rollout: 100},
+			{Name: "a-feature", Rollout: 100},
+			{Name: "off-feature", Rollout: 0},
+		},
+	}
+	experimenter, err := NewExperimenter(ctx, time.Hour, source, LocalLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		url, want string
+	}{
+		{"/", "a-feature,b-feature"},
+		{"/?experiment=off-feature", "a-feature,b-feature,off-feature"},
+		// Unknown experiments are not tagged.
+		{"/?experiment=whatever", "a-feature,b-feature"},
+	} {
+		var got string
+		handler := Experiment(experimenter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, _ = tag.FromContext(r.Context()).Value(dcensus.KeyExperiments)
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", test.url, nil))
+		if got != test.want {
+			t.Errorf("%s: got tag %q, want %q", test.url, got, test.want)
+		}
+	}
+
+	if got, want := experimentsTagValue(nil, nil), "none"; got != want {
+		t.Errorf("experimentsTagValue(nil, nil) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"golang.org/x/pkgsite/internal/config"
+)
+
+var (
+	keyLoadShedReason = tag.MustNewKey("loadshed.reason")
+	loadShedResults   = stats.Int64(
+		"go-discovery/loadshed_count",
+		"The number of requests rejected by load shedding.",
+		stats.UnitDimensionless,
+	)
+	// LoadShedCount is a counter of requests rejected by load shedding, by
+	// reason.
+	LoadShedCount = &view.View{
+		Name:        "go-discovery/loadshed/count",
+		Measure:     loadShedResults,
+		Aggregation: view.Count(),
+		Description: "requests rejected by load shedding, by reason",
+		TagKeys:     []tag.Key{keyLoadShedReason},
+	}
+)
+
+const (
+	// loadShedRetryAfter is the value of the Retry-After header sent with
+	// rejected requests.
+	loadShedRetryAfter = 10 * time.Second
+
+	// dbWaitSampleInterval is how often the DB pool statistics are sampled.
+	dbWaitSampleInterval = time.Second
+)
+
+// LoadShedding returns a middleware that rejects requests for expensiveRoutes,
+// the ExpensiveRoutes of config.QuotaSettings, with a 503 and a Retry-After
+// header, when either more than maxInFlight expensive requests are already
+// being served, or the average time spent waiting for a DB connection over the
+// last second exceeds maxDBWait. Cheap requests are always served, so that a
+// traffic spike degrades gracefully instead of causing timeouts on every page.
+//
+// dbStats reports the DB connection pool statistics. If it is nil, only the
+// number of in-flight requests is considered.
+func LoadShedding(maxInFlight int, maxDBWait time.Duration, dbStats func() sql.DBStats, expensiveRoutes map[string]config.RouteQuota) Middleware {
+	var (
+		inFlight int64
+		waits    = &dbWaitTracker{stats: dbStats}
+	)
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if expensiveRoute(r, expensiveRoutes) == "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+			var reason string
+			if n := atomic.AddInt64(&inFlight, 1); n > int64(maxInFlight) {
+				reason = "in_flight"
+			} else if dbStats != nil && waits.averageWait(time.Now()) > maxDBWait {
+				reason = "db_wait"
+			}
+			defer atomic.AddInt64(&inFlight, -1)
+			if reason != "" {
+				recordLoadShedMetric(reason)
+				w.Header().Set("Retry-After", strconv.Itoa(int(loadShedRetryAfter.Seconds())))
+				const su = http.StatusServiceUnavailable
+				http.Error(w, http.StatusText(su), su)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// dbWaitTracker computes the average time spent waiting for a DB connection,
+// from the differences between successive samples of the pool statistics.
+type dbWaitTracker struct {
+	stats func() sql.DBStats
+
+	mu          sync.Mutex
+	lastSample  time.Time
+	last        sql.DBStats
+	lastAverage time.Duration
+}
+
+// averageWait returns the average wait for a DB connection between the last
+// two samples. It takes a new sample if the last one is older than
+// dbWaitSampleInterval.
+func (t *dbWaitTracker) averageWait(now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if now.Sub(t.lastSample) < dbWaitSampleInterval {
+		return t.lastAverage
+	}
+	s := t.stats()
+	if !t.lastSample.IsZero() {
+		if n := s.WaitCount - t.last.WaitCount; n > 0 {
+			t.lastAverage = (s.WaitDuration - t.last.WaitDuration) / time.Duration(n)
+		} else {
+			t.lastAverage = 0
+		}
+	}
+	t.last = s
+	t.lastSample = now
+	return t.lastAverage
+}
+
+func recordLoadShedMetric(reason string) {
+	stats.RecordWithTags(context.Background(), []tag.Mutator{
+		tag.Upsert(keyLoadShedReason, reason),
+	}, loadShedResults.M(1))
+}
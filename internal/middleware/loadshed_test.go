@@ -0,0 +1,90 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/pkgsite/internal/config"
+)
+
+func TestLoadSheddingInFlight(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search" {
+			started <- struct{}{}
+			<-release
+		}
+	})
+	ts := httptest.NewServer(LoadShedding(1, time.Second, nil, map[string]config.RouteQuota{"/search": {}})(h))
+	defer ts.Close()
+
+	get := func(path string) *http.Response {
+		res, err := ts.Client().Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res
+	}
+
+	done := make(chan int)
+	go func() {
+		res, err := ts.Client().Get(ts.URL + "/search")
+		if err != nil {
+			done <- 0
+			return
+		}
+		res.Body.Close()
+		done <- res.StatusCode
+	}()
+	<-started
+
+	// A second expensive request is rejected while the first is in flight.
+	res := get("/search")
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("/search: got %d, want %d", res.StatusCode, http.StatusServiceUnavailable)
+	}
+	if got := res.Header.Get("Retry-After"); got != "10" {
+		t.Errorf("Retry-After = %q, want %q", got, "10")
+	}
+	// Cheap requests are still served.
+	if res := get("/net/http"); res.StatusCode != http.StatusOK {
+		t.Errorf("/net/http: got %d, want %d", res.StatusCode, http.StatusOK)
+	}
+
+	close(release)
+	if got := <-done; got != http.StatusOK {
+		t.Errorf("first /search: got %d, want %d", got, http.StatusOK)
+	}
+}
+
+func TestDBWaitTracker(t *testing.T) {
+	var s sql.DBStats
+	tr := &dbWaitTracker{stats: func() sql.DBStats { return s }}
+	now := time.Now()
+
+	if got := tr.averageWait(now); got != 0 {
+		t.Errorf("first sample: got %v, want 0", got)
+	}
+	s = sql.DBStats{WaitCount: 4, WaitDuration: 2 * time.Second}
+	// Samples are taken at most once per interval.
+	if got := tr.averageWait(now.Add(dbWaitSampleInterval / 2)); got != 0 {
+		t.Errorf("before interval: got %v, want 0", got)
+	}
+	now = now.Add(dbWaitSampleInterval)
+	if got, want := tr.averageWait(now), 500*time.Millisecond; got != want {
+		t.Errorf("after waits: got %v, want %v", got, want)
+	}
+	now = now.Add(dbWaitSampleInterval)
+	if got := tr.averageWait(now); got != 0 {
+		t.Errorf("no new waits: got %v, want 0", got)
+	}
+}
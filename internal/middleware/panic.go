@@ -5,19 +5,35 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
+	"runtime/debug"
 
-	"golang.org/x/pkgsite/internal/log"
+	"cloud.google.com/go/errorreporting"
 )
 
 // Panic returns a middleware that executes panicHandler on any panic
-// originating from the delegate handler.
-func Panic(panicHandler http.Handler) Middleware {
+// originating from the delegate handler. The panic and its stack trace are
+// sent to reporter.
+//
+// A panic with http.ErrAbortHandler, used to abort a response that has
+// already been partly written, is passed on to the server.
+func Panic(panicHandler http.Handler, reporter ErrorReporter) Middleware {
+	if reporter == nil {
+		reporter = LogReporter{}
+	}
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if e := recover(); e != nil {
-					log.Errorf(r.Context(), "middleware.Panic: %v", e)
+					if e == http.ErrAbortHandler {
+						panic(e)
+					}
+					reporter.Report(errorreporting.Entry{
+						Error: fmt.Errorf("middleware.Panic: %v", e),
+						Req:   r,
+						Stack: debug.Stack(),
+					})
 					panicHandler.ServeHTTP(w, r)
 				}
 			}()
@@ -9,7 +9,10 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"cloud.google.com/go/errorreporting"
 )
 
 func TestPanic(t *testing.T) {
@@ -24,7 +27,8 @@ func TestPanic(t *testing.T) {
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(w, "don't panic")
 	})
-	mw := Panic(panicHandler)
+	reporter := &fakeReporter{}
+	mw := Panic(panicHandler, reporter)
 	ts := httptest.NewServer(mw(handler))
 
 	tests := []struct {
@@ -39,6 +43,7 @@ func TestPanic(t *testing.T) {
 	for _, test := range tests {
 		t.Run(fmt.Sprintf("doPanic=%t", test.doPanic), func(t *testing.T) {
 			doPanic = test.doPanic
+			reporter.entries = nil
 			resp, err := ts.Client().Get(ts.URL)
 			if err != nil {
 				t.Fatal(err)
@@ -54,6 +59,49 @@ func TestPanic(t *testing.T) {
 			if got := string(body); got != test.wantBody {
 				t.Errorf("body=%q, want %q", got, test.wantBody)
 			}
+			if !test.doPanic {
+				if len(reporter.entries) != 0 {
+					t.Errorf("got %d reported errors, want 0", len(reporter.entries))
+				}
+				return
+			}
+			if len(reporter.entries) != 1 {
+				t.Fatalf("got %d reported errors, want 1", len(reporter.entries))
+			}
+			e := reporter.entries[0]
+			if !strings.Contains(e.Error.Error(), "panic!") {
+				t.Errorf("reported error %q does not mention the panic value", e.Error)
+			}
+			if !strings.Contains(string(e.Stack), "panic_test.go") {
+				t.Errorf("reported stack does not include the panicking handler:\n%s", e.Stack)
+			}
 		})
 	}
 }
+
+type fakeReporter struct {
+	entries []errorreporting.Entry
+}
+
+func (r *fakeReporter) Report(e errorreporting.Entry) {
+	r.entries = append(r.entries, e)
+}
+
+func TestPanicAbortHandler(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+	panicHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("panicHandler called")
+	})
+	reporter := &fakeReporter{}
+	defer func() {
+		if e := recover(); e != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", e)
+		}
+		if len(reporter.entries) != 0 {
+			t.Errorf("got %d reported errors, want 0", len(reporter.entries))
+		}
+	}()
+	Panic(panicHandler, reporter)(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
@@ -16,9 +16,15 @@ import (
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
+	"golang.org/x/pkgsite/internal/apikey"
 	"golang.org/x/pkgsite/internal/config"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -40,16 +46,27 @@ var (
 
 // Quota implements a simple IP-based rate limiter. Each set of incoming IP
 // addresses with the same low-order byte gets qps requests per second, with the
-// given burst.
-// Information is kept in an LRU cache of size maxEntries.
+// given burst. Requests for each of settings.ExpensiveRoutes draw from a
+// separate budget. Requests made with an API key (see APIKeys) are not
+// limited, nor are requests from allow-listed crawlers for routes that are not
+// expensive. Information is kept in an LRU cache of size maxEntries.
 //
 // If a request is disallowed, a 429 (TooManyRequests) will be served.
+// Requests that are allowed but that APIKeys found to have an unknown API key
+// are rejected with a 401 (Unauthorized).
 func Quota(settings config.QuotaSettings) Middleware {
-	var mu sync.Mutex
-	cache := lru.New(settings.MaxEntries)
-
-	return func(h http.Handler) http.Handler {
+	limiters := newIPLimiters(settings)
+	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := next
+			if hasUnknownAPIKey(r.Context()) {
+				h = http.HandlerFunc(rejectUnknownAPIKey)
+			}
+			if apikey.KeyFromContext(r.Context()) != nil {
+				// Limited by APIKeys instead.
+				h.ServeHTTP(w, r)
+				return
+			}
 			for _, url := range settings.AcceptedURLs {
 				if r.Referer() == url {
 					recordQuotaMetric("accepted")
@@ -58,22 +75,18 @@ func Quota(settings config.QuotaSettings) Middleware {
 					return
 				}
 			}
+			route := expensiveRoute(r, settings.ExpensiveRoutes)
+			// Anyone can claim to be a crawler, so crawlers are still limited
+			// on expensive routes.
+			if route == "" && isAllowedUserAgent(r.UserAgent(), settings.AllowedUserAgents) {
+				recordQuotaMetric("accepted")
+				h.ServeHTTP(w, r)
+				return
+			}
 
-			key := ipKey(r.Header.Get("X-Forwarded-For"))
-			// key is empty if we couldn't parse an IP, or there is no IP.
+			// The key is empty if we couldn't parse an IP, or there is no IP.
 			// Fail open in this case: allow serving.
-			var limiter *rate.Limiter
-			if key != "" {
-				mu.Lock()
-				if v, ok := cache.Get(key); ok {
-					limiter = v.(*rate.Limiter)
-				} else {
-					limiter = rate.NewLimiter(rate.Limit(settings.QPS), settings.Burst)
-					cache.Add(key, limiter)
-				}
-				mu.Unlock()
-			}
-			blocked := limiter != nil && !limiter.Allow()
+			blocked := !limiters.allow(ipKey(r.Header.Get("X-Forwarded-For")), route)
 			recordQuotaMetric(strconv.FormatBool(blocked))
 			if blocked && settings.RecordOnly != nil && !*settings.RecordOnly {
 				const tmr = http.StatusTooManyRequests
@@ -85,6 +98,95 @@ func Quota(settings config.QuotaSettings) Middleware {
 	}
 }
 
+// ipLimiters holds the rate limiters of sets of IP addresses, in an LRU
+// cache.
+type ipLimiters struct {
+	settings                     config.QuotaSettings
+	expensiveQPS, expensiveBurst int
+
+	mu    sync.Mutex
+	cache *lru.Cache
+}
+
+func newIPLimiters(settings config.QuotaSettings) *ipLimiters {
+	l := &ipLimiters{
+		settings:       settings,
+		expensiveQPS:   settings.ExpensiveQPS,
+		expensiveBurst: settings.ExpensiveBurst,
+		cache:          lru.New(settings.MaxEntries),
+	}
+	if l.expensiveQPS == 0 {
+		l.expensiveQPS = settings.QPS
+	}
+	if l.expensiveBurst == 0 {
+		l.expensiveBurst = settings.Burst
+	}
+	return l
+}
+
+// allow reports whether a request from the IP addresses of key, as returned
+// by ipKey, is within their quota. route is the expensive route whose budget
+// the request draws from, or "" for the general budget. Requests with an
+// empty key are always allowed.
+func (l *ipLimiters) allow(key, route string) bool {
+	if key == "" {
+		return true
+	}
+	qps, burst := l.settings.QPS, l.settings.Burst
+	if route != "" {
+		key += " " + route
+		q := l.settings.ExpensiveRoutes[route]
+		qps, burst = q.QPS, q.Burst
+		if qps == 0 {
+			qps = l.expensiveQPS
+		}
+		if burst == 0 {
+			burst = l.expensiveBurst
+		}
+	}
+	l.mu.Lock()
+	var limiter *rate.Limiter
+	if v, ok := l.cache.Get(key); ok {
+		limiter = v.(*rate.Limiter)
+	} else {
+		limiter = rate.NewLimiter(rate.Limit(qps), burst)
+		l.cache.Add(key, limiter)
+	}
+	l.mu.Unlock()
+	return limiter.Allow()
+}
+
+// expensiveRoute returns the route of routes, the ExpensiveRoutes of
+// config.QuotaSettings, that r is for: its path, like "/search", or its tab,
+// like "tab=importedby". It returns "" if r is not for an expensive route.
+func expensiveRoute(r *http.Request, routes map[string]config.RouteQuota) string {
+	if _, ok := routes[r.URL.Path]; ok {
+		return r.URL.Path
+	}
+	if tab := r.URL.Query().Get("tab"); tab != "" {
+		if _, ok := routes["tab="+tab]; ok {
+			return "tab=" + tab
+		}
+	}
+	return ""
+}
+
+// isAllowedUserAgent reports whether ua contains one of the allowed
+// substrings. Since the User-Agent header is easily spoofed, the list should
+// only contain crawlers that are worth the risk of abuse, and requests for
+// expensive routes are limited anyway.
+func isAllowedUserAgent(ua string, allowed []string) bool {
+	if ua == "" {
+		return false
+	}
+	for _, a := range allowed {
+		if strings.Contains(ua, a) {
+			return true
+		}
+	}
+	return false
+}
+
 func recordQuotaMetric(blocked string) {
 	stats.RecordWithTags(context.Background(), []tag.Mutator{
 		tag.Upsert(keyQuotaBlocked, blocked),
@@ -103,3 +205,28 @@ func ipKey(s string) string {
 	ip[len(ip)-1] = 0
 	return ip.String()
 }
+
+// GRPCQuota returns a gRPC interceptor that limits unary calls as Quota
+// limits HTTP requests, by the IP address in the x-forwarded-for metadata of
+// the call or, without it, the address of the peer. Calls over quota fail
+// with codes.ResourceExhausted.
+func GRPCQuota(settings config.QuotaSettings) grpc.UnaryServerInterceptor {
+	limiters := newIPLimiters(settings)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var key string
+		if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("x-forwarded-for")) > 0 {
+			key = ipKey(md.Get("x-forwarded-for")[0])
+		} else if p, ok := peer.FromContext(ctx); ok {
+			host, _, err := net.SplitHostPort(p.Addr.String())
+			if err == nil {
+				key = ipKey(host)
+			}
+		}
+		blocked := !limiters.allow(key, "")
+		recordQuotaMetric(strconv.FormatBool(blocked))
+		if blocked && settings.RecordOnly != nil && !*settings.RecordOnly {
+			return nil, status.Error(codes.ResourceExhausted, codes.ResourceExhausted.String())
+		}
+		return handler(ctx, req)
+	}
+}
@@ -5,6 +5,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -14,6 +15,10 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"go.opencensus.io/stats/view"
 	"golang.org/x/pkgsite/internal/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func TestQuota(t *testing.T) {
@@ -142,6 +147,105 @@ func TestQuotaBadKey(t *testing.T) {
 	}
 }
 
+func TestQuotaExpensiveRoutes(t *testing.T) {
+	mw := Quota(config.QuotaSettings{
+		QPS:            1,
+		Burst:          3,
+		MaxEntries:     10,
+		RecordOnly:     boolptr(false),
+		ExpensiveQPS:   1,
+		ExpensiveBurst: 1,
+		ExpensiveRoutes: map[string]config.RouteQuota{
+			"/search":        {},
+			"tab=importedby": {QPS: 1, Burst: 2},
+		},
+	})
+	ts := httptest.NewServer(mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	defer ts.Close()
+
+	get := func(path string) int {
+		req, err := http.NewRequest("GET", ts.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Add("X-Forwarded-For", "1.2.3.4")
+		res, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	// Each expensive route has its own budget, by default of one request.
+	for _, test := range []struct {
+		path string
+		want int
+	}{
+		{"/search?q=http", http.StatusOK},
+		{"/search?q=json", http.StatusTooManyRequests},
+		{"/net/http?tab=importedby", http.StatusOK},
+		{"/net/url?tab=importedby", http.StatusOK},
+		{"/net/http?tab=importedby", http.StatusTooManyRequests},
+	} {
+		if got := get(test.path); got != test.want {
+			t.Errorf("%s: got %d, want %d", test.path, got, test.want)
+		}
+	}
+	// Other routes are still served from their own budget.
+	for _, path := range []string{"/net/http", "/net/http?tab=doc", "/about"} {
+		if got := get(path); got != http.StatusOK {
+			t.Errorf("%s: got %d, want %d", path, got, http.StatusOK)
+		}
+	}
+}
+
+func TestQuotaAllowedUserAgents(t *testing.T) {
+	mw := Quota(config.QuotaSettings{
+		QPS:               1,
+		Burst:             1,
+		MaxEntries:        10,
+		RecordOnly:        boolptr(false),
+		AllowedUserAgents: []string{"Googlebot"},
+		ExpensiveQPS:      1,
+		ExpensiveBurst:    1,
+		ExpensiveRoutes:   map[string]config.RouteQuota{"/search": {}},
+	})
+	ts := httptest.NewServer(mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	defer ts.Close()
+
+	const googlebot = "Mozilla/5.0 (compatible; Googlebot/2.1)"
+	for _, test := range []struct {
+		userAgent string
+		path      string
+		want      int
+	}{
+		{googlebot, "/", http.StatusOK},
+		{googlebot, "/", http.StatusOK},
+		{"curl/7.64.1", "/", http.StatusOK},
+		{"curl/7.64.1", "/", http.StatusTooManyRequests},
+		{googlebot, "/", http.StatusOK},
+		// Expensive routes are limited even for allowed user agents.
+		{googlebot, "/search?q=http", http.StatusOK},
+		{googlebot, "/search?q=json", http.StatusTooManyRequests},
+	} {
+		req, err := http.NewRequest("GET", ts.URL+test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Add("X-Forwarded-For", "1.2.3.4")
+		req.Header.Set("User-Agent", test.userAgent)
+		res, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != test.want {
+			t.Errorf("%q %s: got %d, want %d", test.userAgent, test.path, res.StatusCode, test.want)
+		}
+	}
+}
+
 func collectViewData(t *testing.T) map[bool]int {
 	m := map[bool]int{}
 	rows, err := view.RetrieveData(QuotaResultCount.Name)
@@ -178,3 +282,19 @@ func TestIPKey(t *testing.T) {
 }
 
 func boolptr(b bool) *bool { return &b }
+
+func TestGRPCQuota(t *testing.T) {
+	intercept := GRPCQuota(config.QuotaSettings{QPS: 1, Burst: 2, MaxEntries: 1, RecordOnly: boolptr(false)})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-for", "1.2.3.4"))
+	for i := 0; i < 3; i++ {
+		_, err := intercept(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+		want := codes.OK
+		if i >= 2 {
+			want = codes.ResourceExhausted
+		}
+		if got := status.Code(err); got != want {
+			t.Errorf("#%d: got code %s, want %s", i, got, want)
+		}
+	}
+}
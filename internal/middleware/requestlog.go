@@ -6,10 +6,14 @@ package middleware
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/logging"
@@ -34,10 +38,43 @@ func (l LocalLogger) Log(entry logging.Entry) {
 			msg.WriteString(entry.HTTPRequest.Request.URL.Path + " ")
 		}
 	}
-	msg.WriteString(fmt.Sprint(entry.Payload))
+	if p, ok := entry.Payload.(*RequestLogPayload); ok {
+		b, err := json.Marshal(p)
+		if err != nil {
+			msg.WriteString(fmt.Sprint(p))
+		} else {
+			msg.Write(b)
+		}
+	} else {
+		msg.WriteString(fmt.Sprint(entry.Payload))
+	}
 	log.Info(context.Background(), msg.String())
 }
 
+// RequestIDHeader is the header used to return the request ID to the client.
+// If an incoming request already has this header, for example because it was
+// set by a load balancer, its value is used as the request ID, as long as it
+// is a valid request ID (see validRequestID).
+const RequestIDHeader = "X-Request-Id"
+
+// maxRequestIDLength is the length of the longest incoming request ID that is
+// used.
+const maxRequestIDLength = 128
+
+// RequestLogPayload is the structured payload of the log entries written by
+// the RequestLog middleware.
+type RequestLogPayload struct {
+	Message   string `json:"message"`
+	RequestID string `json:"requestID"`
+	Method    string `json:"method"`
+	Route     string `json:"route"`
+	Status    int    `json:"status,omitempty"`
+	LatencyMS int64  `json:"latencyMS,omitempty"`
+	// Error holds the errors that handlers reported with RecordRequestError,
+	// separated by semicolons.
+	Error string `json:"error,omitempty"`
+}
+
 // RequestLog returns a middleware that logs each incoming requests using the
 // given logger. This logger replaces the built-in appengine request logger,
 // which logged PII when behind IAP, in such a way that was impossible to turn
@@ -59,26 +96,114 @@ type handler struct {
 func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	traceID := r.Header.Get("X-Cloud-Trace-Context")
+	requestID := r.Header.Get(RequestIDHeader)
+	if !validRequestID(requestID) {
+		requestID = newRequestID()
+	}
+	w.Header().Set(RequestIDHeader, requestID)
 	h.logger.Log(logging.Entry{
 		HTTPRequest: &logging.HTTPRequest{Request: r},
-		Payload:     "request start",
-		Severity:    logging.Info,
-		Trace:       traceID,
+		Payload: &RequestLogPayload{
+			Message:   "request start",
+			RequestID: requestID,
+			Method:    r.Method,
+			Route:     r.URL.Path,
+		},
+		Severity: logging.Info,
+		Trace:    traceID,
 	})
 	w2 := &responseWriter{ResponseWriter: w}
-	h.delegate.ServeHTTP(w2, r.WithContext(log.NewContextWithTraceID(r.Context(), traceID)))
+	state := &requestLogState{}
+	ctx := log.NewContextWithTraceID(r.Context(), traceID)
+	ctx = log.NewContextWithRequestID(ctx, requestID)
+	ctx = context.WithValue(ctx, requestLogStateKey{}, state)
+	h.delegate.ServeHTTP(w2, r.WithContext(ctx))
+	status := translateStatus(w2.status)
+	latency := time.Since(start)
+	severity := logging.Info
+	if status >= http.StatusInternalServerError {
+		severity = logging.Error
+	}
 	h.logger.Log(logging.Entry{
 		HTTPRequest: &logging.HTTPRequest{
 			Request: r,
-			Status:  translateStatus(w2.status),
-			Latency: time.Since(start),
+			Status:  status,
+			Latency: latency,
 		},
-		Payload:  "request end",
-		Severity: logging.Info,
+		Payload: &RequestLogPayload{
+			Message:   "request end",
+			RequestID: requestID,
+			Method:    r.Method,
+			Route:     r.URL.Path,
+			Status:    status,
+			LatencyMS: latency.Milliseconds(),
+			Error:     state.error(),
+		},
+		Severity: severity,
 		Trace:    traceID,
 	})
 }
 
+// validRequestID reports whether id can be used as a request ID: it must not
+// be empty or longer than maxRequestIDLength, and must consist of the token
+// characters of RFC 7230, section 3.2.6, so that it cannot break up log
+// lines or headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestLogState holds what handlers report about a request for the entry
+// that RequestLog writes at its end.
+type requestLogState struct {
+	mu     sync.Mutex
+	errors []string
+}
+
+// requestLogStateKey is the context key of the requestLogState of a request.
+type requestLogStateKey struct{}
+
+func (s *requestLogState) error() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return strings.Join(s.errors, "; ")
+}
+
+// RecordRequestError records err as an error of the request whose context is
+// ctx, to be logged by RequestLog with the end of the request, along with the
+// ID, route and status of the request. If the request is not logged by
+// RequestLog, err is logged right away.
+func RecordRequestError(ctx context.Context, err error) {
+	s, ok := ctx.Value(requestLogStateKey{}).(*requestLogState)
+	if !ok {
+		log.Error(ctx, err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors = append(s.errors, err.Error())
+}
+
+// newRequestID returns a random identifier for a request.
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 
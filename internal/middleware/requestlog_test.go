@@ -5,12 +5,16 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"cloud.google.com/go/logging"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal/log"
 )
 
 func TestRequestLog(t *testing.T) {
@@ -44,19 +48,102 @@ func TestRequestLog(t *testing.T) {
 				t.Fatalf("GET returned error %v", err)
 			}
 			resp.Body.Close()
-			if diff := cmp.Diff(test.want, lg); diff != "" {
+			if diff := cmp.Diff(test.want, lg, cmpopts.IgnoreFields(fakeLog{}, "Payload")); diff != "" {
 				t.Errorf("mismatching log state (-want +got):\n%s", diff)
 			}
 		})
 	}
 }
 
+func TestRequestLogRequestID(t *testing.T) {
+	for _, test := range []struct {
+		label, header string
+		useHeader     bool
+	}{
+		{"generates request ID", "", false},
+		{"uses incoming request ID", "abc123", true},
+		{"replaces long request ID", strings.Repeat("a", maxRequestIDLength+1), false},
+		{"replaces request ID with spaces", "abc 123", false},
+		{"replaces request ID with markup", "<b>abc</b>", false},
+	} {
+		t.Run(test.label, func(t *testing.T) {
+			var ctxID string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctxID = log.RequestID(r.Context())
+			})
+			lg := fakeLog{}
+			ts := httptest.NewServer(RequestLog(&lg)(handler))
+			defer ts.Close()
+			req, err := http.NewRequest("GET", ts.URL+"/net/http", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.header != "" {
+				req.Header.Set(RequestIDHeader, test.header)
+			}
+			resp, err := ts.Client().Do(req)
+			if err != nil {
+				t.Fatalf("GET returned error %v", err)
+			}
+			resp.Body.Close()
+
+			got := resp.Header.Get(RequestIDHeader)
+			if got == "" {
+				t.Fatalf("missing %s header", RequestIDHeader)
+			}
+			if test.useHeader && got != test.header {
+				t.Errorf("%s = %q, want %q", RequestIDHeader, got, test.header)
+			}
+			if !test.useHeader && (got == test.header || !validRequestID(got)) {
+				t.Errorf("%s = %q, want a new request ID", RequestIDHeader, got)
+			}
+			if ctxID != got {
+				t.Errorf("log.RequestID(ctx) = %q, want %q", ctxID, got)
+			}
+			want := &RequestLogPayload{
+				Message:   "request end",
+				RequestID: got,
+				Method:    "GET",
+				Route:     "/net/http",
+				Status:    200,
+				LatencyMS: lg.Payload.LatencyMS,
+			}
+			if diff := cmp.Diff(want, lg.Payload); diff != "" {
+				t.Errorf("mismatching payload (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRecordRequestError(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		RecordRequestError(r.Context(), errors.New("first"))
+		RecordRequestError(r.Context(), errors.New("second"))
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	lg := fakeLog{}
+	ts := httptest.NewServer(RequestLog(&lg)(handler))
+	defer ts.Close()
+	resp, err := ts.Client().Get(ts.URL)
+	if err != nil {
+		t.Fatalf("GET returned error %v", err)
+	}
+	resp.Body.Close()
+	if got, want := lg.Payload.Error, "first; second"; got != want {
+		t.Errorf("got error %q, want %q", got, want)
+	}
+}
+
 type fakeLog struct {
-	Status int
+	Status  int
+	Payload *RequestLogPayload
 }
 
 func (l *fakeLog) Log(entry logging.Entry) {
 	if entry.HTTPRequest != nil {
 		l.Status = entry.HTTPRequest.Status
 	}
+	if p, ok := entry.Payload.(*RequestLogPayload); ok {
+		l.Payload = p
+	}
 }
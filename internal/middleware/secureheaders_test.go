@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -58,12 +59,19 @@ func TestSecureHeaders(t *testing.T) {
 		"content-security-policy",
 		"x-frame-options",
 		"x-content-type-options",
+		"strict-transport-security",
+		"referrer-policy",
 	}
 	for _, header := range expectedHeaders {
 		if got := resp.Header.Get(header); got == "" {
 			t.Errorf("GET returned empty %s", header)
 		}
 	}
+	for _, d := range strings.Split(resp.Header.Get("content-security-policy"), ";") {
+		if d := strings.TrimSpace(d); strings.HasPrefix(d, "script-src ") && strings.Contains(d, "'unsafe-inline'") {
+			t.Errorf("content-security-policy allows inline scripts: %s", d)
+		}
+	}
 
 	// Check that the nonce was substituted correctly.
 	// We need to extract it from the header.
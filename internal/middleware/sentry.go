@@ -0,0 +1,169 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/errorreporting"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+const (
+	// sentryTimeout bounds the time taken to send a report to Sentry.
+	sentryTimeout = 10 * time.Second
+
+	// maxPendingSentryReports is the number of reports that may be in
+	// flight at once. Further reports are only logged.
+	maxPendingSentryReports = 10
+)
+
+// SentryReporter is an ErrorReporter that sends errors to Sentry, using its
+// HTTP store API. Reports are sent in the background, so that reporting an
+// error does not delay the response to the request that caused it.
+type SentryReporter struct {
+	storeURL  string
+	publicKey string
+	client    *http.Client
+
+	pending chan struct{} // holds a value for each report in flight
+	wg      sync.WaitGroup
+}
+
+// NewSentryReporter returns a SentryReporter for the project identified by
+// dsn, which has the form https://<key>@<host>/<project>.
+func NewSentryReporter(dsn string) (_ *SentryReporter, err error) {
+	defer derrors.Wrap(&err, "NewSentryReporter")
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	project := strings.Trim(u.Path, "/")
+	if u.User == nil || u.User.Username() == "" || project == "" {
+		return nil, fmt.Errorf("DSN must have the form https://<key>@<host>/<project>")
+	}
+	return &SentryReporter{
+		storeURL:  fmt.Sprintf("%s://%s/api/%s/store/", u.Scheme, u.Host, project),
+		publicKey: u.User.Username(),
+		client:    &http.Client{},
+		pending:   make(chan struct{}, maxPendingSentryReports),
+	}, nil
+}
+
+// sentryEvent is the subset of the Sentry event payload used by
+// SentryReporter.
+type sentryEvent struct {
+	EventID   string            `json:"event_id"`
+	Timestamp string            `json:"timestamp"`
+	Level     string            `json:"level"`
+	Platform  string            `json:"platform"`
+	Message   string            `json:"message"`
+	Request   *sentryRequest    `json:"request,omitempty"`
+	Extra     map[string]string `json:"extra,omitempty"`
+}
+
+type sentryRequest struct {
+	URL    string `json:"url"`
+	Method string `json:"method"`
+}
+
+// Report implements the ErrorReporter interface. It returns without waiting
+// for the report to be sent; errors that occur while sending it are logged.
+// If too many reports are in flight, the error is only logged.
+func (s *SentryReporter) Report(e errorreporting.Entry) {
+	ctx := context.Background()
+	if e.Req != nil {
+		ctx = e.Req.Context()
+	}
+	// Build the event now: the request may be reused once its handler
+	// returns.
+	ev := newSentryEvent(e)
+	select {
+	case s.pending <- struct{}{}:
+	default:
+		log.Errorf(ctx, "SentryReporter.Report: too many pending reports; original error: %v", e.Error)
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer func() {
+			<-s.pending
+			s.wg.Done()
+		}()
+		if err := s.send(ev); err != nil {
+			log.Errorf(ctx, "SentryReporter.Report: %v; original error: %v", err, e.Error)
+		}
+	}()
+}
+
+// Flush waits for the reports in flight to be sent.
+func (s *SentryReporter) Flush() {
+	s.wg.Wait()
+}
+
+// send posts ev to Sentry, giving up after sentryTimeout. It does not use the
+// context of the request being reported, which may already be canceled if the
+// request timed out.
+func (s *SentryReporter) send(ev *sentryEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sentryTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", s.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth",
+		fmt.Sprintf("Sentry sentry_version=7, sentry_client=pkgsite/1.0, sentry_key=%s", s.publicKey))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("sending event to Sentry: %s", resp.Status)
+	}
+	return nil
+}
+
+func newSentryEvent(e errorreporting.Entry) *sentryEvent {
+	id := make([]byte, 16)
+	rand.Read(id)
+	ev := &sentryEvent{
+		EventID:   hex.EncodeToString(id),
+		Timestamp: time.Now().UTC().Format("2006-01-02T15:04:05"),
+		Level:     "error",
+		Platform:  "go",
+		Message:   fmt.Sprint(e.Error),
+	}
+	if e.Req != nil {
+		ev.Request = &sentryRequest{URL: e.Req.URL.String(), Method: e.Req.Method}
+		if id := log.RequestID(e.Req.Context()); id != "" {
+			ev.Extra = map[string]string{"requestID": id}
+		}
+	}
+	if len(e.Stack) > 0 {
+		if ev.Extra == nil {
+			ev.Extra = map[string]string{}
+		}
+		ev.Extra["stack"] = string(e.Stack)
+	}
+	return ev
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/errorreporting"
+)
+
+func TestNewSentryReporter(t *testing.T) {
+	s, err := NewSentryReporter("https://abc@sentry.example.com/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://sentry.example.com/api/42/store/"; s.storeURL != want {
+		t.Errorf("storeURL = %q, want %q", s.storeURL, want)
+	}
+	if want := "abc"; s.publicKey != want {
+		t.Errorf("publicKey = %q, want %q", s.publicKey, want)
+	}
+
+	for _, dsn := range []string{"https://sentry.example.com/42", "https://abc@sentry.example.com"} {
+		if _, err := NewSentryReporter(dsn); err == nil {
+			t.Errorf("NewSentryReporter(%q): got nil error, want non-nil", dsn)
+		}
+	}
+}
+
+func TestSentryReporter(t *testing.T) {
+	var (
+		got  sentryEvent
+		auth string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("X-Sentry-Auth")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	s, err := NewSentryReporter(strings.Replace(ts.URL, "://", "://key@", 1) + "/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/net/http", nil)
+	s.Report(errorreporting.Entry{Error: errors.New("bad"), Req: req, Stack: []byte("stack")})
+	s.Flush()
+
+	if !strings.Contains(auth, "sentry_key=key") {
+		t.Errorf("X-Sentry-Auth = %q, want it to contain sentry_key=key", auth)
+	}
+	if got.Message != "bad" || got.Level != "error" || got.Extra["stack"] != "stack" {
+		t.Errorf("got event %+v", got)
+	}
+	if got.Request == nil || got.Request.Method != "GET" || !strings.HasSuffix(got.Request.URL, "/net/http") {
+		t.Errorf("got request %+v", got.Request)
+	}
+}
+
+func TestSentryReporterAsync(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+
+	s, err := NewSentryReporter(strings.Replace(ts.URL, "://", "://key@", 1) + "/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		// More reports than may be pending: the extra ones are dropped.
+		for i := 0; i < maxPendingSentryReports+1; i++ {
+			s.Report(errorreporting.Entry{Error: errors.New("bad")})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Report waited for Sentry")
+	}
+	close(release)
+	s.Flush()
+}
@@ -8,6 +8,8 @@ import (
 	"context"
 	"net/http"
 	"time"
+
+	"golang.org/x/pkgsite/internal/database"
 )
 
 // Timeout returns a new Middleware that times out each request after the given
@@ -21,3 +23,13 @@ func Timeout(d time.Duration) Middleware {
 		})
 	}
 }
+
+// QueryTimeout returns a new Middleware that cancels each database query made
+// while handling a request if it takes longer than the given duration.
+func QueryTimeout(d time.Duration) Middleware {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r.WithContext(database.WithQueryTimeout(r.Context(), d)))
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal/abuse"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// InsertAbuseReport records r, made by reporter, for review. The ID, Status,
+// CreatedAt and ReviewedAt fields of r are ignored. It returns the ID of the
+// new report.
+func (db *DB) InsertAbuseReport(ctx context.Context, r *abuse.Report, reporter string) (id int64, err error) {
+	defer derrors.Wrap(&err, "InsertAbuseReport(ctx, %q, %q)", r.Path, r.Kind)
+
+	if err := r.Validate(); err != nil {
+		return 0, fmt.Errorf("%v: %w", err, derrors.InvalidArgument)
+	}
+	err = db.db.QueryRow(ctx, `
+		INSERT INTO abuse_reports (path, version, kind, details, contact, reporter)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		r.Path, r.Version, r.Kind, r.Details, r.Contact, reporter).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// CountAbuseReports returns the number of reports made by reporter after
+// since.
+func (db *DB) CountAbuseReports(ctx context.Context, reporter string, since time.Time) (n int, err error) {
+	defer derrors.Wrap(&err, "CountAbuseReports(ctx, %q, %s)", reporter, since)
+
+	err = db.db.QueryRow(ctx, `
+		SELECT count(*)
+		FROM abuse_reports
+		WHERE reporter = $1 AND created_at > $2`,
+		reporter, since).Scan(&n)
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// GetAbuseReports returns the reports with the given status, oldest first.
+func (db *DB) GetAbuseReports(ctx context.Context, status string) (_ []*abuse.Report, err error) {
+	defer derrors.Wrap(&err, "GetAbuseReports(ctx, %q)", status)
+
+	var reports []*abuse.Report
+	collect := func(rows *sql.Rows) error {
+		var (
+			r          abuse.Report
+			reviewedAt pq.NullTime
+		)
+		if err := rows.Scan(&r.ID, &r.Path, &r.Version, &r.Kind, &r.Details, &r.Contact,
+			&r.Status, &r.CreatedAt, &reviewedAt); err != nil {
+			return err
+		}
+		r.ReviewedAt = reviewedAt.Time
+		reports = append(reports, &r)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, `
+		SELECT id, path, version, kind, details, contact, status, created_at, reviewed_at
+		FROM abuse_reports
+		WHERE status = $1
+		ORDER BY created_at, id`, collect, status); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// reviewAbuseReport sets the status of the report with the given ID to
+// abuse.StatusResolved or abuse.StatusDismissed. Administrators review
+// reports with Moderate, which records the review in the audit log.
+func reviewAbuseReport(ctx context.Context, ddb *database.DB, id int64, status string) error {
+	if status != abuse.StatusResolved && status != abuse.StatusDismissed {
+		return fmt.Errorf("status must be %q or %q: %w", abuse.StatusResolved, abuse.StatusDismissed, derrors.InvalidArgument)
+	}
+	res, err := ddb.Exec(ctx, `
+		UPDATE abuse_reports
+		SET status = $2, reviewed_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, id, status)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("report %d: %w", id, derrors.NotFound)
+	}
+	return nil
+}
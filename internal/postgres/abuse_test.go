@@ -0,0 +1,91 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal/abuse"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+func TestAbuseReports(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	start := time.Now().Add(-time.Minute)
+	r := &abuse.Report{
+		Path:    "github.com/a/b/c",
+		Version: "v1.0.0",
+		Kind:    abuse.KindMalware,
+		Details: "runs a miner in init",
+	}
+	id, err := testDB.InsertAbuseReport(ctx, r, "reporter1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testDB.InsertAbuseReport(ctx, &abuse.Report{Path: "github.com/a/b", Kind: "rude"}, "reporter1"); !errors.Is(err, derrors.InvalidArgument) {
+		t.Errorf("InsertAbuseReport(unknown kind): got error %v, want InvalidArgument", err)
+	}
+
+	for _, test := range []struct {
+		reporter string
+		want     int
+	}{
+		{"reporter1", 1},
+		{"reporter2", 0},
+	} {
+		got, err := testDB.CountAbuseReports(ctx, test.reporter, start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("CountAbuseReports(%q) = %d, want %d", test.reporter, got, test.want)
+		}
+	}
+
+	want := *r
+	want.ID = id
+	want.Status = abuse.StatusPending
+	ignoreTimes := cmpopts.IgnoreFields(abuse.Report{}, "CreatedAt", "ReviewedAt")
+	pending, err := testDB.GetAbuseReports(ctx, abuse.StatusPending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*abuse.Report{&want}, pending, ignoreTimes); diff != "" {
+		t.Errorf("GetAbuseReports(pending) mismatch (-want +got):\n%s", diff)
+	}
+
+	a := &abuse.Action{Actor: "admin@example.com", Kind: abuse.ActionResolveReport, Target: strconv.FormatInt(id, 10)}
+	if err := testDB.Moderate(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := testDB.GetAbuseReports(ctx, abuse.StatusResolved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved) != 1 || resolved[0].ReviewedAt.IsZero() {
+		t.Errorf("GetAbuseReports(resolved) = %v, want one reviewed report", resolved)
+	}
+	unknown := &abuse.Action{Actor: "admin@example.com", Kind: abuse.ActionResolveReport, Target: strconv.FormatInt(id+1, 10)}
+	if err := testDB.Moderate(ctx, unknown); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("Moderate(unknown id): got error %v, want NotFound", err)
+	}
+	for _, bad := range []*abuse.Action{
+		{Actor: "admin@example.com", Kind: "pend-report", Target: strconv.FormatInt(id, 10)},
+		{Actor: "admin@example.com", Kind: abuse.ActionResolveReport, Target: "x"},
+	} {
+		if err := testDB.Moderate(ctx, bad); !errors.Is(err, derrors.InvalidArgument) {
+			t.Errorf("Moderate(%q, %q): got error %v, want InvalidArgument", bad.Kind, bad.Target, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// SetACL makes the methods of db that serve the frontend behave as if the
+// modules that the user in the request context may not see, according to
+// acl, were not in the database. It must be called before db is used.
+func (db *DB) SetACL(acl *auth.ACL) {
+	db.acl = acl
+}
+
+// checkAccess returns an error wrapping derrors.NotFound if the user in ctx
+// may not see path.
+func (db *DB) checkAccess(ctx context.Context, path string) error {
+	if !db.acl.Allowed(ctx, path) {
+		return fmt.Errorf("%q: %w", path, derrors.NotFound)
+	}
+	return nil
+}
+
+// filterPaths returns the paths that the user in ctx may see.
+func (db *DB) filterPaths(ctx context.Context, paths []string) []string {
+	if db.acl == nil {
+		return paths
+	}
+	var r []string
+	for _, p := range paths {
+		if db.acl.Allowed(ctx, p) {
+			r = append(r, p)
+		}
+	}
+	return r
+}
+
+// filterModuleInfos returns the module versions that the user in ctx may see.
+func (db *DB) filterModuleInfos(ctx context.Context, mis []*internal.LegacyModuleInfo) []*internal.LegacyModuleInfo {
+	if db.acl == nil {
+		return mis
+	}
+	var r []*internal.LegacyModuleInfo
+	for _, mi := range mis {
+		if db.acl.Allowed(ctx, mi.ModulePath) {
+			r = append(r, mi)
+		}
+	}
+	return r
+}
@@ -0,0 +1,70 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestACL(t *testing.T) {
+	defer ResetTestDB(testDB, t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	for _, m := range []*internal.Module{
+		sample.Module("public.com/m", "v1.0.0", "p"),
+		sample.Module("corp.com/secret", "v1.0.0", "p"),
+	} {
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := New(testDB.Underlying())
+	db.SetACL(auth.NewACL(map[string][]string{"corp.com": {"eng"}}))
+	eng := auth.NewContextWithUser(ctx, &auth.User{Email: "e@corp.com", Groups: []string{"eng"}})
+	other := auth.NewContextWithUser(ctx, &auth.User{Email: "o@corp.com", Groups: []string{"sales"}})
+
+	for _, test := range []struct {
+		name       string
+		ctx        context.Context
+		modulePath string
+		wantErr    error
+	}{
+		{"public", other, "public.com/m", nil},
+		{"allowed", eng, "corp.com/secret", nil},
+		{"denied", other, "corp.com/secret", derrors.NotFound},
+		{"anonymous", ctx, "corp.com/secret", derrors.NotFound},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := db.GetModuleInfo(test.ctx, test.modulePath, "v1.0.0"); !errors.Is(err, test.wantErr) {
+				t.Errorf("GetModuleInfo: got error %v, want %v", err, test.wantErr)
+			}
+			if _, err := db.GetPackage(test.ctx, test.modulePath+"/p", test.modulePath, "v1.0.0", internal.AllFields); !errors.Is(err, test.wantErr) {
+				t.Errorf("GetPackage: got error %v, want %v", err, test.wantErr)
+			}
+			if _, _, _, err := db.GetPathInfo(test.ctx, test.modulePath+"/p", internal.UnknownModulePath, internal.LatestVersion); !errors.Is(err, test.wantErr) {
+				t.Errorf("GetPathInfo: got error %v, want %v", err, test.wantErr)
+			}
+			mis, err := db.GetTaggedVersionsForModule(test.ctx, test.modulePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantVersions := 1
+			if test.wantErr != nil {
+				wantVersions = 0
+			}
+			if len(mis) != wantVersions {
+				t.Errorf("GetTaggedVersionsForModule: got %d versions, want %d", len(mis), wantVersions)
+			}
+		})
+	}
+}
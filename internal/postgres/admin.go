@@ -0,0 +1,148 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/pkgsite/internal/abuse"
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/lookalike"
+)
+
+// Moderate makes the change described by a, and records it in the audit log
+// with the state of its target before and after, in one transaction.
+func (db *DB) Moderate(ctx context.Context, a *abuse.Action) (err error) {
+	defer derrors.Wrap(&err, "Moderate(ctx, %q, %q, %q)", a.Actor, a.Kind, a.Target)
+
+	if a.Actor == "" || a.Target == "" {
+		return fmt.Errorf("missing actor or target: %w", derrors.InvalidArgument)
+	}
+	err = db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		before, after, err := moderate(ctx, tx, a)
+		if err != nil {
+			return err
+		}
+		return recordAuditEntry(ctx, tx, &audit.Entry{
+			Actor:  a.Actor,
+			Action: a.Kind,
+			Target: a.Target,
+			Reason: a.Reason,
+			Before: before,
+			After:  after,
+		})
+	})
+	if a.Kind == abuse.ActionExclude || a.Kind == abuse.ActionUnexclude {
+		// Arrange to re-read the excluded_prefixes table on the next call to IsExcluded.
+		setExcludedPrefixesLastFetched(time.Time{})
+	}
+	return err
+}
+
+// moderate makes the change described by a, and returns the state of its
+// target before and after.
+func moderate(ctx context.Context, tx *database.DB, a *abuse.Action) (before, after json.RawMessage, err error) {
+	switch a.Kind {
+	case abuse.ActionResolveReport, abuse.ActionDismissReport:
+		id, err := strconv.ParseInt(a.Target, 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bad report ID %q: %w", a.Target, derrors.InvalidArgument)
+		}
+		status := abuse.StatusResolved
+		if a.Kind == abuse.ActionDismissReport {
+			status = abuse.StatusDismissed
+		}
+		old, err := currentStatus(ctx, tx, `SELECT status FROM abuse_reports WHERE id = $1 FOR UPDATE`, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := reviewAbuseReport(ctx, tx, id, status); err != nil {
+			return nil, nil, err
+		}
+		return statusJSON(old), statusJSON(status), nil
+	case abuse.ActionConfirmLookalike, abuse.ActionDismissLookalike:
+		status := lookalike.StatusConfirmed
+		if a.Kind == abuse.ActionDismissLookalike {
+			status = lookalike.StatusDismissed
+		}
+		old, err := currentStatus(ctx, tx, `SELECT status FROM lookalikes WHERE module_path = $1 FOR UPDATE`, a.Target)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := reviewLookalike(ctx, tx, a.Target, status); err != nil {
+			return nil, nil, err
+		}
+		return statusJSON(old), statusJSON(status), nil
+	case abuse.ActionExclude:
+		if a.Reason == "" {
+			return nil, nil, fmt.Errorf("an exclusion needs a reason: %w", derrors.InvalidArgument)
+		}
+		if err := insertExcludedPrefix(ctx, tx, a.Target, a.Actor, a.Reason); err != nil {
+			return nil, nil, err
+		}
+		return nil, exclusionJSON(a.Actor, a.Reason), nil
+	case abuse.ActionUnexclude:
+		var createdBy, reason string
+		err := tx.QueryRow(ctx, `SELECT created_by, reason FROM excluded_prefixes WHERE prefix = $1`,
+			a.Target).Scan(&createdBy, &reason)
+		if err == sql.ErrNoRows {
+			return nil, nil, fmt.Errorf("%s: %w", a.Target, derrors.NotFound)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := deleteExcludedPrefix(ctx, tx, a.Target); err != nil {
+			return nil, nil, err
+		}
+		return exclusionJSON(createdBy, reason), nil, nil
+	case abuse.ActionSupersede:
+		old, err := getSupersededBy(ctx, tx, a.Target)
+		if err != nil && !errors.Is(err, derrors.NotFound) {
+			return nil, nil, err
+		}
+		if err := supersedePackage(ctx, tx, a.Target, a.SupersededBy, a.Actor); err != nil {
+			return nil, nil, err
+		}
+		return supersessionJSON(old), supersessionJSON(a.SupersededBy), nil
+	case abuse.ActionUnsupersede:
+		old, err := getSupersededBy(ctx, tx, a.Target)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := unsupersedePackage(ctx, tx, a.Target); err != nil {
+			return nil, nil, err
+		}
+		return supersessionJSON(old), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown action %q: %w", a.Kind, derrors.InvalidArgument)
+	}
+}
+
+// currentStatus returns the status selected by query. It returns an error
+// that wraps derrors.NotFound if there is no row.
+func currentStatus(ctx context.Context, tx *database.DB, query string, arg interface{}) (string, error) {
+	var status string
+	err := tx.QueryRow(ctx, query, arg).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%v: %w", arg, derrors.NotFound)
+	}
+	return status, err
+}
+
+func statusJSON(status string) json.RawMessage {
+	return auditJSON(map[string]string{"status": status})
+}
+
+func exclusionJSON(createdBy, reason string) json.RawMessage {
+	return auditJSON(map[string]string{"created_by": createdBy, "reason": reason})
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal/abuse"
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+func TestModerate(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	id, err := testDB.InsertAbuseReport(ctx, &abuse.Report{Path: "bad.com/m", Kind: abuse.KindMalware}, "reporter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const admin = "admin@example.com"
+	actions := []*abuse.Action{
+		{Actor: admin, Kind: abuse.ActionExclude, Target: "bad.com", Reason: "malware"},
+		{Actor: admin, Kind: abuse.ActionResolveReport, Target: strconv.FormatInt(id, 10)},
+	}
+	for _, a := range actions {
+		if err := testDB.Moderate(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	excluded, err := testDB.IsExcluded(ctx, "bad.com/m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !excluded {
+		t.Error("bad.com/m is not excluded after ActionExclude")
+	}
+	exs, err := testDB.GetExclusions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantExs := []*abuse.Exclusion{{Prefix: "bad.com", CreatedBy: admin, Reason: "malware"}}
+	if diff := cmp.Diff(wantExs, exs, cmpopts.IgnoreFields(abuse.Exclusion{}, "CreatedAt")); diff != "" {
+		t.Errorf("GetExclusions mismatch (-want +got):\n%s", diff)
+	}
+	resolved, err := testDB.GetAbuseReports(ctx, abuse.StatusResolved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved) != 1 {
+		t.Errorf("got %d resolved reports, want 1", len(resolved))
+	}
+
+	// Failed actions are not recorded.
+	for _, test := range []struct {
+		action *abuse.Action
+		want   error
+	}{
+		{&abuse.Action{Actor: admin, Kind: abuse.ActionResolveReport, Target: "x"}, derrors.InvalidArgument},
+		{&abuse.Action{Actor: admin, Kind: abuse.ActionDismissReport, Target: strconv.FormatInt(id+1, 10)}, derrors.NotFound},
+		{&abuse.Action{Actor: admin, Kind: abuse.ActionDismissLookalike, Target: "github.com/a/b"}, derrors.NotFound},
+		{&abuse.Action{Actor: admin, Kind: abuse.ActionUnexclude, Target: "good.com"}, derrors.NotFound},
+		{&abuse.Action{Actor: admin, Kind: abuse.ActionExclude, Target: "other.com"}, derrors.InvalidArgument},
+		{&abuse.Action{Actor: admin, Kind: "delete", Target: "bad.com"}, derrors.InvalidArgument},
+		{&abuse.Action{Kind: abuse.ActionUnexclude, Target: "bad.com"}, derrors.InvalidArgument},
+	} {
+		if err := testDB.Moderate(ctx, test.action); !errors.Is(err, test.want) {
+			t.Errorf("Moderate(%+v): got error %v, want %v", test.action, err, test.want)
+		}
+	}
+
+	got, err := testDB.GetAuditLog(ctx, audit.Query{Actor: admin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*audit.Entry{
+		{
+			Actor:  admin,
+			Action: abuse.ActionResolveReport,
+			Target: strconv.FormatInt(id, 10),
+			Before: json.RawMessage(`{"status":"pending"}`),
+			After:  json.RawMessage(`{"status":"resolved"}`),
+		},
+		{
+			Actor:  admin,
+			Action: abuse.ActionExclude,
+			Target: "bad.com",
+			Reason: "malware",
+			After:  json.RawMessage(`{"created_by":"admin@example.com","reason":"malware"}`),
+		},
+	}
+	// Compare the JSON values, not their formatting.
+	decode := cmp.Transformer("decode", func(m json.RawMessage) map[string]string {
+		var v map[string]string
+		if err := json.Unmarshal(m, &v); err != nil && len(m) > 0 {
+			t.Fatal(err)
+		}
+		return v
+	})
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(audit.Entry{}, "ID", "CreatedAt"), decode); diff != "" {
+		t.Errorf("GetAuditLog mismatch (-want +got):\n%s", diff)
+	}
+}
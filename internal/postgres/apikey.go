@@ -0,0 +1,170 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal/apikey"
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// InsertAPIKey issues a new API key with the given name and tier, and records
+// its issuance by actor in the audit log. It returns the ID of the key and the
+// key itself, which is not stored.
+func (db *DB) InsertAPIKey(ctx context.Context, name, tier, actor string) (id int64, key string, err error) {
+	defer derrors.Wrap(&err, "InsertAPIKey(ctx, %q, %q, %q)", name, tier, actor)
+
+	if name == "" || tier == "" {
+		return 0, "", fmt.Errorf("missing name or tier: %w", derrors.InvalidArgument)
+	}
+	key, err = apikey.Generate()
+	if err != nil {
+		return 0, "", err
+	}
+	err = db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO api_keys (key_hash, name, tier, created_by)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id`,
+			apikey.Hash(key), name, tier, actor).Scan(&id)
+		if err != nil {
+			return err
+		}
+		return recordAuditEntry(ctx, tx, &audit.Entry{
+			Actor:  actor,
+			Action: audit.ActionAddAPIKey,
+			Target: strconv.FormatInt(id, 10),
+			After:  auditJSON(map[string]string{"name": name, "tier": tier}),
+		})
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	return id, key, nil
+}
+
+// RevokeAPIKey revokes the API key with the given ID, and records its
+// revocation by actor in the audit log. It returns an error that wraps
+// derrors.NotFound if there is no such key, or it is already revoked.
+func (db *DB) RevokeAPIKey(ctx context.Context, id int64, actor string) (err error) {
+	defer derrors.Wrap(&err, "RevokeAPIKey(ctx, %d, %q)", id, actor)
+
+	return db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		var name, tier string
+		err := tx.QueryRow(ctx, `
+			UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND revoked_at IS NULL
+			RETURNING name, tier`, id).Scan(&name, &tier)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("API key %d: %w", id, derrors.NotFound)
+		}
+		if err != nil {
+			return err
+		}
+		return recordAuditEntry(ctx, tx, &audit.Entry{
+			Actor:  actor,
+			Action: audit.ActionRevokeAPIKey,
+			Target: strconv.FormatInt(id, 10),
+			Before: auditJSON(map[string]string{"name": name, "tier": tier}),
+		})
+	})
+}
+
+// GetAPIKeys returns all API keys, including revoked ones, oldest first, with
+// the number of requests made with them in the last apikey.UsageDays days.
+func (db *DB) GetAPIKeys(ctx context.Context) (_ []*apikey.Key, err error) {
+	defer derrors.Wrap(&err, "GetAPIKeys(ctx)")
+
+	var keys []*apikey.Key
+	collect := func(rows *sql.Rows) error {
+		var (
+			k         apikey.Key
+			revokedAt pq.NullTime
+		)
+		if err := rows.Scan(&k.ID, &k.Name, &k.Tier, &k.CreatedBy, &k.CreatedAt, &revokedAt, &k.Requests); err != nil {
+			return err
+		}
+		if revokedAt.Valid {
+			k.RevokedAt = &revokedAt.Time
+		}
+		keys = append(keys, &k)
+		return nil
+	}
+	query := `
+		SELECT
+			k.id, k.name, k.tier, k.created_by, k.created_at, k.revoked_at,
+			COALESCE((
+				SELECT sum(u.requests)
+				FROM api_key_usage u
+				WHERE u.key_id = k.id
+				AND u.day > (CURRENT_TIMESTAMP AT TIME ZONE 'UTC')::date - $1::integer
+			), 0)
+		FROM api_keys k
+		ORDER BY k.id`
+	if err := db.db.RunQuery(ctx, query, collect, apikey.UsageDays); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// LookupAPIKey returns the API key key, without its usage. It returns an
+// error that wraps derrors.NotFound if there is no such key, or it was
+// revoked.
+func (db *DB) LookupAPIKey(ctx context.Context, key string) (_ *apikey.Key, err error) {
+	// Don't include the key in the error.
+	defer derrors.Wrap(&err, "LookupAPIKey(ctx)")
+
+	var k apikey.Key
+	err = db.db.QueryRow(ctx, `
+		SELECT id, name, tier, created_by, created_at
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL`,
+		apikey.Hash(key)).Scan(&k.ID, &k.Name, &k.Tier, &k.CreatedBy, &k.CreatedAt)
+	switch err {
+	case sql.ErrNoRows:
+		return nil, derrors.NotFound
+	case nil:
+		return &k, nil
+	default:
+		return nil, err
+	}
+}
+
+// RecordAPIKeyUsage adds the given numbers of requests, by key ID, to the
+// usage of the keys on the day (UTC) of t.
+func (db *DB) RecordAPIKeyUsage(ctx context.Context, t time.Time, requests map[int64]int64) (err error) {
+	defer derrors.Wrap(&err, "RecordAPIKeyUsage(ctx, %s, %d keys)", t.Format(time.RFC3339), len(requests))
+
+	ids := make([]int64, 0, len(requests))
+	for id := range requests {
+		ids = append(ids, id)
+	}
+	// Update the rows in the same order in every transaction, to avoid
+	// deadlocks between frontend instances.
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	day := t.UTC().Format("2006-01-02")
+	return db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		for _, id := range ids {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO api_key_usage (key_id, day, requests)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (key_id, day)
+				DO UPDATE SET requests = api_key_usage.requests + excluded.requests`,
+				id, day, requests[id]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal/apikey"
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+func TestAPIKeys(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	const actor = "a@example.com"
+	id, key, err := testDB.InsertAPIKey(ctx, "bot", "standard", actor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := testDB.InsertAPIKey(ctx, "", "standard", actor); !errors.Is(err, derrors.InvalidArgument) {
+		t.Errorf("InsertAPIKey without a name: got %v, want InvalidArgument", err)
+	}
+
+	got, err := testDB.LookupAPIKey(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &apikey.Key{ID: id, Name: "bot", Tier: "standard", CreatedBy: actor}
+	ignore := cmpopts.IgnoreFields(apikey.Key{}, "CreatedAt", "RevokedAt")
+	if diff := cmp.Diff(want, got, ignore); diff != "" {
+		t.Errorf("LookupAPIKey mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := testDB.LookupAPIKey(ctx, key+"x"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("LookupAPIKey of an unknown key: got %v, want NotFound", err)
+	}
+
+	now := time.Now()
+	for _, d := range []time.Duration{0, 0, -40 * 24 * time.Hour} {
+		if err := testDB.RecordAPIKeyUsage(ctx, now.Add(d), map[int64]int64{id: 5}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keys, err := testDB.GetAPIKeys(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Usage older than apikey.UsageDays is not counted.
+	want.Requests = 10
+	if diff := cmp.Diff([]*apikey.Key{want}, keys, ignore); diff != "" {
+		t.Errorf("GetAPIKeys mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := testDB.RevokeAPIKey(ctx, id, actor); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDB.RevokeAPIKey(ctx, id, actor); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("RevokeAPIKey twice: got %v, want NotFound", err)
+	}
+	if _, err := testDB.LookupAPIKey(ctx, key); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("LookupAPIKey of a revoked key: got %v, want NotFound", err)
+	}
+	keys, err = testDB.GetAPIKeys(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].RevokedAt == nil {
+		t.Errorf("GetAPIKeys after revoking = %+v, want one revoked key", keys)
+	}
+
+	entries, err := testDB.GetAuditLog(ctx, audit.Query{Actor: actor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	if diff := cmp.Diff([]string{audit.ActionRevokeAPIKey, audit.ActionAddAPIKey}, actions); diff != "" {
+		t.Errorf("audit log mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// RecordAuditEntry appends e to the audit log. The ID and CreatedAt fields of
+// e are ignored.
+func (db *DB) RecordAuditEntry(ctx context.Context, e *audit.Entry) (err error) {
+	defer derrors.Wrap(&err, "RecordAuditEntry(ctx, %q, %q, %q)", e.Actor, e.Action, e.Target)
+	return recordAuditEntry(ctx, db.db, e)
+}
+
+func recordAuditEntry(ctx context.Context, ddb *database.DB, e *audit.Entry) error {
+	if e.Actor == "" || e.Action == "" {
+		return fmt.Errorf("missing actor or action: %w", derrors.InvalidArgument)
+	}
+	_, err := ddb.Exec(ctx, `
+		INSERT INTO admin_actions (actor, action, target, reason, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		e.Actor, e.Action, e.Target, e.Reason, jsonValue(e.Before), jsonValue(e.After))
+	return err
+}
+
+// jsonValue returns the value to store for the JSON m: NULL if m is empty.
+func jsonValue(m json.RawMessage) interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	return string(m)
+}
+
+// GetAuditLog returns the entries of the audit log that match q, newest
+// first.
+func (db *DB) GetAuditLog(ctx context.Context, q audit.Query) (_ []*audit.Entry, err error) {
+	defer derrors.Wrap(&err, "GetAuditLog(ctx, %+v)", q)
+
+	var (
+		conds []string
+		args  []interface{}
+	)
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if q.Actor != "" {
+		add("actor = $%d", q.Actor)
+	}
+	if q.Action != "" {
+		add("action = $%d", q.Action)
+	}
+	if q.Target != "" {
+		add("target = $%d", q.Target)
+	}
+	if !q.Since.IsZero() {
+		add("created_at >= $%d", q.Since)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = audit.DefaultLimit
+	}
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+		SELECT id, actor, action, target, reason, before, after, created_at
+		FROM admin_actions
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d`, where, len(args))
+
+	var entries []*audit.Entry
+	collect := func(rows *sql.Rows) error {
+		var (
+			e             audit.Entry
+			before, after []byte
+		)
+		if err := rows.Scan(&e.ID, &e.Actor, &e.Action, &e.Target, &e.Reason, &before, &after, &e.CreatedAt); err != nil {
+			return err
+		}
+		if before != nil {
+			e.Before = json.RawMessage(before)
+		}
+		if after != nil {
+			e.After = json.RawMessage(after)
+		}
+		entries = append(entries, &e)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, args...); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// auditJSON returns the JSON encoding of v for an audit entry, or nil if v is
+// nil.
+func auditJSON(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		// v is always a map or struct of our own.
+		panic(err)
+	}
+	return data
+}
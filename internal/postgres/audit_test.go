@@ -0,0 +1,75 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"golang.org/x/pkgsite/internal/audit"
+)
+
+func TestAuditLog(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	start := time.Now().Add(-time.Minute)
+	for _, e := range []*audit.Entry{
+		{Actor: "a@example.com", Action: audit.ActionReprocess, Target: "2020-01-01t00"},
+		{Actor: "b@example.com", Action: audit.ActionClearCache},
+		{Actor: "a@example.com", Action: audit.ActionClearCache, After: json.RawMessage(`{"keys":0}`)},
+	} {
+		if err := testDB.RecordAuditEntry(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := testDB.RecordAuditEntry(ctx, &audit.Entry{Action: audit.ActionClearCache}); err == nil {
+		t.Error("RecordAuditEntry without an actor: got nil error, want one")
+	}
+
+	for _, test := range []struct {
+		name  string
+		query audit.Query
+		want  []string // actor and action of each entry
+	}{
+		{"all", audit.Query{}, []string{"a@example.com clear-cache", "b@example.com clear-cache", "a@example.com reprocess"}},
+		{"actor", audit.Query{Actor: "a@example.com"}, []string{"a@example.com clear-cache", "a@example.com reprocess"}},
+		{"action", audit.Query{Action: audit.ActionReprocess}, []string{"a@example.com reprocess"}},
+		{"target", audit.Query{Target: "2020-01-01t00"}, []string{"a@example.com reprocess"}},
+		{"limit", audit.Query{Limit: 1}, []string{"a@example.com clear-cache"}},
+		{"since", audit.Query{Since: start.Add(time.Hour)}, nil},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			entries, err := testDB.GetAuditLog(ctx, test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Actor+" "+e.Action)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("got %q, want %q", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("got %q, want %q", got, test.want)
+					break
+				}
+			}
+		})
+	}
+
+	// The audit log is append-only.
+	if _, err := testDB.db.Exec(ctx, `UPDATE admin_actions SET actor = 'c@example.com'`); err == nil {
+		t.Error("UPDATE admin_actions: got nil error, want one")
+	}
+	if _, err := testDB.db.Exec(ctx, `DELETE FROM admin_actions`); err == nil {
+		t.Error("DELETE FROM admin_actions: got nil error, want one")
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// A PathPrefix is a prefix of module paths, one path element longer than the
+// prefix it is under, like github.com/golang under github.com.
+type PathPrefix struct {
+	Prefix string
+	// NumModules is the number of modules in search whose paths are Prefix
+	// or under it.
+	NumModules int
+	// IsModule is whether there is a module at Prefix.
+	IsModule bool
+}
+
+// GetPathPrefixes returns the prefixes of the paths of the modules in search
+// that are one element longer than prefix, or the first elements of the paths,
+// like github.com and golang.org, if prefix is empty. The prefixes with the
+// most modules come first, and at most limit of them are returned after
+// skipping the first offset. It also returns the number of such prefixes.
+// The standard library, excluded modules, and modules the user may not see
+// are omitted from both the prefixes and their counts.
+func (db *DB) GetPathPrefixes(ctx context.Context, prefix string, limit, offset int) (_ []*PathPrefix, total int, err error) {
+	defer derrors.Wrap(&err, "GetPathPrefixes(ctx, %q, %d, %d)", prefix, limit, offset)
+
+	depth := 1
+	if prefix != "" {
+		depth = strings.Count(prefix, "/") + 2
+	}
+	var paths []string
+	collect := func(rows *sql.Rows) error {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return err
+		}
+		paths = append(paths, p)
+		return nil
+	}
+	// The modules are filtered here rather than in the query, so that the
+	// counts only include modules the user may see.
+	query := `
+		SELECT DISTINCT module_path
+		FROM search_documents
+		WHERE module_path <> $2
+		AND ($1 = '' OR starts_with(module_path, $1 || '/'))`
+	if err := db.db.RunQuery(ctx, query, collect, prefix, stdlib.ModulePath); err != nil {
+		return nil, 0, err
+	}
+	byPrefix := map[string]*PathPrefix{}
+	var pps []*PathPrefix
+	for _, path := range paths {
+		ex, err := db.IsExcluded(ctx, path)
+		if err != nil {
+			return nil, 0, err
+		}
+		if ex || !db.acl.Allowed(ctx, path) {
+			continue
+		}
+		elems := strings.Split(path, "/")
+		if len(elems) > depth {
+			elems = elems[:depth]
+		}
+		pre := strings.Join(elems, "/")
+		p := byPrefix[pre]
+		if p == nil {
+			p = &PathPrefix{Prefix: pre}
+			byPrefix[pre] = p
+			pps = append(pps, p)
+		}
+		p.NumModules++
+		if path == pre {
+			p.IsModule = true
+		}
+	}
+	sort.Slice(pps, func(i, j int) bool {
+		if pps[i].NumModules != pps[j].NumModules {
+			return pps[i].NumModules > pps[j].NumModules
+		}
+		return pps[i].Prefix < pps[j].Prefix
+	})
+	total = len(pps)
+	if offset >= total {
+		return nil, total, nil
+	}
+	pps = pps[offset:]
+	if len(pps) > limit {
+		pps = pps[:limit]
+	}
+	return pps, total, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestGetPathPrefixes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	for _, m := range []string{
+		"github.com/a/x",
+		"github.com/a/y",
+		"github.com/b/z",
+		"golang.org/x/tools",
+		"golang.org/x/tools/gopls",
+		"bad.com/m",
+	} {
+		if err := testDB.InsertModule(ctx, sample.Module(m, sample.VersionString, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := testDB.InsertModule(ctx, sample.Module(stdlib.ModulePath, "v1.14.0", "errors")); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDB.InsertExcludedPrefix(ctx, "bad.com", "admin", "spam"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		prefix    string
+		wantTotal int
+		want      []*PathPrefix
+	}{
+		{
+			"",
+			2,
+			[]*PathPrefix{{Prefix: "github.com", NumModules: 3}, {Prefix: "golang.org", NumModules: 2}},
+		},
+		{
+			"github.com",
+			2,
+			[]*PathPrefix{{Prefix: "github.com/a", NumModules: 2}, {Prefix: "github.com/b", NumModules: 1}},
+		},
+		{
+			"golang.org/x",
+			1,
+			[]*PathPrefix{{Prefix: "golang.org/x/tools", NumModules: 2, IsModule: true}},
+		},
+		{"example.com", 0, nil},
+	} {
+		got, total, err := testDB.GetPathPrefixes(ctx, test.prefix, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != test.wantTotal {
+			t.Errorf("%q: got total %d, want %d", test.prefix, total, test.wantTotal)
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("%q: mismatch (-want +got):\n%s", test.prefix, diff)
+		}
+	}
+}
+
+func TestGetPathPrefixesACL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	for _, m := range []string{
+		"github.com/a/x",
+		"github.com/corp/secret",
+		"corp.com/tool",
+	} {
+		if err := testDB.InsertModule(ctx, sample.Module(m, sample.VersionString, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := New(testDB.Underlying())
+	db.SetACL(auth.NewACL(map[string][]string{
+		"github.com/corp": {"eng"},
+		"corp.com":        {"eng"},
+	}))
+	eng := auth.NewContextWithUser(ctx, &auth.User{Email: "e@corp.com", Groups: []string{"eng"}})
+
+	for _, test := range []struct {
+		name      string
+		ctx       context.Context
+		wantTotal int
+		want      []*PathPrefix
+	}{
+		{
+			"anonymous",
+			ctx,
+			1,
+			[]*PathPrefix{{Prefix: "github.com", NumModules: 1}},
+		},
+		{
+			"member of eng",
+			eng,
+			2,
+			[]*PathPrefix{{Prefix: "github.com", NumModules: 2}, {Prefix: "corp.com", NumModules: 1}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, total, err := db.GetPathPrefixes(test.ctx, "", 10, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != test.wantTotal {
+				t.Errorf("got total %d, want %d", total, test.wantTotal)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// LoadCheckpoint returns the packages of a module version saved by
+// SaveCheckpoint, and the states of the packages and of the directories that
+// could not be processed.
+func (db *DB) LoadCheckpoint(ctx context.Context, modulePath, version string) (pkgs []*internal.LegacyPackage, states []*internal.PackageVersionState, err error) {
+	defer derrors.Wrap(&err, "LoadCheckpoint(ctx, %q, %q)", modulePath, version)
+
+	query := `
+		SELECT package_path, status, error, ` + textOrGzip("package_json") + `
+		FROM package_checkpoints
+		WHERE module_path = $1 AND version = $2
+		ORDER BY package_path`
+	collect := func(rows *sql.Rows) error {
+		state := &internal.PackageVersionState{ModulePath: modulePath, Version: version}
+		var (
+			errMsg      sql.NullString
+			packageJSON string
+		)
+		if err := rows.Scan(&state.PackagePath, &state.Status, &errMsg, decompressed(&packageJSON)); err != nil {
+			return err
+		}
+		state.Error = errMsg.String
+		states = append(states, state)
+		if packageJSON == "" {
+			return nil
+		}
+		var pkg internal.LegacyPackage
+		if err := json.Unmarshal([]byte(packageJSON), &pkg); err != nil {
+			return err
+		}
+		pkgs = append(pkgs, &pkg)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, modulePath, version); err != nil {
+		return nil, nil, err
+	}
+	return pkgs, states, nil
+}
+
+// SaveCheckpoint adds pkgs to the packages saved for a module version, so
+// that a fetch of a large module can resume after an interruption, and
+// records the progress in module_version_states. States holds the state of
+// each package in pkgs, and of directories that could not be processed.
+func (db *DB) SaveCheckpoint(ctx context.Context, modulePath, version string, pkgs []*internal.LegacyPackage, states []*internal.PackageVersionState) (err error) {
+	defer derrors.Wrap(&err, "SaveCheckpoint(ctx, %q, %q, %d states)", modulePath, version, len(states))
+
+	byPath := map[string]*internal.LegacyPackage{}
+	for _, p := range pkgs {
+		byPath[p.Path] = p
+	}
+	var values []interface{}
+	for _, s := range states {
+		var text, gz interface{}
+		if p := byPath[s.PackagePath]; p != nil {
+			data, err := json.Marshal(p)
+			if err != nil {
+				return err
+			}
+			text, gz, err = compressText(string(data))
+			if err != nil {
+				return err
+			}
+		}
+		values = append(values, modulePath, version, s.PackagePath, s.Status, makeValidUnicode(s.Error), text, gz)
+	}
+	return db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		cols := []string{"module_path", "version", "package_path", "status", "error", "package_json", "package_json_gz"}
+		if err := tx.BulkUpsert(ctx, "package_checkpoints", cols, values, []string{"module_path", "version", "package_path"}); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			UPDATE module_version_states
+			SET
+				checkpoint_packages = (
+					SELECT count(*) FROM package_checkpoints
+					WHERE module_path = $1 AND version = $2),
+				checkpointed_at = CURRENT_TIMESTAMP
+			WHERE module_path = $1 AND version = $2`,
+			modulePath, version)
+		return err
+	})
+}
+
+// DeleteCheckpoint deletes the packages saved for a module version by
+// SaveCheckpoint. It is called when a fetch of the module version finishes.
+func (db *DB) DeleteCheckpoint(ctx context.Context, modulePath, version string) (err error) {
+	defer derrors.Wrap(&err, "DeleteCheckpoint(ctx, %q, %q)", modulePath, version)
+
+	return db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM package_checkpoints
+			WHERE module_path = $1 AND version = $2`,
+			modulePath, version); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			UPDATE module_version_states
+			SET checkpoint_packages = NULL, checkpointed_at = NULL
+			WHERE module_path = $1 AND version = $2 AND checkpoint_packages IS NOT NULL`,
+			modulePath, version)
+		return err
+	})
+}
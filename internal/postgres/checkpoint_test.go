@@ -0,0 +1,70 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestCheckpoint(t *testing.T) {
+	defer ResetTestDB(testDB, t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	const modulePath, version = "example.com/m", "v1.0.0"
+	p1 := sample.LegacyPackage(modulePath, "p1")
+	p2 := sample.LegacyPackage(modulePath, "p2")
+	// Large enough to be compressed.
+	p2.DocumentationHTML = strings.Repeat("<p>doc</p>", 1000)
+	state := func(pkgPath string, status int) *internal.PackageVersionState {
+		return &internal.PackageVersionState{
+			ModulePath:  modulePath,
+			PackagePath: pkgPath,
+			Version:     version,
+			Status:      status,
+		}
+	}
+	states := []*internal.PackageVersionState{
+		state(p1.Path, http.StatusOK),
+		state(p2.Path, http.StatusOK),
+		state(modulePath+"/bad", 600),
+	}
+	if err := testDB.SaveCheckpoint(ctx, modulePath, version, []*internal.LegacyPackage{p1}, states[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDB.SaveCheckpoint(ctx, modulePath, version, []*internal.LegacyPackage{p2}, states[1:]); err != nil {
+		t.Fatal(err)
+	}
+
+	gotPkgs, gotStates, err := testDB.LoadCheckpoint(ctx, modulePath, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*internal.LegacyPackage{p1, p2}, gotPkgs, sample.LicenseCmpOpts...); diff != "" {
+		t.Errorf("packages mismatch (-want +got):\n%s", diff)
+	}
+	wantStates := []*internal.PackageVersionState{states[2], states[0], states[1]} // ordered by path
+	if diff := cmp.Diff(wantStates, gotStates); diff != "" {
+		t.Errorf("states mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := testDB.DeleteCheckpoint(ctx, modulePath, version); err != nil {
+		t.Fatal(err)
+	}
+	gotPkgs, gotStates, err = testDB.LoadCheckpoint(ctx, modulePath, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotPkgs) != 0 || len(gotStates) != 0 {
+		t.Errorf("after DeleteCheckpoint: got %d packages and %d states, want none", len(gotPkgs), len(gotStates))
+	}
+}
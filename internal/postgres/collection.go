@@ -0,0 +1,196 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/collection"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// SaveCollection creates the collection c, or replaces the collection with
+// its name, and records the change by actor in the audit log.
+func (db *DB) SaveCollection(ctx context.Context, c *collection.Collection, actor string) (err error) {
+	defer derrors.Wrap(&err, "SaveCollection(ctx, %q, %q)", c.Name, actor)
+
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("%v: %w", err, derrors.InvalidArgument)
+	}
+	return db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		before, err := getCollection(ctx, tx, c.Name)
+		if err != nil && err != derrors.NotFound {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO collections (name, title, description, updated_by)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (name) DO UPDATE SET
+				title = excluded.title,
+				description = excluded.description,
+				updated_by = excluded.updated_by,
+				updated_at = CURRENT_TIMESTAMP`,
+			c.Name, c.Title, c.Description, actor); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM collection_packages WHERE collection_name = $1`, c.Name); err != nil {
+			return err
+		}
+		var values []interface{}
+		for i, p := range c.Packages {
+			values = append(values, c.Name, p.Path, p.Blurb, i)
+		}
+		if len(values) > 0 {
+			cols := []string{"collection_name", "package_path", "blurb", "position"}
+			if err := tx.BulkInsert(ctx, "collection_packages", cols, values, ""); err != nil {
+				return err
+			}
+		}
+		e := &audit.Entry{
+			Actor:  actor,
+			Action: audit.ActionSaveCollection,
+			Target: c.Name,
+			After:  collectionJSON(c),
+		}
+		if before != nil {
+			e.Before = collectionJSON(before)
+		}
+		return recordAuditEntry(ctx, tx, e)
+	})
+}
+
+// DeleteCollection deletes the collection with the given name, and records
+// its deletion by actor in the audit log. It returns an error that wraps
+// derrors.NotFound if there is no such collection.
+func (db *DB) DeleteCollection(ctx context.Context, name, actor string) (err error) {
+	defer derrors.Wrap(&err, "DeleteCollection(ctx, %q, %q)", name, actor)
+
+	return db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		before, err := getCollection(ctx, tx, name)
+		if err == derrors.NotFound {
+			return fmt.Errorf("collection %q: %w", name, derrors.NotFound)
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM collections WHERE name = $1`, name); err != nil {
+			return err
+		}
+		return recordAuditEntry(ctx, tx, &audit.Entry{
+			Actor:  actor,
+			Action: audit.ActionDeleteCollection,
+			Target: name,
+			Before: collectionJSON(before),
+		})
+	})
+}
+
+// collectionJSON describes c in the audit log, without the synopses of its
+// packages.
+func collectionJSON(c *collection.Collection) json.RawMessage {
+	type pkg struct {
+		Path  string `json:"path"`
+		Blurb string `json:"blurb,omitempty"`
+	}
+	v := struct {
+		Title       string `json:"title"`
+		Description string `json:"description,omitempty"`
+		Packages    []pkg  `json:"packages"`
+	}{Title: c.Title, Description: c.Description}
+	for _, p := range c.Packages {
+		v.Packages = append(v.Packages, pkg{p.Path, p.Blurb})
+	}
+	return auditJSON(v)
+}
+
+// GetCollections returns all collections, without their packages, in the
+// order of their titles.
+func (db *DB) GetCollections(ctx context.Context) (_ []*collection.Collection, err error) {
+	defer derrors.Wrap(&err, "GetCollections(ctx)")
+
+	var cs []*collection.Collection
+	collect := func(rows *sql.Rows) error {
+		var c collection.Collection
+		if err := rows.Scan(&c.Name, &c.Title, &c.Description, &c.UpdatedBy, &c.UpdatedAt); err != nil {
+			return err
+		}
+		cs = append(cs, &c)
+		return nil
+	}
+	query := `
+		SELECT name, title, description, updated_by, updated_at
+		FROM collections
+		ORDER BY title, name`
+	if err := db.db.RunQuery(ctx, query, collect); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// GetCollection returns the collection with the given name, with its packages
+// and their synopses. Packages that the user in ctx may not see are left out.
+// It returns an error that wraps derrors.NotFound if there is no such
+// collection.
+func (db *DB) GetCollection(ctx context.Context, name string) (_ *collection.Collection, err error) {
+	defer derrors.Wrap(&err, "GetCollection(ctx, %q)", name)
+
+	c, err := getCollection(ctx, db.db, name)
+	if err == derrors.NotFound {
+		return nil, fmt.Errorf("collection %q: %w", name, derrors.NotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if db.acl != nil {
+		var pkgs []*collection.Package
+		for _, p := range c.Packages {
+			if db.acl.Allowed(ctx, p.Path) {
+				pkgs = append(pkgs, p)
+			}
+		}
+		c.Packages = pkgs
+	}
+	return c, nil
+}
+
+// getCollection returns the collection with the given name, or
+// derrors.NotFound itself if there is none.
+func getCollection(ctx context.Context, db *database.DB, name string) (*collection.Collection, error) {
+	c := &collection.Collection{Name: name}
+	err := db.QueryRow(ctx, `
+		SELECT title, description, updated_by, updated_at
+		FROM collections
+		WHERE name = $1`, name).Scan(&c.Title, &c.Description, &c.UpdatedBy, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, derrors.NotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	collect := func(rows *sql.Rows) error {
+		var p collection.Package
+		if err := rows.Scan(&p.Path, &p.Blurb, &p.Synopsis); err != nil {
+			return err
+		}
+		c.Packages = append(c.Packages, &p)
+		return nil
+	}
+	query := `
+		SELECT cp.package_path, cp.blurb, COALESCE(sd.synopsis, '')
+		FROM collection_packages cp
+		LEFT JOIN search_documents sd
+		ON sd.package_path = cp.package_path
+		WHERE cp.collection_name = $1
+		ORDER BY cp.position`
+	if err := db.RunQuery(ctx, query, collect, name); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
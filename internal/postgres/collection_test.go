@@ -0,0 +1,146 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/collection"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestCollections(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	if err := testDB.InsertModule(ctx, sample.Module("github.com/gorilla/mux", sample.VersionString, "")); err != nil {
+		t.Fatal(err)
+	}
+	const actor = "a@example.com"
+	c := &collection.Collection{
+		Name:  "http-routers",
+		Title: "HTTP routers",
+		Packages: []*collection.Package{
+			{Path: "github.com/gorilla/mux", Blurb: "A powerful router."},
+			{Path: "github.com/go-chi/chi", Blurb: "A lightweight router."},
+		},
+	}
+	if err := testDB.SaveCollection(ctx, c, actor); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDB.SaveCollection(ctx, &collection.Collection{Name: "x"}, actor); !errors.Is(err, derrors.InvalidArgument) {
+		t.Errorf("SaveCollection without a title: got %v, want InvalidArgument", err)
+	}
+
+	got, err := testDB.GetCollection(ctx, c.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &collection.Collection{
+		Name:  c.Name,
+		Title: c.Title,
+		Packages: []*collection.Package{
+			{Path: "github.com/gorilla/mux", Blurb: "A powerful router.", Synopsis: sample.Synopsis},
+			{Path: "github.com/go-chi/chi", Blurb: "A lightweight router."},
+		},
+		UpdatedBy: actor,
+	}
+	ignore := cmpopts.IgnoreFields(collection.Collection{}, "UpdatedAt")
+	if diff := cmp.Diff(want, got, ignore); diff != "" {
+		t.Errorf("GetCollection mismatch (-want +got):\n%s", diff)
+	}
+
+	// Saving a collection again replaces its packages.
+	c.Description = "Routers for net/http."
+	c.Packages = c.Packages[1:]
+	if err := testDB.SaveCollection(ctx, c, actor); err != nil {
+		t.Fatal(err)
+	}
+	all, err := testDB.GetCollections(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantAll := []*collection.Collection{{Name: c.Name, Title: c.Title, Description: c.Description, UpdatedBy: actor}}
+	if diff := cmp.Diff(wantAll, all, ignore); diff != "" {
+		t.Errorf("GetCollections mismatch (-want +got):\n%s", diff)
+	}
+	got, err = testDB.GetCollection(ctx, c.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Packages) != 1 || got.Packages[0].Path != "github.com/go-chi/chi" {
+		t.Errorf("got packages %+v, want only github.com/go-chi/chi", got.Packages)
+	}
+
+	if err := testDB.DeleteCollection(ctx, c.Name, actor); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDB.DeleteCollection(ctx, c.Name, actor); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("DeleteCollection twice: got %v, want NotFound", err)
+	}
+	if _, err := testDB.GetCollection(ctx, c.Name); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("GetCollection after delete: got %v, want NotFound", err)
+	}
+
+	entries, err := testDB.GetAuditLog(ctx, audit.Query{Target: c.Name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("got %d audit entries, want 3", len(entries))
+	}
+}
+
+func TestGetCollectionACL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	c := &collection.Collection{
+		Name:  "tools",
+		Title: "Tools",
+		Packages: []*collection.Package{
+			{Path: "corp.com/secret/tool"},
+			{Path: "github.com/public/tool"},
+		},
+	}
+	if err := testDB.SaveCollection(ctx, c, "a@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	db := New(testDB.Underlying())
+	db.SetACL(auth.NewACL(map[string][]string{"corp.com": {"eng"}}))
+	eng := auth.NewContextWithUser(ctx, &auth.User{Email: "e@corp.com", Groups: []string{"eng"}})
+
+	for _, test := range []struct {
+		name string
+		ctx  context.Context
+		want []string
+	}{
+		{"anonymous", ctx, []string{"github.com/public/tool"}},
+		{"member of eng", eng, []string{"corp.com/secret/tool", "github.com/public/tool"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := db.GetCollection(test.ctx, c.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var paths []string
+			for _, p := range got.Packages {
+				paths = append(paths, p.Path)
+			}
+			if diff := cmp.Diff(test.want, paths); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
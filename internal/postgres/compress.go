@@ -0,0 +1,152 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// Documentation HTML and README contents can be large, so they are stored
+// gzip-compressed. Each text column holding them has a companion bytea column,
+// with the same name and a "_gz" suffix. A value is stored in at most one of
+// the two columns: small values, and values written before compression was
+// introduced, are in the text column.
+
+// compressThreshold is the size above which values are stored compressed.
+const compressThreshold = 4096
+
+// gzipMagic begins every gzip stream. Since it is not valid UTF-8 text, it
+// distinguishes compressed values from uncompressed ones when reading.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// compressedColumns lists the text columns that have a compressed companion,
+// by table.
+var compressedColumns = []struct {
+	table, column string
+}{
+	{"packages", "documentation"},
+	{"documentation", "html"},
+	{"modules", "readme_contents"},
+	{"readmes", "contents"},
+}
+
+// compressText returns the values to store for s in a text column and in its
+// compressed companion. One of them is nil.
+func compressText(s string) (text interface{}, gz interface{}, err error) {
+	if len(s) <= compressThreshold {
+		return s, nil, nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		return nil, nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, nil, err
+	}
+	return nil, buf.Bytes(), nil
+}
+
+// textOrGzip returns an SQL expression for the value stored in the text
+// column or its compressed companion. The value must be scanned with
+// decompressed.
+func textOrGzip(column string) string {
+	return fmt.Sprintf("COALESCE(%[1]s_gz, convert_to(%[1]s, 'UTF8'))", column)
+}
+
+// decompressed returns a sql.Scanner that writes to s a value selected with
+// textOrGzip, decompressing it if needed. NULL is written as the empty string.
+func decompressed(s *string) sql.Scanner {
+	return decompressingScanner{s}
+}
+
+type decompressingScanner struct {
+	ptr *string
+}
+
+func (d decompressingScanner) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d.ptr = ""
+	case string:
+		*d.ptr = v
+	case []byte:
+		if !bytes.HasPrefix(v, gzipMagic) {
+			*d.ptr = string(v)
+			return nil
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(v))
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadAll(zr)
+		if err != nil {
+			return err
+		}
+		*d.ptr = string(data)
+	default:
+		return fmt.Errorf("decompressed: cannot scan %T", value)
+	}
+	return nil
+}
+
+// CompressDocumentation compresses up to limit large values in each table
+// that were stored uncompressed, and returns the number of values
+// compressed. It is used to backfill values written before compression was
+// introduced.
+func (db *DB) CompressDocumentation(ctx context.Context, limit int) (n int, err error) {
+	defer derrors.Wrap(&err, "CompressDocumentation(ctx, %d)", limit)
+
+	for _, c := range compressedColumns {
+		query := fmt.Sprintf(`
+			SELECT ctid, %[1]s
+			FROM %[2]s
+			WHERE %[1]s IS NOT NULL AND octet_length(%[1]s) > $1
+			LIMIT $2`, c.column, c.table)
+		// A row is identified by its ctid, its physical location, because
+		// the tables do not share a kind of key.
+		type value struct {
+			ctid string
+			text string
+		}
+		var values []value
+		collect := func(rows *sql.Rows) error {
+			var v value
+			if err := rows.Scan(&v.ctid, &v.text); err != nil {
+				return err
+			}
+			values = append(values, v)
+			return nil
+		}
+		if err := db.db.RunQuery(ctx, query, collect, compressThreshold, limit); err != nil {
+			return n, err
+		}
+		for _, v := range values {
+			_, gz, err := compressText(v.text)
+			if err != nil {
+				return n, err
+			}
+			// Check that the text has not changed since it was read, in case
+			// the row was rewritten by a concurrent insert.
+			update := fmt.Sprintf(`
+				UPDATE %[1]s SET %[2]s = NULL, %[2]s_gz = $1
+				WHERE ctid = $2 AND %[2]s = $3`, c.table, c.column)
+			if _, err := db.db.Exec(ctx, update, gz, v.ctid, v.text); err != nil {
+				return n, err
+			}
+			n++
+		}
+		log.Infof(ctx, "compressed %d values of %s.%s", len(values), c.table, c.column)
+	}
+	return n, nil
+}
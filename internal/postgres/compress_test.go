@@ -0,0 +1,99 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestCompressText(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"small",
+		strings.Repeat("x", compressThreshold),
+		strings.Repeat("<p>large documentation</p>", compressThreshold),
+	} {
+		text, gz, err := compressText(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (text == nil) == (gz == nil) {
+			t.Fatalf("compressText(%d bytes): got text %v and gz %v, want exactly one of them", len(s), text != nil, gz != nil)
+		}
+		if wantGz := len(s) > compressThreshold; (gz != nil) != wantGz {
+			t.Errorf("compressText(%d bytes): compressed = %t, want %t", len(s), gz != nil, wantGz)
+		}
+		// Values are read through textOrGzip, which converts the text column
+		// to bytes.
+		value := gz
+		if value == nil {
+			value = []byte(text.(string))
+		}
+		var got string
+		if err := decompressed(&got).Scan(value); err != nil {
+			t.Fatal(err)
+		}
+		if got != s {
+			t.Errorf("round trip of %d bytes: got %d bytes, want the original", len(s), len(got))
+		}
+	}
+}
+
+func TestCompressDocumentation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	defer ResetTestDB(testDB, t)
+
+	doc := strings.Repeat("<p>large documentation</p>", compressThreshold)
+	m := sample.Module(sample.ModulePath, sample.VersionString, "")
+	m.LegacyPackages[0].DocumentationHTML = doc
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	pkgPath := m.LegacyPackages[0].Path
+
+	checkDoc := func() {
+		t.Helper()
+		got, err := testDB.GetPackage(ctx, pkgPath, sample.ModulePath, sample.VersionString, internal.AllFields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.DocumentationHTML != doc {
+			t.Errorf("GetPackage: got %d bytes of documentation, want %d", len(got.DocumentationHTML), len(doc))
+		}
+	}
+	checkDoc()
+
+	// Simulate a value written before compression was introduced.
+	if _, err := testDB.db.Exec(ctx, `
+		UPDATE packages SET documentation = $1, documentation_gz = NULL
+		WHERE path = $2`, doc, pkgPath); err != nil {
+		t.Fatal(err)
+	}
+	checkDoc()
+
+	n, err := testDB.CompressDocumentation(ctx, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("CompressDocumentation: got %d values compressed, want 1", n)
+	}
+	checkDoc()
+
+	n, err = testDB.CompressDocumentation(ctx, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("second CompressDocumentation: got %d values compressed, want 0", n)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// An ExportTable is a table whose rows can be exported with ExportRows, for
+// analysis outside the site. Only public information about modules is
+// exported: not the contents of READMEs, documentation or licenses, and
+// nothing about the site's users.
+type ExportTable struct {
+	// Name is the name of the exported table.
+	Name string
+	// Columns are the names of the exported columns.
+	Columns []string
+
+	// exprs are the SQL expressions for the columns, from the from clause.
+	exprs []string
+	from  string
+	// modulePathColumn holds the path of the module that each row is about,
+	// which determines the row's partition and visibility.
+	modulePathColumn string
+}
+
+// ExportTables are the tables that can be exported.
+var ExportTables = []*ExportTable{
+	{
+		Name:             "modules",
+		Columns:          []string{"module_path", "version", "commit_time", "version_type", "series_path", "redistributable", "has_go_mod"},
+		from:             "modules",
+		modulePathColumn: "module_path",
+	},
+	{
+		Name:    "packages",
+		Columns: []string{"path", "module_path", "version", "commit_time", "name", "synopsis", "license_types", "v1_path", "goos", "goarch", "redistributable"},
+		exprs: []string{"path", "module_path", "version", "commit_time", "name",
+			// Like the site, show synopses only of redistributable packages.
+			"CASE WHEN redistributable THEN synopsis END",
+			"array_to_string(license_types, ',')", "v1_path", "goos", "goarch", "redistributable"},
+		from:             "packages",
+		modulePathColumn: "module_path",
+	},
+	{
+		Name:             "licenses",
+		Columns:          []string{"module_path", "version", "file_path", "types"},
+		exprs:            []string{"module_path", "version", "file_path", "array_to_string(types, ',')"},
+		from:             "licenses",
+		modulePathColumn: "module_path",
+	},
+	{
+		Name:             "imports",
+		Columns:          []string{"from_path", "from_module_path", "from_version", "to_path"},
+		from:             "imports",
+		modulePathColumn: "from_module_path",
+	},
+	{
+		Name:             "imported_by",
+		Columns:          []string{"to_path", "from_path", "from_module_path"},
+		from:             "imports_unique",
+		modulePathColumn: "from_module_path",
+	},
+}
+
+// LookupExportTable returns the ExportTable with the given name, or nil if
+// there is none.
+func LookupExportTable(name string) *ExportTable {
+	for _, t := range ExportTables {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// ExportRows calls f with the values of each row of partition p of table t,
+// converted to text, in the order of t.Columns. Null values are empty. The
+// rows of a table are divided into numPartitions partitions by hashing their
+// module paths. Rows about modules that the ACL of db hides from signed-out
+// users are skipped.
+func (db *DB) ExportRows(ctx context.Context, t *ExportTable, p, numPartitions int, f func([]string) error) (err error) {
+	defer derrors.Wrap(&err, "ExportRows(ctx, %q, %d, %d)", t.Name, p, numPartitions)
+
+	exprs := t.exprs
+	if exprs == nil {
+		exprs = t.Columns
+	}
+	var cols []string
+	for _, e := range exprs {
+		cols = append(cols, fmt.Sprintf("(%s)::text", e))
+	}
+	query := fmt.Sprintf(`
+		SELECT %[1]s::text, %[2]s
+		FROM %[3]s
+		WHERE mod(abs(hashtext(%[1]s)::bigint), $1) = $2
+		ORDER BY %[1]s`,
+		t.modulePathColumn, strings.Join(cols, ", "), t.from)
+	// The module path is scanned first, to check visibility, and is not
+	// passed to f.
+	return db.db.RunQuery(ctx, query, func(rows *sql.Rows) error {
+		var modulePath string
+		values := make([]sql.NullString, len(exprs))
+		dest := []interface{}{&modulePath}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		if !db.acl.Allowed(ctx, modulePath) {
+			return nil
+		}
+		row := make([]string, len(values))
+		for i, v := range values {
+			row[i] = v.String
+		}
+		return f(row)
+	}, numPartitions, p)
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestExportRows(t *testing.T) {
+	defer ResetTestDB(testDB, t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	nonRedist := sample.Module("example.com/b", "v1.0.0", "p")
+	nonRedist.LegacyPackages[0].IsRedistributable = false
+	for _, m := range []*internal.Module{
+		sample.Module("example.com/a", "v1.0.0", "p"),
+		nonRedist,
+		sample.Module("corp.com/secret", "v1.0.0", "p"),
+	} {
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := New(testDB.Underlying())
+	db.SetACL(auth.NewACL(map[string][]string{"corp.com": {"eng"}}))
+
+	// The rows of every partition together are all the visible rows.
+	const numPartitions = 3
+	var got [][]string
+	for p := 0; p < numPartitions; p++ {
+		err := db.ExportRows(ctx, LookupExportTable("packages"), p, numPartitions, func(row []string) error {
+			got = append(got, []string{row[0], row[5], row[10]})
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i][0] < got[j][0] })
+	want := [][]string{
+		{"example.com/a/p", sample.Synopsis, "true"},
+		{"example.com/b/p", "", "false"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
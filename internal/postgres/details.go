@@ -34,7 +34,7 @@ func (db *DB) GetPackagesInModule(ctx context.Context, modulePath, version strin
 		license_types,
 		license_paths,
 		redistributable,
-		documentation,
+		` + textOrGzip("documentation") + `,
 		goos,
 		goarch
 	FROM
@@ -44,6 +44,9 @@ func (db *DB) GetPackagesInModule(ctx context.Context, modulePath, version strin
 		AND version = $2
 	ORDER BY path;`
 
+	if err := db.checkAccess(ctx, modulePath); err != nil {
+		return nil, fmt.Errorf("DB.GetPackagesInModule(ctx, %q, %q): %w", modulePath, version, err)
+	}
 	var packages []*internal.LegacyPackage
 	collect := func(rows *sql.Rows) error {
 		var (
@@ -51,7 +54,7 @@ func (db *DB) GetPackagesInModule(ctx context.Context, modulePath, version strin
 			licenseTypes, licensePaths []string
 		)
 		if err := rows.Scan(&p.Path, &p.Name, &p.Synopsis, &p.V1Path, pq.Array(&licenseTypes),
-			pq.Array(&licensePaths), &p.IsRedistributable, database.NullIsEmpty(&p.DocumentationHTML),
+			pq.Array(&licensePaths), &p.IsRedistributable, decompressed(&p.DocumentationHTML),
 			&p.GOOS, &p.GOARCH); err != nil {
 			return fmt.Errorf("row.Scan(): %v", err)
 		}
@@ -74,14 +77,22 @@ func (db *DB) GetPackagesInModule(ctx context.Context, modulePath, version strin
 // descending semver order. This list includes tagged versions of packages that
 // have the same v1path.
 func (db *DB) GetTaggedVersionsForPackageSeries(ctx context.Context, pkgPath string) ([]*internal.LegacyModuleInfo, error) {
-	return getPackageVersions(ctx, db, pkgPath, []version.Type{version.TypeRelease, version.TypePrerelease})
+	mis, err := getPackageVersions(ctx, db, pkgPath, []version.Type{version.TypeRelease, version.TypePrerelease})
+	if err != nil {
+		return nil, err
+	}
+	return db.filterModuleInfos(ctx, mis), nil
 }
 
 // GetPseudoVersionsForPackageSeries returns the 10 most recent from a list of
 // pseudo-versions sorted in descending semver order. This list includes
 // pseudo-versions of packages that have the same v1path.
 func (db *DB) GetPseudoVersionsForPackageSeries(ctx context.Context, pkgPath string) ([]*internal.LegacyModuleInfo, error) {
-	return getPackageVersions(ctx, db, pkgPath, []version.Type{version.TypePseudo})
+	mis, err := getPackageVersions(ctx, db, pkgPath, []version.Type{version.TypePseudo})
+	if err != nil {
+		return nil, err
+	}
+	return db.filterModuleInfos(ctx, mis), nil
 }
 
 // getPackageVersions returns a list of versions sorted in descending semver
@@ -155,13 +166,21 @@ func versionTypeExpr(vts []version.Type) string {
 // GetTaggedVersionsForModule returns a list of tagged versions sorted in
 // descending semver order.
 func (db *DB) GetTaggedVersionsForModule(ctx context.Context, modulePath string) ([]*internal.LegacyModuleInfo, error) {
-	return getModuleVersions(ctx, db, modulePath, []version.Type{version.TypeRelease, version.TypePrerelease})
+	mis, err := getModuleVersions(ctx, db, modulePath, []version.Type{version.TypeRelease, version.TypePrerelease})
+	if err != nil {
+		return nil, err
+	}
+	return db.filterModuleInfos(ctx, mis), nil
 }
 
 // GetPseudoVersionsForModule returns the 10 most recent from a list of
 // pseudo-versions sorted in descending semver order.
 func (db *DB) GetPseudoVersionsForModule(ctx context.Context, modulePath string) ([]*internal.LegacyModuleInfo, error) {
-	return getModuleVersions(ctx, db, modulePath, []version.Type{version.TypePseudo})
+	mis, err := getModuleVersions(ctx, db, modulePath, []version.Type{version.TypePseudo})
+	if err != nil {
+		return nil, err
+	}
+	return db.filterModuleInfos(ctx, mis), nil
 }
 
 // getModuleVersions returns a list of versions sorted in descending semver
@@ -204,6 +223,71 @@ func getModuleVersions(ctx context.Context, db *DB, modulePath string, versionTy
 	return vinfos, nil
 }
 
+// GetLatestModuleVersions returns the latest version of every module in the
+// database, sorted by module path. Release versions are preferred over
+// prereleases and pseudo-versions.
+func (db *DB) GetLatestModuleVersions(ctx context.Context) (_ []*internal.ModuleInfo, err error) {
+	defer derrors.Wrap(&err, "GetLatestModuleVersions(ctx)")
+
+	query := `
+		SELECT DISTINCT ON (module_path)
+			module_path, version, commit_time
+		FROM
+			modules
+		ORDER BY
+			module_path,
+			version_type = 'release' DESC,
+			sort_version DESC;`
+	var mis []*internal.ModuleInfo
+	collect := func(rows *sql.Rows) error {
+		var mi internal.ModuleInfo
+		if err := rows.Scan(&mi.ModulePath, &mi.Version, &mi.CommitTime); err != nil {
+			return err
+		}
+		mis = append(mis, &mi)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect); err != nil {
+		return nil, err
+	}
+	return mis, nil
+}
+
+// GetLatestVersions returns the latest versions of the modules with
+// modulePaths, as GetModuleInfo does for internal.LatestVersion, keyed by
+// module path. Modules that are unknown, or that the user in ctx may not see,
+// are not in the map.
+func (db *DB) GetLatestVersions(ctx context.Context, modulePaths []string) (_ map[string]string, err error) {
+	defer derrors.Wrap(&err, "GetLatestVersions(ctx, %d paths)", len(modulePaths))
+
+	query := `
+		SELECT DISTINCT ON (module_path)
+			module_path, version
+		FROM
+			modules
+		WHERE
+			module_path = ANY($1)
+		ORDER BY
+			module_path,
+			version_type = 'release' DESC,
+			sort_version DESC;`
+	versions := map[string]string{}
+	collect := func(rows *sql.Rows) error {
+		var path, version string
+		if err := rows.Scan(&path, &version); err != nil {
+			return fmt.Errorf("row.Scan(): %v", err)
+		}
+		if db.acl.Allowed(ctx, path) {
+			versions[path] = version
+		}
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, pq.Array(modulePaths)); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
 // GetImports fetches and returns all of the imports for the package with
 // pkgPath, modulePath and version.
 //
@@ -215,6 +299,9 @@ func (db *DB) GetImports(ctx context.Context, pkgPath, modulePath, version strin
 	if pkgPath == "" || version == "" || modulePath == "" {
 		return nil, fmt.Errorf("pkgPath, modulePath and version must all be non-empty: %w", derrors.InvalidArgument)
 	}
+	if err := db.checkAccess(ctx, modulePath); err != nil {
+		return nil, err
+	}
 
 	var toPath string
 	query := `
@@ -238,7 +325,40 @@ func (db *DB) GetImports(ctx context.Context, pkgPath, modulePath, version strin
 	if err := db.db.RunQuery(ctx, query, collect, pkgPath, version, modulePath); err != nil {
 		return nil, err
 	}
-	return imports, nil
+	return db.filterPaths(ctx, imports), nil
+}
+
+// GetPreviousPackageVersion returns the closest version of modulePath before
+// versionString that contains the package pkgPath. Pseudo-versions are only
+// considered if versionString is one. It returns an error that wraps
+// derrors.NotFound if there is no such version.
+func (db *DB) GetPreviousPackageVersion(ctx context.Context, pkgPath, modulePath, versionString string) (_ string, err error) {
+	defer derrors.Wrap(&err, "GetPreviousPackageVersion(ctx, %q, %q, %q)", pkgPath, modulePath, versionString)
+
+	query := `
+		SELECT m.version
+		FROM modules m
+		INNER JOIN packages p
+		ON p.module_path = m.module_path AND p.version = m.version
+		WHERE
+			p.path = $1
+			AND m.module_path = $2
+			AND m.sort_version < (
+				SELECT sort_version FROM modules
+				WHERE module_path = $2 AND version = $3)
+			AND ($4 OR m.version_type != 'pseudo')
+		ORDER BY m.sort_version DESC
+		LIMIT 1`
+	var prev string
+	isPseudo := version.IsPseudo(versionString)
+	err = db.db.QueryRow(ctx, query, pkgPath, modulePath, versionString, isPseudo).Scan(&prev)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%s@%s: %w", pkgPath, versionString, derrors.NotFound)
+	}
+	if err != nil {
+		return "", err
+	}
+	return prev, nil
 }
 
 // GetImportedBy fetches and returns all of the packages that import the
@@ -246,12 +366,16 @@ func (db *DB) GetImports(ctx context.Context, pkgPath, modulePath, version strin
 // The returned error may be checked with derrors.IsInvalidArgument to
 // determine if it resulted from an invalid package path or version.
 //
-// Instead of supporting pagination, this query runs with a limit.
+// Instead of supporting pagination, this query runs with a limit. Since
+// imports_unique is partitioned by to_path, it reads a single partition.
 func (db *DB) GetImportedBy(ctx context.Context, pkgPath, modulePath string, limit int) (paths []string, err error) {
 	defer derrors.Wrap(&err, "GetImportedBy(ctx, %q, %q)", pkgPath, modulePath)
 	if pkgPath == "" {
 		return nil, fmt.Errorf("pkgPath cannot be empty: %w", derrors.InvalidArgument)
 	}
+	if err := db.checkAccess(ctx, modulePath); err != nil {
+		return nil, err
+	}
 	query := `
 		SELECT
 			DISTINCT from_path
@@ -277,7 +401,64 @@ func (db *DB) GetImportedBy(ctx context.Context, pkgPath, modulePath string, lim
 	if err := db.db.RunQuery(ctx, query, collect, pkgPath, modulePath, limit); err != nil {
 		return nil, err
 	}
-	return importedby, nil
+	return db.filterPaths(ctx, importedby), nil
+}
+
+// GetImportedByCount returns the number of packages in other modules that
+// import the package with pkgPath, as of the last time the counts were
+// computed; see UpdateSearchDocumentsImportedByCount. It returns 0 if the
+// package has no known importers.
+func (db *DB) GetImportedByCount(ctx context.Context, pkgPath, modulePath string) (_ int, err error) {
+	defer derrors.Wrap(&err, "GetImportedByCount(ctx, %q, %q)", pkgPath, modulePath)
+
+	if err := db.checkAccess(ctx, modulePath); err != nil {
+		return 0, err
+	}
+	var n int
+	err = db.db.QueryRow(ctx, `
+		SELECT imported_by_count
+		FROM imported_by_counts
+		WHERE package_path = $1`, pkgPath).Scan(&n)
+	switch err {
+	case sql.ErrNoRows:
+		return 0, nil
+	case nil:
+		return n, nil
+	default:
+		return 0, err
+	}
+}
+
+// GetImportedByCounts returns the imported-by counts of the packages with
+// pkgPaths in the module with modulePath, as GetImportedByCount does. Packages
+// with no known importers are not in the map.
+func (db *DB) GetImportedByCounts(ctx context.Context, modulePath string, pkgPaths []string) (_ map[string]int, err error) {
+	defer derrors.Wrap(&err, "GetImportedByCounts(ctx, %q, %d paths)", modulePath, len(pkgPaths))
+
+	if err := db.checkAccess(ctx, modulePath); err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	collect := func(rows *sql.Rows) error {
+		var (
+			path string
+			n    int
+		)
+		if err := rows.Scan(&path, &n); err != nil {
+			return fmt.Errorf("row.Scan(): %v", err)
+		}
+		if n > 0 {
+			counts[path] = n
+		}
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, `
+		SELECT package_path, imported_by_count
+		FROM imported_by_counts
+		WHERE package_path = ANY($1)`, collect, pq.Array(pkgPaths)); err != nil {
+		return nil, err
+	}
+	return counts, nil
 }
 
 // GetModuleLicenses returns all licenses associated with the given module path and
@@ -289,6 +470,9 @@ func (db *DB) GetModuleLicenses(ctx context.Context, modulePath, version string)
 	if modulePath == "" || version == "" {
 		return nil, fmt.Errorf("neither modulePath nor version can be empty: %w", derrors.InvalidArgument)
 	}
+	if err := db.checkAccess(ctx, modulePath); err != nil {
+		return nil, err
+	}
 	query := `
 	SELECT
 		types, file_path, contents, coverage
@@ -314,6 +498,9 @@ func (db *DB) GetPackageLicenses(ctx context.Context, pkgPath, modulePath, versi
 	if pkgPath == "" || version == "" {
 		return nil, fmt.Errorf("neither pkgPath nor version can be empty: %w", derrors.InvalidArgument)
 	}
+	if err := db.checkAccess(ctx, pkgPath); err != nil {
+		return nil, err
+	}
 	query := `
 		SELECT
 			l.types,
@@ -432,11 +619,14 @@ func (db *DB) GetModuleInfo(ctx context.Context, modulePath string, version stri
 			version,
 			commit_time,
 			readme_file_path,
-			readme_contents,
+			` + textOrGzip("readme_contents") + `,
 			version_type,
 			source_info,
 			redistributable,
-			has_go_mod
+			has_go_mod,
+			origin,
+			has_executables,
+			has_large_files
 		FROM
 			modules`
 
@@ -463,14 +653,18 @@ func (db *DB) GetModuleInfo(ctx context.Context, modulePath string, version stri
 	)
 	row := db.db.QueryRow(ctx, query, args...)
 	if err := row.Scan(&mi.ModulePath, &mi.Version, &mi.CommitTime,
-		database.NullIsEmpty(&mi.LegacyReadmeFilePath), database.NullIsEmpty(&mi.LegacyReadmeContents), &mi.VersionType,
-		jsonbScanner{&mi.SourceInfo}, &mi.IsRedistributable, &hasGoMod); err != nil {
+		database.NullIsEmpty(&mi.LegacyReadmeFilePath), decompressed(&mi.LegacyReadmeContents), &mi.VersionType,
+		jsonbScanner{&mi.SourceInfo}, &mi.IsRedistributable, &hasGoMod, jsonbScanner{&mi.Origin},
+		&mi.HasExecutables, &mi.HasLargeFiles); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("module version %s@%s: %w", modulePath, version, derrors.NotFound)
 		}
 		return nil, fmt.Errorf("row.Scan(): %v", err)
 	}
 	setHasGoMod(&mi.ModuleInfo, hasGoMod)
+	if err := db.checkAccess(ctx, mi.ModulePath); err != nil {
+		return nil, err
+	}
 	return &mi, nil
 }
 
@@ -15,6 +15,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/auth"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/licenses"
 	"golang.org/x/pkgsite/internal/source"
@@ -353,6 +354,63 @@ func TestGetPackagesInVersion(t *testing.T) {
 	}
 }
 
+func TestGetLatestModuleVersions(t *testing.T) {
+	defer ResetTestDB(testDB, t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	for _, m := range []*internal.Module{
+		sample.Module("a.com/m", "v1.0.0", "p"),
+		sample.Module("a.com/m", "v1.1.0", "p"),
+		sample.Module("a.com/m", "v1.2.0-pre", "p"),
+		sample.Module("b.com/m", "v0.0.0-20200101000000-0123456789ab", "p"),
+	} {
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := testDB.GetLatestModuleVersions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotVersions []string
+	for _, mi := range got {
+		gotVersions = append(gotVersions, mi.ModulePath+"@"+mi.Version)
+	}
+	want := []string{"a.com/m@v1.1.0", "b.com/m@v0.0.0-20200101000000-0123456789ab"}
+	if diff := cmp.Diff(want, gotVersions); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetLatestVersions(t *testing.T) {
+	defer ResetTestDB(testDB, t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	for _, m := range []*internal.Module{
+		sample.Module("a.com/m", "v1.0.0", "p"),
+		sample.Module("a.com/m", "v1.1.0", "p"),
+		sample.Module("a.com/m", "v1.2.0-pre", "p"),
+		sample.Module("b.com/m", "v0.1.0", "p"),
+		sample.Module("corp.com/m", "v1.0.0", "p"),
+	} {
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := New(testDB.Underlying())
+	db.SetACL(auth.NewACL(map[string][]string{"corp.com": {"eng"}}))
+	got, err := db.GetLatestVersions(ctx, []string{"a.com/m", "corp.com/m", "unknown.com/m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"a.com/m": "v1.1.0"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestGetPackageLicenses(t *testing.T) {
 	modulePath := "test.module"
 	testModule := sample.Module(modulePath, "v1.2.3", "", "foo")
@@ -456,3 +514,46 @@ func TestJSONBScanner(t *testing.T) {
 		t.Errorf("got %#v, want nil", got2)
 	}
 }
+
+func TestGetPreviousPackageVersion(t *testing.T) {
+	defer ResetTestDB(testDB, t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	const (
+		modulePath = "a.com/m"
+		pkgPath    = "a.com/m/p"
+	)
+	for _, m := range []*internal.Module{
+		sample.Module(modulePath, "v1.0.0", "p"),
+		sample.Module(modulePath, "v1.1.0", "q"),
+		sample.Module(modulePath, "v1.2.1-0.20200101000000-0123456789ab", "p"),
+		sample.Module(modulePath, "v1.3.0", "p"),
+		sample.Module(modulePath, "v1.3.1-0.20200101000000-0123456789ab", "p"),
+		sample.Module(modulePath, "v1.3.1-0.20200102000000-0123456789ab", "p"),
+	} {
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, test := range []struct {
+		version, want string
+	}{
+		// v1.1.0 does not contain the package, and pseudo-versions are
+		// skipped.
+		{"v1.3.0", "v1.0.0"},
+		{"v1.3.1-0.20200101000000-0123456789ab", "v1.3.0"},
+		{"v1.3.1-0.20200102000000-0123456789ab", "v1.3.1-0.20200101000000-0123456789ab"},
+	} {
+		got, err := testDB.GetPreviousPackageVersion(ctx, pkgPath, modulePath, test.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("GetPreviousPackageVersion(%q) = %q, want %q", test.version, got, test.want)
+		}
+	}
+	if _, err := testDB.GetPreviousPackageVersion(ctx, pkgPath, modulePath, "v1.0.0"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("GetPreviousPackageVersion of the first version: got %v, want NotFound", err)
+	}
+}
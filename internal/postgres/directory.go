@@ -21,6 +21,9 @@ import (
 // data associated with that directory, including the package, imports, readme,
 // documentation, and licenses.
 func (db *DB) GetDirectoryNew(ctx context.Context, path, modulePath, version string) (_ *internal.VersionedDirectory, err error) {
+	if err := db.checkAccess(ctx, modulePath); err != nil {
+		return nil, err
+	}
 	query := `
 		SELECT
 			m.module_path,
@@ -29,6 +32,8 @@ func (db *DB) GetDirectoryNew(ctx context.Context, path, modulePath, version str
 			m.version_type,
 			m.redistributable,
 			m.has_go_mod,
+			m.has_executables,
+			m.has_large_files,
 			m.source_info,
 			p.id,
 			p.path,
@@ -40,7 +45,7 @@ func (db *DB) GetDirectoryNew(ctx context.Context, path, modulePath, version str
 			d.goos,
 			d.goarch,
 			d.synopsis,
-			d.html
+			` + textOrGzip("d.html") + `
 		FROM modules m
 		INNER JOIN paths p
 		ON p.module_id = m.id
@@ -66,6 +71,8 @@ func (db *DB) GetDirectoryNew(ctx context.Context, path, modulePath, version str
 		&mi.VersionType,
 		&mi.IsRedistributable,
 		&mi.HasGoMod,
+		&mi.HasExecutables,
+		&mi.HasLargeFiles,
 		jsonbScanner{&mi.SourceInfo},
 		&pathID,
 		&dir.Path,
@@ -77,7 +84,7 @@ func (db *DB) GetDirectoryNew(ctx context.Context, path, modulePath, version str
 		database.NullIsEmpty(&doc.GOOS),
 		database.NullIsEmpty(&doc.GOARCH),
 		database.NullIsEmpty(&doc.Synopsis),
-		database.NullIsEmpty(&doc.HTML),
+		decompressed(&doc.HTML),
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("directory %s@%s: %w", path, version, derrors.NotFound)
@@ -108,24 +115,27 @@ func (db *DB) GetDirectoryNew(ctx context.Context, path, modulePath, version str
 		WHERE path_id = $1`, collect, pathID); err != nil {
 			return nil, err
 		}
+		pkg.Imports = db.filterPaths(ctx, pkg.Imports)
 	}
 
-	// TODO(golang/go#38513): remove and query the readmes table directly once
-	// we start displaying READMEs for directories instead of the top-level
-	// module.
+	// The README of a directory is its own, if it has one, or else the
+	// README of its module. Mono-repos often document sub-packages in their
+	// own directory.
 	var readme internal.Readme
 	row = db.db.QueryRow(ctx, `
-		SELECT file_path, contents
+		SELECT file_path, `+textOrGzip("contents")+`
 		FROM modules m
 		INNER JOIN paths p
 		ON p.module_id = m.id
 		INNER JOIN readmes r
 		ON p.id = r.path_id
 		WHERE
-		    module_path=$1
+		    m.module_path=$1
 			AND m.version=$2
-			AND m.module_path=p.path`, modulePath, version)
-	if err := row.Scan(&readme.Filepath, &readme.Contents); err != nil && err != sql.ErrNoRows {
+			AND (p.path=$3 OR p.path=m.module_path)
+		ORDER BY p.path=$3 DESC
+		LIMIT 1`, modulePath, version, path)
+	if err := row.Scan(&readme.Filepath, decompressed(&readme.Contents)); err != nil && err != sql.ErrNoRows {
 		return nil, err
 	}
 	if readme.Filepath != "" {
@@ -207,7 +217,7 @@ func (db *DB) GetDirectory(ctx context.Context, dirPath, modulePath, version str
 			&pkg.V1Path,
 		}
 		if fields&internal.WithDocumentationHTML != 0 {
-			scanArgs = append(scanArgs, database.NullIsEmpty(&pkg.DocumentationHTML))
+			scanArgs = append(scanArgs, decompressed(&pkg.DocumentationHTML))
 		}
 		scanArgs = append(scanArgs,
 			pq.Array(&licenseTypes),
@@ -219,7 +229,7 @@ func (db *DB) GetDirectory(ctx context.Context, dirPath, modulePath, version str
 			&mi.ModulePath,
 			database.NullIsEmpty(&mi.LegacyReadmeFilePath))
 		if fields&internal.WithReadmeContents != 0 {
-			scanArgs = append(scanArgs, database.NullIsEmpty(&mi.LegacyReadmeContents))
+			scanArgs = append(scanArgs, decompressed(&mi.LegacyReadmeContents))
 		}
 		var hasGoMod sql.NullBool
 		scanArgs = append(scanArgs,
@@ -246,6 +256,9 @@ func (db *DB) GetDirectory(ctx context.Context, dirPath, modulePath, version str
 	if len(packages) == 0 {
 		return nil, fmt.Errorf("packages in directory not found: %w", derrors.NotFound)
 	}
+	if err := db.checkAccess(ctx, mi.ModulePath); err != nil {
+		return nil, err
+	}
 	sort.Slice(packages, func(i, j int) bool {
 		return packages[i].Path < packages[j].Path
 	})
@@ -259,10 +272,10 @@ func (db *DB) GetDirectory(ctx context.Context, dirPath, modulePath, version str
 func directoryColumns(fields internal.FieldSet) string {
 	var doc, readme string
 	if fields&internal.WithDocumentationHTML != 0 {
-		doc = "p.documentation,"
+		doc = textOrGzip("p.documentation") + ","
 	}
 	if fields&internal.WithReadmeContents != 0 {
-		readme = "m.readme_contents,"
+		readme = textOrGzip("m.readme_contents") + ","
 	}
 	return `
 			p.path,
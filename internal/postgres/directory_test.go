@@ -433,11 +433,13 @@ func TestGetDirectoryNew(t *testing.T) {
 				// The packages table only includes partial license information; it omits the Coverage field.
 				cmpopts.IgnoreFields(licenses.Metadata{}, "Coverage"),
 			}
-			// TODO(golang/go#38513): remove once we start displaying
-			// READMEs for directories instead of the top-level module.
-			tc.want.Readme = &internal.Readme{
-				Filepath: sample.ReadmeFilePath,
-				Contents: sample.ReadmeContents,
+			// Directories without a README have the README of their
+			// module.
+			if tc.want.Readme == nil {
+				tc.want.Readme = &internal.Readme{
+					Filepath: sample.ReadmeFilePath,
+					Contents: sample.ReadmeContents,
+				}
 			}
 			if diff := cmp.Diff(tc.want, got, opts...); diff != "" {
 				t.Errorf("mismatch (-want, +got):\n%s", diff)
@@ -7,10 +7,15 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal/abuse"
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/database"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/log"
 )
@@ -34,7 +39,8 @@ func (db *DB) IsExcluded(ctx context.Context, path string) (_ bool, err error) {
 	return false, nil
 }
 
-// InsertExcludedPrefix inserts prefix into the excluded_prefixes table.
+// InsertExcludedPrefix inserts prefix into the excluded_prefixes table, and
+// records the exclusion by user in the audit log.
 //
 // For real-time administration (e.g. DOS prevention), use the dbadmin tool.
 // to exclude or unexclude a prefix. If the exclusion is permanent (e.g. a user
@@ -42,8 +48,18 @@ func (db *DB) IsExcluded(ctx context.Context, path string) (_ bool, err error) {
 func (db *DB) InsertExcludedPrefix(ctx context.Context, prefix, user, reason string) (err error) {
 	defer derrors.Wrap(&err, "DB.InsertExcludedPrefix(ctx, %q, %q)", prefix, reason)
 
-	_, err = db.db.Exec(ctx, "INSERT INTO excluded_prefixes (prefix, created_by, reason) VALUES ($1, $2, $3)",
-		prefix, user, reason)
+	err = db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		if err := insertExcludedPrefix(ctx, tx, prefix, user, reason); err != nil {
+			return err
+		}
+		return recordAuditEntry(ctx, tx, &audit.Entry{
+			Actor:  user,
+			Action: abuse.ActionExclude,
+			Target: prefix,
+			Reason: reason,
+			After:  exclusionJSON(user, reason),
+		})
+	})
 	if err != nil {
 		// Arrange to re-read the excluded_prefixes table on the next call to IsExcluded.
 		setExcludedPrefixesLastFetched(time.Time{})
@@ -51,6 +67,55 @@ func (db *DB) InsertExcludedPrefix(ctx context.Context, prefix, user, reason str
 	return err
 }
 
+func insertExcludedPrefix(ctx context.Context, ddb *database.DB, prefix, user, reason string) error {
+	_, err := ddb.Exec(ctx, "INSERT INTO excluded_prefixes (prefix, created_by, reason) VALUES ($1, $2, $3)",
+		prefix, user, reason)
+	return err
+}
+
+// deleteExcludedPrefix deletes prefix from the excluded_prefixes table. It
+// returns an error that wraps derrors.NotFound if prefix is not excluded.
+func deleteExcludedPrefix(ctx context.Context, ddb *database.DB, prefix string) error {
+	res, err := ddb.Exec(ctx, "DELETE FROM excluded_prefixes WHERE prefix = $1", prefix)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", prefix, derrors.NotFound)
+	}
+	return nil
+}
+
+// GetExclusions returns all the excluded prefixes, in order.
+func (db *DB) GetExclusions(ctx context.Context) (_ []*abuse.Exclusion, err error) {
+	defer derrors.Wrap(&err, "DB.GetExclusions(ctx)")
+
+	var exs []*abuse.Exclusion
+	collect := func(rows *sql.Rows) error {
+		var (
+			e         abuse.Exclusion
+			createdAt pq.NullTime
+		)
+		if err := rows.Scan(&e.Prefix, &e.CreatedBy, &e.Reason, &createdAt); err != nil {
+			return err
+		}
+		e.CreatedAt = createdAt.Time
+		exs = append(exs, &e)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, `
+		SELECT prefix, created_by, reason, created_at
+		FROM excluded_prefixes
+		ORDER BY prefix`, collect); err != nil {
+		return nil, err
+	}
+	return exs, nil
+}
+
 // In-memory copy of excluded_prefixes.
 var excludedPrefixes struct {
 	mu          sync.Mutex
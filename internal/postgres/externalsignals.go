@@ -0,0 +1,98 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/signals"
+)
+
+// UpsertExternalSignal stores s as the signal of its provider for the module
+// at modulePath, replacing the one stored before.
+func (db *DB) UpsertExternalSignal(ctx context.Context, modulePath string, s *signals.Signal) (err error) {
+	defer derrors.Wrap(&err, "UpsertExternalSignal(ctx, %q, %q)", modulePath, s.Provider)
+
+	_, err = db.db.Exec(ctx, `
+		INSERT INTO external_signals (module_path, provider, label, url, fetched_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (module_path, provider)
+		DO UPDATE SET
+			label = excluded.label,
+			url = excluded.url,
+			fetched_at = excluded.fetched_at`,
+		modulePath, s.Provider, s.Label, s.URL, s.FetchedAt)
+	return err
+}
+
+// GetExternalSignals returns the signals of the given providers for the
+// module at modulePath, by provider name. Signals with empty labels are
+// left out.
+func (db *DB) GetExternalSignals(ctx context.Context, modulePath string, providers []string) (_ []*signals.Signal, err error) {
+	defer derrors.Wrap(&err, "GetExternalSignals(ctx, %q, %q)", modulePath, providers)
+
+	var sigs []*signals.Signal
+	collect := func(rows *sql.Rows) error {
+		var s signals.Signal
+		if err := rows.Scan(&s.Provider, &s.Label, &s.URL, &s.FetchedAt); err != nil {
+			return err
+		}
+		sigs = append(sigs, &s)
+		return nil
+	}
+	query := `
+		SELECT provider, label, url, fetched_at
+		FROM external_signals
+		WHERE module_path = $1 AND provider = ANY($2) AND label <> ''
+		ORDER BY provider`
+	if err := db.db.RunQuery(ctx, query, collect, modulePath, pq.Array(providers)); err != nil {
+		return nil, err
+	}
+	return sigs, nil
+}
+
+// GetModulesForExternalSignals returns the latest versions of at most limit
+// modules whose signal from provider was never got, or got before
+// staleBefore, the oldest first. Only modules with a known repository are
+// returned, with their SourceInfo.
+func (db *DB) GetModulesForExternalSignals(ctx context.Context, provider string, staleBefore time.Time, limit int) (_ []*internal.ModuleInfo, err error) {
+	defer derrors.Wrap(&err, "GetModulesForExternalSignals(ctx, %q, %s, %d)", provider, staleBefore, limit)
+
+	query := `
+		SELECT m.module_path, m.version, m.commit_time, m.source_info
+		FROM (
+			SELECT DISTINCT ON (module_path)
+				module_path, version, commit_time, source_info
+			FROM modules
+			WHERE source_info IS NOT NULL AND source_info <> 'null'
+			ORDER BY
+				module_path,
+				version_type = 'release' DESC,
+				sort_version DESC
+		) m
+		LEFT JOIN external_signals s
+		ON s.module_path = m.module_path AND s.provider = $1
+		WHERE s.fetched_at IS NULL OR s.fetched_at < $2
+		ORDER BY s.fetched_at NULLS FIRST, m.module_path
+		LIMIT $3`
+	var mis []*internal.ModuleInfo
+	collect := func(rows *sql.Rows) error {
+		var mi internal.ModuleInfo
+		if err := rows.Scan(&mi.ModulePath, &mi.Version, &mi.CommitTime, jsonbScanner{&mi.SourceInfo}); err != nil {
+			return err
+		}
+		mis = append(mis, &mi)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, provider, staleBefore, limit); err != nil {
+		return nil, err
+	}
+	return mis, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/signals"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestExternalSignals(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	for _, m := range []string{"github.com/a/a", "github.com/b/b", "github.com/c/c"} {
+		if err := testDB.InsertModule(ctx, sample.Module(m, sample.VersionString, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now().Round(time.Second)
+	for _, s := range []struct {
+		modulePath string
+		signal     *signals.Signal
+	}{
+		{"github.com/a/a", &signals.Signal{Provider: "github-stars", Label: "12 stars", URL: "https://github.com/a/a/stargazers", FetchedAt: now.Add(-48 * time.Hour)}},
+		{"github.com/a/a", &signals.Signal{Provider: "other", Label: "A+", FetchedAt: now}},
+		{"github.com/b/b", &signals.Signal{Provider: "github-stars", FetchedAt: now}},
+	} {
+		if err := testDB.UpsertExternalSignal(ctx, s.modulePath, s.signal); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := testDB.GetExternalSignals(ctx, "github.com/a/a", []string{"github-stars"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*signals.Signal{{Provider: "github-stars", Label: "12 stars", URL: "https://github.com/a/a/stargazers", FetchedAt: now.Add(-48 * time.Hour)}}
+	if diff := cmp.Diff(want, got, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+		t.Errorf("GetExternalSignals mismatch (-want +got):\n%s", diff)
+	}
+	// Empty labels are not shown.
+	got, err = testDB.GetExternalSignals(ctx, "github.com/b/b", []string{"github-stars"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("GetExternalSignals(github.com/b/b) = %v, want none", got)
+	}
+
+	mis, err := testDB.GetModulesForExternalSignals(ctx, "github-stars", now.Add(-24*time.Hour), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, mi := range mis {
+		paths = append(paths, mi.ModulePath)
+		if mi.SourceInfo.RepoURL() == "" {
+			t.Errorf("%s: no repository URL", mi.ModulePath)
+		}
+	}
+	// Modules never fetched come first, then the stale ones.
+	if diff := cmp.Diff([]string{"github.com/c/c", "github.com/a/a"}, paths); diff != "" {
+		t.Errorf("GetModulesForExternalSignals mismatch (-want +got):\n%s", diff)
+	}
+}
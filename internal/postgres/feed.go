@@ -0,0 +1,117 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// A FeedEntry describes a module version in the feed of processed versions.
+type FeedEntry struct {
+	ModulePath string    `json:"module_path"`
+	Version    string    `json:"version"`
+	CommitTime time.Time `json:"commit_time"`
+	// ProcessedAt is when the version was first processed.
+	ProcessedAt time.Time `json:"processed_at"`
+	// Synopsis is the synopsis of the package at the root of the module,
+	// or of its package with the shortest path if there is none. It is
+	// empty if the module is not redistributable.
+	Synopsis string   `json:"synopsis"`
+	Licenses []string `json:"licenses"` // license types, sorted
+}
+
+// A FeedCursor identifies an entry of the feed, by the time its version was
+// processed and, since many versions may be processed at the same time, by its
+// module path and version.
+type FeedCursor struct {
+	ProcessedAt time.Time
+	ModulePath  string
+	Version     string
+}
+
+// GetFeedEntries returns the module versions processed after the entry
+// identified by after, oldest first, or if after.ProcessedAt is zero, the most
+// recently processed versions, newest first. Entries are ordered by the
+// fields of FeedCursor. At most limit versions are returned. Excluded modules,
+// and modules the user may not see, are omitted.
+func (db *DB) GetFeedEntries(ctx context.Context, after FeedCursor, limit int) (_ []*FeedEntry, err error) {
+	defer derrors.Wrap(&err, "GetFeedEntries(ctx, %+v, %d)", after, limit)
+
+	const queryFormat = `
+		SELECT
+			m.module_path,
+			m.version,
+			m.commit_time,
+			m.created_at,
+			COALESCE((
+				SELECT p.synopsis
+				FROM packages p
+				WHERE p.module_path = m.module_path AND p.version = m.version AND p.redistributable
+				ORDER BY length(p.path), p.path
+				LIMIT 1), ''),
+			ARRAY(
+				SELECT DISTINCT unnest(l.types)
+				FROM licenses l
+				WHERE l.module_path = m.module_path AND l.version = m.version
+				ORDER BY 1)
+		FROM modules m
+		%[1]s
+		ORDER BY m.created_at %[2]s, m.module_path %[2]s, m.version %[2]s
+		LIMIT $1`
+	order, op := "ASC", ">"
+	if after.ProcessedAt.IsZero() {
+		order, op = "DESC", "<"
+	}
+	var (
+		entries []*FeedEntry
+		page    []*FeedEntry
+	)
+	collect := func(rows *sql.Rows) error {
+		var e FeedEntry
+		if err := rows.Scan(&e.ModulePath, &e.Version, &e.CommitTime, &e.ProcessedAt, &e.Synopsis, pq.Array(&e.Licenses)); err != nil {
+			return err
+		}
+		page = append(page, &e)
+		return nil
+	}
+	// Hidden versions can only be filtered out after they are read, so keep
+	// reading pages until there are limit entries or no more versions.
+	cursor := after
+	for {
+		var where string
+		args := []interface{}{limit}
+		if !cursor.ProcessedAt.IsZero() {
+			where = fmt.Sprintf("WHERE (m.created_at, m.module_path, m.version) %s ($2, $3, $4)", op)
+			args = append(args, cursor.ProcessedAt, cursor.ModulePath, cursor.Version)
+		}
+		page = nil
+		if err := db.db.RunQuery(ctx, fmt.Sprintf(queryFormat, where, order), collect, args...); err != nil {
+			return nil, err
+		}
+		for _, e := range page {
+			ex, err := db.IsExcluded(ctx, e.ModulePath)
+			if err != nil {
+				return nil, err
+			}
+			if !ex && db.acl.Allowed(ctx, e.ModulePath) {
+				entries = append(entries, e)
+				if len(entries) == limit {
+					return entries, nil
+				}
+			}
+		}
+		if len(page) < limit {
+			return entries, nil
+		}
+		last := page[len(page)-1]
+		cursor = FeedCursor{ProcessedAt: last.ProcessedAt, ModulePath: last.ModulePath, Version: last.Version}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestGetFeedEntries(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	for _, v := range []string{"v1.0.0", "v1.1.0", "v1.2.0"} {
+		if err := testDB.InsertModule(ctx, sample.Module("example.com/a", v, "", "p")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := testDB.InsertModule(ctx, sample.Module("bad.com/m", "v1.0.0", "p")); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDB.InsertExcludedPrefix(ctx, "bad.com", "admin", "spam"); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := func(version string) *FeedEntry {
+		return &FeedEntry{
+			ModulePath: "example.com/a",
+			Version:    version,
+			CommitTime: sample.CommitTime,
+			Synopsis:   sample.Synopsis,
+			Licenses:   sample.LicenseMetadata[0].Types,
+		}
+	}
+	ignore := cmpopts.IgnoreFields(FeedEntry{}, "ProcessedAt")
+
+	// Newest first. The excluded module is the newest, so a second page is
+	// read to fill the first.
+	got, err := testDB.GetFeedEntries(ctx, FeedCursor{}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*FeedEntry{entry("v1.2.0"), entry("v1.1.0")}
+	if diff := cmp.Diff(want, got, ignore); diff != "" {
+		t.Fatalf("GetFeedEntries(zero) mismatch (-want +got):\n%s", diff)
+	}
+
+	// Oldest first, after the cursor.
+	got, err = testDB.GetFeedEntries(ctx, cursorOf(got[1]), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []*FeedEntry{entry("v1.2.0")}
+	if diff := cmp.Diff(want, got, ignore); diff != "" {
+		t.Errorf("GetFeedEntries(after) mismatch (-want +got):\n%s", diff)
+	}
+
+	// Versions processed at the same time are all followed, one at a time.
+	processed := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := testDB.db.Exec(ctx, `UPDATE modules SET created_at = $1`, processed); err != nil {
+		t.Fatal(err)
+	}
+	var versions []string
+	after := FeedCursor{ProcessedAt: processed.Add(-time.Microsecond)}
+	for {
+		got, err := testDB.GetFeedEntries(ctx, after, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) == 0 {
+			break
+		}
+		versions = append(versions, got[0].Version)
+		after = cursorOf(got[0])
+	}
+	if diff := cmp.Diff([]string{"v1.0.0", "v1.1.0", "v1.2.0"}, versions); diff != "" {
+		t.Errorf("followed versions mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func cursorOf(e *FeedEntry) FeedCursor {
+	return FeedCursor{ProcessedAt: e.ProcessedAt, ModulePath: e.ModulePath, Version: e.Version}
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// insertFiles replaces the rows of the module_files table for m with the
+// files of m. File names and sizes are stored even if m is not
+// redistributable, because they reveal nothing of the files' contents.
+func insertFiles(ctx context.Context, db *database.DB, m *internal.Module) (err error) {
+	defer derrors.Wrap(&err, "insertFiles(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	if _, err := db.Exec(ctx, `DELETE FROM module_files WHERE module_path = $1 AND version = $2`,
+		m.ModulePath, m.Version); err != nil {
+		return err
+	}
+	var values []interface{}
+	for _, f := range m.Files {
+		values = append(values, m.ModulePath, m.Version, makeValidUnicode(f.Path), f.Size, f.Executable)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	cols := []string{"module_path", "version", "path", "size", "executable"}
+	return db.BulkInsert(ctx, "module_files", cols, values, database.OnConflictDoNothing)
+}
+
+// GetModuleFiles returns the files in the zip of the given module version,
+// sorted by path. It returns no files if the files of the module
+// version were not recorded, as for versions fetched before they were.
+func (db *DB) GetModuleFiles(ctx context.Context, modulePath, version string) (_ []*internal.ModuleFile, err error) {
+	defer derrors.Wrap(&err, "GetModuleFiles(ctx, %q, %q)", modulePath, version)
+
+	var files []*internal.ModuleFile
+	collect := func(rows *sql.Rows) error {
+		var f internal.ModuleFile
+		if err := rows.Scan(&f.Path, &f.Size, &f.Executable); err != nil {
+			return err
+		}
+		files = append(files, &f)
+		return nil
+	}
+	query := `
+		SELECT path, size, executable
+		FROM module_files
+		WHERE module_path = $1 AND version = $2
+		ORDER BY path`
+	if err := db.db.RunQuery(ctx, query, collect, modulePath, version); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
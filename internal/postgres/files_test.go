@@ -0,0 +1,58 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestGetModuleFiles(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	m := sample.DefaultModule()
+	m.Files = []*internal.ModuleFile{
+		{Path: "LICENSE", Size: 1075},
+		{Path: "bin/tool", Size: 8 << 20, Executable: true},
+		{Path: "go.mod", Size: 30},
+	}
+	m.HasExecutables = true
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	mi, err := testDB.GetModuleInfo(ctx, m.ModulePath, m.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mi.HasExecutables || mi.HasLargeFiles {
+		t.Errorf("GetModuleInfo: HasExecutables, HasLargeFiles = %t, %t; want true, false", mi.HasExecutables, mi.HasLargeFiles)
+	}
+	got, err := testDB.GetModuleFiles(ctx, m.ModulePath, m.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(m.Files, got); diff != "" {
+		t.Errorf("GetModuleFiles mismatch (-want +got):\n%s", diff)
+	}
+
+	// Reinserting the module replaces its files.
+	m.Files = m.Files[:1]
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	got, err = testDB.GetModuleFiles(ctx, m.ModulePath, m.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(m.Files, got); diff != "" {
+		t.Errorf("GetModuleFiles after reinsert mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -92,6 +92,30 @@ func (db *DB) saveModule(ctx context.Context, m *internal.Module) (err error) {
 		}
 		logMemory(ctx, "after insertPackages")
 
+		if err := insertSymbols(ctx, tx, m); err != nil {
+			return err
+		}
+
+		if err := insertPackageQuality(ctx, tx, m); err != nil {
+			return err
+		}
+
+		if err := insertUsageSnippets(ctx, tx, m); err != nil {
+			return err
+		}
+
+		if err := insertFiles(ctx, tx, m); err != nil {
+			return err
+		}
+
+		if err := insertRequires(ctx, tx, m); err != nil {
+			return err
+		}
+
+		if err := insertReleaseNotes(ctx, tx, m); err != nil {
+			return err
+		}
+
 		if experiment.IsActive(ctx, internal.ExperimentInsertDirectories) {
 			if err := insertDirectories(ctx, tx, m, moduleID); err != nil {
 				return err
@@ -143,15 +167,14 @@ func (db *DB) saveModule(ctx context.Context, m *internal.Module) (err error) {
 		// (github.com/Sirupsen/logrus@v1.1.0 in the example) and then see the valid
 		// one. The "if code == 491" section of internal/worker.fetchAndUpdateState
 		// handles the case where we fetch the versions in the other order.
-		row := tx.QueryRow(ctx, `
-			SELECT 1 FROM module_version_states
-			WHERE module_path = $1 AND sort_version > $2 and status = 491`,
-			m.ModulePath, version.ForSorting(m.Version))
-		var x int
-		if err := row.Scan(&x); err != sql.ErrNoRows {
-			log.Infof(ctx, "%s@%s: not inserting into search documents", m.ModulePath, m.Version)
+		alt, err := hasLaterAlternativeVersion(ctx, tx, m.ModulePath, m.Version)
+		if err != nil {
 			return err
 		}
+		if alt {
+			log.Infof(ctx, "%s@%s: not inserting into search documents", m.ModulePath, m.Version)
+			return nil
+		}
 		// Insert the module's packages into search_documents.
 		return UpsertSearchDocuments(ctx, tx, m)
 	})
@@ -165,6 +188,17 @@ func insertModule(ctx context.Context, db *database.DB, m *internal.Module) (_ i
 	if err != nil {
 		return 0, err
 	}
+	var originJSON []byte
+	if m.Origin != nil {
+		originJSON, err = json.Marshal(m.Origin)
+		if err != nil {
+			return 0, err
+		}
+	}
+	readme, readmeGZ, err := compressText(makeValidUnicode(m.LegacyReadmeContents))
+	if err != nil {
+		return 0, err
+	}
 	var moduleID int
 	err = db.QueryRow(ctx,
 		`INSERT INTO modules(
@@ -173,32 +207,45 @@ func insertModule(ctx context.Context, db *database.DB, m *internal.Module) (_ i
 			commit_time,
 			readme_file_path,
 			readme_contents,
+			readme_contents_gz,
 			sort_version,
 			version_type,
 			series_path,
 			source_info,
 			redistributable,
-			has_go_mod)
-		VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10, $11)
+			has_go_mod,
+			origin,
+			has_executables,
+			has_large_files)
+		VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
 		ON CONFLICT
 			(module_path, version)
 		DO UPDATE SET
 			readme_file_path=excluded.readme_file_path,
 			readme_contents=excluded.readme_contents,
+			readme_contents_gz=excluded.readme_contents_gz,
 			source_info=excluded.source_info,
-			redistributable=excluded.redistributable
+			redistributable=excluded.redistributable,
+			origin=excluded.origin,
+			has_executables=excluded.has_executables,
+			has_large_files=excluded.has_large_files,
+			documentation_pruned_at=NULL
 		RETURNING id`,
 		m.ModulePath,
 		m.Version,
 		m.CommitTime,
 		m.LegacyReadmeFilePath,
-		makeValidUnicode(m.LegacyReadmeContents),
+		readme,
+		readmeGZ,
 		version.ForSorting(m.Version),
 		m.VersionType,
 		m.SeriesPath(),
 		sourceInfoJSON,
 		m.IsRedistributable,
 		m.HasGoMod,
+		originJSON,
+		m.HasExecutables,
+		m.HasLargeFiles,
 	).Scan(&moduleID)
 	if err != nil {
 		return 0, err
@@ -217,7 +264,7 @@ func insertLicenses(ctx context.Context, db *database.DB, m *internal.Module, mo
 			return fmt.Errorf("marshalling %+v: %v", l.Coverage, err)
 		}
 		licenseValues = append(licenseValues, m.ModulePath, m.Version,
-			l.FilePath, makeValidUnicode(string(l.Contents)), pq.Array(l.Types), covJSON, moduleID)
+			l.FilePath, makeValidUnicode(string(l.Contents)), pq.Array(l.Types), string(covJSON), moduleID)
 	}
 	if len(licenseValues) > 0 {
 		licenseCols := []string{
@@ -229,7 +276,7 @@ func insertLicenses(ctx context.Context, db *database.DB, m *internal.Module, mo
 			"coverage",
 			"module_id",
 		}
-		return db.BulkUpsert(ctx, "licenses", licenseCols, licenseValues,
+		return db.CopyUpsert(ctx, "licenses", licenseCols, licenseValues,
 			[]string{"module_path", "version", "file_path"})
 	}
 	return nil
@@ -276,6 +323,10 @@ func insertPackages(ctx context.Context, db *database.DB, m *internal.Module) (e
 				}
 			}
 		}
+		doc, docGZ, err := compressText(makeValidUnicode(p.DocumentationHTML))
+		if err != nil {
+			return err
+		}
 		pkgValues = append(pkgValues,
 			p.Path,
 			p.Synopsis,
@@ -284,7 +335,8 @@ func insertPackages(ctx context.Context, db *database.DB, m *internal.Module) (e
 			m.ModulePath,
 			p.V1Path,
 			p.IsRedistributable,
-			makeValidUnicode(p.DocumentationHTML),
+			doc,
+			docGZ,
 			pq.Array(licenseTypes),
 			pq.Array(licensePaths),
 			p.GOOS,
@@ -306,13 +358,14 @@ func insertPackages(ctx context.Context, db *database.DB, m *internal.Module) (e
 			"v1_path",
 			"redistributable",
 			"documentation",
+			"documentation_gz",
 			"license_types",
 			"license_paths",
 			"goos",
 			"goarch",
 			"commit_time",
 		}
-		if err := db.BulkUpsert(ctx, "packages", pkgCols, pkgValues, uniqueCols); err != nil {
+		if err := db.CopyUpsert(ctx, "packages", pkgCols, pkgValues, uniqueCols); err != nil {
 			return err
 		}
 	}
@@ -324,7 +377,7 @@ func insertPackages(ctx context.Context, db *database.DB, m *internal.Module) (e
 			"from_version",
 			"to_path",
 		}
-		if err := db.BulkUpsert(ctx, "imports", importCols, importValues, importCols); err != nil {
+		if err := db.CopyUpsert(ctx, "imports", importCols, importValues, importCols); err != nil {
 			return err
 		}
 	}
@@ -356,7 +409,7 @@ func insertImportsUnique(ctx context.Context, tx *database.DB, m *internal.Modul
 		return nil
 	}
 	cols := []string{"from_path", "from_module_path", "to_path"}
-	return tx.BulkUpsert(ctx, "imports_unique", cols, values, cols)
+	return tx.CopyUpsert(ctx, "imports_unique", cols, values, cols)
 }
 
 func insertDirectories(ctx context.Context, db *database.DB, m *internal.Module, moduleID int) (err error) {
@@ -476,9 +529,13 @@ func insertDirectories(ctx context.Context, db *database.DB, m *internal.Module,
 				continue
 			}
 			id := pathToID[path]
-			readmeValues = append(readmeValues, id, readme.Filepath, makeValidUnicode(readme.Contents))
+			contents, contentsGZ, err := compressText(makeValidUnicode(readme.Contents))
+			if err != nil {
+				return err
+			}
+			readmeValues = append(readmeValues, id, readme.Filepath, contents, contentsGZ)
 		}
-		readmeCols := []string{"path_id", "file_path", "contents"}
+		readmeCols := []string{"path_id", "file_path", "contents", "contents_gz"}
 		if err := db.BulkUpsert(ctx, "readmes", readmeCols, readmeValues, []string{"path_id"}); err != nil {
 			return err
 		}
@@ -493,10 +550,14 @@ func insertDirectories(ctx context.Context, db *database.DB, m *internal.Module,
 				continue
 			}
 			id := pathToID[path]
-			docValues = append(docValues, id, doc.GOOS, doc.GOARCH, doc.Synopsis, makeValidUnicode(doc.HTML))
+			html, htmlGZ, err := compressText(makeValidUnicode(doc.HTML))
+			if err != nil {
+				return err
+			}
+			docValues = append(docValues, id, doc.GOOS, doc.GOARCH, doc.Synopsis, html, htmlGZ)
 		}
 		uniqueCols := []string{"path_id", "goos", "goarch"}
-		docCols := append(uniqueCols, "synopsis", "html")
+		docCols := append(uniqueCols, "synopsis", "html", "html_gz")
 		if err := db.BulkUpsert(ctx, "documentation", docCols, docValues, uniqueCols); err != nil {
 			return err
 		}
@@ -684,26 +745,50 @@ func removeNonDistributableData(m *internal.Module) {
 	if !m.IsRedistributable {
 		m.LegacyReadmeFilePath = ""
 		m.LegacyReadmeContents = ""
+		m.ReleaseNotes = nil
 	}
 }
 
 // DeleteModule deletes a Version from the database.
+// Packages of the version that were in search_documents are replaced by their
+// latest remaining versions, if any.
 func (db *DB) DeleteModule(ctx context.Context, modulePath, version string) (err error) {
 	defer derrors.Wrap(&err, "DeleteModule(ctx, db, %q, %q)", modulePath, version)
 	return db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		// Remember the packages of this version that are in search_documents,
+		// so that they can be replaced by their next latest versions.
+		var searchPaths []string
+		err := tx.RunQuery(ctx, `
+			SELECT package_path FROM search_documents
+			WHERE module_path = $1 AND version = $2`,
+			func(rows *sql.Rows) error {
+				var p string
+				if err := rows.Scan(&p); err != nil {
+					return err
+				}
+				searchPaths = append(searchPaths, p)
+				return nil
+			}, modulePath, version)
+		if err != nil {
+			return err
+		}
+
 		// We only need to delete from the modules table. Thanks to ON DELETE
 		// CASCADE constraints, that will trigger deletions from all other tables.
 		const stmt = `DELETE FROM modules WHERE module_path=$1 AND version=$2`
-		if _, err := db.db.Exec(ctx, stmt, modulePath, version); err != nil {
+		if _, err := tx.Exec(ctx, stmt, modulePath, version); err != nil {
+			return err
+		}
+		if err := upsertSearchDocumentsForPaths(ctx, tx, searchPaths); err != nil {
 			return err
 		}
 		var x int
-		err = db.db.QueryRow(ctx, `SELECT 1 FROM modules WHERE module_path=$1 LIMIT 1`, modulePath).Scan(&x)
+		err = tx.QueryRow(ctx, `SELECT 1 FROM modules WHERE module_path=$1 LIMIT 1`, modulePath).Scan(&x)
 		if err != sql.ErrNoRows || err == nil {
 			return err
 		}
 		// No versions of this module exist; remove it from imports_unique.
-		_, err = db.db.Exec(ctx, `DELETE FROM imports_unique WHERE from_module_path = $1`, modulePath)
+		_, err = tx.Exec(ctx, `DELETE FROM imports_unique WHERE from_module_path = $1`, modulePath)
 		return err
 	})
 }
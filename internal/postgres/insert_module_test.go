@@ -53,6 +53,19 @@ func TestInsertModule(t *testing.T) {
 				return m
 			}(),
 		},
+		{
+			name: "valid test with origin",
+			module: func() *internal.Module {
+				m := sample.DefaultModule()
+				m.Origin = &internal.Origin{
+					VCS:  "git",
+					URL:  "https://github.com/valid/module_name",
+					Hash: "2af7b9a2ba5a7bc8be3d7c81b6c0eb9a18e4d8d7",
+					Ref:  "refs/tags/" + sample.VersionString,
+				}
+				return m
+			}(),
+		},
 		{
 			name: "stdlib",
 			module: func() *internal.Module {
@@ -94,7 +107,7 @@ func checkModule(ctx context.Context, t *testing.T, want *internal.Module) {
 	}
 
 	for _, wantp := range want.LegacyPackages {
-		got, err := testDB.GetPackage(ctx, wantp.Path, want.ModulePath, want.Version)
+		got, err := testDB.GetPackage(ctx, wantp.Path, want.ModulePath, want.Version, internal.AllFields)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -0,0 +1,52 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// VersionLicenses is the set of license types of a module version.
+type VersionLicenses struct {
+	Version string
+	Types   []string // sorted
+}
+
+// GetLicenseHistory returns the license types of every version of
+// modulePath, in descending semver order. Only licenses at the root of the
+// module count: they are the ones that apply to the module as a whole.
+func (db *DB) GetLicenseHistory(ctx context.Context, modulePath string) (_ []*VersionLicenses, err error) {
+	defer derrors.Wrap(&err, "GetLicenseHistory(ctx, %q)", modulePath)
+
+	var history []*VersionLicenses
+	collect := func(rows *sql.Rows) error {
+		var vl VersionLicenses
+		if err := rows.Scan(&vl.Version, pq.Array(&vl.Types)); err != nil {
+			return err
+		}
+		history = append(history, &vl)
+		return nil
+	}
+	query := `
+		SELECT
+			m.version,
+			ARRAY(
+				SELECT DISTINCT unnest(l.types)
+				FROM licenses l
+				WHERE l.module_path = m.module_path AND l.version = m.version
+				AND position('/' in l.file_path) = 0
+				ORDER BY 1)
+		FROM modules m
+		WHERE m.module_path = $1
+		ORDER BY m.sort_version DESC`
+	if err := db.db.RunQuery(ctx, query, collect, modulePath); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestGetLicenseHistory(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	const modulePath = "example.com/m"
+	license := func(filePath string, types ...string) *licenses.License {
+		return &licenses.License{
+			Metadata: &licenses.Metadata{Types: types, FilePath: filePath},
+			Contents: []byte("Lorem Ipsum"),
+		}
+	}
+	for _, m := range []struct {
+		version  string
+		licenses []*licenses.License
+	}{
+		{"v1.0.0", []*licenses.License{license("LICENSE", "MIT")}},
+		// Licenses of subdirectories are ignored.
+		{"v1.1.0", []*licenses.License{license("LICENSE", "MIT"), license("third_party/LICENSE", "BSD-3-Clause")}},
+		{"v1.2.0", []*licenses.License{license("LICENSE", "BUSL-1.1"), license("COPYING", "MIT", "Apache-2.0")}},
+		{"v1.10.0", nil},
+	} {
+		mod := sample.Module(modulePath, m.version, "p")
+		mod.Licenses = m.licenses
+		if err := testDB.InsertModule(ctx, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := testDB.GetLicenseHistory(ctx, modulePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*VersionLicenses{
+		{Version: "v1.10.0"},
+		{Version: "v1.2.0", Types: []string{"Apache-2.0", "BUSL-1.1", "MIT"}},
+		{Version: "v1.1.0", Types: []string{"MIT"}},
+		{Version: "v1.0.0", Types: []string{"MIT"}},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("GetLicenseHistory mismatch (-want +got):\n%s", diff)
+	}
+}
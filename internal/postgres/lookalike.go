@@ -0,0 +1,156 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/lookalike"
+)
+
+// numPopularModules is the number of most imported modules that new module
+// paths are compared against by FlagLookalikes.
+const numPopularModules = 1000
+
+// FlagLookalikes compares the paths of modules first inserted after since
+// with the paths of the most imported modules, and records those that look
+// confusingly like one of them for review. Module paths that were already
+// flagged keep their review status. It returns the newly flagged module paths.
+func (db *DB) FlagLookalikes(ctx context.Context, since time.Time) (flagged []string, err error) {
+	defer derrors.Wrap(&err, "FlagLookalikes(ctx, %s)", since)
+
+	popular, err := collectStrings(ctx, db.db, `
+		SELECT module_path
+		FROM search_documents
+		GROUP BY module_path
+		ORDER BY max(imported_by_count) DESC
+		LIMIT $1`, numPopularModules)
+	if err != nil {
+		return nil, err
+	}
+	newPaths, err := collectStrings(ctx, db.db, `
+		SELECT DISTINCT m.module_path
+		FROM modules m
+		WHERE m.created_at > $1
+		AND NOT EXISTS (
+			SELECT 1 FROM modules o
+			WHERE o.module_path = m.module_path AND o.created_at <= $1)`, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range newPaths {
+		similarTo, reason := lookalike.Find(p, popular)
+		if similarTo == "" {
+			continue
+		}
+		res, err := db.db.Exec(ctx, `
+			INSERT INTO lookalikes (module_path, similar_to, reason)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (module_path) DO NOTHING`,
+			p, similarTo, reason)
+		if err != nil {
+			return flagged, err
+		}
+		if c, err := res.RowsAffected(); err == nil && c > 0 {
+			flagged = append(flagged, p)
+		}
+	}
+	return flagged, nil
+}
+
+// GetLookalike returns the flag recorded for modulePath by FlagLookalikes. It
+// returns an error that wraps derrors.NotFound if there is none.
+func (db *DB) GetLookalike(ctx context.Context, modulePath string) (_ *lookalike.Flag, err error) {
+	defer derrors.Wrap(&err, "GetLookalike(ctx, %q)", modulePath)
+
+	f, err := scanLookalike(db.db.QueryRow(ctx, `
+		SELECT module_path, similar_to, reason, status, created_at, reviewed_at
+		FROM lookalikes
+		WHERE module_path = $1`, modulePath).Scan)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s: %w", modulePath, derrors.NotFound)
+	}
+	return f, err
+}
+
+// GetLookalikes returns the flags with the given status, oldest first.
+func (db *DB) GetLookalikes(ctx context.Context, status string) (_ []*lookalike.Flag, err error) {
+	defer derrors.Wrap(&err, "GetLookalikes(ctx, %q)", status)
+
+	var flags []*lookalike.Flag
+	collect := func(rows *sql.Rows) error {
+		f, err := scanLookalike(rows.Scan)
+		if err != nil {
+			return err
+		}
+		flags = append(flags, f)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, `
+		SELECT module_path, similar_to, reason, status, created_at, reviewed_at
+		FROM lookalikes
+		WHERE status = $1
+		ORDER BY created_at, module_path`, collect, status); err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+// reviewLookalike sets the status of the flag for modulePath to
+// lookalike.StatusConfirmed or lookalike.StatusDismissed. Administrators
+// review flags with Moderate, which records the review in the audit log.
+func reviewLookalike(ctx context.Context, ddb *database.DB, modulePath, status string) error {
+	if status != lookalike.StatusConfirmed && status != lookalike.StatusDismissed {
+		return fmt.Errorf("status must be %q or %q: %w", lookalike.StatusConfirmed, lookalike.StatusDismissed, derrors.InvalidArgument)
+	}
+	res, err := ddb.Exec(ctx, `
+		UPDATE lookalikes
+		SET status = $2, reviewed_at = CURRENT_TIMESTAMP
+		WHERE module_path = $1`, modulePath, status)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", modulePath, derrors.NotFound)
+	}
+	return nil
+}
+
+func scanLookalike(scan func(dest ...interface{}) error) (*lookalike.Flag, error) {
+	var (
+		f          lookalike.Flag
+		reviewedAt pq.NullTime
+	)
+	if err := scan(&f.ModulePath, &f.SimilarTo, &f.Reason, &f.Status, &f.CreatedAt, &reviewedAt); err != nil {
+		return nil, err
+	}
+	f.ReviewedAt = reviewedAt.Time
+	return &f, nil
+}
+
+// collectStrings returns the values of the single text column of the rows
+// returned by query.
+func collectStrings(ctx context.Context, db *database.DB, query string, args ...interface{}) ([]string, error) {
+	var ss []string
+	err := db.RunQuery(ctx, query, func(rows *sql.Rows) error {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return err
+		}
+		ss = append(ss, s)
+		return nil
+	}, args...)
+	return ss, err
+}
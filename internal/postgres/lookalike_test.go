@@ -0,0 +1,92 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/abuse"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/lookalike"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestLookalikes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	for _, m := range []*internal.Module{
+		sample.Module("github.com/sirupsen/logrus", sample.VersionString, "hooks"),
+		sample.Module("github.com/slrupsen/logrus", sample.VersionString, "hooks"),
+		sample.Module("github.com/sirupsen/other", sample.VersionString, "pkg"),
+	} {
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	flagged, err := testDB.FlagLookalikes(ctx, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"github.com/slrupsen/logrus"}, flagged); diff != "" {
+		t.Errorf("FlagLookalikes mismatch (-want +got):\n%s", diff)
+	}
+
+	want := &lookalike.Flag{
+		ModulePath: "github.com/slrupsen/logrus",
+		SimilarTo:  "github.com/sirupsen/logrus",
+		Reason:     lookalike.ReasonHomoglyph,
+		Status:     lookalike.StatusPending,
+	}
+	ignoreTimes := cmpopts.IgnoreFields(lookalike.Flag{}, "CreatedAt", "ReviewedAt")
+	got, err := testDB.GetLookalike(ctx, want.ModulePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got, ignoreTimes); diff != "" {
+		t.Errorf("GetLookalike mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := testDB.GetLookalike(ctx, "github.com/sirupsen/logrus"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("GetLookalike(popular module): got error %v, want NotFound", err)
+	}
+	pending, err := testDB.GetLookalikes(ctx, lookalike.StatusPending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*lookalike.Flag{want}, pending, ignoreTimes); diff != "" {
+		t.Errorf("GetLookalikes(pending) mismatch (-want +got):\n%s", diff)
+	}
+
+	a := &abuse.Action{Actor: "admin@example.com", Kind: abuse.ActionDismissLookalike, Target: want.ModulePath}
+	if err := testDB.Moderate(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	// Flagging again does not reset the review.
+	if _, err := testDB.FlagLookalikes(ctx, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err = testDB.GetLookalike(ctx, want.ModulePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != lookalike.StatusDismissed || got.ReviewedAt.IsZero() {
+		t.Errorf("after review: status = %q, reviewed at %v; want %q and a time", got.Status, got.ReviewedAt, lookalike.StatusDismissed)
+	}
+	unknown := &abuse.Action{Actor: "admin@example.com", Kind: abuse.ActionDismissLookalike, Target: "github.com/no/such"}
+	if err := testDB.Moderate(ctx, unknown); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("Moderate(unknown module): got error %v, want NotFound", err)
+	}
+	bogus := &abuse.Action{Actor: "admin@example.com", Kind: "bogus-lookalike", Target: want.ModulePath}
+	if err := testDB.Moderate(ctx, bogus); !errors.Is(err, derrors.InvalidArgument) {
+		t.Errorf("Moderate(bogus kind): got error %v, want InvalidArgument", err)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// A PrefixModule describes the latest version of a module under a path
+// prefix.
+type PrefixModule struct {
+	ModulePath string
+	Version    string
+	CommitTime time.Time
+	// Synopsis is the synopsis of the package at the root of the module, or
+	// of its package with the shortest path if there is none.
+	Synopsis string
+}
+
+// GetModulesByPrefix returns the modules in search whose paths start with
+// prefix, in the order of their paths, skipping the first offset of them and
+// returning at most limit. It also returns the number of such modules.
+// Excluded modules, and modules the user may not see, are omitted from both
+// the modules and the count.
+func (db *DB) GetModulesByPrefix(ctx context.Context, prefix string, limit, offset int) (_ []*PrefixModule, total int, err error) {
+	defer derrors.Wrap(&err, "GetModulesByPrefix(ctx, %q, %d, %d)", prefix, limit, offset)
+
+	var mods []*PrefixModule
+	collect := func(rows *sql.Rows) error {
+		var m PrefixModule
+		if err := rows.Scan(&m.ModulePath, &m.Version, &m.CommitTime, &m.Synopsis); err != nil {
+			return err
+		}
+		mods = append(mods, &m)
+		return nil
+	}
+	// The modules are paginated after filtering, so that the count and the
+	// pages only include modules the user may see.
+	query := `
+		SELECT DISTINCT ON (module_path)
+			module_path, version, commit_time, COALESCE(synopsis, '')
+		FROM search_documents
+		WHERE starts_with(module_path, $1)
+		ORDER BY module_path, length(package_path), package_path`
+	if err := db.db.RunQuery(ctx, query, collect, prefix); err != nil {
+		return nil, 0, err
+	}
+	var filtered []*PrefixModule
+	for _, m := range mods {
+		ex, err := db.IsExcluded(ctx, m.ModulePath)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !ex && db.acl.Allowed(ctx, m.ModulePath) {
+			filtered = append(filtered, m)
+		}
+	}
+	total = len(filtered)
+	if offset >= total {
+		return nil, total, nil
+	}
+	filtered = filtered[offset:]
+	if len(filtered) > limit {
+		filtered = filtered[:limit]
+	}
+	return filtered, total, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestGetModulesByPrefix(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	for _, m := range []struct {
+		path, version string
+		suffixes      []string
+	}{
+		{"github.com/org/a", "v1.0.0", []string{"", "sub"}},
+		{"github.com/org/a", "v1.1.0", []string{"", "sub"}},
+		{"github.com/org/b", "v0.2.0", []string{"pkg"}},
+		{"github.com/org/c", "v1.0.0", []string{""}},
+		{"github.com/organization/d", "v1.0.0", []string{""}},
+		{"github.com/org/bad", "v1.0.0", []string{""}},
+	} {
+		if err := testDB.InsertModule(ctx, sample.Module(m.path, m.version, m.suffixes...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := testDB.InsertExcludedPrefix(ctx, "github.com/org/bad", "admin", "spam"); err != nil {
+		t.Fatal(err)
+	}
+
+	module := func(path, version string) *PrefixModule {
+		return &PrefixModule{ModulePath: path, Version: version, CommitTime: sample.CommitTime, Synopsis: sample.Synopsis}
+	}
+	for _, test := range []struct {
+		limit, offset int
+		want          []*PrefixModule
+	}{
+		{10, 0, []*PrefixModule{module("github.com/org/a", "v1.1.0"), module("github.com/org/b", "v0.2.0"), module("github.com/org/c", "v1.0.0")}},
+		{2, 2, []*PrefixModule{module("github.com/org/c", "v1.0.0")}},
+		{2, 10, nil},
+	} {
+		got, total, err := testDB.GetModulesByPrefix(ctx, "github.com/org/", test.limit, test.offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The excluded module is not counted.
+		if total != 3 {
+			t.Errorf("limit %d, offset %d: got total %d, want 3", test.limit, test.offset, total)
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("limit %d, offset %d: mismatch (-want +got):\n%s", test.limit, test.offset, diff)
+		}
+	}
+}
+
+func TestGetModulesByPrefixACL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	for _, m := range []string{"github.com/org/a", "github.com/org/secret", "github.com/hidden/x"} {
+		if err := testDB.InsertModule(ctx, sample.Module(m, sample.VersionString, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := New(testDB.Underlying())
+	db.SetACL(auth.NewACL(map[string][]string{
+		"github.com/org/secret": {"eng"},
+		"github.com/hidden":     {"eng"},
+	}))
+	eng := auth.NewContextWithUser(ctx, &auth.User{Email: "e@corp.com", Groups: []string{"eng"}})
+
+	for _, test := range []struct {
+		name      string
+		ctx       context.Context
+		prefix    string
+		wantTotal int
+		wantPaths []string
+	}{
+		{"anonymous", ctx, "github.com/org/", 1, []string{"github.com/org/a"}},
+		{"member of eng", eng, "github.com/org/", 2, []string{"github.com/org/a", "github.com/org/secret"}},
+		// A prefix with only hidden modules has none.
+		{"anonymous hidden", ctx, "github.com/hidden/", 0, nil},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, total, err := db.GetModulesByPrefix(test.ctx, test.prefix, 10, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != test.wantTotal {
+				t.Errorf("got total %d, want %d", total, test.wantTotal)
+			}
+			var paths []string
+			for _, m := range got {
+				paths = append(paths, m.ModulePath)
+			}
+			if diff := cmp.Diff(test.wantPaths, paths); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
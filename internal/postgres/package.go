@@ -34,6 +34,12 @@ import (
 //     module_path = "github.com/hashicorp/vault/api"
 // The latter will be returned.
 //
+// fields is a set of fields to read (see internal.FieldSet and related
+// definitions). If a field is not in fields, it will not be read from the DB
+// and its value will be one of the XXXFieldMissing constants. Pages that do
+// not show documentation or a README should omit them, because they are the
+// largest columns of the packages and modules tables.
+//
 // The returned error may be checked with
 // errors.Is(err, derrors.InvalidArgument) to determine if it was caused by an
 // invalid pkgPath, modulePath or version.
@@ -41,12 +47,19 @@ import (
 // The returned error may be checked with
 // errors.Is(err, derrors.InvalidArgument) to determine if it was caused by an
 // invalid path or version.
-func (db *DB) GetPackage(ctx context.Context, pkgPath, modulePath, version string) (_ *internal.LegacyVersionedPackage, err error) {
+func (db *DB) GetPackage(ctx context.Context, pkgPath, modulePath, version string, fields internal.FieldSet) (_ *internal.LegacyVersionedPackage, err error) {
 	defer derrors.Wrap(&err, "DB.GetPackage(ctx, %q, %q)", pkgPath, version)
 	if pkgPath == "" || modulePath == "" || version == "" {
 		return nil, fmt.Errorf("none of pkgPath, modulePath, or version can be empty: %w", derrors.InvalidArgument)
 	}
 
+	var doc, readme string
+	if fields&internal.WithDocumentationHTML != 0 {
+		doc = textOrGzip("p.documentation") + ","
+	}
+	if fields&internal.WithReadmeContents != 0 {
+		readme = textOrGzip("m.readme_contents") + ","
+	}
 	args := []interface{}{pkgPath}
 	query := `
 		SELECT
@@ -57,18 +70,20 @@ func (db *DB) GetPackage(ctx context.Context, pkgPath, modulePath, version strin
 			p.license_types,
 			p.license_paths,
 			p.redistributable,
-			p.documentation,
+			` + doc + `
 			p.goos,
 			p.goarch,
 			m.version,
 			m.commit_time,
 			m.readme_file_path,
-			m.readme_contents,
+			` + readme + `
 			m.module_path,
 			m.version_type,
 		    m.source_info,
 			m.redistributable,
-			m.has_go_mod
+			m.has_go_mod,
+			m.has_executables,
+			m.has_large_files
 		FROM
 			modules m
 		INNER JOIN
@@ -136,13 +151,22 @@ func (db *DB) GetPackage(ctx context.Context, pkgPath, modulePath, version strin
 		licenseTypes, licensePaths []string
 		hasGoMod                   sql.NullBool
 	)
+	pkg.DocumentationHTML = internal.StringFieldMissing
+	pkg.LegacyReadmeContents = internal.StringFieldMissing
+	scanArgs := []interface{}{&pkg.Path, &pkg.Name, &pkg.Synopsis,
+		&pkg.V1Path, pq.Array(&licenseTypes), pq.Array(&licensePaths), &pkg.LegacyPackage.IsRedistributable}
+	if fields&internal.WithDocumentationHTML != 0 {
+		scanArgs = append(scanArgs, decompressed(&pkg.DocumentationHTML))
+	}
+	scanArgs = append(scanArgs, &pkg.GOOS, &pkg.GOARCH, &pkg.Version,
+		&pkg.CommitTime, database.NullIsEmpty(&pkg.LegacyReadmeFilePath))
+	if fields&internal.WithReadmeContents != 0 {
+		scanArgs = append(scanArgs, decompressed(&pkg.LegacyReadmeContents))
+	}
+	scanArgs = append(scanArgs, &pkg.ModulePath, &pkg.VersionType, jsonbScanner{&pkg.SourceInfo},
+		&pkg.LegacyModuleInfo.IsRedistributable, &hasGoMod, &pkg.HasExecutables, &pkg.HasLargeFiles)
 	row := db.db.QueryRow(ctx, query, args...)
-	err = row.Scan(&pkg.Path, &pkg.Name, &pkg.Synopsis,
-		&pkg.V1Path, pq.Array(&licenseTypes), pq.Array(&licensePaths), &pkg.LegacyPackage.IsRedistributable,
-		database.NullIsEmpty(&pkg.DocumentationHTML), &pkg.GOOS, &pkg.GOARCH, &pkg.Version,
-		&pkg.CommitTime, database.NullIsEmpty(&pkg.LegacyReadmeFilePath), database.NullIsEmpty(&pkg.LegacyReadmeContents),
-		&pkg.ModulePath, &pkg.VersionType, jsonbScanner{&pkg.SourceInfo}, &pkg.LegacyModuleInfo.IsRedistributable,
-		&hasGoMod)
+	err = row.Scan(scanArgs...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("package %s@%s: %w", pkgPath, version, derrors.NotFound)
@@ -150,6 +174,9 @@ func (db *DB) GetPackage(ctx context.Context, pkgPath, modulePath, version strin
 		return nil, fmt.Errorf("row.Scan(): %v", err)
 	}
 	setHasGoMod(&pkg.ModuleInfo, hasGoMod)
+	if err := db.checkAccess(ctx, pkg.ModulePath); err != nil {
+		return nil, err
+	}
 	lics, err := zipLicenseMetadata(licenseTypes, licensePaths)
 	if err != nil {
 		return nil, err
@@ -188,7 +188,7 @@ func TestGetPackage(t *testing.T) {
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			got, err := testDB.GetPackage(ctx, tc.pkgPath, tc.modulePath, tc.version)
+			got, err := testDB.GetPackage(ctx, tc.pkgPath, tc.modulePath, tc.version, internal.AllFields)
 			if tc.wantNotFoundErr {
 				if !errors.Is(err, derrors.NotFound) {
 					t.Fatalf("want derrors.NotFound; got = %v", err)
@@ -227,10 +227,51 @@ func TestGetPackageInvalidArguments(t *testing.T) {
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			got, err := testDB.GetPackage(ctx, tc.modulePath+"/package", tc.modulePath, tc.version)
+			got, err := testDB.GetPackage(ctx, tc.modulePath+"/package", tc.modulePath, tc.version, internal.AllFields)
 			if !errors.Is(err, derrors.InvalidArgument) {
 				t.Fatalf("want %v; got = \n%+v, %v", derrors.InvalidArgument, got, err)
 			}
 		})
 	}
 }
+
+func TestGetPackageFields(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	defer ResetTestDB(testDB, t)
+
+	m := sample.Module(sample.ModulePath, sample.VersionString, "")
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	pkgPath := m.LegacyPackages[0].Path
+
+	for _, test := range []struct {
+		name       string
+		fields     internal.FieldSet
+		wantDoc    string
+		wantReadme string
+	}{
+		{"minimal", internal.MinimalFields, internal.StringFieldMissing, internal.StringFieldMissing},
+		{"doc", internal.WithDocumentationHTML, sample.DocumentationHTML, internal.StringFieldMissing},
+		{"readme", internal.WithReadmeContents, internal.StringFieldMissing, sample.ReadmeContents},
+		{"all", internal.AllFields, sample.DocumentationHTML, sample.ReadmeContents},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := testDB.GetPackage(ctx, pkgPath, sample.ModulePath, sample.VersionString, test.fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.DocumentationHTML != test.wantDoc {
+				t.Errorf("DocumentationHTML = %q, want %q", got.DocumentationHTML, test.wantDoc)
+			}
+			if got.LegacyReadmeContents != test.wantReadme {
+				t.Errorf("LegacyReadmeContents = %q, want %q", got.LegacyReadmeContents, test.wantReadme)
+			}
+			if got.Path != pkgPath || got.Version != sample.VersionString {
+				t.Errorf("got %s@%s, want %s@%s", got.Path, got.Version, pkgPath, sample.VersionString)
+			}
+		})
+	}
+}
@@ -55,6 +55,9 @@ func (db *DB) GetPathInfo(ctx context.Context, path, inModulePath, inVersion str
 	case sql.ErrNoRows:
 		return "", "", false, derrors.NotFound
 	case nil:
+		if err := db.checkAccess(ctx, outModulePath); err != nil {
+			return "", "", false, err
+		}
 		return outModulePath, outVersion, isPackage, nil
 	default:
 		return "", "", false, err
@@ -5,16 +5,18 @@
 package postgres
 
 import (
+	"golang.org/x/pkgsite/internal/auth"
 	"golang.org/x/pkgsite/internal/database"
 )
 
 type DB struct {
-	db *database.DB
+	db  *database.DB
+	acl *auth.ACL // see SetACL
 }
 
 // New returns a new postgres DB.
 func New(db *database.DB) *DB {
-	return &DB{db}
+	return &DB{db: db}
 }
 
 // Close closes a DB.
@@ -0,0 +1,60 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// insertPackageQuality replaces the quality signals of the packages of m
+// with their Quality fields. Packages whose Quality is nil have none.
+func insertPackageQuality(ctx context.Context, db *database.DB, m *internal.Module) (err error) {
+	defer derrors.Wrap(&err, "insertPackageQuality(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	if _, err := db.Exec(ctx, `DELETE FROM package_quality WHERE module_path = $1 AND version = $2`,
+		m.ModulePath, m.Version); err != nil {
+		return err
+	}
+	var values []interface{}
+	for _, p := range m.LegacyPackages {
+		if q := p.Quality; q != nil {
+			values = append(values, p.Path, m.ModulePath, m.Version,
+				q.HasTests, q.HasExamples, q.NumExported, q.NumDocumented)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	cols := []string{"path", "module_path", "version", "has_tests", "has_examples", "num_exported", "num_documented"}
+	return db.BulkInsert(ctx, "package_quality", cols, values, database.OnConflictDoNothing)
+}
+
+// GetPackageQuality returns the quality signals of the package at pkgPath in
+// modulePath@version. It returns an error that wraps derrors.NotFound if
+// there are none.
+func (db *DB) GetPackageQuality(ctx context.Context, pkgPath, modulePath, version string) (_ *internal.PackageQuality, err error) {
+	defer derrors.Wrap(&err, "GetPackageQuality(ctx, %q, %q, %q)", pkgPath, modulePath, version)
+
+	var q internal.PackageQuality
+	err = db.db.QueryRow(ctx, `
+		SELECT has_tests, has_examples, num_exported, num_documented
+		FROM package_quality
+		WHERE path = $1 AND module_path = $2 AND version = $3`,
+		pkgPath, modulePath, version).Scan(&q.HasTests, &q.HasExamples, &q.NumExported, &q.NumDocumented)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s in %s@%s: %w", pkgPath, modulePath, version, derrors.NotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
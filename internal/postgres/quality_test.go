@@ -0,0 +1,40 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestPackageQuality(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	m := sample.Module("example.com/m", sample.VersionString, "a", "b")
+	want := &internal.PackageQuality{HasTests: true, NumExported: 10, NumDocumented: 7}
+	m.LegacyPackages[0].Quality = want
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := testDB.GetPackageQuality(ctx, m.LegacyPackages[0].Path, m.ModulePath, m.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := testDB.GetPackageQuality(ctx, m.LegacyPackages[1].Path, m.ModulePath, m.Version); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("package without quality signals: got %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// insertReleaseNotes replaces the release notes of m with m.ReleaseNotes.
+func insertReleaseNotes(ctx context.Context, db *database.DB, m *internal.Module) (err error) {
+	defer derrors.Wrap(&err, "insertReleaseNotes(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	if _, err := db.Exec(ctx, `DELETE FROM release_notes WHERE module_path = $1 AND version = $2`,
+		m.ModulePath, m.Version); err != nil {
+		return err
+	}
+	if m.ReleaseNotes == nil {
+		return nil
+	}
+	_, err = db.Exec(ctx, `
+		INSERT INTO release_notes (module_path, version, file_path, contents)
+		VALUES ($1, $2, $3, $4)`,
+		m.ModulePath, m.Version, m.ReleaseNotes.Filepath, makeValidUnicode(m.ReleaseNotes.Contents))
+	return err
+}
+
+// GetReleaseNotes returns the release notes of the given module version. It
+// returns an error that wraps derrors.NotFound if none were found.
+func (db *DB) GetReleaseNotes(ctx context.Context, modulePath, version string) (_ *internal.Readme, err error) {
+	defer derrors.Wrap(&err, "GetReleaseNotes(ctx, %q, %q)", modulePath, version)
+
+	var rn internal.Readme
+	err = db.db.QueryRow(ctx, `
+		SELECT file_path, contents
+		FROM release_notes
+		WHERE module_path = $1 AND version = $2`,
+		modulePath, version).Scan(&rn.Filepath, &rn.Contents)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s@%s: %w", modulePath, version, derrors.NotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rn, nil
+}
+
+// releaseNotesPrefixLength is the number of characters of release notes
+// returned by GetReleaseNotesForModule.
+const releaseNotesPrefixLength = 1000
+
+// GetReleaseNotesForModule returns the release notes of the redistributable
+// versions of modulePath, by version. Only the first 1000 characters of
+// the notes are returned, which is enough for an excerpt.
+func (db *DB) GetReleaseNotesForModule(ctx context.Context, modulePath string) (_ map[string]*internal.Readme, err error) {
+	defer derrors.Wrap(&err, "GetReleaseNotesForModule(ctx, %q)", modulePath)
+
+	notes := map[string]*internal.Readme{}
+	collect := func(rows *sql.Rows) error {
+		var (
+			version string
+			rn      internal.Readme
+		)
+		if err := rows.Scan(&version, &rn.Filepath, &rn.Contents); err != nil {
+			return err
+		}
+		notes[version] = &rn
+		return nil
+	}
+	query := `
+		SELECT r.version, r.file_path, left(r.contents, $2)
+		FROM release_notes r
+		INNER JOIN modules m
+		ON m.module_path = r.module_path AND m.version = r.version
+		WHERE r.module_path = $1 AND m.redistributable`
+	if err := db.db.RunQuery(ctx, query, collect, modulePath, releaseNotesPrefixLength); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
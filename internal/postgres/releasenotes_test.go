@@ -0,0 +1,66 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestReleaseNotes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	const modulePath = "example.com/m"
+	long := strings.Repeat("x", 2*releaseNotesPrefixLength)
+	for _, m := range []struct {
+		version         string
+		notes           *internal.Readme
+		redistributable bool
+	}{
+		{"v1.0.0", &internal.Readme{Filepath: "CHANGELOG.md", Contents: "- First.\n"}, true},
+		{"v1.1.0", &internal.Readme{Filepath: "NEWS", Contents: long}, true},
+		{"v1.2.0", &internal.Readme{Filepath: "CHANGELOG.md", Contents: "- Hidden.\n"}, false},
+		{"v1.3.0", nil, true},
+	} {
+		mod := sample.Module(modulePath, m.version, "p")
+		mod.ReleaseNotes = m.notes
+		mod.IsRedistributable = m.redistributable
+		if err := testDB.InsertModule(ctx, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := testDB.GetReleaseNotes(ctx, modulePath, "v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&internal.Readme{Filepath: "NEWS", Contents: long}, got); diff != "" {
+		t.Errorf("GetReleaseNotes mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := testDB.GetReleaseNotes(ctx, modulePath, "v1.3.0"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("GetReleaseNotes of a version without notes: got %v, want NotFound", err)
+	}
+
+	notes, err := testDB.GetReleaseNotesForModule(ctx, modulePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]*internal.Readme{
+		"v1.0.0": {Filepath: "CHANGELOG.md", Contents: "- First.\n"},
+		"v1.1.0": {Filepath: "NEWS", Contents: long[:releaseNotesPrefixLength]},
+	}
+	if diff := cmp.Diff(want, notes); diff != "" {
+		t.Errorf("GetReleaseNotesForModule mismatch (-want +got):\n%s", diff)
+	}
+}
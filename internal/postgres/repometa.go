@@ -0,0 +1,109 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/repometa"
+)
+
+// UpsertRepositoryMetadata stores md, replacing the metadata stored before
+// for its repository.
+func (db *DB) UpsertRepositoryMetadata(ctx context.Context, md *repometa.Metadata) (err error) {
+	defer derrors.Wrap(&err, "UpsertRepositoryMetadata(ctx, %q)", md.RepoURL)
+
+	_, err = db.db.Exec(ctx, `
+		INSERT INTO repository_metadata (repo_url, missing, stars, archived, last_activity, fetched_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (repo_url)
+		DO UPDATE SET
+			missing = excluded.missing,
+			stars = excluded.stars,
+			archived = excluded.archived,
+			last_activity = excluded.last_activity,
+			fetched_at = excluded.fetched_at`,
+		md.RepoURL, md.Missing, md.Stars, md.Archived,
+		pq.NullTime{Time: md.LastActivity, Valid: !md.LastActivity.IsZero()}, md.FetchedAt)
+	return err
+}
+
+// GetRepositoryMetadata returns the metadata of the repository at repoURL.
+// It returns an error that wraps derrors.NotFound if there is none.
+func (db *DB) GetRepositoryMetadata(ctx context.Context, repoURL string) (_ *repometa.Metadata, err error) {
+	defer derrors.Wrap(&err, "GetRepositoryMetadata(ctx, %q)", repoURL)
+
+	var (
+		md           = repometa.Metadata{RepoURL: repoURL}
+		lastActivity pq.NullTime
+	)
+	err = db.db.QueryRow(ctx, `
+		SELECT missing, stars, archived, last_activity, fetched_at
+		FROM repository_metadata
+		WHERE repo_url = $1`, repoURL).Scan(&md.Missing, &md.Stars, &md.Archived, &lastActivity, &md.FetchedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", repoURL, derrors.NotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	md.LastActivity = lastActivity.Time
+	return &md, nil
+}
+
+// GetRepositoriesForMetadata returns the URLs of at most limit repositories
+// of modules, on the code hosts supported by package repometa, whose
+// metadata was never got, or got before staleBefore, the oldest first.
+func (db *DB) GetRepositoriesForMetadata(ctx context.Context, staleBefore time.Time, limit int) (_ []string, err error) {
+	defer derrors.Wrap(&err, "GetRepositoriesForMetadata(ctx, %s, %d)", staleBefore, limit)
+
+	var patterns []string
+	for _, p := range repometa.URLPrefixes {
+		patterns = append(patterns, p+"%")
+	}
+	query := `
+		SELECT r.repo_url
+		FROM (
+			SELECT DISTINCT source_info->>'RepoURL' AS repo_url
+			FROM modules
+			WHERE source_info->>'RepoURL' LIKE ANY($1)
+		) r
+		LEFT JOIN repository_metadata m
+		ON m.repo_url = r.repo_url
+		WHERE m.fetched_at IS NULL OR m.fetched_at < $2
+		ORDER BY m.fetched_at NULLS FIRST, r.repo_url
+		LIMIT $3`
+	var urls []string
+	collect := func(rows *sql.Rows) error {
+		var u string
+		if err := rows.Scan(&u); err != nil {
+			return err
+		}
+		urls = append(urls, u)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, pq.Array(patterns), staleBefore, limit); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// GetModulePathsForRepository returns the paths of the modules whose
+// repository is at repoURL, in order.
+func (db *DB) GetModulePathsForRepository(ctx context.Context, repoURL string) (_ []string, err error) {
+	defer derrors.Wrap(&err, "GetModulePathsForRepository(ctx, %q)", repoURL)
+
+	return collectStrings(ctx, db.db, `
+		SELECT DISTINCT module_path
+		FROM modules
+		WHERE source_info->>'RepoURL' = $1
+		ORDER BY module_path`, repoURL)
+}
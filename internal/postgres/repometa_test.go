@@ -0,0 +1,70 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/repometa"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestRepositoryMetadata(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	// sample.Module sets the repository URL to https:// followed by the
+	// module path.
+	for _, m := range []string{"github.com/a/a", "github.com/b/b", "gitlab.com/c/c", "example.com/d"} {
+		if err := testDB.InsertModule(ctx, sample.Module(m, sample.VersionString, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now().Round(time.Second)
+	for _, md := range []*repometa.Metadata{
+		{RepoURL: "https://github.com/a/a", Stars: 3, Archived: true, LastActivity: now.Add(-time.Hour), FetchedAt: now.Add(-48 * time.Hour)},
+		{RepoURL: "https://github.com/b/b", Missing: true, FetchedAt: now},
+	} {
+		if err := testDB.UpsertRepositoryMetadata(ctx, md); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := testDB.GetRepositoryMetadata(ctx, "https://github.com/a/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &repometa.Metadata{RepoURL: "https://github.com/a/a", Stars: 3, Archived: true, LastActivity: now.Add(-time.Hour), FetchedAt: now.Add(-48 * time.Hour)}
+	if diff := cmp.Diff(want, got, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+		t.Errorf("GetRepositoryMetadata mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := testDB.GetRepositoryMetadata(ctx, "https://gitlab.com/c/c"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("GetRepositoryMetadata of a repository never fetched: got %v, want NotFound", err)
+	}
+
+	urls, err := testDB.GetRepositoriesForMetadata(ctx, now.Add(-24*time.Hour), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Repositories never fetched come first, then stale ones. Repositories
+	// on other hosts are left out.
+	if diff := cmp.Diff([]string{"https://gitlab.com/c/c", "https://github.com/a/a"}, urls); diff != "" {
+		t.Errorf("GetRepositoriesForMetadata mismatch (-want +got):\n%s", diff)
+	}
+
+	paths, err := testDB.GetModulePathsForRepository(ctx, "https://github.com/a/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"github.com/a/a"}, paths); diff != "" {
+		t.Errorf("GetModulePathsForRepository mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// insertRequires replaces the rows of the module_requires table for m with
+// the requirements of m.
+func insertRequires(ctx context.Context, db *database.DB, m *internal.Module) (err error) {
+	defer derrors.Wrap(&err, "insertRequires(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	if _, err := db.Exec(ctx, `DELETE FROM module_requires WHERE module_path = $1 AND version = $2`,
+		m.ModulePath, m.Version); err != nil {
+		return err
+	}
+	var values []interface{}
+	for _, r := range m.Requires {
+		values = append(values, m.ModulePath, m.Version, r.Path, r.Version, r.Indirect)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	cols := []string{"module_path", "version", "required_path", "required_version", "indirect"}
+	return db.BulkInsert(ctx, "module_requires", cols, values, database.OnConflictDoNothing)
+}
+
+// GetModuleRequires returns the requirements in the go.mod file of the given
+// module version, sorted by path. It returns no requirements if they were
+// not recorded, as for versions fetched before they were, or if the go.mod
+// file has none.
+func (db *DB) GetModuleRequires(ctx context.Context, modulePath, version string) (_ []*internal.ModuleRequire, err error) {
+	defer derrors.Wrap(&err, "GetModuleRequires(ctx, %q, %q)", modulePath, version)
+
+	var reqs []*internal.ModuleRequire
+	collect := func(rows *sql.Rows) error {
+		var r internal.ModuleRequire
+		if err := rows.Scan(&r.Path, &r.Version, &r.Indirect); err != nil {
+			return err
+		}
+		reqs = append(reqs, &r)
+		return nil
+	}
+	query := `
+		SELECT required_path, required_version, indirect
+		FROM module_requires
+		WHERE module_path = $1 AND version = $2
+		ORDER BY required_path`
+	if err := db.db.RunQuery(ctx, query, collect, modulePath, version); err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestGetModuleRequires(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	m := sample.DefaultModule()
+	m.Requires = []*internal.ModuleRequire{
+		{Path: "example.com/a", Version: "v1.2.0"},
+		{Path: "example.com/b", Version: "v0.0.0-20200101000000-abcdefabcdef", Indirect: true},
+	}
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	got, err := testDB.GetModuleRequires(ctx, m.ModulePath, m.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(m.Requires, got); diff != "" {
+		t.Errorf("GetModuleRequires mismatch (-want +got):\n%s", diff)
+	}
+
+	// Reinserting the module replaces its requirements.
+	m.Requires = nil
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	got, err = testDB.GetModuleRequires(ctx, m.ModulePath, m.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("GetModuleRequires after reinsert: got %d requirements, want none", len(got))
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// PrunePseudoVersions deletes the documentation and READMEs of up to limit
+// pseudo-versions that were committed before the given time, in modules that
+// have a tagged version. The rest of what is known about them, like their
+// packages, licenses and imports, is kept, so they still appear in version
+// lists and imported-by counts. It returns the number of module versions
+// pruned.
+//
+// Most module versions are pseudo-versions that nobody looks at once a module
+// has releases, and their documentation is the bulk of the database.
+// Fetching a pruned version again restores its documentation.
+func (db *DB) PrunePseudoVersions(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer derrors.Wrap(&err, "PrunePseudoVersions(ctx, %s, %d)", before, limit)
+
+	query := `
+		SELECT m.id, m.module_path, m.version
+		FROM modules m
+		WHERE m.version_type = 'pseudo'
+		AND m.documentation_pruned_at IS NULL
+		AND m.commit_time < $1
+		AND EXISTS (
+			SELECT 1 FROM modules t
+			WHERE t.module_path = m.module_path
+			AND t.version_type != 'pseudo'
+		)
+		ORDER BY m.commit_time
+		LIMIT $2`
+	type moduleVersion struct {
+		id                  int
+		modulePath, version string
+	}
+	var mvs []moduleVersion
+	collect := func(rows *sql.Rows) error {
+		var mv moduleVersion
+		if err := rows.Scan(&mv.id, &mv.modulePath, &mv.version); err != nil {
+			return err
+		}
+		mvs = append(mvs, mv)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, before, limit); err != nil {
+		return 0, err
+	}
+	for _, mv := range mvs {
+		err := db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+			return pruneModuleVersion(ctx, tx, mv.id, mv.modulePath, mv.version)
+		})
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	log.Infof(ctx, "pruned the documentation of %d pseudo-versions", n)
+	return n, nil
+}
+
+// pruneModuleVersion deletes the documentation and READMEs of a module
+// version, and records that it did.
+func pruneModuleVersion(ctx context.Context, tx *database.DB, moduleID int, modulePath, version string) error {
+	for _, stmt := range []struct {
+		query string
+		args  []interface{}
+	}{
+		{`UPDATE packages SET documentation = NULL, documentation_gz = NULL
+			WHERE module_path = $1 AND version = $2`, []interface{}{modulePath, version}},
+		{`UPDATE documentation SET html = NULL, html_gz = NULL
+			WHERE path_id IN (SELECT id FROM paths WHERE module_id = $1)`, []interface{}{moduleID}},
+		{`DELETE FROM readmes
+			WHERE path_id IN (SELECT id FROM paths WHERE module_id = $1)`, []interface{}{moduleID}},
+		{`UPDATE modules SET
+				readme_file_path = NULL,
+				readme_contents = NULL,
+				readme_contents_gz = NULL,
+				documentation_pruned_at = CURRENT_TIMESTAMP
+			WHERE id = $1`, []interface{}{moduleID}},
+	} {
+		if _, err := tx.Exec(ctx, stmt.query, stmt.args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
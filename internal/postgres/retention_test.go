@@ -0,0 +1,71 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestPrunePseudoVersions(t *testing.T) {
+	defer ResetTestDB(testDB, t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	const pseudo = "v0.0.0-20190101000000-abcdefabcdef"
+	old := sample.CommitTime.Add(-48 * time.Hour)
+	// a has a release, so its old pseudo-version is pruned; b has none.
+	aPseudo := sample.Module("example.com/a", pseudo, "p")
+	aPseudo.CommitTime = old
+	bPseudo := sample.Module("example.com/b", pseudo, "p")
+	bPseudo.CommitTime = old
+	for _, m := range []*internal.Module{
+		sample.Module("example.com/a", "v1.0.0", "p"),
+		aPseudo,
+		bPseudo,
+	} {
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	before := sample.CommitTime.Add(-time.Hour)
+	n, err := testDB.PrunePseudoVersions(ctx, before, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("PrunePseudoVersions: got %d, want 1", n)
+	}
+	for _, test := range []struct {
+		modulePath, version string
+		wantDoc             bool
+	}{
+		{"example.com/a", "v1.0.0", true},
+		{"example.com/a", pseudo, false},
+		{"example.com/b", pseudo, true},
+	} {
+		pkg, err := testDB.GetPackage(ctx, test.modulePath+"/p", test.modulePath, test.version, internal.WithDocumentationHTML)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := pkg.DocumentationHTML != ""; got != test.wantDoc {
+			t.Errorf("%s@%s: has documentation = %t, want %t", test.modulePath, test.version, got, test.wantDoc)
+		}
+	}
+
+	// Pruned versions are not pruned again.
+	n, err = testDB.PrunePseudoVersions(ctx, before, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("PrunePseudoVersions again: got %d, want 0", n)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"golang.org/x/mod/semver"
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/dcensus"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/stdlib"
@@ -53,6 +54,15 @@ var (
 		Description: "Search count, by result source query type.",
 		TagKeys:     []tag.Key{keySearchSource},
 	}
+	// SearchResponseCountByExperiment counts search responses by the
+	// experiments active for the request.
+	SearchResponseCountByExperiment = &view.View{
+		Name:        "go-discovery/search/count_by_experiment",
+		Measure:     keySearchLatency,
+		Aggregation: view.Count(),
+		Description: "Search count, by active experiments.",
+		TagKeys:     []tag.Key{dcensus.KeyExperiments},
+	}
 )
 
 // searchResponse is used for internal bookkeeping when fanning-out search
@@ -105,10 +115,10 @@ var searchers = map[string]searcher{
 // complete.
 //
 // Because 0 <= ts_rank() <= 1, we know that the highest score of any unscanned
-// package is ln(e+N), where N is imported_by_count of the package we are
-// currently considering.  Therefore if the lowest scoring result of popular
-// search is greater than ln(e+N), we know that we haven't missed any results
-// and can return the search result immediately, cancelling other searches.
+// package is the rank of the package we are currently considering (see
+// rankExpr). Therefore if the lowest scoring result of popular search is
+// greater than that rank, we know that we haven't missed any results and can
+// return the search result immediately, cancelling other searches.
 //
 // On the other hand, if the popular search is slow, it is likely that the
 // search term is infrequent, and deep search will be fast due to our inverted
@@ -117,24 +127,54 @@ var searchers = map[string]searcher{
 // The gap in this optimization is search terms that are very frequent, but
 // rarely relevant: "int" or "package", for example. In these cases we'll pay
 // the penalty of a deep search that scans nearly every package.
+//
+// A query like "ident:Reader" instead finds the packages that export the
+// identifier; see IdentifierQuery. Queries can also restrict results to
+// commands or other packages; see ParseSearchFilters.
 func (db *DB) Search(ctx context.Context, q string, limit, offset int) (_ []*internal.SearchResult, err error) {
 	defer derrors.Wrap(&err, "DB.Search(ctx, %q, %d, %d)", q, limit, offset)
+	limit, err = internal.CheckSearchPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if ident, ok := IdentifierQuery(q); ok {
+		return db.searchIdentifier(ctx, ident, limit, offset)
+	}
 	resp, err := db.hedgedSearch(ctx, q, limit, offset, searchers, nil)
 	if err != nil {
 		return nil, err
 	}
-	// Filter out excluded paths.
-	var results []*internal.SearchResult
-	for _, r := range resp.results {
+	results, err := db.filterSearchResults(ctx, resp.results)
+	if err != nil {
+		return nil, err
+	}
+	text, kind := ParseSearchFilters(q)
+	if offset == 0 && kind == "" {
+		results, err = db.PinExactPathMatch(ctx, text, results)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := db.HighlightSearchResults(ctx, text, results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// filterSearchResults filters out excluded paths, and modules the user may
+// not see.
+func (db *DB) filterSearchResults(ctx context.Context, results []*internal.SearchResult) ([]*internal.SearchResult, error) {
+	var filtered []*internal.SearchResult
+	for _, r := range results {
 		ex, err := db.IsExcluded(ctx, r.PackagePath)
 		if err != nil {
 			return nil, err
 		}
-		if !ex {
-			results = append(results, r)
+		if !ex && db.acl.Allowed(ctx, r.ModulePath) {
+			filtered = append(filtered, r)
 		}
 	}
-	return results, nil
+	return filtered, nil
 }
 
 // Penalties to search scores, applied as multipliers to the score.
@@ -145,26 +185,28 @@ const (
 	// Start this off gently (close to 1), but consider lowering
 	// it as time goes by and more of the ecosystem converts to modules.
 	noGoModPenalty = 0.8
+	// Latest version was committed more than staleReleaseAge ago.
+	staleReleasePenalty = 0.9
+	// Package is superseded by another package.
+	supersededPenalty = 0.5
 )
 
+// staleReleaseAge is the age after which a package's latest version is
+// considered stale for ranking.
+const staleReleaseAge = 2 * 365 * 24 * time.Hour
+
 // scoreExpr is the expression that computes the search score.
 // It is the product of:
 // - The Postgres ts_rank score, based the relevance of the document to the query.
-// - The log of the module's popularity, estimated by the number of importing packages.
-//   The log factor contains exp(1) so that it is always >= 1. Taking the log
-//   of imported_by_count instead of using it directly makes the effect less
-//   dramatic: being 2x as popular only has an additive effect.
-// - A penalty factor for non-redistributable modules, since a lot of
-//   details cannot be displayed.
+// - The rank of the document, which does not depend on the query and is
+//   precomputed (see rankExpr).
 // The first argument to ts_rank is an array of weights for the four tsvector sections,
 // in the order D, C, B, A.
 // The weights below match the defaults except for B.
-var scoreExpr = fmt.Sprintf(`
+const scoreExpr = `
 		ts_rank('{0.1, 0.2, 1.0, 1.0}', tsv_search_tokens, websearch_to_tsquery($1)) *
-		ln(exp(1)+imported_by_count) *
-		CASE WHEN redistributable THEN 1 ELSE %f END *
-		CASE WHEN COALESCE(has_go_mod, true) THEN 1 ELSE %f END
-	`, nonRedistributablePenalty, noGoModPenalty)
+		rank
+	`
 
 // hedgedSearch executes multiple search methods and returns the first
 // available result.
@@ -311,6 +353,7 @@ var hllQuery = fmt.Sprintf(`
 					%[2]s *
 					CASE WHEN tsv_search_tokens @@ websearch_to_tsquery($1) THEN 1 ELSE 0 END
 				) > 0.1
+				AND %[3]s
 				AND hll_register=generate_series
 				ORDER BY hll_leading_zeros DESC
 			) t
@@ -335,7 +378,7 @@ var hllQuery = fmt.Sprintf(`
 			)::int AS result_count,
 			%[1]d - count(1) AS empty_register_count
 		FROM nonempty_registers
-	) d`, hllRegisterCount, scoreExpr)
+	) d`, hllRegisterCount, scoreExpr, kindCondition("$2"))
 
 type estimateResponse struct {
 	estimate uint64
@@ -345,7 +388,8 @@ type estimateResponse struct {
 // EstimateResultsCount uses the hyperloglog algorithm to estimate the number
 // of results for the given search term.
 func (db *DB) estimateResultsCount(ctx context.Context, q string) estimateResponse {
-	row := db.db.QueryRow(ctx, hllQuery, q)
+	text, kind := ParseSearchFilters(q)
+	row := db.db.QueryRow(ctx, hllQuery, text, kind)
 	var estimate sql.NullInt64
 	if err := row.Scan(&estimate); err != nil {
 		return estimateResponse{err: fmt.Errorf("row.Scan(): %v", err)}
@@ -371,6 +415,7 @@ func (db *DB) deepSearch(ctx context.Context, q string, limit, offset int) searc
 				FROM
 					search_documents
 				WHERE tsv_search_tokens @@ websearch_to_tsquery($1)
+				AND %s
 				ORDER BY
 					score DESC,
 					commit_time DESC,
@@ -378,7 +423,7 @@ func (db *DB) deepSearch(ctx context.Context, q string, limit, offset int) searc
 		) r
 		WHERE r.score > 0.1
 		LIMIT $2
-		OFFSET $3`, scoreExpr)
+		OFFSET $3`, scoreExpr, kindCondition("$4"))
 	var results []*internal.SearchResult
 	collect := func(rows *sql.Rows) error {
 		var r internal.SearchResult
@@ -389,7 +434,8 @@ func (db *DB) deepSearch(ctx context.Context, q string, limit, offset int) searc
 		results = append(results, &r)
 		return nil
 	}
-	err := db.db.RunQuery(ctx, query, collect, q, limit, offset)
+	text, kind := ParseSearchFilters(q)
+	err := db.db.RunQuery(ctx, query, collect, text, limit, offset, kind)
 	if err != nil {
 		results = nil
 	}
@@ -409,7 +455,7 @@ func (db *DB) popularSearch(ctx context.Context, searchQuery string, limit, offs
 			commit_time,
 			imported_by_count,
 			score
-		FROM popular_search($1, $2, $3, $4, $5)`
+		FROM popular_search_ranked($1, $2, $3, $4)`
 	var results []*internal.SearchResult
 	collect := func(rows *sql.Rows) error {
 		var r internal.SearchResult
@@ -420,7 +466,8 @@ func (db *DB) popularSearch(ctx context.Context, searchQuery string, limit, offs
 		results = append(results, &r)
 		return nil
 	}
-	err := db.db.RunQuery(ctx, query, collect, searchQuery, limit, offset, nonRedistributablePenalty, noGoModPenalty)
+	text, kind := ParseSearchFilters(searchQuery)
+	err := db.db.RunQuery(ctx, query, collect, text, limit, offset, kind)
 	if err != nil {
 		results = nil
 	}
@@ -482,6 +529,46 @@ func (db *DB) addPackageDataToSearchResults(ctx context.Context, results []*inte
 		}
 		return nil
 	}
+	if err := db.db.RunQuery(ctx, query, collect); err != nil {
+		return err
+	}
+	return db.addSymbolsToSearchResults(ctx, keys, resultMap)
+}
+
+// maxSearchResultSymbols is the number of symbols of a package shown in its
+// search result.
+const maxSearchResultSymbols = 3
+
+// addSymbolsToSearchResults sets the Symbols of the search results in
+// resultMap, keyed by package path, to their most important symbols. keys are
+// the (path, version, module_path) tuples of the results.
+func (db *DB) addSymbolsToSearchResults(ctx context.Context, keys []string, resultMap map[string]*internal.SearchResult) (err error) {
+	defer derrors.Wrap(&err, "addSymbolsToSearchResults")
+
+	query := fmt.Sprintf(`
+		SELECT package_path, name
+		FROM (
+			SELECT
+				package_path,
+				name,
+				importance,
+				ROW_NUMBER() OVER (PARTITION BY package_path ORDER BY importance DESC, name) AS n
+			FROM symbols
+			WHERE (package_path, version, module_path) IN (%s)
+			AND importance > 0
+		) s
+		WHERE n <= %d
+		ORDER BY package_path, n`, strings.Join(keys, ","), maxSearchResultSymbols)
+	collect := func(rows *sql.Rows) error {
+		var path, name string
+		if err := rows.Scan(&path, &name); err != nil {
+			return fmt.Errorf("rows.Scan(): %v", err)
+		}
+		if r, ok := resultMap[path]; ok {
+			r.Symbols = append(r.Symbols, name)
+		}
+		return nil
+	}
 	return db.db.RunQuery(ctx, query, collect)
 }
 
@@ -499,7 +586,9 @@ var upsertSearchStatement = fmt.Sprintf(`
 		has_go_mod,
 		tsv_search_tokens,
 		hll_register,
-		hll_leading_zeros
+		hll_leading_zeros,
+		rank,
+		rank_updated_at
 	)
 	SELECT
 		p.path,
@@ -519,7 +608,9 @@ var upsertSearchStatement = fmt.Sprintf(`
 			SETWEIGHT(TO_TSVECTOR($5), 'D')
 		),
 		hll_hash(p.path) & (%[1]d - 1),
-		hll_zeros(hll_hash(p.path))
+		hll_zeros(hll_hash(p.path)),
+		%[2]s,
+		CURRENT_TIMESTAMP
 	FROM
 		packages p
 	INNER JOIN
@@ -555,8 +646,13 @@ var upsertSearchStatement = fmt.Sprintf(`
 			CASE WHEN excluded.version = search_documents.version
 			THEN search_documents.version_updated_at
 			ELSE CURRENT_TIMESTAMP
-			END)
-	;`, hllRegisterCount)
+			END),
+		-- keep the imported_by_count of the existing row
+		rank=%[3]s,
+		rank_updated_at=CURRENT_TIMESTAMP
+	;`, hllRegisterCount,
+	rankExpr("p.path", "0", "p.redistributable", "m.has_go_mod", "m.commit_time"),
+	rankExpr("excluded.package_path", "search_documents.imported_by_count", "excluded.redistributable", "excluded.has_go_mod", "excluded.commit_time"))
 
 // UpsertSearchDocuments adds search information for mod ot the search_documents table.
 func UpsertSearchDocuments(ctx context.Context, db *database.DB, mod *internal.Module) (err error) {
@@ -614,7 +710,7 @@ func (db *DB) GetPackagesForSearchDocumentUpsert(ctx context.Context, before tim
 	defer derrors.Wrap(&err, "GetPackagesForSearchDocumentUpsert(ctx, %s, %d)", before, limit)
 
 	query := `
-		SELECT sd.package_path, sd.module_path, sd.synopsis, m.readme_file_path, m.readme_contents
+		SELECT sd.package_path, sd.module_path, sd.synopsis, m.readme_file_path, ` + textOrGzip("m.readme_contents") + `
 		FROM search_documents sd
 		INNER JOIN modules m
 		USING (module_path, version)
@@ -623,7 +719,7 @@ func (db *DB) GetPackagesForSearchDocumentUpsert(ctx context.Context, before tim
 
 	collect := func(rows *sql.Rows) error {
 		var a upsertSearchDocumentArgs
-		if err := rows.Scan(&a.PackagePath, &a.ModulePath, &a.Synopsis, &a.ReadmeFilePath, &a.ReadmeContents); err != nil {
+		if err := rows.Scan(&a.PackagePath, &a.ModulePath, &a.Synopsis, &a.ReadmeFilePath, decompressed(&a.ReadmeContents)); err != nil {
 			return err
 		}
 		argsList = append(argsList, a)
@@ -639,7 +735,11 @@ func (db *DB) GetPackagesForSearchDocumentUpsert(ctx context.Context, before tim
 // imported_by_count_updated_at.
 //
 // It does so by completely recalculating the imported-by counts
-// from the imports_unique table.
+// from the imports_unique table, and storing them in the imported_by_counts
+// table.
+//
+// It also updates the rank of every search document, since ranks depend on
+// imported-by counts.
 //
 // UpdateSearchDocumentsImportedByCount returns the number of rows updated.
 func (db *DB) UpdateSearchDocumentsImportedByCount(ctx context.Context) (nUpdated int64, err error) {
@@ -661,6 +761,11 @@ func (db *DB) UpdateSearchDocumentsImportedByCount(ctx context.Context) (nUpdate
 			return err
 		}
 		nUpdated, err = updateImportedByCounts(ctx, tx)
+		if err != nil {
+			return err
+		}
+		// Ranks depend on imported-by counts.
+		_, err = updateSearchDocumentsRank(ctx, tx)
 		return err
 	})
 	return nUpdated, err
@@ -726,24 +831,24 @@ func (db *DB) computeImportedByCounts(ctx context.Context, searchDocsPackages ma
 	return counts, nil
 }
 
+// insertImportedByCounts replaces the contents of the imported_by_counts
+// table with counts.
 func insertImportedByCounts(ctx context.Context, db *database.DB, counts map[string]int) (err error) {
 	defer derrors.Wrap(&err, "insertImportedByCounts(ctx, db, counts)")
 
-	const createTableQuery = `
-		CREATE TEMPORARY TABLE computed_imported_by_counts (
-			package_path      TEXT NOT NULL,
-			imported_by_count INTEGER DEFAULT 0 NOT NULL
-		) ON COMMIT DROP;
-    `
-	if _, err := db.Exec(ctx, createTableQuery); err != nil {
-		return fmt.Errorf("CREATE TABLE: %v", err)
+	// Readers see the old counts until the transaction commits.
+	if _, err := db.Exec(ctx, `DELETE FROM imported_by_counts`); err != nil {
+		return err
 	}
 	values := make([]interface{}, 0, 2*len(counts))
 	for p, c := range counts {
 		values = append(values, p, c)
 	}
+	if len(values) == 0 {
+		return nil
+	}
 	columns := []string{"package_path", "imported_by_count"}
-	return db.BulkInsert(ctx, "computed_imported_by_counts", columns, values, "")
+	return db.CopyUpsert(ctx, "imported_by_counts", columns, values, []string{"package_path"})
 }
 
 func compareImportedByCounts(ctx context.Context, db *database.DB) (err error) {
@@ -757,7 +862,7 @@ func compareImportedByCounts(ctx context.Context, db *database.DB) (err error) {
 		FROM
 			search_documents s
 		INNER JOIN
-			computed_imported_by_counts c
+			imported_by_counts c
 		ON
 			s.package_path = c.package_path
 	`
@@ -787,7 +892,7 @@ func compareImportedByCounts(ctx context.Context, db *database.DB) (err error) {
 	if err != nil {
 		return err
 	}
-	log.Infof(ctx, "%6d total rows in search_documents match computed_imported_by_counts", total)
+	log.Infof(ctx, "%6d total rows in search_documents match imported_by_counts", total)
 	log.Infof(ctx, "%6d will change", change)
 	log.Infof(ctx, "%6d currently have a zero imported-by count", zero)
 	log.Infof(ctx, "%6d of the non-zero rows will change by more than %d%%", diff, int(changeThreshold*100))
@@ -795,7 +900,7 @@ func compareImportedByCounts(ctx context.Context, db *database.DB) (err error) {
 }
 
 // updateImportedByCounts updates the imported_by_count column in search_documents
-// for every package in computed_imported_by_counts.
+// for every package in imported_by_counts.
 //
 // A row is updated even if the value doesn't change, so that the imported_by_count_updated_at
 // column is set.
@@ -808,7 +913,7 @@ func updateImportedByCounts(ctx context.Context, db *database.DB) (int64, error)
 		SET
 			imported_by_count = c.imported_by_count,
 			imported_by_count_updated_at = CURRENT_TIMESTAMP
-		FROM computed_imported_by_counts c
+		FROM imported_by_counts c
 		WHERE s.package_path = c.package_path;`
 
 	res, err := db.Exec(ctx, updateStmt)
@@ -849,20 +954,26 @@ var (
 // GeneratePathTokens returns the subPaths and path token parts that will be
 // indexed for search, which includes (1) the packagePath (2) all sub-paths of
 // the packagePath (3) all parts for a path element that is delimited by a dash
-// and (4) all parts of a path element that is delimited by a dot, except for
-// the last element.
+// or an underscore (4) the lower-case words of a CamelCase path element and
+// (5) all parts of a path element that is delimited by a dot, except for the
+// last element.
 func GeneratePathTokens(packagePath string) []string {
 	packagePath = strings.Trim(packagePath, "/")
 
 	subPathSet := make(map[string]bool)
 	parts := strings.Split(packagePath, "/")
 	for i, part := range parts {
-		dashParts := strings.Split(part, "-")
+		dashParts := strings.FieldsFunc(part, func(r rune) bool { return r == '-' || r == '_' })
 		if len(dashParts) > 1 {
 			for _, p := range dashParts {
 				subPathSet[p] = true
 			}
 		}
+		for _, p := range dashParts {
+			for _, w := range splitCamelCase(p) {
+				subPathSet[strings.ToLower(w)] = true
+			}
+		}
 		for j := i + 2; j <= len(parts); j++ {
 			p := strings.Join(parts[i:j], "/")
 			p = strings.Trim(p, "/")
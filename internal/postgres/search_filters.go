@@ -0,0 +1,45 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kinds of packages that a search can be restricted to, using a filter like
+// "is:command" in the query.
+const (
+	// CommandKind is the kind of packages named main.
+	CommandKind = "command"
+	// PackageKind is the kind of all other packages.
+	PackageKind = "package"
+)
+
+// ParseSearchFilters separates the filters in the search query q from the
+// text to search for. It returns the text and the kind of package that the
+// results must be, or the empty string if any kind will do. If there are
+// several filters, the last one is used.
+func ParseSearchFilters(q string) (text, kind string) {
+	var words []string
+	for _, w := range strings.Fields(q) {
+		switch strings.ToLower(w) {
+		case "is:" + CommandKind:
+			kind = CommandKind
+		case "is:" + PackageKind:
+			kind = PackageKind
+		default:
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, " "), kind
+}
+
+// kindCondition returns an SQL condition on the name column of
+// search_documents that holds for packages of the kind given by the
+// placeholder param. Every package is of the empty kind.
+func kindCondition(param string) string {
+	return fmt.Sprintf("(%[1]s = '' OR (name = 'main') = (%[1]s = '%[2]s'))", param, CommandKind)
+}
@@ -0,0 +1,25 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import "testing"
+
+func TestParseSearchFilters(t *testing.T) {
+	for _, test := range []struct {
+		q, wantText, wantKind string
+	}{
+		{"http client", "http client", ""},
+		{"is:command lint", "lint", CommandKind},
+		{"lint IS:Package", "lint", PackageKind},
+		{"is:package is:command lint", "lint", CommandKind},
+		{"is:other lint", "is:other lint", ""},
+		{"is:command", "", CommandKind},
+	} {
+		text, kind := ParseSearchFilters(test.q)
+		if text != test.wantText || kind != test.wantKind {
+			t.Errorf("ParseSearchFilters(%q) = %q, %q; want %q, %q", test.q, text, kind, test.wantText, test.wantKind)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// ts_headline marks the words that match the query with these private-use
+// characters, which are removed from the text beforehand. They are replaced
+// by HTML elements after the text is escaped.
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+// maxHighlightTextLen is the maximum number of bytes of a README that are
+// searched for fragments to highlight. The cost of ts_headline is
+// proportional to the length of the text.
+const maxHighlightTextLen = 10000
+
+var (
+	// synopsisHighlightOptions highlights every match in the synopsis, which is
+	// short enough to show in full.
+	synopsisHighlightOptions = fmt.Sprintf(`StartSel="%s", StopSel="%s", HighlightAll=true`,
+		highlightStart, highlightStop)
+
+	// readmeHighlightOptions selects up to two short fragments of the README.
+	readmeHighlightOptions = fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxFragments=2, MaxWords=20, MinWords=8, FragmentDelimiter=" … "`,
+		highlightStart, highlightStop)
+
+	highlightMarkerRemover = strings.NewReplacer(highlightStart, "", highlightStop, "")
+	highlightHTMLReplacer  = strings.NewReplacer(highlightStart, "<b>", highlightStop, "</b>")
+)
+
+// HighlightSearchResults sets the SynopsisHighlight and ReadmeHighlight fields
+// of the results to the fragments of the synopsis and README that match the
+// query q. Each result must have its Synopsis set. As in search_documents,
+// only the README of a module's root package is used.
+func (db *DB) HighlightSearchResults(ctx context.Context, q string, results []*internal.SearchResult) (err error) {
+	defer derrors.Wrap(&err, "HighlightSearchResults(ctx, %q, %d results)", q, len(results))
+
+	if len(results) == 0 {
+		return nil
+	}
+	readmes, err := getSearchResultReadmes(ctx, db.db, results)
+	if err != nil {
+		return err
+	}
+	// Highlight the synopses and READMEs in a single query. texts[2*i] is the
+	// synopsis of results[i], and texts[2*i+1] is its README.
+	var texts, options []string
+	for _, r := range results {
+		texts = append(texts, highlightMarkerRemover.Replace(r.Synopsis), readmes[r.PackagePath])
+		options = append(options, synopsisHighlightOptions, readmeHighlightOptions)
+	}
+	query := `
+		SELECT ts_headline(t, websearch_to_tsquery($1), o)
+		FROM unnest($2::text[], $3::text[]) WITH ORDINALITY AS x(t, o, i)
+		ORDER BY i`
+	var headlines []string
+	collect := func(rows *sql.Rows) error {
+		var h string
+		if err := rows.Scan(&h); err != nil {
+			return err
+		}
+		headlines = append(headlines, h)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, q, pq.Array(texts), pq.Array(options)); err != nil {
+		return err
+	}
+	if len(headlines) != len(texts) {
+		return fmt.Errorf("got %d headlines, want %d", len(headlines), len(texts))
+	}
+	for i, r := range results {
+		r.SynopsisHighlight = highlightHTML(headlines[2*i])
+		r.ReadmeHighlight = highlightHTML(headlines[2*i+1])
+	}
+	return nil
+}
+
+// getSearchResultReadmes returns the text of the READMEs of the results that
+// are the root packages of their modules, keyed by package path. The text is
+// truncated to maxHighlightTextLen bytes.
+func getSearchResultReadmes(ctx context.Context, db *database.DB, results []*internal.SearchResult) (map[string]string, error) {
+	var keys []string
+	for _, r := range results {
+		if r.PackagePath == r.ModulePath {
+			keys = append(keys, fmt.Sprintf("(%s, %s)", pq.QuoteLiteral(r.ModulePath), pq.QuoteLiteral(r.Version)))
+		}
+	}
+	readmes := map[string]string{}
+	if len(keys) == 0 {
+		return readmes, nil
+	}
+	query := fmt.Sprintf(`
+		SELECT
+			module_path,
+			readme_file_path,
+			`+textOrGzip("readme_contents")+`
+		FROM modules
+		WHERE (module_path, version) IN (%s)`, strings.Join(keys, ","))
+	collect := func(rows *sql.Rows) error {
+		var modulePath, filename, contents string
+		if err := rows.Scan(&modulePath, database.NullIsEmpty(&filename), decompressed(&contents)); err != nil {
+			return err
+		}
+		if isMarkdown(filename) {
+			contents = processMarkdown(contents)
+		}
+		readmes[modulePath] = truncateText(highlightMarkerRemover.Replace(makeValidUnicode(contents)), maxHighlightTextLen)
+		return nil
+	}
+	if err := db.RunQuery(ctx, query, collect); err != nil {
+		return nil, err
+	}
+	return readmes, nil
+}
+
+// truncateText returns the longest prefix of s that has at most n bytes and
+// does not split a rune.
+func truncateText(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// highlightHTML returns the output of ts_headline as HTML, in which the
+// highlighted words are in <b> elements. It returns the empty string if
+// nothing is highlighted, because then ts_headline returns the start of the
+// text, which does not show why the result matched.
+func highlightHTML(s string) string {
+	if !strings.Contains(s, highlightStart) {
+		return ""
+	}
+	return highlightHTMLReplacer.Replace(html.EscapeString(s))
+}
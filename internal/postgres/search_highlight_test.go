@@ -0,0 +1,87 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestHighlightHTML(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"no match", ""},
+		{"a match", "a <b>match</b>"},
+		{"<script>x & y", "&lt;script&gt;<b>x</b> &amp; y"},
+	} {
+		if got := highlightHTML(test.in); got != test.want {
+			t.Errorf("highlightHTML(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"abc", 5, "abc"},
+		{"abcdef", 3, "abc"},
+		{"aé", 2, "a"},
+		{"aé", 3, "aé"},
+	} {
+		if got := truncateText(test.in, test.n); got != test.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", test.in, test.n, got, test.want)
+		}
+	}
+}
+
+func TestHighlightSearchResults(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	m := sample.Module(sample.ModulePath, sample.VersionString, "")
+	m.LegacyReadmeFilePath = "README.md"
+	m.LegacyReadmeContents = "# Title\n\nThis module parses **configuration** files.\n"
+	m.LegacyPackages[0].Synopsis = "Package foo reads configuration."
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	results := []*internal.SearchResult{
+		{
+			PackagePath: sample.ModulePath,
+			ModulePath:  sample.ModulePath,
+			Version:     sample.VersionString,
+			Synopsis:    m.LegacyPackages[0].Synopsis,
+		},
+		{
+			// Not a module root, so the README is not used.
+			PackagePath: sample.ModulePath + "/sub",
+			ModulePath:  sample.ModulePath,
+			Version:     sample.VersionString,
+			Synopsis:    "Unrelated.",
+		},
+	}
+	if err := testDB.HighlightSearchResults(ctx, "configuration", results); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := results[0].SynopsisHighlight, "Package foo reads <b>configuration</b>."; got != want {
+		t.Errorf("SynopsisHighlight = %q, want %q", got, want)
+	}
+	if got := results[0].ReadmeHighlight; !strings.Contains(got, "<b>configuration</b>") || strings.Contains(got, "*") {
+		t.Errorf("ReadmeHighlight = %q, want a fragment highlighting %q without the markdown", got, "configuration")
+	}
+	if results[1].SynopsisHighlight != "" || results[1].ReadmeHighlight != "" {
+		t.Errorf("got highlights %q, %q for a non-matching result, want none",
+			results[1].SynopsisHighlight, results[1].ReadmeHighlight)
+	}
+}
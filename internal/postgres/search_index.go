@@ -0,0 +1,205 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// The search_documents table is maintained incrementally: a package's row is
+// upserted when the latest version of its module is inserted, and replaced by
+// the row for the next latest version when a module version is deleted.
+// CheckSearchDocuments reports any drift between the packages table and the
+// search index.
+
+// hasLaterAlternativeVersion reports whether a version of modulePath later
+// than version was found to have an alternative module path. The packages of
+// such a module are not inserted into search_documents; see saveModule.
+func hasLaterAlternativeVersion(ctx context.Context, db *database.DB, modulePath, vers string) (bool, error) {
+	row := db.QueryRow(ctx, `
+		SELECT 1 FROM module_version_states
+		WHERE module_path = $1 AND sort_version > $2 and status = 491`,
+		modulePath, version.ForSorting(vers))
+	var x int
+	switch err := row.Scan(&x); err {
+	case nil:
+		return true, nil
+	case sql.ErrNoRows:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// upsertSearchDocumentsForPaths upserts the search documents for the latest
+// versions of the packages with the given paths. Paths that are no longer in
+// the packages table are skipped.
+func upsertSearchDocumentsForPaths(ctx context.Context, db *database.DB, paths []string) (err error) {
+	defer derrors.Wrap(&err, "upsertSearchDocumentsForPaths(ctx, %d paths)", len(paths))
+
+	if len(paths) == 0 {
+		return nil
+	}
+	query := `
+		SELECT DISTINCT ON (p.path)
+			p.path,
+			p.module_path,
+			p.version,
+			p.synopsis,
+			m.readme_file_path,
+			` + textOrGzip("m.readme_contents") + `
+		FROM packages p
+		INNER JOIN modules m
+		ON p.module_path = m.module_path AND p.version = m.version
+		WHERE p.path = ANY($1)
+		-- Use the same order as upsertSearchStatement.
+		ORDER BY
+			p.path,
+			m.version_type = 'release' DESC,
+			m.sort_version DESC,
+			m.module_path DESC`
+	type latest struct {
+		args    upsertSearchDocumentArgs
+		version string
+	}
+	var packages []latest
+	collect := func(rows *sql.Rows) error {
+		var l latest
+		if err := rows.Scan(&l.args.PackagePath, &l.args.ModulePath, &l.version, &l.args.Synopsis,
+			database.NullIsEmpty(&l.args.ReadmeFilePath), decompressed(&l.args.ReadmeContents)); err != nil {
+			return err
+		}
+		packages = append(packages, l)
+		return nil
+	}
+	if err := db.RunQuery(ctx, query, collect, pq.Array(paths)); err != nil {
+		return err
+	}
+	for _, l := range packages {
+		if isInternalPackage(l.args.PackagePath) {
+			continue
+		}
+		alt, err := hasLaterAlternativeVersion(ctx, db, l.args.ModulePath, l.version)
+		if err != nil {
+			return err
+		}
+		if alt {
+			continue
+		}
+		if err := UpsertSearchDocument(ctx, db, l.args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchIndexDrift describes the differences between the packages table and
+// the search_documents table.
+type SearchIndexDrift struct {
+	// Missing holds the paths of packages that should be in search_documents
+	// but are not.
+	Missing []string
+	// Stale holds the paths of packages whose search document is not for the
+	// latest version of the package.
+	Stale []string
+}
+
+// CheckSearchDocuments compares the search_documents table with the latest
+// versions of the packages in the packages table.
+//
+// A package should be in search_documents if it is in the latest version of
+// its module, is not internal, and its module has no later version with an
+// alternative module path. Its search document should be for the latest
+// version of the package path, across all modules.
+func (db *DB) CheckSearchDocuments(ctx context.Context) (_ *SearchIndexDrift, err error) {
+	defer derrors.Wrap(&err, "CheckSearchDocuments(ctx)")
+
+	query := `
+		WITH latest_modules AS (
+			SELECT DISTINCT ON (module_path) module_path, version, sort_version
+			FROM modules
+			ORDER BY module_path, version_type = 'release' DESC, sort_version DESC
+		),
+		indexed_paths AS (
+			SELECT DISTINCT p.path
+			FROM packages p
+			INNER JOIN latest_modules lm
+			ON p.module_path = lm.module_path AND p.version = lm.version
+			WHERE NOT EXISTS (
+				SELECT 1 FROM module_version_states s
+				WHERE s.module_path = lm.module_path
+				AND s.sort_version > lm.sort_version
+				AND s.status = 491)
+		),
+		latest_packages AS (
+			SELECT DISTINCT ON (p.path) p.path, p.module_path, p.version
+			FROM packages p
+			INNER JOIN modules m
+			ON p.module_path = m.module_path AND p.version = m.version
+			WHERE p.path IN (SELECT path FROM indexed_paths)
+			ORDER BY
+				p.path,
+				m.version_type = 'release' DESC,
+				m.sort_version DESC,
+				m.module_path DESC
+		)
+		SELECT lp.path, sd.package_path IS NULL
+		FROM latest_packages lp
+		LEFT JOIN search_documents sd
+		ON sd.package_path = lp.path
+		WHERE sd.package_path IS NULL
+		OR sd.module_path <> lp.module_path
+		OR sd.version <> lp.version`
+
+	var drift SearchIndexDrift
+	collect := func(rows *sql.Rows) error {
+		var (
+			path    string
+			missing bool
+		)
+		if err := rows.Scan(&path, &missing); err != nil {
+			return err
+		}
+		if isInternalPackage(path) {
+			return nil
+		}
+		if missing {
+			drift.Missing = append(drift.Missing, path)
+		} else {
+			drift.Stale = append(drift.Stale, path)
+		}
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect); err != nil {
+		return nil, err
+	}
+	sort.Strings(drift.Missing)
+	sort.Strings(drift.Stale)
+	log.Infof(ctx, "search_documents: %d missing packages, %d stale packages", len(drift.Missing), len(drift.Stale))
+	return &drift, nil
+}
+
+// RepairSearchDocuments upserts the search documents for the given package
+// paths, typically those reported by CheckSearchDocuments.
+func (db *DB) RepairSearchDocuments(ctx context.Context, paths []string) (err error) {
+	defer derrors.Wrap(&err, "RepairSearchDocuments(ctx, %d paths)", len(paths))
+	return db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		return upsertSearchDocumentsForPaths(ctx, tx, paths)
+	})
+}
+
+// String returns a summary of the drift.
+func (d *SearchIndexDrift) String() string {
+	return fmt.Sprintf("%d missing, %d stale", len(d.Missing), len(d.Stale))
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestSearchDocumentsIncremental(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	defer ResetTestDB(testDB, t)
+
+	const modulePath = "example.com/mod"
+	pkgPath := modulePath + "/p"
+	for _, v := range []string{"v1.0.0", "v1.1.0"} {
+		if err := testDB.InsertModule(ctx, sample.Module(modulePath, v, "p")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkVersion := func(want string) {
+		t.Helper()
+		sd, err := getSearchDocument(ctx, testDB, pkgPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sd.version != want {
+			t.Errorf("search document version = %q, want %q", sd.version, want)
+		}
+	}
+	checkDrift := func(want *SearchIndexDrift) {
+		t.Helper()
+		got, err := testDB.CheckSearchDocuments(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got, cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("CheckSearchDocuments mismatch (-want +got):\n%s", diff)
+		}
+	}
+	checkVersion("v1.1.0")
+	checkDrift(&SearchIndexDrift{})
+
+	// Deleting the latest version falls back to the previous one.
+	if err := testDB.DeleteModule(ctx, modulePath, "v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	checkVersion("v1.0.0")
+	checkDrift(&SearchIndexDrift{})
+
+	// Introduce drift, and repair it.
+	if err := testDB.InsertModule(ctx, sample.Module(modulePath, "v1.2.0", "p")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testDB.db.Exec(ctx, `UPDATE search_documents SET version = 'v1.0.0' WHERE package_path = $1`, pkgPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDB.InsertModule(ctx, sample.Module("example.com/other", "v1.0.0", "q")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testDB.db.Exec(ctx, `DELETE FROM search_documents WHERE module_path = 'example.com/other'`); err != nil {
+		t.Fatal(err)
+	}
+	want := &SearchIndexDrift{
+		Missing: []string{"example.com/other/q"},
+		Stale:   []string{pkgPath},
+	}
+	checkDrift(want)
+	if err := testDB.RepairSearchDocuments(ctx, append(want.Missing, want.Stale...)); err != nil {
+		t.Fatal(err)
+	}
+	checkVersion("v1.2.0")
+	checkDrift(&SearchIndexDrift{})
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// looksLikeImportPath reports whether the search query q could be an import
+// path: a single word that contains a slash or a dot.
+func looksLikeImportPath(q string) bool {
+	return strings.ContainsAny(q, "/.") && strings.IndexFunc(q, unicode.IsSpace) < 0
+}
+
+// PinExactPathMatch returns results with the package whose path is the
+// search query q first, if q looks like an import path and that package can
+// be searched for. Otherwise it returns results unchanged.
+//
+// If the package is already in results, it is moved to the front. Otherwise
+// it is added, even though its score was too low for it to be among the
+// results. Callers should only pin the first page of results, because the
+// package is not removed from later pages.
+func (db *DB) PinExactPathMatch(ctx context.Context, q string, results []*internal.SearchResult) (_ []*internal.SearchResult, err error) {
+	defer derrors.Wrap(&err, "PinExactPathMatch(ctx, %q, %d results)", q, len(results))
+
+	q = strings.Trim(strings.TrimSpace(q), "/")
+	if !looksLikeImportPath(q) {
+		return results, nil
+	}
+	for i, r := range results {
+		if r.PackagePath == q {
+			pinned := append([]*internal.SearchResult{r}, results[:i]...)
+			return append(pinned, results[i+1:]...), nil
+		}
+	}
+
+	r := &internal.SearchResult{PackagePath: q}
+	err = db.db.QueryRow(ctx, `
+		SELECT module_path, version, commit_time, imported_by_count
+		FROM search_documents
+		WHERE package_path = $1`, q).Scan(&r.ModulePath, &r.Version, &r.CommitTime, &r.NumImportedBy)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		return results, nil
+	default:
+		return nil, err
+	}
+	if err := db.addPackageDataToSearchResults(ctx, []*internal.SearchResult{r}); err != nil {
+		return nil, err
+	}
+	found, err := db.filterSearchResults(ctx, []*internal.SearchResult{r})
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return results, nil
+	}
+	if len(results) > 0 {
+		r.NumResults = results[0].NumResults + 1
+		r.Approximate = results[0].Approximate
+	} else {
+		r.NumResults = 1
+	}
+	return append([]*internal.SearchResult{r}, results...), nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestLooksLikeImportPath(t *testing.T) {
+	for _, test := range []struct {
+		q    string
+		want bool
+	}{
+		{"github.com/a/b", true},
+		{"gopkg.in", true},
+		{"net/http", true},
+		{"http", false},
+		{"http server", false},
+		{"net/http server", false},
+	} {
+		if got := looksLikeImportPath(test.q); got != test.want {
+			t.Errorf("looksLikeImportPath(%q) = %t, want %t", test.q, got, test.want)
+		}
+	}
+}
+
+func TestPinExactPathMatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	for _, m := range []*internal.Module{
+		sample.Module("github.com/pin/a", sample.VersionString, "pkg"),
+		sample.Module("github.com/pin/b", sample.VersionString, "pkg"),
+	} {
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result := func(path string) *internal.SearchResult {
+		return &internal.SearchResult{PackagePath: path, NumResults: 1}
+	}
+	paths := func(rs []*internal.SearchResult) []string {
+		var ps []string
+		for _, r := range rs {
+			ps = append(ps, r.PackagePath)
+		}
+		return ps
+	}
+
+	for _, test := range []struct {
+		q       string
+		results []*internal.SearchResult
+		want    []string
+	}{
+		{
+			q:       "pkg",
+			results: []*internal.SearchResult{result("github.com/pin/a/pkg")},
+			want:    []string{"github.com/pin/a/pkg"},
+		},
+		{
+			q:       "github.com/pin/b/pkg",
+			results: []*internal.SearchResult{result("github.com/pin/a/pkg"), result("github.com/pin/b/pkg")},
+			want:    []string{"github.com/pin/b/pkg", "github.com/pin/a/pkg"},
+		},
+		{
+			q:       "github.com/pin/b/pkg/",
+			results: []*internal.SearchResult{result("github.com/pin/a/pkg")},
+			want:    []string{"github.com/pin/b/pkg", "github.com/pin/a/pkg"},
+		},
+		{
+			q:       "github.com/pin/c/pkg",
+			results: []*internal.SearchResult{result("github.com/pin/a/pkg")},
+			want:    []string{"github.com/pin/a/pkg"},
+		},
+	} {
+		got, err := testDB.PinExactPathMatch(ctx, test.q, test.results)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(test.want, paths(got)); diff != "" {
+			t.Errorf("PinExactPathMatch(%q) mismatch (-want +got):\n%s", test.q, diff)
+		}
+		if test.q == "github.com/pin/b/pkg/" {
+			pinned := got[0]
+			if pinned.Name != "pkg" || pinned.ModulePath != "github.com/pin/b" || pinned.NumResults != 2 {
+				t.Errorf("pinned result = %+v, want name, module path and result count set", pinned)
+			}
+		}
+	}
+}
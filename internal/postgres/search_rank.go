@@ -0,0 +1,94 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// rankExpr returns the expression that computes the rank of a search
+// document from the given SQL expressions for its columns. The rank is the
+// part of the search score that does not depend on the query. It is stored in
+// the rank column of search_documents, so that it is not recomputed for every
+// query and so that the order of results is stable between ranking updates.
+//
+// The rank is the product of:
+//   - The log of the package's popularity, estimated by the number of importing
+//     packages. The log factor contains exp(1) so that it is always >= 1. Taking
+//     the log of imported_by_count instead of using it directly makes the effect
+//     less dramatic: being 2x as popular only has an additive effect.
+//   - A penalty factor for non-redistributable modules, since a lot of
+//     details cannot be displayed.
+//   - A penalty factor for modules without a go.mod file.
+//   - A penalty factor for packages whose latest version is older than
+//     staleReleaseAge.
+//   - A penalty factor for packages superseded by other packages (see
+//     SupersedePackage).
+//
+// Since every factor except the first is at most 1, the rank of a document
+// is at most ln(e+imported_by_count).
+func rankExpr(packagePath, importedByCount, redistributable, hasGoMod, commitTime string) string {
+	return fmt.Sprintf(`(
+		ln(exp(1)+%s) *
+		CASE WHEN %s THEN 1 ELSE %f END *
+		CASE WHEN COALESCE(%s, true) THEN 1 ELSE %f END *
+		CASE WHEN %s < CURRENT_TIMESTAMP - INTERVAL '%d seconds' THEN %f ELSE 1 END *
+		CASE WHEN EXISTS (SELECT 1 FROM superseded_packages sp WHERE sp.package_path = %s) THEN %f ELSE 1 END
+	)::real`,
+		importedByCount,
+		redistributable, nonRedistributablePenalty,
+		hasGoMod, noGoModPenalty,
+		commitTime, int(staleReleaseAge.Seconds()), staleReleasePenalty,
+		packagePath, supersededPenalty)
+}
+
+// UpdateSearchDocumentsRank recomputes the rank of every search document
+// whose rank has changed, and returns the number of documents updated.
+//
+// Ranks are also updated when documents are inserted and when imported-by
+// counts are updated, but they must be recomputed periodically because
+// releases become stale over time.
+func (db *DB) UpdateSearchDocumentsRank(ctx context.Context) (nUpdated int64, err error) {
+	defer derrors.Wrap(&err, "UpdateSearchDocumentsRank(ctx)")
+	return updateSearchDocumentsRank(ctx, db.db)
+}
+
+func updateSearchDocumentsRank(ctx context.Context, db *database.DB) (int64, error) {
+	return updateRanks(ctx, db, "true")
+}
+
+// updateSearchDocumentRank recomputes the rank of the search document of the
+// package at pkgPath, if it has one.
+func updateSearchDocumentRank(ctx context.Context, db *database.DB, pkgPath string) error {
+	_, err := updateRanks(ctx, db, "package_path = $1", pkgPath)
+	return err
+}
+
+// updateRanks recomputes the rank of the search documents that match the SQL
+// condition cond, whose arguments are args, and returns the number of
+// documents whose rank changed.
+func updateRanks(ctx context.Context, db *database.DB, cond string, args ...interface{}) (int64, error) {
+	rank := rankExpr("search_documents.package_path", "imported_by_count", "redistributable", "has_go_mod", "commit_time")
+	updateStmt := fmt.Sprintf(`
+		UPDATE search_documents
+		SET
+			rank = %[1]s,
+			rank_updated_at = CURRENT_TIMESTAMP
+		WHERE %[2]s
+		AND rank IS DISTINCT FROM %[1]s;`, rank, cond)
+	res, err := db.Exec(ctx, updateStmt, args...)
+	if err != nil {
+		return 0, fmt.Errorf("error updating rank for search documents: %v", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("RowsAffected: %v", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestSearchDocumentsRank(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	defer ResetTestDB(testDB, t)
+
+	modules := map[string]struct {
+		redist   bool
+		stale    bool
+		wantRank float64
+	}{
+		"fresh.com/foo":     {true, false, 1},
+		"stale.com/foo":     {true, true, staleReleasePenalty},
+		"nonredist.com/foo": {false, false, nonRedistributablePenalty},
+	}
+	for path, m := range modules {
+		v := sample.Module(path, sample.VersionString, "p")
+		v.LegacyPackages[0].IsRedistributable = m.redist
+		v.IsRedistributable = m.redist
+		if m.stale {
+			v.CommitTime = time.Now().Add(-staleReleaseAge - 24*time.Hour)
+		}
+		if err := testDB.InsertModule(ctx, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkRanks := func() {
+		t.Helper()
+		for path, m := range modules {
+			var got float64
+			row := testDB.db.QueryRow(ctx, `SELECT rank FROM search_documents WHERE package_path = $1`, path+"/p")
+			if err := row.Scan(&got); err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-m.wantRank) > 1e-6 {
+				t.Errorf("%s: got rank %f, want %f", path, got, m.wantRank)
+			}
+		}
+	}
+	// Ranks are computed when documents are inserted.
+	checkRanks()
+
+	// Nothing has changed, so nothing is updated.
+	n, err := testDB.UpdateSearchDocumentsRank(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("UpdateSearchDocumentsRank: updated %d documents, want 0", n)
+	}
+
+	if _, err := testDB.db.Exec(ctx, `UPDATE search_documents SET rank = 1`); err != nil {
+		t.Fatal(err)
+	}
+	n, err = testDB.UpdateSearchDocumentsRank(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(2); n != want {
+		t.Errorf("UpdateSearchDocumentsRank: updated %d documents, want %d", n, want)
+	}
+	checkRanks()
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// A SearchDocument holds the contents of a row of search_documents, in a form
+// that can be copied to another search backend.
+type SearchDocument struct {
+	PackagePath     string
+	ModulePath      string
+	Version         string
+	Name            string
+	Synopsis        string
+	Licenses        []string
+	CommitTime      time.Time
+	ImportedByCount int
+	// Rank is the part of the search score that does not depend on the
+	// query; see rankExpr.
+	Rank float64
+
+	// PathTokens, SectionB, SectionC and SectionD are the text of the
+	// document, in decreasing order of importance. They are the inputs to the
+	// A, B, C and D sections of the tsvector in search_documents.
+	PathTokens string
+	SectionB   string
+	SectionC   string
+	SectionD   string
+
+	// UpdatedAt is when the row was last updated.
+	UpdatedAt time.Time
+}
+
+// SearchDocumentsUpdatedSince returns up to limit search documents that were
+// updated after the given time, or at that time with a package path after
+// afterPath, in order of update time and package path. The UpdatedAt and
+// PackagePath of the last document can be passed to the next call to page
+// through all the documents.
+func (db *DB) SearchDocumentsUpdatedSince(ctx context.Context, since time.Time, afterPath string, limit int) (_ []*SearchDocument, err error) {
+	defer derrors.Wrap(&err, "SearchDocumentsUpdatedSince(ctx, %s, %q, %d)", since, afterPath, limit)
+
+	query := `
+		SELECT
+			sd.package_path,
+			sd.module_path,
+			sd.version,
+			sd.name,
+			sd.synopsis,
+			sd.license_types,
+			sd.commit_time,
+			sd.imported_by_count,
+			sd.rank,
+			sd.updated_at,
+			m.readme_file_path,
+			` + textOrGzip("m.readme_contents") + `
+		FROM search_documents sd
+		INNER JOIN modules m
+		USING (module_path, version)
+		WHERE (sd.updated_at, sd.package_path) > ($1, $2)
+		ORDER BY sd.updated_at, sd.package_path
+		LIMIT $3`
+	var docs []*SearchDocument
+	collect := func(rows *sql.Rows) error {
+		var (
+			d                              SearchDocument
+			licenseTypes                   []string
+			readmeFilePath, readmeContents string
+		)
+		if err := rows.Scan(&d.PackagePath, &d.ModulePath, &d.Version, &d.Name,
+			database.NullIsEmpty(&d.Synopsis), pq.Array(&licenseTypes), &d.CommitTime,
+			&d.ImportedByCount, &d.Rank, &d.UpdatedAt,
+			database.NullIsEmpty(&readmeFilePath), decompressed(&readmeContents)); err != nil {
+			return err
+		}
+		for _, l := range licenseTypes {
+			if l != "" {
+				d.Licenses = append(d.Licenses, l)
+			}
+		}
+		// As in UpsertSearchDocument, only use the README if the package and
+		// module have the same path.
+		if d.PackagePath != d.ModulePath {
+			readmeFilePath, readmeContents = "", ""
+		}
+		d.PathTokens = strings.Join(GeneratePathTokens(d.PackagePath), " ")
+		d.SectionB, d.SectionC, d.SectionD = SearchDocumentSections(d.Synopsis, readmeFilePath, readmeContents)
+		docs = append(docs, &d)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, since, afterPath, limit); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// CompleteSearchResults prepares search results from another search backend
+// for display. Each result must have its PackagePath, ModulePath and Version
+// set. CompleteSearchResults adds the package information that is not stored
+// in the search backend, and removes results whose package is no longer in the
+// database, is excluded, or may not be seen by the user.
+func (db *DB) CompleteSearchResults(ctx context.Context, results []*internal.SearchResult) (_ []*internal.SearchResult, err error) {
+	defer derrors.Wrap(&err, "CompleteSearchResults(ctx, %d results)", len(results))
+
+	if err := db.addPackageDataToSearchResults(ctx, results); err != nil {
+		return nil, err
+	}
+	var found []*internal.SearchResult
+	for _, r := range results {
+		// Every package has a name, so an empty name means that the package
+		// was not found.
+		if r.Name != "" {
+			found = append(found, r)
+		}
+	}
+	return db.filterSearchResults(ctx, found)
+}
@@ -135,6 +135,24 @@ func TestPathTokens(t *testing.T) {
 				"k8s",
 			},
 		},
+		{
+			path: "github.com/foo/go_redis/HTTPServer",
+			want: []string{
+				"HTTPServer",
+				"foo",
+				"foo/go_redis",
+				"foo/go_redis/HTTPServer",
+				"github.com/foo",
+				"github.com/foo/go_redis",
+				"github.com/foo/go_redis/HTTPServer",
+				"go",
+				"go_redis",
+				"go_redis/HTTPServer",
+				"http",
+				"redis",
+				"server",
+			},
+		},
 		{
 			path: "/",
 			want: nil,
@@ -842,6 +860,26 @@ func TestUpdateSearchDocumentsImportedByCount(t *testing.T) {
 		updateImportedByCount()
 		sdA := validateImportedByCountAndGetSearchDocument(pkgPath(mA), 2)
 		sdC := validateImportedByCountAndGetSearchDocument(pkgPath(mC), 0)
+		for _, m := range []*internal.Module{mA, mC} {
+			want := sdA.importedByCount
+			if m == mC {
+				want = 0
+			}
+			got, err := testDB.GetImportedByCount(ctx, pkgPath(m), m.ModulePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("GetImportedByCount(%q) = %d, want %d", pkgPath(m), got, want)
+			}
+		}
+		counts, err := testDB.GetImportedByCounts(ctx, mA.ModulePath, []string{pkgPath(mA), pkgPath(mC)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(map[string]int{pkgPath(mA): 2}, counts); diff != "" {
+			t.Errorf("GetImportedByCounts mismatch (-want +got):\n%s", diff)
+		}
 
 		// Nothing imports C, so it has never been updated.
 		if !sdC.importedByCountUpdatedAt.IsZero() {
@@ -1060,3 +1098,31 @@ func TestDeleteOlderVersionFromSearch(t *testing.T) {
 	insert(mod)
 	check(mod)
 }
+
+func TestSearchReadme(t *testing.T) {
+	// Verify that a package can be found by a word deep in its README, even
+	// though its synopsis does not mention it.
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	m := sample.Module("example.com/terse", sample.VersionString, "")
+	m.LegacyPackages[0].Synopsis = "Package terse."
+	m.LegacyReadmeFilePath = "README.md"
+	m.LegacyReadmeContents = "# Terse\n\nThis is the first sentence. " +
+		strings.Repeat("Some filler words. ", 30) + "It also speaks websockets."
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	results, err := testDB.Search(ctx, "websockets", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].PackagePath != "example.com/terse" {
+		var got []string
+		for _, r := range results {
+			got = append(got, r.PackagePath)
+		}
+		t.Errorf("got %v, want [example.com/terse]", got)
+	}
+}
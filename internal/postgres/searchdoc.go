@@ -13,8 +13,10 @@ import (
 )
 
 const (
-	maxSectionWords   = 50
-	maxReadmeFraction = 0.5
+	maxSectionWords = 50
+	// maxReadmeWords limits the D section, so that long READMEs do not make
+	// tsvectors too large to store or rank quickly.
+	maxReadmeWords = 500
 )
 
 // SearchDocumentSections computes the B and C sections of a Postgres search
@@ -24,16 +26,16 @@ const (
 //
 // The B section consists of the synopsis.
 // The C section consists of the first sentence of the README.
-// The D section consists of the remainder of the README.
+// The D section consists of the remainder of the README, so that a package
+// can be found by what its README says even if its synopsis is terse.
 // All sections are split into words and processed for replacements.
-// Each section is limited to maxSectionWords words, and in addition the
-// D section is limited to an initial fraction of the README, determined
-// by maxReadmeFraction.
+// The B and C sections are limited to maxSectionWords words, and the D
+// section to maxReadmeWords words.
 func SearchDocumentSections(synopsis, readmeFilename, readme string) (b, c, d string) {
-	return searchDocumentSections(synopsis, readmeFilename, readme, maxSectionWords, maxReadmeFraction)
+	return searchDocumentSections(synopsis, readmeFilename, readme, maxSectionWords, maxReadmeWords)
 }
 
-func searchDocumentSections(synopsis, readmeFilename, readme string, maxSecWords int, maxReadmeFrac float64) (b, c, d string) {
+func searchDocumentSections(synopsis, readmeFilename, readme string, maxSecWords, maxDWords int) (b, c, d string) {
 	var readmeFirst, readmeRest string
 	if isMarkdown(readmeFilename) {
 		readme = processMarkdown(readme)
@@ -51,13 +53,7 @@ func searchDocumentSections(synopsis, readmeFilename, readme string, maxSecWords
 	sectionC, rwfd := split(rwf, maxSecWords)
 	// section D is the part of the readme that is not in sectionC.
 	rwd := append(rwfd, rwr...)
-	// Keep maxSecWords of section D, but not more than maxReadmeFrac.
-	f := int(maxReadmeFrac * float64(len(rwd)))
-	nkeep := maxSecWords
-	if nkeep > f {
-		nkeep = f
-	}
-	sectionD, _ := split(rwd, nkeep)
+	sectionD, _ := split(rwd, maxDWords)
 
 	// If there is no synopsis, use first sentence of the README.
 	// But do not promote the rest of the README to section C.
@@ -108,15 +104,60 @@ func sentenceEndIndex(s string) int {
 }
 
 // processWords splits s into words at whitespace, then processes each word.
+// The words of CamelCase identifiers are added after the identifier, so that
+// NewHTTPClient matches a search for "http client".
 func processWords(s string) []string {
-	fields := strings.Fields(strings.ToLower(s))
 	var words []string
-	for _, f := range fields {
-		words = append(words, processWord(f)...)
+	for _, f := range strings.Fields(s) {
+		words = append(words, processWord(strings.ToLower(f))...)
+		words = append(words, identifierWords(f)...)
+	}
+	return words
+}
+
+// identifierWords returns the processed, lower-case words of the CamelCase
+// identifiers in s. It returns nil if s contains no CamelCase identifiers.
+func identifierWords(s string) []string {
+	notIdent := func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }
+	var words []string
+	for _, f := range strings.FieldsFunc(s, notIdent) {
+		for _, w := range splitCamelCase(f) {
+			words = append(words, processWord(strings.ToLower(w))...)
+		}
 	}
 	return words
 }
 
+// splitCamelCase splits s into the words of a CamelCase identifier. For
+// example, it splits NewHTTPClient into New, HTTP and Client. It returns nil
+// if s is a single word.
+func splitCamelCase(s string) []string {
+	rs := []rune(s)
+	var (
+		words []string
+		start int
+	)
+	for i := 1; i < len(rs); i++ {
+		// A word starts at an upper-case letter that follows a lower-case
+		// letter or a digit, as in "newHTTP" or "utf8Decoder", or that
+		// follows an upper-case letter and precedes a lower-case one, as in
+		// "HTTPClient".
+		if !unicode.IsUpper(rs[i]) {
+			continue
+		}
+		prev := rs[i-1]
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+			(unicode.IsUpper(prev) && i+1 < len(rs) && unicode.IsLower(rs[i+1])) {
+			words = append(words, string(rs[start:i]))
+			start = i
+		}
+	}
+	if start == 0 {
+		return nil
+	}
+	return append(words, string(rs[start:]))
+}
+
 // summaryReplacements is used to replace words with other words.
 // It is used by processWord, below.
 // Example key-value pairs:
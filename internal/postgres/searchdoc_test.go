@@ -26,7 +26,7 @@ func TestSearchDocumentSections(t *testing.T) {
 
 			"this is a synopsis",
 			"package blackfriday is a markdown processor",
-			"that is all",
+			"that is all that it is",
 		},
 		{
 			"non-markdown",
@@ -36,7 +36,7 @@ func TestSearchDocumentSections(t *testing.T) {
 
 			"this synopsis is too long so",
 			"",
-			"this readme doesn't have a sentence",
+			"this readme doesn't have a sentence end so",
 		},
 		{
 			"viper",
@@ -55,10 +55,10 @@ Many Go projects are built using Viper including:`,
 
 			"go configuration with fangs", // first sentence of README promoted
 			"",
-			"many go projects are",
+			"many go projects are built using viper including",
 		},
 	} {
-		gotB, gotC, gotD := searchDocumentSections(test.synopsis, test.readmeFilename, test.readmeContents, 6, 0.5)
+		gotB, gotC, gotD := searchDocumentSections(test.synopsis, test.readmeFilename, test.readmeContents, 6, 8)
 		if gotB != test.wantB {
 			t.Errorf("%s, B: got %q, want %q", test.name, gotB, test.wantB)
 		}
@@ -85,6 +85,9 @@ func TestProcessWords(t *testing.T) {
 			"a", "postgres", "postgresql", "and", "nats", "server", "over", "http"}},
 		{"http://a-b-c.com full-text chart-parser", []string{
 			"http://a-b-c.com", "full-text", "chart-parser", "parser", "parse"}},
+		{"NewHTTPClient returns a PostgresDB.", []string{
+			"newhttpclient", "new", "http", "client", "returns", "a",
+			"postgresdb", "postgres", "postgresql", "db"}},
 	} {
 		got := processWords(test.in)
 		if !cmp.Equal(got, test.want) {
@@ -93,6 +96,27 @@ func TestProcessWords(t *testing.T) {
 	}
 }
 
+func TestSplitCamelCase(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"client", nil},
+		{"Client", nil},
+		{"HTTP", nil},
+		{"NewHTTPClient", []string{"New", "HTTP", "Client"}},
+		{"newClient", []string{"new", "Client"}},
+		{"UTF8Decoder", []string{"UTF8", "Decoder"}},
+		{"ServeHTTP", []string{"Serve", "HTTP"}},
+	} {
+		got := splitCamelCase(test.in)
+		if !cmp.Equal(got, test.want) {
+			t.Errorf("splitCamelCase(%q) = %#v, want %#v", test.in, got, test.want)
+		}
+	}
+}
+
 func TestProcessMarkdown(t *testing.T) {
 	const (
 		in = `
@@ -0,0 +1,242 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/similar"
+)
+
+const (
+	// maxSimilarPackages is the number of similar packages recorded for each
+	// package.
+	maxSimilarPackages = 5
+
+	// maxSimilarityCandidates bounds the number of candidates of each kind
+	// that a package is compared with.
+	maxSimilarityCandidates = 100
+)
+
+// ComputeSimilarPackages records the packages most similar to each of at most
+// limit packages whose similar packages were never computed, or were computed
+// before staleBefore, most imported packages first. It returns the number of
+// packages it computed the similar packages of.
+func (db *DB) ComputeSimilarPackages(ctx context.Context, staleBefore time.Time, limit int) (n int, err error) {
+	defer derrors.Wrap(&err, "ComputeSimilarPackages(ctx, %s, %d)", staleBefore, limit)
+
+	type target struct {
+		similar.Target
+		modulePath, name string
+	}
+	var targets []*target
+	collect := func(rows *sql.Rows) error {
+		var t target
+		if err := rows.Scan(&t.Path, &t.modulePath, &t.name, &t.Synopsis, &t.Importers); err != nil {
+			return err
+		}
+		targets = append(targets, &t)
+		return nil
+	}
+	query := `
+		SELECT package_path, module_path, name, COALESCE(synopsis, ''), imported_by_count
+		FROM search_documents
+		WHERE similar_computed_at IS NULL OR similar_computed_at < $1
+		ORDER BY similar_computed_at NULLS FIRST, imported_by_count DESC, package_path
+		LIMIT $2`
+	if err := db.db.RunQuery(ctx, query, collect, staleBefore, limit); err != nil {
+		return 0, err
+	}
+	for _, t := range targets {
+		if err := db.db.QueryRow(ctx, `
+			SELECT count(DISTINCT module_path)
+			FROM module_requires
+			WHERE required_path = $1`, t.modulePath).Scan(&t.Requirers); err != nil {
+			return n, err
+		}
+		cands, err := getSimilarityCandidates(ctx, db.db, t.Path, t.modulePath, t.name)
+		if err != nil {
+			return n, err
+		}
+		if err := updateSimilarPackages(ctx, db.db, t.Path, similar.Rank(&t.Target, cands, maxSimilarPackages)); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// getSimilarityCandidates returns the packages of modules other than
+// modulePath that may be similar to the package at pkgPath, whose name is
+// name: the packages most often imported along with it, the root packages of
+// the modules most often required along with its module, and the most
+// imported packages with the same name.
+func getSimilarityCandidates(ctx context.Context, db *database.DB, pkgPath, modulePath, name string) (_ []*similar.Candidate, err error) {
+	defer derrors.Wrap(&err, "getSimilarityCandidates(ctx, %q, %q, %q)", pkgPath, modulePath, name)
+
+	sharedImporters := map[string]int{}
+	collectImporters := func(rows *sql.Rows) error {
+		var (
+			path string
+			n    int
+		)
+		if err := rows.Scan(&path, &n); err != nil {
+			return err
+		}
+		sharedImporters[path] = n
+		return nil
+	}
+	query := `
+		SELECT i2.to_path, count(DISTINCT i1.from_path) AS shared
+		FROM imports_unique i1
+		INNER JOIN imports_unique i2
+		ON i2.from_path = i1.from_path AND i2.from_module_path = i1.from_module_path
+		WHERE i1.to_path = $1
+		AND i1.from_module_path <> $2
+		AND i2.to_path <> $1
+		GROUP BY i2.to_path
+		ORDER BY shared DESC, i2.to_path
+		LIMIT $3`
+	if err := db.RunQuery(ctx, query, collectImporters, pkgPath, modulePath, maxSimilarityCandidates); err != nil {
+		return nil, err
+	}
+
+	sharedRequirers := map[string]int{}
+	collectRequirers := func(rows *sql.Rows) error {
+		var (
+			path string
+			n    int
+		)
+		if err := rows.Scan(&path, &n); err != nil {
+			return err
+		}
+		sharedRequirers[path] = n
+		return nil
+	}
+	query = `
+		SELECT r2.required_path, count(DISTINCT r1.module_path) AS shared
+		FROM module_requires r1
+		INNER JOIN module_requires r2
+		ON r2.module_path = r1.module_path AND r2.version = r1.version
+		WHERE r1.required_path = $1
+		AND r2.required_path <> $1
+		GROUP BY r2.required_path
+		ORDER BY shared DESC, r2.required_path
+		LIMIT $2`
+	if err := db.RunQuery(ctx, query, collectRequirers, modulePath, maxSimilarityCandidates); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for p := range sharedImporters {
+		paths = append(paths, p)
+	}
+	var modulePaths []string
+	for p := range sharedRequirers {
+		modulePaths = append(modulePaths, p)
+	}
+	var cands []*similar.Candidate
+	collect := func(rows *sql.Rows) error {
+		var c similar.Candidate
+		if err := rows.Scan(&c.Path, &c.ModulePath, &c.Synopsis, &c.Importers, &c.Requirers); err != nil {
+			return err
+		}
+		c.SharedImporters = sharedImporters[c.Path]
+		c.SharedRequirers = sharedRequirers[c.ModulePath]
+		cands = append(cands, &c)
+		return nil
+	}
+	query = `
+		SELECT
+			sd.package_path,
+			sd.module_path,
+			COALESCE(sd.synopsis, ''),
+			sd.imported_by_count,
+			(SELECT count(DISTINCT r.module_path) FROM module_requires r WHERE r.required_path = sd.module_path)
+		FROM search_documents sd
+		WHERE sd.module_path <> $1
+		AND (
+			sd.package_path = ANY($2)
+			OR sd.package_path = ANY($3)
+			OR sd.package_path IN (
+				SELECT package_path
+				FROM search_documents
+				WHERE name = $4 AND module_path <> $1
+				ORDER BY imported_by_count DESC, package_path
+				LIMIT $5))`
+	if err := db.RunQuery(ctx, query, collect, modulePath, pq.Array(paths), pq.Array(modulePaths), name, maxSimilarityCandidates); err != nil {
+		return nil, err
+	}
+	return cands, nil
+}
+
+// updateSimilarPackages replaces the packages recorded as similar to the
+// package at pkgPath with pkgs.
+func updateSimilarPackages(ctx context.Context, db *database.DB, pkgPath string, pkgs []*similar.Package) error {
+	return db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM similar_packages WHERE package_path = $1`, pkgPath); err != nil {
+			return err
+		}
+		var values []interface{}
+		for _, p := range pkgs {
+			values = append(values, pkgPath, p.Path, p.Score)
+		}
+		if len(values) > 0 {
+			cols := []string{"package_path", "similar_path", "score"}
+			if err := tx.BulkInsert(ctx, "similar_packages", cols, values, database.OnConflictDoNothing); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(ctx, `
+			UPDATE search_documents
+			SET similar_computed_at = CURRENT_TIMESTAMP
+			WHERE package_path = $1`, pkgPath)
+		return err
+	})
+}
+
+// GetSimilarPackages returns the packages recorded as most similar to the
+// package at pkgPath, most similar first. Packages no longer in search are
+// left out, as are packages that are excluded or that the user in ctx may not
+// see. It returns no packages if none were computed.
+func (db *DB) GetSimilarPackages(ctx context.Context, pkgPath string) (_ []*similar.Package, err error) {
+	defer derrors.Wrap(&err, "GetSimilarPackages(ctx, %q)", pkgPath)
+
+	var pkgs []*similar.Package
+	collect := func(rows *sql.Rows) error {
+		var p similar.Package
+		if err := rows.Scan(&p.Path, &p.ModulePath, &p.Synopsis, &p.Score); err != nil {
+			return err
+		}
+		pkgs = append(pkgs, &p)
+		return nil
+	}
+	query := `
+		SELECT s.similar_path, sd.module_path, COALESCE(sd.synopsis, ''), s.score
+		FROM similar_packages s
+		INNER JOIN search_documents sd
+		ON sd.package_path = s.similar_path
+		WHERE s.package_path = $1
+		ORDER BY s.score DESC, s.similar_path`
+	if err := db.db.RunQuery(ctx, query, collect, pkgPath); err != nil {
+		return nil, err
+	}
+	var filtered []*similar.Package
+	for _, p := range pkgs {
+		ex, err := db.IsExcluded(ctx, p.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !ex && db.acl.Allowed(ctx, p.ModulePath) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered, nil
+}
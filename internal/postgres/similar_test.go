@@ -0,0 +1,87 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/similar"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestComputeSimilarPackages(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	insert := func(modulePath, synopsis string, imports ...string) {
+		t.Helper()
+		m := sample.Module(modulePath, sample.VersionString, "")
+		m.LegacyPackages[0].Synopsis = synopsis
+		m.LegacyPackages[0].Imports = imports
+		m.Requires = nil
+		for _, imp := range imports {
+			m.Requires = append(m.Requires, &internal.ModuleRequire{Path: imp, Version: sample.VersionString})
+		}
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	insert("a.com/zap", "Package zap is a fast, structured logger.")
+	insert("b.com/logrus", "Package logrus is a structured logger.")
+	insert("c.com/yaml", "Package yaml decodes YAML.")
+	insert("d.com/app", "Command app serves.", "a.com/zap", "b.com/logrus")
+	insert("e.com/app", "Command app serves too.", "a.com/zap", "b.com/logrus")
+	insert("f.com/app", "Command app parses.", "c.com/yaml")
+	if _, err := testDB.UpdateSearchDocumentsImportedByCount(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	n, err := testDB.ComputeSimilarPackages(ctx, start, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 6 {
+		t.Errorf("ComputeSimilarPackages computed %d packages, want 6", n)
+	}
+	got, err := testDB.GetSimilarPackages(ctx, "a.com/zap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*similar.Package{{Path: "b.com/logrus", ModulePath: "b.com/logrus", Synopsis: "Package logrus is a structured logger."}}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(similar.Package{}, "Score")); diff != "" {
+		t.Errorf("GetSimilarPackages mismatch (-want +got):\n%s", diff)
+	}
+	if got, err := testDB.GetSimilarPackages(ctx, "c.com/yaml"); err != nil || len(got) != 0 {
+		t.Errorf("GetSimilarPackages(c.com/yaml) = %v, %v; want none", got, err)
+	}
+
+	// Similar packages that the user may not see are left out.
+	db := New(testDB.Underlying())
+	db.SetACL(auth.NewACL(map[string][]string{"b.com": {"eng"}}))
+	if got, err := db.GetSimilarPackages(ctx, "a.com/zap"); err != nil || len(got) != 0 {
+		t.Errorf("GetSimilarPackages(a.com/zap) with an ACL = %v, %v; want none", got, err)
+	}
+	ectx := auth.NewContextWithUser(ctx, &auth.User{Email: "e@example.com", Groups: []string{"eng"}})
+	if got, err := db.GetSimilarPackages(ectx, "a.com/zap"); err != nil || len(got) != 1 {
+		t.Errorf("GetSimilarPackages(a.com/zap) as a member of eng = %v, %v; want one", got, err)
+	}
+
+	// Packages computed after staleBefore are not computed again.
+	n, err = testDB.ComputeSimilarPackages(ctx, start, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("ComputeSimilarPackages computed %d packages again, want 0", n)
+	}
+}
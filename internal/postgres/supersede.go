@@ -0,0 +1,149 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/pkgsite/internal/abuse"
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// SupersedePackage records that the package at pkgPath is superseded by the
+// package at supersededBy, replacing any earlier supersession of it, and
+// records the change by user in the audit log. The package is demoted in
+// search.
+//
+// Administrators supersede packages with Moderate.
+func (db *DB) SupersedePackage(ctx context.Context, pkgPath, supersededBy, user string) (err error) {
+	defer derrors.Wrap(&err, "SupersedePackage(ctx, %q, %q, %q)", pkgPath, supersededBy, user)
+
+	return db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		before, err := getSupersededBy(ctx, tx, pkgPath)
+		if err != nil && !errors.Is(err, derrors.NotFound) {
+			return err
+		}
+		if err := supersedePackage(ctx, tx, pkgPath, supersededBy, user); err != nil {
+			return err
+		}
+		return recordAuditEntry(ctx, tx, &audit.Entry{
+			Actor:  user,
+			Action: abuse.ActionSupersede,
+			Target: pkgPath,
+			Before: supersessionJSON(before),
+			After:  supersessionJSON(supersededBy),
+		})
+	})
+}
+
+// UnsupersedePackage removes the supersession of the package at pkgPath, and
+// records the change by user in the audit log. It returns an error that wraps
+// derrors.NotFound if the package is not superseded.
+func (db *DB) UnsupersedePackage(ctx context.Context, pkgPath, user string) (err error) {
+	defer derrors.Wrap(&err, "UnsupersedePackage(ctx, %q, %q)", pkgPath, user)
+
+	return db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		before, err := getSupersededBy(ctx, tx, pkgPath)
+		if err != nil {
+			return err
+		}
+		if err := unsupersedePackage(ctx, tx, pkgPath); err != nil {
+			return err
+		}
+		return recordAuditEntry(ctx, tx, &audit.Entry{
+			Actor:  user,
+			Action: abuse.ActionUnsupersede,
+			Target: pkgPath,
+			Before: supersessionJSON(before),
+		})
+	})
+}
+
+// GetSupersededBy returns the path of the package that supersedes the package
+// at pkgPath. It returns an error that wraps derrors.NotFound if the package
+// is not superseded.
+func (db *DB) GetSupersededBy(ctx context.Context, pkgPath string) (_ string, err error) {
+	defer derrors.Wrap(&err, "GetSupersededBy(ctx, %q)", pkgPath)
+	return getSupersededBy(ctx, db.db, pkgPath)
+}
+
+// GetSupersessions returns the supersessions of the packages of the module at
+// modulePath, or of all packages if modulePath is empty, sorted by package
+// path.
+func (db *DB) GetSupersessions(ctx context.Context, modulePath string) (_ []*abuse.Supersession, err error) {
+	defer derrors.Wrap(&err, "GetSupersessions(ctx, %q)", modulePath)
+
+	var sups []*abuse.Supersession
+	collect := func(rows *sql.Rows) error {
+		var s abuse.Supersession
+		if err := rows.Scan(&s.PackagePath, &s.SupersededBy, &s.CreatedBy, &s.CreatedAt); err != nil {
+			return err
+		}
+		sups = append(sups, &s)
+		return nil
+	}
+	query := `
+		SELECT package_path, superseded_by, created_by, created_at
+		FROM superseded_packages
+		WHERE $1 = '' OR package_path = $1 OR substr(package_path, 1, length($1) + 1) = $1 || '/'
+		ORDER BY package_path`
+	if err := db.db.RunQuery(ctx, query, collect, modulePath); err != nil {
+		return nil, err
+	}
+	return sups, nil
+}
+
+func getSupersededBy(ctx context.Context, db *database.DB, pkgPath string) (string, error) {
+	var path string
+	err := db.QueryRow(ctx, `SELECT superseded_by FROM superseded_packages WHERE package_path = $1`, pkgPath).Scan(&path)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%s: %w", pkgPath, derrors.NotFound)
+	}
+	return path, err
+}
+
+func supersedePackage(ctx context.Context, tx *database.DB, pkgPath, supersededBy, user string) error {
+	if pkgPath == "" || supersededBy == "" || user == "" {
+		return fmt.Errorf("missing package path, superseding path or user: %w", derrors.InvalidArgument)
+	}
+	if pkgPath == supersededBy {
+		return fmt.Errorf("%s cannot supersede itself: %w", pkgPath, derrors.InvalidArgument)
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO superseded_packages (package_path, superseded_by, created_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (package_path)
+		DO UPDATE SET
+			superseded_by = excluded.superseded_by,
+			created_by = excluded.created_by,
+			created_at = CURRENT_TIMESTAMP`,
+		pkgPath, supersededBy, user)
+	if err != nil {
+		return err
+	}
+	return updateSearchDocumentRank(ctx, tx, pkgPath)
+}
+
+func unsupersedePackage(ctx context.Context, tx *database.DB, pkgPath string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM superseded_packages WHERE package_path = $1`, pkgPath); err != nil {
+		return err
+	}
+	return updateSearchDocumentRank(ctx, tx, pkgPath)
+}
+
+// supersessionJSON returns the state of a supersession for the audit log,
+// or nil if the package is not superseded.
+func supersessionJSON(supersededBy string) json.RawMessage {
+	if supersededBy == "" {
+		return nil
+	}
+	return auditJSON(map[string]string{"superseded_by": supersededBy})
+}
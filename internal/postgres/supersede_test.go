@@ -0,0 +1,113 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal/abuse"
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestSupersedePackage(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	const (
+		owner = "owner@example.com"
+		admin = "admin@example.com"
+		old   = "github.com/pkg/errors/p"
+	)
+	if err := testDB.InsertModule(ctx, sample.Module("github.com/pkg/errors", sample.VersionString, "p")); err != nil {
+		t.Fatal(err)
+	}
+	rank := func() float64 {
+		t.Helper()
+		var r float64
+		if err := testDB.db.QueryRow(ctx, `SELECT rank FROM search_documents WHERE package_path = $1`, old).Scan(&r); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	before := rank()
+
+	if _, err := testDB.GetSupersededBy(ctx, old); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("GetSupersededBy before SupersedePackage: got %v, want NotFound", err)
+	}
+	if err := testDB.SupersedePackage(ctx, old, old, owner); !errors.Is(err, derrors.InvalidArgument) {
+		t.Errorf("superseding a package by itself: got %v, want InvalidArgument", err)
+	}
+	if err := testDB.SupersedePackage(ctx, old, "errors", owner); err != nil {
+		t.Fatal(err)
+	}
+	got, err := testDB.GetSupersededBy(ctx, old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "errors" {
+		t.Errorf("GetSupersededBy = %q, want %q", got, "errors")
+	}
+	if got, want := rank(), before*supersededPenalty; math.Abs(got-want) > 1e-6 {
+		t.Errorf("rank of a superseded package: got %f, want %f", got, want)
+	}
+	// Recomputing all ranks keeps the penalty.
+	if _, err := testDB.UpdateSearchDocumentsRank(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rank(), before*supersededPenalty; math.Abs(got-want) > 1e-6 {
+		t.Errorf("rank after UpdateSearchDocumentsRank: got %f, want %f", got, want)
+	}
+
+	// Administrators can change the supersession.
+	if err := testDB.Moderate(ctx, &abuse.Action{Actor: admin, Kind: abuse.ActionSupersede, Target: old, SupersededBy: "fmt"}); err != nil {
+		t.Fatal(err)
+	}
+	sups, err := testDB.GetSupersessions(ctx, "github.com/pkg/errors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*abuse.Supersession{{PackagePath: old, SupersededBy: "fmt", CreatedBy: admin}}
+	if diff := cmp.Diff(want, sups, cmpopts.IgnoreFields(abuse.Supersession{}, "CreatedAt")); diff != "" {
+		t.Errorf("GetSupersessions mismatch (-want +got):\n%s", diff)
+	}
+	if sups, err := testDB.GetSupersessions(ctx, "github.com/pkg/err"); err != nil || len(sups) != 0 {
+		t.Errorf("GetSupersessions of another module = %v, %v; want none", sups, err)
+	}
+
+	if err := testDB.UnsupersedePackage(ctx, old, owner); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDB.UnsupersedePackage(ctx, old, owner); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("UnsupersedePackage twice: got %v, want NotFound", err)
+	}
+	if got := rank(); math.Abs(got-before) > 1e-6 {
+		t.Errorf("rank after UnsupersedePackage: got %f, want %f", got, before)
+	}
+
+	entries, err := testDB.GetAuditLog(ctx, audit.Query{Target: old})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Actor+" "+e.Action)
+	}
+	wantActions := []string{
+		owner + " " + abuse.ActionUnsupersede,
+		admin + " " + abuse.ActionSupersede,
+		owner + " " + abuse.ActionSupersede,
+	}
+	if diff := cmp.Diff(wantActions, actions); diff != "" {
+		t.Errorf("audit log mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/fetch/dochtml"
+)
+
+// insertSymbols replaces the rows of the symbols table for the packages of
+// m with the symbols in their documentation. The symbols of packages that are
+// not redistributable are not inserted, because their documentation is not
+// shown.
+func insertSymbols(ctx context.Context, db *database.DB, m *internal.Module) (err error) {
+	defer derrors.Wrap(&err, "insertSymbols(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	if _, err := db.Exec(ctx, `DELETE FROM symbols WHERE module_path = $1 AND version = $2`,
+		m.ModulePath, m.Version); err != nil {
+		return err
+	}
+	var values []interface{}
+	for _, p := range m.LegacyPackages {
+		if !p.IsRedistributable {
+			continue
+		}
+		syms, err := dochtml.Symbols(p.DocumentationHTML)
+		if err != nil {
+			return err
+		}
+		importance, err := dochtml.SymbolImportance(p.DocumentationHTML)
+		if err != nil {
+			return err
+		}
+		for _, s := range syms {
+			values = append(values, p.Path, m.ModulePath, m.Version, s.Name, s.Kind, importance[s.Name])
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	cols := []string{"package_path", "module_path", "version", "name", "kind", "importance"}
+	return db.BulkInsert(ctx, "symbols", cols, values, database.OnConflictDoNothing)
+}
+
+// GetSymbols returns the symbols documented by the package at pkgPath, in
+// modulePath@version, sorted by name regardless of case. It returns no
+// symbols for packages that are not redistributable.
+func (db *DB) GetSymbols(ctx context.Context, pkgPath, modulePath, version string) (_ []*dochtml.Symbol, err error) {
+	defer derrors.Wrap(&err, "GetSymbols(ctx, %q, %q, %q)", pkgPath, modulePath, version)
+
+	if err := db.checkAccess(ctx, modulePath); err != nil {
+		return nil, err
+	}
+	var symbols []*dochtml.Symbol
+	collect := func(rows *sql.Rows) error {
+		var s dochtml.Symbol
+		if err := rows.Scan(&s.Name, &s.Kind); err != nil {
+			return err
+		}
+		symbols = append(symbols, &s)
+		return nil
+	}
+	query := `
+		SELECT name, kind
+		FROM symbols
+		WHERE package_path = $1 AND module_path = $2 AND version = $3
+		ORDER BY lower(name), name`
+	if err := db.db.RunQuery(ctx, query, collect, pkgPath, modulePath, version); err != nil {
+		return nil, err
+	}
+	return symbols, nil
+}
+
+// identifierPrefix starts a search query for the packages that export an
+// identifier.
+const identifierPrefix = "ident:"
+
+// IdentifierQuery reports whether the search query q is a search for the
+// packages that export an identifier, like "ident:Reader" or
+// "ident:Client.Do", and returns the identifier.
+func IdentifierQuery(q string) (ident string, ok bool) {
+	q = strings.TrimSpace(q)
+	if !strings.HasPrefix(q, identifierPrefix) {
+		return "", false
+	}
+	ident = strings.TrimSpace(strings.TrimPrefix(q, identifierPrefix))
+	for _, part := range strings.Split(ident, ".") {
+		if part == "" {
+			return "", false
+		}
+		for _, r := range part {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+				return "", false
+			}
+		}
+	}
+	return ident, true
+}
+
+// searchIdentifier returns the packages in search_documents that export
+// ident, in decreasing order of their search rank.
+func (db *DB) searchIdentifier(ctx context.Context, ident string, limit, offset int) (_ []*internal.SearchResult, err error) {
+	defer derrors.Wrap(&err, "searchIdentifier(ctx, %q, %d, %d)", ident, limit, offset)
+
+	query := `
+		SELECT
+			sd.package_path,
+			sd.module_path,
+			sd.version,
+			sd.commit_time,
+			sd.imported_by_count,
+			sd.rank,
+			COUNT(*) OVER() AS total
+		FROM symbols s
+		INNER JOIN search_documents sd
+		ON s.package_path = sd.package_path
+		AND s.module_path = sd.module_path
+		AND s.version = sd.version
+		WHERE s.name = $1
+		ORDER BY sd.rank DESC, sd.package_path
+		LIMIT $2
+		OFFSET $3`
+	var results []*internal.SearchResult
+	collect := func(rows *sql.Rows) error {
+		var r internal.SearchResult
+		if err := rows.Scan(&r.PackagePath, &r.ModulePath, &r.Version, &r.CommitTime,
+			&r.NumImportedBy, &r.Score, &r.NumResults); err != nil {
+			return err
+		}
+		results = append(results, &r)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, ident, limit, offset); err != nil {
+		return nil, err
+	}
+	if err := db.addPackageDataToSearchResults(ctx, results); err != nil {
+		return nil, err
+	}
+	return db.filterSearchResults(ctx, results)
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/fetch/dochtml"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestIdentifierQuery(t *testing.T) {
+	for _, test := range []struct {
+		q, want string
+		wantOK  bool
+	}{
+		{"ident:Reader", "Reader", true},
+		{" ident: Client.Do ", "Client.Do", true},
+		{"ident:", "", false},
+		{"ident:Client.", "", false},
+		{"ident:a b", "", false},
+		{"Reader", "", false},
+		{"identity", "", false},
+	} {
+		got, ok := IdentifierQuery(test.q)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("IdentifierQuery(%q) = %q, %t; want %q, %t", test.q, got, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestSearchIdentifier(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	docHTML := func(ids ...string) string {
+		var s string
+		for _, id := range ids {
+			s += `<h3 id="` + id + `" data-kind="type"></h3>`
+		}
+		return s
+	}
+	a := sample.Module("example.com/a", sample.VersionString, "pkg")
+	a.LegacyPackages[0].DocumentationHTML = docHTML("Reader", "Writer")
+	b := sample.Module("example.com/b", sample.VersionString, "pkg")
+	b.LegacyPackages[0].DocumentationHTML = docHTML("Reader")
+	c := sample.Module("example.com/c", sample.VersionString, "pkg")
+	c.LegacyPackages[0].DocumentationHTML = docHTML("Reader")
+	c.LegacyPackages[0].IsRedistributable = false
+	for _, m := range []*internal.Module{a, b, c} {
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, test := range []struct {
+		q    string
+		want []string
+	}{
+		{"ident:Reader", []string{"example.com/a/pkg", "example.com/b/pkg"}},
+		{"ident:Writer", []string{"example.com/a/pkg"}},
+		{"ident:Closer", nil},
+	} {
+		results, err := testDB.Search(ctx, test.q, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, r := range results {
+			got = append(got, r.PackagePath)
+			if r.Name != "pkg" || int(r.NumResults) != len(test.want) {
+				t.Errorf("%s: got name %q, %d results; want %q, %d", test.q, r.Name, r.NumResults, "pkg", len(test.want))
+			}
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("%s: mismatch (-want +got):\n%s", test.q, diff)
+		}
+	}
+}
+
+func TestSearchResultSymbols(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	m := sample.Module("example.com/a", sample.VersionString, "pkg")
+	m.LegacyPackages[0].DocumentationHTML = `
+		<h3 id="Client" data-kind="type"></h3><p>A Client is a client of the service.</p>
+		<h3 id="Client.Do" data-kind="method"></h3><p>Do sends a request.</p>
+		<h3 id="New" data-kind="function"></h3><pre>func New() *<a href="#Client">Client</a></pre><p>New returns a Client.</p>
+		<h3 id="Open" data-kind="function"></h3><pre>func Open() *<a href="#Client">Client</a></pre>
+		<h3 id="Unused" data-kind="function"></h3>`
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	results, err := testDB.Search(ctx, "ident:Client", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	want := []string{"Client", "New", "Client.Do"}
+	if diff := cmp.Diff(want, results[0].Symbols); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetSymbols(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	m := sample.Module("example.com/a", sample.VersionString, "pkg")
+	m.LegacyPackages[0].DocumentationHTML = `
+		<h3 id="open" data-kind="function"></h3>
+		<h3 id="Client" data-kind="type"></h3>
+		<h3 id="Client.Do" data-kind="method"></h3>`
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	got, err := testDB.GetSymbols(ctx, "example.com/a/pkg", "example.com/a", sample.VersionString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*dochtml.Symbol{
+		{Name: "Client", Kind: "type"},
+		{Name: "Client.Do", Kind: "method"},
+		{Name: "open", Kind: "function"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// TeeproxyEvent is a godoc.org request received by the teeproxy, along with
+// the result of replaying that request against pkg.go.dev.
+type TeeproxyEvent struct {
+	GddoPath        string
+	PkgGoDevPath    string
+	GddoStatus      int
+	PkgGoDevStatus  int
+	GddoLatency     time.Duration
+	PkgGoDevLatency time.Duration
+	IsRobot         bool
+	CreatedAt       time.Time
+}
+
+// TeeproxyStats summarizes the teeproxy events recorded since a given time.
+type TeeproxyStats struct {
+	// Total is the number of events.
+	Total int
+	// StatusMismatches is the number of events for which the godoc.org and
+	// pkg.go.dev statuses differ.
+	StatusMismatches int
+	// Median and 90th percentile latencies of each site.
+	GddoLatencyP50, GddoLatencyP90         time.Duration
+	PkgGoDevLatencyP50, PkgGoDevLatencyP90 time.Duration
+}
+
+// InsertTeeproxyEvent inserts a row into the teeproxy_events table.
+func (db *DB) InsertTeeproxyEvent(ctx context.Context, e *TeeproxyEvent) (err error) {
+	defer derrors.Wrap(&err, "DB.InsertTeeproxyEvent(ctx, %q)", e.GddoPath)
+
+	_, err = db.db.Exec(ctx,
+		`INSERT INTO teeproxy_events (
+			gddo_path,
+			pkggodev_path,
+			gddo_status,
+			pkggodev_status,
+			gddo_latency_ms,
+			pkggodev_latency_ms,
+			is_robot
+		) VALUES ($1, $2, $3, $4, $5, $6, $7);`,
+		e.GddoPath, e.PkgGoDevPath, e.GddoStatus, e.PkgGoDevStatus,
+		e.GddoLatency.Milliseconds(), e.PkgGoDevLatency.Milliseconds(), e.IsRobot)
+	return err
+}
+
+// GetTeeproxyDivergences returns up to limit of the most recent teeproxy
+// events created after since, for which the godoc.org and pkg.go.dev statuses
+// differ.
+func (db *DB) GetTeeproxyDivergences(ctx context.Context, since time.Time, limit int) (_ []*TeeproxyEvent, err error) {
+	defer derrors.Wrap(&err, "DB.GetTeeproxyDivergences(ctx, %v, %d)", since, limit)
+
+	query := `
+		SELECT
+			gddo_path,
+			pkggodev_path,
+			gddo_status,
+			pkggodev_status,
+			gddo_latency_ms,
+			pkggodev_latency_ms,
+			is_robot,
+			created_at
+		FROM teeproxy_events
+		WHERE
+			created_at > $1
+			AND gddo_status != pkggodev_status
+		ORDER BY created_at DESC
+		LIMIT $2;`
+	var events []*TeeproxyEvent
+	err = db.db.RunQuery(ctx, query, func(rows *sql.Rows) error {
+		var (
+			e                     TeeproxyEvent
+			gddoMillis, pkgMillis int64
+		)
+		if err := rows.Scan(&e.GddoPath, &e.PkgGoDevPath, &e.GddoStatus, &e.PkgGoDevStatus,
+			&gddoMillis, &pkgMillis, &e.IsRobot, &e.CreatedAt); err != nil {
+			return err
+		}
+		e.GddoLatency = time.Duration(gddoMillis) * time.Millisecond
+		e.PkgGoDevLatency = time.Duration(pkgMillis) * time.Millisecond
+		events = append(events, &e)
+		return nil
+	}, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetTeeproxyStats returns a summary of the teeproxy events created after
+// since.
+func (db *DB) GetTeeproxyStats(ctx context.Context, since time.Time) (_ *TeeproxyStats, err error) {
+	defer derrors.Wrap(&err, "DB.GetTeeproxyStats(ctx, %v)", since)
+
+	query := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE gddo_status != pkggodev_status),
+			COALESCE(percentile_disc(0.5) WITHIN GROUP (ORDER BY gddo_latency_ms), 0),
+			COALESCE(percentile_disc(0.9) WITHIN GROUP (ORDER BY gddo_latency_ms), 0),
+			COALESCE(percentile_disc(0.5) WITHIN GROUP (ORDER BY pkggodev_latency_ms), 0),
+			COALESCE(percentile_disc(0.9) WITHIN GROUP (ORDER BY pkggodev_latency_ms), 0)
+		FROM teeproxy_events
+		WHERE created_at > $1;`
+	var (
+		stats                  TeeproxyStats
+		gddo50, gddo90         int64
+		pkgGoDev50, pkgGoDev90 int64
+	)
+	if err := db.db.QueryRow(ctx, query, since).Scan(&stats.Total, &stats.StatusMismatches,
+		&gddo50, &gddo90, &pkgGoDev50, &pkgGoDev90); err != nil {
+		return nil, err
+	}
+	stats.GddoLatencyP50 = time.Duration(gddo50) * time.Millisecond
+	stats.GddoLatencyP90 = time.Duration(gddo90) * time.Millisecond
+	stats.PkgGoDevLatencyP50 = time.Duration(pkgGoDev50) * time.Millisecond
+	stats.PkgGoDevLatencyP90 = time.Duration(pkgGoDev90) * time.Millisecond
+	return &stats, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestTeeproxyEvents(t *testing.T) {
+	defer ResetTestDB(testDB, t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	events := []*TeeproxyEvent{
+		{
+			GddoPath:        "/net/http",
+			PkgGoDevPath:    "/net/http",
+			GddoStatus:      200,
+			PkgGoDevStatus:  200,
+			GddoLatency:     100 * time.Millisecond,
+			PkgGoDevLatency: 300 * time.Millisecond,
+		},
+		{
+			GddoPath:        "/-/bot",
+			PkgGoDevPath:    "/404",
+			GddoStatus:      200,
+			PkgGoDevStatus:  404,
+			GddoLatency:     10 * time.Millisecond,
+			PkgGoDevLatency: 20 * time.Millisecond,
+			IsRobot:         true,
+		},
+	}
+	for _, e := range events {
+		if err := testDB.InsertTeeproxyEvent(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	since := time.Now().Add(-time.Hour)
+	got, err := testDB.GetTeeproxyDivergences(ctx, since, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*TeeproxyEvent{events[1]}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(TeeproxyEvent{}, "CreatedAt")); diff != "" {
+		t.Errorf("GetTeeproxyDivergences mismatch (-want +got):\n%s", diff)
+	}
+
+	stats, err := testDB.GetTeeproxyStats(ctx, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Total != 2 || stats.StatusMismatches != 1 {
+		t.Errorf("GetTeeproxyStats: got total=%d, mismatches=%d; want total=2, mismatches=1",
+			stats.Total, stats.StatusMismatches)
+	}
+}
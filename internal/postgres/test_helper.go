@@ -108,7 +108,23 @@ func ResetTestDB(db *DB, t *testing.T) {
 			TRUNCATE modules CASCADE;
 			TRUNCATE version_map;
 			TRUNCATE imports_unique;
-			TRUNCATE experiments;`); err != nil {
+			TRUNCATE imported_by_counts;
+			TRUNCATE package_checkpoints;
+			TRUNCATE experiments;
+			TRUNCATE teeproxy_events;
+			TRUNCATE lookalikes;
+			TRUNCATE abuse_reports;
+			TRUNCATE admin_actions;
+			TRUNCATE webhooks;
+			TRUNCATE webhook_deliveries;
+			TRUNCATE watchlists;
+			TRUNCATE api_keys CASCADE;
+			TRUNCATE external_signals;
+			TRUNCATE repository_metadata;
+			TRUNCATE module_verifications;
+			TRUNCATE superseded_packages;
+			TRUNCATE similar_packages;
+			TRUNCATE collections CASCADE;`); err != nil {
 			return err
 		}
 		if _, err := tx.Exec(ctx, `TRUNCATE module_version_states CASCADE;`); err != nil {
@@ -0,0 +1,77 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// insertUsageSnippets replaces the usage snippets of the packages of m with
+// their Usages fields. The source of packages that are not redistributable
+// must not be shown, so their snippets are not stored.
+func insertUsageSnippets(ctx context.Context, db *database.DB, m *internal.Module) (err error) {
+	defer derrors.Wrap(&err, "insertUsageSnippets(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	if _, err := db.Exec(ctx, `DELETE FROM usage_snippets WHERE module_path = $1 AND version = $2`,
+		m.ModulePath, m.Version); err != nil {
+		return err
+	}
+	var values []interface{}
+	for _, p := range m.LegacyPackages {
+		if !p.IsRedistributable {
+			continue
+		}
+		for _, u := range p.Usages {
+			values = append(values, p.Path, m.ModulePath, m.Version, u.ImportPath, makeValidUnicode(u.Code), u.URL)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	cols := []string{"path", "module_path", "version", "import_path", "code", "url"}
+	return db.BulkInsert(ctx, "usage_snippets", cols, values, database.OnConflictDoNothing)
+}
+
+// GetUsageSnippets returns at most limit snippets that call the package at
+// importPath, from the latest versions of the packages of other modules than
+// modulePath that import it, most imported importers first. Snippets from
+// modules that the user in ctx may not see are left out, so there may be fewer
+// than limit even if there are more importers.
+func (db *DB) GetUsageSnippets(ctx context.Context, importPath, modulePath string, limit int) (_ []*internal.UsageSnippet, err error) {
+	defer derrors.Wrap(&err, "GetUsageSnippets(ctx, %q, %q, %d)", importPath, modulePath, limit)
+
+	var snippets []*internal.UsageSnippet
+	collect := func(rows *sql.Rows) error {
+		var (
+			u                  internal.UsageSnippet
+			importerModulePath string
+		)
+		if err := rows.Scan(&u.PackagePath, &importerModulePath, &u.ImportPath, &u.Code, &u.URL); err != nil {
+			return err
+		}
+		// The code of modules the user may not see is not shown.
+		if db.acl.Allowed(ctx, importerModulePath) {
+			snippets = append(snippets, &u)
+		}
+		return nil
+	}
+	query := `
+		SELECT u.path, u.module_path, u.import_path, u.code, u.url
+		FROM usage_snippets u
+		INNER JOIN search_documents sd
+		ON sd.package_path = u.path AND sd.module_path = u.module_path AND sd.version = u.version
+		WHERE u.import_path = $1 AND u.module_path <> $2
+		ORDER BY sd.imported_by_count DESC, u.path
+		LIMIT $3`
+	if err := db.db.RunQuery(ctx, query, collect, importPath, modulePath, limit); err != nil {
+		return nil, err
+	}
+	return snippets, nil
+}
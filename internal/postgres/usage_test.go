@@ -0,0 +1,116 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestGetUsageSnippets(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	const (
+		errorsModule = "github.com/pkg/errors"
+		errorsPath   = errorsModule + "/p"
+	)
+	insert := func(modulePath string, redistributable bool, imports ...string) {
+		t.Helper()
+		m := sample.Module(modulePath, sample.VersionString, "p")
+		p := m.LegacyPackages[0]
+		p.IsRedistributable = redistributable
+		p.Imports = imports
+		for _, imp := range imports {
+			p.Usages = append(p.Usages, &internal.UsageSnippet{ImportPath: imp, Code: "use(" + modulePath + ")"})
+		}
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	insert(errorsModule, true)
+	insert("a.com/m", true, errorsPath)
+	insert("b.com/m", true, errorsPath)
+	insert("c.com/m", true, "b.com/m/p")
+	insert("d.com/m", false, errorsPath)
+	if _, err := testDB.UpdateSearchDocumentsImportedByCount(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := testDB.GetUsageSnippets(ctx, "a.com/m/p", "a.com/m", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d snippets for a package that is not called, want none", len(got))
+	}
+	got, err = testDB.GetUsageSnippets(ctx, errorsPath, errorsModule, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The most imported importer comes first, and the source of packages
+	// that are not redistributable is not shown.
+	want := []*internal.UsageSnippet{
+		{PackagePath: "b.com/m/p", ImportPath: errorsPath, Code: "use(b.com/m)"},
+		{PackagePath: "a.com/m/p", ImportPath: errorsPath, Code: "use(a.com/m)"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if got, err := testDB.GetUsageSnippets(ctx, errorsPath, errorsModule, 1); err != nil || len(got) != 1 || got[0].PackagePath != "b.com/m/p" {
+		t.Errorf("GetUsageSnippets with limit 1 = %v, %v; want the snippet of b.com/m/p", got, err)
+	}
+}
+
+func TestGetUsageSnippetsACL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	const importPath = "public.com/lib/p"
+	for _, modulePath := range []string{"public.com/lib", "public.com/app", "corp.com/secret"} {
+		m := sample.Module(modulePath, sample.VersionString, "p")
+		if modulePath != "public.com/lib" {
+			p := m.LegacyPackages[0]
+			p.Imports = []string{importPath}
+			p.Usages = []*internal.UsageSnippet{{ImportPath: importPath, Code: "use(" + modulePath + ")"}}
+		}
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := New(testDB.Underlying())
+	db.SetACL(auth.NewACL(map[string][]string{"corp.com": {"eng"}}))
+	eng := auth.NewContextWithUser(ctx, &auth.User{Email: "e@corp.com", Groups: []string{"eng"}})
+
+	for _, test := range []struct {
+		name string
+		ctx  context.Context
+		want []string
+	}{
+		{"anonymous", ctx, []string{"public.com/app/p"}},
+		{"allowed", eng, []string{"corp.com/secret/p", "public.com/app/p"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			snippets, err := db.GetUsageSnippets(test.ctx, importPath, "public.com/lib", 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, s := range snippets {
+				got = append(got, s.PackagePath)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
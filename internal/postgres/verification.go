@@ -0,0 +1,109 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/verify"
+)
+
+// StartVerification records that the user with the given email address asks
+// to be verified as an owner of modulePath, with token. It returns an error
+// that wraps derrors.NotFound if there is no such module. If the user already
+// asked, the request made before is kept, with its token.
+func (db *DB) StartVerification(ctx context.Context, email, modulePath, token string) (err error) {
+	defer derrors.Wrap(&err, "StartVerification(ctx, %q, %q)", email, modulePath)
+
+	if email == "" || token == "" {
+		return fmt.Errorf("missing email or token: %w", derrors.InvalidArgument)
+	}
+	var exists bool
+	err = db.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM modules WHERE module_path = $1)`, modulePath).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", modulePath, derrors.NotFound)
+	}
+	_, err = db.db.Exec(ctx, `
+		INSERT INTO module_verifications (module_path, email, token)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING`,
+		modulePath, email, token)
+	return err
+}
+
+// GetVerification returns the request of the user with the given email
+// address to be verified as an owner of modulePath. It returns an error that
+// wraps derrors.NotFound if there is none.
+func (db *DB) GetVerification(ctx context.Context, email, modulePath string) (_ *verify.Verification, err error) {
+	defer derrors.Wrap(&err, "GetVerification(ctx, %q, %q)", email, modulePath)
+
+	var (
+		v          = verify.Verification{ModulePath: modulePath, Email: email}
+		verifiedAt pq.NullTime
+	)
+	err = db.db.QueryRow(ctx, `
+		SELECT token, method, verified_at, created_at
+		FROM module_verifications
+		WHERE module_path = $1 AND email = $2`,
+		modulePath, email).Scan(&v.Token, database.NullIsEmpty(&v.Method), &verifiedAt, &v.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s by %s: %w", modulePath, email, derrors.NotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	v.VerifiedAt = verifiedAt.Time
+	return &v, nil
+}
+
+// MarkVerified records that the user with the given email address proved
+// control of modulePath with method. It returns an error that wraps
+// derrors.NotFound if the user did not ask to be verified.
+func (db *DB) MarkVerified(ctx context.Context, email, modulePath, method string) (err error) {
+	defer derrors.Wrap(&err, "MarkVerified(ctx, %q, %q, %q)", email, modulePath, method)
+
+	if method != verify.MethodFile && method != verify.MethodDNS {
+		return fmt.Errorf("unknown method %q: %w", method, derrors.InvalidArgument)
+	}
+	res, err := db.db.Exec(ctx, `
+		UPDATE module_verifications
+		SET method = $3, verified_at = CURRENT_TIMESTAMP
+		WHERE module_path = $1 AND email = $2`,
+		modulePath, email, method)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%s by %s: %w", modulePath, email, derrors.NotFound)
+	}
+	return nil
+}
+
+// IsVerifiedModule reports whether a user was verified as an owner of
+// modulePath.
+func (db *DB) IsVerifiedModule(ctx context.Context, modulePath string) (_ bool, err error) {
+	defer derrors.Wrap(&err, "IsVerifiedModule(ctx, %q)", modulePath)
+
+	var verified bool
+	err = db.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM module_verifications
+			WHERE module_path = $1 AND verified_at IS NOT NULL
+		)`, modulePath).Scan(&verified)
+	return verified, err
+}
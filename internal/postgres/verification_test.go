@@ -0,0 +1,81 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/testing/sample"
+	"golang.org/x/pkgsite/internal/verify"
+)
+
+func TestVerification(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	const (
+		alice = "alice@example.com"
+		mod   = "example.com/a"
+	)
+	if err := testDB.InsertModule(ctx, sample.Module(mod, "v1.0.0", "p")); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDB.StartVerification(ctx, alice, "example.com/unknown", "tok"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("StartVerification of an unknown module: got %v, want NotFound", err)
+	}
+	if _, err := testDB.GetVerification(ctx, alice, mod); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("GetVerification before StartVerification: got %v, want NotFound", err)
+	}
+	if err := testDB.MarkVerified(ctx, alice, mod, verify.MethodDNS); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("MarkVerified before StartVerification: got %v, want NotFound", err)
+	}
+
+	if err := testDB.StartVerification(ctx, alice, mod, "tok"); err != nil {
+		t.Fatal(err)
+	}
+	// Asking again keeps the first token.
+	if err := testDB.StartVerification(ctx, alice, mod, "other"); err != nil {
+		t.Fatal(err)
+	}
+	v, err := testDB.GetVerification(ctx, alice, mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Token != "tok" || v.Verified() || !v.VerifiedAt.IsZero() {
+		t.Errorf("got %+v, want unverified with token %q", v, "tok")
+	}
+	verified, err := testDB.IsVerifiedModule(ctx, mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified {
+		t.Error("IsVerifiedModule before MarkVerified = true, want false")
+	}
+
+	if err := testDB.MarkVerified(ctx, alice, mod, "carrier-pigeon"); !errors.Is(err, derrors.InvalidArgument) {
+		t.Errorf("MarkVerified with an unknown method: got %v, want InvalidArgument", err)
+	}
+	if err := testDB.MarkVerified(ctx, alice, mod, verify.MethodDNS); err != nil {
+		t.Fatal(err)
+	}
+	v, err = testDB.GetVerification(ctx, alice, mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Method != verify.MethodDNS || v.VerifiedAt.IsZero() {
+		t.Errorf("got %+v, want verified by DNS", v)
+	}
+	verified, err = testDB.IsVerifiedModule(ctx, mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified {
+		t.Error("IsVerifiedModule after MarkVerified = false, want true")
+	}
+}
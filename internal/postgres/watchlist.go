@@ -0,0 +1,146 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/watchlist"
+)
+
+// Watch adds modulePath to the watchlist of the user with the given email
+// address. It returns an error that wraps derrors.NotFound if there is no
+// such module, or the user in ctx may not see it. Watching a module twice is
+// not an error.
+//
+// The groups of the user in ctx are recorded for all the modules the user
+// watches, so that summaries only list modules the user may still see.
+func (db *DB) Watch(ctx context.Context, email, modulePath string) (err error) {
+	defer derrors.Wrap(&err, "Watch(ctx, %q, %q)", email, modulePath)
+
+	if email == "" {
+		return fmt.Errorf("missing email: %w", derrors.InvalidArgument)
+	}
+	if err := db.checkAccess(ctx, modulePath); err != nil {
+		return err
+	}
+	var exists bool
+	err = db.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM modules WHERE module_path = $1)`, modulePath).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", modulePath, derrors.NotFound)
+	}
+	groups := []string{}
+	if u := auth.UserFromContext(ctx); u != nil && u.Groups != nil {
+		groups = u.Groups
+	}
+	return db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO watchlists (email, module_path)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING`,
+			email, modulePath); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `UPDATE watchlists SET groups = $2 WHERE email = $1`, email, pq.Array(groups))
+		return err
+	})
+}
+
+// Unwatch removes modulePath from the watchlist of the user with the given
+// email address.
+func (db *DB) Unwatch(ctx context.Context, email, modulePath string) (err error) {
+	defer derrors.Wrap(&err, "Unwatch(ctx, %q, %q)", email, modulePath)
+
+	_, err = db.db.Exec(ctx, `DELETE FROM watchlists WHERE email = $1 AND module_path = $2`, email, modulePath)
+	return err
+}
+
+// GetWatchlist returns the modules watched by the user with the given email
+// address, sorted by path.
+func (db *DB) GetWatchlist(ctx context.Context, email string) (_ []*watchlist.Watch, err error) {
+	defer derrors.Wrap(&err, "GetWatchlist(ctx, %q)", email)
+
+	var watches []*watchlist.Watch
+	collect := func(rows *sql.Rows) error {
+		var w watchlist.Watch
+		if err := rows.Scan(&w.ModulePath, &w.CreatedAt); err != nil {
+			return err
+		}
+		watches = append(watches, &w)
+		return nil
+	}
+	query := `
+		SELECT module_path, created_at
+		FROM watchlists
+		WHERE email = $1
+		ORDER BY module_path`
+	if err := db.db.RunQuery(ctx, query, collect, email); err != nil {
+		return nil, err
+	}
+	return watches, nil
+}
+
+// GetWatchlistSummaries returns, for each user with updates, the versions of
+// watched modules inserted after the user started watching the module and
+// after the last summary sent to the user, up to until. Summaries are sorted
+// by email address, and their updates by module path and version. Each
+// summary has the groups recorded by the last call to Watch for the user, so
+// that the caller can check them against an ACL.
+func (db *DB) GetWatchlistSummaries(ctx context.Context, until time.Time) (_ []*watchlist.Summary, err error) {
+	defer derrors.Wrap(&err, "GetWatchlistSummaries(ctx, %s)", until)
+
+	var summaries []*watchlist.Summary
+	collect := func(rows *sql.Rows) error {
+		var (
+			email  string
+			groups []string
+			u      watchlist.Update
+		)
+		if err := rows.Scan(&email, pq.Array(&groups), &u.ModulePath, &u.Version, &u.CommitTime); err != nil {
+			return err
+		}
+		if len(summaries) == 0 || summaries[len(summaries)-1].Email != email {
+			s := &watchlist.Summary{Email: email}
+			if len(groups) > 0 {
+				s.Groups = groups
+			}
+			summaries = append(summaries, s)
+		}
+		s := summaries[len(summaries)-1]
+		s.Updates = append(s.Updates, &u)
+		return nil
+	}
+	// GREATEST ignores NULLs, so notified_at only counts once it is set.
+	query := `
+		SELECT w.email, w.groups, m.module_path, m.version, m.commit_time
+		FROM watchlists w
+		INNER JOIN modules m ON m.module_path = w.module_path
+		WHERE m.created_at > GREATEST(w.created_at, w.notified_at)
+		AND m.created_at <= $1
+		ORDER BY w.email, m.module_path, m.sort_version`
+	if err := db.db.RunQuery(ctx, query, collect, until); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// MarkWatchlistNotified records that the user with the given email address
+// was sent a summary of the updates up to until.
+func (db *DB) MarkWatchlistNotified(ctx context.Context, email string, until time.Time) (err error) {
+	defer derrors.Wrap(&err, "MarkWatchlistNotified(ctx, %q, %s)", email, until)
+
+	_, err = db.db.Exec(ctx, `UPDATE watchlists SET notified_at = $2 WHERE email = $1`, email, until)
+	return err
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/testing/sample"
+	"golang.org/x/pkgsite/internal/watchlist"
+)
+
+func TestWatchlist(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	const (
+		alice = "alice@example.com"
+		bob   = "bob@example.com"
+	)
+	for _, m := range []string{"example.com/a", "example.com/b"} {
+		if err := testDB.InsertModule(ctx, sample.Module(m, "v1.0.0", "p")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, w := range []struct{ email, modulePath string }{
+		{alice, "example.com/a"},
+		{alice, "example.com/a"}, // watching twice is not an error
+		{alice, "example.com/b"},
+		{bob, "example.com/b"},
+	} {
+		if err := testDB.Watch(ctx, w.email, w.modulePath); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := testDB.Watch(ctx, alice, "example.com/unknown"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("Watch of an unknown module: got %v, want NotFound", err)
+	}
+	if err := testDB.Unwatch(ctx, bob, "example.com/b"); err != nil {
+		t.Fatal(err)
+	}
+
+	watches, err := testDB.GetWatchlist(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, w := range watches {
+		paths = append(paths, w.ModulePath)
+	}
+	if diff := cmp.Diff([]string{"example.com/a", "example.com/b"}, paths); diff != "" {
+		t.Errorf("GetWatchlist mismatch (-want +got):\n%s", diff)
+	}
+
+	// v1.0.0 was inserted before the modules were watched, so it is not an
+	// update.
+	for _, v := range []string{"v1.2.0", "v1.1.0"} {
+		if err := testDB.InsertModule(ctx, sample.Module("example.com/a", v, "p")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	until := time.Now().Add(time.Minute)
+	got, err := testDB.GetWatchlistSummaries(ctx, until)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*watchlist.Summary{{
+		Email: alice,
+		Updates: []*watchlist.Update{
+			{ModulePath: "example.com/a", Version: "v1.1.0", CommitTime: sample.CommitTime},
+			{ModulePath: "example.com/a", Version: "v1.2.0", CommitTime: sample.CommitTime},
+		},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetWatchlistSummaries mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := testDB.MarkWatchlistNotified(ctx, alice, until); err != nil {
+		t.Fatal(err)
+	}
+	got, err = testDB.GetWatchlistSummaries(ctx, until)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("GetWatchlistSummaries after notifying: got %d summaries, want none", len(got))
+	}
+}
+
+func TestWatchlistACL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	for _, m := range []string{"public.com/a", "corp.com/secret"} {
+		if err := testDB.InsertModule(ctx, sample.Module(m, "v1.0.0", "p")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := New(testDB.Underlying())
+	db.SetACL(auth.NewACL(map[string][]string{"corp.com": {"eng"}}))
+
+	const alice = "alice@example.com"
+	actx := auth.NewContextWithUser(ctx, &auth.User{Email: alice})
+	// A restricted module is indistinguishable from one that does not exist.
+	if err := db.Watch(actx, alice, "corp.com/secret"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("Watch of a restricted module: got %v, want NotFound", err)
+	}
+
+	const bob = "bob@example.com"
+	bctx := auth.NewContextWithUser(ctx, &auth.User{Email: bob, Groups: []string{"eng"}})
+	for _, m := range []string{"public.com/a", "corp.com/secret"} {
+		if err := db.Watch(bctx, bob, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := testDB.InsertModule(ctx, sample.Module("corp.com/secret", "v1.1.0", "p")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.GetWatchlistSummaries(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*watchlist.Summary{{
+		Email:  bob,
+		Groups: []string{"eng"},
+		Updates: []*watchlist.Update{
+			{ModulePath: "corp.com/secret", Version: "v1.1.0", CommitTime: sample.CommitTime},
+		},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetWatchlistSummaries mismatch (-want +got):\n%s", diff)
+	}
+}
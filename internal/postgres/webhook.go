@@ -0,0 +1,135 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/webhook"
+)
+
+// InsertWebhook registers h, and records its registration by actor in the
+// audit log. It returns the ID of the new webhook. The ID, CreatedBy and
+// CreatedAt fields of h are ignored.
+func (db *DB) InsertWebhook(ctx context.Context, h *webhook.Hook, actor string) (id int64, err error) {
+	defer derrors.Wrap(&err, "InsertWebhook(ctx, %q, %q, %q)", h.Prefix, h.URL, actor)
+
+	if err := h.Validate(); err != nil {
+		return 0, fmt.Errorf("%v: %w", err, derrors.InvalidArgument)
+	}
+	err = db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO webhooks (prefix, url, secret, created_by)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id`,
+			h.Prefix, h.URL, h.Secret, actor).Scan(&id)
+		if err != nil {
+			return err
+		}
+		return recordAuditEntry(ctx, tx, &audit.Entry{
+			Actor:  actor,
+			Action: audit.ActionAddWebhook,
+			Target: strconv.FormatInt(id, 10),
+			After:  webhookJSON(h),
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// DeleteWebhook deletes the webhook with the given ID, and records its
+// deletion by actor in the audit log. It returns an error that wraps
+// derrors.NotFound if there is no such webhook.
+func (db *DB) DeleteWebhook(ctx context.Context, id int64, actor string) (err error) {
+	defer derrors.Wrap(&err, "DeleteWebhook(ctx, %d, %q)", id, actor)
+
+	return db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		var h webhook.Hook
+		err := tx.QueryRow(ctx, `DELETE FROM webhooks WHERE id = $1 RETURNING prefix, url`, id).Scan(&h.Prefix, &h.URL)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("webhook %d: %w", id, derrors.NotFound)
+		}
+		if err != nil {
+			return err
+		}
+		return recordAuditEntry(ctx, tx, &audit.Entry{
+			Actor:  actor,
+			Action: audit.ActionDeleteWebhook,
+			Target: strconv.FormatInt(id, 10),
+			Before: webhookJSON(&h),
+		})
+	})
+}
+
+// webhookJSON describes h in the audit log, without its secret.
+func webhookJSON(h *webhook.Hook) json.RawMessage {
+	return auditJSON(map[string]string{"prefix": h.Prefix, "url": h.URL})
+}
+
+// GetWebhooks returns all registered webhooks, including their secrets,
+// oldest first.
+func (db *DB) GetWebhooks(ctx context.Context) (_ []*webhook.Hook, err error) {
+	defer derrors.Wrap(&err, "GetWebhooks(ctx)")
+
+	var hooks []*webhook.Hook
+	collect := func(rows *sql.Rows) error {
+		var h webhook.Hook
+		if err := rows.Scan(&h.ID, &h.Prefix, &h.URL, &h.Secret, &h.CreatedBy, &h.CreatedAt); err != nil {
+			return err
+		}
+		hooks = append(hooks, &h)
+		return nil
+	}
+	query := `
+		SELECT id, prefix, url, secret, created_by, created_at
+		FROM webhooks
+		ORDER BY id`
+	if err := db.db.RunQuery(ctx, query, collect); err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// GetWebhookDeliveries returns the set of webhook URLs that were notified of
+// modulePath@version.
+func (db *DB) GetWebhookDeliveries(ctx context.Context, modulePath, version string) (_ map[string]bool, err error) {
+	defer derrors.Wrap(&err, "GetWebhookDeliveries(ctx, %q, %q)", modulePath, version)
+
+	urls := map[string]bool{}
+	collect := func(rows *sql.Rows) error {
+		var u string
+		if err := rows.Scan(&u); err != nil {
+			return err
+		}
+		urls[u] = true
+		return nil
+	}
+	query := `SELECT url FROM webhook_deliveries WHERE module_path = $1 AND version = $2`
+	if err := db.db.RunQuery(ctx, query, collect, modulePath, version); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// RecordWebhookDelivery records that url was notified of modulePath@version.
+func (db *DB) RecordWebhookDelivery(ctx context.Context, url, modulePath, version string) (err error) {
+	defer derrors.Wrap(&err, "RecordWebhookDelivery(ctx, %q, %q, %q)", url, modulePath, version)
+
+	_, err = db.db.Exec(ctx, `
+		INSERT INTO webhook_deliveries (url, module_path, version)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING`,
+		url, modulePath, version)
+	return err
+}
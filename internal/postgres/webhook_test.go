@@ -0,0 +1,90 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/webhook"
+)
+
+func TestWebhooks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	const actor = "a@example.com"
+	h := &webhook.Hook{Prefix: "github.com/a", URL: "https://bot.example/hook", Secret: "s"}
+	id, err := testDB.InsertWebhook(ctx, h, actor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testDB.InsertWebhook(ctx, &webhook.Hook{Prefix: "github.com/a"}, actor); !errors.Is(err, derrors.InvalidArgument) {
+		t.Errorf("InsertWebhook without a URL: got %v, want InvalidArgument", err)
+	}
+
+	got, err := testDB.GetWebhooks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*webhook.Hook{{ID: id, Prefix: h.Prefix, URL: h.URL, Secret: h.Secret, CreatedBy: actor}}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(webhook.Hook{}, "CreatedAt")); diff != "" {
+		t.Errorf("GetWebhooks mismatch (-want +got):\n%s", diff)
+	}
+
+	const modulePath, version = "github.com/a/b", "v1.0.0"
+	for i := 0; i < 2; i++ {
+		// Recording a delivery twice is not an error.
+		if err := testDB.RecordWebhookDelivery(ctx, h.URL, modulePath, version); err != nil {
+			t.Fatal(err)
+		}
+	}
+	delivered, err := testDB.GetWebhookDeliveries(ctx, modulePath, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]bool{h.URL: true}, delivered); diff != "" {
+		t.Errorf("GetWebhookDeliveries mismatch (-want +got):\n%s", diff)
+	}
+	delivered, err = testDB.GetWebhookDeliveries(ctx, modulePath, "v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delivered) != 0 {
+		t.Errorf("GetWebhookDeliveries for another version = %v, want none", delivered)
+	}
+
+	if err := testDB.DeleteWebhook(ctx, id, actor); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDB.DeleteWebhook(ctx, id, actor); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("DeleteWebhook twice: got %v, want NotFound", err)
+	}
+	got, err = testDB.GetWebhooks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("GetWebhooks after delete = %v, want none", got)
+	}
+
+	entries, err := testDB.GetAuditLog(ctx, audit.Query{Actor: actor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	if diff := cmp.Diff([]string{audit.ActionDeleteWebhook, audit.ActionAddWebhook}, actions); diff != "" {
+		t.Errorf("audit log mismatch (-want +got):\n%s", diff)
+	}
+}
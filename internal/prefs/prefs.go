@@ -0,0 +1,140 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package prefs keeps the preferences of users, like the tab that package
+// pages open on, in a signed cookie.
+package prefs
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	cookieName = "pkgsite-prefs"
+
+	// cookieMaxAge is how long preferences are kept after they are saved.
+	cookieMaxAge = 2 * 365 * 24 * time.Hour
+)
+
+// Themes are the themes users can choose. The empty theme follows the
+// settings of the browser.
+var Themes = []string{"light", "dark"}
+
+// Prefs are the preferences of a user. The zero value has no preferences.
+type Prefs struct {
+	// Tab is the tab package pages open on when their URL has none, like
+	// "overview".
+	Tab string `json:"tab,omitempty"`
+	// GOOS is the platform whose documentation the user would rather read,
+	// like "windows".
+	GOOS string `json:"goos,omitempty"`
+	// Theme is one of Themes, or empty.
+	Theme string `json:"theme,omitempty"`
+}
+
+// A Store reads and writes preferences in a cookie signed with a key, so that
+// only values written by a Store with the same key are read back.
+type Store struct {
+	key []byte
+}
+
+// NewStore returns a Store that signs cookies with key. If key is empty, it
+// returns nil, which is a Store that has no preferences and cannot save any.
+func NewStore(key []byte) *Store {
+	if len(key) == 0 {
+		return nil
+	}
+	return &Store{key: key}
+}
+
+// Read returns the preferences in the cookie of r, or nil if it has none, or
+// the cookie is not signed by s.
+func (s *Store) Read(r *http.Request) *Prefs {
+	if s == nil {
+		return nil
+	}
+	c, err := r.Cookie(cookieName)
+	if err != nil {
+		return nil
+	}
+	i := strings.LastIndex(c.Value, ".")
+	if i < 0 {
+		return nil
+	}
+	payload := c.Value[:i]
+	mac, err := base64.RawURLEncoding.DecodeString(c.Value[i+1:])
+	if err != nil || !hmac.Equal(mac, s.mac(payload)) {
+		return nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil
+	}
+	var p Prefs
+	if err := json.Unmarshal(data, &p); err != nil || p == (Prefs{}) {
+		return nil
+	}
+	return &p
+}
+
+// Write saves p in a cookie of the response w. If p has no preferences, the
+// cookie is removed. Write does nothing if s is nil.
+func (s *Store) Write(w http.ResponseWriter, p *Prefs) {
+	if s == nil {
+		return
+	}
+	if p == nil || *p == (Prefs{}) {
+		setCookie(w, "", -1)
+		return
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		// Prefs only has strings.
+		panic(err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	setCookie(w, payload+"."+base64.RawURLEncoding.EncodeToString(s.mac(payload)), cookieMaxAge)
+}
+
+func (s *Store) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+func setCookie(w http.ResponseWriter, value string, maxAge time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying p, the preferences of the user of a
+// request.
+func NewContext(ctx context.Context, p *Prefs) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the preferences carried by ctx. It returns the zero
+// Prefs if ctx has none.
+func FromContext(ctx context.Context) Prefs {
+	if p, ok := ctx.Value(contextKey{}).(*Prefs); ok && p != nil {
+		return *p
+	}
+	return Prefs{}
+}
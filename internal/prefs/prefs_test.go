@@ -0,0 +1,71 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package prefs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// roundTrip writes p with s, and reads it back with r.
+func roundTrip(s, r *Store, p *Prefs) *Prefs {
+	w := httptest.NewRecorder()
+	s.Write(w, p)
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, c := range w.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	return r.Read(req)
+}
+
+func TestStore(t *testing.T) {
+	s := NewStore([]byte("key"))
+	want := &Prefs{Tab: "overview", GOOS: "windows", Theme: "dark"}
+	if diff := cmp.Diff(want, roundTrip(s, s, want)); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if got := roundTrip(s, NewStore([]byte("other key")), want); got != nil {
+		t.Errorf("read with another key: got %+v, want nil", got)
+	}
+	if got := roundTrip(s, s, &Prefs{}); got != nil {
+		t.Errorf("no preferences: got %+v, want nil", got)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: cookieName, Value: "eyJ0YWIiOiJkb2MifQ.bad"})
+	if got := s.Read(r); got != nil {
+		t.Errorf("bad signature: got %+v, want nil", got)
+	}
+}
+
+func TestNilStore(t *testing.T) {
+	s := NewStore(nil)
+	if s != nil {
+		t.Fatal("NewStore(nil) != nil")
+	}
+	w := httptest.NewRecorder()
+	s.Write(w, &Prefs{Tab: "doc"})
+	if got := w.Header().Get("Set-Cookie"); got != "" {
+		t.Errorf("Set-Cookie = %q, want none", got)
+	}
+	if got := s.Read(httptest.NewRequest("GET", "/", nil)); got != nil {
+		t.Errorf("Read = %+v, want nil", got)
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	if got := FromContext(ctx); got != (Prefs{}) {
+		t.Errorf("FromContext(empty) = %+v, want zero", got)
+	}
+	want := Prefs{Tab: "doc"}
+	if got := FromContext(NewContext(ctx, &want)); got != want {
+		t.Errorf("FromContext = %+v, want %+v", got, want)
+	}
+}
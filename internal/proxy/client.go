@@ -16,52 +16,330 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
+	"path"
+	"regexp"
 	"strings"
 	"time"
 
 	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/plugin/ochttp/propagation/tracecontext"
 	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 	"golang.org/x/net/context/ctxhttp"
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/derrors"
 )
 
-// A Client is used by the fetch service to communicate with a module
-// proxy. It handles all methods defined by go help goproxy.
+// A Client is used by the fetch service to communicate with module
+// proxies. It handles all methods defined by go help goproxy.
 type Client struct {
-	// URL of the module proxy web server
-	url string
+	// proxies are the module proxies tried in turn for modules that match
+	// no route.
+	proxies []*server
+
+	// routes are the proxies used for modules matching patterns, instead of
+	// proxies.
+	routes []*route
 
 	// client used for HTTP requests. It is mutable for testing purposes.
 	httpClient *http.Client
+
+	// header holds headers added to every request.
+	header http.Header
+
+	// netrc holds the credentials for proxy hosts.
+	netrc []netrcLine
+
+	// sum verifies downloaded files against a checksum database. It is nil
+	// if files are not verified.
+	sum *sumChecker
+}
+
+// A server is a single module proxy.
+type server struct {
+	// URL of the module proxy web server
+	url string
+
+	// isFile reports whether the proxy is a directory in the local file
+	// system, in the layout of GOPROXY=file:// (like the module download
+	// cache). Such a proxy has no @latest endpoint.
+	isFile bool
+
+	// isOff reports whether this is the "off" entry of a proxy list, which
+	// disallows fetching.
+	isOff bool
+
+	// fallBackOnError reports whether the next proxy in the list is tried
+	// after any error from this one, not only after a not found error, as
+	// with a "|" separator in GOPROXY.
+	fallBackOnError bool
+}
+
+// A route is a list of proxies for the modules whose paths match patterns.
+type route struct {
+	patterns string
+	proxies  []*server
+}
+
+// Config configures a Client.
+type Config struct {
+	// URL is the list of module proxies to use, in the syntax of GOPROXY:
+	// proxy URLs separated by "," or "|". The next proxy is tried after a
+	// not found error with ",", and after any error with "|". The URLs may
+	// use the https or file schemes. The list may end with "off", to fail
+	// instead of trying more proxies.
+	URL string
+
+	// Routes lists proxies to use for some modules instead of those in
+	// URL. It is a list of PATTERNS=LIST entries separated by ";". PATTERNS
+	// is a comma-separated list of glob patterns of module path prefixes,
+	// with the syntax of GONOPROXY, and LIST is a proxy list like URL. The
+	// first entry whose patterns match the module path is used.
+	Routes string
+
+	// Netrc is the contents of a .netrc file, holding credentials for
+	// proxy hosts. Credentials in proxy URLs take precedence.
+	Netrc string
+
+	// Header holds extra headers sent with every request to a proxy, as
+	// "Name: value" lines.
+	Header string
+
+	// SumDB is the checksum database that downloaded go.mod and zip files
+	// are verified against, in the syntax of GOSUMDB. If it is empty or
+	// "off", files are not verified.
+	SumDB string
+
+	// NoSumCheck is a comma-separated list of glob patterns of module path
+	// prefixes, with the syntax of GONOSUMDB, of modules that are not
+	// verified.
+	NoSumCheck string
 }
 
 // A VersionInfo contains metadata about a given version of a module.
 type VersionInfo struct {
 	Version string
 	Time    time.Time
+	// Origin is where the proxy got the version from. Not all proxies
+	// report it.
+	Origin *internal.Origin `json:",omitempty"`
 }
 
 // New constructs a *Client using the provided rawurl, which is expected to
-// be an absolute URI that can be directly passed to http.Get.
+// be an absolute URI that can be directly passed to http.Get, or a file://
+// URL of a directory laid out like a module proxy, for running without
+// network access. It may also be a list of URLs in the syntax of GOPROXY;
+// see Config.URL.
 func New(rawurl string) (_ *Client, err error) {
-	derrors.Wrap(&err, "proxy.New(%q)", rawurl)
+	return NewWithConfig(Config{URL: rawurl})
+}
+
+// NewWithConfig constructs a *Client for the proxies described by cfg.
+func NewWithConfig(cfg Config) (_ *Client, err error) {
+	defer derrors.Wrap(&err, "proxy.NewWithConfig(%q)", redactURL(cfg.URL))
+
+	c := &Client{
+		httpClient: &http.Client{Transport: newTransport()},
+		netrc:      parseNetrc(cfg.Netrc),
+	}
+	c.proxies, err = parseProxyList(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range strings.Split(cfg.Routes, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		i := strings.Index(entry, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("route %q: want PATTERNS=LIST", redactURL(entry))
+		}
+		proxies, err := parseProxyList(entry[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		c.routes = append(c.routes, &route{patterns: strings.TrimSpace(entry[:i]), proxies: proxies})
+	}
+	c.header, err = parseHeader(cfg.Header)
+	if err != nil {
+		return nil, err
+	}
+	c.sum, err = newSumChecker(cfg.SumDB, cfg.NoSumCheck)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newTransport returns a transport that can make requests to both https and
+// file URLs.
+func newTransport() http.RoundTripper {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+	return &ochttp.Transport{Base: t, Propagation: &tracecontext.HTTPFormat{}}
+}
+
+// parseProxyList parses a list of proxies in the syntax of GOPROXY.
+func parseProxyList(list string) (_ []*server, err error) {
+	defer derrors.Wrap(&err, "parseProxyList(%q)", redactURL(list))
+
+	var proxies []*server
+	for list != "" {
+		var (
+			rawurl          string
+			fallBackOnError bool
+		)
+		if i := strings.IndexAny(list, ",|"); i >= 0 {
+			rawurl = list[:i]
+			fallBackOnError = list[i] == '|'
+			list = list[i+1:]
+		} else {
+			rawurl = list
+			list = ""
+		}
+		rawurl = strings.TrimSpace(rawurl)
+		if rawurl == "" {
+			continue
+		}
+		if rawurl == "off" {
+			proxies = append(proxies, &server{isOff: true})
+			break
+		}
+		s, err := newServer(rawurl)
+		if err != nil {
+			return nil, err
+		}
+		s.fallBackOnError = fallBackOnError
+		proxies = append(proxies, s)
+	}
+	if len(proxies) == 0 {
+		return nil, errors.New("no proxy URL")
+	}
+	return proxies, nil
+}
+
+func newServer(rawurl string) (*server, error) {
+	if rawurl == "direct" {
+		return nil, errors.New(`"direct" is not supported; only module proxies are`)
+	}
 	url, err := url.Parse(rawurl)
 	if err != nil {
 		return nil, fmt.Errorf("url.Parse: %v", err)
 	}
-	if url.Scheme != "https" {
-		return nil, fmt.Errorf("scheme must be https (got %s)", url.Scheme)
-	}
 	cleanURL := strings.TrimRight(rawurl, "/")
-	return &Client{url: cleanURL, httpClient: &http.Client{Transport: &ochttp.Transport{}}}, nil
+	switch url.Scheme {
+	case "https":
+		return &server{url: cleanURL}, nil
+	case "file":
+		if url.Host != "" || !strings.HasPrefix(url.Path, "/") {
+			return nil, fmt.Errorf("file URL must have an absolute path and no host (got %s)", rawurl)
+		}
+		return &server{url: cleanURL, isFile: true}, nil
+	default:
+		return nil, fmt.Errorf("scheme must be https or file (got %s)", url.Scheme)
+	}
+}
+
+// parseHeader parses "Name: value" lines.
+func parseHeader(s string) (http.Header, error) {
+	h := http.Header{}
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i < 0 {
+			// Don't show the line: it may hold a secret.
+			return nil, errors.New("header lines must have the form Name: value")
+		}
+		h.Add(strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]))
+	}
+	return h, nil
+}
+
+// redactURL replaces the passwords in the URLs in s with "xxxxx", so that s
+// can be logged.
+func redactURL(s string) string {
+	return passwordRegexp.ReplaceAllString(s, "${1}xxxxx@")
+}
+
+var passwordRegexp = regexp.MustCompile(`(://[^/:@]*:)[^/@]*@`)
+
+// proxiesFor returns the proxies to use for modulePath.
+func (c *Client) proxiesFor(modulePath string) []*server {
+	for _, r := range c.routes {
+		if matchPrefixPatterns(r.patterns, modulePath) {
+			return r.proxies
+		}
+	}
+	return c.proxies
+}
+
+// tryProxies calls f with each proxy for modulePath in turn, until f
+// succeeds or fails with an error after which the next proxy should not be
+// tried. It returns the last error from f.
+func (c *Client) tryProxies(modulePath string, f func(s *server) error) error {
+	var err error
+	for _, s := range c.proxiesFor(modulePath) {
+		if s.isOff {
+			return fmt.Errorf("module lookup disabled by proxy list: %w", derrors.NotFound)
+		}
+		err = f(s)
+		if err == nil || !(s.fallBackOnError || errors.Is(err, derrors.NotFound)) {
+			return err
+		}
+	}
+	return err
+}
+
+// matchPrefixPatterns reports whether any path prefix of target matches one
+// of the glob patterns in the comma-separated globs list, like GONOPROXY.
+func matchPrefixPatterns(globs, target string) bool {
+	for _, glob := range strings.Split(globs, ",") {
+		glob = strings.TrimSuffix(strings.TrimSpace(glob), "/")
+		if glob == "" {
+			continue
+		}
+		// Truncate target after as many path elements as the pattern has.
+		n := strings.Count(glob, "/")
+		prefix := target
+		for i := 0; i < len(target); i++ {
+			if target[i] == '/' {
+				if n == 0 {
+					prefix = target[:i]
+					break
+				}
+				n--
+			}
+		}
+		if n > 0 {
+			// Not enough path elements.
+			continue
+		}
+		if matched, _ := path.Match(glob, prefix); matched {
+			return true
+		}
+	}
+	return false
 }
 
 // GetInfo makes a request to $GOPROXY/<module>/@v/<requestedVersion>.info and
 // transforms that data into a *VersionInfo.
 func (c *Client) GetInfo(ctx context.Context, modulePath, requestedVersion string) (_ *VersionInfo, err error) {
 	defer derrors.Wrap(&err, "proxy.Client.GetInfo(%q, %q)", modulePath, requestedVersion)
-	data, err := c.readBody(ctx, modulePath, requestedVersion, "info")
+	var data []byte
+	err = c.tryProxies(modulePath, func(s *server) (err error) {
+		version := requestedVersion
+		if version == internal.LatestVersion && s.isFile {
+			version, err = c.latestFromList(ctx, s, modulePath)
+			if err != nil {
+				return err
+			}
+		}
+		data, err = c.readBody(ctx, s, modulePath, version, "info")
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -72,10 +350,51 @@ func (c *Client) GetInfo(ctx context.Context, modulePath, requestedVersion strin
 	return &v, nil
 }
 
+// latestFromList returns the latest version in the list of versions of
+// modulePath, preferring releases to prereleases, as the go command does
+// when a proxy does not support @latest.
+func (c *Client) latestFromList(ctx context.Context, s *server, modulePath string) (string, error) {
+	versions, err := c.listVersions(ctx, s, modulePath)
+	if err != nil {
+		return "", err
+	}
+	var latest string
+	for _, v := range versions {
+		if !semver.IsValid(v) {
+			continue
+		}
+		isRelease := semver.Prerelease(v) == ""
+		latestIsRelease := latest != "" && semver.Prerelease(latest) == ""
+		switch {
+		case latest == "",
+			isRelease && !latestIsRelease,
+			isRelease == latestIsRelease && semver.Compare(v, latest) > 0:
+			latest = v
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no versions of %q: %w", modulePath, derrors.NotFound)
+	}
+	return latest, nil
+}
+
 // GetMod makes a request to $GOPROXY/<module>/@v/<resolvedVersion>.mod and returns the raw data.
 func (c *Client) GetMod(ctx context.Context, modulePath, resolvedVersion string) (_ []byte, err error) {
 	defer derrors.Wrap(&err, "proxy.Client.GetMod(%q, %q)", modulePath, resolvedVersion)
-	return c.readBody(ctx, modulePath, resolvedVersion, "mod")
+	var data []byte
+	err = c.tryProxies(modulePath, func(s *server) (err error) {
+		data, err = c.readBody(ctx, s, modulePath, resolvedVersion, "mod")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if c.sum != nil {
+		if err := c.sum.checkMod(modulePath, resolvedVersion, data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
 }
 
 // GetZip makes a request to $GOPROXY/<path>/@v/<resolvedVersion>.zip and transforms
@@ -89,7 +408,11 @@ func (c *Client) GetZip(ctx context.Context, requestedPath, requestedVersion str
 	if err != nil {
 		return nil, err
 	}
-	bodyBytes, err := c.readBody(ctx, requestedPath, info.Version, "zip")
+	var bodyBytes []byte
+	err = c.tryProxies(requestedPath, func(s *server) (err error) {
+		bodyBytes, err = c.readBody(ctx, s, requestedPath, info.Version, "zip")
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -97,10 +420,82 @@ func (c *Client) GetZip(ctx context.Context, requestedPath, requestedVersion str
 	if err != nil {
 		return nil, fmt.Errorf("zip.NewReader: %v", err)
 	}
+	if c.sum != nil {
+		if err := c.sum.checkZip(requestedPath, info.Version, zipReader); err != nil {
+			return nil, err
+		}
+	}
 	return zipReader, nil
 }
 
-func (c *Client) escapedURL(modulePath, version, suffix string) (_ string, err error) {
+// MaxZipSize is the size of the largest module zip that GetZipFile
+// downloads. It is the limit imposed by the go command.
+const MaxZipSize = 500 << 20
+
+// A ZipFile is a module zip stored in a temporary file.
+type ZipFile struct {
+	*zip.Reader
+	f *os.File
+}
+
+// Close closes and removes the temporary file of z.
+func (z *ZipFile) Close() error {
+	err := z.f.Close()
+	if rerr := os.Remove(z.f.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// GetZipFile is like GetZip, but it streams the zip to a temporary file
+// instead of holding it in memory, so that large modules can be processed
+// with little memory. Zips larger than MaxZipSize are rejected. The caller
+// must close the returned ZipFile.
+func (c *Client) GetZipFile(ctx context.Context, requestedPath, requestedVersion string) (_ *ZipFile, err error) {
+	defer derrors.Wrap(&err, "proxy.Client.GetZipFile(ctx, %q, %q)", requestedPath, requestedVersion)
+
+	info, err := c.GetInfo(ctx, requestedPath, requestedVersion)
+	if err != nil {
+		return nil, err
+	}
+	f, err := ioutil.TempFile("", "module-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	z := &ZipFile{f: f}
+	defer func() {
+		if err != nil {
+			z.Close()
+		}
+	}()
+	var size int64
+	err = c.tryProxies(requestedPath, func(s *server) (err error) {
+		// Discard what an earlier proxy wrote.
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		size, err = c.copyBody(ctx, s, requestedPath, info.Version, "zip", f, MaxZipSize)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	z.Reader, err = zip.NewReader(f, size)
+	if err != nil {
+		return nil, fmt.Errorf("zip.NewReader: %v", err)
+	}
+	if c.sum != nil {
+		if err := c.sum.checkZip(requestedPath, info.Version, z.Reader); err != nil {
+			return nil, err
+		}
+	}
+	return z, nil
+}
+
+func (s *server) escapedURL(modulePath, version, suffix string) (_ string, err error) {
 	defer func() {
 		derrors.Wrap(&err, "Client.escapedURL(%q, %q, %q)", modulePath, version, suffix)
 	}()
@@ -116,19 +511,19 @@ func (c *Client) escapedURL(modulePath, version, suffix string) (_ string, err e
 		if suffix != "info" {
 			return "", fmt.Errorf("cannot ask for latest with suffix %q", suffix)
 		}
-		return fmt.Sprintf("%s/%s/@latest", c.url, escapedPath), nil
+		return fmt.Sprintf("%s/%s/@latest", s.url, escapedPath), nil
 	}
 	escapedVersion, err := module.EscapeVersion(version)
 	if err != nil {
 		return "", fmt.Errorf("version: %v: %w", err, derrors.InvalidArgument)
 	}
-	return fmt.Sprintf("%s/%s/@v/%s.%s", c.url, escapedPath, escapedVersion, suffix), nil
+	return fmt.Sprintf("%s/%s/@v/%s.%s", s.url, escapedPath, escapedVersion, suffix), nil
 }
 
-func (c *Client) readBody(ctx context.Context, modulePath, version, suffix string) (_ []byte, err error) {
+func (c *Client) readBody(ctx context.Context, s *server, modulePath, version, suffix string) (_ []byte, err error) {
 	defer derrors.Wrap(&err, "Client.readBody(%q, %q, %q)", modulePath, version, suffix)
 
-	u, err := c.escapedURL(modulePath, version, suffix)
+	u, err := s.escapedURL(modulePath, version, suffix)
 	if err != nil {
 		return nil, err
 	}
@@ -144,14 +539,52 @@ func (c *Client) readBody(ctx context.Context, modulePath, version, suffix strin
 	return data, nil
 }
 
+// copyBody is like readBody, but writes the body to w, and returns its size.
+// Bodies larger than maxSize are an error.
+func (c *Client) copyBody(ctx context.Context, s *server, modulePath, version, suffix string, w io.Writer, maxSize int64) (n int64, err error) {
+	defer derrors.Wrap(&err, "Client.copyBody(%q, %q, %q)", modulePath, version, suffix)
+
+	u, err := s.escapedURL(modulePath, version, suffix)
+	if err != nil {
+		return 0, err
+	}
+	err = c.executeRequest(ctx, u, func(body io.Reader) error {
+		var err error
+		n, err = io.Copy(w, io.LimitReader(body, maxSize+1))
+		if err != nil {
+			return err
+		}
+		if n > maxSize {
+			return fmt.Errorf("larger than %d bytes: %w", maxSize, derrors.BadModule)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
 // ListVersions makes a request to $GOPROXY/<path>/@v/list and returns the
 // resulting version strings.
 func (c *Client) ListVersions(ctx context.Context, modulePath string) ([]string, error) {
+	var versions []string
+	err := c.tryProxies(modulePath, func(s *server) (err error) {
+		versions, err = c.listVersions(ctx, s, modulePath)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (c *Client) listVersions(ctx context.Context, s *server, modulePath string) ([]string, error) {
 	escapedPath, err := module.EscapePath(modulePath)
 	if err != nil {
 		return nil, fmt.Errorf("module.EscapePath(%q): %w", modulePath, derrors.InvalidArgument)
 	}
-	u := fmt.Sprintf("%s/%s/@v/list", c.url, escapedPath)
+	u := fmt.Sprintf("%s/%s/@v/list", s.url, escapedPath)
 	var versions []string
 	collect := func(body io.Reader) error {
 		scanner := bufio.NewScanner(body)
@@ -169,9 +602,22 @@ func (c *Client) ListVersions(ctx context.Context, modulePath string) ([]string,
 // executeRequest executes an HTTP GET request for u, then calls the bodyFunc
 // on the response body, if no error occurred.
 func (c *Client) executeRequest(ctx context.Context, u string, bodyFunc func(body io.Reader) error) error {
-	r, err := ctxhttp.Get(ctx, c.httpClient, u)
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	for name, values := range c.header {
+		req.Header[name] = values
+	}
+	if req.URL.User == nil {
+		if login, password, ok := c.credentials(req.URL.Hostname()); ok {
+			req.SetBasicAuth(login, password)
+		}
+	}
+	u = redactURL(u)
+	r, err := ctxhttp.Do(ctx, c.httpClient, req)
 	if err != nil {
-		return fmt.Errorf("ctxhttp.Get(ctx, client, %q): %v", u, err)
+		return fmt.Errorf("ctxhttp.Do(ctx, client, %q): %v", u, redactURL(err.Error()))
 	}
 	defer r.Body.Close()
 	switch {
@@ -181,9 +627,9 @@ func (c *Client) executeRequest(ctx context.Context, u string, bodyFunc func(bod
 		r.StatusCode == http.StatusGone:
 		// Treat both 404 Not Found and 410 Gone responses
 		// from the proxy as a "not found" error category.
-		return fmt.Errorf("ctxhttp.Get(ctx, client, %q): %w", u, derrors.NotFound)
+		return fmt.Errorf("ctxhttp.Do(ctx, client, %q): %w", u, derrors.NotFound)
 	default:
-		return fmt.Errorf("ctxhttp.Get(ctx, client, %q): unexpected status %d %s", u, r.StatusCode, r.Status)
+		return fmt.Errorf("ctxhttp.Do(ctx, client, %q): unexpected status %d %s", u, r.StatusCode, r.Status)
 	}
 	return bodyFunc(r.Body)
 }
@@ -0,0 +1,63 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proxy
+
+import "strings"
+
+// A netrcLine holds the credentials for one machine in a .netrc file.
+type netrcLine struct {
+	machine  string
+	login    string
+	password string
+}
+
+// parseNetrc parses the contents of a .netrc file, in the format described
+// at https://www.gnu.org/software/inetutils/manual/html_node/The-_002enetrc-file.html.
+// Macro definitions and the default entry are ignored.
+func parseNetrc(data string) []netrcLine {
+	var (
+		nrc     []netrcLine
+		l       netrcLine
+		inMacro bool
+	)
+	for _, line := range strings.Split(data, "\n") {
+		if inMacro {
+			// A macro definition ends at an empty line.
+			if line == "" {
+				inMacro = false
+			}
+			continue
+		}
+		f := strings.Fields(line)
+		for i := 0; i < len(f)-1; i += 2 {
+			switch f[i] {
+			case "machine":
+				l = netrcLine{machine: f[i+1]}
+			case "login":
+				l.login = f[i+1]
+			case "password":
+				l.password = f[i+1]
+			case "macdef":
+				inMacro = true
+			}
+			if l.machine != "" && l.login != "" && l.password != "" {
+				nrc = append(nrc, l)
+				l = netrcLine{}
+			}
+		}
+	}
+	return nrc
+}
+
+// credentials returns the login and password for host from the .netrc
+// contents of c.
+func (c *Client) credentials(host string) (login, password string, ok bool) {
+	for _, l := range c.netrc {
+		if l.machine == host {
+			return l.login, l.password, true
+		}
+	}
+	return "", "", false
+}
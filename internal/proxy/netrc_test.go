@@ -0,0 +1,33 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proxy
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseNetrc(t *testing.T) {
+	data := `machine a.com login alice password pa
+machine b.com
+	login bob
+	password pb
+
+macdef init
+machine evil.com login mallory password pm
+
+machine c.com login carol
+default login anonymous password x
+`
+	got := parseNetrc(data)
+	want := []netrcLine{
+		{"a.com", "alice", "pa"},
+		{"b.com", "bob", "pb"},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(netrcLine{})); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
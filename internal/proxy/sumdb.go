@@ -0,0 +1,220 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proxy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/groupcache/lru"
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/dirhash"
+	"golang.org/x/mod/sumdb/note"
+	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// knownSumDBs maps the names of well-known checksum databases to their keys.
+var knownSumDBs = map[string]string{
+	"sum.golang.org": "sum.golang.org+033de0ae+Ac4zctda0e5eza+HJyk9SxEdh+s3Ux18htTTAD8OuAn8",
+}
+
+const (
+	// sumDBTimeout bounds each request to the checksum database.
+	sumDBTimeout = 1 * time.Minute
+
+	// sumDBCacheEntries is the number of tiles and records of the checksum
+	// database kept in memory. Tiles are at most 8K.
+	sumDBCacheEntries = 4096
+)
+
+// A sumChecker verifies module files against a checksum database.
+type sumChecker struct {
+	client *sumdb.Client
+	ops    *sumDBOps
+}
+
+// newSumChecker returns a sumChecker for the checksum database described by
+// gosumdb, which has the syntax of GOSUMDB, skipping modules that match the
+// GONOSUMDB-style patterns in noSumCheck. It returns nil if gosumdb is empty
+// or "off".
+func newSumChecker(gosumdb, noSumCheck string) (_ *sumChecker, err error) {
+	defer derrors.Wrap(&err, "newSumChecker(%q, %q)", gosumdb, noSumCheck)
+
+	if gosumdb == "" || gosumdb == "off" {
+		return nil, nil
+	}
+	fields := strings.Fields(gosumdb)
+	if len(fields) > 2 {
+		return nil, errors.New("too many fields")
+	}
+	key := fields[0]
+	if k := knownSumDBs[key]; k != "" {
+		key = k
+	}
+	verifier, err := note.NewVerifier(key)
+	if err != nil {
+		return nil, err
+	}
+	u := "https://" + verifier.Name()
+	if len(fields) == 2 {
+		u = fields[1]
+	}
+	if _, err := url.Parse(u); err != nil {
+		return nil, err
+	}
+	ops := &sumDBOps{
+		url:        strings.TrimRight(u, "/"),
+		key:        key,
+		httpClient: &http.Client{Transport: newTransport()},
+		config:     map[string][]byte{},
+		cache:      lru.New(sumDBCacheEntries),
+	}
+	client := sumdb.NewClient(ops)
+	if noSumCheck != "" {
+		client.SetGONOSUMDB(noSumCheck)
+	}
+	return &sumChecker{client: client, ops: ops}, nil
+}
+
+// checkZip verifies the hash of the zip of the given module version.
+func (s *sumChecker) checkZip(modulePath, version string, zr *zip.Reader) error {
+	h, err := hashZip(zr)
+	if err != nil {
+		return err
+	}
+	return s.check(modulePath, version, h)
+}
+
+// checkMod verifies the hash of the go.mod file of the given module version.
+func (s *sumChecker) checkMod(modulePath, version string, goMod []byte) error {
+	h, err := hashGoMod(goMod)
+	if err != nil {
+		return err
+	}
+	return s.check(modulePath, version+"/go.mod", h)
+}
+
+// hashZip returns the hash of a module zip, as recorded in go.sum files.
+func hashZip(zr *zip.Reader) (string, error) {
+	files := make(map[string]*zip.File)
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		files[f.Name] = f
+	}
+	return dirhash.Hash1(names, func(name string) (io.ReadCloser, error) {
+		return files[name].Open()
+	})
+}
+
+// hashGoMod returns the hash of a go.mod file, as recorded in go.sum files.
+func hashGoMod(goMod []byte) (string, error) {
+	return dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(goMod)), nil
+	})
+}
+
+// check verifies that the checksum database records hash for the version
+// of modulePath, which may end in "/go.mod".
+func (s *sumChecker) check(modulePath, version, hash string) error {
+	lines, err := s.client.Lookup(modulePath, version)
+	if errors.Is(err, sumdb.ErrGONOSUMDB) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("verifying module: %v", err)
+	}
+	want := fmt.Sprintf("%s %s %s", modulePath, version, hash)
+	for _, line := range lines {
+		if line == want {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s@%s: downloaded %s, checksum database has %q: %w",
+		modulePath, version, hash, lines, derrors.ChecksumMismatch)
+}
+
+// sumDBOps implements sumdb.ClientOps, keeping the configuration and cache
+// in memory.
+type sumDBOps struct {
+	url string
+	key string
+
+	// httpClient is used for requests to the checksum database. It is
+	// mutable for testing purposes.
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	config map[string][]byte
+	cache  *lru.Cache
+}
+
+func (o *sumDBOps) ReadRemote(path string) (_ []byte, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sumDBTimeout)
+	defer cancel()
+	r, err := ctxhttp.Get(ctx, o.httpClient, o.url+path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s%s: %s", o.url, path, r.Status)
+	}
+	return ioutil.ReadAll(r.Body)
+}
+
+func (o *sumDBOps) ReadConfig(file string) ([]byte, error) {
+	if file == "key" {
+		return []byte(o.key), nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.config[file], nil
+}
+
+func (o *sumDBOps) WriteConfig(file string, old, new []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !bytes.Equal(o.config[file], old) {
+		return sumdb.ErrWriteConflict
+	}
+	o.config[file] = new
+	return nil
+}
+
+func (o *sumDBOps) ReadCache(file string) ([]byte, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if data, ok := o.cache.Get(file); ok {
+		return data.([]byte), nil
+	}
+	return nil, derrors.NotFound
+}
+
+func (o *sumDBOps) WriteCache(file string, data []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.cache.Add(file, data)
+}
+
+func (o *sumDBOps) Log(msg string) {
+	log.Debug(context.Background(), msg)
+}
+
+func (o *sumDBOps) SecurityError(msg string) {
+	log.Errorf(context.Background(), "checksum database: %s", msg)
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proxy
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+func TestSumDB(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	otherModule := &TestModule{
+		ModulePath: "github.com/my/other",
+		Version:    "v1.0.0",
+		Files: map[string]string{
+			"go.mod": "module github.com/my/other\n",
+			"a.go":   "package other\n",
+		},
+	}
+	client, teardown := SetupTestProxy(t, []*TestModule{sampleModule, otherModule})
+	defer teardown()
+
+	// hashes returns the hashes of the zip and go.mod of a module version
+	// served by the proxy.
+	hashes := func(modulePath string) (zipHash, modHash string) {
+		zr, err := client.GetZip(ctx, modulePath, "v1.0.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		zipHash, err = hashZip(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		goMod, err := client.GetMod(ctx, modulePath, "v1.0.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		modHash, err = hashGoMod(goMod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return zipHash, modHash
+	}
+	sampleZip, sampleMod := hashes(sampleModule.ModulePath)
+	_, otherMod := hashes(otherModule.ModulePath)
+	// The checksum database has the wrong hash for the zip of otherModule.
+	gosum := map[string]string{
+		sampleModule.ModulePath: fmt.Sprintf("%[1]s v1.0.0 %[2]s\n%[1]s v1.0.0/go.mod %[3]s\n",
+			sampleModule.ModulePath, sampleZip, sampleMod),
+		otherModule.ModulePath: fmt.Sprintf("%[1]s v1.0.0 h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n%[1]s v1.0.0/go.mod %[2]s\n",
+			otherModule.ModulePath, otherMod),
+	}
+	skey, vkey, err := note.GenerateKey(rand.Reader, "sum.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewTLSServer(sumdb.NewServer(sumdb.NewTestServer(skey, func(path, vers string) ([]byte, error) {
+		if s, ok := gosum[path]; ok {
+			return []byte(s), nil
+		}
+		return nil, fmt.Errorf("%s: %w", path, derrors.NotFound)
+	})))
+	defer srv.Close()
+
+	for _, test := range []struct {
+		name, noSumCheck, modulePath string
+		wantErr                      error
+	}{
+		{"verified", "", sampleModule.ModulePath, nil},
+		{"mismatch", "", otherModule.ModulePath, derrors.ChecksumMismatch},
+		{"exempt", "github.com/my/*", otherModule.ModulePath, nil},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := NewWithConfig(Config{
+				URL:        client.proxies[0].url,
+				SumDB:      vkey + " " + srv.URL,
+				NoSumCheck: test.noSumCheck,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.httpClient = client.httpClient
+			c.sum.ops.httpClient = srv.Client()
+			if _, err := c.GetMod(ctx, test.modulePath, "v1.0.0"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.GetZip(ctx, test.modulePath, "v1.0.0"); !errors.Is(err, test.wantErr) {
+				t.Errorf("GetZip: got error %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewSumCheckerOff(t *testing.T) {
+	for _, gosumdb := range []string{"", "off"} {
+		s, err := newSumChecker(gosumdb, "")
+		if err != nil || s != nil {
+			t.Errorf("newSumChecker(%q) = %v, %v; want nil, nil", gosumdb, s, err)
+		}
+	}
+	if _, err := newSumChecker("sum.golang.org", ""); err != nil {
+		t.Errorf(`newSumChecker("sum.golang.org"): %v`, err)
+	}
+	if _, err := newSumChecker("not-a-key", ""); err == nil {
+		t.Error(`newSumChecker("not-a-key"): got nil error, want error`)
+	}
+}
@@ -107,7 +107,7 @@ func (ds *DataSource) GetDirectoryNew(ctx context.Context, dirPath, modulePath,
 // GetImports returns package imports as extracted from the module zip.
 func (ds *DataSource) GetImports(ctx context.Context, pkgPath, modulePath, version string) (_ []string, err error) {
 	defer derrors.Wrap(&err, "GetImports(%q, %q, %q)", pkgPath, modulePath, version)
-	vp, err := ds.GetPackage(ctx, pkgPath, modulePath, version)
+	vp, err := ds.GetPackage(ctx, pkgPath, modulePath, version, internal.MinimalFields)
 	if err != nil {
 		return nil, err
 	}
@@ -135,7 +135,7 @@ func (ds *DataSource) GetModuleLicenses(ctx context.Context, modulePath, version
 // such a package exists in the cache, it will be returned without querying the
 // proxy. Otherwise, the proxy is queried to find the longest module path at
 // that version containing the package.
-func (ds *DataSource) GetPackage(ctx context.Context, pkgPath, modulePath, version string) (_ *internal.LegacyVersionedPackage, err error) {
+func (ds *DataSource) GetPackage(ctx context.Context, pkgPath, modulePath, version string, _ internal.FieldSet) (_ *internal.LegacyVersionedPackage, err error) {
 	defer derrors.Wrap(&err, "GetPackage(%q, %q)", pkgPath, version)
 
 	var m *internal.Module
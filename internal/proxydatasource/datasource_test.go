@@ -73,7 +73,7 @@ var (
 		LegacyPackage:    wantPackage,
 	}
 	cmpOpts = append([]cmp.Option{
-		cmpopts.IgnoreFields(internal.LegacyPackage{}, "DocumentationHTML"),
+		cmpopts.IgnoreFields(internal.LegacyPackage{}, "DocumentationHTML", "Quality"),
 		cmpopts.IgnoreFields(licenses.License{}, "Contents"),
 	}, sample.LicenseCmpOpts...)
 )
@@ -111,7 +111,7 @@ func TestDataSource_GetImports(t *testing.T) {
 func TestDataSource_GetPackage_Latest(t *testing.T) {
 	ctx, ds, teardown := setup(t)
 	defer teardown()
-	got, err := ds.GetPackage(ctx, "foo.com/bar/baz", internal.UnknownModulePath, internal.LatestVersion)
+	got, err := ds.GetPackage(ctx, "foo.com/bar/baz", internal.UnknownModulePath, internal.LatestVersion, internal.AllFields)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -148,7 +148,7 @@ func TestDataSource_GetModuleLicenses(t *testing.T) {
 func TestDataSource_GetPackage(t *testing.T) {
 	ctx, ds, teardown := setup(t)
 	defer teardown()
-	got, err := ds.GetPackage(ctx, "foo.com/bar/baz", internal.UnknownModulePath, "v1.2.0")
+	got, err := ds.GetPackage(ctx, "foo.com/bar/baz", internal.UnknownModulePath, "v1.2.0", internal.AllFields)
 	if err != nil {
 		t.Fatal(err)
 	}
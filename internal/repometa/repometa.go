@@ -0,0 +1,216 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package repometa gets metadata about the repositories of modules from the
+// APIs of their code hosts, like whether a repository is archived.
+//
+// Code hosts limit the rate of API requests. A Client remembers when a host
+// said that no more requests are allowed, and does not send it any until the
+// limit is reset.
+package repometa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// Metadata is what a code host knows about a repository.
+type Metadata struct {
+	RepoURL string
+	// Missing reports whether the code host has no such repository. The
+	// other fields are then zero.
+	Missing  bool
+	Stars    int
+	Archived bool
+	// LastActivity is the last time the repository was pushed to, or zero
+	// if it is not known.
+	LastActivity time.Time
+	// FetchedAt is when the metadata was got from the code host.
+	FetchedAt time.Time
+}
+
+// URLPrefixes are the prefixes of the URLs of the repositories whose
+// metadata can be got.
+var URLPrefixes = []string{githubPrefix, gitlabPrefix}
+
+const (
+	githubPrefix = "https://github.com/"
+	gitlabPrefix = "https://gitlab.com/"
+)
+
+// A RateLimitError is returned when the code host of a repository does not
+// allow more requests until Reset.
+type RateLimitError struct {
+	Host  string
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit of %s exceeded until %s", e.Host, e.Reset.Format(time.RFC3339))
+}
+
+// A Client gets the metadata of repositories on GitHub and GitLab.
+type Client struct {
+	httpClient  *http.Client
+	githubURL   string // base URL of the GitHub API
+	gitlabURL   string // base URL of the GitLab API
+	githubToken string
+	gitlabToken string
+
+	mu sync.Mutex
+	// limitedUntil holds when the rate limits of code hosts that are
+	// exhausted are reset, by host.
+	limitedUntil map[string]time.Time
+}
+
+// NewClient returns a Client that sends requests with httpClient. The
+// tokens, if not empty, authenticate the requests to GitHub and GitLab,
+// which are then allowed many more of them.
+func NewClient(httpClient *http.Client, githubToken, gitlabToken string) *Client {
+	return &Client{
+		httpClient:   httpClient,
+		githubURL:    "https://api.github.com",
+		gitlabURL:    "https://gitlab.com/api/v4",
+		githubToken:  githubToken,
+		gitlabToken:  gitlabToken,
+		limitedUntil: map[string]time.Time{},
+	}
+}
+
+// Get returns the metadata of the repository at repoURL. It returns nil if
+// the repository is not on a supported code host. If the rate limit of the
+// code host is exhausted, it returns a *RateLimitError.
+func (c *Client) Get(ctx context.Context, repoURL string) (_ *Metadata, err error) {
+	defer derrors.Wrap(&err, "repometa.Get(%q)", repoURL)
+
+	var (
+		host, apiURL string
+		header       http.Header
+		data         struct {
+			// Fields of GitHub repositories.
+			StargazersCount int       `json:"stargazers_count"`
+			PushedAt        time.Time `json:"pushed_at"`
+			// Fields of GitLab projects.
+			StarCount      int       `json:"star_count"`
+			LastActivityAt time.Time `json:"last_activity_at"`
+			// Fields of both.
+			Archived bool `json:"archived"`
+		}
+	)
+	switch {
+	case strings.HasPrefix(repoURL, githubPrefix):
+		repo := strings.TrimPrefix(repoURL, githubPrefix)
+		if strings.Count(repo, "/") != 1 {
+			return nil, nil
+		}
+		host = "github.com"
+		apiURL = c.githubURL + "/repos/" + repo
+		header = http.Header{"Accept": {"application/vnd.github.v3+json"}}
+		if c.githubToken != "" {
+			header.Set("Authorization", "token "+c.githubToken)
+		}
+	case strings.HasPrefix(repoURL, gitlabPrefix):
+		project := strings.TrimPrefix(repoURL, gitlabPrefix)
+		if project == "" {
+			return nil, nil
+		}
+		host = "gitlab.com"
+		apiURL = c.gitlabURL + "/projects/" + url.PathEscape(project)
+		header = http.Header{}
+		if c.gitlabToken != "" {
+			header.Set("Private-Token", c.gitlabToken)
+		}
+	default:
+		return nil, nil
+	}
+
+	if err := c.checkLimit(host); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	resp, err := ctxhttp.Do(ctx, c.httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := c.recordLimit(host, resp); err != nil {
+		return nil, err
+	}
+	md := &Metadata{RepoURL: repoURL, FetchedAt: time.Now()}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		md.Missing = true
+		return md, nil
+	default:
+		return nil, fmt.Errorf("%s: %s", apiURL, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, err
+	}
+	md.Stars = data.StargazersCount + data.StarCount
+	md.Archived = data.Archived
+	md.LastActivity = data.PushedAt
+	if md.LastActivity.IsZero() {
+		md.LastActivity = data.LastActivityAt
+	}
+	return md, nil
+}
+
+// checkLimit returns a *RateLimitError if the rate limit of host is
+// exhausted.
+func (c *Client) checkLimit(host string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if reset, ok := c.limitedUntil[host]; ok && time.Now().Before(reset) {
+		return &RateLimitError{Host: host, Reset: reset}
+	}
+	return nil
+}
+
+// recordLimit remembers when the rate limit of host is reset if resp says
+// that it is exhausted. It returns a *RateLimitError if resp was refused
+// because of the limit.
+//
+// GitHub sends the X-RateLimit-Remaining and X-RateLimit-Reset headers, and
+// GitLab sends the same headers without the "X-" prefix. Both reset times
+// are in seconds since the Unix epoch.
+func (c *Client) recordLimit(host string, resp *http.Response) error {
+	remaining := resp.Header.Get("X-RateLimit-Remaining")
+	reset := resp.Header.Get("X-RateLimit-Reset")
+	if remaining == "" {
+		remaining = resp.Header.Get("RateLimit-Remaining")
+		reset = resp.Header.Get("RateLimit-Reset")
+	}
+	refused := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && remaining == "0")
+	if remaining != "0" && !refused {
+		return nil
+	}
+	resetTime := time.Now().Add(time.Minute)
+	if secs, err := strconv.ParseInt(reset, 10, 64); err == nil {
+		resetTime = time.Unix(secs, 0)
+	}
+	c.mu.Lock()
+	c.limitedUntil[host] = resetTime
+	c.mu.Unlock()
+	if refused {
+		return &RateLimitError{Host: host, Reset: resetTime}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package repometa
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestGet(t *testing.T) {
+	pushed := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/github/repos/owner/repo":
+			if got := r.Header.Get("Authorization"); got != "token gh" {
+				t.Errorf("Authorization = %q, want %q", got, "token gh")
+			}
+			w.Header().Set("X-RateLimit-Remaining", "10")
+			w.Write([]byte(`{"stargazers_count": 42, "archived": true, "pushed_at": "2020-06-01T12:00:00Z"}`))
+		case "/gitlab/projects/group%2Fsub%2Fproject":
+			if got := r.Header.Get("Private-Token"); got != "gl" {
+				t.Errorf("Private-Token = %q, want %q", got, "gl")
+			}
+			w.Write([]byte(`{"star_count": 7, "archived": false, "last_activity_at": "2020-06-01T12:00:00Z"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.Client(), "gh", "gl")
+	c.githubURL = ts.URL + "/github"
+	c.gitlabURL = ts.URL + "/gitlab"
+	ctx := context.Background()
+	for _, test := range []struct {
+		repoURL string
+		want    *Metadata
+	}{
+		{
+			"https://github.com/owner/repo",
+			&Metadata{RepoURL: "https://github.com/owner/repo", Stars: 42, Archived: true, LastActivity: pushed},
+		},
+		{
+			"https://gitlab.com/group/sub/project",
+			&Metadata{RepoURL: "https://gitlab.com/group/sub/project", Stars: 7, LastActivity: pushed},
+		},
+		{
+			"https://github.com/owner/gone",
+			&Metadata{RepoURL: "https://github.com/owner/gone", Missing: true},
+		},
+		{"https://bitbucket.org/owner/repo", nil},
+		{"https://github.com/owner", nil},
+	} {
+		got, err := c.Get(ctx, test.repoURL)
+		if err != nil {
+			t.Fatalf("%s: %v", test.repoURL, err)
+		}
+		if diff := cmp.Diff(test.want, got, cmpopts.IgnoreFields(Metadata{}, "FetchedAt")); diff != "" {
+			t.Errorf("%s: mismatch (-want +got):\n%s", test.repoURL, diff)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		if requests == 1 {
+			// The last request allowed.
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Write([]byte(`{"stargazers_count": 1}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.Client(), "", "")
+	c.githubURL = ts.URL
+	c.gitlabURL = ts.URL
+	ctx := context.Background()
+	if _, err := c.Get(ctx, "https://github.com/a/a"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := c.Get(ctx, "https://github.com/b/b")
+	var rle *RateLimitError
+	if !errors.As(err, &rle) {
+		t.Fatalf("got error %v, want a *RateLimitError", err)
+	}
+	if rle.Host != "github.com" || !rle.Reset.Equal(reset) {
+		t.Errorf("got %+v, want github.com limited until %s", rle, reset)
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1", requests)
+	}
+	// Other hosts can still be asked.
+	if _, err := c.Get(ctx, "https://gitlab.com/c/c"); errors.As(err, &rle) {
+		t.Errorf("gitlab.com: got %v, want no rate limit error", err)
+	}
+}
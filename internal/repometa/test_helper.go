@@ -0,0 +1,16 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package repometa
+
+import "net/http"
+
+// NewTestClient returns a Client that sends the requests for the GitHub API
+// to baseURL+"/github", and those for the GitLab API to baseURL+"/gitlab".
+func NewTestClient(httpClient *http.Client, baseURL string) *Client {
+	c := NewClient(httpClient, "", "")
+	c.githubURL = baseURL + "/github"
+	c.gitlabURL = baseURL + "/gitlab"
+	return c
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package signals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+const (
+	githubStarsName = "github-stars"
+	githubAPIURL    = "https://api.github.com"
+)
+
+// GitHubStars is a Provider of the number of stars of the GitHub
+// repositories of modules.
+type GitHubStars struct {
+	// Token, if not empty, authenticates the requests to the GitHub API.
+	Token string
+	// BaseURL is the URL of the GitHub API. If empty, it is
+	// https://api.github.com.
+	BaseURL string
+	Client  *http.Client
+}
+
+// Name implements Provider.
+func (*GitHubStars) Name() string { return githubStarsName }
+
+// Get implements Provider. It returns nil for modules whose repository is not
+// on GitHub.
+func (g *GitHubStars) Get(ctx context.Context, modulePath, repoURL string) (_ *Signal, err error) {
+	defer derrors.Wrap(&err, "GitHubStars.Get(%q, %q)", modulePath, repoURL)
+
+	repo := strings.TrimPrefix(repoURL, "https://github.com/")
+	if repo == repoURL || strings.Count(repo, "/") != 1 {
+		return nil, nil
+	}
+	base := g.BaseURL
+	if base == "" {
+		base = githubAPIURL
+	}
+	req, err := http.NewRequest(http.MethodGet, base+"/repos/"+repo, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if g.Token != "" {
+		req.Header.Set("Authorization", "token "+g.Token)
+	}
+	resp, err := ctxhttp.Do(ctx, g.Client, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", req.URL, resp.Status)
+	}
+	var r struct {
+		Stars int `json:"stargazers_count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	label := formatCount(r.Stars) + " stars"
+	if r.Stars == 1 {
+		label = "1 star"
+	}
+	return &Signal{
+		Provider:  githubStarsName,
+		Label:     label,
+		URL:       repoURL + "/stargazers",
+		FetchedAt: time.Now(),
+	}, nil
+}
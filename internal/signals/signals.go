@@ -0,0 +1,74 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package signals gets facts about modules from services outside the site,
+// like the number of stars of their repositories, to show as chips in the
+// header of their pages.
+//
+// Each kind of signal is got by a Provider. The worker refreshes the signals
+// of the providers that a deployment enables and stores them in the
+// database, from which the frontend reads them, so that pages never wait for
+// an external service.
+package signals
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// A Signal is a fact about a module from an external service.
+type Signal struct {
+	// Provider is the name of the Provider of the signal.
+	Provider string
+	// Label is the text of the chip, like "1.2k stars". A Signal with an
+	// empty label records that the service had nothing about the module.
+	Label string
+	// URL, if not empty, is where users can learn more about the signal.
+	URL string
+	// FetchedAt is when the signal was got from the service.
+	FetchedAt time.Time
+}
+
+// A Provider gets one kind of signal from an external service.
+type Provider interface {
+	// Name identifies the provider in the configuration of deployments and
+	// in the database, like "github-stars".
+	Name() string
+	// Get returns the signal of the module at modulePath, whose repository
+	// is at repoURL. It returns nil if the service has nothing about the
+	// module.
+	Get(ctx context.Context, modulePath, repoURL string) (*Signal, error)
+}
+
+// Providers returns the built-in providers with the given names.
+// githubToken, if not empty, authenticates the requests of the providers
+// that use the GitHub API, which are then allowed many more of them.
+func Providers(names []string, githubToken string) ([]Provider, error) {
+	var ps []Provider
+	for _, n := range names {
+		switch n {
+		case githubStarsName:
+			ps = append(ps, &GitHubStars{Token: githubToken, Client: http.DefaultClient})
+		default:
+			return nil, fmt.Errorf("unknown external signal provider %q", n)
+		}
+	}
+	return ps, nil
+}
+
+// formatCount returns n in a few characters, like "950", "1.2k" or "34k".
+func formatCount(n int) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprint(n)
+	case n < 10000:
+		return fmt.Sprintf("%.1fk", float64(n/100)/10)
+	case n < 1000000:
+		return fmt.Sprintf("%dk", n/1000)
+	default:
+		return fmt.Sprintf("%.1fM", float64(n/100000)/10)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package signals
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestFormatCount(t *testing.T) {
+	for _, test := range []struct {
+		n    int
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1.0k"},
+		{1250, "1.2k"},
+		{34567, "34k"},
+		{1234567, "1.2M"},
+	} {
+		if got := formatCount(test.n); got != test.want {
+			t.Errorf("formatCount(%d) = %q, want %q", test.n, got, test.want)
+		}
+	}
+}
+
+func TestProviders(t *testing.T) {
+	ps, err := Providers([]string{"github-stars"}, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 1 || ps[0].Name() != "github-stars" {
+		t.Errorf("got %v, want the github-stars provider", ps)
+	}
+	if _, err := Providers([]string{"nope"}, ""); err == nil {
+		t.Error("unknown provider: got nil error")
+	}
+}
+
+func TestGitHubStars(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token secret" {
+			t.Errorf("Authorization = %q, want %q", got, "token secret")
+		}
+		switch r.URL.Path {
+		case "/repos/owner/repo":
+			w.Write([]byte(`{"stargazers_count": 1234}`))
+		case "/repos/owner/one":
+			w.Write([]byte(`{"stargazers_count": 1}`))
+		case "/repos/owner/broken":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	g := &GitHubStars{Token: "secret", BaseURL: ts.URL, Client: ts.Client()}
+	for _, test := range []struct {
+		repoURL string
+		want    *Signal
+	}{
+		{
+			"https://github.com/owner/repo",
+			&Signal{Provider: "github-stars", Label: "1.2k stars", URL: "https://github.com/owner/repo/stargazers"},
+		},
+		{
+			"https://github.com/owner/one",
+			&Signal{Provider: "github-stars", Label: "1 star", URL: "https://github.com/owner/one/stargazers"},
+		},
+		{"https://github.com/owner/missing", nil},
+		{"https://gitlab.com/owner/repo", nil},
+		{"", nil},
+	} {
+		got, err := g.Get(ctx, "example.com/m", test.repoURL)
+		if err != nil {
+			t.Fatalf("%s: %v", test.repoURL, err)
+		}
+		if diff := cmp.Diff(test.want, got, cmpopts.IgnoreFields(Signal{}, "FetchedAt")); diff != "" {
+			t.Errorf("%s: mismatch (-want +got):\n%s", test.repoURL, diff)
+		}
+	}
+	if _, err := g.Get(ctx, "example.com/m", "https://github.com/owner/broken"); err == nil {
+		t.Error("forbidden: got nil error")
+	}
+}
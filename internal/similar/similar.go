@@ -0,0 +1,137 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package similar scores how similar packages are to each other, to recommend
+// packages beyond those matching a search.
+//
+// Two packages are similar when the same packages import both of them, when
+// the go.mod files of the same modules require both of their modules, and
+// when their synopses share words. Each of these is measured as the Jaccard
+// index of the two sets, and the score is a weighted sum of them.
+package similar
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Weights of the measures of similarity in a score. They add up to 1, so
+// scores are between 0 and 1.
+const (
+	importersWeight = 0.5
+	requirersWeight = 0.3
+	synopsisWeight  = 0.2
+)
+
+// MinScore is the score below which packages are not recommended as similar.
+const MinScore = 0.05
+
+// A Package is a package recommended as similar to another one.
+type Package struct {
+	Path       string  `json:"path"`
+	ModulePath string  `json:"modulePath"`
+	Synopsis   string  `json:"synopsis"`
+	Score      float64 `json:"score"`
+}
+
+// A Target is a package to find similar packages for.
+type Target struct {
+	Path     string
+	Synopsis string
+	// Importers is the number of packages that import the package.
+	Importers int
+	// Requirers is the number of modules whose go.mod files require the
+	// module of the package.
+	Requirers int
+}
+
+// A Candidate is a package that may be similar to a Target.
+type Candidate struct {
+	Path       string
+	ModulePath string
+	Synopsis   string
+	Importers  int
+	Requirers  int
+	// SharedImporters is the number of packages that import both the
+	// candidate and the target.
+	SharedImporters int
+	// SharedRequirers is the number of modules whose go.mod files require
+	// both the module of the candidate and that of the target.
+	SharedRequirers int
+}
+
+// Score returns the similarity of c to t, between 0 and 1.
+func Score(t *Target, c *Candidate) float64 {
+	return importersWeight*jaccard(c.SharedImporters, t.Importers, c.Importers) +
+		requirersWeight*jaccard(c.SharedRequirers, t.Requirers, c.Requirers) +
+		synopsisWeight*wordJaccard(t.Synopsis, c.Synopsis)
+}
+
+// Rank returns the at most n candidates most similar to t, most similar
+// first. Candidates scoring below MinScore are left out.
+func Rank(t *Target, cands []*Candidate, n int) []*Package {
+	var pkgs []*Package
+	for _, c := range cands {
+		if c.Path == t.Path {
+			continue
+		}
+		if s := Score(t, c); s >= MinScore {
+			pkgs = append(pkgs, &Package{Path: c.Path, ModulePath: c.ModulePath, Synopsis: c.Synopsis, Score: s})
+		}
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		if pkgs[i].Score != pkgs[j].Score {
+			return pkgs[i].Score > pkgs[j].Score
+		}
+		return pkgs[i].Path < pkgs[j].Path
+	})
+	if len(pkgs) > n {
+		pkgs = pkgs[:n]
+	}
+	return pkgs
+}
+
+// jaccard returns the Jaccard index of two sets of sizes a and b with shared
+// elements in common.
+func jaccard(shared, a, b int) float64 {
+	union := a + b - shared
+	if shared <= 0 || union <= 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// wordJaccard returns the Jaccard index of the sets of words of a and b.
+func wordJaccard(a, b string) float64 {
+	wa, wb := words(a), words(b)
+	shared := 0
+	for w := range wa {
+		if wb[w] {
+			shared++
+		}
+	}
+	return jaccard(shared, len(wa), len(wb))
+}
+
+// stopWords are words too common in synopses to make them similar.
+var stopWords = map[string]bool{
+	"and": true, "for": true, "from": true, "golang": true, "implements": true,
+	"library": true, "package": true, "provides": true, "that": true,
+	"the": true, "this": true, "with": true,
+}
+
+// words returns the set of the lower-case words of s, without stop words and
+// words shorter than three letters.
+func words(s string) map[string]bool {
+	ws := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) >= 3 && !stopWords[w] {
+			ws[w] = true
+		}
+	}
+	return ws
+}
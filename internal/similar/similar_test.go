@@ -0,0 +1,74 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package similar
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestScore(t *testing.T) {
+	target := &Target{
+		Path:      "github.com/sirupsen/logrus",
+		Synopsis:  "Package logrus is a structured logger for Go.",
+		Importers: 10,
+		Requirers: 4,
+	}
+	for _, test := range []struct {
+		name string
+		c    *Candidate
+		want float64
+	}{
+		{"unrelated", &Candidate{Synopsis: "Package yaml decodes YAML.", Importers: 5, Requirers: 2}, 0},
+		{
+			"same importers",
+			&Candidate{Importers: 10, SharedImporters: 10},
+			importersWeight,
+		},
+		{
+			"half",
+			&Candidate{Importers: 5, Requirers: 4, SharedImporters: 5, SharedRequirers: 2},
+			importersWeight*0.5 + requirersWeight*(2.0/6),
+		},
+		{
+			"synopsis",
+			// "structured" and "logger" are shared out of "logrus", "structured",
+			// "logger" and "zap", "fast".
+			&Candidate{Synopsis: "Package zap is a fast, structured logger."},
+			synopsisWeight * 2 / 5,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Score(target, test.c); math.Abs(got-test.want) > 1e-9 {
+				t.Errorf("got %f, want %f", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	target := &Target{Path: "a.com/log", Synopsis: "Package log logs.", Importers: 10}
+	cands := []*Candidate{
+		{Path: "a.com/log", Importers: 10, SharedImporters: 10},
+		{Path: "b.com/zap", ModulePath: "b.com", Importers: 10, SharedImporters: 5},
+		{Path: "c.com/other", Importers: 100, SharedImporters: 1},
+		{Path: "d.com/logrus", ModulePath: "d.com", Importers: 10, SharedImporters: 8},
+		{Path: "e.com/glog", ModulePath: "e.com", Importers: 10, SharedImporters: 5},
+	}
+	got := Rank(target, cands, 2)
+	want := []*Package{
+		{Path: "d.com/logrus", ModulePath: "d.com"},
+		{Path: "b.com/zap", ModulePath: "b.com"},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Package{}, "Score")); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if len(Rank(target, cands, 10)) != 3 {
+		t.Errorf("Rank kept the target itself, or candidates below MinScore")
+	}
+}
@@ -135,6 +135,17 @@ func (i *Info) RawURL(pathname string) string {
 	})
 }
 
+// AtCommit returns a copy of i whose URLs refer to commit instead of the
+// commit of the module version, like "HEAD" for the default branch.
+func (i *Info) AtCommit(commit string) *Info {
+	if i == nil {
+		return nil
+	}
+	c := *i
+	c.commit = commit
+	return &c
+}
+
 // map of common urlTemplates
 var urlTemplatesByKind = map[string]urlTemplates{
 	"github":    githubURLTemplates,
@@ -236,7 +247,9 @@ func (c *Client) doURL(ctx context.Context, method, url string, only200 bool) (_
 // returns a URL to that repo, as well as the directory of the module relative
 // to the repo root.
 //
-// LegacyModuleInfo may fetch from arbitrary URLs, so it can be slow.
+// LegacyModuleInfo may fetch from arbitrary URLs, so it can be slow. If client
+// is nil, no requests are made, and only modules in repositories on
+// well-known hosts have source info.
 func ModuleInfo(ctx context.Context, client *Client, modulePath, version string) (info *Info, err error) {
 	defer derrors.Wrap(&err, "source.LegacyModuleInfo(ctx, %q, %q)", modulePath, version)
 	ctx, span := trace.StartSpan(ctx, "source.LegacyModuleInfo")
@@ -256,6 +269,9 @@ func ModuleInfo(ctx context.Context, client *Client, modulePath, version string)
 	}
 	repo, relativeModulePath, templates, err := matchStatic(modulePath)
 	if err != nil {
+		if client == nil {
+			return nil, fmt.Errorf("%v; source lookups are disabled", err)
+		}
 		info, err = moduleInfoDynamic(ctx, client, modulePath, version)
 		if err != nil {
 			return nil, err
@@ -388,6 +404,11 @@ func adjustVersionedModuleDirectory(ctx context.Context, client *Client, info *I
 	if info.moduleDir == dirWithoutVersion {
 		return
 	}
+	if client == nil {
+		// Without source lookups, assume the more common layout.
+		info.moduleDir = dirWithoutVersion
+		return
+	}
 	// moduleDir does have a "/vN" for N > 1. To see if that is the actual directory,
 	// fetch the go.mod file from it.
 	res, err := client.doURL(ctx, "HEAD", info.FileURL("go.mod"), true)
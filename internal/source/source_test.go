@@ -757,3 +757,18 @@ func TestJSON(t *testing.T) {
 		}
 	}
 }
+
+func TestAtCommit(t *testing.T) {
+	info := NewGitHubInfo("https://github.com/a/b", "c", "v1.2.3")
+	const want = "https://raw.githubusercontent.com/a/b/HEAD/c/README.md"
+	if got := info.AtCommit("HEAD").RawURL("README.md"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := info.RawURL("README.md"); got != "https://raw.githubusercontent.com/a/b/v1.2.3/c/README.md" {
+		t.Errorf("AtCommit changed the original: got %q", got)
+	}
+	var nilInfo *Info
+	if got := nilInfo.AtCommit("HEAD"); got != nil {
+		t.Errorf("nil.AtCommit = %+v, want nil", got)
+	}
+}
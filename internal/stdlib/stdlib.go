@@ -24,6 +24,7 @@ import (
 	"golang.org/x/mod/semver"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/testing/testhelper"
+	"golang.org/x/pkgsite/internal/version"
 
 	"gopkg.in/src-d/go-billy.v4/osfs"
 	"gopkg.in/src-d/go-git.v4"
@@ -81,17 +82,22 @@ func VersionForTag(tag string) string {
 // TagForVersion returns the Go standard library repository tag corresponding
 // to semver. The Go tags differ from standard semantic versions in a few ways,
 // such as beginning with "go" instead of "v".
-func TagForVersion(version string) (_ string, err error) {
-	defer derrors.Wrap(&err, "TagForVersion(%q)", version)
+//
+// The pseudo-versions that Zip assigns to master map to "master".
+func TagForVersion(v string) (_ string, err error) {
+	defer derrors.Wrap(&err, "TagForVersion(%q)", v)
 
 	// Special case: v1.0.0 => go1.
-	if version == "v1.0.0" {
+	if v == "v1.0.0" {
 		return "go1", nil
 	}
-	if !semver.IsValid(version) {
-		return "", derrors.FromHTTPStatus(http.StatusBadRequest, "requested version is not a valid semantic version: %q ", version)
+	if v == version.Master || version.IsPseudo(v) {
+		return version.Master, nil
+	}
+	if !semver.IsValid(v) {
+		return "", derrors.FromHTTPStatus(http.StatusBadRequest, "requested version is not a valid semantic version: %q ", v)
 	}
-	goVersion := semver.Canonical(version)
+	goVersion := semver.Canonical(v)
 	prerelease := semver.Prerelease(goVersion)
 	versionWithoutPrerelease := strings.TrimSuffix(goVersion, prerelease)
 	patch := strings.TrimPrefix(versionWithoutPrerelease, semver.MajorMinor(goVersion)+".")
@@ -119,15 +125,16 @@ func TagForVersion(version string) (_ string, err error) {
 
 // MajorVersionForVersion returns the Go major version for version.
 // E.g. "v1.13.3" => "go1".
-func MajorVersionForVersion(version string) (_ string, err error) {
-	defer derrors.Wrap(&err, "MajorVersionForVersion(%q)", version)
+func MajorVersionForVersion(v string) (_ string, err error) {
+	defer derrors.Wrap(&err, "MajorVersionForVersion(%q)", v)
 
-	tag, err := TagForVersion(version)
+	tag, err := TagForVersion(v)
 	if err != nil {
 		return "", err
 	}
-	if tag == "go1" {
-		return tag, nil
+	if tag == "go1" || tag == version.Master {
+		// The standard library at master is still part of Go 1.
+		return "go1", nil
 	}
 	i := strings.IndexRune(tag, '.')
 	if i < 0 {
@@ -165,14 +172,22 @@ var UseTestData = false
 var TestCommitTime = time.Date(2019, 9, 4, 1, 2, 3, 0, time.UTC)
 
 // getGoRepo returns a repo object for the Go repo at version.
-func getGoRepo(version string) (_ *git.Repository, err error) {
-	tag, err := TagForVersion(version)
-	if err != nil {
-		return nil, err
+// If version is version.Master, the repo is cloned at the head of the master
+// branch.
+func getGoRepo(v string) (_ *git.Repository, err error) {
+	var ref plumbing.ReferenceName
+	if v == version.Master {
+		ref = plumbing.NewBranchReferenceName(version.Master)
+	} else {
+		tag, err := TagForVersion(v)
+		if err != nil {
+			return nil, err
+		}
+		ref = plumbing.NewTagReferenceName(tag)
 	}
 	return git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
 		URL:           GoRepoURL,
-		ReferenceName: plumbing.NewTagReferenceName(tag),
+		ReferenceName: ref,
 		SingleBranch:  true,
 		Depth:         1,
 		Tags:          git.NoTags,
@@ -242,92 +257,114 @@ func Versions() (_ []string, err error) {
 }
 
 // Directory returns the directory of the standard library relative to the repo root.
-func Directory(version string) string {
+func Directory(v string) string {
+	if v == version.Master || version.IsPseudo(v) {
+		return "src"
+	}
 	// For versions older than v1.4.0-beta.1, the stdlib is in src/pkg.
-	if semver.Compare(version, "v1.4.0-beta.1") == -1 {
+	if semver.Compare(v, "v1.4.0-beta.1") == -1 {
 		return "src/pkg"
 	}
 	return "src"
 }
 
 // Zip creates a module zip representing the entire Go standard library at the
-// given version and returns a reader to it. It also returns the time of the
-// commit for that version. The zip file is in module form, with each path
-// prefixed by ModuleName + "@" + version.
+// given version and returns a reader to it. It also returns the resolved
+// version and the time of the commit for that version. The zip file is in
+// module form, with each path prefixed by ModuleName + "@" + resolvedVersion.
 //
 // Zip reads the standard library at the Go repository tag corresponding to to
-// the given semantic version.
+// the given semantic version. If requestedVersion is version.Master, Zip reads
+// the head of the master branch instead, and the resolved version is a
+// pseudo-version for that commit.
 //
 // Zip ignores go.mod files in the standard library, treating it as if it were a
 // single module named "std" at the given version.
-func Zip(version string) (_ *zip.Reader, commitTime time.Time, err error) {
+func Zip(requestedVersion string) (_ *zip.Reader, resolvedVersion string, commitTime time.Time, err error) {
 	// This code taken, with modifications, from
 	// https://github.com/shurcooL/play/blob/master/256/moduleproxy/std/std.go.
-	defer derrors.Wrap(&err, "stdlib.Zip(%q)", version)
+	defer derrors.Wrap(&err, "stdlib.Zip(%q)", requestedVersion)
 
-	knownVersions, err := Versions()
-	if err != nil {
-		return nil, time.Time{}, err
-	}
-	found := false
-	for _, v := range knownVersions {
-		if v == version {
-			found = true
-			break
+	if requestedVersion != version.Master {
+		knownVersions, err := Versions()
+		if err != nil {
+			return nil, "", time.Time{}, err
+		}
+		found := false
+		for _, v := range knownVersions {
+			if v == requestedVersion {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, "", time.Time{}, fmt.Errorf("%w: requested version unknown: %q", derrors.InvalidArgument, requestedVersion)
 		}
-	}
-	if !found {
-		return nil, time.Time{}, fmt.Errorf("%w: requested version unknown: %q", derrors.InvalidArgument, version)
 	}
 
 	var repo *git.Repository
 	if UseTestData {
-		repo, err = getTestGoRepo(version)
+		repo, err = getTestGoRepo(requestedVersion)
 	} else {
-		repo, err = getGoRepo(version)
+		repo, err = getGoRepo(requestedVersion)
 	}
 	if err != nil {
-		return nil, time.Time{}, err
+		return nil, "", time.Time{}, err
 	}
 	var buf bytes.Buffer
 	z := zip.NewWriter(&buf)
 	head, err := repo.Head()
 	if err != nil {
-		return nil, time.Time{}, err
+		return nil, "", time.Time{}, err
 	}
 	commit, err := repo.CommitObject(head.Hash())
 	if err != nil {
-		return nil, time.Time{}, err
+		return nil, "", time.Time{}, err
+	}
+	resolvedVersion = requestedVersion
+	if requestedVersion == version.Master {
+		resolvedVersion = newPseudoVersion(commit.Committer.When, commit.Hash.String())
 	}
 	root, err := repo.TreeObject(commit.TreeHash)
 	if err != nil {
-		return nil, time.Time{}, err
+		return nil, "", time.Time{}, err
 	}
-	prefixPath := ModulePath + "@" + version
+	prefixPath := ModulePath + "@" + resolvedVersion
 	// Add top-level files.
 	if err := addFiles(z, repo, root, prefixPath, false); err != nil {
-		return nil, time.Time{}, err
+		return nil, "", time.Time{}, err
 	}
 	// Add files from the stdlib directory.
 	libdir := root
-	for _, d := range strings.Split(Directory(version), "/") {
+	for _, d := range strings.Split(Directory(resolvedVersion), "/") {
 		libdir, err = subTree(repo, libdir, d)
 		if err != nil {
-			return nil, time.Time{}, err
+			return nil, "", time.Time{}, err
 		}
 	}
 	if err := addFiles(z, repo, libdir, prefixPath, true); err != nil {
-		return nil, time.Time{}, err
+		return nil, "", time.Time{}, err
 	}
 	if err := z.Close(); err != nil {
-		return nil, time.Time{}, err
+		return nil, "", time.Time{}, err
 	}
 	br := bytes.NewReader(buf.Bytes())
 	zr, err := zip.NewReader(br, int64(br.Len()))
 	if err != nil {
-		return nil, time.Time{}, err
+		return nil, "", time.Time{}, err
 	}
-	return zr, commit.Committer.When, nil
+	return zr, resolvedVersion, commit.Committer.When, nil
+}
+
+// newPseudoVersion returns a pseudo-version for the commit with the given
+// time and hash, of the form v0.0.0-yyyymmddhhmmss-abcdefabcdef.
+// The standard library has no module tags that the go command understands,
+// so a base version of v0.0.0 is always used.
+func newPseudoVersion(commitTime time.Time, hash string) string {
+	if len(hash) > 12 {
+		hash = hash[:12]
+	}
+	return fmt.Sprintf("v0.0.0-%s-%s", commitTime.UTC().Format("20060102150405"), hash)
 }
 
 // addFiles adds the files in t to z, using dirpath as the path prefix.
@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/mod/semver"
 )
@@ -55,6 +56,16 @@ func TestTagForVersion(t *testing.T) {
 			version: "v1.13.0",
 			want:    "go1.13",
 		},
+		{
+			name:    "master",
+			version: "master",
+			want:    "master",
+		},
+		{
+			name:    "pseudo-version for master",
+			version: "v0.0.0-20200601000000-0123456789ab",
+			want:    "master",
+		},
 		{
 			name:    "bad std semver",
 			version: "v1.x",
@@ -100,6 +111,7 @@ func TestMajorVersionForVersion(t *testing.T) {
 		{"v1.13.3", "go1"},
 		{"v1.9.0-rc.2", "go1"},
 		{"v2.1.3", "go2"},
+		{"v0.0.0-20200601000000-0123456789ab", "go1"},
 	} {
 		got, err := MajorVersionForVersion(test.in)
 		if (err != nil) != (test.want == "") {
@@ -117,10 +129,13 @@ func TestZip(t *testing.T) {
 
 	for _, version := range []string{"v1.12.5", "v1.3.2"} {
 		t.Run(version, func(t *testing.T) {
-			zr, gotTime, err := Zip(version)
+			zr, gotVersion, gotTime, err := Zip(version)
 			if err != nil {
 				t.Fatal(err)
 			}
+			if gotVersion != version {
+				t.Errorf("resolved version: got %s, want %s", gotVersion, version)
+			}
 			if !gotTime.Equal(TestCommitTime) {
 				t.Errorf("commit time: got %s, want %s", gotTime, TestCommitTime)
 			}
@@ -167,6 +182,18 @@ func TestZip(t *testing.T) {
 	}
 }
 
+func TestNewPseudoVersion(t *testing.T) {
+	commitTime := time.Date(2020, 6, 1, 12, 30, 45, 0, time.FixedZone("EST", -5*60*60))
+	got := newPseudoVersion(commitTime, "0123456789abcdef0123456789abcdef01234567")
+	want := "v0.0.0-20200601173045-0123456789ab"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := Directory(got); got != "src" {
+		t.Errorf("Directory(%q) = %q, want %q", want, got, "src")
+	}
+}
+
 func TestVersions(t *testing.T) {
 	UseTestData = true
 	defer func() { UseTestData = false }()
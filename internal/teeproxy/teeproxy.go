@@ -5,11 +5,16 @@
 package teeproxy
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"html/template"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"path/filepath"
+	"strconv"
 	"time"
 
 	"golang.org/x/net/context/ctxhttp"
@@ -17,6 +22,7 @@ import (
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/experiment"
 	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
 )
 
 type RequestEvent struct {
@@ -59,15 +65,38 @@ var gddoToPkgGoDevRequest = map[string]string{
 	"/third_party/jquery.timeago.js": "/404",
 }
 
-func HandleGddoEvent(w http.ResponseWriter, r *http.Request) {
-	if status, err := doRequest(r); err != nil {
+// Server receives mirrored godoc.org requests, replays them against
+// pkg.go.dev, and records the results.
+type Server struct {
+	// db is used to record events. If it is nil, events are only logged.
+	db             *postgres.DB
+	reportTemplate *template.Template
+}
+
+// NewServer returns a new Server. db may be nil, in which case events are
+// logged but not recorded, and the report page is not available.
+func NewServer(db *postgres.DB, staticPath string) (_ *Server, err error) {
+	defer derrors.Wrap(&err, "NewServer(db, %q)", staticPath)
+
+	t, err := template.New("report.tmpl").Funcs(template.FuncMap{
+		"timefmt": func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+	}).ParseFiles(filepath.Join(staticPath, "html/teeproxy/report.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	return &Server{db: db, reportTemplate: t}, nil
+}
+
+// HandleGddoEvent handles a godoc.org request event sent to the teeproxy.
+func (s *Server) HandleGddoEvent(w http.ResponseWriter, r *http.Request) {
+	if status, err := s.doRequest(r); err != nil {
 		log.Infof(r.Context(), "teeproxy.HandleGddoEvent: %v", err)
 		http.Error(w, http.StatusText(status), status)
 		return
 	}
 }
 
-func doRequest(r *http.Request) (_ int, err error) {
+func (s *Server) doRequest(r *http.Request) (_ int, err error) {
 	defer derrors.Wrap(&err, "doRequest(%q): referer=%q", r.URL.Path, r.Referer())
 	ctx := r.Context()
 	status, err := validateTeeProxyRequest(r)
@@ -93,9 +122,80 @@ func doRequest(r *http.Request) (_ int, err error) {
 		"godoc.org":  gddoEvent,
 		"pkg.go.dev": pkgGoDevEvent,
 	})
+	if s.db != nil && pkgGoDevEvent != nil {
+		// Failing to record an event should not cause godoc.org to retry
+		// the request, so only log the error.
+		if err := s.db.InsertTeeproxyEvent(ctx, newTeeproxyEvent(gddoEvent, pkgGoDevEvent)); err != nil {
+			log.Error(ctx, err)
+		}
+	}
 	return http.StatusOK, nil
 }
 
+// newTeeproxyEvent returns the event to record for a godoc.org request and
+// the corresponding pkg.go.dev request.
+func newTeeproxyEvent(gddoEvent, pkgGoDevEvent *RequestEvent) *postgres.TeeproxyEvent {
+	return &postgres.TeeproxyEvent{
+		GddoPath:        gddoEvent.Path,
+		PkgGoDevPath:    pkgGoDevEvent.Path,
+		GddoStatus:      gddoEvent.Status,
+		PkgGoDevStatus:  pkgGoDevEvent.Status,
+		GddoLatency:     gddoEvent.Latency,
+		PkgGoDevLatency: pkgGoDevEvent.Latency,
+		IsRobot:         gddoEvent.IsRobot,
+	}
+}
+
+// reportPeriod is the default period covered by the report page.
+const reportPeriod = 24 * time.Hour
+
+// HandleReport serves a page summarizing status and latency divergence
+// between godoc.org and pkg.go.dev over the last day, or over the number of
+// hours given by the "hours" query parameter.
+func (s *Server) HandleReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if s.db == nil {
+		http.Error(w, "teeproxy is not recording events", http.StatusNotFound)
+		return
+	}
+	period := reportPeriod
+	if h, err := strconv.Atoi(r.FormValue("hours")); err == nil && h > 0 {
+		period = time.Duration(h) * time.Hour
+	}
+	since := time.Now().Add(-period)
+	stats, err := s.db.GetTeeproxyStats(ctx, since)
+	if err != nil {
+		log.Error(ctx, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	divergences, err := s.db.GetTeeproxyDivergences(ctx, since, 100)
+	if err != nil {
+		log.Error(ctx, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	page := struct {
+		Period      time.Duration
+		Stats       *postgres.TeeproxyStats
+		Divergences []*postgres.TeeproxyEvent
+	}{
+		Period:      period,
+		Stats:       stats,
+		Divergences: divergences,
+	}
+	var buf bytes.Buffer
+	if err := s.reportTemplate.Execute(&buf, page); err != nil {
+		log.Error(ctx, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := io.Copy(w, &buf); err != nil {
+		log.Errorf(ctx, "Error copying buffer to ResponseWriter: %v", err)
+	}
+}
+
 // validateTeeProxyRequest validates that a request to the teeproxy is allowed.
 // It will return the error code and error if a request is invalid. Otherwise,
 // it will return http.StatusOK.
@@ -14,6 +14,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal/postgres"
 )
 
 func TestPkgGoDevPath(t *testing.T) {
@@ -95,3 +96,32 @@ func TestGetGddoEvent(t *testing.T) {
 		}
 	}
 }
+
+func TestNewTeeproxyEvent(t *testing.T) {
+	gddoEvent := &RequestEvent{
+		Host:    "godoc.org",
+		Path:    "/-/about",
+		Status:  http.StatusOK,
+		Latency: 10,
+		IsRobot: true,
+	}
+	pkgGoDevEvent := &RequestEvent{
+		Host:    "pkg.go.dev",
+		Path:    "/about",
+		Status:  http.StatusFound,
+		Latency: 20,
+	}
+	want := &postgres.TeeproxyEvent{
+		GddoPath:        "/-/about",
+		PkgGoDevPath:    "/about",
+		GddoStatus:      http.StatusOK,
+		PkgGoDevStatus:  http.StatusFound,
+		GddoLatency:     10,
+		PkgGoDevLatency: 20,
+		IsRobot:         true,
+	}
+	got := newTeeproxyEvent(gddoEvent, pkgGoDevEvent)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
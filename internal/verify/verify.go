@@ -0,0 +1,186 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package verify checks that users control the modules whose ownership they
+// claim, so that the site can show them as verified publishers.
+//
+// A user asks to verify a module and is given a token. The user proves
+// control of the module by publishing the token either in a file at the root
+// of the module in its repository (see FilePath), or, for modules with vanity
+// import paths, in a DNS TXT record of the host of the module path (see
+// DNSName).
+package verify
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/source"
+)
+
+const (
+	// FilePath is the path of the verification file, relative to the root of
+	// the module in its repository. It is read on the default branch.
+	FilePath = ".well-known/pkgsite-verification"
+
+	// dnsPrefix is prepended to the host of a module path to get the name of
+	// its verification TXT record.
+	dnsPrefix = "_pkgsite-verification."
+
+	// recordPrefix starts the verification TXT records.
+	recordPrefix = "pkgsite-verification="
+
+	// maxFileSize is the number of bytes of the verification file that are
+	// read.
+	maxFileSize = 4096
+)
+
+// The ways of verifying a module.
+const (
+	MethodFile = "file"
+	MethodDNS  = "dns"
+)
+
+// A Verification is the request of a user to be recorded as an owner of a
+// module.
+type Verification struct {
+	ModulePath string
+	Email      string
+	Token      string
+	// Method is the way the module was verified, one of MethodFile and
+	// MethodDNS, or empty if it is not verified yet.
+	Method string
+	// VerifiedAt is when the module was verified, or zero if it is not
+	// verified yet.
+	VerifiedAt time.Time
+	CreatedAt  time.Time
+}
+
+// Verified reports whether the user was verified as an owner of the module.
+func (v *Verification) Verified() bool {
+	return v.Method != ""
+}
+
+// NewToken returns a new random verification token.
+func NewToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// DNSName returns the name of the TXT record that verifies modulePath, like
+// "_pkgsite-verification.example.com".
+func DNSName(modulePath string) string {
+	host := modulePath
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	return dnsPrefix + host
+}
+
+// DNSRecord returns the content of the TXT record for token.
+func DNSRecord(token string) string {
+	return recordPrefix + token
+}
+
+// A Resolver looks up DNS TXT records. *net.Resolver is a Resolver.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// A Checker checks that verification tokens are published.
+type Checker struct {
+	client   *http.Client
+	resolver Resolver
+}
+
+// NewChecker returns a Checker that reads verification files with client, and
+// looks up TXT records with resolver.
+func NewChecker(client *http.Client, resolver Resolver) *Checker {
+	return &Checker{client: client, resolver: resolver}
+}
+
+// Check reports whether token is published for the module at modulePath, whose
+// source is info, in the way given by method.
+func (c *Checker) Check(ctx context.Context, method, modulePath string, info *source.Info, token string) (bool, error) {
+	switch method {
+	case MethodFile:
+		return c.CheckFile(ctx, info, token)
+	case MethodDNS:
+		return c.CheckDNS(ctx, modulePath, token)
+	default:
+		return false, fmt.Errorf("unknown verification method %q: %w", method, derrors.InvalidArgument)
+	}
+}
+
+// CheckFile reports whether the verification file of the module whose source
+// is info, on the default branch of its repository, has a line with token.
+func (c *Checker) CheckFile(ctx context.Context, info *source.Info, token string) (_ bool, err error) {
+	defer derrors.Wrap(&err, "CheckFile(ctx, %q)", info.RepoURL())
+
+	u := FileURL(info)
+	if u == "" {
+		return false, fmt.Errorf("cannot read files of %q: %w", info.RepoURL(), derrors.NotFound)
+	}
+	resp, err := ctxhttp.Get(ctx, c.client, u)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	scan := bufio.NewScanner(io.LimitReader(resp.Body, maxFileSize))
+	for scan.Scan() {
+		if strings.TrimSpace(scan.Text()) == token {
+			return true, nil
+		}
+	}
+	return false, scan.Err()
+}
+
+// FileURL returns the URL of the raw content of the verification file of the
+// module whose source is info, or "" if its code host does not serve raw
+// content.
+func FileURL(info *source.Info) string {
+	return info.AtCommit("HEAD").RawURL(FilePath)
+}
+
+// CheckDNS reports whether the host of modulePath has a verification TXT
+// record for token.
+func (c *Checker) CheckDNS(ctx context.Context, modulePath, token string) (_ bool, err error) {
+	defer derrors.Wrap(&err, "CheckDNS(ctx, %q)", modulePath)
+
+	records, err := c.resolver.LookupTXT(ctx, DNSName(modulePath))
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, r := range records {
+		if strings.TrimSpace(r) == DNSRecord(token) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package verify
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/source"
+)
+
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, ok := r[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestCheckDNS(t *testing.T) {
+	c := NewChecker(http.DefaultClient, fakeResolver{
+		"_pkgsite-verification.example.com": {"v=spf1 -all", "pkgsite-verification=tok"},
+	})
+	for _, test := range []struct {
+		modulePath, token string
+		want              bool
+	}{
+		{"example.com/a/b", "tok", true},
+		{"example.com", "tok", true},
+		{"example.com/a", "other", false},
+		{"example.org/a", "tok", false},
+	} {
+		got, err := c.CheckDNS(context.Background(), test.modulePath, test.token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("CheckDNS(%q, %q) = %t, want %t", test.modulePath, test.token, got, test.want)
+		}
+	}
+}
+
+func TestCheckFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/owner/repo/HEAD/sub/.well-known/pkgsite-verification":
+			w.Write([]byte("# pkgsite\n  tok  \n"))
+		case "/owner/broken/HEAD/.well-known/pkgsite-verification":
+			http.Error(w, "oops", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	// Send requests for raw content to the test server.
+	client := &http.Client{Transport: rewriteTransport(srv.URL)}
+	c := NewChecker(client, fakeResolver{})
+	ctx := context.Background()
+
+	info := source.NewGitHubInfo("https://github.com/owner/repo", "sub", "v1.0.0")
+	for _, test := range []struct {
+		info  *source.Info
+		token string
+		want  bool
+	}{
+		{info, "tok", true},
+		{info, "pkgsite", false},
+		{source.NewGitHubInfo("https://github.com/owner/repo", "", "v1.0.0"), "tok", false},
+	} {
+		got, err := c.CheckFile(ctx, test.info, test.token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("CheckFile(%q, %q) = %t, want %t", FileURL(test.info), test.token, got, test.want)
+		}
+	}
+	if _, err := c.CheckFile(ctx, source.NewGitHubInfo("https://github.com/owner/broken", "", "v1.0.0"), "tok"); err == nil {
+		t.Error("server error: got nil, want error")
+	}
+	if _, err := c.CheckFile(ctx, nil, "tok"); err == nil {
+		t.Error("no source: got nil, want error")
+	}
+}
+
+// rewriteTransport sends all requests to the server at baseURL.
+type rewriteTransport string
+
+func (t rewriteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r2 := r.Clone(r.Context())
+	r2.URL.Scheme = "http"
+	r2.URL.Host = strings.TrimPrefix(string(t), "http://")
+	return http.DefaultTransport.RoundTrip(r2)
+}
+
+func TestToken(t *testing.T) {
+	t1, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t2, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(t1) != 32 || t1 == t2 {
+		t.Errorf("got tokens %q and %q, want two different 32-character tokens", t1, t2)
+	}
+}
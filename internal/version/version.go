@@ -29,6 +29,10 @@ const (
 	TypePseudo = Type("pseudo")
 )
 
+// Master represents the master branch of a repository, as a version
+// that can be requested but that must be resolved to a pseudo-version.
+const Master = "master"
+
 func (t Type) String() string {
 	return string(t)
 }
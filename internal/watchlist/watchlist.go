@@ -0,0 +1,129 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package watchlist supports the lists of modules that the users of a private
+// deployment watch, and the email summaries of their new versions.
+package watchlist
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// A Watch records that a user watches a module.
+type Watch struct {
+	ModulePath string
+	CreatedAt  time.Time
+}
+
+// An Update is a new version of a watched module.
+type Update struct {
+	ModulePath string
+	Version    string
+	CommitTime time.Time
+}
+
+// A Summary lists the updates to the modules watched by one user.
+type Summary struct {
+	Email string
+	// Groups are the groups of the user when they last watched a module.
+	Groups  []string
+	Updates []*Update
+}
+
+// Filter removes the updates to the modules for which allowed returns false.
+func (s *Summary) Filter(allowed func(modulePath string) bool) {
+	var us []*Update
+	for _, u := range s.Updates {
+		if allowed(u.ModulePath) {
+			us = append(us, u)
+		}
+	}
+	s.Updates = us
+}
+
+// Subject returns the subject of the email for s.
+func (s *Summary) Subject() string {
+	if len(s.Updates) == 1 {
+		u := s.Updates[0]
+		return fmt.Sprintf("New version of %s: %s", u.ModulePath, u.Version)
+	}
+	return fmt.Sprintf("%d new versions of modules you watch", len(s.Updates))
+}
+
+// Body returns the text of the email for s. The versions link to the site at
+// siteURL.
+func (s *Summary) Body(siteURL string) string {
+	siteURL = strings.TrimSuffix(siteURL, "/")
+	var b strings.Builder
+	fmt.Fprintf(&b, "New versions of the modules you watch were published:\n\n")
+	for _, u := range s.Updates {
+		fmt.Fprintf(&b, "  %s %s (%s)\n", u.ModulePath, u.Version, u.CommitTime.UTC().Format("2006-01-02"))
+		fmt.Fprintf(&b, "    %s/mod/%s@%s\n", siteURL, u.ModulePath, u.Version)
+	}
+	fmt.Fprintf(&b, "\nTo stop watching a module, visit %s/watchlist.\n", siteURL)
+	return b.String()
+}
+
+// A Mailer sends email.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// An SMTPMailer sends plain-text email through an SMTP server.
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer returns an SMTPMailer that sends email from the address from
+// through the SMTP server at addr, a host:port. If user is not empty, it
+// authenticates with user and password.
+func NewSMTPMailer(addr, user, password, from string) (*SMTPMailer, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("NewSMTPMailer: bad address %q: %v", addr, err)
+	}
+	m := &SMTPMailer{addr: addr, from: from}
+	if user != "" {
+		m.auth = smtp.PlainAuth("", user, password, host)
+	}
+	return m, nil
+}
+
+// Send sends an email to the address to. The context is not used, since
+// net/smtp does not support cancelation.
+func (m *SMTPMailer) Send(ctx context.Context, to, subject, body string) error {
+	msg, err := message(m.from, to, subject, body)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, msg)
+}
+
+// message returns the email with the given header values and body.
+func message(from, to, subject, body string) ([]byte, error) {
+	for _, v := range []string{from, to, subject} {
+		// A newline would start another header.
+		if strings.ContainsAny(v, "\r\n") {
+			return nil, errors.New("newline in email header")
+		}
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&b, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: text/plain; charset=UTF-8\r\n")
+	fmt.Fprintf(&b, "\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return b.Bytes(), nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package watchlist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSummary(t *testing.T) {
+	commit := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := &Summary{
+		Email: "a@example.com",
+		Updates: []*Update{
+			{ModulePath: "example.com/a", Version: "v1.1.0", CommitTime: commit},
+			{ModulePath: "example.com/b", Version: "v0.2.0", CommitTime: commit},
+		},
+	}
+	if got, want := s.Subject(), "2 new versions of modules you watch"; got != want {
+		t.Errorf("Subject() = %q, want %q", got, want)
+	}
+	want := `New versions of the modules you watch were published:
+
+  example.com/a v1.1.0 (2020-06-01)
+    https://pkg.example.com/mod/example.com/a@v1.1.0
+  example.com/b v0.2.0 (2020-06-01)
+    https://pkg.example.com/mod/example.com/b@v0.2.0
+
+To stop watching a module, visit https://pkg.example.com/watchlist.
+`
+	if diff := cmp.Diff(want, s.Body("https://pkg.example.com/")); diff != "" {
+		t.Errorf("Body mismatch (-want +got):\n%s", diff)
+	}
+
+	s.Updates = s.Updates[:1]
+	if got, want := s.Subject(), "New version of example.com/a: v1.1.0"; got != want {
+		t.Errorf("Subject() = %q, want %q", got, want)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	got, err := message("site@example.com", "a@example.com", "hi", "line 1\nline 2\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "From: site@example.com\r\nTo: a@example.com\r\nSubject: hi\r\n" +
+		"MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\nline 1\r\nline 2\r\n"
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("message mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := message("site@example.com", "a@example.com\r\nBcc: b@example.com", "hi", ""); err == nil {
+		t.Error("got no error for a newline in a header")
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package webhook notifies other services, like dependency-update bots, when
+// the worker processes a new version of a module.
+//
+// A Hook registers a URL for the modules under a path prefix. When a version
+// of such a module is processed successfully for the first time, the worker
+// POSTs a JSON Event to the URL, signed with the secret of the hook: the
+// SignatureHeader of the request holds "sha256=" followed by the hex-encoded
+// HMAC-SHA256 of the body, keyed by the secret. Receivers should compute the
+// same signature and compare it with hmac.Equal.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+const (
+	// SignatureHeader is the request header holding the signature of the
+	// body.
+	SignatureHeader = "X-Pkgsite-Signature"
+	// EventHeader is the request header holding the type of the event.
+	EventHeader = "X-Pkgsite-Event"
+)
+
+// EventNewVersion is the type of the event sent when a new module version is
+// processed.
+const EventNewVersion = "new-version"
+
+// A Hook asks for the events about the modules under Prefix to be sent to
+// URL.
+type Hook struct {
+	ID     int64  `json:"id,omitempty"` // zero for hooks from the deployment config
+	Prefix string `json:"prefix"`
+	URL    string `json:"url"`
+	// Secret is the key that events are signed with. It is never shown
+	// after the hook is registered.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// Matches reports whether modulePath is Prefix or a path under it.
+func (h *Hook) Matches(modulePath string) bool {
+	return modulePath == h.Prefix || strings.HasPrefix(modulePath, strings.TrimSuffix(h.Prefix, "/")+"/")
+}
+
+// Validate reports whether h has a prefix, an HTTP or HTTPS URL and a secret.
+func (h *Hook) Validate() error {
+	if h.Prefix == "" {
+		return errors.New("missing prefix")
+	}
+	u, err := url.Parse(h.URL)
+	if err != nil {
+		return fmt.Errorf("bad URL: %v", err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("bad URL %q: must be an absolute HTTP or HTTPS URL", h.URL)
+	}
+	if h.Secret == "" {
+		return errors.New("missing secret")
+	}
+	return nil
+}
+
+// ReadHooks reads the hooks in the JSON file filename, which holds a list of
+// objects with the fields prefix, url and secret.
+func ReadHooks(filename string) (_ []*Hook, err error) {
+	defer derrors.Wrap(&err, "ReadHooks(%q)", filename)
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var hooks []*Hook
+	if err := json.Unmarshal(data, &hooks); err != nil {
+		return nil, err
+	}
+	for _, h := range hooks {
+		if err := h.Validate(); err != nil {
+			return nil, fmt.Errorf("hook for %q: %v", h.Prefix, err)
+		}
+	}
+	return hooks, nil
+}
+
+// An Event is the payload sent to a hook.
+type Event struct {
+	Type       string    `json:"type"`
+	ModulePath string    `json:"module_path"`
+	Version    string    `json:"version"`
+	Timestamp  time.Time `json:"timestamp"` // when the version was processed
+}
+
+// Sign returns the signature of body with secret, as sent in
+// SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// A Sender sends events to hooks.
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender returns a Sender that gives up on a hook after timeout.
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{client: &http.Client{Timeout: timeout}}
+}
+
+// Send POSTs e to the URL of h. It returns an error if the request fails or
+// the response status is not 2xx.
+func (s *Sender) Send(ctx context.Context, h *Hook, e *Event) (err error) {
+	defer derrors.Wrap(&err, "Send(ctx, %q, %q, %q)", h.URL, e.ModulePath, e.Version)
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pkgsite-webhook")
+	req.Header.Set(EventHeader, e.Type)
+	req.Header.Set(SignatureHeader, Sign(h.Secret, body))
+	resp, err := ctxhttp.Do(ctx, s.client, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMatches(t *testing.T) {
+	h := &Hook{Prefix: "github.com/a"}
+	for _, test := range []struct {
+		modulePath string
+		want       bool
+	}{
+		{"github.com/a", true},
+		{"github.com/a/b", true},
+		{"github.com/ab", false},
+		{"github.com", false},
+	} {
+		if got := h.Matches(test.modulePath); got != test.want {
+			t.Errorf("Matches(%q) = %t, want %t", test.modulePath, got, test.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		hook Hook
+		ok   bool
+	}{
+		{Hook{Prefix: "example.com", URL: "https://bot.example/hook", Secret: "s"}, true},
+		{Hook{Prefix: "example.com", URL: "http://localhost:8080/hook", Secret: "s"}, true},
+		{Hook{URL: "https://bot.example/hook", Secret: "s"}, false},
+		{Hook{Prefix: "example.com", URL: "ftp://bot.example/hook", Secret: "s"}, false},
+		{Hook{Prefix: "example.com", URL: "/hook", Secret: "s"}, false},
+		{Hook{Prefix: "example.com", URL: "https://bot.example/hook"}, false},
+	} {
+		err := test.hook.Validate()
+		if (err == nil) != test.ok {
+			t.Errorf("%+v: got error %v, want ok = %t", test.hook, err, test.ok)
+		}
+	}
+}
+
+func TestReadHooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "hooks.json")
+	data := `[{"prefix": "example.com", "url": "https://bot.example/hook", "secret": "s"}]`
+	if err := ioutil.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hooks, err := ReadHooks(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 1 || hooks[0].Prefix != "example.com" || hooks[0].Secret != "s" {
+		t.Errorf("got %+v, want the hook for example.com", hooks)
+	}
+
+	if err := ioutil.WriteFile(filename, []byte(`[{"prefix": "example.com"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadHooks(filename); err == nil {
+		t.Error("got no error for a hook without a URL")
+	}
+}
+
+func TestSend(t *testing.T) {
+	const secret = "secret"
+	var got Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sig := r.Header.Get(SignatureHeader); !hmac.Equal([]byte(sig), []byte(Sign(secret, body))) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(EventHeader) != EventNewVersion {
+			http.Error(w, "bad event", http.StatusBadRequest)
+			return
+		}
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	s := NewSender(time.Minute)
+	want := Event{
+		Type:       EventNewVersion,
+		ModulePath: "example.com/m",
+		Version:    "v1.2.3",
+		Timestamp:  time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if err := s.Send(ctx, &Hook{Prefix: "example.com", URL: srv.URL, Secret: secret}, &want); err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if err := s.Send(ctx, &Hook{Prefix: "example.com", URL: srv.URL, Secret: "wrong"}, &want); err == nil {
+		t.Error("got no error with the wrong secret")
+	}
+}
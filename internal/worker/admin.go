@@ -0,0 +1,227 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/abuse"
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/lookalike"
+	"golang.org/x/pkgsite/internal/middleware"
+)
+
+// localAdmin is the actor recorded for actions taken on a local worker, which
+// does not require administrators to sign in.
+const localAdmin = "local"
+
+// unknownAdmin is the actor recorded for actions whose administrator cannot
+// be identified.
+const unknownAdmin = "unknown"
+
+// iapUserHeader is the header in which Identity-Aware Proxy, which protects
+// the deployed worker, passes the email address of the user, prefixed by
+// "accounts.google.com:".
+const iapUserHeader = "X-Goog-Authenticated-User-Email"
+
+// numAuditLogActions is the number of recent actions shown on the moderation
+// dashboard.
+const numAuditLogActions = 50
+
+// adminMiddleware returns the middleware that protects the moderation
+// dashboard. Administrators must sign in and be members of one of the admin
+// groups. If no authenticator is configured, the dashboard is only served
+// by a local worker.
+func (s *Server) adminMiddleware() middleware.Middleware {
+	if s.adminAuth != nil {
+		return middleware.Authenticate(s.adminAuth, s.adminGroups)
+	}
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if s.cfg.OnAppEngine() || s.cfg.ServiceID != "" {
+				http.Error(w, "admin sign-in is not configured", http.StatusNotFound)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// adminActor returns the administrator making the request r, for the audit
+// log: the user signed in to the moderation dashboard, or else the user
+// identified by Identity-Aware Proxy.
+func (s *Server) adminActor(r *http.Request) string {
+	if u := auth.UserFromContext(r.Context()); u != nil {
+		return u.Email
+	}
+	if v := r.Header.Get(iapUserHeader); v != "" {
+		return strings.TrimPrefix(v, "accounts.google.com:")
+	}
+	if !s.cfg.OnAppEngine() && s.cfg.ServiceID == "" {
+		return localAdmin
+	}
+	return unknownAdmin
+}
+
+// adminButton is a form on the moderation dashboard that takes one action.
+type adminButton struct {
+	Kind, Target, Label string
+	// Report is the ID of the abuse report that the action resolves, if
+	// any.
+	Report string
+	// NeedsReason is whether the form asks for the reason of the action.
+	NeedsReason bool
+}
+
+// handleAdmin serves the moderation dashboard, which shows the pending abuse
+// reports and lookalike module paths, the exclusions, the superseded packages
+// and the audit log.
+func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) error {
+	if r.URL.Path != "/admin/" {
+		return &serverError{http.StatusNotFound, fmt.Errorf("unknown admin page %q", r.URL.Path)}
+	}
+	ctx := r.Context()
+	reports, err := s.db.GetAbuseReports(ctx, abuse.StatusPending)
+	if err != nil {
+		return err
+	}
+	flags, err := s.db.GetLookalikes(ctx, lookalike.StatusPending)
+	if err != nil {
+		return err
+	}
+	exclusions, err := s.db.GetExclusions(ctx)
+	if err != nil {
+		return err
+	}
+	supersessions, err := s.db.GetSupersessions(ctx, "")
+	if err != nil {
+		return err
+	}
+	actions, err := s.db.GetAuditLog(ctx, audit.Query{Limit: numAuditLogActions})
+	if err != nil {
+		return err
+	}
+	page := struct {
+		Env           string
+		User          string
+		Reports       []*abuse.Report
+		Lookalikes    []*lookalike.Flag
+		Exclusions    []*abuse.Exclusion
+		Supersessions []*abuse.Supersession
+		Actions       []*audit.Entry
+	}{
+		Env:           s.envName(),
+		User:          s.adminActor(r),
+		Reports:       reports,
+		Lookalikes:    flags,
+		Exclusions:    exclusions,
+		Supersessions: supersessions,
+		Actions:       actions,
+	}
+	var buf bytes.Buffer
+	if err := s.adminTemplate.Execute(&buf, page); err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, &buf); err != nil {
+		log.Errorf(ctx, "Error copying buffer to ResponseWriter: %v", err)
+	}
+	return nil
+}
+
+// handleAdminAction takes the action posted from the moderation dashboard,
+// and redirects back to it. If the form has a "report" value, the abuse
+// report with that ID is also resolved.
+func (s *Server) handleAdminAction(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodPost {
+		return &serverError{http.StatusMethodNotAllowed, fmt.Errorf("%s not allowed", r.Method)}
+	}
+	ctx := r.Context()
+	actor := s.adminActor(r)
+	a := &abuse.Action{
+		Actor:        actor,
+		Kind:         r.FormValue("kind"),
+		Target:       strings.TrimSpace(r.FormValue("target")),
+		Reason:       strings.TrimSpace(r.FormValue("reason")),
+		SupersededBy: strings.TrimSpace(r.FormValue("superseded_by")),
+	}
+	if err := s.db.Moderate(ctx, a); err != nil {
+		return &serverError{derrors.ToHTTPStatus(err), err}
+	}
+	log.Infof(ctx, "admin action by %s: %s %s", a.Actor, a.Kind, a.Target)
+	s.invalidateModerated(ctx, a)
+	if report := r.FormValue("report"); report != "" {
+		ra := &abuse.Action{
+			Actor:  actor,
+			Kind:   abuse.ActionResolveReport,
+			Target: report,
+			Reason: fmt.Sprintf("%s %s", a.Kind, a.Target),
+		}
+		if err := s.db.Moderate(ctx, ra); err != nil {
+			return &serverError{derrors.ToHTTPStatus(err), err}
+		}
+	}
+	http.Redirect(w, r, "/admin/", http.StatusSeeOther)
+	return nil
+}
+
+// invalidateModerated invalidates the pages that show the state changed by
+// a: those of the module of a reviewed lookalike flag, or of the module of a
+// superseded package.
+func (s *Server) invalidateModerated(ctx context.Context, a *abuse.Action) {
+	switch a.Kind {
+	case abuse.ActionConfirmLookalike, abuse.ActionDismissLookalike:
+		s.invalidateModule(ctx, a.Target)
+	case abuse.ActionSupersede, abuse.ActionUnsupersede:
+		modulePath, _, _, err := s.db.GetPathInfo(ctx, a.Target, internal.UnknownModulePath, internal.LatestVersion)
+		if err != nil {
+			log.Error(ctx, err)
+			return
+		}
+		s.invalidateModule(ctx, modulePath)
+	}
+}
+
+// handleAudit serves the entries of the audit log selected by the query
+// parameters of r, as JSON.
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) error {
+	q := audit.Query{
+		Actor:  r.FormValue("actor"),
+		Action: r.FormValue("action"),
+		Target: r.FormValue("target"),
+		Limit:  parseIntParam(r, "limit", audit.DefaultLimit),
+	}
+	if since := r.FormValue("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return &serverError{http.StatusBadRequest, fmt.Errorf("bad since: %v", err)}
+		}
+		q.Since = t
+	}
+	entries, err := s.db.GetAuditLog(r.Context(), q)
+	if err != nil {
+		return err
+	}
+	if entries == nil {
+		entries = []*audit.Entry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,176 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/abuse"
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/cdn"
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/middleware"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+// fakeAuthenticator signs in every request as its user.
+type fakeAuthenticator struct {
+	user *auth.User
+}
+
+func (a fakeAuthenticator) Authenticate(w http.ResponseWriter, r *http.Request) *auth.User {
+	return a.user
+}
+
+func TestAdmin(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer postgres.ResetTestDB(testDB, t)
+
+	id, err := testDB.InsertAbuseReport(ctx, &abuse.Report{Path: "bad.com/m", Kind: abuse.KindMalware}, "reporter")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newMux := func(cfg *config.Config, a middleware.Authenticator) *http.ServeMux {
+		t.Helper()
+		scfg := ServerConfig{DB: testDB, StaticPath: "../../content/static"}
+		if a != nil {
+			scfg.AdminAuthenticator = a
+			scfg.AdminGroups = []string{"admins"}
+		}
+		s, err := NewServer(cfg, scfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mux := http.NewServeMux()
+		s.Install(mux.Handle)
+		return mux
+	}
+	serve := func(mux *http.ServeMux, r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+	takedown := func() *http.Request {
+		form := url.Values{
+			"kind":   {abuse.ActionExclude},
+			"target": {"bad.com"},
+			"reason": {"malware"},
+			"report": {strconv.FormatInt(id, 10)},
+		}
+		r := httptest.NewRequest("POST", "/admin/action", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+
+	// Without sign-in, only a local worker serves the dashboard.
+	if w := serve(newMux(&config.Config{ServiceID: "etl"}, nil), httptest.NewRequest("GET", "/admin/", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("deployed worker without sign-in: got code %d, want %d", w.Code, http.StatusNotFound)
+	}
+	outsider := fakeAuthenticator{&auth.User{Email: "someone@example.com", Groups: []string{"users"}}}
+	if w := serve(newMux(&config.Config{ServiceID: "etl"}, outsider), takedown()); w.Code != http.StatusForbidden {
+		t.Errorf("user not in an admin group: got code %d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	admin := fakeAuthenticator{&auth.User{Email: "admin@example.com", Groups: []string{"admins"}}}
+	mux := newMux(&config.Config{ServiceID: "etl"}, admin)
+	w := serve(mux, httptest.NewRequest("GET", "/admin/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /admin/: got code %d, want %d", w.Code, http.StatusOK)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "bad.com/m") {
+		t.Errorf("dashboard does not show the pending report:\n%s", body)
+	}
+	if w := serve(mux, takedown()); w.Code != http.StatusSeeOther {
+		t.Fatalf("POST /admin/action: got code %d, want %d; body:\n%s", w.Code, http.StatusSeeOther, w.Body)
+	}
+
+	excluded, err := testDB.IsExcluded(ctx, "bad.com/m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !excluded {
+		t.Error("bad.com/m is not excluded after the takedown")
+	}
+	entries, err := testDB.GetAuditLog(ctx, audit.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Actor+" "+e.Action+" "+e.Target)
+	}
+	want := []string{
+		"admin@example.com resolve-report " + strconv.FormatInt(id, 10),
+		"admin@example.com exclude bad.com",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("audit log:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	w = serve(mux, httptest.NewRequest("GET", "/admin/audit?action=exclude", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /admin/audit: got code %d, want %d", w.Code, http.StatusOK)
+	}
+	var served []*audit.Entry
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil {
+		t.Fatal(err)
+	}
+	if len(served) != 1 || served[0].Target != "bad.com" || served[0].Actor != "admin@example.com" {
+		t.Errorf("GET /admin/audit?action=exclude: got %s", w.Body)
+	}
+}
+
+func TestAdminActionPurges(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer postgres.ResetTestDB(testDB, t)
+
+	if err := testDB.InsertModule(ctx, sample.Module("github.com/a/m", sample.VersionString, "pkg")); err != nil {
+		t.Fatal(err)
+	}
+	var purged []string
+	purger := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		purged = append(purged, r.URL.Path)
+	}))
+	defer purger.Close()
+
+	s, err := NewServer(&config.Config{}, ServerConfig{
+		DB:         testDB,
+		StaticPath: "../../content/static",
+		CDNPurger:  cdn.NewPurger(purger.URL, "key"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.Install(mux.Handle)
+	form := url.Values{
+		"kind":          {abuse.ActionSupersede},
+		"target":        {"github.com/a/m/pkg"},
+		"superseded_by": {"github.com/b/n/pkg"},
+	}
+	r := httptest.NewRequest("POST", "/admin/action", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("POST /admin/action: got code %d, want %d; body:\n%s", w.Code, http.StatusSeeOther, w.Body)
+	}
+	// The pages of the module of the superseded package show a banner now.
+	if want := "/purge/" + cdn.ModuleKey("github.com/a/m"); len(purged) != 1 || purged[0] != want {
+		t.Errorf("purged %q, want [%q]", purged, want)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/apikey"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// handleAPIKeys lists the API keys with their recent usage, or issues or
+// revokes one of them.
+func (s *Server) handleAPIKeys(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	if r.Method == http.MethodPost {
+		actor := s.adminActor(r)
+		if rv := r.FormValue("revoke"); rv != "" {
+			id, err := strconv.ParseInt(rv, 10, 64)
+			if err != nil {
+				return &serverError{http.StatusBadRequest, fmt.Errorf("bad id: %v", err)}
+			}
+			if err := s.db.RevokeAPIKey(ctx, id, actor); err != nil {
+				return &serverError{derrors.ToHTTPStatus(err), err}
+			}
+			log.Infof(ctx, "API key %d revoked by %s", id, actor)
+			fmt.Fprintf(w, "revoked API key %d\n", id)
+			return nil
+		}
+		name := strings.TrimSpace(r.FormValue("name"))
+		tier := strings.TrimSpace(r.FormValue("tier"))
+		if _, ok := s.cfg.Quota.APIKeyTiers[tier]; !ok {
+			return &serverError{http.StatusBadRequest, fmt.Errorf("unknown tier %q", tier)}
+		}
+		id, key, err := s.db.InsertAPIKey(ctx, name, tier, actor)
+		if err != nil {
+			return &serverError{derrors.ToHTTPStatus(err), err}
+		}
+		log.Infof(ctx, "API key %d for %s issued by %s", id, name, actor)
+		// This is the only time the key is shown.
+		fmt.Fprintf(w, "issued API key %d: %s\n", id, key)
+		return nil
+	}
+	keys, err := s.db.GetAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if keys == nil {
+		keys = []*apikey.Key{}
+	}
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/collection"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// handleCollections lists the curated collections of packages, or saves or
+// deletes one of them.
+func (s *Server) handleCollections(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	if r.Method == http.MethodPost {
+		actor := s.adminActor(r)
+		if name := r.FormValue("delete"); name != "" {
+			if err := s.db.DeleteCollection(ctx, name, actor); err != nil {
+				return &serverError{derrors.ToHTTPStatus(err), err}
+			}
+			log.Infof(ctx, "collection %q deleted by %s", name, actor)
+			fmt.Fprintf(w, "deleted collection %q\n", name)
+			return nil
+		}
+		c := &collection.Collection{
+			Name:        strings.TrimSpace(r.FormValue("name")),
+			Title:       strings.TrimSpace(r.FormValue("title")),
+			Description: strings.TrimSpace(r.FormValue("description")),
+			Packages:    collection.ParsePackages(r.FormValue("packages")),
+		}
+		if err := s.db.SaveCollection(ctx, c, actor); err != nil {
+			return &serverError{derrors.ToHTTPStatus(err), err}
+		}
+		log.Infof(ctx, "collection %q with %d packages saved by %s", c.Name, len(c.Packages), actor)
+		fmt.Fprintf(w, "saved collection %q with %d packages\n", c.Name, len(c.Packages))
+		return nil
+	}
+	cs, err := s.db.GetCollections(ctx)
+	if err != nil {
+		return err
+	}
+	for i, c := range cs {
+		cs[i], err = s.db.GetCollection(ctx, c.Name)
+		if err != nil {
+			return err
+		}
+	}
+	if cs == nil {
+		cs = []*collection.Collection{}
+	}
+	data, err := json.MarshalIndent(cs, "", "  ")
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(data)
+	return err
+}
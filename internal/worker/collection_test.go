@@ -0,0 +1,76 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal/collection"
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+func TestHandleCollections(t *testing.T) {
+	defer postgres.ResetTestDB(testDB, t)
+
+	s, err := NewServer(&config.Config{}, ServerConfig{DB: testDB, StaticPath: "../../content/static"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.Install(mux.Handle)
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/collections", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	form := url.Values{
+		"name":     {"http-routers"},
+		"title":    {"HTTP routers"},
+		"packages": {"github.com/gorilla/mux A powerful router.\ngithub.com/go-chi/chi"},
+	}
+	if w := post(form); w.Code != http.StatusOK {
+		t.Fatalf("save: got code %d, want 200: %s", w.Code, w.Body)
+	}
+	if w := post(url.Values{"name": {"HTTP routers"}, "title": {"HTTP routers"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("save with a bad name: got code %d, want 400", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/collections", nil))
+	var got []*collection.Collection
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []*collection.Collection{{
+		Name:  "http-routers",
+		Title: "HTTP routers",
+		Packages: []*collection.Package{
+			{Path: "github.com/gorilla/mux", Blurb: "A powerful router."},
+			{Path: "github.com/go-chi/chi"},
+		},
+		UpdatedBy: localAdmin,
+	}}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(collection.Collection{}, "UpdatedAt")); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if w := post(url.Values{"delete": {"http-routers"}}); w.Code != http.StatusOK {
+		t.Errorf("delete: got code %d, want 200: %s", w.Code, w.Body)
+	}
+	if w := post(url.Values{"delete": {"http-routers"}}); w.Code != http.StatusNotFound {
+		t.Errorf("delete twice: got code %d, want 404", w.Code)
+	}
+}
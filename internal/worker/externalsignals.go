@@ -0,0 +1,73 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/signals"
+)
+
+// handleRefreshExternalSignals gets the signals of each external signal
+// provider for the modules whose signals are missing, or older than the
+// "hours" query parameter (default 24). At most "limit" (default 1000)
+// modules are refreshed per provider. Signals that could not be got are
+// tried again the next time.
+func (s *Server) handleRefreshExternalSignals(w http.ResponseWriter, r *http.Request) error {
+	if len(s.signalProviders) == 0 {
+		return &serverError{http.StatusNotImplemented, errors.New("no external signal providers configured")}
+	}
+	ctx := r.Context()
+	hours := parseIntParam(r, "hours", 24)
+	limit := parseIntParam(r, "limit", 1000)
+	staleBefore := time.Now().Add(-time.Duration(hours) * time.Hour)
+	for _, p := range s.signalProviders {
+		mis, err := s.db.GetModulesForExternalSignals(ctx, p.Name(), staleBefore, limit)
+		if err != nil {
+			return err
+		}
+		var nrefreshed, nfailed int
+		for _, mi := range mis {
+			sig, err := p.Get(ctx, mi.ModulePath, mi.SourceInfo.RepoURL())
+			if err != nil {
+				log.Errorf(ctx, "getting %s signal of %s: %v", p.Name(), mi.ModulePath, err)
+				nfailed++
+				continue
+			}
+			if sig == nil {
+				// Remember that the service has nothing about the module, so
+				// that it is not asked again until the signal is stale.
+				sig = &signals.Signal{Provider: p.Name(), FetchedAt: time.Now()}
+			}
+			old, err := s.db.GetExternalSignals(ctx, mi.ModulePath, []string{p.Name()})
+			if err != nil {
+				return err
+			}
+			if err := s.db.UpsertExternalSignal(ctx, mi.ModulePath, sig); err != nil {
+				return err
+			}
+			if signalChanged(old, sig) {
+				s.invalidateModule(ctx, mi.ModulePath)
+			}
+			nrefreshed++
+		}
+		log.Infof(ctx, "refreshed %d %s signals, %d failed", nrefreshed, p.Name(), nfailed)
+		fmt.Fprintf(w, "refreshed %d %s signals, %d failed\n", nrefreshed, p.Name(), nfailed)
+	}
+	return nil
+}
+
+// signalChanged reports whether sig shows differently on pages than old, the
+// signals of its provider stored before, which leave out empty labels.
+func signalChanged(old []*signals.Signal, sig *signals.Signal) bool {
+	if len(old) == 0 {
+		return sig.Label != ""
+	}
+	return old[0].Label != sig.Label || old[0].URL != sig.URL
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/signals"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+// fakeProvider is a signals.Provider that labels the repositories it knows,
+// and fails for the repository fail.
+type fakeProvider struct {
+	labels map[string]string
+	fail   string
+	calls  int
+}
+
+func (*fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Get(ctx context.Context, modulePath, repoURL string) (*signals.Signal, error) {
+	p.calls++
+	if repoURL == p.fail {
+		return nil, errors.New("unavailable")
+	}
+	label, ok := p.labels[repoURL]
+	if !ok {
+		return nil, nil
+	}
+	return &signals.Signal{Provider: "fake", Label: label, FetchedAt: time.Now()}, nil
+}
+
+func TestRefreshExternalSignals(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer postgres.ResetTestDB(testDB, t)
+
+	for _, m := range []string{"github.com/a/a", "github.com/b/b", "github.com/c/c"} {
+		if err := testDB.InsertModule(ctx, sample.Module(m, sample.VersionString, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := &fakeProvider{
+		labels: map[string]string{"https://github.com/a/a": "A+"},
+		fail:   "https://github.com/c/c",
+	}
+	s, err := NewServer(&config.Config{}, ServerConfig{
+		DB:              testDB,
+		StaticPath:      "../../content/static",
+		SignalProviders: []signals.Provider{p},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.Install(mux.Handle)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/refresh-external-signals", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("got code %d, want 200", w.Code)
+		}
+	}
+	// The signals of a and b are fresh after the first refresh; c, which
+	// failed, is tried again.
+	if p.calls != 4 {
+		t.Errorf("got %d calls to the provider, want 4", p.calls)
+	}
+	sigs, err := testDB.GetExternalSignals(ctx, "github.com/a/a", []string{"fake"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sigs) != 1 || sigs[0].Label != "A+" {
+		t.Errorf("got signals %v, want one labeled A+", sigs)
+	}
+}
@@ -88,9 +88,33 @@ func FetchAndUpdateState(ctx context.Context, modulePath, requestedVersion strin
 		return http.StatusInternalServerError, ft.Error
 	}
 	logTaskResult(ctx, ft, "Updated module version state")
+	if ft.Status < http.StatusInternalServerError {
+		// The fetch finished, so it won't be resumed. After a server error,
+		// like a timeout, the fetch is retried, and uses the checkpoint.
+		if err := db.DeleteCheckpoint(ctx, ft.ModulePath, ft.ResolvedVersion); err != nil {
+			log.Error(ctx, err)
+		}
+	}
 	return ft.Status, ft.Error
 }
 
+// checkpointStore saves the checkpoints of fetches in the database.
+type checkpointStore struct {
+	db *postgres.DB
+}
+
+func (cs checkpointStore) LoadCheckpoint(ctx context.Context, modulePath, version string) (*fetch.Checkpoint, error) {
+	pkgs, states, err := cs.db.LoadCheckpoint(ctx, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+	return &fetch.Checkpoint{Packages: pkgs, States: states}, nil
+}
+
+func (cs checkpointStore) SaveCheckpoint(ctx context.Context, modulePath, version string, cp *fetch.Checkpoint) error {
+	return cs.db.SaveCheckpoint(ctx, modulePath, version, cp.Packages, cp.States)
+}
+
 // fetchAndInsertModule fetches the given module version from the module proxy
 // or (in the case of the standard library) from the Go repo and writes the
 // resulting data to the database.
@@ -131,7 +155,8 @@ func fetchAndInsertModule(ctx context.Context, modulePath, requestedVersion stri
 	}
 
 	start := time.Now()
-	fr := fetch.FetchModule(ctx, modulePath, requestedVersion, proxyClient, sourceClient)
+	fr := fetch.FetchModuleWithCheckpoints(ctx, modulePath, requestedVersion, proxyClient, sourceClient,
+		checkpointStore{db}, config.CheckpointZipSize())
 	if fr == nil {
 		panic("fetch.FetchModule should never return a nil FetchResult")
 	}
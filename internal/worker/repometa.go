@@ -0,0 +1,82 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/repometa"
+)
+
+// handleRefreshRepositoryMetadata gets from their code hosts the metadata of
+// the repositories of modules whose metadata is missing, or older than the
+// "hours" query parameter (default 24), at most "limit" (default 1000) of
+// them. Repositories on hosts whose rate limit is exhausted are skipped, to
+// be refreshed the next time.
+func (s *Server) handleRefreshRepositoryMetadata(w http.ResponseWriter, r *http.Request) error {
+	if s.repoClient == nil {
+		return &serverError{http.StatusNotImplemented, errors.New("repository metadata is not enabled")}
+	}
+	ctx := r.Context()
+	hours := parseIntParam(r, "hours", 24)
+	limit := parseIntParam(r, "limit", 1000)
+	urls, err := s.db.GetRepositoriesForMetadata(ctx, time.Now().Add(-time.Duration(hours)*time.Hour), limit)
+	if err != nil {
+		return err
+	}
+	var nrefreshed, nlimited, nfailed int
+	for _, u := range urls {
+		md, err := s.repoClient.Get(ctx, u)
+		var rle *repometa.RateLimitError
+		switch {
+		case errors.As(err, &rle):
+			nlimited++
+			continue
+		case err != nil:
+			log.Errorf(ctx, "getting metadata of repository %s: %v", u, err)
+			nfailed++
+			continue
+		case md == nil:
+			// Not on a supported code host.
+			continue
+		}
+		old, err := s.db.GetRepositoryMetadata(ctx, u)
+		if err != nil && !errors.Is(err, derrors.NotFound) {
+			return err
+		}
+		if err := s.db.UpsertRepositoryMetadata(ctx, md); err != nil {
+			return err
+		}
+		if archiveChanged(old, md) {
+			// The pages of the modules in the repository warn that it is
+			// archived.
+			modulePaths, err := s.db.GetModulePathsForRepository(ctx, u)
+			if err != nil {
+				return err
+			}
+			for _, modulePath := range modulePaths {
+				s.invalidateModule(ctx, modulePath)
+			}
+		}
+		nrefreshed++
+	}
+	log.Infof(ctx, "refreshed the metadata of %d repositories, %d rate limited, %d failed", nrefreshed, nlimited, nfailed)
+	fmt.Fprintf(w, "refreshed the metadata of %d repositories, %d rate limited, %d failed\n", nrefreshed, nlimited, nfailed)
+	return nil
+}
+
+// archiveChanged reports whether the archive warning of a repository with
+// metadata md differs from the one with old, which is nil if there was none.
+func archiveChanged(old, md *repometa.Metadata) bool {
+	if old == nil || old.Archived != md.Archived {
+		return md.Archived
+	}
+	return md.Archived && !old.LastActivity.Equal(md.LastActivity)
+}
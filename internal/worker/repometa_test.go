@@ -0,0 +1,80 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/cdn"
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/repometa"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestRefreshRepositoryMetadata(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer postgres.ResetTestDB(testDB, t)
+
+	for _, m := range []string{"github.com/a/a", "github.com/b/b"} {
+		if err := testDB.InsertModule(ctx, sample.Module(m, sample.VersionString, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hosts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github/repos/a/a":
+			w.Write([]byte(`{"stargazers_count": 5, "archived": true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer hosts.Close()
+	var purged []string
+	purger := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		purged = append(purged, r.URL.Path)
+	}))
+	defer purger.Close()
+
+	s, err := NewServer(&config.Config{}, ServerConfig{
+		DB:         testDB,
+		StaticPath: "../../content/static",
+		RepoClient: repometa.NewTestClient(hosts.Client(), hosts.URL),
+		CDNPurger:  cdn.NewPurger(purger.URL, "key"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.Install(mux.Handle)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/refresh-repository-metadata", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got code %d, want 200", w.Code)
+	}
+
+	md, err := testDB.GetRepositoryMetadata(ctx, "https://github.com/a/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !md.Archived || md.Stars != 5 {
+		t.Errorf("got %+v, want an archived repository with 5 stars", md)
+	}
+	md, err = testDB.GetRepositoryMetadata(ctx, "https://github.com/b/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !md.Missing {
+		t.Errorf("got %+v, want a missing repository", md)
+	}
+	// Only the module whose repository became archived is purged.
+	if want := "/purge/" + cdn.ModuleKey("github.com/a/a"); len(purged) != 1 || purged[0] != want {
+		t.Errorf("purged %q, want [%q]", purged, want)
+	}
+}
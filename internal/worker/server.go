@@ -22,16 +22,27 @@ import (
 	"github.com/go-redis/redis/v7"
 	"go.opencensus.io/trace"
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/abuse"
+	"golang.org/x/pkgsite/internal/audit"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/cdn"
 	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/database"
 	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/elasticsearch"
 	"golang.org/x/pkgsite/internal/index"
 	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/lookalike"
 	"golang.org/x/pkgsite/internal/middleware"
 	"golang.org/x/pkgsite/internal/postgres"
 	"golang.org/x/pkgsite/internal/proxy"
 	"golang.org/x/pkgsite/internal/queue"
+	"golang.org/x/pkgsite/internal/repometa"
+	"golang.org/x/pkgsite/internal/signals"
 	"golang.org/x/pkgsite/internal/source"
 	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/watchlist"
+	"golang.org/x/pkgsite/internal/webhook"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -46,9 +57,21 @@ type Server struct {
 	db                   *postgres.DB
 	queue                queue.Queue
 	reportingClient      *errorreporting.Client
+	cdnPurger            *cdn.Purger
+	searchBackend        *elasticsearch.Backend
 	taskIDChangeInterval time.Duration
+	adminAuth            middleware.Authenticator
+	adminGroups          []string
+	webhooks             []*webhook.Hook
+	webhookSender        *webhook.Sender
+	mailer               watchlist.Mailer
+	siteURL              string
+	acl                  *auth.ACL
+	signalProviders      []signals.Provider
+	repoClient           *repometa.Client
 
 	indexTemplate *template.Template
+	adminTemplate *template.Template
 }
 
 // ServerConfig contains everything needed by a Server.
@@ -61,18 +84,50 @@ type ServerConfig struct {
 	RedisCacheClient     *redis.Client
 	Queue                queue.Queue
 	ReportingClient      *errorreporting.Client
+	CDNPurger            *cdn.Purger
 	TaskIDChangeInterval time.Duration
 	StaticPath           string
+	// SearchBackend, if non-nil, is an Elasticsearch index that search
+	// documents are copied to.
+	SearchBackend *elasticsearch.Backend
+	// AdminAuthenticator, if non-nil, signs in the administrators who use
+	// the moderation dashboard. They must be members of one of AdminGroups.
+	AdminAuthenticator middleware.Authenticator
+	AdminGroups        []string
+	// Webhooks are notified of new module versions, in addition to the
+	// webhooks registered in the database.
+	Webhooks []*webhook.Hook
+	// Mailer, if non-nil, sends the watchlist summaries, with links to the
+	// frontend at SiteURL.
+	Mailer  watchlist.Mailer
+	SiteURL string
+	// ACL, if non-nil, restricts the modules listed in watchlist summaries
+	// to those that the watchers may see.
+	ACL *auth.ACL
+	// SignalProviders are the providers of the external signals that are
+	// shown on the pages of modules.
+	SignalProviders []signals.Provider
+	// RepoClient, if non-nil, gets the metadata of the repositories of
+	// modules from their code hosts.
+	RepoClient *repometa.Client
 }
 
 // NewServer creates a new Server with the given dependencies.
 func NewServer(cfg *config.Config, scfg ServerConfig) (_ *Server, err error) {
 	defer derrors.Wrap(&err, "NewServer(db, %+v)", scfg)
 
-	indexTemplate, err := parseTemplate(scfg.StaticPath)
+	indexTemplate, err := parseTemplate(scfg.StaticPath, "index.tmpl")
 	if err != nil {
 		return nil, err
 	}
+	adminTemplate, err := parseTemplate(scfg.StaticPath, "admin.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	if scfg.AdminAuthenticator != nil && len(scfg.AdminGroups) == 0 {
+		// Otherwise anyone who can sign in with the provider could moderate.
+		return nil, errors.New("admin sign-in needs admin groups")
+	}
 
 	return &Server{
 		cfg:                  cfg,
@@ -84,7 +139,19 @@ func NewServer(cfg *config.Config, scfg ServerConfig) (_ *Server, err error) {
 		redisCacheClient:     scfg.RedisCacheClient,
 		queue:                scfg.Queue,
 		reportingClient:      scfg.ReportingClient,
+		cdnPurger:            scfg.CDNPurger,
+		searchBackend:        scfg.SearchBackend,
+		adminAuth:            scfg.AdminAuthenticator,
+		adminGroups:          scfg.AdminGroups,
+		webhooks:             scfg.Webhooks,
+		webhookSender:        webhook.NewSender(webhookTimeout),
+		mailer:               scfg.Mailer,
+		siteURL:              scfg.SiteURL,
+		acl:                  scfg.ACL,
+		signalProviders:      scfg.SignalProviders,
+		repoClient:           scfg.RepoClient,
 		indexTemplate:        indexTemplate,
+		adminTemplate:        adminTemplate,
 		taskIDChangeInterval: scfg.TaskIDChangeInterval,
 	}, nil
 }
@@ -112,6 +179,24 @@ func (s *Server) Install(handle func(string, http.Handler)) {
 	// This endpoint is invoked by a Cloud Scheduler job.
 	handle("/update-imported-by-count", rmw(s.errorHandler(s.handleUpdateImportedByCount)))
 
+	// cloud-scheduler: update-search-ranks recomputes the ranks of packages in
+	// search_documents, which change as their latest versions age. Ranks are
+	// also recomputed by update-imported-by-count.
+	// This endpoint is invoked by a Cloud Scheduler job.
+	handle("/update-search-ranks", rmw(s.errorHandler(s.handleUpdateSearchRanks)))
+
+	// cloud-scheduler: check-search-documents reports packages that are
+	// missing from search_documents, or whose search document is not for
+	// their latest version. With repair=true, it also fixes them.
+	// This endpoint is invoked by a Cloud Scheduler job.
+	handle("/check-search-documents", rmw(s.errorHandler(s.handleCheckSearchDocuments)))
+
+	// cloud-scheduler: sync-search-backend copies search documents that
+	// changed since the last sync to the Elasticsearch index, if one is
+	// configured.
+	// This endpoint is invoked by a Cloud Scheduler job.
+	handle("/sync-search-backend", rmw(s.errorHandler(s.handleSyncSearchBackend)))
+
 	// cloud-scheduler: download search document data and update the redis sorted
 	// set(s) used in auto-completion.
 	handle("/update-redis-indexes", rmw(s.errorHandler(s.handleUpdateRedisIndexes)))
@@ -148,11 +233,115 @@ func (s *Server) Install(handle func(string, http.Handler)) {
 	// see the comments on duplicate tasks for "/requeue", above.
 	handle("/populate-stdlib", rmw(s.errorHandler(s.handlePopulateStdLib)))
 
+	// cloud-scheduler: fetch-std-master schedules a fetch of the Go standard
+	// library at master, so that documentation for unreleased changes is
+	// available at /std@master. It should be run periodically; the task ID
+	// changes every taskIDChangeInterval, so more frequent requests are
+	// de-duplicated.
+	handle("/fetch-std-master", rmw(s.errorHandler(s.handleFetchStdMaster)))
+
 	// manual: populate-search-documents repopulates every row in the
 	// search_documents table that was last updated before the time in the
-	// "before" query parameter.
+	// "before" query parameter. search_documents is kept up to date as modules
+	// are inserted and deleted, so this is only needed when the way search
+	// documents are computed changes.
 	handle("/repopulate-search-documents", rmw(s.errorHandler(s.handleRepopulateSearchDocuments)))
 
+	// manual: compress-documentation compresses documentation HTML and
+	// READMEs that were stored uncompressed, at most "limit" values per table.
+	// Call it repeatedly until it reports that nothing was compressed.
+	handle("/compress-documentation", rmw(s.errorHandler(s.handleCompressDocumentation)))
+
+	// cloud-scheduler: prune-pseudo-versions deletes the documentation of
+	// old pseudo-versions of modules that have tagged versions, according to
+	// the configured retention, at most "limit" versions per call.
+	// This endpoint is invoked by a Cloud Scheduler job.
+	handle("/prune-pseudo-versions", rmw(s.errorHandler(s.handlePrunePseudoVersions)))
+
+	// cloud-scheduler: compute-similar-packages computes the packages most
+	// similar to each package, from the packages that import both, the go.mod
+	// files that require both modules and their synopses, for the packages
+	// whose similar packages are missing or older than "hours" hours (default
+	// 168), at most "limit" (default 1000) of them.
+	// This endpoint is invoked by a Cloud Scheduler job.
+	handle("/compute-similar-packages", rmw(s.errorHandler(s.handleComputeSimilarPackages)))
+
+	// cloud-scheduler: flag-lookalikes flags module paths first seen in the
+	// last "hours" hours (default 25) that look confusingly like the paths
+	// of popular modules, so that they can be reviewed.
+	// This endpoint is invoked by a Cloud Scheduler job.
+	handle("/flag-lookalikes", rmw(s.errorHandler(s.handleFlagLookalikes)))
+
+	// cloud-scheduler: notify-watchlists emails each user of a private
+	// deployment a summary of the new versions of the modules they watch,
+	// since the last summary.
+	// This endpoint is invoked by a Cloud Scheduler job.
+	handle("/notify-watchlists", rmw(s.errorHandler(s.handleNotifyWatchlists)))
+
+	// cloud-scheduler: refresh-external-signals gets the signals about
+	// modules from the external signal providers, like the stars of their
+	// repositories, for the modules whose signals are missing or older than
+	// "hours" hours (default 24), at most "limit" (default 1000) modules per
+	// provider.
+	// This endpoint is invoked by a Cloud Scheduler job.
+	handle("/refresh-external-signals", rmw(s.errorHandler(s.handleRefreshExternalSignals)))
+
+	// cloud-scheduler: refresh-repository-metadata gets the metadata of the
+	// repositories of modules from GitHub and GitLab, like whether they are
+	// archived, for the repositories whose metadata is missing or older than
+	// "hours" hours (default 24), at most "limit" (default 1000) of them.
+	// This endpoint is invoked by a Cloud Scheduler job.
+	handle("/refresh-repository-metadata", rmw(s.errorHandler(s.handleRefreshRepositoryMetadata)))
+
+	// manual: lookalikes lists the flagged module paths with the given
+	// "status" (default pending). A POST with the form values module_path and
+	// status (confirmed or dismissed) records a review of one of them.
+	handle("/lookalikes", rmw(s.errorHandler(s.handleLookalikes)))
+
+	// manual: abuse-reports lists the abuse reports sent from the frontend
+	// with the given "status" (default pending). A POST with the form values
+	// id and status (resolved or dismissed) records a review of one of them.
+	handle("/abuse-reports", rmw(s.errorHandler(s.handleAbuseReports)))
+
+	// manual: webhooks lists the webhooks registered in the database, without
+	// their secrets. A POST with the form values prefix, url and secret
+	// registers a webhook, and a POST with the form value delete deletes the
+	// webhook with that ID.
+	handle("/webhooks", rmw(s.errorHandler(s.handleWebhooks)))
+
+	// manual: api-keys lists the API keys of the public API, with the number
+	// of requests made with them in the last 30 days, but not the keys
+	// themselves. A POST with the form values name and tier issues a key and
+	// shows it once, and a POST with the form value revoke revokes the key
+	// with that ID.
+	handle("/api-keys", rmw(s.errorHandler(s.handleAPIKeys)))
+
+	// manual: collections lists the curated collections of packages shown by
+	// the frontend at /collections/<name>, with their packages. A POST with
+	// the form values name, title, description and packages, which holds one
+	// package path per line optionally followed by a blurb, creates or
+	// replaces a collection, and a POST with the form value delete deletes
+	// the collection with that name.
+	handle("/collections", rmw(s.errorHandler(s.handleCollections)))
+
+	// admin: the moderation dashboard shows the pending abuse reports and
+	// lookalike module paths, the exclusions, the superseded packages and
+	// the audit log, with forms to act on them. Administrators must sign in; see adminMiddleware.
+	admin := s.adminMiddleware()
+	handle("/admin/", admin(rmw(s.errorHandler(s.handleAdmin))))
+	handle("/admin/action", admin(rmw(s.errorHandler(s.handleAdminAction))))
+
+	// admin: audit returns the entries of the audit log as JSON, newest
+	// first. The "actor", "action" and "target" query parameters select
+	// entries with those values, "since" (RFC 3339) selects recent entries,
+	// and "limit" (default 100) is the maximum number of entries.
+	handle("/admin/audit", admin(rmw(s.errorHandler(s.handleAudit))))
+
+	// manual: db-pool shows the limits and statistics of the database
+	// connection pool as JSON. A POST with any of the form values max_open,
+	// max_idle and max_lifetime changes those limits.
+	handle("/db-pool", database.PoolHandler(s.db.Underlying()))
+
 	// manual: clear-cache clears the redis cache.
 	handle("/clear-cache", rmw(s.errorHandler(s.clearCache)))
 
@@ -170,6 +359,59 @@ func (s *Server) handleUpdateImportedByCount(w http.ResponseWriter, r *http.Requ
 	return nil
 }
 
+// handleUpdateSearchRanks updates the search rank of all packages.
+func (s *Server) handleUpdateSearchRanks(w http.ResponseWriter, r *http.Request) error {
+	n, err := s.db.UpdateSearchDocumentsRank(r.Context())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "updated %d packages", n)
+	return nil
+}
+
+// handleCheckSearchDocuments reports drift between the packages and
+// search_documents tables, and optionally repairs it.
+func (s *Server) handleCheckSearchDocuments(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	drift, err := s.db.CheckSearchDocuments(ctx)
+	if err != nil {
+		return err
+	}
+	if len(drift.Missing) > 0 || len(drift.Stale) > 0 {
+		log.Errorf(ctx, "search_documents drift: %s", drift)
+	}
+	fmt.Fprintf(w, "%s\n", drift)
+	for _, p := range drift.Missing {
+		fmt.Fprintf(w, "missing %s\n", p)
+	}
+	for _, p := range drift.Stale {
+		fmt.Fprintf(w, "stale %s\n", p)
+	}
+	if r.FormValue("repair") != "true" {
+		return nil
+	}
+	if err := s.db.RepairSearchDocuments(ctx, append(drift.Missing, drift.Stale...)); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "repaired %d packages\n", len(drift.Missing)+len(drift.Stale))
+	return nil
+}
+
+// handleSyncSearchBackend copies up to "limit" changed search documents to
+// the search backend.
+func (s *Server) handleSyncSearchBackend(w http.ResponseWriter, r *http.Request) error {
+	if s.searchBackend == nil {
+		return &serverError{http.StatusFailedDependency, errors.New("no search backend configured")}
+	}
+	limit := parseIntParam(r, "limit", 100000)
+	n, err := s.searchBackend.Sync(r.Context(), 1000, limit)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "copied %d search documents", n)
+	return nil
+}
+
 // handleRepopulateSearchDocuments repopulates every row in the search_documents table
 // that was last updated before the given time.
 func (s *Server) handleRepopulateSearchDocuments(w http.ResponseWriter, r *http.Request) error {
@@ -201,6 +443,126 @@ func (s *Server) handleRepopulateSearchDocuments(w http.ResponseWriter, r *http.
 	return nil
 }
 
+func (s *Server) handleCompressDocumentation(w http.ResponseWriter, r *http.Request) error {
+	limit := parseIntParam(r, "limit", 1000)
+	n, err := s.db.CompressDocumentation(r.Context(), limit)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "compressed %d values\n", n)
+	return nil
+}
+
+func (s *Server) handlePrunePseudoVersions(w http.ResponseWriter, r *http.Request) error {
+	if s.cfg.PseudoVersionRetention == 0 {
+		fmt.Fprintln(w, "pseudo-versions are kept forever")
+		return nil
+	}
+	limit := parseIntParam(r, "limit", 1000)
+	n, err := s.db.PrunePseudoVersions(r.Context(), time.Now().Add(-s.cfg.PseudoVersionRetention), limit)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "pruned %d pseudo-versions\n", n)
+	return nil
+}
+
+// handleFlagLookalikes flags module paths that look like the paths of popular
+// modules.
+func (s *Server) handleFlagLookalikes(w http.ResponseWriter, r *http.Request) error {
+	hours := parseIntParam(r, "hours", 25)
+	flagged, err := s.db.FlagLookalikes(r.Context(), time.Now().Add(-time.Duration(hours)*time.Hour))
+	if err != nil {
+		return err
+	}
+	// Pages of the flagged modules now show a warning.
+	for _, modulePath := range flagged {
+		s.invalidateModule(r.Context(), modulePath)
+	}
+	fmt.Fprintf(w, "flagged %d module paths\n", len(flagged))
+	return nil
+}
+
+// handleLookalikes lists flagged module paths, or reviews one of them.
+func (s *Server) handleLookalikes(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	if r.Method == http.MethodPost {
+		modulePath := r.FormValue("module_path")
+		status := r.FormValue("status")
+		kind := map[string]string{
+			lookalike.StatusConfirmed: abuse.ActionConfirmLookalike,
+			lookalike.StatusDismissed: abuse.ActionDismissLookalike,
+		}[status]
+		if kind == "" {
+			return &serverError{http.StatusBadRequest, fmt.Errorf("bad status %q", status)}
+		}
+		a := &abuse.Action{Actor: s.adminActor(r), Kind: kind, Target: modulePath}
+		if err := s.db.Moderate(ctx, a); err != nil {
+			return &serverError{derrors.ToHTTPStatus(err), err}
+		}
+		s.invalidateModerated(ctx, a)
+		log.Infof(ctx, "lookalike module path %s reviewed: %s", modulePath, status)
+		fmt.Fprintf(w, "%s: %s\n", modulePath, status)
+		return nil
+	}
+	status := r.FormValue("status")
+	if status == "" {
+		status = lookalike.StatusPending
+	}
+	flags, err := s.db.GetLookalikes(ctx, status)
+	if err != nil {
+		return err
+	}
+	for _, f := range flags {
+		fmt.Fprintf(w, "%s\tlooks like %s (%s), flagged %s\n", f.ModulePath, f.SimilarTo, f.Reason, f.CreatedAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// handleAbuseReports lists abuse reports, or reviews one of them.
+func (s *Server) handleAbuseReports(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	if r.Method == http.MethodPost {
+		id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+		if err != nil {
+			return &serverError{http.StatusBadRequest, fmt.Errorf("bad id: %v", err)}
+		}
+		status := r.FormValue("status")
+		kind := map[string]string{
+			abuse.StatusResolved:  abuse.ActionResolveReport,
+			abuse.StatusDismissed: abuse.ActionDismissReport,
+		}[status]
+		if kind == "" {
+			return &serverError{http.StatusBadRequest, fmt.Errorf("bad status %q", status)}
+		}
+		a := &abuse.Action{Actor: s.adminActor(r), Kind: kind, Target: strconv.FormatInt(id, 10)}
+		if err := s.db.Moderate(ctx, a); err != nil {
+			return &serverError{derrors.ToHTTPStatus(err), err}
+		}
+		log.Infof(ctx, "abuse report %d reviewed: %s", id, status)
+		fmt.Fprintf(w, "%d: %s\n", id, status)
+		return nil
+	}
+	status := r.FormValue("status")
+	if status == "" {
+		status = abuse.StatusPending
+	}
+	reports, err := s.db.GetAbuseReports(ctx, status)
+	if err != nil {
+		return err
+	}
+	for _, rep := range reports {
+		fmt.Fprintf(w, "%d\t%s\t%s@%s, reported %s\n", rep.ID, rep.Kind, rep.Path, rep.Version, rep.CreatedAt.Format(time.RFC3339))
+		if rep.Details != "" {
+			fmt.Fprintf(w, "\t%q\n", rep.Details)
+		}
+		if rep.Contact != "" {
+			fmt.Fprintf(w, "\tcontact: %s\n", rep.Contact)
+		}
+	}
+	return nil
+}
+
 // handleFetch executes a fetch request and returns a http.StatusOK if the
 // status is not http.StatusInternalServerError, so that the task queue does
 // not retry fetching module versions that have a terminal error.
@@ -243,9 +605,30 @@ func (s *Server) doFetch(r *http.Request) (string, int) {
 	if err != nil {
 		return err.Error(), code
 	}
+	// Pages for the module may show stale information, like the latest
+	// version.
+	s.invalidateModule(r.Context(), modulePath, cdn.ModuleVersionKey(modulePath, version))
+	s.notifyWebhooks(r.Context(), modulePath, version)
 	return fmt.Sprintf("fetched and updated %s@%s", modulePath, version), code
 }
 
+// invalidateModule removes the pages of modulePath from the page cache and
+// purges them, and those tagged with any of keys, from the CDN. It is called
+// whenever something shown on the pages changes. Failing to invalidate them
+// is not fatal: they will expire, so errors are only logged.
+func (s *Server) invalidateModule(ctx context.Context, modulePath string, keys ...string) {
+	if s.redisCacheClient != nil {
+		if err := middleware.InvalidateModule(ctx, s.redisCacheClient, modulePath); err != nil {
+			log.Error(ctx, err)
+		}
+	}
+	if s.cdnPurger != nil {
+		if err := s.cdnPurger.Purge(ctx, append([]string{cdn.ModuleKey(modulePath)}, keys...)...); err != nil {
+			log.Error(ctx, err)
+		}
+	}
+}
+
 // parseModulePathAndVersion returns the module and version specified by p. p
 // is assumed to have either of the following two structures:
 //   - <module>/@v/<version>
@@ -273,6 +656,12 @@ func parseModulePathAndVersion(requestPath string) (string, string, error) {
 func (s *Server) handleIndexAndQueue(w http.ResponseWriter, r *http.Request) (err error) {
 	defer derrors.Wrap(&err, "handleIndexAndQueue(%q)", r.URL.Path)
 	ctx := r.Context()
+	if s.indexClient == nil {
+		// The module index is disabled; modules are only fetched on request.
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintln(w, "module index is disabled")
+		return nil
+	}
 	limit := parseIntParam(r, "limit", 10)
 	suffixParam := r.FormValue("suffix")
 	since, err := s.db.LatestIndexTimestamp(ctx)
@@ -412,21 +801,12 @@ func (s *Server) doStatusPage(w http.ResponseWriter, r *http.Request) (_ string,
 	}
 	sort.Slice(counts, func(i, j int) bool { return counts[i].Code < counts[j].Code })
 
-	var env string
-	switch s.cfg.ServiceID {
-	case "":
-		env = "Local"
-	case "dev-etl":
-		env = "Dev"
-	case "staging-etl":
-		env = "Staging"
-	case "etl":
-		env = "Prod"
-	}
+	env := s.envName()
 	page := struct {
 		Config                       *config.Config
 		Env                          string
 		ResourcePrefix               string
+		Nonce                        string
 		LatestTimestamp              *time.Time
 		Counts                       []*count
 		Next, Recent, RecentFailures []*internal.ModuleVersionState
@@ -434,6 +814,7 @@ func (s *Server) doStatusPage(w http.ResponseWriter, r *http.Request) (_ string,
 		Config:          s.cfg,
 		Env:             env,
 		ResourcePrefix:  strings.ToLower(env) + "-",
+		Nonce:           middleware.NoncePlaceholder,
 		LatestTimestamp: &stats.LatestTimestamp,
 		Counts:          counts,
 		Next:            next,
@@ -475,6 +856,16 @@ func (s *Server) doPopulateStdLib(ctx context.Context, suffix string) (string, e
 	return fmt.Sprintf("Scheduling modules to be fetched: %s.\n", strings.Join(versions, ", ")), nil
 }
 
+// handleFetchStdMaster schedules a fetch of the standard library at master.
+func (s *Server) handleFetchStdMaster(w http.ResponseWriter, r *http.Request) error {
+	if err := s.queue.ScheduleFetch(r.Context(), stdlib.ModulePath, internal.MasterVersion, r.FormValue("suffix"), s.taskIDChangeInterval); err != nil {
+		return fmt.Errorf("handleFetchStdMaster: %v", err)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "scheduled %s@%s\n", stdlib.ModulePath, internal.MasterVersion)
+	return nil
+}
+
 func (s *Server) handleReprocess(w http.ResponseWriter, r *http.Request) error {
 	appVersion := r.FormValue("app_version")
 	if appVersion == "" {
@@ -486,7 +877,11 @@ func (s *Server) handleReprocess(w http.ResponseWriter, r *http.Request) error {
 	if err := s.db.UpdateModuleVersionStatesForReprocessing(r.Context(), appVersion); err != nil {
 		return err
 	}
-	return nil
+	return s.db.RecordAuditEntry(r.Context(), &audit.Entry{
+		Actor:  s.adminActor(r),
+		Action: audit.ActionReprocess,
+		Target: appVersion,
+	})
 }
 
 func (s *Server) clearCache(w http.ResponseWriter, r *http.Request) error {
@@ -497,19 +892,53 @@ func (s *Server) clearCache(w http.ResponseWriter, r *http.Request) error {
 	if status.Err() != nil {
 		return status.Err()
 	}
+	if err := s.db.RecordAuditEntry(r.Context(), &audit.Entry{
+		Actor:  s.adminActor(r),
+		Action: audit.ActionClearCache,
+	}); err != nil {
+		return err
+	}
 	fmt.Fprint(w, "Cache cleared.")
 	return nil
 }
 
 // Parse the template for the status page.
-func parseTemplate(staticPath string) (*template.Template, error) {
+// envName returns the name of the environment the worker runs in, for
+// display.
+func (s *Server) envName() string {
+	switch s.cfg.ServiceID {
+	case "":
+		return "Local"
+	case "dev-etl":
+		return "Dev"
+	case "staging-etl":
+		return "Staging"
+	case "etl":
+		return "Prod"
+	}
+	return ""
+}
+
+func parseTemplate(staticPath, name string) (*template.Template, error) {
 	if staticPath == "" {
 		return nil, nil
 	}
-	templatePath := filepath.Join(staticPath, "html/worker/index.tmpl")
-	return template.New("index.tmpl").Funcs(template.FuncMap{
+	templatePath := filepath.Join(staticPath, "html/worker", name)
+	return template.New(name).Funcs(template.FuncMap{
 		"truncate": truncate,
 		"timefmt":  formatTime,
+		"action": func(kind string, target interface{}, label string) adminButton {
+			return adminButton{Kind: kind, Target: fmt.Sprint(target), Label: label}
+		},
+		"takedown": func(path string, report interface{}) adminButton {
+			return adminButton{
+				Kind:        abuse.ActionExclude,
+				Target:      path,
+				Label:       "Take down",
+				Report:      fmt.Sprint(report),
+				NeedsReason: true,
+			}
+		},
 	}).ParseFiles(templatePath)
 }
 
@@ -0,0 +1,29 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// handleComputeSimilarPackages computes the packages most similar to the
+// packages whose similar packages are missing, or older than the "hours"
+// query parameter (default 168), at most "limit" (default 1000) of them.
+func (s *Server) handleComputeSimilarPackages(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	hours := parseIntParam(r, "hours", 7*24)
+	limit := parseIntParam(r, "limit", 1000)
+	n, err := s.db.ComputeSimilarPackages(ctx, time.Now().Add(-time.Duration(hours)*time.Hour), limit)
+	if err != nil {
+		return err
+	}
+	log.Infof(ctx, "computed the similar packages of %d packages", n)
+	fmt.Fprintf(w, "computed the similar packages of %d packages\n", n)
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestComputeSimilarPackages(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer postgres.ResetTestDB(testDB, t)
+
+	for _, m := range []string{"github.com/a/a", "github.com/b/b"} {
+		if err := testDB.InsertModule(ctx, sample.Module(m, sample.VersionString, "p")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, err := NewServer(&config.Config{}, ServerConfig{DB: testDB, StaticPath: "../../content/static"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.Install(mux.Handle)
+	for _, want := range []string{"of 2 packages", "of 0 packages"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/compute-similar-packages", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("got code %d, want 200", w.Code)
+		}
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("got body %q, want it to contain %q", w.Body, want)
+		}
+	}
+}
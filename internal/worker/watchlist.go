@@ -0,0 +1,54 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// handleNotifyWatchlists emails each user with updates to the modules they
+// watch a summary of them. A user whose email could not be sent gets the
+// updates in the next summary. Updates to modules that the ACL no longer
+// lets a user see are left out.
+func (s *Server) handleNotifyWatchlists(w http.ResponseWriter, r *http.Request) error {
+	if s.mailer == nil {
+		return &serverError{http.StatusNotImplemented, errors.New("no mailer configured")}
+	}
+	ctx := r.Context()
+	until := time.Now()
+	summaries, err := s.db.GetWatchlistSummaries(ctx, until)
+	if err != nil {
+		return err
+	}
+	var nsent, nfailed int
+	for _, sum := range summaries {
+		uctx := auth.NewContextWithUser(ctx, &auth.User{Email: sum.Email, Groups: sum.Groups})
+		sum.Filter(func(modulePath string) bool { return s.acl.Allowed(uctx, modulePath) })
+		if len(sum.Updates) == 0 {
+			if err := s.db.MarkWatchlistNotified(ctx, sum.Email, until); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.mailer.Send(ctx, sum.Email, sum.Subject(), sum.Body(s.siteURL)); err != nil {
+			log.Errorf(ctx, "sending watchlist summary to %s: %v", sum.Email, err)
+			nfailed++
+			continue
+		}
+		if err := s.db.MarkWatchlistNotified(ctx, sum.Email, until); err != nil {
+			return err
+		}
+		nsent++
+	}
+	log.Infof(ctx, "sent %d watchlist summaries, %d failed", nsent, nfailed)
+	fmt.Fprintf(w, "sent %d watchlist summaries, %d failed\n", nsent, nfailed)
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/auth"
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+// fakeMailer records the subjects of the email it sends, by recipient. It
+// fails to send to the address fail.
+type fakeMailer struct {
+	sent map[string][]string
+	fail string
+}
+
+func (m *fakeMailer) Send(ctx context.Context, to, subject, body string) error {
+	if to == m.fail {
+		return errors.New("unreachable")
+	}
+	m.sent[to] = append(m.sent[to], subject)
+	return nil
+}
+
+func TestNotifyWatchlists(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer postgres.ResetTestDB(testDB, t)
+
+	if err := testDB.InsertModule(ctx, sample.Module("example.com/a", "v1.0.0", "p")); err != nil {
+		t.Fatal(err)
+	}
+	for _, email := range []string{"alice@example.com", "bob@example.com"} {
+		if err := testDB.Watch(ctx, email, "example.com/a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := testDB.InsertModule(ctx, sample.Module("example.com/a", "v1.1.0", "p")); err != nil {
+		t.Fatal(err)
+	}
+
+	mailer := &fakeMailer{sent: map[string][]string{}, fail: "bob@example.com"}
+	s, err := NewServer(&config.Config{}, ServerConfig{
+		DB:         testDB,
+		StaticPath: "../../content/static",
+		Mailer:     mailer,
+		SiteURL:    "https://pkg.example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.Install(mux.Handle)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/notify-watchlists", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("got code %d, want 200", w.Code)
+		}
+	}
+	// Alice is sent the new version once. Bob, whose email failed, will get
+	// it in a later summary.
+	want := map[string][]string{"alice@example.com": {"New version of example.com/a: v1.1.0"}}
+	if diff := cmp.Diff(want, mailer.sent); diff != "" {
+		t.Errorf("sent mismatch (-want +got):\n%s", diff)
+	}
+	sums, err := testDB.GetWatchlistSummaries(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sums) != 1 || sums[0].Email != "bob@example.com" {
+		t.Errorf("got summaries %+v, want one for bob@example.com", sums)
+	}
+}
+
+func TestNotifyWatchlistsACL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer postgres.ResetTestDB(testDB, t)
+
+	if err := testDB.InsertModule(ctx, sample.Module("corp.com/secret", "v1.0.0", "p")); err != nil {
+		t.Fatal(err)
+	}
+	// Bob was in the eng group when watching the module; Alice was not.
+	for _, u := range []*auth.User{
+		{Email: "alice@example.com"},
+		{Email: "bob@example.com", Groups: []string{"eng"}},
+	} {
+		if err := testDB.Watch(auth.NewContextWithUser(ctx, u), u.Email, "corp.com/secret"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := testDB.InsertModule(ctx, sample.Module("corp.com/secret", "v1.1.0", "p")); err != nil {
+		t.Fatal(err)
+	}
+
+	mailer := &fakeMailer{sent: map[string][]string{}}
+	s, err := NewServer(&config.Config{}, ServerConfig{
+		DB:         testDB,
+		StaticPath: "../../content/static",
+		Mailer:     mailer,
+		SiteURL:    "https://pkg.example.com",
+		ACL:        auth.NewACL(map[string][]string{"corp.com": {"eng"}}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.Install(mux.Handle)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/notify-watchlists", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got code %d, want 200", w.Code)
+	}
+	want := map[string][]string{"bob@example.com": {"New version of corp.com/secret: v1.1.0"}}
+	if diff := cmp.Diff(want, mailer.sent); diff != "" {
+		t.Errorf("sent mismatch (-want +got):\n%s", diff)
+	}
+}
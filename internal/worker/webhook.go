@@ -0,0 +1,143 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/webhook"
+)
+
+// webhookTimeout is how long the worker waits for a webhook to respond.
+const webhookTimeout = 10 * time.Second
+
+// notifyWebhooks sends an event about modulePath@version, which was just
+// processed, to the webhooks for it that have not been sent one yet. Failing
+// to notify a webhook is not fatal: it is logged, and the webhook is notified
+// if the version is processed again.
+func (s *Server) notifyWebhooks(ctx context.Context, modulePath, version string) {
+	if err := s.doNotifyWebhooks(ctx, modulePath, version); err != nil {
+		log.Error(ctx, err)
+	}
+}
+
+func (s *Server) doNotifyWebhooks(ctx context.Context, modulePath, version string) (err error) {
+	defer derrors.Wrap(&err, "notifyWebhooks(%q, %q)", modulePath, version)
+
+	dbHooks, err := s.db.GetWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	var hooks []*webhook.Hook
+	for _, hs := range [][]*webhook.Hook{s.webhooks, dbHooks} {
+		for _, h := range hs {
+			if h.Matches(modulePath) {
+				hooks = append(hooks, h)
+			}
+		}
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	if version == internal.LatestVersion {
+		vm, err := s.db.GetVersionMap(ctx, modulePath, version)
+		if err != nil {
+			return err
+		}
+		version = vm.ResolvedVersion
+	}
+	delivered, err := s.db.GetWebhookDeliveries(ctx, modulePath, version)
+	if err != nil {
+		return err
+	}
+	e := &webhook.Event{
+		Type:       webhook.EventNewVersion,
+		ModulePath: modulePath,
+		Version:    version,
+		Timestamp:  time.Now().UTC(),
+	}
+	var wg sync.WaitGroup
+	for _, h := range hooks {
+		if delivered[h.URL] {
+			continue
+		}
+		// Several hooks with the same URL are notified once.
+		delivered[h.URL] = true
+		h := h
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.webhookSender.Send(ctx, h, e); err != nil {
+				log.Errorf(ctx, "webhook for %q: %v", h.Prefix, err)
+				return
+			}
+			if err := s.db.RecordWebhookDelivery(ctx, h.URL, modulePath, version); err != nil {
+				log.Error(ctx, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// handleWebhooks lists the webhooks registered in the database, or registers
+// or deletes one of them.
+func (s *Server) handleWebhooks(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	if r.Method == http.MethodPost {
+		actor := s.adminActor(r)
+		if d := r.FormValue("delete"); d != "" {
+			id, err := strconv.ParseInt(d, 10, 64)
+			if err != nil {
+				return &serverError{http.StatusBadRequest, fmt.Errorf("bad id: %v", err)}
+			}
+			if err := s.db.DeleteWebhook(ctx, id, actor); err != nil {
+				return &serverError{derrors.ToHTTPStatus(err), err}
+			}
+			log.Infof(ctx, "webhook %d deleted by %s", id, actor)
+			fmt.Fprintf(w, "deleted webhook %d\n", id)
+			return nil
+		}
+		h := &webhook.Hook{
+			Prefix: strings.TrimSpace(r.FormValue("prefix")),
+			URL:    strings.TrimSpace(r.FormValue("url")),
+			Secret: r.FormValue("secret"),
+		}
+		id, err := s.db.InsertWebhook(ctx, h, actor)
+		if err != nil {
+			return &serverError{derrors.ToHTTPStatus(err), err}
+		}
+		log.Infof(ctx, "webhook %d for %s registered by %s", id, h.Prefix, actor)
+		fmt.Fprintf(w, "registered webhook %d\n", id)
+		return nil
+	}
+	hooks, err := s.db.GetWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, h := range hooks {
+		h.Secret = ""
+	}
+	if hooks == nil {
+		hooks = []*webhook.Hook{}
+	}
+	data, err := json.MarshalIndent(hooks, "", "  ")
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(data)
+	return err
+}
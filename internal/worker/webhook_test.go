@@ -0,0 +1,143 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/webhook"
+)
+
+func TestNotifyWebhooks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer postgres.ResetTestDB(testDB, t)
+
+	var (
+		mu     sync.Mutex
+		events = map[string][]string{} // path of the hook URL to module@version
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Header.Get(webhook.SignatureHeader) != webhook.Sign("secret", body) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		var e webhook.Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			t.Fatal(err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		events[r.URL.Path] = append(events[r.URL.Path], e.ModulePath+"@"+e.Version)
+	}))
+	defer receiver.Close()
+
+	if _, err := testDB.InsertWebhook(ctx, &webhook.Hook{Prefix: "github.com/b", URL: receiver.URL + "/db", Secret: "secret"}, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewServer(&config.Config{}, ServerConfig{
+		DB:         testDB,
+		StaticPath: "../../content/static",
+		Webhooks: []*webhook.Hook{
+			{Prefix: "github.com/a", URL: receiver.URL + "/config", Secret: "secret"},
+			{Prefix: "github.com/b", URL: receiver.URL + "/config", Secret: "secret"},
+			{Prefix: "github.com", URL: receiver.URL + "/wrong", Secret: "wrong"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.notifyWebhooks(ctx, "github.com/b/m", "v1.0.0")
+	// A version is only sent once to each URL.
+	s.notifyWebhooks(ctx, "github.com/b/m", "v1.0.0")
+	s.notifyWebhooks(ctx, "github.com/a/m", "v1.0.0")
+	s.notifyWebhooks(ctx, "golang.org/x/m", "v1.0.0")
+
+	want := map[string][]string{
+		"/config": {"github.com/b/m@v1.0.0", "github.com/a/m@v1.0.0"},
+		"/db":     {"github.com/b/m@v1.0.0"},
+	}
+	for path, w := range want {
+		if got := strings.Join(events[path], " "); got != strings.Join(w, " ") {
+			t.Errorf("events sent to %s = %q, want %q", path, events[path], w)
+		}
+	}
+	if got := events["/wrong"]; len(got) != 0 {
+		t.Errorf("events accepted with a bad signature: %q", got)
+	}
+	// A failed delivery is not recorded, so it is retried when the version is
+	// processed again.
+	delivered, err := testDB.GetWebhookDeliveries(ctx, "github.com/a/m", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered[receiver.URL+"/wrong"] {
+		t.Error("failed delivery was recorded")
+	}
+}
+
+func TestHandleWebhooks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer postgres.ResetTestDB(testDB, t)
+
+	s, err := NewServer(&config.Config{}, ServerConfig{DB: testDB, StaticPath: "../../content/static"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.Install(mux.Handle)
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/webhooks", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := post(url.Values{"prefix": {"github.com/a"}, "url": {"https://bot.example/hook"}, "secret": {"s"}}); w.Code != http.StatusOK {
+		t.Fatalf("register: got code %d, want 200: %s", w.Code, w.Body)
+	}
+	if w := post(url.Values{"prefix": {"github.com/a"}, "url": {"https://bot.example/hook"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("register without a secret: got code %d, want 400", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/webhooks", nil))
+	var hooks []*webhook.Hook
+	if err := json.Unmarshal(w.Body.Bytes(), &hooks); err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 1 || hooks[0].Prefix != "github.com/a" || hooks[0].CreatedBy != localAdmin {
+		t.Fatalf("got %+v, want the hook for github.com/a registered by %s", hooks, localAdmin)
+	}
+	if hooks[0].Secret != "" {
+		t.Error("the secret of the hook was listed")
+	}
+
+	id := strconv.FormatInt(hooks[0].ID, 10)
+	if w := post(url.Values{"delete": {id}}); w.Code != http.StatusOK {
+		t.Errorf("delete: got code %d, want 200: %s", w.Code, w.Body)
+	}
+	if w := post(url.Values{"delete": {id}}); w.Code != http.StatusNotFound {
+		t.Errorf("delete twice: got code %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,25 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE teeproxy_events (
+    id                   INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    gddo_path            text NOT NULL,
+    pkggodev_path        text NOT NULL,
+    gddo_status          integer NOT NULL,
+    pkggodev_status      integer NOT NULL,
+    gddo_latency_ms      integer NOT NULL,
+    pkggodev_latency_ms  integer NOT NULL,
+    is_robot             boolean DEFAULT false NOT NULL,
+    created_at           timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+COMMENT ON TABLE teeproxy_events IS
+'TABLE teeproxy_events contains godoc.org requests received by the teeproxy, along with the result of replaying each request against pkg.go.dev. It is used to find divergences in status and latency between the two sites.';
+
+CREATE INDEX idx_teeproxy_events_created_at ON teeproxy_events (created_at);
+COMMENT ON INDEX idx_teeproxy_events_created_at IS
+'INDEX idx_teeproxy_events_created_at is used to report on recent events.';
+
+END;
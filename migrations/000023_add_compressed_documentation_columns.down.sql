@@ -0,0 +1,19 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+-- Compressed values cannot be decompressed in SQL, so they are lost. Modules
+-- whose documentation or READMEs were compressed must be reprocessed.
+
+BEGIN;
+
+UPDATE documentation SET html = '' WHERE html IS NULL;
+UPDATE readmes SET contents = '' WHERE contents IS NULL;
+ALTER TABLE packages DROP COLUMN documentation_gz;
+ALTER TABLE documentation DROP COLUMN html_gz;
+ALTER TABLE documentation ALTER COLUMN html SET NOT NULL;
+ALTER TABLE modules DROP COLUMN readme_contents_gz;
+ALTER TABLE readmes DROP COLUMN contents_gz;
+ALTER TABLE readmes ALTER COLUMN contents SET NOT NULL;
+
+END;
@@ -0,0 +1,23 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE packages ADD COLUMN documentation_gz bytea;
+ALTER TABLE documentation ADD COLUMN html_gz bytea;
+ALTER TABLE documentation ALTER COLUMN html DROP NOT NULL;
+ALTER TABLE modules ADD COLUMN readme_contents_gz bytea;
+ALTER TABLE readmes ADD COLUMN contents_gz bytea;
+ALTER TABLE readmes ALTER COLUMN contents DROP NOT NULL;
+
+COMMENT ON COLUMN packages.documentation_gz IS
+'COLUMN documentation_gz holds the gzip-compressed documentation HTML, when it is too large to store in the documentation column.';
+COMMENT ON COLUMN documentation.html_gz IS
+'COLUMN html_gz holds the gzip-compressed HTML, when it is too large to store in the html column.';
+COMMENT ON COLUMN modules.readme_contents_gz IS
+'COLUMN readme_contents_gz holds the gzip-compressed README contents, when they are too large to store in the readme_contents column.';
+COMMENT ON COLUMN readmes.contents_gz IS
+'COLUMN contents_gz holds the gzip-compressed README contents, when they are too large to store in the contents column.';
+
+END;
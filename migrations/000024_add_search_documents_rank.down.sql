@@ -0,0 +1,13 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+DROP FUNCTION popular_search_ranked(rawquery text, lim integer, off integer);
+DROP INDEX idx_search_documents_rank;
+ALTER TABLE search_documents
+    DROP COLUMN rank,
+    DROP COLUMN rank_updated_at;
+
+END;
@@ -0,0 +1,82 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE search_documents
+    ADD COLUMN rank real NOT NULL DEFAULT 1,
+    ADD COLUMN rank_updated_at timestamp with time zone;
+
+COMMENT ON COLUMN search_documents.rank IS
+'COLUMN rank is the part of the search score that does not depend on the query. It combines the imported_by_count, redistributability, has_go_mod and commit_time of the document, and is at most ln(exp(1)+imported_by_count).';
+
+-- Keep in sync with rankExpr in internal/postgres/search_rank.go.
+UPDATE search_documents SET
+    rank = (
+        ln(exp(1)+imported_by_count) *
+        CASE WHEN redistributable THEN 1 ELSE 0.5 END *
+        CASE WHEN COALESCE(has_go_mod, true) THEN 1 ELSE 0.8 END *
+        CASE WHEN commit_time < CURRENT_TIMESTAMP - INTERVAL '63072000 seconds' THEN 0.9 ELSE 1 END
+    )::real,
+    rank_updated_at = CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_search_documents_rank ON search_documents (rank DESC);
+
+CREATE OR REPLACE FUNCTION popular_search_ranked(rawquery text, lim integer, off integer) RETURNS SETOF search_result
+    LANGUAGE plpgsql
+    AS $$
+	DECLARE cur CURSOR(query TSQUERY) FOR
+		SELECT
+			package_path,
+			module_path,
+			version,
+			commit_time,
+			imported_by_count,
+			(
+				ts_rank('{0.1, 0.2, 1.0, 1.0}', tsv_search_tokens, query) *
+				rank *
+				CASE WHEN tsv_search_tokens @@ query THEN 1 ELSE 0 END
+			) score,
+			rank
+			FROM search_documents
+			ORDER BY rank DESC;
+	top search_result[];
+	res search_result;
+	res_rank real;
+	last_idx INT;
+BEGIN
+	last_idx := lim+off;
+	top := array_fill(NULL::search_result, array[last_idx]);
+	OPEN cur(query := websearch_to_tsquery(rawquery));
+	FETCH cur INTO res.package_path, res.module_path, res.version, res.commit_time,
+		res.imported_by_count, res.score, res_rank;
+	WHILE found LOOP
+		IF top[last_idx] IS NULL OR res.score >= top[last_idx].score THEN
+			FOR i IN 1..last_idx LOOP
+				IF top[i] IS NULL OR
+					(res.score > top[i].score) OR
+					(res.score = top[i].score AND res.commit_time > top[i].commit_time) OR
+					(res.score = top[i].score AND res.commit_time = top[i].commit_time AND
+					 res.package_path < top[i].package_path) THEN
+					top := (top[1:i-1] || res) || top[i:last_idx-1];
+					EXIT;
+				END IF;
+			END LOOP;
+		END IF;
+		-- Since ts_rank is at most 1, no later document can score more than
+		-- its rank.
+		IF top[last_idx].score > res_rank THEN
+			EXIT;
+		END IF;
+		FETCH cur INTO res.package_path, res.module_path, res.version, res.commit_time,
+			res.imported_by_count, res.score, res_rank;
+	END LOOP;
+	CLOSE cur;
+	RETURN QUERY SELECT * FROM UNNEST(top[off+1:last_idx])
+		WHERE package_path IS NOT NULL AND score > 0.1;
+END; $$;
+COMMENT ON FUNCTION popular_search_ranked(rawquery text, lim integer, off integer) IS
+'FUNCTION popular_search_ranked is used to generate results for search. It is like popular_search, but scans search documents in descending order of their precomputed rank.';
+
+END;
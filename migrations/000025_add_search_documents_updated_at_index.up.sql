@@ -0,0 +1,11 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE INDEX idx_search_documents_updated_at ON search_documents (updated_at, package_path);
+COMMENT ON INDEX idx_search_documents_updated_at IS
+'INDEX idx_search_documents_updated_at is used to copy search documents to another search backend incrementally.';
+
+END;
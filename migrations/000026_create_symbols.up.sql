@@ -0,0 +1,24 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE symbols (
+    package_path text NOT NULL,
+    module_path text NOT NULL,
+    version text NOT NULL,
+    name text NOT NULL,
+    kind text NOT NULL,
+    PRIMARY KEY (package_path, module_path, version, name),
+    FOREIGN KEY (package_path, module_path, version)
+        REFERENCES packages(path, module_path, version) ON DELETE CASCADE
+);
+COMMENT ON TABLE symbols IS
+'TABLE symbols contains the exported identifiers documented by each package, such as "Reader" and "Client.Do".';
+
+CREATE INDEX idx_symbols_name ON symbols (name);
+COMMENT ON INDEX idx_symbols_name IS
+'INDEX idx_symbols_name is used to find the packages that export an identifier.';
+
+END;
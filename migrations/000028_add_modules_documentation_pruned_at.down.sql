@@ -0,0 +1,10 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+DROP INDEX idx_modules_pseudo_unpruned;
+ALTER TABLE modules DROP COLUMN documentation_pruned_at;
+
+END;
@@ -0,0 +1,16 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE modules ADD COLUMN documentation_pruned_at timestamp with time zone;
+COMMENT ON COLUMN modules.documentation_pruned_at IS
+'COLUMN documentation_pruned_at is the time at which the documentation and READMEs of the module version were deleted by the retention policy for pseudo-versions, or NULL if they were not.';
+
+CREATE INDEX idx_modules_pseudo_unpruned ON modules (commit_time)
+    WHERE version_type = 'pseudo' AND documentation_pruned_at IS NULL;
+COMMENT ON INDEX idx_modules_pseudo_unpruned IS
+'INDEX idx_modules_pseudo_unpruned is used to find the pseudo-versions whose documentation may be pruned.';
+
+END;
@@ -0,0 +1,46 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+DROP TABLE imported_by_counts;
+
+ALTER TABLE imports_unique RENAME TO imports_unique_partitioned;
+ALTER TABLE imports_unique_partitioned RENAME CONSTRAINT imports_unique_pkey TO imports_unique_partitioned_pkey;
+DROP INDEX idx_imports_unique_from_module_path;
+CREATE TABLE imports_unique (
+    to_path text NOT NULL,
+    from_path text NOT NULL,
+    from_module_path text NOT NULL,
+    PRIMARY KEY (to_path, from_path, from_module_path)
+);
+COMMENT ON TABLE imports_unique IS
+'TABLE imports_unique contains the imports for a unique import_path in the packages table. The from_version is dropped; each row says that package from_path in some version of from_module_path imports (some version of) to_path. Used to speed up imported-by computations.';
+INSERT INTO imports_unique (to_path, from_path, from_module_path)
+    SELECT to_path, from_path, from_module_path FROM imports_unique_partitioned;
+DROP TABLE imports_unique_partitioned;
+CREATE INDEX idx_imports_unique_from_module_path ON imports_unique (from_module_path);
+
+ALTER TABLE imports RENAME TO imports_partitioned;
+ALTER TABLE imports_partitioned RENAME CONSTRAINT imports_pkey TO imports_partitioned_pkey;
+DROP INDEX idx_imports_from_path_from_version;
+CREATE TABLE imports (
+    from_path text NOT NULL,
+    from_module_path text NOT NULL,
+    from_version text NOT NULL,
+    to_path text NOT NULL,
+    PRIMARY KEY (to_path, from_path, from_version, from_module_path),
+    FOREIGN KEY (from_path, from_module_path, from_version)
+        REFERENCES packages(path, module_path, version) ON DELETE CASCADE
+);
+COMMENT ON TABLE imports IS
+'TABLE imports contains the imports for a package in the packages table. Package (from_path), in module (from_module_path) at version (from_version), imports package (to_path). We do not store the version and module at which to_path is imported because it is hard to compute.';
+INSERT INTO imports (from_path, from_module_path, from_version, to_path)
+    SELECT from_path, from_module_path, from_version, to_path FROM imports_partitioned;
+DROP TABLE imports_partitioned;
+CREATE INDEX idx_imports_from_path_from_version ON imports (from_path, from_version);
+COMMENT ON INDEX idx_imports_from_path_from_version IS
+'INDEX idx_imports_from_path_from_version is used to improve performance of the imports tab.';
+
+END;
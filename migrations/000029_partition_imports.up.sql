@@ -0,0 +1,70 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+-- imports_unique is read by to_path, to find a package's importers, so it is
+-- partitioned by a hash of to_path. Each imported-by query reads only one
+-- partition, and its indexes stay small enough to fit in memory.
+ALTER TABLE imports_unique RENAME TO imports_unique_old;
+ALTER TABLE imports_unique_old RENAME CONSTRAINT imports_unique_pkey TO imports_unique_old_pkey;
+DROP INDEX idx_imports_unique_from_module_path;
+
+CREATE TABLE imports_unique (
+    to_path text NOT NULL,
+    from_path text NOT NULL,
+    from_module_path text NOT NULL,
+    PRIMARY KEY (to_path, from_path, from_module_path)
+) PARTITION BY HASH (to_path);
+COMMENT ON TABLE imports_unique IS
+'TABLE imports_unique contains the imports for a unique import_path in the packages table. The from_version is dropped; each row says that package from_path in some version of from_module_path imports (some version of) to_path. Used to speed up imported-by computations. It is partitioned by a hash of to_path.';
+
+CREATE INDEX idx_imports_unique_from_module_path ON imports_unique (from_module_path);
+
+-- imports is read by from_path, for the imports tab, so it is partitioned by
+-- a hash of from_path.
+ALTER TABLE imports RENAME TO imports_old;
+ALTER TABLE imports_old RENAME CONSTRAINT imports_pkey TO imports_old_pkey;
+DROP INDEX idx_imports_from_path_from_version;
+
+CREATE TABLE imports (
+    from_path text NOT NULL,
+    from_module_path text NOT NULL,
+    from_version text NOT NULL,
+    to_path text NOT NULL,
+    PRIMARY KEY (to_path, from_path, from_version, from_module_path),
+    FOREIGN KEY (from_path, from_module_path, from_version)
+        REFERENCES packages(path, module_path, version) ON DELETE CASCADE
+) PARTITION BY HASH (from_path);
+COMMENT ON TABLE imports IS
+'TABLE imports contains the imports for a package in the packages table. Package (from_path), in module (from_module_path) at version (from_version), imports package (to_path). We do not store the version and module at which to_path is imported because it is hard to compute. It is partitioned by a hash of from_path.';
+
+CREATE INDEX idx_imports_from_path_from_version ON imports (from_path, from_version);
+COMMENT ON INDEX idx_imports_from_path_from_version IS
+'INDEX idx_imports_from_path_from_version is used to improve performance of the imports tab.';
+
+DO $$
+BEGIN
+    FOR i IN 0..15 LOOP
+        EXECUTE format('CREATE TABLE imports_unique_%s PARTITION OF imports_unique FOR VALUES WITH (MODULUS 16, REMAINDER %s)', i, i);
+        EXECUTE format('CREATE TABLE imports_%s PARTITION OF imports FOR VALUES WITH (MODULUS 16, REMAINDER %s)', i, i);
+    END LOOP;
+END $$;
+
+INSERT INTO imports_unique (to_path, from_path, from_module_path)
+    SELECT to_path, from_path, from_module_path FROM imports_unique_old;
+DROP TABLE imports_unique_old;
+INSERT INTO imports (from_path, from_module_path, from_version, to_path)
+    SELECT from_path, from_module_path, from_version, to_path FROM imports_old;
+DROP TABLE imports_old;
+
+CREATE TABLE imported_by_counts (
+    package_path text NOT NULL PRIMARY KEY,
+    imported_by_count integer NOT NULL,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+COMMENT ON TABLE imported_by_counts IS
+'TABLE imported_by_counts holds the number of packages in other modules that import each package, as of the last time the worker computed them from imports_unique. Only the latest version of each importer is counted.';
+
+END;
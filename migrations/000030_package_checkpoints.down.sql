@@ -0,0 +1,13 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+DROP TABLE package_checkpoints;
+
+ALTER TABLE module_version_states
+    DROP COLUMN checkpoint_packages,
+    DROP COLUMN checkpointed_at;
+
+END;
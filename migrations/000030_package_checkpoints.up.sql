@@ -0,0 +1,30 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE module_version_states
+    ADD COLUMN checkpoint_packages integer,
+    ADD COLUMN checkpointed_at timestamp with time zone;
+COMMENT ON COLUMN module_version_states.checkpoint_packages IS
+'COLUMN checkpoint_packages is the number of packages of a large module version that have been processed and saved in package_checkpoints by a fetch that has not finished, or NULL if there are none.';
+COMMENT ON COLUMN module_version_states.checkpointed_at IS
+'COLUMN checkpointed_at is the time that packages were last saved in package_checkpoints for the module version.';
+
+CREATE TABLE package_checkpoints (
+    module_path text NOT NULL,
+    version text NOT NULL,
+    package_path text NOT NULL,
+    status integer NOT NULL,
+    error text,
+    package_json text,
+    package_json_gz bytea,
+    PRIMARY KEY (module_path, version, package_path)
+);
+COMMENT ON TABLE package_checkpoints IS
+'TABLE package_checkpoints holds the packages of large module versions that have been processed by a fetch that has not finished, so that a fetch that is interrupted can resume. The rows of a module version are deleted when its fetch finishes.';
+COMMENT ON COLUMN package_checkpoints.package_json IS
+'COLUMN package_json is the processed package, encoded as JSON, or NULL if the directory could not be processed into a package. Large values are stored gzip-compressed in package_json_gz instead.';
+
+END;
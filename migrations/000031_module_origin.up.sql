@@ -0,0 +1,12 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE modules ADD COLUMN origin jsonb;
+
+COMMENT ON COLUMN modules.origin IS
+'COLUMN origin holds the version control origin of the module version (VCS, repository URL, subdirectory, commit hash and ref), as reported by the module proxy. It is NULL if the proxy did not report it.';
+
+END;
@@ -0,0 +1,23 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE module_files (
+    module_path text NOT NULL,
+    version text NOT NULL,
+    path text NOT NULL,
+    size bigint NOT NULL,
+    PRIMARY KEY (module_path, version, path),
+    FOREIGN KEY (module_path, version)
+        REFERENCES modules(module_path, version) ON DELETE CASCADE
+);
+COMMENT ON TABLE module_files IS
+'TABLE module_files lists the files in the zip of each module version, so that users can see what a module ships.';
+COMMENT ON COLUMN module_files.path IS
+'COLUMN path is the path of the file relative to the module root.';
+COMMENT ON COLUMN module_files.size IS
+'COLUMN size is the uncompressed size of the file in bytes.';
+
+END;
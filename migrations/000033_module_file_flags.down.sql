@@ -0,0 +1,13 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE module_files DROP COLUMN executable;
+
+ALTER TABLE modules
+    DROP COLUMN has_executables,
+    DROP COLUMN has_large_files;
+
+END;
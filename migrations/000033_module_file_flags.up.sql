@@ -0,0 +1,19 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE modules
+    ADD COLUMN has_executables boolean NOT NULL DEFAULT FALSE,
+    ADD COLUMN has_large_files boolean NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN modules.has_executables IS
+'COLUMN has_executables records whether the module zip contains executable or object files.';
+COMMENT ON COLUMN modules.has_large_files IS
+'COLUMN has_large_files records whether the module zip contains files larger than the worker''s large file threshold when the module version was fetched.';
+
+ALTER TABLE module_files ADD COLUMN executable boolean NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN module_files.executable IS
+'COLUMN executable records whether the file is an executable or object file, judging by its first bytes.';
+
+END;
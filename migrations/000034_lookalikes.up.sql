@@ -0,0 +1,26 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE lookalikes (
+    module_path text NOT NULL PRIMARY KEY,
+    similar_to text NOT NULL,
+    reason text NOT NULL,
+    status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'confirmed', 'dismissed')),
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    reviewed_at timestamp with time zone
+);
+COMMENT ON TABLE lookalikes IS
+'TABLE lookalikes holds module paths that look confusingly like the path of a popular module, as a typosquatting attack would use, and their review status.';
+COMMENT ON COLUMN lookalikes.similar_to IS
+'COLUMN similar_to is the path of the popular module that module_path looks like.';
+COMMENT ON COLUMN lookalikes.status IS
+'COLUMN status is pending until an administrator reviews the flag, and then confirmed or dismissed. Pages of modules with dismissed flags show no warning.';
+
+CREATE INDEX idx_lookalikes_status ON lookalikes (status, created_at);
+COMMENT ON INDEX idx_lookalikes_status IS
+'INDEX idx_lookalikes_status is used to list the flags awaiting review.';
+
+END;
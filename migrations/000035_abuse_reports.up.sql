@@ -0,0 +1,38 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE abuse_reports (
+    id bigserial PRIMARY KEY,
+    path text NOT NULL,
+    version text NOT NULL DEFAULT '',
+    kind text NOT NULL CHECK (kind IN ('malware', 'license-violation', 'spam')),
+    details text NOT NULL DEFAULT '',
+    contact text NOT NULL DEFAULT '',
+    reporter text NOT NULL,
+    status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'resolved', 'dismissed')),
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    reviewed_at timestamp with time zone
+);
+COMMENT ON TABLE abuse_reports IS
+'TABLE abuse_reports holds reports by users that a package or module contains malware, violates a license or is spam, and their review status.';
+COMMENT ON COLUMN abuse_reports.path IS
+'COLUMN path is the path of the reported package or module.';
+COMMENT ON COLUMN abuse_reports.version IS
+'COLUMN version is the reported version, or empty if the report is not about a specific version.';
+COMMENT ON COLUMN abuse_reports.reporter IS
+'COLUMN reporter is a hash of the IP address the report came from, used to limit the rate of reports. The address itself is not stored.';
+COMMENT ON COLUMN abuse_reports.status IS
+'COLUMN status is pending until an administrator reviews the report, and then resolved or dismissed.';
+
+CREATE INDEX idx_abuse_reports_status ON abuse_reports (status, created_at);
+COMMENT ON INDEX idx_abuse_reports_status IS
+'INDEX idx_abuse_reports_status is used to list the reports awaiting review.';
+
+CREATE INDEX idx_abuse_reports_reporter ON abuse_reports (reporter, created_at);
+COMMENT ON INDEX idx_abuse_reports_reporter IS
+'INDEX idx_abuse_reports_reporter is used to count the recent reports from a reporter.';
+
+END;
@@ -0,0 +1,26 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE admin_actions (
+    id bigserial PRIMARY KEY,
+    actor text NOT NULL CHECK (actor <> ''),
+    action text NOT NULL,
+    target text NOT NULL,
+    reason text NOT NULL DEFAULT '',
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+COMMENT ON TABLE admin_actions IS
+'TABLE admin_actions is the audit log of the moderation dashboard: each row is a change made by an administrator to abuse reports, lookalike flags or excluded prefixes.';
+COMMENT ON COLUMN admin_actions.actor IS
+'COLUMN actor is the email address of the administrator who made the change.';
+COMMENT ON COLUMN admin_actions.target IS
+'COLUMN target is the ID of an abuse report, a module path for lookalike flags, or a path prefix for exclusions.';
+
+CREATE INDEX idx_admin_actions_created_at ON admin_actions (created_at);
+COMMENT ON INDEX idx_admin_actions_created_at IS
+'INDEX idx_admin_actions_created_at is used to show the most recent actions.';
+
+END;
@@ -0,0 +1,15 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+DROP INDEX idx_admin_actions_target;
+DROP INDEX idx_admin_actions_actor;
+DROP TRIGGER admin_actions_append_only ON admin_actions;
+DROP FUNCTION reject_admin_actions_change();
+ALTER TABLE admin_actions
+    DROP COLUMN after,
+    DROP COLUMN before;
+
+END;
@@ -0,0 +1,34 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE admin_actions
+    ADD COLUMN before jsonb,
+    ADD COLUMN after jsonb;
+COMMENT ON TABLE admin_actions IS
+'TABLE admin_actions is the append-only audit log of changes made by administrators, like moderation actions, exclusions, reprocessing and cache purges.';
+COMMENT ON COLUMN admin_actions.before IS
+'COLUMN before is a JSON description of the target before the change, or NULL if it did not exist or is not recorded.';
+COMMENT ON COLUMN admin_actions.after IS
+'COLUMN after is a JSON description of the target after the change, or NULL if it no longer exists or is not recorded.';
+
+CREATE FUNCTION reject_admin_actions_change() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    RAISE EXCEPTION 'admin_actions is append-only';
+END;
+$$;
+
+CREATE TRIGGER admin_actions_append_only
+    BEFORE UPDATE OR DELETE ON admin_actions
+    FOR EACH ROW EXECUTE PROCEDURE reject_admin_actions_change();
+COMMENT ON TRIGGER admin_actions_append_only ON admin_actions IS
+'TRIGGER admin_actions_append_only keeps rows of the audit log from being changed or deleted.';
+
+CREATE INDEX idx_admin_actions_actor ON admin_actions (actor, created_at);
+CREATE INDEX idx_admin_actions_target ON admin_actions (target, created_at);
+
+END;
@@ -0,0 +1,34 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE webhooks (
+    id bigserial PRIMARY KEY,
+    prefix text NOT NULL CHECK (prefix <> ''),
+    url text NOT NULL,
+    secret text NOT NULL,
+    created_by text NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+COMMENT ON TABLE webhooks IS
+'TABLE webhooks holds the URLs that are notified when a new version of a module under a path prefix is processed.';
+COMMENT ON COLUMN webhooks.secret IS
+'COLUMN secret is the key that notifications to the URL are signed with.';
+
+CREATE TABLE webhook_deliveries (
+    url text NOT NULL,
+    module_path text NOT NULL,
+    version text NOT NULL,
+    delivered_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (url, module_path, version)
+);
+COMMENT ON TABLE webhook_deliveries IS
+'TABLE webhook_deliveries records the module versions that each webhook URL was notified of, so that reprocessing a version does not notify it again.';
+
+CREATE INDEX idx_webhook_deliveries_module_path_version ON webhook_deliveries (module_path, version);
+COMMENT ON INDEX idx_webhook_deliveries_module_path_version IS
+'INDEX idx_webhook_deliveries_module_path_version is used to find the URLs already notified of a module version.';
+
+END;
@@ -0,0 +1,23 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE watchlists (
+    email text NOT NULL,
+    module_path text NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    notified_at timestamp with time zone,
+    PRIMARY KEY (email, module_path)
+);
+COMMENT ON TABLE watchlists IS
+'TABLE watchlists holds the modules that signed-in users of a private deployment watch, to be emailed about their new versions.';
+COMMENT ON COLUMN watchlists.notified_at IS
+'COLUMN notified_at is when the user was last sent a summary of new versions, or NULL if never. Versions inserted after it, and after created_at, are in the next summary.';
+
+CREATE INDEX idx_watchlists_module_path ON watchlists (module_path);
+COMMENT ON INDEX idx_watchlists_module_path IS
+'INDEX idx_watchlists_module_path is used to join watched modules with their versions.';
+
+END;
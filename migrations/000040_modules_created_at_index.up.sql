@@ -0,0 +1,11 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE INDEX idx_modules_created_at ON modules (created_at);
+COMMENT ON INDEX idx_modules_created_at IS
+'INDEX idx_modules_created_at is used to list the most recently processed module versions, for the feed of new versions.';
+
+END;
@@ -0,0 +1,26 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE module_requires (
+    module_path text NOT NULL,
+    version text NOT NULL,
+    required_path text NOT NULL,
+    required_version text NOT NULL,
+    indirect boolean NOT NULL DEFAULT false,
+    PRIMARY KEY (module_path, version, required_path),
+    FOREIGN KEY (module_path, version)
+        REFERENCES modules(module_path, version) ON DELETE CASCADE
+);
+COMMENT ON TABLE module_requires IS
+'TABLE module_requires lists the requirements in the go.mod file of each module version, so that users can compare the dependencies of two versions.';
+COMMENT ON COLUMN module_requires.required_path IS
+'COLUMN required_path is the module path of the requirement.';
+COMMENT ON COLUMN module_requires.required_version IS
+'COLUMN required_version is the version of the requirement.';
+COMMENT ON COLUMN module_requires.indirect IS
+'COLUMN indirect is whether the requirement is marked "// indirect".';
+
+END;
@@ -0,0 +1,23 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE release_notes (
+    module_path text NOT NULL,
+    version text NOT NULL,
+    file_path text NOT NULL,
+    contents text NOT NULL,
+    PRIMARY KEY (module_path, version),
+    FOREIGN KEY (module_path, version)
+        REFERENCES modules(module_path, version) ON DELETE CASCADE
+);
+COMMENT ON TABLE release_notes IS
+'TABLE release_notes holds the section about each module version of a changelog at the root of the module, found heuristically.';
+COMMENT ON COLUMN release_notes.file_path IS
+'COLUMN file_path is the path of the changelog relative to the module root, like CHANGELOG.md.';
+COMMENT ON COLUMN release_notes.contents IS
+'COLUMN contents is the section of the changelog about the version, without its heading.';
+
+END;
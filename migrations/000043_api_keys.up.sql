@@ -0,0 +1,34 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE api_keys (
+    id bigserial PRIMARY KEY,
+    key_hash text NOT NULL UNIQUE,
+    name text NOT NULL CHECK (name <> ''),
+    tier text NOT NULL,
+    created_by text NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    revoked_at timestamp with time zone
+);
+COMMENT ON TABLE api_keys IS
+'TABLE api_keys holds the keys that give programmatic users of the API higher rate limits than anonymous traffic.';
+COMMENT ON COLUMN api_keys.key_hash IS
+'COLUMN key_hash is the hex-encoded SHA-256 hash of the key. The key itself is only shown when it is issued.';
+COMMENT ON COLUMN api_keys.tier IS
+'COLUMN tier is the name of the quota tier of the key, which determines its rate limit.';
+COMMENT ON COLUMN api_keys.revoked_at IS
+'COLUMN revoked_at is when the key was revoked, or NULL if it can still be used.';
+
+CREATE TABLE api_key_usage (
+    key_id bigint NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    day date NOT NULL,
+    requests bigint NOT NULL,
+    PRIMARY KEY (key_id, day)
+);
+COMMENT ON TABLE api_key_usage IS
+'TABLE api_key_usage counts the requests made with each API key, by day (UTC).';
+
+END;
@@ -0,0 +1,11 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE symbols ADD COLUMN importance real NOT NULL DEFAULT 0;
+COMMENT ON COLUMN symbols.importance IS
+'COLUMN importance is how prominent the symbol is in the documentation of its package, from the links to it and the length of its documentation. The most important symbols of a package are shown in search results.';
+
+END;
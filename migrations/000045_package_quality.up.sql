@@ -0,0 +1,26 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE package_quality (
+    path text NOT NULL,
+    module_path text NOT NULL,
+    version text NOT NULL,
+    has_tests boolean NOT NULL,
+    has_examples boolean NOT NULL,
+    num_exported integer NOT NULL,
+    num_documented integer NOT NULL,
+    PRIMARY KEY (path, module_path, version),
+    FOREIGN KEY (path, module_path, version)
+        REFERENCES packages(path, module_path, version) ON DELETE CASCADE
+);
+COMMENT ON TABLE package_quality IS
+'TABLE package_quality holds signals about the quality of each package version, found by analyzing its source when it is fetched.';
+COMMENT ON COLUMN package_quality.num_exported IS
+'COLUMN num_exported is the number of exported constants, variables, functions, types and methods of the package.';
+COMMENT ON COLUMN package_quality.num_documented IS
+'COLUMN num_documented is the number of exported identifiers of the package that have a doc comment.';
+
+END;
@@ -0,0 +1,22 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE external_signals (
+    module_path text NOT NULL,
+    provider text NOT NULL,
+    label text NOT NULL,
+    url text NOT NULL,
+    fetched_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (module_path, provider)
+);
+COMMENT ON TABLE external_signals IS
+'TABLE external_signals caches the facts about modules got from services outside the site, like the number of stars of their repositories. The worker refreshes them.';
+COMMENT ON COLUMN external_signals.provider IS
+'COLUMN provider is the name of the signals.Provider that got the signal, like github-stars.';
+COMMENT ON COLUMN external_signals.label IS
+'COLUMN label is the text shown for the signal, or empty if the service had nothing about the module.';
+
+END;
@@ -0,0 +1,22 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE repository_metadata (
+    repo_url text PRIMARY KEY,
+    missing boolean NOT NULL,
+    stars integer NOT NULL,
+    archived boolean NOT NULL,
+    last_activity timestamp with time zone,
+    fetched_at timestamp with time zone NOT NULL
+);
+COMMENT ON TABLE repository_metadata IS
+'TABLE repository_metadata holds what the code hosts of the repositories of modules know about them, refreshed by the worker.';
+COMMENT ON COLUMN repository_metadata.missing IS
+'COLUMN missing is whether the code host has no such repository, for instance because it was deleted.';
+COMMENT ON COLUMN repository_metadata.last_activity IS
+'COLUMN last_activity is the last time the repository was pushed to, if known.';
+
+END;
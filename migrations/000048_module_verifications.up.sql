@@ -0,0 +1,23 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE module_verifications (
+    module_path text NOT NULL,
+    email text NOT NULL,
+    token text NOT NULL,
+    method text,
+    verified_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (module_path, email)
+);
+COMMENT ON TABLE module_verifications IS
+'TABLE module_verifications holds the requests of signed-in users to be recorded as owners of modules, and whether they proved control of them.';
+COMMENT ON COLUMN module_verifications.token IS
+'COLUMN token is the secret that the user publishes in the repository of the module, or in a DNS TXT record of its host, to prove control of it.';
+COMMENT ON COLUMN module_verifications.method IS
+'COLUMN method is how the user proved control of the module, "file" or "dns", or NULL if the user has not yet.';
+
+END;
@@ -0,0 +1,18 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE superseded_packages (
+    package_path text PRIMARY KEY,
+    superseded_by text NOT NULL,
+    created_by text NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE superseded_packages IS
+'TABLE superseded_packages holds the packages that verified owners or administrators recorded as superseded by other packages. Superseded packages are demoted in search.';
+COMMENT ON COLUMN superseded_packages.created_by IS
+'COLUMN created_by is the email address of the owner or administrator who recorded the supersession.';
+
+END;
@@ -0,0 +1,22 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE similar_packages (
+    package_path text NOT NULL,
+    similar_path text NOT NULL,
+    score real NOT NULL,
+    PRIMARY KEY (package_path, similar_path)
+);
+COMMENT ON TABLE similar_packages IS
+'TABLE similar_packages holds, for each package, the packages of other modules most similar to it, from the packages that import both, the go.mod files that require both modules, and their synopses. It is computed periodically by the worker.';
+COMMENT ON COLUMN similar_packages.score IS
+'COLUMN score is how similar similar_path is to package_path, between 0 and 1.';
+
+ALTER TABLE search_documents ADD COLUMN similar_computed_at timestamp with time zone;
+COMMENT ON COLUMN search_documents.similar_computed_at IS
+'COLUMN similar_computed_at is when the rows of similar_packages for the package were last computed.';
+
+END;
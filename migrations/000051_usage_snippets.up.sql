@@ -0,0 +1,25 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE usage_snippets (
+    path text NOT NULL,
+    module_path text NOT NULL,
+    version text NOT NULL,
+    import_path text NOT NULL,
+    code text NOT NULL,
+    url text NOT NULL,
+    PRIMARY KEY (path, module_path, version, import_path),
+    FOREIGN KEY (path, module_path, version)
+        REFERENCES packages(path, module_path, version) ON DELETE CASCADE
+);
+COMMENT ON TABLE usage_snippets IS
+'TABLE usage_snippets holds short excerpts of the source of redistributable package versions that call the packages of other modules they import, found when they are fetched. They show how the imported packages are used.';
+COMMENT ON COLUMN usage_snippets.url IS
+'COLUMN url is the URL of the first line of the snippet at the repository of the package, or empty if it is not known.';
+
+CREATE INDEX idx_usage_snippets_import_path ON usage_snippets (import_path);
+
+END;
@@ -0,0 +1,33 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE collections (
+    name text NOT NULL PRIMARY KEY,
+    title text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    updated_by text NOT NULL,
+    updated_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE collections IS
+'TABLE collections holds the lists of hand-picked packages that administrators curate, shown at /collections/<name>.';
+COMMENT ON COLUMN collections.updated_by IS
+'COLUMN updated_by is the administrator who last saved the collection.';
+
+CREATE TABLE collection_packages (
+    collection_name text NOT NULL REFERENCES collections(name) ON DELETE CASCADE,
+    package_path text NOT NULL,
+    blurb text NOT NULL DEFAULT '',
+    position integer NOT NULL,
+    PRIMARY KEY (collection_name, package_path)
+);
+COMMENT ON TABLE collection_packages IS
+'TABLE collection_packages holds the packages of each collection. The packages need not have been fetched.';
+COMMENT ON COLUMN collection_packages.blurb IS
+'COLUMN blurb says why the package is in the collection.';
+COMMENT ON COLUMN collection_packages.position IS
+'COLUMN position orders the packages of a collection, from zero.';
+
+END;
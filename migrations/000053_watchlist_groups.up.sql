@@ -0,0 +1,11 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE watchlists ADD COLUMN groups text[] NOT NULL DEFAULT '{}';
+COMMENT ON COLUMN watchlists.groups IS
+'COLUMN groups holds the groups of the user when they last watched a module. The worker checks the ACL against them before emailing the user about new versions.';
+
+END;